@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -16,8 +18,16 @@ type Agent struct {
 	config     *Config
 	executor   *Executor
 	monitor    *SystemMonitor
+	state      *StateStore
+	events     *EventBus
+	metrics    *Broadcaster[*SystemMetrics]
+	alerts     *Broadcaster[LogAlert]
+	watcher    *LogAlertWatcher
 	servers    map[string]*MinecraftServer
+	restarts   map[string]*restartState
 	serversMux sync.RWMutex
+	saveLocks  sync.Map // Un backup en línea a la vez por servidor
+	gameStats  sync.Map // Últimas GameStats recolectadas por servidor
 	startTime  time.Time
 }
 
@@ -33,6 +43,18 @@ type Config struct {
 	EnableMetrics  bool              `json:"enable_metrics"`
 	MetricsInterval time.Duration    `json:"metrics_interval"`
 	CustomEnv      map[string]string `json:"custom_env"`
+	StateFile      string            `json:"state_file"` // Por defecto WorkDir/.aymc-state.json
+	BackupDir      string            `json:"backup_dir"` // Por defecto WorkDir/backups
+	RestartPolicy  RestartPolicy     `json:"restart_policy"`
+	AuthTokenHash  string            `json:"auth_token_hash"` // SHA256 del token del backend (vacío = sin emparejar)
+	TLSDir         string            `json:"tls_dir"`         // Por defecto WorkDir/.aymc-tls
+	EnrollmentToken string           `json:"enrollment_token,omitempty"` // Se canjea en el primer arranque
+	EnrollURL      string            `json:"enroll_url,omitempty"`     // URL HTTPS de la API del backend donde se canjea el token
+	AllowInsecureEnroll bool         `json:"allow_insecure_enroll,omitempty"` // Acepta enroll_url con http:// (solo desarrollo o redes de confianza)
+	FileRoots      []string          `json:"file_roots,omitempty"` // Directorios extra accesibles por la API de archivos sin server_id
+	TunnelAddress  string            `json:"tunnel_address,omitempty"` // host:port del backend; si se define el agente abre el túnel (modo reverse, NAT)
+	GameStatsInterval time.Duration  `json:"game_stats_interval,omitempty"` // Jugadores, TPS y MSPT; 0 = DefaultGameStatsInterval
+	MetricsAddr    string            `json:"metrics_addr,omitempty"` // Escucha HTTP de métricas Prometheus (p. ej. ":9100"); vacío = deshabilitado
 }
 
 // MinecraftServer representa una instancia de servidor
@@ -48,6 +70,9 @@ type MinecraftServer struct {
 	StartTime   time.Time
 	WorkDir     string
 	Config      ServerConfig
+
+	RestartCount int // Reinicios automáticos dentro de la ventana actual
+	LastExitCode int
 }
 
 // ServerStatus representa el estado del servidor
@@ -82,6 +107,19 @@ func NewAgent(ctx context.Context, config *Config) (*Agent, error) {
 		return nil, fmt.Errorf("error creando directorio de trabajo: %w", err)
 	}
 
+	// Directorio donde se generan y reciben los archivos de backup
+	if config.BackupDir == "" {
+		config.BackupDir = filepath.Join(config.WorkDir, "backups")
+	}
+	if err := os.MkdirAll(config.BackupDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creando directorio de backups: %w", err)
+	}
+
+	// Directorio de los certificados emitidos por el backend
+	if config.TLSDir == "" {
+		config.TLSDir = filepath.Join(config.WorkDir, ".aymc-tls")
+	}
+
 	// Inicializar executor
 	executor, err := NewExecutor(config.WorkDir)
 	if err != nil {
@@ -91,15 +129,35 @@ func NewAgent(ctx context.Context, config *Config) (*Agent, error) {
 	// Inicializar monitor de sistema
 	monitor := NewSystemMonitor()
 
+	// Inicializar almacén de estado
+	statePath := config.StateFile
+	if statePath == "" {
+		statePath = filepath.Join(config.WorkDir, defaultStateFileName)
+	}
+
 	agent := &Agent{
 		ctx:       ctx,
 		config:    config,
 		executor:  executor,
 		monitor:   monitor,
+		state:     NewStateStore(statePath),
+		events:    NewEventBus(),
+		metrics:   NewBroadcaster[*SystemMetrics](),
+		alerts:    NewBroadcaster[LogAlert](),
+		watcher:   NewLogAlertWatcher(),
 		servers:   make(map[string]*MinecraftServer),
+		restarts:  make(map[string]*restartState),
 		startTime: time.Now(),
 	}
 
+	executor.SetExitHandler(agent.handleProcessExit)
+	executor.SetLineHandler(agent.handleLogLine)
+
+	// Restaurar servidores de la ejecución anterior
+	if err := agent.restoreState(); err != nil {
+		return nil, fmt.Errorf("error restaurando estado: %w", err)
+	}
+
 	log.Printf("[INFO] Agente inicializado correctamente")
 	log.Printf("[INFO] Directorio de trabajo: %s", config.WorkDir)
 	log.Printf("[INFO] Max servidores: %d", config.MaxServers)
@@ -122,6 +180,25 @@ func LoadConfig(path string) (*Config, error) {
 	return &config, nil
 }
 
+// SaveConfig guarda la configuración en disco de forma atómica
+func SaveConfig(path string, config *Config) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Escribir a un temporal y renombrar para no dejar un archivo a medias
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
 // DefaultConfig retorna una configuración por defecto
 func DefaultConfig() *Config {
 	return &Config{
@@ -135,6 +212,7 @@ func DefaultConfig() *Config {
 		EnableMetrics:   true,
 		MetricsInterval: 5 * time.Second,
 		CustomEnv:       make(map[string]string),
+		RestartPolicy:   DefaultRestartPolicy(),
 	}
 }
 
@@ -169,7 +247,15 @@ func (a *Agent) processMetrics(metrics *SystemMetrics) {
 			metrics.CPUPercent, metrics.MemoryPercent, metrics.DiskPercent)
 	}
 
-	// Aquí se enviarían las métricas al backend vía gRPC
+	// Publicar para los streams de eventos hacia el backend
+	a.metrics.Publish(metrics)
+}
+
+// handleLogLine analiza cada línea de log en busca de errores
+func (a *Agent) handleLogLine(serverID, source, line string) {
+	if alert := a.watcher.Process(serverID, line); alert != nil {
+		a.alerts.Publish(*alert)
+	}
 }
 
 // GetServer obtiene un servidor por ID
@@ -185,6 +271,22 @@ func (a *Agent) GetServer(id string) (*MinecraftServer, error) {
 	return server, nil
 }
 
+// GetServerMetrics mide el uso de recursos del proceso de un servidor en
+// ejecución
+func (a *Agent) GetServerMetrics(id string) (*ProcessMetrics, error) {
+	server, err := a.GetServer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	pid, err := a.executor.GetPID(id)
+	if err != nil {
+		return nil, fmt.Errorf("servidor %s no está en ejecución: %w", id, err)
+	}
+
+	return a.monitor.GetProcessMetrics(pid, server.WorkDir)
+}
+
 // ListServers lista todos los servidores
 func (a *Agent) ListServers() []*MinecraftServer {
 	a.serversMux.RLock()
@@ -207,12 +309,15 @@ func (a *Agent) Shutdown() {
 	defer a.serversMux.Unlock()
 
 	for id, server := range a.servers {
+		a.cancelPendingRestartLocked(id)
 		if server.Status == StatusRunning {
 			log.Printf("[INFO] Deteniendo servidor: %s", id)
 			// TODO: implementar stopServer
 		}
 	}
 
+	a.saveStateLocked()
+
 	log.Printf("[INFO] Agente detenido")
 }
 
@@ -223,6 +328,27 @@ func (a *Agent) GetConfig() *Config {
 	return a.config
 }
 
+// ResolveBackupPath convierte una ruta de backup relativa en una ruta
+// dentro de BackupDir. Rechaza rutas que escapen del directorio.
+func (a *Agent) ResolveBackupPath(path string) (string, error) {
+	base, err := filepath.Abs(a.config.BackupDir)
+	if err != nil {
+		return "", fmt.Errorf("directorio de backups inválido: %w", err)
+	}
+
+	resolved := path
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(base, resolved)
+	}
+	resolved = filepath.Clean(resolved)
+
+	if !strings.HasPrefix(resolved, base+string(os.PathSeparator)) {
+		return "", fmt.Errorf("ruta fuera del directorio de backups: %s", path)
+	}
+
+	return resolved, nil
+}
+
 // GetExecutor retorna el executor
 func (a *Agent) GetExecutor() *Executor {
 	return a.executor
@@ -233,6 +359,21 @@ func (a *Agent) GetMonitor() *SystemMonitor {
 	return a.monitor
 }
 
+// Events retorna el bus de eventos de servidores
+func (a *Agent) Events() *EventBus {
+	return a.events
+}
+
+// Metrics retorna el broadcaster de métricas del sistema
+func (a *Agent) Metrics() *Broadcaster[*SystemMetrics] {
+	return a.metrics
+}
+
+// LogAlerts retorna el broadcaster de alertas de logs
+func (a *Agent) LogAlerts() *Broadcaster[LogAlert] {
+	return a.alerts
+}
+
 // GetStartTime retorna el tiempo de inicio del agente
 func (a *Agent) GetStartTime() time.Time {
 	return a.startTime
@@ -240,18 +381,20 @@ func (a *Agent) GetStartTime() time.Time {
 
 // Métodos de gestión de servidores
 
-// StartServer inicia un servidor de Minecraft
+// StartServer inicia un servidor de Minecraft. Si el servidor ya está
+// registrado y detenido se reutiliza su definición.
 func (a *Agent) StartServer(server *MinecraftServer) error {
 	a.serversMux.Lock()
 	defer a.serversMux.Unlock()
 
-	// Verificar si ya existe
-	if _, exists := a.servers[server.ID]; exists {
+	// Verificar si ya está en ejecución
+	existing, exists := a.servers[server.ID]
+	if exists && existing.isActive() {
 		return fmt.Errorf("el servidor ya existe: %s", server.ID)
 	}
 
 	// Verificar límite de servidores
-	if len(a.servers) >= a.config.MaxServers {
+	if a.activeServersLocked() >= a.config.MaxServers {
 		return fmt.Errorf("límite de servidores alcanzado: %d", a.config.MaxServers)
 	}
 
@@ -263,30 +406,72 @@ func (a *Agent) StartServer(server *MinecraftServer) error {
 	// Actualizar estado
 	server.Status = StatusRunning
 	server.StartTime = time.Now()
+	server.WorkDir = filepath.Join(a.config.WorkDir, server.ID)
+	if pid, err := a.executor.GetPID(server.ID); err == nil {
+		server.PID = pid
+	}
+	server.RestartCount = 0
 	a.servers[server.ID] = server
+	delete(a.restarts, server.ID) // Un inicio manual reinicia el historial de crashes
+	a.saveStateLocked()
+
+	a.events.Publish(ServerEvent{
+		ServerID: server.ID,
+		Type:     EventServerStarted,
+		Status:   server.Status,
+	})
 
 	log.Printf("[INFO] Servidor %s iniciado correctamente", server.ID)
 	return nil
 }
 
-// StopServer detiene un servidor de Minecraft
+// StopServer detiene un servidor de Minecraft. La definición se conserva
+// en el registro con estado detenido.
 func (a *Agent) StopServer(serverID string) error {
 	a.serversMux.Lock()
-	defer a.serversMux.Unlock()
-
 	server, exists := a.servers[serverID]
 	if !exists {
+		a.serversMux.Unlock()
 		return fmt.Errorf("servidor no encontrado: %s", serverID)
 	}
 
-	// Detener el servidor
+	// Servidor esperando un reinicio automático: basta con cancelarlo
+	if a.cancelPendingRestartLocked(serverID) {
+		server.Status = StatusStopped
+		server.PID = 0
+		a.saveStateLocked()
+		a.serversMux.Unlock()
+
+		a.events.Publish(ServerEvent{
+			ServerID: serverID,
+			Type:     EventServerStopped,
+			Status:   StatusStopped,
+			Reason:   "reinicio automático cancelado",
+		})
+		log.Printf("[INFO] Reinicio automático de %s cancelado", serverID)
+		return nil
+	}
+
+	previous := server.Status
+	server.Status = StatusStopping
+	a.saveStateLocked()
+	a.serversMux.Unlock()
+
+	// Detener el servidor (puede tardar hasta el timeout graceful)
 	if err := a.executor.StopServer(serverID); err != nil {
+		a.serversMux.Lock()
+		server.Status = previous
+		a.saveStateLocked()
+		a.serversMux.Unlock()
 		return fmt.Errorf("error deteniendo servidor: %w", err)
 	}
 
 	// Actualizar estado
+	a.serversMux.Lock()
 	server.Status = StatusStopped
-	delete(a.servers, serverID)
+	server.PID = 0
+	a.saveStateLocked()
+	a.serversMux.Unlock()
 
 	log.Printf("[INFO] Servidor %s detenido correctamente", serverID)
 	return nil
@@ -326,6 +511,80 @@ func (a *Agent) RestartServer(serverID string) error {
 	return nil
 }
 
+// restoreState carga el registro persistido y lo reconcilia con los
+// procesos que siguen en ejecución
+func (a *Agent) restoreState() error {
+	state, err := a.state.Load()
+	if err != nil {
+		return err
+	}
+
+	a.serversMux.Lock()
+	defer a.serversMux.Unlock()
+
+	for _, saved := range state.Servers {
+		server := saved.toServer()
+		if server.WorkDir == "" {
+			server.WorkDir = filepath.Join(a.config.WorkDir, server.ID)
+		}
+
+		if server.isActive() {
+			if matchesServerProcess(server.PID, server.WorkDir) &&
+				a.executor.AdoptProcess(server.ID, server.PID) == nil {
+				server.Status = StatusRunning
+				log.Printf("[INFO] Servidor %s sigue en ejecución (PID %d)", server.ID, server.PID)
+			} else {
+				log.Printf("[WARN] Servidor %s ya no está en ejecución (PID %d), marcado como detenido",
+					server.ID, server.PID)
+				server.Status = StatusStopped
+				server.PID = 0
+			}
+		} else {
+			server.PID = 0
+		}
+
+		a.servers[server.ID] = server
+	}
+
+	if len(state.Servers) > 0 {
+		log.Printf("[INFO] Restaurados %d servidores desde %s", len(state.Servers), a.state.Path())
+	}
+
+	a.saveStateLocked()
+	return nil
+}
+
+// saveStateLocked persiste el registro. Requiere tener serversMux tomado
+func (a *Agent) saveStateLocked() {
+	state := &AgentState{
+		AgentID: a.config.AgentID,
+		Servers: make([]ServerState, 0, len(a.servers)),
+	}
+	for _, server := range a.servers {
+		state.Servers = append(state.Servers, newServerState(server))
+	}
+
+	if err := a.state.Save(state); err != nil {
+		log.Printf("[WARN] Error guardando estado del agente: %v", err)
+	}
+}
+
+// activeServersLocked cuenta los servidores que no están detenidos
+func (a *Agent) activeServersLocked() int {
+	count := 0
+	for _, server := range a.servers {
+		if server.isActive() {
+			count++
+		}
+	}
+	return count
+}
+
+// isActive indica si el servidor tiene (o debería tener) un proceso vivo
+func (s *MinecraftServer) isActive() bool {
+	return s.Status != StatusStopped && s.Status != StatusCrashed
+}
+
 // generateAgentID genera un ID único para el agente
 func generateAgentID() string {
 	// TODO: Implementar generación segura de ID
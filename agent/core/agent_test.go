@@ -245,3 +245,41 @@ func TestGetServer(t *testing.T) {
 		t.Error("Debería haber error al obtener servidor inexistente")
 	}
 }
+
+func TestResolveBackupPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := &Config{
+		AgentID:    "test-agent",
+		WorkDir:    tmpDir,
+		MaxServers: 5,
+	}
+
+	agent, err := NewAgent(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Error creando agente: %v", err)
+	}
+
+	backupDir := filepath.Join(tmpDir, "backups")
+	if _, err := os.Stat(backupDir); err != nil {
+		t.Fatalf("Directorio de backups no fue creado: %v", err)
+	}
+
+	resolved, err := agent.ResolveBackupPath("server-1/backup.tar.gz")
+	if err != nil {
+		t.Fatalf("Error resolviendo ruta relativa: %v", err)
+	}
+	if resolved != filepath.Join(backupDir, "server-1", "backup.tar.gz") {
+		t.Errorf("Ruta inesperada: %s", resolved)
+	}
+
+	absolute := filepath.Join(backupDir, "backup.tar.gz")
+	if resolved, err := agent.ResolveBackupPath(absolute); err != nil || resolved != absolute {
+		t.Errorf("Ruta absoluta dentro del directorio rechazada: %s, %v", resolved, err)
+	}
+
+	for _, path := range []string{"../escape.tar.gz", "server-1/../../escape.tar.gz", "/etc/passwd", ""} {
+		if _, err := agent.ResolveBackupPath(path); err == nil {
+			t.Errorf("Ruta %q debería ser rechazada", path)
+		}
+	}
+}
@@ -1,23 +1,35 @@
 package core
 
 import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
+// installerTimeout tiempo máximo que puede tardar un instalador de Fabric,
+// Forge o NeoForge (descarga librerías y, en Forge, parchea el cliente)
+const installerTimeout = 20 * time.Minute
+
 // ServerDownloader maneja la descarga de JARs de servidores
 type ServerDownloader struct {
-	serverType string
-	version    string
-	outputDir  string
-	client     *http.Client
+	serverType   string
+	version      string
+	outputDir    string
+	client       *http.Client
+	endpoints    Endpoints
+	runInstaller installerRunner
 }
 
 // DownloadProgress representa el progreso de una descarga
@@ -32,179 +44,170 @@ type DownloadProgress struct {
 // ProgressCallback es llamado durante la descarga
 type ProgressCallback func(progress DownloadProgress)
 
+// DownloaderOption modifica la configuración de un ServerDownloader
+type DownloaderOption func(*ServerDownloader)
+
+// WithHTTPClient usa un cliente HTTP propio para las APIs y las descargas
+func WithHTTPClient(client *http.Client) DownloaderOption {
+	return func(sd *ServerDownloader) {
+		sd.client = client
+	}
+}
+
+// WithEndpoints sustituye las URLs de las APIs de cada plataforma (mirrors o
+// servidores de prueba)
+func WithEndpoints(endpoints Endpoints) DownloaderOption {
+	return func(sd *ServerDownloader) {
+		sd.endpoints = endpoints
+	}
+}
+
+// installerRunner ejecuta un instalador JAR dentro de dir y devuelve su salida
+type installerRunner func(ctx context.Context, dir, jar string, args []string) ([]byte, error)
+
 // NewServerDownloader crea un nuevo descargador de servidores
-func NewServerDownloader(serverType, version, outputDir string) *ServerDownloader {
-	return &ServerDownloader{
-		serverType: serverType,
+func NewServerDownloader(serverType, version, outputDir string, opts ...DownloaderOption) *ServerDownloader {
+	sd := &ServerDownloader{
+		serverType: strings.ToLower(serverType),
 		version:    version,
 		outputDir:  outputDir,
 		client: &http.Client{
 			Timeout: 30 * time.Minute,
 		},
+		endpoints:    DefaultEndpoints,
+		runInstaller: runJavaInstaller,
 	}
+
+	for _, opt := range opts {
+		opt(sd)
+	}
+
+	return sd
 }
 
-// PaperMCVersion representa una versión de PaperMC
-type PaperMCVersion struct {
-	ProjectID   string `json:"project_id"`
-	ProjectName string `json:"project_name"`
-	Version     string `json:"version"`
-	Builds      []int  `json:"builds"`
+// Checksum hash esperado de un archivo descargado
+type Checksum struct {
+	Algorithm string // md5, sha1, sha256 o sha512
+	Value     string
 }
 
-// PaperMCBuilds representa los builds de una versión
-type PaperMCBuilds struct {
-	ProjectID   string `json:"project_id"`
-	ProjectName string `json:"project_name"`
-	Version     string `json:"version"`
-	Builds      []struct {
-		Build     int       `json:"build"`
-		Time      time.Time `json:"time"`
-		Downloads struct {
-			Application struct {
-				Name   string `json:"name"`
-				SHA256 string `json:"sha256"`
-			} `json:"application"`
-		} `json:"downloads"`
-	} `json:"builds"`
+// InstallerSpec describe cómo ejecutar un instalador sin interfaz gráfica
+type InstallerSpec struct {
+	// Args argumentos tras "java -jar <instalador>", relativos al
+	// directorio de instalación
+	Args []string
+	// LaunchFiles candidatos a archivo de arranque tras la instalación, en
+	// orden de preferencia y relativos al directorio de instalación
+	LaunchFiles []string
 }
 
-// PurpurBuilds representa los builds de Purpur
-type PurpurBuilds struct {
-	Builds struct {
-		All    []string `json:"all"`
-		Latest string   `json:"latest"`
-	} `json:"builds"`
+// ServerArtifact archivo a descargar para un tipo y versión de servidor
+type ServerArtifact struct {
+	Version   string // versión resuelta ("latest" se sustituye por la real)
+	URL       string
+	FileName  string
+	Checksum  Checksum
+	Installer *InstallerSpec // nil si el archivo es directamente el servidor
 }
 
-// GetDownloadURL obtiene la URL de descarga según el tipo de servidor
-func (sd *ServerDownloader) GetDownloadURL() (string, string, error) {
+// Resolve consulta la API de la plataforma y obtiene el archivo a descargar
+func (sd *ServerDownloader) Resolve() (*ServerArtifact, error) {
 	switch sd.serverType {
 	case "paper":
 		return sd.getPaperURL()
+	case "velocity", "waterfall":
+		return sd.getPaperMCURL(sd.serverType)
 	case "purpur":
 		return sd.getPurpurURL()
-	case "spigot":
-		return "", "", fmt.Errorf("spigot requiere compilación con BuildTools")
 	case "vanilla":
 		return sd.getVanillaURL()
+	case "fabric":
+		return sd.getFabricURL()
+	case "forge":
+		return sd.getForgeURL()
+	case "neoforge":
+		return sd.getNeoForgeURL()
+	case "spigot":
+		return nil, fmt.Errorf("spigot requiere compilación con BuildTools")
 	default:
-		return "", "", fmt.Errorf("tipo de servidor no soportado: %s", sd.serverType)
+		return nil, fmt.Errorf("tipo de servidor no soportado: %s", sd.serverType)
 	}
 }
 
-// getPaperURL obtiene la URL de descarga de PaperMC
-func (sd *ServerDownloader) getPaperURL() (string, string, error) {
-	// API: https://api.papermc.io/v2/projects/paper/versions/{version}/builds
-	apiURL := fmt.Sprintf("https://api.papermc.io/v2/projects/paper/versions/%s/builds", sd.version)
-
-	resp, err := sd.client.Get(apiURL)
+// Download descarga el servidor y devuelve la ruta del archivo de arranque.
+// Para Fabric, Forge y NeoForge ejecuta además el instalador en
+// <outputDir>/<tipo>-<versión>, que pasa a ser el directorio del servidor.
+func (sd *ServerDownloader) Download(callback ProgressCallback) (string, error) {
+	artifact, err := sd.Resolve()
 	if err != nil {
-		return "", "", fmt.Errorf("error consultando API de PaperMC: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return "", "", fmt.Errorf("API de PaperMC retornó código %d", resp.StatusCode)
+		return "", err
 	}
 
-	var builds PaperMCBuilds
-	if err := json.NewDecoder(resp.Body).Decode(&builds); err != nil {
-		return "", "", fmt.Errorf("error decodificando respuesta de PaperMC: %w", err)
-	}
+	callback(DownloadProgress{
+		Message: fmt.Sprintf("Descargando %s %s...", sd.serverType, artifact.Version),
+	})
 
-	if len(builds.Builds) == 0 {
-		return "", "", fmt.Errorf("no se encontraron builds para la versión %s", sd.version)
+	// Crear directorio de salida si no existe
+	if err := os.MkdirAll(sd.outputDir, 0755); err != nil {
+		return "", fmt.Errorf("error creando directorio: %w", err)
 	}
 
-	// Obtener el build más reciente
-	latestBuild := builds.Builds[len(builds.Builds)-1]
-	jarName := latestBuild.Downloads.Application.Name
-	sha256Hash := latestBuild.Downloads.Application.SHA256
-
-	downloadURL := fmt.Sprintf(
-		"https://api.papermc.io/v2/projects/paper/versions/%s/builds/%d/downloads/%s",
-		sd.version,
-		latestBuild.Build,
-		jarName,
-	)
-
-	return downloadURL, sha256Hash, nil
-}
-
-// getPurpurURL obtiene la URL de descarga de Purpur
-func (sd *ServerDownloader) getPurpurURL() (string, string, error) {
-	// API: https://api.purpurmc.org/v2/purpur/{version}
-	apiURL := fmt.Sprintf("https://api.purpurmc.org/v2/purpur/%s", sd.version)
-
-	resp, err := sd.client.Get(apiURL)
-	if err != nil {
-		return "", "", fmt.Errorf("error consultando API de Purpur: %w", err)
+	fileName := artifact.FileName
+	if fileName == "" {
+		fileName = fmt.Sprintf("%s-%s.jar", sd.serverType, artifact.Version)
 	}
-	defer resp.Body.Close()
+	outputFile := filepath.Join(sd.outputDir, fileName)
 
-	if resp.StatusCode != 200 {
-		return "", "", fmt.Errorf("API de Purpur retornó código %d", resp.StatusCode)
+	if err := sd.downloadFile(artifact.URL, outputFile, artifact.Checksum, callback); err != nil {
+		return "", err
 	}
 
-	var builds PurpurBuilds
-	if err := json.NewDecoder(resp.Body).Decode(&builds); err != nil {
-		return "", "", fmt.Errorf("error decodificando respuesta de Purpur: %w", err)
+	if artifact.Installer == nil {
+		callback(DownloadProgress{
+			Message: fmt.Sprintf("✅ Servidor descargado: %s", outputFile),
+		})
+		return outputFile, nil
 	}
 
-	latestBuild := builds.Builds.Latest
-	downloadURL := fmt.Sprintf(
-		"https://api.purpurmc.org/v2/purpur/%s/%s/download",
-		sd.version,
-		latestBuild,
-	)
-
-	// Purpur no proporciona SHA256 en la API, lo calcularemos después de descargar
-	return downloadURL, "", nil
-}
-
-// getVanillaURL obtiene la URL de descarga de Minecraft Vanilla
-func (sd *ServerDownloader) getVanillaURL() (string, string, error) {
-	// Para vanilla necesitaríamos parsear el manifest de Mojang
-	// API: https://launchermeta.mojang.com/mc/game/version_manifest.json
-	return "", "", fmt.Errorf("descarga de vanilla no implementada aún")
-}
-
-// Download descarga el JAR del servidor
-func (sd *ServerDownloader) Download(callback ProgressCallback) (string, error) {
-	// Obtener URL de descarga
-	downloadURL, expectedSHA256, err := sd.GetDownloadURL()
+	installDir := filepath.Join(sd.outputDir, fmt.Sprintf("%s-%s", sd.serverType, artifact.Version))
+	launchFile, err := sd.install(outputFile, installDir, artifact.Installer, callback)
 	if err != nil {
 		return "", err
 	}
 
 	callback(DownloadProgress{
-		Message: fmt.Sprintf("Descargando %s %s...", sd.serverType, sd.version),
+		Message: fmt.Sprintf("✅ Servidor instalado: %s", launchFile),
 	})
 
-	// Crear directorio de salida si no existe
-	if err := os.MkdirAll(sd.outputDir, 0755); err != nil {
-		return "", fmt.Errorf("error creando directorio: %w", err)
-	}
+	return launchFile, nil
+}
 
-	// Nombre del archivo
-	outputFile := filepath.Join(sd.outputDir, fmt.Sprintf("%s-%s.jar", sd.serverType, sd.version))
+// downloadFile descarga url en outputFile informando del progreso y
+// verificando el checksum si se conoce
+func (sd *ServerDownloader) downloadFile(url, outputFile string, checksum Checksum, callback ProgressCallback) error {
+	var hasher hash.Hash
+	if checksum.Value != "" {
+		var err error
+		if hasher, err = newHasher(checksum.Algorithm); err != nil {
+			return err
+		}
+	}
 
 	// Iniciar descarga
-	resp, err := sd.client.Get(downloadURL)
+	resp, err := sd.client.Get(url)
 	if err != nil {
-		return "", fmt.Errorf("error iniciando descarga: %w", err)
+		return fmt.Errorf("error iniciando descarga: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("servidor retornó código %d", resp.StatusCode)
+		return fmt.Errorf("servidor retornó código %d", resp.StatusCode)
 	}
 
 	// Crear archivo de salida
 	out, err := os.Create(outputFile)
 	if err != nil {
-		return "", fmt.Errorf("error creando archivo: %w", err)
+		return fmt.Errorf("error creando archivo: %w", err)
 	}
 	defer out.Close()
 
@@ -215,18 +218,19 @@ func (sd *ServerDownloader) Download(callback ProgressCallback) (string, error)
 	lastUpdate := time.Now()
 
 	buffer := make([]byte, 32*1024) // 32KB buffer
-	hasher := sha256.New()
 
 	for {
 		n, err := resp.Body.Read(buffer)
 		if n > 0 {
 			// Escribir al archivo
 			if _, writeErr := out.Write(buffer[:n]); writeErr != nil {
-				return "", fmt.Errorf("error escribiendo archivo: %w", writeErr)
+				return fmt.Errorf("error escribiendo archivo: %w", writeErr)
 			}
 
 			// Actualizar hash
-			hasher.Write(buffer[:n])
+			if hasher != nil {
+				hasher.Write(buffer[:n])
+			}
 
 			downloaded += int64(n)
 
@@ -252,7 +256,7 @@ func (sd *ServerDownloader) Download(callback ProgressCallback) (string, error)
 			break
 		}
 		if err != nil {
-			return "", fmt.Errorf("error durante descarga: %w", err)
+			return fmt.Errorf("error durante descarga: %w", err)
 		}
 	}
 
@@ -264,23 +268,86 @@ func (sd *ServerDownloader) Download(callback ProgressCallback) (string, error)
 		Message:    "Descarga completada",
 	})
 
-	// Verificar SHA256 si está disponible
-	if expectedSHA256 != "" {
-		actualSHA256 := hex.EncodeToString(hasher.Sum(nil))
-		if actualSHA256 != expectedSHA256 {
+	// Verificar checksum si está disponible
+	if hasher != nil {
+		algorithm := strings.ToUpper(checksum.Algorithm)
+		actual := hex.EncodeToString(hasher.Sum(nil))
+		if !strings.EqualFold(actual, checksum.Value) {
+			out.Close()
 			os.Remove(outputFile)
-			return "", fmt.Errorf("checksum SHA256 no coincide. Esperado: %s, Obtenido: %s", expectedSHA256, actualSHA256)
+			return fmt.Errorf("checksum %s no coincide. Esperado: %s, Obtenido: %s", algorithm, checksum.Value, actual)
 		}
 		callback(DownloadProgress{
-			Message: "✅ Checksum SHA256 verificado",
+			Message: fmt.Sprintf("✅ Checksum %s verificado", algorithm),
 		})
 	}
 
+	return nil
+}
+
+// install ejecuta el instalador descargado y localiza el archivo de arranque
+func (sd *ServerDownloader) install(installerJar, installDir string, spec *InstallerSpec, callback ProgressCallback) (string, error) {
+	if err := os.MkdirAll(installDir, 0755); err != nil {
+		return "", fmt.Errorf("error creando directorio de instalación: %w", err)
+	}
+
+	jar, err := filepath.Abs(installerJar)
+	if err != nil {
+		return "", fmt.Errorf("error resolviendo ruta del instalador: %w", err)
+	}
+
 	callback(DownloadProgress{
-		Message: fmt.Sprintf("✅ Servidor descargado: %s", outputFile),
+		Message: fmt.Sprintf("Ejecutando instalador de %s (puede tardar varios minutos)...", sd.serverType),
 	})
 
-	return outputFile, nil
+	ctx, cancel := context.WithTimeout(context.Background(), installerTimeout)
+	defer cancel()
+
+	output, err := sd.runInstaller(ctx, installDir, jar, spec.Args)
+	if err != nil {
+		return "", fmt.Errorf("error ejecutando instalador: %w: %s", err, outputTail(output, 500))
+	}
+
+	for _, candidate := range spec.LaunchFiles {
+		path := filepath.Join(installDir, candidate)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("el instalador terminó pero no generó ninguno de: %s", strings.Join(spec.LaunchFiles, ", "))
+}
+
+// runJavaInstaller ejecuta "java -jar <jar> <args>" dentro de dir
+func runJavaInstaller(ctx context.Context, dir, jar string, args []string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "java", append([]string{"-jar", jar}, args...)...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// newHasher crea el hash correspondiente a un algoritmo de checksum
+func newHasher(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "md5":
+		return md5.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("algoritmo de checksum no soportado: %s", algorithm)
+	}
+}
+
+// outputTail devuelve los últimos bytes de la salida de un proceso
+func outputTail(output []byte, max int) string {
+	text := strings.TrimSpace(string(output))
+	if len(text) > max {
+		text = "..." + text[len(text)-max:]
+	}
+	return text
 }
 
 // formatBytes formatea bytes en formato legible
@@ -1,23 +1,134 @@
 package core
 
 import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
+var fakeJar = []byte("PK\x03\x04 fake minecraft server jar")
+
+// mockPlatforms servidor HTTP que imita las APIs de todas las plataformas
+type mockPlatforms struct {
+	*httptest.Server
+	mux *http.ServeMux
+}
+
+func newMockPlatforms(t *testing.T) *mockPlatforms {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return &mockPlatforms{Server: srv, mux: mux}
+}
+
+func (m *mockPlatforms) handle(path string, handler http.HandlerFunc) {
+	m.mux.HandleFunc(path, handler)
+}
+
+func (m *mockPlatforms) json(path string, v interface{}) {
+	m.handle(path, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(v)
+	})
+}
+
+func (m *mockPlatforms) file(path string, data []byte) {
+	m.handle(path, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	})
+}
+
+func (m *mockPlatforms) downloader(t *testing.T, serverType, version string) *ServerDownloader {
+	return NewServerDownloader(serverType, version, t.TempDir(),
+		WithHTTPClient(m.Client()),
+		WithEndpoints(Endpoints{
+			PaperMC:        m.URL + "/papermc",
+			Purpur:         m.URL + "/purpur",
+			MojangManifest: m.URL + "/mojang/version_manifest_v2.json",
+			FabricMeta:     m.URL + "/fabric",
+			ForgeFiles:     m.URL + "/forge-files",
+			ForgeMaven:     m.URL + "/forge-maven",
+			NeoForgeMaven:  m.URL + "/neoforge-maven",
+		}),
+	)
+}
+
+func sha1Hex(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func paperBuilds(sha string) map[string]interface{} {
+	build := func(number int, channel string) map[string]interface{} {
+		return map[string]interface{}{
+			"build":   number,
+			"channel": channel,
+			"downloads": map[string]interface{}{
+				"application": map[string]string{
+					"name":   fmt.Sprintf("paper-1.20.1-%d.jar", number),
+					"sha256": sha,
+				},
+			},
+		}
+	}
+	return map[string]interface{}{
+		"builds": []interface{}{build(195, "default"), build(196, "default"), build(197, "experimental")},
+	}
+}
+
+// fakeInstaller simula un instalador que crea los archivos indicados
+func fakeInstaller(calls *[]string, files ...string) installerRunner {
+	return func(ctx context.Context, dir, jar string, args []string) ([]byte, error) {
+		*calls = append(*calls, filepath.Base(jar)+" "+strings.Join(args, " "))
+		for _, file := range files {
+			path := filepath.Join(dir, file)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(path, []byte("launch"), 0644); err != nil {
+				return nil, err
+			}
+		}
+		return []byte("instalación completada"), nil
+	}
+}
+
+func noProgress(DownloadProgress) {}
+
 func TestNewServerDownloader(t *testing.T) {
 	downloader := NewServerDownloader("paper", "1.20.1", "/tmp")
-	
+
 	if downloader == nil {
 		t.Fatal("ServerDownloader es nil")
 	}
-	
+
 	if downloader.serverType != "paper" {
 		t.Errorf("serverType esperado 'paper', obtenido '%s'", downloader.serverType)
 	}
-	
+
 	if downloader.version != "1.20.1" {
 		t.Errorf("version esperada '1.20.1', obtenida '%s'", downloader.version)
 	}
+
+	if downloader.endpoints != DefaultEndpoints {
+		t.Error("Debería usar los endpoints oficiales por defecto")
+	}
 }
 
 func TestFormatBytes(t *testing.T) {
@@ -32,7 +143,7 @@ func TestFormatBytes(t *testing.T) {
 		{1048576, "1.0 MB"},
 		{1073741824, "1.0 GB"},
 	}
-	
+
 	for _, tt := range tests {
 		result := formatBytes(tt.bytes)
 		if result != tt.expected {
@@ -41,41 +152,387 @@ func TestFormatBytes(t *testing.T) {
 	}
 }
 
-func TestGetDownloadURL_UnsupportedType(t *testing.T) {
+func TestResolve_UnsupportedType(t *testing.T) {
 	downloader := NewServerDownloader("unknown", "1.20.1", "/tmp")
-	
-	_, _, err := downloader.GetDownloadURL()
+
+	_, err := downloader.Resolve()
 	if err == nil {
 		t.Error("Debería retornar error para tipo de servidor no soportado")
 	}
 }
 
-func TestGetDownloadURL_Spigot(t *testing.T) {
+func TestResolve_Spigot(t *testing.T) {
 	downloader := NewServerDownloader("spigot", "1.20.1", "/tmp")
-	
-	_, _, err := downloader.GetDownloadURL()
+
+	_, err := downloader.Resolve()
 	if err == nil {
 		t.Error("Spigot debería retornar error indicando que requiere BuildTools")
 	}
 }
 
 func TestDownload_WithMockServer(t *testing.T) {
-	t.Skip("Test requiere refactoring para soportar dependency injection")
-	
-	// TODO: Refactorizar ServerDownloader para permitir inyección de cliente HTTP
-	// y función GetDownloadURL para facilitar testing con mocks
+	mock := newMockPlatforms(t)
+	mock.json("/papermc/projects/paper/versions/1.20.1/builds", paperBuilds(sha256Hex(fakeJar)))
+	mock.file("/papermc/projects/paper/versions/1.20.1/builds/196/downloads/paper-1.20.1-196.jar", fakeJar)
+
+	downloader := mock.downloader(t, "paper", "1.20.1")
+
+	var messages []string
+	filePath, err := downloader.Download(func(p DownloadProgress) {
+		messages = append(messages, p.Message)
+	})
+	if err != nil {
+		t.Fatalf("Error descargando: %v", err)
+	}
+
+	if filepath.Base(filePath) != "paper-1.20.1.jar" {
+		t.Errorf("Nombre de archivo inesperado: %s", filePath)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Error leyendo archivo descargado: %v", err)
+	}
+	if string(data) != string(fakeJar) {
+		t.Error("Contenido del archivo descargado no coincide")
+	}
+
+	if !strings.Contains(strings.Join(messages, "\n"), "Checksum SHA256 verificado") {
+		t.Errorf("No se informó de la verificación del checksum: %v", messages)
+	}
 }
 
 func TestDownload_ServerError(t *testing.T) {
-	t.Skip("Test requiere refactoring para soportar dependency injection")
+	mock := newMockPlatforms(t)
+	mock.handle("/papermc/projects/paper/versions/1.20.1/builds", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := mock.downloader(t, "paper", "1.20.1").Download(noProgress)
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Debería retornar el código de error de la API, obtenido: %v", err)
+	}
+}
+
+func TestDownload_ChecksumMismatch(t *testing.T) {
+	mock := newMockPlatforms(t)
+	mock.json("/papermc/projects/paper/versions/1.20.1/builds", paperBuilds(sha256Hex([]byte("otro contenido"))))
+	mock.file("/papermc/projects/paper/versions/1.20.1/builds/196/downloads/paper-1.20.1-196.jar", fakeJar)
+
+	downloader := mock.downloader(t, "paper", "1.20.1")
+
+	_, err := downloader.Download(noProgress)
+	if err == nil || !strings.Contains(err.Error(), "checksum SHA256 no coincide") {
+		t.Fatalf("Debería detectar el checksum incorrecto, obtenido: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(downloader.outputDir, "paper-1.20.1.jar")); !os.IsNotExist(err) {
+		t.Error("El archivo corrupto debería eliminarse")
+	}
 }
 
 func TestDownloadWithRetry_Success(t *testing.T) {
-	t.Skip("Test requiere refactoring para soportar dependency injection")
+	mock := newMockPlatforms(t)
+	mock.json("/papermc/projects/paper/versions/1.20.1/builds", paperBuilds(sha256Hex(fakeJar)))
+
+	var attempts int32
+	mock.handle("/papermc/projects/paper/versions/1.20.1/builds/196/downloads/paper-1.20.1-196.jar", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(fakeJar)
+	})
+
+	filePath, err := mock.downloader(t, "paper", "1.20.1").DownloadWithRetry(3, noProgress)
+	if err != nil {
+		t.Fatalf("Debería completar la descarga en el segundo intento: %v", err)
+	}
+	if filePath == "" {
+		t.Error("Ruta de archivo vacía")
+	}
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("Intentos esperados 2, obtenidos %d", attempts)
+	}
 }
 
 func TestDownloadWithRetry_AllFail(t *testing.T) {
-	t.Skip("Test requiere refactoring para soportar dependency injection")
+	mock := newMockPlatforms(t)
+
+	var attempts int32
+	mock.handle("/papermc/projects/paper/versions/1.20.1/builds", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, err := mock.downloader(t, "paper", "1.20.1").DownloadWithRetry(2, noProgress)
+	if err == nil || !strings.Contains(err.Error(), "después de 2 intentos") {
+		t.Fatalf("Debería fallar tras agotar los reintentos, obtenido: %v", err)
+	}
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("Intentos esperados 2, obtenidos %d", attempts)
+	}
+}
+
+func TestResolve_Proxies(t *testing.T) {
+	mock := newMockPlatforms(t)
+	mock.json("/papermc/projects/velocity", map[string]interface{}{
+		"project_id": "velocity",
+		"versions":   []string{"3.2.0-SNAPSHOT", "3.3.0-SNAPSHOT"},
+	})
+	mock.json("/papermc/projects/velocity/versions/3.3.0-SNAPSHOT/builds", map[string]interface{}{
+		"builds": []interface{}{map[string]interface{}{
+			"build": 436, "channel": "default",
+			"downloads": map[string]interface{}{"application": map[string]string{"name": "velocity-3.3.0-SNAPSHOT-436.jar", "sha256": "abc"}},
+		}},
+	})
+	mock.json("/papermc/projects/waterfall/versions/1.20/builds", map[string]interface{}{
+		"builds": []interface{}{map[string]interface{}{
+			"build": 564, "channel": "default",
+			"downloads": map[string]interface{}{"application": map[string]string{"name": "waterfall-1.20-564.jar", "sha256": "def"}},
+		}},
+	})
+
+	velocity, err := mock.downloader(t, "velocity", "latest").Resolve()
+	if err != nil {
+		t.Fatalf("Error resolviendo Velocity: %v", err)
+	}
+	if velocity.Version != "3.3.0-SNAPSHOT" {
+		t.Errorf("Debería resolver la última versión, obtenida %s", velocity.Version)
+	}
+	if !strings.HasSuffix(velocity.URL, "/projects/velocity/versions/3.3.0-SNAPSHOT/builds/436/downloads/velocity-3.3.0-SNAPSHOT-436.jar") {
+		t.Errorf("URL de Velocity inesperada: %s", velocity.URL)
+	}
+
+	waterfall, err := mock.downloader(t, "Waterfall", "1.20").Resolve()
+	if err != nil {
+		t.Fatalf("Error resolviendo Waterfall: %v", err)
+	}
+	if waterfall.Checksum != (Checksum{Algorithm: "sha256", Value: "def"}) {
+		t.Errorf("Checksum de Waterfall inesperado: %+v", waterfall.Checksum)
+	}
+}
+
+func TestDownload_Purpur(t *testing.T) {
+	md5Sum := md5.Sum(fakeJar)
+
+	mock := newMockPlatforms(t)
+	mock.json("/purpur/purpur/1.20.1", map[string]interface{}{
+		"builds": map[string]interface{}{"all": []string{"2061", "2062"}, "latest": "2062"},
+	})
+	mock.json("/purpur/purpur/1.20.1/2062", map[string]string{
+		"build": "2062", "md5": hex.EncodeToString(md5Sum[:]), "result": "SUCCESS",
+	})
+	mock.file("/purpur/purpur/1.20.1/2062/download", fakeJar)
+
+	filePath, err := mock.downloader(t, "purpur", "1.20.1").Download(noProgress)
+	if err != nil {
+		t.Fatalf("Error descargando Purpur: %v", err)
+	}
+	if filepath.Base(filePath) != "purpur-1.20.1.jar" {
+		t.Errorf("Nombre de archivo inesperado: %s", filePath)
+	}
+}
+
+func TestDownload_Vanilla(t *testing.T) {
+	mock := newMockPlatforms(t)
+
+	versionJSON := []byte(fmt.Sprintf(`{"id":"1.21.1","downloads":{"server":{"sha1":"%s","size":%d,"url":"%s/mojang/server.jar"}}}`,
+		sha1Hex(fakeJar), len(fakeJar), mock.URL))
+
+	mock.json("/mojang/version_manifest_v2.json", map[string]interface{}{
+		"latest": map[string]string{"release": "1.21.1", "snapshot": "24w33a"},
+		"versions": []map[string]string{
+			{"id": "24w33a", "type": "snapshot", "url": mock.URL + "/mojang/24w33a.json", "sha1": "0000"},
+			{"id": "1.21.1", "type": "release", "url": mock.URL + "/mojang/1.21.1.json", "sha1": sha1Hex(versionJSON)},
+		},
+	})
+	mock.file("/mojang/1.21.1.json", versionJSON)
+	mock.file("/mojang/server.jar", fakeJar)
+
+	filePath, err := mock.downloader(t, "vanilla", "latest").Download(noProgress)
+	if err != nil {
+		t.Fatalf("Error descargando vanilla: %v", err)
+	}
+	if filepath.Base(filePath) != "vanilla-1.21.1.jar" {
+		t.Errorf("Debería resolver latest a la última release, obtenido %s", filePath)
+	}
+
+	// Metadatos alterados respecto al manifest
+	mock.file("/mojang/24w33a.json", versionJSON)
+	if _, err := mock.downloader(t, "vanilla", "24w33a").Resolve(); err == nil || !strings.Contains(err.Error(), "SHA1") {
+		t.Errorf("Debería rechazar metadatos con SHA1 incorrecto, obtenido: %v", err)
+	}
+
+	if _, err := mock.downloader(t, "vanilla", "1.0.0-inexistente").Resolve(); err == nil {
+		t.Error("Debería retornar error para una versión inexistente")
+	}
+}
+
+func TestDownload_Fabric(t *testing.T) {
+	mock := newMockPlatforms(t)
+	installerURL := mock.URL + "/fabric-maven/fabric-installer-1.0.1.jar"
+
+	mock.json("/fabric/versions/loader/1.20.1", []map[string]interface{}{
+		{"loader": map[string]interface{}{"version": "0.16.0-beta.1", "stable": false}},
+		{"loader": map[string]interface{}{"version": "0.15.11", "stable": true}},
+	})
+	mock.json("/fabric/versions/installer", []map[string]interface{}{
+		{"url": installerURL, "version": "1.0.1", "stable": true},
+	})
+	mock.file("/fabric-maven/fabric-installer-1.0.1.jar", fakeJar)
+	mock.file("/fabric-maven/fabric-installer-1.0.1.jar.sha1", []byte(sha1Hex(fakeJar)))
+
+	var calls []string
+	downloader := mock.downloader(t, "fabric", "1.20.1")
+	downloader.runInstaller = fakeInstaller(&calls, "fabric-server-launch.jar")
+
+	launchFile, err := downloader.Download(noProgress)
+	if err != nil {
+		t.Fatalf("Error instalando Fabric: %v", err)
+	}
+
+	if launchFile != filepath.Join(downloader.outputDir, "fabric-1.20.1", "fabric-server-launch.jar") {
+		t.Errorf("Archivo de arranque inesperado: %s", launchFile)
+	}
+
+	want := "fabric-installer-1.0.1.jar server -mcversion 1.20.1 -loader 0.15.11 -downloadMinecraft"
+	if len(calls) != 1 || calls[0] != want {
+		t.Errorf("Instalador ejecutado con %v, esperado %q", calls, want)
+	}
+
+	// Fabric meta devuelve una lista vacía para versiones no soportadas
+	mock.json("/fabric/versions/loader/1.2.5", []interface{}{})
+	if _, err := mock.downloader(t, "fabric", "1.2.5").Resolve(); err == nil {
+		t.Error("Debería retornar error para versiones sin loader")
+	}
+}
+
+func TestResolve_Forge(t *testing.T) {
+	mock := newMockPlatforms(t)
+	mock.json("/forge-files/promotions_slim.json", map[string]interface{}{
+		"promos": map[string]string{
+			"1.20.1-latest":      "47.3.0",
+			"1.20.1-recommended": "47.2.0",
+			"1.21.1-latest":      "52.0.10",
+		},
+	})
+	for _, version := range []string{"1.20.1-47.2.0", "1.21.1-52.0.10", "1.20.1-47.1.0"} {
+		mock.file(fmt.Sprintf("/forge-maven/net/minecraftforge/forge/%s/forge-%s-installer.jar.sha1", version, version),
+			[]byte(sha1Hex([]byte(version))+"  forge-installer.jar\n"))
+	}
+
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"1.20.1", "1.20.1-47.2.0"},        // recomendada
+		{"1.21.1", "1.21.1-52.0.10"},       // sin recomendada, usa la última
+		{"1.20.1-47.1.0", "1.20.1-47.1.0"}, // versión explícita
+	}
+
+	for _, tt := range tests {
+		artifact, err := mock.downloader(t, "forge", tt.version).Resolve()
+		if err != nil {
+			t.Fatalf("Error resolviendo Forge %s: %v", tt.version, err)
+		}
+		if artifact.Version != tt.expected {
+			t.Errorf("Forge %s resolvió %s, esperado %s", tt.version, artifact.Version, tt.expected)
+		}
+		if artifact.Checksum != (Checksum{Algorithm: "sha1", Value: sha1Hex([]byte(tt.expected))}) {
+			t.Errorf("Checksum de Forge %s inesperado: %+v", tt.version, artifact.Checksum)
+		}
+		if artifact.Installer == nil || strings.Join(artifact.Installer.Args, " ") != "--installServer ." {
+			t.Errorf("Instalador de Forge inesperado: %+v", artifact.Installer)
+		}
+	}
+
+	if _, err := mock.downloader(t, "forge", "1.19.9").Resolve(); err == nil {
+		t.Error("Debería retornar error para versiones sin builds de Forge")
+	}
+
+	// Sin archivo .sha1 no se puede verificar el instalador
+	if _, err := mock.downloader(t, "forge", "1.20.1-47.0.1").Resolve(); err == nil {
+		t.Error("Debería retornar error si falta el checksum del instalador")
+	}
+}
+
+func TestDownload_NeoForge(t *testing.T) {
+	mock := newMockPlatforms(t)
+	mock.file("/neoforge-maven/net/neoforged/neoforge/maven-metadata.xml", []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>net.neoforged</groupId>
+  <artifactId>neoforge</artifactId>
+  <versioning>
+    <versions>
+      <version>20.4.237</version>
+      <version>21.1.76</version>
+      <version>21.1.77</version>
+      <version>21.1.78-beta</version>
+      <version>21.2.0-beta</version>
+    </versions>
+  </versioning>
+</metadata>`))
+	mock.file("/neoforge-maven/net/neoforged/neoforge/21.1.77/neoforge-21.1.77-installer.jar", fakeJar)
+	mock.file("/neoforge-maven/net/neoforged/neoforge/21.1.77/neoforge-21.1.77-installer.jar.sha1", []byte(sha1Hex(fakeJar)))
+
+	argsFile := filepath.Join("libraries", "net", "neoforged", "neoforge", "21.1.77", argsFileName())
+
+	var calls []string
+	downloader := mock.downloader(t, "neoforge", "1.21.1")
+	downloader.runInstaller = fakeInstaller(&calls, argsFile)
+
+	launchFile, err := downloader.Download(noProgress)
+	if err != nil {
+		t.Fatalf("Error instalando NeoForge: %v", err)
+	}
+
+	if launchFile != filepath.Join(downloader.outputDir, "neoforge-21.1.77", argsFile) {
+		t.Errorf("Archivo de arranque inesperado: %s", launchFile)
+	}
+	if len(calls) != 1 || calls[0] != "neoforge-21.1.77-installer.jar --installServer ." {
+		t.Errorf("Instalador ejecutado con %v", calls)
+	}
+
+	// Un instalador que no genera el archivo de arranque es un error
+	downloader = mock.downloader(t, "neoforge", "21.1.77")
+	downloader.runInstaller = fakeInstaller(&calls)
+	if _, err := downloader.Download(noProgress); err == nil {
+		t.Error("Debería retornar error si la instalación no genera el archivo de arranque")
+	}
+
+	if _, err := mock.downloader(t, "neoforge", "1.20.1").Resolve(); err == nil {
+		t.Error("Debería retornar error para versiones sin builds de NeoForge")
+	}
+}
+
+func TestNeoForgePrefix(t *testing.T) {
+	tests := map[string]string{
+		"1.20.4": "20.4.",
+		"1.21":   "21.0.",
+		"1.21.1": "21.1.",
+	}
+	for minecraft, expected := range tests {
+		prefix, err := neoForgePrefix(minecraft)
+		if err != nil || prefix != expected {
+			t.Errorf("neoForgePrefix(%s) = %q, %v; esperado %q", minecraft, prefix, err, expected)
+		}
+	}
+
+	if _, err := neoForgePrefix("1.21.1.5"); err == nil {
+		t.Error("Debería rechazar versiones de Minecraft inválidas")
+	}
+
+	versions := []string{"21.1.1-beta", "21.1.2-beta", "21.2.0-beta"}
+	if got := latestNeoForge(versions, "21.1."); got != "21.1.2-beta" {
+		t.Errorf("Sin versiones estables debería usar la última beta, obtenido %s", got)
+	}
+}
+
+func TestNewHasher_Unsupported(t *testing.T) {
+	if _, err := newHasher("crc32"); err == nil {
+		t.Error("Debería rechazar algoritmos de checksum desconocidos")
+	}
 }
 
 // Test de integración con APIs reales (se salta en modo corto)
@@ -83,58 +540,58 @@ func TestGetPaperURL_Integration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
-	
+
 	downloader := NewServerDownloader("paper", "1.20.1", "/tmp")
-	
-	url, sha256, err := downloader.getPaperURL()
+
+	artifact, err := downloader.getPaperURL()
 	if err != nil {
 		t.Fatalf("Error obteniendo URL de Paper: %v", err)
 	}
-	
-	if url == "" {
+
+	if artifact.URL == "" {
 		t.Error("URL de descarga vacía")
 	}
-	
-	if sha256 == "" {
+
+	if artifact.Checksum.Value == "" {
 		t.Error("SHA256 vacío")
 	}
-	
-	t.Logf("URL: %s", url)
-	t.Logf("SHA256: %s", sha256)
+
+	t.Logf("URL: %s", artifact.URL)
+	t.Logf("SHA256: %s", artifact.Checksum.Value)
 }
 
 func TestGetPurpurURL_Integration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
-	
+
 	downloader := NewServerDownloader("purpur", "1.20.1", "/tmp")
-	
-	url, _, err := downloader.getPurpurURL()
+
+	artifact, err := downloader.getPurpurURL()
 	if err != nil {
 		t.Fatalf("Error obteniendo URL de Purpur: %v", err)
 	}
-	
-	if url == "" {
+
+	if artifact.URL == "" {
 		t.Error("URL de descarga vacía")
 	}
-	
-	t.Logf("URL: %s", url)
+
+	t.Logf("URL: %s", artifact.URL)
 }
 
 func TestDownload_RealPaper_Integration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
-	
+
 	// Solo ejecutar si la variable de entorno está configurada
 	// Ejemplo: TEST_REAL_DOWNLOAD=1 go test -run TestDownload_RealPaper_Integration
 	// if os.Getenv("TEST_REAL_DOWNLOAD") != "1" {
 	// 	t.Skip("TEST_REAL_DOWNLOAD no está configurado")
 	// }
-	
+
 	t.Skip("Test de descarga real requiere conexión a internet y tiempo")
-	
+
 	// Descomentar para test manual:
 	// tmpDir := t.TempDir()
 	// downloader := NewServerDownloader("paper", "1.20.1", tmpDir)
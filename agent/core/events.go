@@ -0,0 +1,113 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// ServerEventType tipo de evento de ciclo de vida de un servidor
+type ServerEventType string
+
+const (
+	EventServerStarted  ServerEventType = "started"
+	EventServerStopped  ServerEventType = "stopped"
+	EventServerCrashed  ServerEventType = "crashed"
+	EventRestartAttempt ServerEventType = "restart_attempt"
+	EventRestartFailed  ServerEventType = "restart_failed"
+	EventRestartLimit   ServerEventType = "restart_limit"
+)
+
+// ServerEvent evento emitido por el agente sobre un servidor
+type ServerEvent struct {
+	Timestamp   time.Time
+	ServerID    string
+	Type        ServerEventType
+	Status      ServerStatus
+	ExitCode    int
+	Attempt     int
+	MaxAttempts int
+	Backoff     time.Duration
+	Reason      string
+}
+
+// LogAlert alerta generada al detectar un error en los logs de un servidor
+type LogAlert struct {
+	Timestamp  time.Time
+	ServerID   string
+	Level      LogLevel
+	Message    string
+	ErrorType  string
+	Severity   int
+	Suggestion string
+	Plugin     string
+}
+
+// Broadcaster distribuye valores a múltiples suscriptores
+type Broadcaster[T any] struct {
+	subscribers map[int]chan T
+	nextID      int
+	mu          sync.RWMutex
+}
+
+// NewBroadcaster crea un nuevo broadcaster
+func NewBroadcaster[T any]() *Broadcaster[T] {
+	return &Broadcaster[T]{
+		subscribers: make(map[int]chan T),
+	}
+}
+
+// Subscribe registra un suscriptor y retorna su ID y canal
+func (b *Broadcaster[T]) Subscribe(buffer int) (int, <-chan T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+
+	ch := make(chan T, buffer)
+	b.subscribers[id] = ch
+	return id, ch
+}
+
+// Unsubscribe elimina un suscriptor y cierra su canal
+func (b *Broadcaster[T]) Unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ch, exists := b.subscribers[id]; exists {
+		close(ch)
+		delete(b.subscribers, id)
+	}
+}
+
+// Publish envía un valor a todos los suscriptores.
+// Los suscriptores lentos pierden valores en lugar de bloquear al agente.
+func (b *Broadcaster[T]) Publish(value T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- value:
+		default:
+		}
+	}
+}
+
+// EventBus distribuye eventos de servidores
+type EventBus struct {
+	*Broadcaster[ServerEvent]
+}
+
+// NewEventBus crea un nuevo bus de eventos
+func NewEventBus() *EventBus {
+	return &EventBus{NewBroadcaster[ServerEvent]()}
+}
+
+// Publish envía un evento completando su timestamp si no lo tiene
+func (b *EventBus) Publish(event ServerEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	b.Broadcaster.Publish(event)
+}
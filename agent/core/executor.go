@@ -9,7 +9,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -18,9 +20,19 @@ import (
 type Executor struct {
 	workDir   string
 	processes map[string]*Process
+	logs      map[string]*LogBroker // Persisten entre reinicios del proceso
 	mu        sync.RWMutex
+	onExit    ExitHandler
+	onLine    LineHandler
 }
 
+// LineHandler se invoca por cada línea de salida de un servidor
+type LineHandler func(serverID, source, line string)
+
+// ExitHandler se invoca cuando un proceso termina. stopRequested indica
+// si la salida fue solicitada mediante StopServer.
+type ExitHandler func(serverID string, exitCode int, stopRequested bool)
+
 // Process representa un proceso en ejecución
 type Process struct {
 	ID        string
@@ -29,9 +41,34 @@ type Process struct {
 	Stdout    io.ReadCloser
 	Stderr    io.ReadCloser
 	StartTime time.Time
-	LogChan   chan string
+	Logs      *LogBroker
 	ctx       context.Context
 	cancel    context.CancelFunc
+	done      chan struct{}
+	readers   sync.WaitGroup
+	stopping  atomic.Bool
+	osProcess *os.Process // Solo para procesos adoptados (sin Cmd)
+}
+
+// PID retorna el PID del proceso
+func (p *Process) PID() int {
+	if p.osProcess != nil {
+		return p.osProcess.Pid
+	}
+	if p.Cmd != nil && p.Cmd.Process != nil {
+		return p.Cmd.Process.Pid
+	}
+	return 0
+}
+
+// IsAdopted indica si el proceso fue adoptado tras un reinicio del agente
+func (p *Process) IsAdopted() bool {
+	return p.osProcess != nil
+}
+
+// Done retorna un canal que se cierra cuando el proceso termina
+func (p *Process) Done() <-chan struct{} {
+	return p.done
 }
 
 // NewExecutor crea un nuevo executor
@@ -43,9 +80,60 @@ func NewExecutor(workDir string) (*Executor, error) {
 	return &Executor{
 		workDir:   workDir,
 		processes: make(map[string]*Process),
+		logs:      make(map[string]*LogBroker),
 	}, nil
 }
 
+// SetExitHandler registra el callback de fin de proceso
+func (e *Executor) SetExitHandler(handler ExitHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onExit = handler
+}
+
+// SetLineHandler registra el callback de líneas de log
+func (e *Executor) SetLineHandler(handler LineHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onLine = handler
+}
+
+// AdoptProcess registra un proceso ya en ejecución que sobrevivió a un
+// reinicio del agente. No hay acceso a stdin/stdout, solo a señales.
+func (e *Executor) AdoptProcess(serverID string, pid int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.processes[serverID]; exists {
+		return fmt.Errorf("el servidor ya está en ejecución")
+	}
+
+	if !processAlive(pid) {
+		return fmt.Errorf("el proceso %d no está en ejecución", pid)
+	}
+
+	osProcess, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("error buscando proceso %d: %w", pid, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	process := &Process{
+		ID:        serverID,
+		StartTime: time.Now(),
+		Logs:      e.logBrokerLocked(serverID),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		osProcess: osProcess,
+	}
+
+	e.processes[serverID] = process
+	go e.watchAdoptedProcess(process)
+
+	log.Printf("[INFO] Proceso %d adoptado para servidor %s", pid, serverID)
+	return nil
+}
 // StartServer inicia un servidor de Minecraft
 func (e *Executor) StartServer(serverID string, config ServerConfig) error {
 	e.mu.Lock()
@@ -96,9 +184,10 @@ func (e *Executor) StartServer(serverID string, config ServerConfig) error {
 		Stdout:    stdout,
 		Stderr:    stderr,
 		StartTime: time.Now(),
-		LogChan:   make(chan string, 1000),
+		Logs:      e.logBrokerLocked(serverID),
 		ctx:       ctx,
 		cancel:    cancel,
+		done:      make(chan struct{}),
 	}
 
 	// Iniciar proceso
@@ -110,6 +199,7 @@ func (e *Executor) StartServer(serverID string, config ServerConfig) error {
 	e.processes[serverID] = process
 
 	// Iniciar captura de logs
+	process.readers.Add(2)
 	go e.captureLogs(process, stdout, "STDOUT")
 	go e.captureLogs(process, stderr, "STDERR")
 
@@ -123,18 +213,25 @@ func (e *Executor) StartServer(serverID string, config ServerConfig) error {
 
 // StopServer detiene un servidor
 func (e *Executor) StopServer(serverID string) error {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-
+	e.mu.RLock()
 	process, exists := e.processes[serverID]
+	e.mu.RUnlock()
+
 	if !exists {
 		return fmt.Errorf("servidor no encontrado: %s", serverID)
 	}
 
 	log.Printf("[INFO] Deteniendo servidor %s...", serverID)
 
-	// Intentar detener gracefully con comando "stop"
-	if _, err := process.Stdin.Write([]byte("stop\n")); err != nil {
+	process.stopping.Store(true)
+
+	if process.IsAdopted() {
+		// Sin stdin: el servidor guarda el mundo al recibir SIGTERM
+		if err := process.osProcess.Signal(syscall.SIGTERM); err != nil {
+			log.Printf("[WARN] Error enviando SIGTERM: %v", err)
+		}
+	} else if _, err := process.Stdin.Write([]byte("stop\n")); err != nil {
+		// Intentar detener gracefully con comando "stop"
 		log.Printf("[WARN] Error enviando comando stop: %v", err)
 	}
 
@@ -142,31 +239,50 @@ func (e *Executor) StopServer(serverID string) error {
 	gracefulTimer := time.NewTimer(30 * time.Second)
 	defer gracefulTimer.Stop()
 
-	done := make(chan struct{})
-	go func() {
-		process.Cmd.Wait()
-		close(done)
-	}()
-
 	select {
-	case <-done:
+	case <-process.done:
 		log.Printf("[INFO] Servidor %s detenido gracefully", serverID)
 	case <-gracefulTimer.C:
 		log.Printf("[WARN] Servidor %s no respondió, forzando detención...", serverID)
-		if err := process.Cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		if err := process.signal(syscall.SIGTERM); err != nil {
 			log.Printf("[ERROR] Error enviando SIGTERM: %v", err)
-			process.Cmd.Process.Kill()
+			process.kill()
+		}
+		select {
+		case <-process.done:
+		case <-time.After(10 * time.Second):
+			process.kill()
 		}
 	}
 
 	// Cancelar contexto y limpiar
 	process.cancel()
-	close(process.LogChan)
-	delete(e.processes, serverID)
+	e.mu.Lock()
+	if e.processes[serverID] == process {
+		delete(e.processes, serverID)
+	}
+	e.mu.Unlock()
 
 	return nil
 }
 
+// signal envía una señal al proceso
+func (p *Process) signal(sig os.Signal) error {
+	if p.osProcess != nil {
+		return p.osProcess.Signal(sig)
+	}
+	return p.Cmd.Process.Signal(sig)
+}
+
+// kill termina el proceso de forma forzada
+func (p *Process) kill() {
+	if p.osProcess != nil {
+		p.osProcess.Kill()
+		return
+	}
+	p.Cmd.Process.Kill()
+}
+
 // SendCommand envía un comando al servidor
 func (e *Executor) SendCommand(serverID string, command string) error {
 	e.mu.RLock()
@@ -177,6 +293,10 @@ func (e *Executor) SendCommand(serverID string, command string) error {
 		return fmt.Errorf("servidor no encontrado: %s", serverID)
 	}
 
+	if process.IsAdopted() {
+		return fmt.Errorf("el servidor %s fue adoptado y no tiene consola disponible", serverID)
+	}
+
 	if _, err := process.Stdin.Write([]byte(command + "\n")); err != nil {
 		return fmt.Errorf("error enviando comando: %w", err)
 	}
@@ -185,17 +305,38 @@ func (e *Executor) SendCommand(serverID string, command string) error {
 	return nil
 }
 
-// GetLogs retorna el canal de logs del servidor
-func (e *Executor) GetLogs(serverID string) (<-chan string, error) {
+// SubscribeLogs se suscribe a la salida de un servidor, retornando el
+// historial solicitado y un canal con las líneas nuevas
+func (e *Executor) SubscribeLogs(serverID string, opts LogReplayOptions) (*LogSubscription, error) {
+	broker, err := e.GetLogBroker(serverID)
+	if err != nil {
+		return nil, err
+	}
+	return broker.Subscribe(opts), nil
+}
+
+// GetLogBroker retorna el broker de logs de un servidor. Existe desde el
+// primer arranque y conserva el historial aunque el proceso termine.
+func (e *Executor) GetLogBroker(serverID string) (*LogBroker, error) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	process, exists := e.processes[serverID]
+	broker, exists := e.logs[serverID]
 	if !exists {
 		return nil, fmt.Errorf("servidor no encontrado: %s", serverID)
 	}
 
-	return process.LogChan, nil
+	return broker, nil
+}
+
+// logBrokerLocked obtiene o crea el broker de un servidor. Requiere e.mu tomado.
+func (e *Executor) logBrokerLocked(serverID string) *LogBroker {
+	broker, exists := e.logs[serverID]
+	if !exists {
+		broker = NewLogBroker(DefaultLogBufferSize)
+		e.logs[serverID] = broker
+	}
+	return broker
 }
 
 // buildJavaCommand construye los argumentos para el comando Java
@@ -230,26 +371,33 @@ func (e *Executor) buildJavaCommand(config ServerConfig) []string {
 		"-XX:MaxTenuringThreshold=1",
 	)
 
-	// Agregar JAR y argumentos finales
-	args = append(args, "-jar", config.JarFile, "nogui")
+	// Agregar JAR y argumentos finales. Forge y NeoForge modernos no generan
+	// un JAR ejecutable sino un archivo de argumentos (unix_args.txt)
+	if strings.HasSuffix(config.JarFile, "_args.txt") {
+		args = append(args, "@"+config.JarFile, "nogui")
+	} else {
+		args = append(args, "-jar", config.JarFile, "nogui")
+	}
 
 	return args
 }
 
 // captureLogs captura los logs del proceso
 func (e *Executor) captureLogs(process *Process, reader io.Reader, source string) {
+	defer process.readers.Done()
+
+	e.mu.RLock()
+	onLine := e.onLine
+	e.mu.RUnlock()
+
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		line := scanner.Text()
-		logEntry := fmt.Sprintf("[%s] %s", source, line)
-		
-		select {
-		case process.LogChan <- logEntry:
-		default:
-			// Canal lleno, descartar log antiguo
-			<-process.LogChan
-			process.LogChan <- logEntry
+		if onLine != nil {
+			onLine(process.ID, source, line)
 		}
+
+		process.Logs.Publish(source, line)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -259,6 +407,8 @@ func (e *Executor) captureLogs(process *Process, reader io.Reader, source string
 
 // monitorProcess monitorea el estado del proceso
 func (e *Executor) monitorProcess(process *Process) {
+	// Las lecturas de los pipes deben completarse antes de Wait
+	process.readers.Wait()
 	err := process.Cmd.Wait()
 	
 	exitCode := 0
@@ -270,12 +420,50 @@ func (e *Executor) monitorProcess(process *Process) {
 
 	log.Printf("[INFO] Proceso %s terminó con código %d", process.ID, exitCode)
 
+	e.finishProcess(process, exitCode)
+}
+
+// watchAdoptedProcess sondea un proceso adoptado hasta que termina.
+// Al no ser hijo del agente no es posible obtener su código de salida.
+func (e *Executor) watchAdoptedProcess(process *Process) {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !processAlive(process.PID()) {
+			break
+		}
+	}
+
+	log.Printf("[INFO] Proceso adoptado %s (PID %d) terminó", process.ID, process.PID())
+
+	e.finishProcess(process, -1)
+}
+
+// finishProcess limpia un proceso terminado y notifica al handler
+func (e *Executor) finishProcess(process *Process, exitCode int) {
+	close(process.done)
+
 	// Limpiar proceso de la lista
 	e.mu.Lock()
-	delete(e.processes, process.ID)
+	if e.processes[process.ID] == process {
+		delete(e.processes, process.ID)
+	}
+	handler := e.onExit
 	e.mu.Unlock()
 
-	// TODO: Implementar auto-restart si está configurado
+	if handler != nil {
+		handler(process.ID, exitCode, process.stopping.Load())
+	}
+}
+
+// processAlive verifica si existe un proceso con el PID indicado
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
 }
 
 // IsRunning verifica si un servidor está en ejecución
@@ -296,9 +484,10 @@ func (e *Executor) GetPID(serverID string) (int, error) {
 		return 0, fmt.Errorf("servidor no encontrado")
 	}
 
-	if process.Cmd.Process == nil {
+	pid := process.PID()
+	if pid == 0 {
 		return 0, fmt.Errorf("proceso no iniciado")
 	}
 
-	return process.Cmd.Process.Pid, nil
+	return pid, nil
 }
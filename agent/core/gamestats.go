@@ -0,0 +1,305 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultGameStatsInterval intervalo por defecto entre recolecciones de
+	// estadísticas de juego
+	DefaultGameStatsInterval = 30 * time.Second
+
+	// consoleStatsTimeout tiempo máximo de espera de la respuesta a "tps" y
+	// "mspt" en la consola
+	consoleStatsTimeout = 5 * time.Second
+
+	// defaultGamePort puerto de Minecraft si server.properties no indica otro
+	defaultGamePort = 25565
+)
+
+// GameStats estadísticas de juego de un servidor: jugadores (Server List
+// Ping y Query) y rendimiento del tick (consola de Paper)
+type GameStats struct {
+	Timestamp     time.Time     `json:"timestamp"`
+	Version       string        `json:"version"`
+	PlayersOnline int           `json:"players_online"`
+	MaxPlayers    int           `json:"max_players"`
+	Players       []string      `json:"players"`
+	Latency       time.Duration `json:"latency"`
+	TPS           float64       `json:"tps"`  // Media del último minuto; 0 si no disponible
+	MSPT          float64       `json:"mspt"` // Media del último minuto; 0 si no disponible
+}
+
+// StartGameStatsCollector recolecta periódicamente las estadísticas de juego
+// de los servidores en ejecución
+func (a *Agent) StartGameStatsCollector(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultGameStatsInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("[INFO] Recolección de estadísticas de juego iniciada (interval: %v)", interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.collectAllGameStats(ctx, interval)
+		}
+	}
+}
+
+// collectAllGameStats recolecta en paralelo las estadísticas de todos los
+// servidores en ejecución. Los servidores detenidos o que no responden
+// pierden sus estadísticas anteriores.
+func (a *Agent) collectAllGameStats(ctx context.Context, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, server := range a.ListServers() {
+		if server.Status != StatusRunning {
+			a.gameStats.Delete(server.ID)
+			continue
+		}
+
+		wg.Add(1)
+		go func(server *MinecraftServer) {
+			defer wg.Done()
+
+			collectCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			stats, err := a.CollectGameStats(collectCtx, server.ID)
+			if err != nil {
+				a.gameStats.Delete(server.ID)
+				if a.config.LogLevel == "debug" {
+					log.Printf("[DEBUG] Sin estadísticas de juego de %s: %v", server.ID, err)
+				}
+				return
+			}
+			a.gameStats.Store(server.ID, stats)
+		}(server)
+	}
+	wg.Wait()
+}
+
+// GetGameStats retorna las últimas estadísticas de juego recolectadas de un
+// servidor, o nil si no hay
+func (a *Agent) GetGameStats(serverID string) *GameStats {
+	if stats, ok := a.gameStats.Load(serverID); ok {
+		return stats.(*GameStats)
+	}
+	return nil
+}
+
+// CollectGameStats consulta un servidor en ejecución. Los jugadores se
+// obtienen con Server List Ping y, si enable-query está activo, con Query
+// (que da la lista completa). En Paper y Purpur además se ejecutan "tps" y
+// "mspt" en la consola y se lee la respuesta del log.
+func (a *Agent) CollectGameStats(ctx context.Context, serverID string) (*GameStats, error) {
+	server, err := a.GetServer(serverID)
+	if err != nil {
+		return nil, err
+	}
+	if server.Status != StatusRunning {
+		return nil, fmt.Errorf("servidor %s no está en ejecución", serverID)
+	}
+
+	props, err := readServerProperties(filepath.Join(server.WorkDir, "server.properties"))
+	if err != nil {
+		return nil, err
+	}
+
+	host := props["server-ip"]
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	port := server.Port
+	if value, err := strconv.Atoi(props["server-port"]); err == nil {
+		port = value
+	}
+	if port == 0 {
+		port = defaultGamePort
+	}
+
+	stats := &GameStats{Timestamp: time.Now()}
+	answered := false
+	var errs []error
+
+	ping, err := PingServer(ctx, net.JoinHostPort(host, strconv.Itoa(port)))
+	if err == nil {
+		stats.Version = ping.Version
+		stats.PlayersOnline = ping.PlayersOnline
+		stats.MaxPlayers = ping.MaxPlayers
+		stats.Players = ping.Players
+		stats.Latency = ping.Latency
+		answered = true
+	} else {
+		errs = append(errs, fmt.Errorf("server list ping: %w", err))
+	}
+
+	if props["enable-query"] == "true" {
+		queryPort := port
+		if value, err := strconv.Atoi(props["query.port"]); err == nil {
+			queryPort = value
+		}
+		query, err := QueryServer(ctx, net.JoinHostPort(host, strconv.Itoa(queryPort)))
+		if err == nil {
+			stats.PlayersOnline = query.PlayersOnline
+			stats.MaxPlayers = query.MaxPlayers
+			stats.Players = query.Players
+			if stats.Version == "" {
+				stats.Version = query.Version
+			}
+			answered = true
+		} else {
+			errs = append(errs, fmt.Errorf("query: %w", err))
+		}
+	}
+
+	if supportsConsoleStats(server.Type) {
+		tps, mspt, err := a.consoleStats(ctx, serverID)
+		if err == nil {
+			stats.TPS, stats.MSPT = tps, mspt
+			answered = true
+		} else {
+			errs = append(errs, fmt.Errorf("consola: %w", err))
+		}
+	}
+
+	// Con cualquier fuente que haya respondido hay algo que mostrar
+	if !answered {
+		return nil, errors.Join(errs...)
+	}
+
+	return stats, nil
+}
+
+// supportsConsoleStats indica si el software del servidor tiene los comandos
+// "tps" y "mspt"
+func supportsConsoleStats(serverType string) bool {
+	switch strings.ToLower(serverType) {
+	case "paper", "purpur":
+		return true
+	}
+	return false
+}
+
+// consoleStats ejecuta "tps" y "mspt" en la consola del servidor y lee las
+// respuestas del log. Si "mspt" no existe (Paper antiguo) se retorna solo
+// el TPS.
+func (a *Agent) consoleStats(ctx context.Context, serverID string) (tps, mspt float64, err error) {
+	// Suscribirse antes de enviar comandos para no perder la respuesta
+	sub, err := a.executor.SubscribeLogs(serverID, LogReplayOptions{NoHistory: true})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer sub.Close()
+
+	parser := NewLogParser()
+
+	if err := a.executor.SendCommand(serverID, "tps"); err != nil {
+		return 0, 0, err
+	}
+	err = waitForConsole(ctx, sub, func(line string) (bool, error) {
+		if value, ok := parser.ParseTPS(line); ok {
+			tps = value
+			return true, nil
+		}
+		if parser.IsUnknownCommand(line) {
+			return false, fmt.Errorf("el servidor no tiene el comando tps")
+		}
+		return false, nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := a.executor.SendCommand(serverID, "mspt"); err != nil {
+		return tps, 0, nil
+	}
+	headerSeen := false
+	waitForConsole(ctx, sub, func(line string) (bool, error) {
+		if parser.IsMSPTHeader(line) {
+			headerSeen = true
+			return false, nil
+		}
+		if headerSeen {
+			if value, ok := parser.ParseMSPT(line); ok {
+				mspt = value
+				return true, nil
+			}
+		}
+		if parser.IsUnknownCommand(line) {
+			return false, errors.New("el servidor no tiene el comando mspt")
+		}
+		return false, nil
+	})
+
+	return tps, mspt, nil
+}
+
+// waitForConsole lee líneas de log hasta que match indica que encontró la
+// respuesta o retorna un error
+func waitForConsole(ctx context.Context, sub *LogSubscription, match func(line string) (bool, error)) error {
+	timer := time.NewTimer(consoleStatsTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case line, ok := <-sub.C:
+			if !ok {
+				return errors.New("la suscripción de logs se cerró")
+			}
+			if done, err := match(line.Text); done || err != nil {
+				return err
+			}
+		case <-timer.C:
+			return errors.New("tiempo agotado esperando la respuesta de la consola")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// readServerProperties lee server.properties. Un archivo inexistente
+// (servidor aún sin generar) equivale a uno vacío.
+func readServerProperties(path string) (map[string]string, error) {
+	props := make(map[string]string)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return props, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		props[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return props, scanner.Err()
+}
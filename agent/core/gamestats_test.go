@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePaperScript simula la consola de Paper respondiendo a "tps" y "mspt"
+const fakePaperScript = `#!/bin/sh
+while IFS= read -r line; do
+  case "$line" in
+    tps) echo "[12:00:00 INFO]: §6TPS from last 1m, 5m, 15m: §a19.5, §a*20.0, §a20.0" ;;
+    mspt)
+      echo "[12:00:00 INFO]: §6Server tick times §e(§7avg§e/§7min§e/§7max§e)§6 from last 5s§7,§6 10s§7,§6 1m§e:"
+      echo "[12:00:00 INFO]: §6◴ §a1.0§7/§a0.5§7/§a2.0§7, §a1.1§7/§a0.5§7/§a2.0§7, §a12.3§7/§a0.4§7/§a40.0" ;;
+    stop) exit 0 ;;
+  esac
+done
+`
+
+// newGameStatsTestAgent inicia un servidor Paper falso cuyo server.properties
+// apunta a los servidores de estado y query indicados
+func newGameStatsTestAgent(t *testing.T, properties string) *Agent {
+	t.Helper()
+
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "java"), []byte(fakePaperScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	agent, err := NewAgent(context.Background(), &Config{WorkDir: t.TempDir(), MaxServers: 5})
+	if err != nil {
+		t.Fatalf("Error creando agente: %v", err)
+	}
+
+	serverDir := filepath.Join(agent.config.WorkDir, "srv")
+	os.MkdirAll(serverDir, 0755)
+	if err := os.WriteFile(filepath.Join(serverDir, "server.properties"), []byte(properties), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := agent.executor.StartServer("srv", ServerConfig{JarFile: "server.jar"}); err != nil {
+		t.Fatalf("Error iniciando servidor falso: %v", err)
+	}
+	t.Cleanup(func() {
+		agent.executor.StopServer("srv")
+
+		// El manejador de salida guarda el estado en WorkDir después de que
+		// StopServer retorne; esperarlo antes de borrar el directorio
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			agent.serversMux.RLock()
+			stopped := agent.servers["srv"].Status == StatusStopped
+			agent.serversMux.RUnlock()
+			if stopped {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	agent.serversMux.Lock()
+	agent.servers["srv"] = &MinecraftServer{ID: "srv", Type: "paper", Status: StatusRunning, WorkDir: serverDir}
+	agent.serversMux.Unlock()
+
+	return agent
+}
+
+func TestCollectGameStats(t *testing.T) {
+	status := startFakeStatusServer(t, `{"version":{"name":"Paper 1.21.1","protocol":767},"players":{"max":20,"online":2,"sample":[{"name":"Steve"}]}}`)
+	query := startFakeQueryServer(t, "Steve", "Alex")
+	_, statusPort, _ := net.SplitHostPort(status)
+	_, queryPort, _ := net.SplitHostPort(query)
+
+	agent := newGameStatsTestAgent(t, fmt.Sprintf("#Minecraft server properties\nserver-port=%s\nenable-query=true\nquery.port=%s\n", statusPort, queryPort))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stats, err := agent.CollectGameStats(ctx, "srv")
+	if err != nil {
+		t.Fatalf("CollectGameStats() error = %v", err)
+	}
+
+	if stats.Version != "Paper 1.21.1" {
+		t.Errorf("Version = %q", stats.Version)
+	}
+	// Query da la lista completa, la muestra del ping solo uno
+	if stats.PlayersOnline != 2 || stats.MaxPlayers != 50 || strings.Join(stats.Players, ",") != "Steve,Alex" {
+		t.Errorf("jugadores = %d/%d %v", stats.PlayersOnline, stats.MaxPlayers, stats.Players)
+	}
+	if stats.TPS != 19.5 || stats.MSPT != 12.3 {
+		t.Errorf("TPS = %v, MSPT = %v; se esperaba 19.5 y 12.3", stats.TPS, stats.MSPT)
+	}
+
+	agent.collectAllGameStats(ctx, 5*time.Second)
+	if cached := agent.GetGameStats("srv"); cached == nil || cached.TPS != 19.5 {
+		t.Errorf("GetGameStats() = %+v", cached)
+	}
+}
+
+func TestCollectGameStatsConsoleOnly(t *testing.T) {
+	// Sin nada escuchando en el puerto solo responde la consola
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	agent := newGameStatsTestAgent(t, "server-port="+port+"\n")
+
+	stats, err := agent.CollectGameStats(context.Background(), "srv")
+	if err != nil {
+		t.Fatalf("CollectGameStats() error = %v", err)
+	}
+	if stats.TPS != 19.5 || stats.MaxPlayers != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestReadServerProperties(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.properties")
+	os.WriteFile(path, []byte("#comentario\nserver-port = 25570\nmotd=A=B\n\nenable-query=true\n"), 0644)
+
+	props, err := readServerProperties(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props["server-port"] != "25570" || props["motd"] != "A=B" || props["enable-query"] != "true" {
+		t.Errorf("props = %v", props)
+	}
+
+	props, err = readServerProperties(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(props) != 0 {
+		t.Errorf("archivo inexistente: %v, %v", props, err)
+	}
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// logAlertCooldown tiempo mínimo entre alertas iguales de un mismo servidor
+const logAlertCooldown = 30 * time.Second
+
+// LogAlertWatcher analiza las líneas de log y genera alertas para errores
+type LogAlertWatcher struct {
+	parser   *LogParser
+	detector *ErrorDetector
+	cooldown time.Duration
+	lastSent map[string]time.Time
+	mu       sync.Mutex
+}
+
+// NewLogAlertWatcher crea un nuevo watcher de alertas
+func NewLogAlertWatcher() *LogAlertWatcher {
+	return &LogAlertWatcher{
+		parser:   NewLogParser(),
+		detector: NewErrorDetector(),
+		cooldown: logAlertCooldown,
+		lastSent: make(map[string]time.Time),
+	}
+}
+
+// Process analiza una línea y retorna una alerta si corresponde.
+// Las alertas repetidas (mismo servidor y tipo) se suprimen durante el cooldown.
+func (w *LogAlertWatcher) Process(serverID, line string) *LogAlert {
+	entry := w.parser.ParseLog(line)
+	pattern := w.detector.DetectError(entry)
+
+	if pattern == nil && !entry.IsError() {
+		return nil
+	}
+
+	alert := &LogAlert{
+		Timestamp: time.Now(),
+		ServerID:  serverID,
+		Level:     entry.Level,
+		Message:   entry.Message,
+		ErrorType: entry.ErrorType,
+		Severity:  entry.GetSeverity(),
+		Plugin:    entry.Plugin,
+	}
+
+	if pattern != nil {
+		alert.ErrorType = pattern.ErrorType
+		alert.Severity = pattern.Severity
+		alert.Suggestion = pattern.Suggestion
+		if pattern.Plugin != "" {
+			alert.Plugin = pattern.Plugin
+		}
+	}
+
+	key := serverID + "|" + alert.ErrorType + "|" + string(alert.Level)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if last, exists := w.lastSent[key]; exists && time.Since(last) < w.cooldown {
+		return nil
+	}
+	w.lastSent[key] = alert.Timestamp
+
+	return alert
+}
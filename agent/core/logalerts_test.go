@@ -0,0 +1,58 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogAlertWatcherDetectsKnownError(t *testing.T) {
+	watcher := NewLogAlertWatcher()
+
+	alert := watcher.Process("srv", "[12:00:00] [Server thread/ERROR]: **** FAILED TO BIND TO PORT! Failed to bind to port")
+	if alert == nil {
+		t.Fatal("Se esperaba una alerta para PORT_IN_USE")
+	}
+
+	if alert.ErrorType != "PORT_IN_USE" {
+		t.Errorf("ErrorType esperado PORT_IN_USE, obtenido %s", alert.ErrorType)
+	}
+	if alert.Severity != 5 {
+		t.Errorf("Severidad esperada 5, obtenida %d", alert.Severity)
+	}
+	if alert.Suggestion == "" {
+		t.Error("La alerta debería incluir una sugerencia")
+	}
+	if alert.ServerID != "srv" {
+		t.Errorf("ServerID esperado 'srv', obtenido '%s'", alert.ServerID)
+	}
+}
+
+func TestLogAlertWatcherIgnoresInfo(t *testing.T) {
+	watcher := NewLogAlertWatcher()
+
+	if alert := watcher.Process("srv", "[12:00:00] [Server thread/INFO]: Done (3.2s)! For help, type \"help\""); alert != nil {
+		t.Errorf("No se esperaba alerta para una línea INFO: %+v", alert)
+	}
+}
+
+func TestLogAlertWatcherCooldown(t *testing.T) {
+	watcher := NewLogAlertWatcher()
+	line := "[12:00:00] [Server thread/ERROR]: java.lang.OutOfMemoryError: Java heap space"
+
+	if watcher.Process("srv", line) == nil {
+		t.Fatal("La primera alerta no debería suprimirse")
+	}
+	if watcher.Process("srv", line) != nil {
+		t.Error("Una alerta repetida dentro del cooldown debería suprimirse")
+	}
+	if watcher.Process("other", line) == nil {
+		t.Error("El cooldown es por servidor")
+	}
+
+	// Simular que expiró el cooldown
+	watcher.cooldown = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if watcher.Process("srv", line) == nil {
+		t.Error("Tras el cooldown la alerta debería emitirse de nuevo")
+	}
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// LogFoldDelay tiempo que se espera por más líneas de stack trace antes
+	// de emitir una entrada pendiente
+	LogFoldDelay = 200 * time.Millisecond
+
+	// maxStackTraceLines límite de líneas agrupadas en una misma entrada
+	maxStackTraceLines = 256
+)
+
+// exceptionHeadPattern línea de excepción sin prefijo de log
+// (ej: "java.lang.NullPointerException: mensaje")
+var exceptionHeadPattern = regexp.MustCompile(`^[A-Za-z_$][\w$]*(?:\.[\w$]+)+(?:Exception|Error|Throwable)\b`)
+
+// StructuredLogEntry entrada de log parseada, con el stack trace que la
+// sigue agrupado en la misma entrada
+type StructuredLogEntry struct {
+	Sequence   uint64 // Secuencia de la última línea incluida
+	Timestamp  time.Time
+	Stream     string // STDOUT, STDERR
+	Level      LogLevel
+	Source     string
+	Thread     string
+	Message    string
+	Plugin     string
+	ErrorType  string
+	Severity   int
+	Suggestion string
+	StackTrace []string
+}
+
+// LogAssembler convierte las líneas crudas de un servidor en entradas
+// estructuradas. Las líneas de stack trace se agregan a la entrada
+// anterior, por lo que cada entrada solo se emite cuando llega la
+// siguiente o al llamar a Flush.
+type LogAssembler struct {
+	parser   *LogParser
+	detector *ErrorDetector
+	pending  *StructuredLogEntry
+	captured time.Time
+}
+
+// NewLogAssembler crea un nuevo ensamblador de logs
+func NewLogAssembler() *LogAssembler {
+	return &LogAssembler{
+		parser:   NewLogParser(),
+		detector: NewErrorDetector(),
+	}
+}
+
+// Add procesa una línea. Retorna la entrada anterior si la línea inicia
+// una nueva, o nil si la línea se agrupó o no había nada pendiente.
+func (a *LogAssembler) Add(line LogLine) *StructuredLogEntry {
+	if a.pending != nil && isContinuationLine(line.Text) {
+		if len(a.pending.StackTrace) < maxStackTraceLines {
+			a.pending.StackTrace = append(a.pending.StackTrace, strings.TrimRight(line.Text, "\r"))
+		}
+		a.pending.Sequence = line.Sequence
+		return nil
+	}
+
+	completed := a.Flush()
+
+	a.pending = &StructuredLogEntry{
+		Sequence: line.Sequence,
+		Stream:   line.Source,
+		Message:  line.Text,
+	}
+	a.captured = line.Timestamp
+
+	return completed
+}
+
+// HasPending indica si hay una entrada esperando más líneas
+func (a *LogAssembler) HasPending() bool {
+	return a.pending != nil
+}
+
+// Flush completa y retorna la entrada pendiente (nil si no hay)
+func (a *LogAssembler) Flush() *StructuredLogEntry {
+	if a.pending == nil {
+		return nil
+	}
+
+	entry := a.pending
+	a.pending = nil
+	a.complete(entry)
+
+	return entry
+}
+
+// complete parsea la entrada y busca errores conocidos, incluyendo las
+// excepciones del stack trace
+func (a *LogAssembler) complete(entry *StructuredLogEntry) {
+	parsed := a.parser.ParseLog(entry.Message)
+
+	entry.Source = parsed.Source
+	entry.Thread = parsed.Thread
+	entry.Plugin = parsed.Plugin
+	entry.Timestamp = a.captured
+	if a.parser.timestampPattern.MatchString(entry.Message) {
+		entry.Timestamp = anchorLogTime(parsed.Timestamp, a.captured)
+	}
+
+	pattern := a.detector.DetectError(parsed)
+
+	for _, traceLine := range entry.StackTrace {
+		trimmed := strings.TrimSpace(traceLine)
+		if strings.HasPrefix(trimmed, "at ") || strings.HasPrefix(trimmed, "...") {
+			continue
+		}
+
+		traceEntry := a.parser.ParseLog(trimmed)
+		if traceEntry.IsException && parsed.ErrorType == "" {
+			parsed.ErrorType = traceEntry.ErrorType
+		}
+		if pattern == nil {
+			pattern = a.detector.DetectError(traceEntry)
+		}
+	}
+
+	// Un stack trace implica error aunque la cabecera no lo indique
+	if parsed.ErrorType != "" && !parsed.IsError() {
+		parsed.Level = LogLevelError
+	}
+
+	entry.Level = parsed.Level
+	entry.ErrorType = parsed.ErrorType
+	entry.Severity = parsed.GetSeverity()
+
+	if pattern != nil {
+		entry.ErrorType = pattern.ErrorType
+		entry.Severity = pattern.Severity
+		entry.Suggestion = pattern.Suggestion
+		if pattern.Plugin != "" {
+			entry.Plugin = pattern.Plugin
+		}
+	}
+}
+
+// isContinuationLine indica si una línea pertenece a la entrada anterior
+// (frames, "Caused by", excepciones sin prefijo o líneas indentadas)
+func isContinuationLine(text string) bool {
+	if text == "" || strings.HasPrefix(text, "[") {
+		return false
+	}
+
+	if text[0] == '\t' || text[0] == ' ' {
+		return strings.TrimSpace(text) != ""
+	}
+
+	return strings.HasPrefix(text, "at ") ||
+		strings.HasPrefix(text, "Caused by:") ||
+		strings.HasPrefix(text, "Suppressed:") ||
+		exceptionHeadPattern.MatchString(text)
+}
+
+// anchorLogTime combina la hora impresa en el log (HH:MM:SS) con la fecha
+// en que se capturó la línea. Si el resultado queda en el futuro, la línea
+// se escribió antes de medianoche.
+func anchorLogTime(logged, captured time.Time) time.Time {
+	captured = captured.Local()
+	anchored := time.Date(captured.Year(), captured.Month(), captured.Day(),
+		logged.Hour(), logged.Minute(), logged.Second(), 0, time.Local)
+
+	if anchored.Sub(captured) > time.Hour {
+		anchored = anchored.AddDate(0, 0, -1)
+	}
+
+	return anchored
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func assembleLines(texts ...string) []*StructuredLogEntry {
+	assembler := NewLogAssembler()
+	entries := []*StructuredLogEntry{}
+
+	for i, text := range texts {
+		line := LogLine{Sequence: uint64(i + 1), Timestamp: time.Now(), Source: "STDOUT", Text: text}
+		if entry := assembler.Add(line); entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	if entry := assembler.Flush(); entry != nil {
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestLogAssemblerParsesFields(t *testing.T) {
+	entries := assembleLines("[12:34:56] [Server thread/WARN]: Can't keep up! Is the server overloaded? Running 5000ms behind")
+	if len(entries) != 1 {
+		t.Fatalf("Se esperaba 1 entrada, obtenidas %d", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.Level != LogLevelWarn {
+		t.Errorf("Nivel esperado WARN, obtenido %s", entry.Level)
+	}
+	if entry.Thread != "Server thread" {
+		t.Errorf("Hilo esperado 'Server thread', obtenido '%s'", entry.Thread)
+	}
+	if entry.Source != "SERVER" {
+		t.Errorf("Origen esperado SERVER, obtenido %s", entry.Source)
+	}
+	if entry.Stream != "STDOUT" {
+		t.Errorf("Stream esperado STDOUT, obtenido %s", entry.Stream)
+	}
+	if entry.ErrorType != "PERFORMANCE_LAG" || entry.Severity != 3 || entry.Suggestion == "" {
+		t.Errorf("Patrón PERFORMANCE_LAG no detectado: %+v", entry)
+	}
+	if h, m, s := entry.Timestamp.Clock(); h != 12 || m != 34 || s != 56 {
+		t.Errorf("Hora esperada 12:34:56, obtenida %02d:%02d:%02d", h, m, s)
+	}
+}
+
+func TestLogAssemblerFoldsStackTrace(t *testing.T) {
+	entries := assembleLines(
+		"[10:00:00] [Server thread/ERROR]: Encountered an unexpected exception",
+		"java.lang.NullPointerException: Cannot invoke \"Object.toString()\"",
+		"\tat com.example.Plugin.onEnable(Plugin.java:42)",
+		"\tat org.bukkit.plugin.java.JavaPlugin.setEnabled(JavaPlugin.java:264)",
+		"Caused by: java.lang.IllegalStateException: boom",
+		"\t... 5 more",
+		"[10:00:01] [Server thread/INFO]: Done (3.2s)!",
+	)
+
+	if len(entries) != 2 {
+		t.Fatalf("Se esperaban 2 entradas, obtenidas %d", len(entries))
+	}
+
+	trace := entries[0]
+	if len(trace.StackTrace) != 5 {
+		t.Errorf("Se esperaban 5 líneas de stack trace, obtenidas %d", len(trace.StackTrace))
+	}
+	if trace.Sequence != 6 {
+		t.Errorf("La secuencia debería ser la de la última línea agrupada (6), obtenida %d", trace.Sequence)
+	}
+	if trace.ErrorType != "NULL_POINTER" {
+		t.Errorf("ErrorType esperado NULL_POINTER, obtenido %s", trace.ErrorType)
+	}
+	if trace.Level != LogLevelError {
+		t.Errorf("Nivel esperado ERROR, obtenido %s", trace.Level)
+	}
+
+	if entries[1].Level != LogLevelInfo || len(entries[1].StackTrace) != 0 {
+		t.Errorf("La línea siguiente no debería agruparse: %+v", entries[1])
+	}
+}
+
+func TestLogAssemblerPlainLinesNotFolded(t *testing.T) {
+	entries := assembleLines(
+		"Starting minecraft server version 1.20.1",
+		"Loading properties",
+	)
+
+	if len(entries) != 2 {
+		t.Errorf("Líneas sin formato no deberían agruparse, obtenidas %d entradas", len(entries))
+	}
+}
+
+func TestAnchorLogTimeBeforeMidnight(t *testing.T) {
+	captured := time.Date(2024, 5, 2, 0, 0, 5, 0, time.Local)
+	logged := time.Date(0, 1, 1, 23, 59, 58, 0, time.Local)
+
+	anchored := anchorLogTime(logged, captured)
+	if anchored.Day() != 1 {
+		t.Errorf("La línea debería pertenecer al día anterior, obtenido %v", anchored)
+	}
+}
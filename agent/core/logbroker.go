@@ -0,0 +1,200 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLogBufferSize líneas de historial guardadas por servidor
+	DefaultLogBufferSize = 5000
+
+	// logSubscriberBuffer capacidad del canal de cada suscriptor
+	logSubscriberBuffer = 1024
+)
+
+// LogLine línea de log capturada de un servidor
+type LogLine struct {
+	Sequence  uint64
+	Timestamp time.Time
+	Source    string // STDOUT, STDERR
+	Text      string
+}
+
+// LogReplayOptions define qué historial se entrega al suscribirse.
+// Si se indican varios criterios se aplican todos.
+type LogReplayOptions struct {
+	Tail          int       // Últimas N líneas (0 = sin límite por cantidad)
+	Since         time.Time // Solo líneas posteriores a este instante
+	AfterSequence uint64    // Solo líneas con secuencia mayor (reanudación)
+	NoHistory     bool      // No reenviar historial, solo salida nueva
+}
+
+// LogSubscription suscripción a la salida de un servidor
+type LogSubscription struct {
+	History []LogLine
+	C       <-chan LogLine
+
+	id     int
+	broker *LogBroker
+}
+
+// Close cancela la suscripción
+func (s *LogSubscription) Close() {
+	s.broker.unsubscribe(s.id)
+}
+
+// Lagged indica si la suscripción se cerró por no consumir a tiempo.
+// En ese caso el consumidor puede reanudar con AfterSequence.
+func (s *LogSubscription) Lagged() bool {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.broker.lagged[s.id]
+}
+
+// LogBroker guarda las últimas líneas de un servidor en un buffer
+// circular y las distribuye a todos los suscriptores
+type LogBroker struct {
+	buffer      []LogLine
+	start       int
+	count       int
+	nextSeq     uint64
+	subscribers map[int]chan LogLine
+	lagged      map[int]bool
+	nextID      int
+	mu          sync.Mutex
+}
+
+// NewLogBroker crea un broker con la capacidad de historial indicada
+func NewLogBroker(capacity int) *LogBroker {
+	if capacity <= 0 {
+		capacity = DefaultLogBufferSize
+	}
+
+	return &LogBroker{
+		buffer:      make([]LogLine, capacity),
+		nextSeq:     1,
+		subscribers: make(map[int]chan LogLine),
+		lagged:      make(map[int]bool),
+	}
+}
+
+// Publish agrega una línea al historial y la envía a los suscriptores.
+// Un suscriptor que no consume a tiempo se desconecta (marcado como
+// lagged) para no bloquear la lectura de la salida del servidor.
+func (b *LogBroker) Publish(source, text string) LogLine {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	line := LogLine{
+		Sequence:  b.nextSeq,
+		Timestamp: time.Now(),
+		Source:    source,
+		Text:      text,
+	}
+	b.nextSeq++
+
+	// Escribir en el buffer circular
+	capacity := len(b.buffer)
+	if b.count < capacity {
+		b.buffer[(b.start+b.count)%capacity] = line
+		b.count++
+	} else {
+		b.buffer[b.start] = line
+		b.start = (b.start + 1) % capacity
+	}
+
+	for id, ch := range b.subscribers {
+		select {
+		case ch <- line:
+		default:
+			close(ch)
+			delete(b.subscribers, id)
+			b.lagged[id] = true
+		}
+	}
+
+	return line
+}
+
+// Subscribe retorna el historial solicitado y un canal con las líneas
+// nuevas. Historial y canal se obtienen de forma atómica, sin huecos ni
+// duplicados entre ambos.
+func (b *LogBroker) Subscribe(opts LogReplayOptions) *LogSubscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+
+	ch := make(chan LogLine, logSubscriberBuffer)
+	b.subscribers[id] = ch
+
+	sub := &LogSubscription{
+		C:      ch,
+		id:     id,
+		broker: b,
+	}
+
+	if !opts.NoHistory {
+		sub.History = b.historyLocked(opts)
+	}
+
+	return sub
+}
+
+// History retorna una copia del historial que cumple los criterios
+func (b *LogBroker) History(opts LogReplayOptions) []LogLine {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.historyLocked(opts)
+}
+
+// historyLocked filtra el buffer circular. Requiere b.mu tomado.
+func (b *LogBroker) historyLocked(opts LogReplayOptions) []LogLine {
+	capacity := len(b.buffer)
+	lines := make([]LogLine, 0, b.count)
+
+	// Una secuencia posterior a la última emitida indica que el consumidor
+	// viene de otra instancia del agente: ignorarla
+	afterSeq := opts.AfterSequence
+	if afterSeq >= b.nextSeq {
+		afterSeq = 0
+	}
+
+	for i := 0; i < b.count; i++ {
+		line := b.buffer[(b.start+i)%capacity]
+		if line.Sequence <= afterSeq {
+			continue
+		}
+		if !opts.Since.IsZero() && line.Timestamp.Before(opts.Since) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if opts.Tail > 0 && len(lines) > opts.Tail {
+		lines = lines[len(lines)-opts.Tail:]
+	}
+
+	return lines
+}
+
+// unsubscribe elimina un suscriptor
+func (b *LogBroker) unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ch, exists := b.subscribers[id]; exists {
+		close(ch)
+		delete(b.subscribers, id)
+	}
+	delete(b.lagged, id)
+}
+
+// SubscriberCount retorna el número de suscriptores activos
+func (b *LogBroker) SubscriberCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
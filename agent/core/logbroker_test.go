@@ -0,0 +1,132 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func publishLines(b *LogBroker, n int) {
+	for i := 0; i < n; i++ {
+		b.Publish("STDOUT", fmt.Sprintf("line %d", i))
+	}
+}
+
+func TestLogBrokerRingBufferWraps(t *testing.T) {
+	broker := NewLogBroker(3)
+	publishLines(broker, 5)
+
+	history := broker.History(LogReplayOptions{})
+	if len(history) != 3 {
+		t.Fatalf("Se esperaban 3 líneas, obtenidas %d", len(history))
+	}
+
+	for i, want := range []string{"line 2", "line 3", "line 4"} {
+		if history[i].Text != want {
+			t.Errorf("Línea %d: esperada %q, obtenida %q", i, want, history[i].Text)
+		}
+	}
+	if history[2].Sequence != 5 {
+		t.Errorf("Secuencia esperada 5, obtenida %d", history[2].Sequence)
+	}
+}
+
+func TestLogBrokerReplayOptions(t *testing.T) {
+	broker := NewLogBroker(10)
+	publishLines(broker, 6)
+
+	if got := broker.History(LogReplayOptions{Tail: 2}); len(got) != 2 || got[0].Text != "line 4" {
+		t.Errorf("Tail=2 incorrecto: %+v", got)
+	}
+
+	if got := broker.History(LogReplayOptions{AfterSequence: 4}); len(got) != 2 || got[0].Sequence != 5 {
+		t.Errorf("AfterSequence=4 incorrecto: %+v", got)
+	}
+
+	// Una secuencia desconocida (agente reiniciado) devuelve todo el historial
+	if got := broker.History(LogReplayOptions{AfterSequence: 100}); len(got) != 6 {
+		t.Errorf("AfterSequence futura debería ignorarse, obtenidas %d líneas", len(got))
+	}
+
+	if got := broker.History(LogReplayOptions{Since: time.Now().Add(time.Hour)}); len(got) != 0 {
+		t.Errorf("Since futuro no debería devolver líneas, obtenidas %d", len(got))
+	}
+
+	sub := broker.Subscribe(LogReplayOptions{NoHistory: true})
+	defer sub.Close()
+	if len(sub.History) != 0 {
+		t.Errorf("NoHistory no debería devolver historial, obtenidas %d", len(sub.History))
+	}
+}
+
+func TestLogBrokerMultipleSubscribers(t *testing.T) {
+	broker := NewLogBroker(100)
+
+	const subscribers = 3
+	const lines = 50
+
+	subs := make([]*LogSubscription, subscribers)
+	for i := range subs {
+		subs[i] = broker.Subscribe(LogReplayOptions{})
+	}
+
+	var wg sync.WaitGroup
+	counts := make([]int, subscribers)
+	for i, sub := range subs {
+		wg.Add(1)
+		go func(i int, sub *LogSubscription) {
+			defer wg.Done()
+			for range sub.C {
+				counts[i]++
+				if counts[i] == lines {
+					return
+				}
+			}
+		}(i, sub)
+	}
+
+	publishLines(broker, lines)
+	wg.Wait()
+
+	for i, count := range counts {
+		if count != lines {
+			t.Errorf("Suscriptor %d recibió %d líneas, esperadas %d", i, count, lines)
+		}
+	}
+
+	for _, sub := range subs {
+		sub.Close()
+	}
+	if broker.SubscriberCount() != 0 {
+		t.Errorf("No deberían quedar suscriptores, quedan %d", broker.SubscriberCount())
+	}
+}
+
+func TestLogBrokerSlowSubscriberLags(t *testing.T) {
+	broker := NewLogBroker(DefaultLogBufferSize)
+	sub := broker.Subscribe(LogReplayOptions{})
+	defer sub.Close()
+
+	// Nadie consume el canal: debe desconectarse en lugar de bloquear
+	publishLines(broker, logSubscriberBuffer+1)
+
+	received := 0
+	for range sub.C {
+		received++
+	}
+
+	if !sub.Lagged() {
+		t.Error("La suscripción debería marcarse como lagged")
+	}
+	if received != logSubscriberBuffer {
+		t.Errorf("Se esperaban %d líneas antes del corte, obtenidas %d", logSubscriberBuffer, received)
+	}
+
+	// Reanudar desde la última secuencia recibida no pierde líneas
+	resumed := broker.Subscribe(LogReplayOptions{AfterSequence: uint64(received)})
+	defer resumed.Close()
+	if len(resumed.History) != 1 {
+		t.Errorf("Se esperaba 1 línea pendiente, obtenidas %d", len(resumed.History))
+	}
+}
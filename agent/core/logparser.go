@@ -2,6 +2,7 @@ package core
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -23,6 +24,7 @@ type ParsedLogEntry struct {
 	Timestamp   time.Time
 	Level       LogLevel
 	Source      string
+	Thread      string
 	Message     string
 	Plugin      string
 	ClassName   string
@@ -42,6 +44,12 @@ type LogParser struct {
 	exceptionPattern *regexp.Regexp
 	atLinePattern    *regexp.Regexp
 	causedByPattern  *regexp.Regexp
+	savedPattern     *regexp.Regexp
+	formatPattern    *regexp.Regexp
+	tpsPattern       *regexp.Regexp
+	msptHeader       *regexp.Regexp
+	msptPattern      *regexp.Regexp
+	unknownCommand   *regexp.Regexp
 }
 
 // NewLogParser crea un nuevo parser de logs
@@ -51,7 +59,7 @@ func NewLogParser() *LogParser {
 		timestampPattern: regexp.MustCompile(`^\[(\d{2}:\d{2}:\d{2})\]`),
 		
 		// [LEVEL] o [Thread/LEVEL]
-		levelPattern: regexp.MustCompile(`\[(?:[^/]+/)?(\w+)\]:`),
+		levelPattern: regexp.MustCompile(`\[(?:([^/\]]+)/)?(\w+)\]:`),
 		
 		// [PluginName] en el mensaje
 		pluginPattern: regexp.MustCompile(`\[([A-Za-z0-9_-]+)\]`),
@@ -64,6 +72,25 @@ func NewLogParser() *LogParser {
 		
 		// Caused by: Exception
 		causedByPattern: regexp.MustCompile(`Caused by:\s+([A-Za-z0-9.]+(?:Exception|Error))`),
+
+		// Respuesta de "save-all", anclada para que un mensaje de chat no
+		// la imite. Vanilla: "[12:00:00] [Server thread/INFO]: Saved the game"
+		// (Forge agrega " [minecraft/MinecraftServer]"); Spigot y Paper:
+		// "[12:00:00 INFO]: Saved the game"
+		savedPattern: regexp.MustCompile(`^\[[\d:]+(?:\] \[Server thread/INFO\](?: \[[\w./-]+\])?| INFO\]):\s*Saved the game$`),
+
+		// Colores ANSI y códigos de formato de Minecraft (§a)
+		formatPattern: regexp.MustCompile(`\x1b\[[0-9;]*m|§.`),
+
+		// Respuesta de "tps" (Spigot, Paper): 1m, 5m y 15m, "*" si supera 20
+		tpsPattern: regexp.MustCompile(`TPS from last 1m, 5m, 15m:\s*\*?([\d.]+),\s*\*?([\d.]+),\s*\*?([\d.]+)`),
+
+		// Respuesta de "mspt" (Paper): cabecera y una línea avg/min/max para
+		// 5s, 10s y 1m
+		msptHeader:  regexp.MustCompile(`Server tick times \(avg/min/max\) from last 5s, 10s, 1m`),
+		msptPattern: regexp.MustCompile(`([\d.]+)/[\d.]+/[\d.]+,\s*([\d.]+)/[\d.]+/[\d.]+,\s*([\d.]+)/[\d.]+/[\d.]+`),
+
+		unknownCommand: regexp.MustCompile(`Unknown (?:or incomplete )?command`),
 	}
 }
 
@@ -90,8 +117,9 @@ func (lp *LogParser) ParseLog(logLine string) *ParsedLogEntry {
 	}
 
 	// Parsear nivel
-	if matches := lp.levelPattern.FindStringSubmatch(logLine); len(matches) > 1 {
-		entry.Level = lp.normalizeLevel(matches[1])
+	if matches := lp.levelPattern.FindStringSubmatch(logLine); len(matches) > 2 {
+		entry.Thread = matches[1]
+		entry.Level = lp.normalizeLevel(matches[2])
 	}
 
 	// Detectar plugin
@@ -135,6 +163,49 @@ func (lp *LogParser) ParseLog(logLine string) *ParsedLogEntry {
 	return entry
 }
 
+// IsWorldSaved indica si la línea confirma que el servidor terminó de
+// guardar el mundo tras un "save-all"
+func (lp *LogParser) IsWorldSaved(logLine string) bool {
+	return lp.savedPattern.MatchString(strings.TrimSpace(lp.stripFormatting(logLine)))
+}
+
+// ParseTPS extrae los TPS del último minuto de la respuesta de "tps"
+func (lp *LogParser) ParseTPS(logLine string) (float64, bool) {
+	match := lp.tpsPattern.FindStringSubmatch(lp.stripFormatting(logLine))
+	if match == nil {
+		return 0, false
+	}
+	tps, err := strconv.ParseFloat(match[1], 64)
+	return tps, err == nil
+}
+
+// IsMSPTHeader indica si la línea es la cabecera de la respuesta de "mspt";
+// los valores llegan en la línea siguiente
+func (lp *LogParser) IsMSPTHeader(logLine string) bool {
+	return lp.msptHeader.MatchString(lp.stripFormatting(logLine))
+}
+
+// ParseMSPT extrae la media de milisegundos por tick del último minuto de
+// la línea de valores de "mspt"
+func (lp *LogParser) ParseMSPT(logLine string) (float64, bool) {
+	match := lp.msptPattern.FindStringSubmatch(lp.stripFormatting(logLine))
+	if match == nil {
+		return 0, false
+	}
+	mspt, err := strconv.ParseFloat(match[3], 64)
+	return mspt, err == nil
+}
+
+// IsUnknownCommand indica si el servidor rechazó un comando de consola
+func (lp *LogParser) IsUnknownCommand(logLine string) bool {
+	return lp.unknownCommand.MatchString(logLine)
+}
+
+// stripFormatting elimina colores ANSI y códigos de formato de Minecraft
+func (lp *LogParser) stripFormatting(logLine string) string {
+	return lp.formatPattern.ReplaceAllString(logLine, "")
+}
+
 // normalizeLevel normaliza el nivel de log
 func (lp *LogParser) normalizeLevel(level string) LogLevel {
 	level = strings.ToUpper(level)
@@ -215,3 +215,45 @@ if pattern.ErrorType != "MISSING_DEPENDENCY" {
 t.Errorf("ErrorType esperado MISSING_DEPENDENCY, obtenido %s", pattern.ErrorType)
 }
 }
+
+func TestParseTPS(t *testing.T) {
+	parser := NewLogParser()
+
+	tests := []struct {
+		line string
+		want float64
+		ok   bool
+	}{
+		{"[12:00:00 INFO]: TPS from last 1m, 5m, 15m: 19.87, 20.0, 20.0", 19.87, true},
+		{"[12:00:00 INFO]: §6TPS from last 1m, 5m, 15m: §a*20.0, §a*20.0, §a20.0", 20.0, true},
+		{"[12:00:00 INFO]: \x1b[0;33;1mTPS from last 1m, 5m, 15m: \x1b[0;32;1m12.5, 18.1, 19.9\x1b[m", 12.5, true},
+		{"[12:00:00 INFO]: Steve joined the game", 0, false},
+	}
+	for _, tt := range tests {
+		tps, ok := parser.ParseTPS(tt.line)
+		if ok != tt.ok || tps != tt.want {
+			t.Errorf("ParseTPS(%q) = %v, %v; want %v, %v", tt.line, tps, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseMSPT(t *testing.T) {
+	parser := NewLogParser()
+
+	if !parser.IsMSPTHeader("[12:00:00 INFO]: Server tick times (avg/min/max) from last 5s, 10s, 1m:") {
+		t.Error("No se reconoció la cabecera de mspt")
+	}
+
+	mspt, ok := parser.ParseMSPT("[12:00:00 INFO]: ◴ §a1.2§7/§a0.5§7/§a3.4§7, §a1.3§7/§a0.5§7/§a5.6§7, §e42.7§7/§a0.4§7/§c90.0")
+	if !ok || mspt != 42.7 {
+		t.Errorf("ParseMSPT = %v, %v; want 42.7, true", mspt, ok)
+	}
+
+	if _, ok := parser.ParseMSPT("[12:00:00 INFO]: Done (3.2s)!"); ok {
+		t.Error("ParseMSPT aceptó una línea sin valores")
+	}
+
+	if !parser.IsUnknownCommand("[12:00:00 INFO]: Unknown command. Type \"/help\" for help.") {
+		t.Error("No se reconoció el comando desconocido")
+	}
+}
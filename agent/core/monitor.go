@@ -2,7 +2,10 @@ package core
 
 import (
 	"log"
+	"os"
 	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/cpu"
@@ -15,6 +18,10 @@ import (
 // SystemMonitor monitorea los recursos del sistema
 type SystemMonitor struct {
 	lastCheck time.Time
+
+	mu         sync.Mutex
+	cpuSamples map[int]cpuSample  // Último tiempo de CPU por PID raíz
+	diskUsage  map[string]dirSize // Caché del tamaño de directorios de trabajo
 }
 
 // SystemMetrics contiene las métricas del sistema
@@ -40,7 +47,9 @@ type SystemMetrics struct {
 // NewSystemMonitor crea un nuevo monitor de sistema
 func NewSystemMonitor() *SystemMonitor {
 	return &SystemMonitor{
-		lastCheck: time.Now(),
+		lastCheck:  time.Now(),
+		cpuSamples: make(map[int]cpuSample),
+		diskUsage:  make(map[string]dirSize),
 	}
 }
 
@@ -117,6 +126,43 @@ func (sm *SystemMonitor) GetNetworkInterfaces() ([]net.InterfaceStat, error) {
 	return net.Interfaces()
 }
 
+// HostInfo información estática del host del agente
+type HostInfo struct {
+	Hostname        string
+	Platform        string // runtime.GOOS
+	PlatformVersion string // distribución y versión, p. ej. "ubuntu 22.04"
+	CPUCores        int
+	MemoryTotal     uint64
+	DiskTotal       uint64
+}
+
+// GetHostInfo obtiene la información estática del host
+func (sm *SystemMonitor) GetHostInfo() HostInfo {
+	info := HostInfo{
+		Platform:        runtime.GOOS,
+		PlatformVersion: "unknown",
+		CPUCores:        runtime.NumCPU(),
+	}
+
+	if hostInfo, err := host.Info(); err == nil {
+		info.Hostname = hostInfo.Hostname
+		if hostInfo.Platform != "" {
+			info.PlatformVersion = strings.TrimSpace(hostInfo.Platform + " " + hostInfo.PlatformVersion)
+		}
+	}
+	if info.Hostname == "" {
+		info.Hostname, _ = os.Hostname()
+	}
+	if vmStat, err := mem.VirtualMemory(); err == nil {
+		info.MemoryTotal = vmStat.Total
+	}
+	if diskStat, err := disk.Usage("/"); err == nil {
+		info.DiskTotal = diskStat.Total
+	}
+
+	return info
+}
+
 // CheckJavaInstalled verifica si Java está instalado
 func (sm *SystemMonitor) CheckJavaInstalled() (bool, string, error) {
 	// TODO: Implementar verificación de Java
@@ -1,7 +1,11 @@
 package core
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 )
 
 func TestNewSystemMonitor(t *testing.T) {
@@ -62,3 +66,65 @@ func TestGetOpenPorts(t *testing.T) {
 		}
 	}
 }
+
+func TestGetProcessMetrics(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "server.properties"), make([]byte, 1500), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(dir, "world"), 0755)
+	if err := os.WriteFile(filepath.Join(dir, "world", "level.dat"), make([]byte, 500), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	monitor := NewSystemMonitor()
+	metrics, err := monitor.GetProcessMetrics(os.Getpid(), dir)
+	if err != nil {
+		t.Fatalf("Error obteniendo métricas del proceso: %v", err)
+	}
+
+	if metrics.MemoryRSS == 0 {
+		t.Error("MemoryRSS es 0")
+	}
+	if metrics.Threads < 1 || metrics.ProcessCount < 1 {
+		t.Errorf("Threads=%d ProcessCount=%d, se esperaba al menos 1", metrics.Threads, metrics.ProcessCount)
+	}
+	if metrics.DiskUsed != 2000 {
+		t.Errorf("DiskUsed = %d, se esperaba 2000", metrics.DiskUsed)
+	}
+
+	// La segunda medición usa la diferencia con la primera
+	metrics, err = monitor.GetProcessMetrics(os.Getpid(), dir)
+	if err != nil {
+		t.Fatalf("Error en la segunda medición: %v", err)
+	}
+	if metrics.CPUPercent < 0 || metrics.CPUPercent > float64(100*runtime.NumCPU()) {
+		t.Errorf("CPUPercent fuera de rango: %f", metrics.CPUPercent)
+	}
+}
+
+func TestGetProcessMetricsUnknownPID(t *testing.T) {
+	monitor := NewSystemMonitor()
+	if _, err := monitor.GetProcessMetrics(1<<22+1, ""); err == nil {
+		t.Error("Se esperaba error para un PID inexistente")
+	}
+}
+
+func TestDirUsageCache(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a"), make([]byte, 100), 0644)
+
+	monitor := NewSystemMonitor()
+	now := time.Now()
+	if size := monitor.dirUsage(dir, now); size != 100 {
+		t.Fatalf("dirUsage = %d, se esperaba 100", size)
+	}
+
+	os.WriteFile(filepath.Join(dir, "b"), make([]byte, 50), 0644)
+	if size := monitor.dirUsage(dir, now.Add(time.Second)); size != 100 {
+		t.Errorf("dirUsage dentro del TTL = %d, se esperaba el valor en caché", size)
+	}
+	if size := monitor.dirUsage(dir, now.Add(diskUsageTTL)); size != 150 {
+		t.Errorf("dirUsage tras el TTL = %d, se esperaba 150", size)
+	}
+}
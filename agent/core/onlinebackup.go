@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// SaveFlushTimeout tiempo máximo de espera para "Saved the game"
+	// después de enviar "save-all flush"
+	SaveFlushTimeout = 2 * time.Minute
+
+	// OnlineBackupTimeout tiempo máximo que el guardado permanece
+	// desactivado mientras se copia el mundo
+	OnlineBackupTimeout = 30 * time.Minute
+)
+
+// WithSavingDisabled ejecuta fn con el guardado automático del servidor
+// desactivado: envía "save-off" y "save-all flush", espera a que el
+// servidor confirme con "Saved the game" y ejecuta fn. "save-on" se envía
+// siempre al terminar, aunque fn falle o se agote el tiempo.
+//
+// Si el servidor no está en ejecución no hay nada que guardar y fn se
+// ejecuta directamente.
+func (a *Agent) WithSavingDisabled(ctx context.Context, serverID string, fn func(ctx context.Context) error) error {
+	server, err := a.GetServer(serverID)
+	if err != nil {
+		return err
+	}
+	if server.Status == StatusStopped || server.Status == StatusCrashed {
+		return fn(ctx)
+	}
+
+	// Dos backups simultáneos se reactivarían el guardado entre sí
+	lock, _ := a.saveLocks.LoadOrStore(serverID, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	// Suscribirse antes de enviar comandos para no perder la confirmación
+	sub, err := a.executor.SubscribeLogs(serverID, LogReplayOptions{NoHistory: true})
+	if err != nil {
+		return err
+	}
+	defer func() { sub.Close() }()
+
+	if err := a.executor.SendCommand(serverID, "save-off"); err != nil {
+		return fmt.Errorf("error desactivando el guardado: %w", err)
+	}
+	log.Printf("[INFO] Guardado desactivado para %s", serverID)
+
+	defer func() {
+		if err := a.executor.SendCommand(serverID, "save-on"); err != nil {
+			log.Printf("[ERROR] No se pudo reactivar el guardado de %s: %v", serverID, err)
+			return
+		}
+		log.Printf("[INFO] Guardado reactivado para %s", serverID)
+	}()
+
+	if err := a.executor.SendCommand(serverID, "save-all flush"); err != nil {
+		return fmt.Errorf("error guardando el mundo: %w", err)
+	}
+
+	waitCtx, cancelWait := context.WithTimeout(ctx, SaveFlushTimeout)
+	sub, err = a.waitForWorldSave(waitCtx, serverID, sub)
+	cancelWait()
+	if err != nil {
+		return err
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, OnlineBackupTimeout)
+	defer cancel()
+
+	return fn(opCtx)
+}
+
+// waitForWorldSave espera la línea "Saved the game". Si la suscripción se
+// cierra por retraso se reanuda desde la última línea vista. Retorna la
+// suscripción vigente para que el llamador la cierre.
+func (a *Agent) waitForWorldSave(ctx context.Context, serverID string, sub *LogSubscription) (*LogSubscription, error) {
+	parser := NewLogParser()
+	var lastSeq uint64
+
+	for {
+		select {
+		case line, ok := <-sub.C:
+			if !ok {
+				if !sub.Lagged() {
+					return sub, fmt.Errorf("el servidor %s dejó de enviar logs", serverID)
+				}
+				sub.Close()
+
+				resumed, err := a.executor.SubscribeLogs(serverID, LogReplayOptions{AfterSequence: lastSeq})
+				if err != nil {
+					return sub, err
+				}
+				sub = resumed
+				for _, line := range sub.History {
+					if parser.IsWorldSaved(line.Text) {
+						return sub, nil
+					}
+					lastSeq = line.Sequence
+				}
+				continue
+			}
+
+			lastSeq = line.Sequence
+			if parser.IsWorldSaved(line.Text) {
+				return sub, nil
+			}
+
+		case <-ctx.Done():
+			return sub, fmt.Errorf("tiempo agotado esperando que %s guarde el mundo: %w", serverID, ctx.Err())
+		}
+	}
+}
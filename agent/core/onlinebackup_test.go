@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeJavaScript simula la consola de un servidor: registra los comandos
+// recibidos y responde a "save-all flush" salvo que exista el archivo nosave
+const fakeJavaScript = `#!/bin/sh
+while IFS= read -r line; do
+  echo "$line" >> commands.log
+  case "$line" in
+    "save-all flush") [ -f nosave ] || echo "[12:00:00] [Server thread/INFO]: Saved the game" ;;
+    stop) exit 0 ;;
+  esac
+done
+`
+
+// newOnlineBackupTestAgent inicia un servidor falso con "java" en el PATH
+func newOnlineBackupTestAgent(t *testing.T, respondToSave bool) (*Agent, string) {
+	t.Helper()
+
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "java"), []byte(fakeJavaScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	config := &Config{
+		WorkDir:    t.TempDir(),
+		MaxServers: 5,
+	}
+	agent, err := NewAgent(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Error creando agente: %v", err)
+	}
+
+	serverDir := filepath.Join(config.WorkDir, "srv")
+	if err := os.MkdirAll(serverDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !respondToSave {
+		os.WriteFile(filepath.Join(serverDir, "nosave"), nil, 0644)
+	}
+
+	// El handler de salida persiste el estado en WorkDir después de que
+	// StopServer retorne; hay que esperarlo antes de borrar el directorio
+	exited := make(chan struct{})
+	var exitOnce sync.Once
+	agent.executor.SetExitHandler(func(serverID string, exitCode int, stopRequested bool) {
+		agent.handleProcessExit(serverID, exitCode, stopRequested)
+		exitOnce.Do(func() { close(exited) })
+	})
+
+	if err := agent.executor.StartServer("srv", ServerConfig{JarFile: "server.jar"}); err != nil {
+		t.Fatalf("Error iniciando servidor falso: %v", err)
+	}
+	t.Cleanup(func() {
+		agent.executor.StopServer("srv")
+		select {
+		case <-exited:
+		case <-time.After(10 * time.Second):
+			t.Error("El handler de salida del servidor falso no terminó")
+		}
+	})
+
+	agent.serversMux.Lock()
+	agent.servers["srv"] = &MinecraftServer{ID: "srv", Status: StatusRunning, WorkDir: serverDir}
+	agent.serversMux.Unlock()
+
+	return agent, serverDir
+}
+
+// waitForCommands espera a que el servidor falso haya recibido los comandos
+func waitForCommands(t *testing.T, serverDir string, expected ...string) []string {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		data, _ := os.ReadFile(filepath.Join(serverDir, "commands.log"))
+		commands := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if strings.Join(commands, ",") == strings.Join(expected, ",") {
+			return commands
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Comandos esperados %v, recibidos %v", expected, commands)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestWithSavingDisabled(t *testing.T) {
+	agent, serverDir := newOnlineBackupTestAgent(t, true)
+
+	ran := false
+	err := agent.WithSavingDisabled(context.Background(), "srv", func(ctx context.Context) error {
+		ran = true
+		// Durante la copia el guardado ya está desactivado y el mundo guardado
+		waitForCommands(t, serverDir, "save-off", "save-all flush")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Error inesperado: %v", err)
+	}
+	if !ran {
+		t.Fatal("La función de copia no se ejecutó")
+	}
+
+	waitForCommands(t, serverDir, "save-off", "save-all flush", "save-on")
+}
+
+func TestWithSavingDisabledReenablesOnFailure(t *testing.T) {
+	agent, serverDir := newOnlineBackupTestAgent(t, true)
+
+	copyErr := errors.New("disco lleno")
+	err := agent.WithSavingDisabled(context.Background(), "srv", func(ctx context.Context) error {
+		return copyErr
+	})
+	if !errors.Is(err, copyErr) {
+		t.Fatalf("Se esperaba el error de la copia, obtenido %v", err)
+	}
+
+	waitForCommands(t, serverDir, "save-off", "save-all flush", "save-on")
+}
+
+func TestWithSavingDisabledReenablesOnTimeout(t *testing.T) {
+	agent, serverDir := newOnlineBackupTestAgent(t, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	ran := false
+	err := agent.WithSavingDisabled(ctx, "srv", func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Se esperaba un error por tiempo agotado")
+	}
+	if ran {
+		t.Error("No se debería copiar sin la confirmación de guardado")
+	}
+
+	waitForCommands(t, serverDir, "save-off", "save-all flush", "save-on")
+}
+
+func TestWithSavingDisabledStoppedServer(t *testing.T) {
+	config := &Config{WorkDir: t.TempDir(), MaxServers: 5}
+	agent, err := NewAgent(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Error creando agente: %v", err)
+	}
+
+	agent.serversMux.Lock()
+	agent.servers["srv"] = &MinecraftServer{ID: "srv", Status: StatusStopped}
+	agent.serversMux.Unlock()
+
+	ran := false
+	if err := agent.WithSavingDisabled(context.Background(), "srv", func(ctx context.Context) error {
+		ran = true
+		return nil
+	}); err != nil {
+		t.Fatalf("Error inesperado: %v", err)
+	}
+	if !ran {
+		t.Error("Con el servidor detenido la copia debe ejecutarse directamente")
+	}
+}
+
+func TestIsWorldSaved(t *testing.T) {
+	parser := NewLogParser()
+
+	saved := []string{
+		"[12:00:00] [Server thread/INFO]: Saved the game",
+		"[12:00:00 INFO]: Saved the game",
+		"[12:00:00] [Server thread/INFO] [minecraft/MinecraftServer]: Saved the game",
+		"\x1b[0m[12:00:00 INFO]: Saved the game\x1b[m\r",
+	}
+	for _, line := range saved {
+		if !parser.IsWorldSaved(line) {
+			t.Errorf("Debería detectar la confirmación de guardado: %q", line)
+		}
+	}
+
+	chat := []string{
+		"[12:00:00] [Server thread/INFO]: <Steve> Saved the game",
+		"[12:00:00] [Server thread/INFO]: <Bob> ]: Saved the game",
+		"[12:00:00 INFO]: <Bob> ]: Saved the game",
+		"[12:00:00] [Async Chat Thread - #0/INFO]: <Bob> [12:00:00] [Server thread/INFO]: Saved the game",
+		"[12:00:00] [Server thread/INFO]: Saved the game by Bob",
+	}
+	for _, line := range chat {
+		if parser.IsWorldSaved(line) {
+			t.Errorf("Un mensaje de chat no es una confirmación de guardado: %q", line)
+		}
+	}
+}
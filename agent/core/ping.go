@@ -0,0 +1,314 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// pingTimeout tiempo máximo de una consulta si el contexto no tiene
+	// deadline propio
+	pingTimeout = 5 * time.Second
+
+	// maxStatusResponse tamaño máximo aceptado de la respuesta de estado
+	// (incluye el favicon en base64)
+	maxStatusResponse = 1 << 20
+)
+
+// ServerListPing respuesta del protocolo Server List Ping
+type ServerListPing struct {
+	Version       string
+	Protocol      int
+	PlayersOnline int
+	MaxPlayers    int
+	Players       []string // Muestra de jugadores; el servidor puede limitarla
+	Latency       time.Duration
+}
+
+// QueryStats respuesta completa del protocolo Query (UDP). Requiere
+// enable-query=true en server.properties.
+type QueryStats struct {
+	MOTD          string
+	GameType      string
+	Version       string
+	Plugins       string
+	Map           string
+	PlayersOnline int
+	MaxPlayers    int
+	Players       []string // Lista completa de jugadores conectados
+}
+
+// PingServer consulta el estado de un servidor con el protocolo Server List
+// Ping (el mismo que usa la lista de servidores del cliente)
+func PingServer(ctx context.Context, address string) (*ServerListPing, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("puerto inválido: %s", portStr)
+	}
+
+	ctx, cancel := withPingTimeout(ctx)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	// Handshake (estado siguiente 1 = status) y petición de estado
+	var handshake bytes.Buffer
+	writeVarInt(&handshake, 0x00)
+	writeVarInt(&handshake, -1) // Versión de protocolo: cualquiera vale para status
+	writeVarInt(&handshake, int32(len(host)))
+	handshake.WriteString(host)
+	binary.Write(&handshake, binary.BigEndian, uint16(port))
+	writeVarInt(&handshake, 1)
+
+	var request bytes.Buffer
+	writePacket(&request, handshake.Bytes())
+	writePacket(&request, []byte{0x00})
+	if _, err := conn.Write(request.Bytes()); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	payload, err := readPacket(reader, 0x00)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo estado: %w", err)
+	}
+	payloadReader := bytes.NewReader(payload)
+	length, err := readVarInt(payloadReader)
+	if err != nil || length < 0 || int(length) > payloadReader.Len() {
+		return nil, fmt.Errorf("respuesta de estado inválida")
+	}
+	status := make([]byte, length)
+	io.ReadFull(payloadReader, status)
+
+	var response struct {
+		Version struct {
+			Name     string `json:"name"`
+			Protocol int    `json:"protocol"`
+		} `json:"version"`
+		Players struct {
+			Max    int `json:"max"`
+			Online int `json:"online"`
+			Sample []struct {
+				Name string `json:"name"`
+			} `json:"sample"`
+		} `json:"players"`
+	}
+	if err := json.Unmarshal(status, &response); err != nil {
+		return nil, fmt.Errorf("respuesta de estado inválida: %w", err)
+	}
+
+	ping := &ServerListPing{
+		Version:       response.Version.Name,
+		Protocol:      response.Version.Protocol,
+		PlayersOnline: response.Players.Online,
+		MaxPlayers:    response.Players.Max,
+	}
+	for _, player := range response.Players.Sample {
+		ping.Players = append(ping.Players, player.Name)
+	}
+
+	// Ping/pong para medir la latencia. Algunos proxies cierran la conexión
+	// tras el estado; en ese caso se retorna sin latencia.
+	var pingPacket bytes.Buffer
+	pingPacket.WriteByte(0x01)
+	binary.Write(&pingPacket, binary.BigEndian, time.Now().UnixMilli())
+	sent := time.Now()
+	var framed bytes.Buffer
+	writePacket(&framed, pingPacket.Bytes())
+	if _, err := conn.Write(framed.Bytes()); err == nil {
+		if _, err := readPacket(reader, 0x01); err == nil {
+			ping.Latency = time.Since(sent)
+		}
+	}
+
+	return ping, nil
+}
+
+// QueryServer obtiene las estadísticas completas de un servidor con el
+// protocolo Query (GameSpy 4 sobre UDP)
+func QueryServer(ctx context.Context, address string) (*QueryStats, error) {
+	ctx, cancel := withPingTimeout(ctx)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	sessionID := int32(time.Now().UnixNano()) & 0x0F0F0F0F
+	buffer := make([]byte, 65535)
+
+	// Handshake: el servidor responde con un token para la consulta
+	request := queryPacket(0x09, sessionID, nil)
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+	n, err := conn.Read(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("sin respuesta al handshake de query: %w", err)
+	}
+	if n < 6 || buffer[0] != 0x09 {
+		return nil, fmt.Errorf("respuesta de handshake inválida")
+	}
+	token, err := strconv.ParseInt(string(bytes.TrimRight(buffer[5:n], "\x00")), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("token de query inválido: %w", err)
+	}
+
+	// Estadísticas completas: token más 4 bytes de relleno
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint32(payload, uint32(token))
+	if _, err := conn.Write(queryPacket(0x00, sessionID, payload)); err != nil {
+		return nil, err
+	}
+	n, err = conn.Read(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("sin respuesta a la consulta: %w", err)
+	}
+
+	return parseFullStat(buffer[:n])
+}
+
+// parseFullStat interpreta la respuesta de "full stat": pares clave/valor
+// terminados en cero seguidos de la lista de jugadores
+func parseFullStat(data []byte) (*QueryStats, error) {
+	// Tipo (1) + sesión (4) + "splitnum\x00\x80\x00" (11)
+	const headerSize = 16
+	if len(data) < headerSize || data[0] != 0x00 {
+		return nil, fmt.Errorf("respuesta de query inválida")
+	}
+
+	fields := bytes.Split(data[headerSize:], []byte{0})
+	values := make(map[string]string)
+	i := 0
+	for ; i+1 < len(fields); i += 2 {
+		key := string(fields[i])
+		if key == "" {
+			break
+		}
+		values[key] = string(fields[i+1])
+	}
+
+	stats := &QueryStats{
+		MOTD:     values["hostname"],
+		GameType: values["gametype"],
+		Version:  values["version"],
+		Plugins:  values["plugins"],
+		Map:      values["map"],
+	}
+	stats.PlayersOnline, _ = strconv.Atoi(values["numplayers"])
+	stats.MaxPlayers, _ = strconv.Atoi(values["maxplayers"])
+
+	// Tras la clave vacía viene "\x01player_\x00\x00" y los nombres
+	for i++; i < len(fields); i++ {
+		if string(fields[i]) == "\x01player_" {
+			break
+		}
+	}
+	for i += 2; i < len(fields); i++ {
+		if len(fields[i]) == 0 {
+			break
+		}
+		stats.Players = append(stats.Players, string(fields[i]))
+	}
+
+	return stats, nil
+}
+
+// queryPacket construye un paquete del protocolo Query
+func queryPacket(packetType byte, sessionID int32, payload []byte) []byte {
+	packet := []byte{0xFE, 0xFD, packetType}
+	packet = binary.BigEndian.AppendUint32(packet, uint32(sessionID))
+	return append(packet, payload...)
+}
+
+// withPingTimeout aplica pingTimeout si el contexto no tiene deadline
+func withPingTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, pingTimeout)
+}
+
+// writePacket escribe un paquete con su longitud como prefijo VarInt
+func writePacket(w *bytes.Buffer, data []byte) {
+	writeVarInt(w, int32(len(data)))
+	w.Write(data)
+}
+
+// readPacket lee un paquete y comprueba su identificador. Retorna los datos
+// posteriores al identificador.
+func readPacket(r *bufio.Reader, wantID int32) ([]byte, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if length <= 0 || length > maxStatusResponse {
+		return nil, fmt.Errorf("longitud de paquete inválida: %d", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	body := bytes.NewReader(data)
+	id, err := readVarInt(body)
+	if err != nil {
+		return nil, err
+	}
+	if id != wantID {
+		return nil, fmt.Errorf("paquete inesperado: 0x%02x", id)
+	}
+	return data[len(data)-body.Len():], nil
+}
+
+func writeVarInt(w *bytes.Buffer, value int32) {
+	v := uint32(value)
+	for {
+		if v&^0x7F == 0 {
+			w.WriteByte(byte(v))
+			return
+		}
+		w.WriteByte(byte(v&0x7F | 0x80))
+		v >>= 7
+	}
+}
+
+func readVarInt(r io.ByteReader) (int32, error) {
+	var value uint32
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(value), nil
+		}
+	}
+	return 0, errors.New("VarInt demasiado largo")
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startFakeStatusServer responde al Server List Ping con status y al ping
+// con el mismo payload. Retorna la dirección en la que escucha.
+func startFakeStatusServer(t *testing.T, status string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+
+				// Handshake y petición de estado
+				if _, err := readPacket(reader, 0x00); err != nil {
+					return
+				}
+				if _, err := readPacket(reader, 0x00); err != nil {
+					return
+				}
+				var body, response bytes.Buffer
+				body.WriteByte(0x00)
+				writeVarInt(&body, int32(len(status)))
+				body.WriteString(status)
+				writePacket(&response, body.Bytes())
+				conn.Write(response.Bytes())
+
+				payload, err := readPacket(reader, 0x01)
+				if err != nil {
+					return
+				}
+				var pong bytes.Buffer
+				writePacket(&pong, append([]byte{0x01}, payload...))
+				conn.Write(pong.Bytes())
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// startFakeQueryServer responde al protocolo Query con los jugadores dados
+func startFakeQueryServer(t *testing.T, players ...string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buffer := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			if n < 7 || buffer[0] != 0xFE || buffer[1] != 0xFD {
+				continue
+			}
+			session := append([]byte(nil), buffer[3:7]...)
+
+			var response bytes.Buffer
+			switch buffer[2] {
+			case 0x09:
+				response.WriteByte(0x09)
+				response.Write(session)
+				response.WriteString("9513307\x00")
+			case 0x00:
+				if n < 11 || binary.BigEndian.Uint32(buffer[7:11]) != 9513307 {
+					continue // Token incorrecto: el servidor real no responde
+				}
+				response.WriteByte(0x00)
+				response.Write(session)
+				response.WriteString("splitnum\x00\x80\x00")
+				for _, kv := range []string{"hostname", "A Minecraft Server", "gametype", "SMP", "version", "1.21.1",
+					"plugins", "Paper on 1.21.1: Essentials", "map", "world", "numplayers", "2", "maxplayers", "50"} {
+					response.WriteString(kv + "\x00")
+				}
+				response.WriteString("\x00\x01player_\x00\x00")
+				for _, player := range players {
+					response.WriteString(player + "\x00")
+				}
+				response.WriteString("\x00")
+			}
+			conn.WriteTo(response.Bytes(), addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestPingServer(t *testing.T) {
+	address := startFakeStatusServer(t, `{"version":{"name":"Paper 1.21.1","protocol":767},`+
+		`"players":{"max":20,"online":3,"sample":[{"name":"Steve","id":"x"},{"name":"Alex","id":"y"}]},`+
+		`"description":{"text":"A Minecraft Server"}}`)
+
+	ping, err := PingServer(context.Background(), address)
+	if err != nil {
+		t.Fatalf("PingServer() error = %v", err)
+	}
+	if ping.Version != "Paper 1.21.1" || ping.Protocol != 767 {
+		t.Errorf("versión = %q (%d)", ping.Version, ping.Protocol)
+	}
+	if ping.PlayersOnline != 3 || ping.MaxPlayers != 20 {
+		t.Errorf("jugadores = %d/%d, se esperaba 3/20", ping.PlayersOnline, ping.MaxPlayers)
+	}
+	if strings.Join(ping.Players, ",") != "Steve,Alex" {
+		t.Errorf("muestra de jugadores = %v", ping.Players)
+	}
+	if ping.Latency <= 0 {
+		t.Error("no se midió la latencia")
+	}
+}
+
+func TestPingServerRefused(t *testing.T) {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := listener.Addr().String()
+	listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := PingServer(ctx, address); err == nil {
+		t.Error("se esperaba error con el puerto cerrado")
+	}
+}
+
+func TestQueryServer(t *testing.T) {
+	address := startFakeQueryServer(t, "Steve", "Alex")
+
+	stats, err := QueryServer(context.Background(), address)
+	if err != nil {
+		t.Fatalf("QueryServer() error = %v", err)
+	}
+	if stats.PlayersOnline != 2 || stats.MaxPlayers != 50 {
+		t.Errorf("jugadores = %d/%d, se esperaba 2/50", stats.PlayersOnline, stats.MaxPlayers)
+	}
+	if stats.Version != "1.21.1" || stats.Map != "world" || stats.MOTD != "A Minecraft Server" {
+		t.Errorf("datos = %+v", stats)
+	}
+	if strings.Join(stats.Players, ",") != "Steve,Alex" {
+		t.Errorf("jugadores = %v", stats.Players)
+	}
+}
+
+func TestVarIntRoundTrip(t *testing.T) {
+	for _, value := range []int32{0, 1, 127, 128, 25565, 2097151, -1} {
+		var buf bytes.Buffer
+		writeVarInt(&buf, value)
+		got, err := readVarInt(&buf)
+		if err != nil || got != value {
+			t.Errorf("VarInt %d: got %d, %v", value, got, err)
+		}
+	}
+}
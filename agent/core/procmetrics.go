@@ -0,0 +1,171 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const (
+	// diskUsageTTL tiempo que se reutiliza el tamaño calculado de un
+	// directorio de trabajo. Recorrer un mundo grande es costoso y el tamaño
+	// cambia despacio.
+	diskUsageTTL = time.Minute
+
+	// cpuSampleTTL tiempo tras el que se descarta la muestra de CPU de un
+	// proceso que ya no se consulta
+	cpuSampleTTL = 10 * time.Minute
+)
+
+// ProcessMetrics uso de recursos del proceso de un servidor y sus hijos
+type ProcessMetrics struct {
+	Timestamp    time.Time `json:"timestamp"`
+	PID          int       `json:"pid"`
+	CPUPercent   float64   `json:"cpu_percent"` // 100 = un núcleo completo, como top
+	MemoryRSS    uint64    `json:"memory_rss"`
+	Threads      int32     `json:"threads"`
+	OpenFiles    int32     `json:"open_files"`
+	ProcessCount int32     `json:"process_count"` // Proceso principal y descendientes
+	DiskUsed     uint64    `json:"disk_used"`     // Tamaño del directorio de trabajo
+}
+
+// cpuSample tiempo de CPU acumulado por un árbol de procesos en un instante
+type cpuSample struct {
+	createTime int64 // Detecta la reutilización del PID
+	cpuSeconds float64
+	at         time.Time
+}
+
+// dirSize tamaño calculado de un directorio
+type dirSize struct {
+	bytes uint64
+	at    time.Time
+}
+
+// GetProcessMetrics mide el proceso pid y todos sus descendientes. El uso de
+// CPU se calcula desde la consulta anterior del mismo PID; en la primera es
+// la media desde que arrancó el proceso.
+func (sm *SystemMonitor) GetProcessMetrics(pid int, workDir string) (*ProcessMetrics, error) {
+	root, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return nil, fmt.Errorf("proceso %d no encontrado: %w", pid, err)
+	}
+	createTime, err := root.CreateTime()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo proceso %d: %w", pid, err)
+	}
+
+	now := time.Now()
+	metrics := &ProcessMetrics{
+		Timestamp: now,
+		PID:       pid,
+	}
+
+	var cpuSeconds float64
+	for _, proc := range processTree(root) {
+		// Un hijo puede terminar entre que se lista y se lee: se ignora
+		if times, err := proc.Times(); err == nil {
+			cpuSeconds += times.User + times.System
+		}
+		if mem, err := proc.MemoryInfo(); err == nil {
+			metrics.MemoryRSS += mem.RSS
+		}
+		if threads, err := proc.NumThreads(); err == nil {
+			metrics.Threads += threads
+		}
+		if fds, err := proc.NumFDs(); err == nil {
+			metrics.OpenFiles += fds
+		}
+		metrics.ProcessCount++
+	}
+
+	metrics.CPUPercent = sm.cpuPercent(pid, createTime, cpuSeconds, now)
+
+	if workDir != "" {
+		metrics.DiskUsed = sm.dirUsage(workDir, now)
+	}
+
+	return metrics, nil
+}
+
+// processTree retorna root y todos sus descendientes
+func processTree(root *process.Process) []*process.Process {
+	tree := []*process.Process{root}
+	for i := 0; i < len(tree); i++ {
+		children, err := tree[i].Children()
+		if err != nil {
+			continue // Sin hijos o el proceso ya terminó
+		}
+		tree = append(tree, children...)
+	}
+	return tree
+}
+
+// cpuPercent calcula el uso de CPU entre la muestra anterior de pid y la
+// actual, y guarda la actual para la siguiente consulta
+func (sm *SystemMonitor) cpuPercent(pid int, createTime int64, cpuSeconds float64, now time.Time) float64 {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.cpuSamples == nil {
+		sm.cpuSamples = make(map[int]cpuSample)
+	}
+	for samplePID, sample := range sm.cpuSamples {
+		if now.Sub(sample.at) > cpuSampleTTL {
+			delete(sm.cpuSamples, samplePID)
+		}
+	}
+
+	previous, ok := sm.cpuSamples[pid]
+	sm.cpuSamples[pid] = cpuSample{createTime: createTime, cpuSeconds: cpuSeconds, at: now}
+
+	var elapsed, used float64
+	if ok && previous.createTime == createTime && cpuSeconds >= previous.cpuSeconds {
+		elapsed = now.Sub(previous.at).Seconds()
+		used = cpuSeconds - previous.cpuSeconds
+	} else {
+		elapsed = now.Sub(time.UnixMilli(createTime)).Seconds()
+		used = cpuSeconds
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+	return used / elapsed * 100
+}
+
+// dirUsage retorna el tamaño de los archivos bajo dir, recalculándolo como
+// mucho una vez por diskUsageTTL
+func (sm *SystemMonitor) dirUsage(dir string, now time.Time) uint64 {
+	sm.mu.Lock()
+	cached, ok := sm.diskUsage[dir]
+	sm.mu.Unlock()
+	if ok && now.Sub(cached.at) < diskUsageTTL {
+		return cached.bytes
+	}
+
+	// El recorrido se hace sin el lock: puede tardar en mundos grandes
+	var total uint64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Archivos borrados o sin permisos durante el recorrido
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				total += uint64(info.Size())
+			}
+		}
+		return nil
+	})
+
+	sm.mu.Lock()
+	if sm.diskUsage == nil {
+		sm.diskUsage = make(map[string]dirSize)
+	}
+	sm.diskUsage[dir] = dirSize{bytes: total, at: now}
+	sm.mu.Unlock()
+
+	return total
+}
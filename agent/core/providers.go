@@ -0,0 +1,549 @@
+package core
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Endpoints URLs base de las APIs de cada plataforma
+type Endpoints struct {
+	PaperMC        string // API v2 de PaperMC (Paper, Velocity, Waterfall)
+	Purpur         string // API v2 de Purpur
+	MojangManifest string // manifest de versiones de Mojang (URL completa)
+	FabricMeta     string // API v2 de Fabric meta
+	ForgeFiles     string // directorio de promotions_slim.json de Forge
+	ForgeMaven     string // repositorio Maven de Forge
+	NeoForgeMaven  string // repositorio Maven de NeoForge
+}
+
+// DefaultEndpoints URLs oficiales de cada plataforma
+var DefaultEndpoints = Endpoints{
+	PaperMC:        "https://api.papermc.io/v2",
+	Purpur:         "https://api.purpurmc.org/v2",
+	MojangManifest: "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json",
+	FabricMeta:     "https://meta.fabricmc.net/v2",
+	ForgeFiles:     "https://files.minecraftforge.net/net/minecraftforge/forge",
+	ForgeMaven:     "https://maven.minecraftforge.net",
+	NeoForgeMaven:  "https://maven.neoforged.net/releases",
+}
+
+// PaperMCProject representa un proyecto de PaperMC y sus versiones
+type PaperMCProject struct {
+	ProjectID string   `json:"project_id"`
+	Versions  []string `json:"versions"`
+}
+
+// PaperMCVersion representa una versión de PaperMC
+type PaperMCVersion struct {
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	Version     string `json:"version"`
+	Builds      []int  `json:"builds"`
+}
+
+// PaperMCBuilds representa los builds de una versión
+type PaperMCBuilds struct {
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	Version     string `json:"version"`
+	Builds      []struct {
+		Build     int       `json:"build"`
+		Time      time.Time `json:"time"`
+		Channel   string    `json:"channel"`
+		Downloads struct {
+			Application struct {
+				Name   string `json:"name"`
+				SHA256 string `json:"sha256"`
+			} `json:"application"`
+		} `json:"downloads"`
+	} `json:"builds"`
+}
+
+// PurpurBuilds representa los builds de Purpur
+type PurpurBuilds struct {
+	Builds struct {
+		All    []string `json:"all"`
+		Latest string   `json:"latest"`
+	} `json:"builds"`
+}
+
+// PurpurBuild representa un build concreto de Purpur
+type PurpurBuild struct {
+	Build  string `json:"build"`
+	MD5    string `json:"md5"`
+	Result string `json:"result"`
+}
+
+// MojangManifest representa el manifest de versiones de Mojang
+type MojangManifest struct {
+	Latest struct {
+		Release  string `json:"release"`
+		Snapshot string `json:"snapshot"`
+	} `json:"latest"`
+	Versions []struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		URL  string `json:"url"`
+		SHA1 string `json:"sha1"`
+	} `json:"versions"`
+}
+
+// MojangVersion representa los metadatos de una versión de Minecraft
+type MojangVersion struct {
+	ID        string `json:"id"`
+	Downloads struct {
+		Server *struct {
+			SHA1 string `json:"sha1"`
+			Size int64  `json:"size"`
+			URL  string `json:"url"`
+		} `json:"server"`
+	} `json:"downloads"`
+}
+
+// FabricLoader representa una versión del loader de Fabric para una
+// versión de Minecraft
+type FabricLoader struct {
+	Loader struct {
+		Version string `json:"version"`
+		Stable  bool   `json:"stable"`
+	} `json:"loader"`
+}
+
+// FabricInstaller representa una versión del instalador de Fabric
+type FabricInstaller struct {
+	URL     string `json:"url"`
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+}
+
+// FabricGameVersion representa una versión de Minecraft soportada por Fabric
+type FabricGameVersion struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+}
+
+// ForgePromotions representa las versiones recomendadas de Forge
+type ForgePromotions struct {
+	Promos map[string]string `json:"promos"`
+}
+
+// mavenMetadata representa el maven-metadata.xml de un artefacto
+type mavenMetadata struct {
+	Versions []string `xml:"versioning>versions>version"`
+}
+
+// isLatest indica si la versión pedida es la más reciente disponible
+func isLatest(version string) bool {
+	return version == "" || strings.EqualFold(version, "latest")
+}
+
+// getPaperURL obtiene la URL de descarga de PaperMC
+func (sd *ServerDownloader) getPaperURL() (*ServerArtifact, error) {
+	return sd.getPaperMCURL("paper")
+}
+
+// getPaperMCURL obtiene la URL de descarga de un proyecto de PaperMC
+// (paper, velocity o waterfall)
+func (sd *ServerDownloader) getPaperMCURL(project string) (*ServerArtifact, error) {
+	// API: https://api.papermc.io/v2/projects/{project}/versions/{version}/builds
+	base := fmt.Sprintf("%s/projects/%s", sd.endpoints.PaperMC, project)
+
+	version := sd.version
+	if isLatest(version) {
+		var info PaperMCProject
+		if err := sd.getJSON(base, "PaperMC", &info); err != nil {
+			return nil, err
+		}
+		if len(info.Versions) == 0 {
+			return nil, fmt.Errorf("no se encontraron versiones de %s", project)
+		}
+		version = info.Versions[len(info.Versions)-1]
+	}
+
+	var builds PaperMCBuilds
+	if err := sd.getJSON(fmt.Sprintf("%s/versions/%s/builds", base, version), "PaperMC", &builds); err != nil {
+		return nil, err
+	}
+
+	if len(builds.Builds) == 0 {
+		return nil, fmt.Errorf("no se encontraron builds para la versión %s", version)
+	}
+
+	// Obtener el build estable más reciente, o el último si solo hay experimentales
+	latestBuild := builds.Builds[len(builds.Builds)-1]
+	for i := len(builds.Builds) - 1; i >= 0; i-- {
+		if builds.Builds[i].Channel == "" || builds.Builds[i].Channel == "default" {
+			latestBuild = builds.Builds[i]
+			break
+		}
+	}
+	jarName := latestBuild.Downloads.Application.Name
+
+	return &ServerArtifact{
+		Version: version,
+		URL:     fmt.Sprintf("%s/versions/%s/builds/%d/downloads/%s", base, version, latestBuild.Build, jarName),
+		Checksum: Checksum{
+			Algorithm: "sha256",
+			Value:     latestBuild.Downloads.Application.SHA256,
+		},
+	}, nil
+}
+
+// getPurpurURL obtiene la URL de descarga de Purpur
+func (sd *ServerDownloader) getPurpurURL() (*ServerArtifact, error) {
+	// API: https://api.purpurmc.org/v2/purpur/{version}
+	base := sd.endpoints.Purpur + "/purpur"
+
+	version := sd.version
+	if isLatest(version) {
+		var info struct {
+			Versions []string `json:"versions"`
+		}
+		if err := sd.getJSON(base, "Purpur", &info); err != nil {
+			return nil, err
+		}
+		if len(info.Versions) == 0 {
+			return nil, fmt.Errorf("no se encontraron versiones de Purpur")
+		}
+		version = info.Versions[len(info.Versions)-1]
+	}
+
+	var builds PurpurBuilds
+	if err := sd.getJSON(fmt.Sprintf("%s/%s", base, version), "Purpur", &builds); err != nil {
+		return nil, err
+	}
+
+	latestBuild := builds.Builds.Latest
+	if latestBuild == "" {
+		return nil, fmt.Errorf("no se encontraron builds para la versión %s", version)
+	}
+
+	// El checksum solo aparece en el detalle de cada build
+	var build PurpurBuild
+	if err := sd.getJSON(fmt.Sprintf("%s/%s/%s", base, version, latestBuild), "Purpur", &build); err != nil {
+		return nil, err
+	}
+
+	return &ServerArtifact{
+		Version:  version,
+		URL:      fmt.Sprintf("%s/%s/%s/download", base, version, latestBuild),
+		Checksum: Checksum{Algorithm: "md5", Value: build.MD5},
+	}, nil
+}
+
+// getVanillaURL obtiene la URL de descarga de Minecraft Vanilla a partir del
+// manifest de versiones de Mojang
+func (sd *ServerDownloader) getVanillaURL() (*ServerArtifact, error) {
+	var manifest MojangManifest
+	if err := sd.getJSON(sd.endpoints.MojangManifest, "Mojang", &manifest); err != nil {
+		return nil, err
+	}
+
+	version := sd.version
+	if isLatest(version) {
+		version = manifest.Latest.Release
+	}
+
+	for _, v := range manifest.Versions {
+		if v.ID != version {
+			continue
+		}
+
+		body, err := sd.get(v.URL, "Mojang")
+		if err != nil {
+			return nil, err
+		}
+
+		// El manifest incluye el SHA1 de los metadatos de cada versión
+		if v.SHA1 != "" {
+			sum := sha1.Sum(body)
+			if actual := hex.EncodeToString(sum[:]); actual != v.SHA1 {
+				return nil, fmt.Errorf("checksum SHA1 de los metadatos de %s no coincide. Esperado: %s, Obtenido: %s", version, v.SHA1, actual)
+			}
+		}
+
+		var meta MojangVersion
+		if err := json.Unmarshal(body, &meta); err != nil {
+			return nil, fmt.Errorf("error decodificando respuesta de Mojang: %w", err)
+		}
+
+		server := meta.Downloads.Server
+		if server == nil || server.URL == "" {
+			return nil, fmt.Errorf("la versión %s no tiene servidor dedicado", version)
+		}
+
+		return &ServerArtifact{
+			Version:  version,
+			URL:      server.URL,
+			Checksum: Checksum{Algorithm: "sha1", Value: server.SHA1},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("versión de Minecraft no encontrada: %s", version)
+}
+
+// getFabricURL obtiene el instalador de Fabric. La instalación genera
+// fabric-server-launch.jar junto al server.jar de Mojang.
+func (sd *ServerDownloader) getFabricURL() (*ServerArtifact, error) {
+	// API: https://meta.fabricmc.net/v2/versions/loader/{version}
+	base := sd.endpoints.FabricMeta + "/versions"
+
+	version := sd.version
+	if isLatest(version) {
+		var games []FabricGameVersion
+		if err := sd.getJSON(base+"/game", "Fabric", &games); err != nil {
+			return nil, err
+		}
+		for _, game := range games {
+			if game.Stable {
+				version = game.Version
+				break
+			}
+		}
+		if isLatest(version) {
+			return nil, fmt.Errorf("no se encontraron versiones estables de Minecraft en Fabric")
+		}
+	}
+
+	var loaders []FabricLoader
+	if err := sd.getJSON(fmt.Sprintf("%s/loader/%s", base, version), "Fabric", &loaders); err != nil {
+		return nil, err
+	}
+	if len(loaders) == 0 {
+		return nil, fmt.Errorf("Fabric no soporta la versión %s", version)
+	}
+
+	// Las listas de Fabric meta vienen ordenadas de más reciente a más antigua
+	loader := loaders[0].Loader.Version
+	for _, l := range loaders {
+		if l.Loader.Stable {
+			loader = l.Loader.Version
+			break
+		}
+	}
+
+	var installers []FabricInstaller
+	if err := sd.getJSON(base+"/installer", "Fabric", &installers); err != nil {
+		return nil, err
+	}
+	if len(installers) == 0 {
+		return nil, fmt.Errorf("no se encontraron instaladores de Fabric")
+	}
+
+	installer := installers[0]
+	for _, i := range installers {
+		if i.Stable {
+			installer = i
+			break
+		}
+	}
+
+	checksum, err := sd.mavenSHA1(installer.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServerArtifact{
+		Version:  version,
+		URL:      installer.URL,
+		FileName: fmt.Sprintf("fabric-installer-%s.jar", installer.Version),
+		Checksum: checksum,
+		Installer: &InstallerSpec{
+			Args:        []string{"server", "-mcversion", version, "-loader", loader, "-downloadMinecraft"},
+			LaunchFiles: []string{"fabric-server-launch.jar"},
+		},
+	}, nil
+}
+
+// getForgeURL obtiene el instalador de Forge. Acepta una versión de
+// Minecraft (usa la recomendada o, si no hay, la última) o una versión
+// completa como 1.20.1-47.2.0.
+func (sd *ServerDownloader) getForgeURL() (*ServerArtifact, error) {
+	fullVersion := sd.version
+	if !strings.Contains(fullVersion, "-") {
+		var promotions ForgePromotions
+		if err := sd.getJSON(sd.endpoints.ForgeFiles+"/promotions_slim.json", "Forge", &promotions); err != nil {
+			return nil, err
+		}
+
+		forge := promotions.Promos[sd.version+"-recommended"]
+		if forge == "" {
+			forge = promotions.Promos[sd.version+"-latest"]
+		}
+		if forge == "" {
+			return nil, fmt.Errorf("no hay builds de Forge para la versión %s", sd.version)
+		}
+		fullVersion = sd.version + "-" + forge
+	}
+
+	url := fmt.Sprintf("%s/net/minecraftforge/forge/%s/forge-%s-installer.jar", sd.endpoints.ForgeMaven, fullVersion, fullVersion)
+	checksum, err := sd.mavenSHA1(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServerArtifact{
+		Version:  fullVersion,
+		URL:      url,
+		FileName: fmt.Sprintf("forge-%s-installer.jar", fullVersion),
+		Checksum: checksum,
+		Installer: &InstallerSpec{
+			Args: []string{"--installServer", "."},
+			// Desde 1.17 Forge arranca con un archivo de argumentos; antes
+			// generaba un JAR ejecutable
+			LaunchFiles: []string{
+				fmt.Sprintf("libraries/net/minecraftforge/forge/%s/%s", fullVersion, argsFileName()),
+				fmt.Sprintf("forge-%s.jar", fullVersion),
+				fmt.Sprintf("forge-%s-universal.jar", fullVersion),
+			},
+		},
+	}, nil
+}
+
+// getNeoForgeURL obtiene el instalador de NeoForge. Acepta una versión de
+// Minecraft (1.21.1 se traduce a la última 21.1.x estable) o una versión de
+// NeoForge concreta como 21.1.77.
+func (sd *ServerDownloader) getNeoForgeURL() (*ServerArtifact, error) {
+	base := sd.endpoints.NeoForgeMaven + "/net/neoforged/neoforge"
+
+	version := sd.version
+	if isLatest(version) || strings.HasPrefix(version, "1.") {
+		prefix := ""
+		if !isLatest(version) {
+			var err error
+			if prefix, err = neoForgePrefix(version); err != nil {
+				return nil, err
+			}
+		}
+
+		body, err := sd.get(base+"/maven-metadata.xml", "NeoForge")
+		if err != nil {
+			return nil, err
+		}
+
+		var metadata mavenMetadata
+		if err := xml.Unmarshal(body, &metadata); err != nil {
+			return nil, fmt.Errorf("error decodificando respuesta de NeoForge: %w", err)
+		}
+
+		if version = latestNeoForge(metadata.Versions, prefix); version == "" {
+			return nil, fmt.Errorf("no hay builds de NeoForge para la versión %s", sd.version)
+		}
+	}
+
+	url := fmt.Sprintf("%s/%s/neoforge-%s-installer.jar", base, version, version)
+	checksum, err := sd.mavenSHA1(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServerArtifact{
+		Version:  version,
+		URL:      url,
+		FileName: fmt.Sprintf("neoforge-%s-installer.jar", version),
+		Checksum: checksum,
+		Installer: &InstallerSpec{
+			Args: []string{"--installServer", "."},
+			LaunchFiles: []string{
+				fmt.Sprintf("libraries/net/neoforged/neoforge/%s/%s", version, argsFileName()),
+			},
+		},
+	}, nil
+}
+
+// neoForgePrefix traduce una versión de Minecraft al prefijo de versiones
+// de NeoForge: 1.21.1 -> "21.1.", 1.21 -> "21.0."
+func neoForgePrefix(minecraft string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(minecraft, "1."), ".")
+	switch len(parts) {
+	case 1:
+		return parts[0] + ".0.", nil
+	case 2:
+		return parts[0] + "." + parts[1] + ".", nil
+	default:
+		return "", fmt.Errorf("versión de Minecraft inválida para NeoForge: %s", minecraft)
+	}
+}
+
+// latestNeoForge elige la última versión con el prefijo dado, prefiriendo
+// las que no son beta
+func latestNeoForge(versions []string, prefix string) string {
+	latest := ""
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		if !strings.HasPrefix(v, prefix) {
+			continue
+		}
+		if !strings.Contains(v, "-beta") {
+			return v
+		}
+		if latest == "" {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// argsFileName nombre del archivo de argumentos que generan los
+// instaladores modernos de Forge y NeoForge
+func argsFileName() string {
+	if runtime.GOOS == "windows" {
+		return "win_args.txt"
+	}
+	return "unix_args.txt"
+}
+
+// mavenSHA1 obtiene el checksum publicado junto a un artefacto Maven
+func (sd *ServerDownloader) mavenSHA1(artifactURL string) (Checksum, error) {
+	body, err := sd.get(artifactURL+".sha1", "Maven")
+	if err != nil {
+		return Checksum{}, err
+	}
+
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 {
+		return Checksum{}, fmt.Errorf("checksum vacío para %s", artifactURL)
+	}
+
+	return Checksum{Algorithm: "sha1", Value: fields[0]}, nil
+}
+
+// get descarga el cuerpo de una respuesta de la API de una plataforma
+func (sd *ServerDownloader) get(url, source string) ([]byte, error) {
+	resp, err := sd.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando API de %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API de %s retornó código %d", source, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo respuesta de %s: %w", source, err)
+	}
+	return body, nil
+}
+
+// getJSON consulta la API de una plataforma y decodifica la respuesta
+func (sd *ServerDownloader) getJSON(url, source string, target interface{}) error {
+	body, err := sd.get(url, source)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("error decodificando respuesta de %s: %w", source, err)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// RestartPolicy política de reinicio automático tras un crash
+type RestartPolicy struct {
+	MaxRestarts    int           `json:"max_restarts"`    // Reinicios permitidos dentro de la ventana
+	Window         time.Duration `json:"window"`          // Ventana de tiempo para contar reinicios
+	InitialBackoff time.Duration `json:"initial_backoff"` // Espera antes del primer reinicio
+	MaxBackoff     time.Duration `json:"max_backoff"`     // Espera máxima entre reinicios
+}
+
+// DefaultRestartPolicy retorna la política de reinicio por defecto
+func DefaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		MaxRestarts:    5,
+		Window:         10 * time.Minute,
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     5 * time.Minute,
+	}
+}
+
+// withDefaults completa los campos no configurados
+func (p RestartPolicy) withDefaults() RestartPolicy {
+	def := DefaultRestartPolicy()
+	if p.MaxRestarts <= 0 {
+		p.MaxRestarts = def.MaxRestarts
+	}
+	if p.Window <= 0 {
+		p.Window = def.Window
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = def.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = def.MaxBackoff
+	}
+	return p
+}
+
+// Backoff calcula la espera para el intento indicado (1 = primer reintento)
+// con crecimiento exponencial limitado por MaxBackoff
+func (p RestartPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return backoff
+}
+
+// restartState historial de reinicios automáticos de un servidor
+type restartState struct {
+	attempts []time.Time
+	timer    *time.Timer
+}
+
+// prune descarta los intentos fuera de la ventana
+func (r *restartState) prune(now time.Time, window time.Duration) {
+	kept := r.attempts[:0]
+	for _, t := range r.attempts {
+		if now.Sub(t) < window {
+			kept = append(kept, t)
+		}
+	}
+	r.attempts = kept
+}
+
+// handleProcessExit actualiza el registro cuando un proceso termina y
+// decide si se trata de un crash que requiere reinicio
+func (a *Agent) handleProcessExit(serverID string, exitCode int, stopRequested bool) {
+	// Un reinicio rápido puede haber lanzado ya un nuevo proceso
+	if a.executor.IsRunning(serverID) {
+		return
+	}
+
+	a.serversMux.Lock()
+	defer a.serversMux.Unlock()
+
+	server, exists := a.servers[serverID]
+	if !exists {
+		return
+	}
+
+	server.PID = 0
+	server.LastExitCode = exitCode
+
+	// Salida solicitada o con código 0: detención normal
+	if stopRequested || exitCode == 0 {
+		server.Status = StatusStopped
+		a.saveStateLocked()
+		a.events.Publish(ServerEvent{
+			ServerID: serverID,
+			Type:     EventServerStopped,
+			Status:   server.Status,
+			ExitCode: exitCode,
+		})
+		log.Printf("[INFO] Servidor %s detenido (código %d)", serverID, exitCode)
+		return
+	}
+
+	reason := fmt.Sprintf("el proceso terminó inesperadamente con código %d", exitCode)
+	log.Printf("[WARN] Servidor %s crasheó: %s", serverID, reason)
+
+	a.events.Publish(ServerEvent{
+		ServerID: serverID,
+		Type:     EventServerCrashed,
+		Status:   StatusCrashed,
+		ExitCode: exitCode,
+		Reason:   reason,
+	})
+
+	if !server.Config.AutoRestart {
+		server.Status = StatusCrashed
+		a.saveStateLocked()
+		return
+	}
+
+	a.scheduleRestartLocked(server, reason)
+}
+
+// scheduleRestartLocked programa un reinicio con backoff exponencial o
+// marca el servidor como crasheado si se superó el límite.
+// Requiere tener serversMux tomado.
+func (a *Agent) scheduleRestartLocked(server *MinecraftServer, reason string) {
+	policy := a.config.RestartPolicy.withDefaults()
+
+	tracker, exists := a.restarts[server.ID]
+	if !exists {
+		tracker = &restartState{}
+		a.restarts[server.ID] = tracker
+	}
+	if tracker.timer != nil {
+		tracker.timer.Stop()
+		tracker.timer = nil
+	}
+
+	now := time.Now()
+	tracker.prune(now, policy.Window)
+
+	if len(tracker.attempts) >= policy.MaxRestarts {
+		server.Status = StatusCrashed
+		a.saveStateLocked()
+
+		limitReason := fmt.Sprintf("límite de %d reinicios en %v alcanzado: %s",
+			policy.MaxRestarts, policy.Window, reason)
+		a.events.Publish(ServerEvent{
+			ServerID:    server.ID,
+			Type:        EventRestartLimit,
+			Status:      server.Status,
+			ExitCode:    server.LastExitCode,
+			Attempt:     len(tracker.attempts),
+			MaxAttempts: policy.MaxRestarts,
+			Reason:      limitReason,
+		})
+		log.Printf("[ERROR] Servidor %s: %s", server.ID, limitReason)
+		return
+	}
+
+	tracker.attempts = append(tracker.attempts, now)
+	attempt := len(tracker.attempts)
+	backoff := policy.Backoff(attempt)
+
+	server.Status = StatusStarting
+	server.RestartCount = attempt
+	a.saveStateLocked()
+
+	a.events.Publish(ServerEvent{
+		ServerID:    server.ID,
+		Type:        EventRestartAttempt,
+		Status:      server.Status,
+		ExitCode:    server.LastExitCode,
+		Attempt:     attempt,
+		MaxAttempts: policy.MaxRestarts,
+		Backoff:     backoff,
+		Reason:      reason,
+	})
+	log.Printf("[INFO] Reinicio automático de %s en %v (intento %d/%d)",
+		server.ID, backoff, attempt, policy.MaxRestarts)
+
+	serverID := server.ID
+	tracker.timer = time.AfterFunc(backoff, func() {
+		a.executeRestart(serverID)
+	})
+}
+
+// executeRestart ejecuta un reinicio programado
+func (a *Agent) executeRestart(serverID string) {
+	a.serversMux.Lock()
+	defer a.serversMux.Unlock()
+
+	server, exists := a.servers[serverID]
+	if !exists || server.Status != StatusStarting {
+		// Reinicio cancelado (servidor detenido o eliminado)
+		return
+	}
+
+	if tracker, ok := a.restarts[serverID]; ok {
+		tracker.timer = nil
+	}
+
+	if err := a.executor.StartServer(serverID, server.Config); err != nil {
+		reason := fmt.Sprintf("error reiniciando servidor: %v", err)
+		a.events.Publish(ServerEvent{
+			ServerID: serverID,
+			Type:     EventRestartFailed,
+			Status:   server.Status,
+			Attempt:  server.RestartCount,
+			Reason:   reason,
+		})
+		log.Printf("[ERROR] Servidor %s: %s", serverID, reason)
+		a.scheduleRestartLocked(server, reason)
+		return
+	}
+
+	server.Status = StatusRunning
+	server.StartTime = time.Now()
+	if pid, err := a.executor.GetPID(serverID); err == nil {
+		server.PID = pid
+	}
+	a.saveStateLocked()
+
+	a.events.Publish(ServerEvent{
+		ServerID: serverID,
+		Type:     EventServerStarted,
+		Status:   server.Status,
+		Attempt:  server.RestartCount,
+		Reason:   "reinicio automático",
+	})
+	log.Printf("[INFO] Servidor %s reiniciado automáticamente", serverID)
+}
+
+// cancelPendingRestartLocked cancela un reinicio programado.
+// Retorna true si había uno pendiente. Requiere tener serversMux tomado.
+func (a *Agent) cancelPendingRestartLocked(serverID string) bool {
+	tracker, exists := a.restarts[serverID]
+	if !exists || tracker.timer == nil {
+		return false
+	}
+
+	tracker.timer.Stop()
+	tracker.timer = nil
+	return true
+}
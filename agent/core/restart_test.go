@@ -0,0 +1,196 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRestartPolicyBackoff(t *testing.T) {
+	policy := RestartPolicy{
+		MaxRestarts:    5,
+		Window:         time.Minute,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Second,
+	}
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{10, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempt); got != tt.expected {
+			t.Errorf("Backoff(%d) = %v, esperado %v", tt.attempt, got, tt.expected)
+		}
+	}
+}
+
+func TestRestartPolicyDefaults(t *testing.T) {
+	policy := RestartPolicy{}.withDefaults()
+	def := DefaultRestartPolicy()
+
+	if policy != def {
+		t.Errorf("Política por defecto esperada %+v, obtenida %+v", def, policy)
+	}
+}
+
+// newRestartTestAgent crea un agente con un servidor registrado y una
+// política cuyo backoff nunca se cumple durante el test
+func newRestartTestAgent(t *testing.T, autoRestart bool, maxRestarts int) *Agent {
+	t.Helper()
+
+	config := &Config{
+		WorkDir:    t.TempDir(),
+		MaxServers: 5,
+		RestartPolicy: RestartPolicy{
+			MaxRestarts:    maxRestarts,
+			Window:         time.Hour,
+			InitialBackoff: time.Hour,
+			MaxBackoff:     time.Hour,
+		},
+	}
+
+	agent, err := NewAgent(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Error creando agente: %v", err)
+	}
+
+	agent.serversMux.Lock()
+	agent.servers["srv"] = &MinecraftServer{
+		ID:     "srv",
+		Status: StatusRunning,
+		PID:    1234,
+		Config: ServerConfig{AutoRestart: autoRestart},
+	}
+	agent.serversMux.Unlock()
+
+	t.Cleanup(agent.Shutdown)
+	return agent
+}
+
+// nextEvent espera el siguiente evento del tipo indicado
+func nextEvent(t *testing.T, events <-chan ServerEvent, eventType ServerEventType) ServerEvent {
+	t.Helper()
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == eventType {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("No se recibió evento %s", eventType)
+		}
+	}
+}
+
+func TestGracefulExitMarksStopped(t *testing.T) {
+	agent := newRestartTestAgent(t, true, 3)
+	_, events := agent.Events().Subscribe(10)
+
+	agent.handleProcessExit("srv", 0, false)
+
+	server, _ := agent.GetServer("srv")
+	if server.Status != StatusStopped {
+		t.Errorf("Estado esperado stopped, obtenido %s", server.Status)
+	}
+	nextEvent(t, events, EventServerStopped)
+}
+
+func TestStopRequestedIsNotCrash(t *testing.T) {
+	agent := newRestartTestAgent(t, true, 3)
+
+	// SIGTERM tras timeout de stop: código distinto de cero pero solicitado
+	agent.handleProcessExit("srv", 143, true)
+
+	server, _ := agent.GetServer("srv")
+	if server.Status != StatusStopped {
+		t.Errorf("Estado esperado stopped, obtenido %s", server.Status)
+	}
+}
+
+func TestCrashWithoutAutoRestart(t *testing.T) {
+	agent := newRestartTestAgent(t, false, 3)
+	_, events := agent.Events().Subscribe(10)
+
+	agent.handleProcessExit("srv", 1, false)
+
+	server, _ := agent.GetServer("srv")
+	if server.Status != StatusCrashed {
+		t.Errorf("Estado esperado crashed, obtenido %s", server.Status)
+	}
+	if server.LastExitCode != 1 {
+		t.Errorf("LastExitCode esperado 1, obtenido %d", server.LastExitCode)
+	}
+	nextEvent(t, events, EventServerCrashed)
+}
+
+func TestCrashSchedulesRestartUntilLimit(t *testing.T) {
+	agent := newRestartTestAgent(t, true, 2)
+	_, events := agent.Events().Subscribe(20)
+
+	// Primer crash: reinicio programado
+	agent.handleProcessExit("srv", 1, false)
+
+	event := nextEvent(t, events, EventRestartAttempt)
+	if event.Attempt != 1 || event.MaxAttempts != 2 {
+		t.Errorf("Intento esperado 1/2, obtenido %d/%d", event.Attempt, event.MaxAttempts)
+	}
+	if event.Backoff != time.Hour {
+		t.Errorf("Backoff esperado 1h, obtenido %v", event.Backoff)
+	}
+	if event.Reason == "" {
+		t.Error("El evento de reinicio debería incluir el motivo")
+	}
+
+	server, _ := agent.GetServer("srv")
+	if server.Status != StatusStarting {
+		t.Errorf("Estado esperado starting, obtenido %s", server.Status)
+	}
+
+	// Segundo crash: segundo intento
+	agent.handleProcessExit("srv", 1, false)
+	event = nextEvent(t, events, EventRestartAttempt)
+	if event.Attempt != 2 {
+		t.Errorf("Intento esperado 2, obtenido %d", event.Attempt)
+	}
+
+	// Tercer crash: límite alcanzado
+	agent.handleProcessExit("srv", 1, false)
+	nextEvent(t, events, EventRestartLimit)
+
+	server, _ = agent.GetServer("srv")
+	if server.Status != StatusCrashed {
+		t.Errorf("Estado esperado crashed, obtenido %s", server.Status)
+	}
+}
+
+func TestStopCancelsPendingRestart(t *testing.T) {
+	agent := newRestartTestAgent(t, true, 3)
+
+	agent.handleProcessExit("srv", 1, false)
+
+	if err := agent.StopServer("srv"); err != nil {
+		t.Fatalf("Error deteniendo servidor con reinicio pendiente: %v", err)
+	}
+
+	server, _ := agent.GetServer("srv")
+	if server.Status != StatusStopped {
+		t.Errorf("Estado esperado stopped, obtenido %s", server.Status)
+	}
+
+	// El timer cancelado no debe relanzar el servidor
+	agent.executeRestart("srv")
+	if server.Status != StatusStopped {
+		t.Errorf("Un reinicio cancelado no debería cambiar el estado, obtenido %s", server.Status)
+	}
+}
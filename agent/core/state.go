@@ -0,0 +1,190 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// stateFileVersion versión del formato del archivo de estado
+const stateFileVersion = 1
+
+// defaultStateFileName nombre del archivo de estado dentro de WorkDir
+const defaultStateFileName = ".aymc-state.json"
+
+// ServerState estado persistido de un servidor
+type ServerState struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Type        string       `json:"type"`
+	Version     string       `json:"version"`
+	JavaVersion string       `json:"java_version"`
+	Port        int          `json:"port"`
+	Status      ServerStatus `json:"status"`
+	PID         int          `json:"pid"`
+	StartTime   time.Time    `json:"start_time"`
+	WorkDir     string       `json:"work_dir"`
+	Config      ServerConfig `json:"config"`
+}
+
+// AgentState contenido del archivo de estado del agente
+type AgentState struct {
+	Version   int           `json:"version"`
+	AgentID   string        `json:"agent_id"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Servers   []ServerState `json:"servers"`
+}
+
+// StateStore persiste el registro de servidores en disco
+type StateStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStateStore crea un nuevo almacén de estado en la ruta indicada
+func NewStateStore(path string) *StateStore {
+	return &StateStore{path: path}
+}
+
+// Path retorna la ruta del archivo de estado
+func (s *StateStore) Path() string {
+	return s.path
+}
+
+// Load lee el estado desde disco. Si el archivo no existe retorna un estado vacío
+func (s *StateStore) Load() (*AgentState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &AgentState{Version: stateFileVersion}, nil
+		}
+		return nil, fmt.Errorf("error leyendo estado: %w", err)
+	}
+
+	var state AgentState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error parseando estado: %w", err)
+	}
+
+	if state.Version > stateFileVersion {
+		return nil, fmt.Errorf("versión de estado no soportada: %d", state.Version)
+	}
+
+	return &state, nil
+}
+
+// Save escribe el estado de forma atómica (archivo temporal + rename)
+func (s *StateStore) Save(state *AgentState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state.Version = stateFileVersion
+	state.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializando estado: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creando directorio de estado: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creando archivo temporal: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("error escribiendo estado: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("error sincronizando estado: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error cerrando estado: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error reemplazando estado: %w", err)
+	}
+
+	return nil
+}
+
+// newServerState construye el estado persistible de un servidor
+func newServerState(server *MinecraftServer) ServerState {
+	return ServerState{
+		ID:          server.ID,
+		Name:        server.Name,
+		Type:        server.Type,
+		Version:     server.Version,
+		JavaVersion: server.JavaVersion,
+		Port:        server.Port,
+		Status:      server.Status,
+		PID:         server.PID,
+		StartTime:   server.StartTime,
+		WorkDir:     server.WorkDir,
+		Config:      server.Config,
+	}
+}
+
+// toServer reconstruye un MinecraftServer desde su estado persistido
+func (s ServerState) toServer() *MinecraftServer {
+	return &MinecraftServer{
+		ID:          s.ID,
+		Name:        s.Name,
+		Type:        s.Type,
+		Version:     s.Version,
+		JavaVersion: s.JavaVersion,
+		Port:        s.Port,
+		Status:      s.Status,
+		PID:         s.PID,
+		StartTime:   s.StartTime,
+		WorkDir:     s.WorkDir,
+		Config:      s.Config,
+	}
+}
+
+// matchesServerProcess verifica que el PID siga vivo y pertenezca al
+// servidor (evita adoptar un PID reutilizado por otro proceso)
+func matchesServerProcess(pid int, workDir string) bool {
+	if !processAlive(pid) {
+		return false
+	}
+
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return false
+	}
+
+	if cwd, err := proc.Cwd(); err == nil {
+		return filepath.Clean(cwd) == filepath.Clean(workDir)
+	}
+
+	// Sin acceso al cwd, aceptar solo procesos Java
+	name, err := proc.Name()
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(name, "java")
+}
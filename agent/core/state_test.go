@@ -0,0 +1,179 @@
+package core
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateStoreRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStateStore(filepath.Join(tmpDir, "state.json"))
+
+	// Sin archivo, estado vacío
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("Error cargando estado inexistente: %v", err)
+	}
+	if len(state.Servers) != 0 {
+		t.Errorf("Esperados 0 servidores, obtenidos %d", len(state.Servers))
+	}
+
+	server := &MinecraftServer{
+		ID:      "srv-1",
+		Name:    "Survival",
+		Type:    "paper",
+		Version: "1.20.4",
+		Port:    25565,
+		Status:  StatusStopped,
+		WorkDir: filepath.Join(tmpDir, "srv-1"),
+		Config: ServerConfig{
+			MinRAM:      "1G",
+			MaxRAM:      "4G",
+			JarFile:     "server.jar",
+			AutoRestart: true,
+		},
+	}
+
+	err = store.Save(&AgentState{AgentID: "agent-1", Servers: []ServerState{newServerState(server)}})
+	if err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Error cargando estado: %v", err)
+	}
+
+	if loaded.AgentID != "agent-1" {
+		t.Errorf("AgentID esperado 'agent-1', obtenido '%s'", loaded.AgentID)
+	}
+	if len(loaded.Servers) != 1 {
+		t.Fatalf("Esperado 1 servidor, obtenidos %d", len(loaded.Servers))
+	}
+
+	restored := loaded.Servers[0].toServer()
+	if restored.Name != "Survival" || restored.Port != 25565 {
+		t.Errorf("Servidor restaurado incorrecto: %+v", restored)
+	}
+	if restored.Config.MaxRAM != "4G" || !restored.Config.AutoRestart {
+		t.Errorf("ServerConfig restaurada incorrecta: %+v", restored.Config)
+	}
+}
+
+func TestNewAgentRestoresServers(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStateStore(filepath.Join(tmpDir, defaultStateFileName))
+
+	// Un servidor detenido y otro "en ejecución" cuyo proceso ya no existe
+	err := store.Save(&AgentState{Servers: []ServerState{
+		{ID: "stopped", Name: "Stopped", Status: StatusStopped},
+		{ID: "dead", Name: "Dead", Status: StatusRunning, PID: 999999},
+	}})
+	if err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	agent, err := NewAgent(context.Background(), &Config{WorkDir: tmpDir, MaxServers: 5})
+	if err != nil {
+		t.Fatalf("Error creando agente: %v", err)
+	}
+
+	if len(agent.ListServers()) != 2 {
+		t.Fatalf("Esperados 2 servidores restaurados, obtenidos %d", len(agent.ListServers()))
+	}
+
+	dead, err := agent.GetServer("dead")
+	if err != nil {
+		t.Fatalf("Servidor 'dead' no restaurado: %v", err)
+	}
+	if dead.Status != StatusStopped || dead.PID != 0 {
+		t.Errorf("Servidor sin proceso debería quedar detenido, obtenido %s (PID %d)", dead.Status, dead.PID)
+	}
+	if dead.WorkDir != filepath.Join(tmpDir, "dead") {
+		t.Errorf("WorkDir inesperado: %s", dead.WorkDir)
+	}
+
+	// El resultado de la reconciliación se persiste
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("Error cargando estado: %v", err)
+	}
+	for _, s := range state.Servers {
+		if s.ID == "dead" && s.Status != StatusStopped {
+			t.Errorf("Estado persistido de 'dead' esperado stopped, obtenido %s", s.Status)
+		}
+	}
+}
+
+func TestNewAgentAdoptsRunningProcess(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep no disponible")
+	}
+
+	tmpDir := t.TempDir()
+	serverDir := filepath.Join(tmpDir, "live")
+	if err := os.MkdirAll(serverDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simular un servidor que sobrevivió al reinicio del agente
+	cmd := exec.Command("sleep", "30")
+	cmd.Dir = serverDir
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Error iniciando proceso: %v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	defer cmd.Process.Kill()
+
+	store := NewStateStore(filepath.Join(tmpDir, defaultStateFileName))
+	err := store.Save(&AgentState{Servers: []ServerState{
+		{ID: "live", Status: StatusRunning, PID: cmd.Process.Pid, WorkDir: serverDir},
+	}})
+	if err != nil {
+		t.Fatalf("Error guardando estado: %v", err)
+	}
+
+	agent, err := NewAgent(context.Background(), &Config{WorkDir: tmpDir, MaxServers: 5})
+	if err != nil {
+		t.Fatalf("Error creando agente: %v", err)
+	}
+
+	server, err := agent.GetServer("live")
+	if err != nil {
+		t.Fatalf("Servidor no restaurado: %v", err)
+	}
+	if server.Status != StatusRunning {
+		t.Errorf("Estado esperado running, obtenido %s", server.Status)
+	}
+	if !agent.GetExecutor().IsRunning("live") {
+		t.Error("El executor debería haber adoptado el proceso")
+	}
+
+	// Detener el proceso adoptado (vía SIGTERM)
+	if err := agent.StopServer("live"); err != nil {
+		t.Fatalf("Error deteniendo servidor adoptado: %v", err)
+	}
+
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("El proceso adoptado no terminó")
+	}
+
+	// La definición se conserva tras detener
+	server, err = agent.GetServer("live")
+	if err != nil {
+		t.Fatalf("La definición debería conservarse: %v", err)
+	}
+	if server.Status != StatusStopped {
+		t.Errorf("Estado esperado stopped, obtenido %s", server.Status)
+	}
+}
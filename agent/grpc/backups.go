@@ -0,0 +1,241 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "github.com/aymc/agent/grpc/pb"
+	"github.com/aymc/agent/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backupChunkSize tamaño de cada fragmento enviado en DownloadBackup
+const backupChunkSize = 1024 * 1024
+
+// DownloadBackup envía un archivo del directorio de backups en fragmentos
+func (s *agentServiceImpl) DownloadBackup(req *pb.BackupFileRequest, stream pb.AgentService_DownloadBackupServer) error {
+	path, err := s.agent.ResolveBackupPath(req.Path)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return status.Errorf(codes.NotFound, "backup no encontrado: %s", req.Path)
+		}
+		return status.Errorf(codes.Internal, "error abriendo backup: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return status.Errorf(codes.Internal, "error leyendo backup: %v", err)
+	}
+
+	log.Printf("[INFO] DownloadBackup: enviando %s (%d bytes)", path, info.Size())
+
+	buffer := make([]byte, backupChunkSize)
+	var offset int64
+	for {
+		n, err := file.Read(buffer)
+		if n > 0 {
+			chunk := &pb.BackupChunk{
+				Data:      buffer[:n],
+				Offset:    offset,
+				TotalSize: info.Size(),
+			}
+			if err := stream.Send(chunk); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "error leyendo backup: %v", err)
+		}
+	}
+}
+
+// UploadBackup recibe un archivo en fragmentos y lo guarda en el
+// directorio de backups. El archivo se escribe con un nombre temporal y
+// solo se renombra si la subida se completa.
+func (s *agentServiceImpl) UploadBackup(stream pb.AgentService_UploadBackupServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	path, err := s.agent.ResolveBackupPath(first.Path)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return status.Errorf(codes.Internal, "error creando directorio: %v", err)
+	}
+
+	tmpPath := path + ".upload"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return status.Errorf(codes.Internal, "error creando archivo: %v", err)
+	}
+
+	hasher := sha256.New()
+	writer := io.MultiWriter(file, hasher)
+
+	size, err := receiveBackupChunks(stream, first, writer)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return status.Errorf(codes.Internal, "error recibiendo backup: %v", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return status.Errorf(codes.Internal, "error guardando backup: %v", err)
+	}
+
+	log.Printf("[INFO] UploadBackup: recibido %s (%d bytes)", path, size)
+
+	return stream.SendAndClose(&pb.BackupFileResponse{
+		Success:   true,
+		Message:   "Backup recibido",
+		Path:      path,
+		SizeBytes: size,
+		Checksum:  hex.EncodeToString(hasher.Sum(nil)),
+	})
+}
+
+// receiveBackupChunks escribe los fragmentos recibidos en orden
+func receiveBackupChunks(stream pb.AgentService_UploadBackupServer, chunk *pb.BackupChunk, writer io.Writer) (int64, error) {
+	var written int64
+	for {
+		if chunk.Offset != written {
+			return written, fmt.Errorf("fragmento fuera de orden: offset %d, esperado %d", chunk.Offset, written)
+		}
+
+		n, err := writer.Write(chunk.Data)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		chunk, err = stream.Recv()
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// DeleteBackup elimina un archivo del directorio de backups
+func (s *agentServiceImpl) DeleteBackup(ctx context.Context, req *pb.BackupFileRequest) (*pb.BackupFileResponse, error) {
+	path, err := s.agent.ResolveBackupPath(req.Path)
+	if err != nil {
+		return &pb.BackupFileResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return &pb.BackupFileResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error eliminando backup: %v", err),
+		}, nil
+	}
+
+	log.Printf("[INFO] Backup eliminado: %s", path)
+
+	return &pb.BackupFileResponse{
+		Success: true,
+		Message: "Backup eliminado",
+		Path:    path,
+	}, nil
+}
+
+// snapshotResponse construye la respuesta de CreateBackup para un snapshot
+func (s *agentServiceImpl) snapshotResponse(snapshot *utils.Snapshot, startTime time.Time) *pb.CreateBackupResponse {
+	checksum, err := s.snapshots.ManifestChecksum(snapshot.ServerID, snapshot.ID)
+	if err != nil {
+		log.Printf("[WARN] Error calculando checksum del snapshot %s: %v", snapshot.ID, err)
+	}
+
+	duration := time.Since(startTime).Milliseconds()
+
+	log.Printf("[INFO] Snapshot creado: %s (%d archivos, %d bytes, %d bytes nuevos) en %d ms",
+		snapshot.ID, len(snapshot.Files), snapshot.TotalSize, snapshot.NewBytes, duration)
+
+	return &pb.CreateBackupResponse{
+		Success:    true,
+		Message:    "Snapshot creado exitosamente",
+		SizeBytes:  snapshot.TotalSize,
+		Checksum:   checksum,
+		DurationMs: duration,
+		SnapshotId: snapshot.ID,
+		NewBytes:   snapshot.NewBytes,
+	}
+}
+
+// ListSnapshots lista los snapshots incrementales de un servidor
+func (s *agentServiceImpl) ListSnapshots(ctx context.Context, req *pb.ListSnapshotsRequest) (*pb.ListSnapshotsResponse, error) {
+	snapshots, err := s.snapshots.ListSnapshots(req.ServerId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	resp := &pb.ListSnapshotsResponse{}
+	for _, snapshot := range snapshots {
+		resp.Snapshots = append(resp.Snapshots, &pb.SnapshotInfo{
+			Id:        snapshot.ID,
+			ParentId:  snapshot.Parent,
+			CreatedAt: snapshot.CreatedAt.Unix(),
+			FileCount: int32(len(snapshot.Files)),
+			TotalSize: snapshot.TotalSize,
+			NewBytes:  snapshot.NewBytes,
+		})
+	}
+
+	return resp, nil
+}
+
+// PruneSnapshots elimina snapshots y recolecta los fragmentos sin uso
+func (s *agentServiceImpl) PruneSnapshots(ctx context.Context, req *pb.PruneSnapshotsRequest) (*pb.PruneSnapshotsResponse, error) {
+	deleted, stats, err := s.snapshots.Prune(req.ServerId, req.SnapshotIds, int(req.KeepLast))
+	if err != nil {
+		return &pb.PruneSnapshotsResponse{
+			Success:    false,
+			Message:    fmt.Sprintf("Error eliminando snapshots: %v", err),
+			DeletedIds: deleted,
+		}, nil
+	}
+
+	log.Printf("[INFO] Snapshots eliminados para %s: %d (%d fragmentos, %d bytes liberados)",
+		req.ServerId, len(deleted), stats.ChunksRemoved, stats.BytesFreed)
+
+	return &pb.PruneSnapshotsResponse{
+		Success:       true,
+		Message:       "Snapshots eliminados",
+		DeletedIds:    deleted,
+		ChunksRemoved: int32(stats.ChunksRemoved),
+		BytesFreed:    stats.BytesFreed,
+	}, nil
+}
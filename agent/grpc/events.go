@@ -0,0 +1,169 @@
+package grpc
+
+import (
+	"io"
+	"log"
+	"time"
+
+	"github.com/aymc/agent/core"
+	pb "github.com/aymc/agent/grpc/pb"
+)
+
+// Tipos de evento que el backend puede filtrar en EventStream
+const (
+	eventTypeMetrics     = "metrics"
+	eventTypeServerEvent = "server_event"
+	eventTypeLogAlert    = "log_alert"
+)
+
+// EventStream mantiene un canal bidireccional con el backend. El agente
+// envía métricas, eventos de servidores y alertas de logs a medida que
+// ocurren; el backend puede filtrar tipos de evento y enviar heartbeats.
+func (s *agentServiceImpl) EventStream(stream pb.AgentService_EventStreamServer) error {
+	log.Printf("[INFO] EventStream iniciado")
+
+	ctx := stream.Context()
+	agentID := s.agent.GetConfig().AgentID
+
+	serverSub, serverEvents := s.agent.Events().Subscribe(256)
+	defer s.agent.Events().Unsubscribe(serverSub)
+
+	metricsSub, metrics := s.agent.Metrics().Subscribe(16)
+	defer s.agent.Metrics().Unsubscribe(metricsSub)
+
+	alertsSub, alerts := s.agent.LogAlerts().Subscribe(256)
+	defer s.agent.LogAlerts().Unsubscribe(alertsSub)
+
+	// Recibir peticiones del backend en una goroutine aparte; los envíos
+	// se hacen únicamente desde el loop principal
+	requests := make(chan *pb.EventStreamRequest, 8)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	filter := map[string]bool{}
+	enabled := func(eventType string) bool {
+		return len(filter) == 0 || filter[eventType]
+	}
+
+	var sequence uint64
+	send := func(event *pb.AgentEvent) error {
+		sequence++
+		event.Timestamp = time.Now().Unix()
+		event.AgentId = agentID
+		event.Sequence = sequence
+		return stream.Send(event)
+	}
+
+	for {
+		var err error
+
+		select {
+		case <-ctx.Done():
+			log.Printf("[INFO] EventStream cerrado")
+			return nil
+
+		case err := <-recvErr:
+			if err == io.EOF {
+				log.Printf("[INFO] EventStream cerrado por el backend")
+				return nil
+			}
+			log.Printf("[WARN] EventStream: error recibiendo: %v", err)
+			return err
+
+		case req := <-requests:
+			if len(req.EventTypes) > 0 {
+				filter = make(map[string]bool, len(req.EventTypes))
+				for _, t := range req.EventTypes {
+					filter[t] = true
+				}
+			}
+			if req.Ping {
+				err = send(&pb.AgentEvent{Payload: &pb.AgentEvent_Pong{
+					Pong: &pb.PongResponse{Timestamp: time.Now().Unix(), Message: "pong"},
+				}})
+			}
+
+		case m, ok := <-metrics:
+			if !ok {
+				return nil
+			}
+			if enabled(eventTypeMetrics) {
+				err = send(&pb.AgentEvent{Payload: &pb.AgentEvent_Metrics{
+					Metrics: convertToProtoMetrics(m),
+				}})
+			}
+
+		case event, ok := <-serverEvents:
+			if !ok {
+				return nil
+			}
+			if enabled(eventTypeServerEvent) {
+				err = send(&pb.AgentEvent{Payload: &pb.AgentEvent_ServerEvent{
+					ServerEvent: convertToProtoEvent(event),
+				}})
+			}
+
+		case alert, ok := <-alerts:
+			if !ok {
+				return nil
+			}
+			if enabled(eventTypeLogAlert) {
+				err = send(&pb.AgentEvent{Payload: &pb.AgentEvent_LogAlert{
+					LogAlert: convertToProtoLogAlert(alert),
+				}})
+			}
+		}
+
+		if err != nil {
+			log.Printf("[ERROR] Error enviando evento: %v", err)
+			return err
+		}
+	}
+}
+
+func convertToProtoMetrics(metrics *core.SystemMetrics) *pb.SystemMetrics {
+	ports32 := make([]int32, len(metrics.OpenPorts))
+	for i, p := range metrics.OpenPorts {
+		ports32[i] = int32(p)
+	}
+
+	return &pb.SystemMetrics{
+		Timestamp:     metrics.Timestamp.Unix(),
+		CpuPercent:    metrics.CPUPercent,
+		MemoryTotal:   metrics.MemoryTotal,
+		MemoryUsed:    metrics.MemoryUsed,
+		MemoryPercent: metrics.MemoryPercent,
+		DiskTotal:     metrics.DiskTotal,
+		DiskUsed:      metrics.DiskUsed,
+		DiskPercent:   metrics.DiskPercent,
+		NetworkSent:   metrics.NetworkSent,
+		NetworkRecv:   metrics.NetworkRecv,
+		OpenPorts:     ports32,
+	}
+}
+
+func convertToProtoLogAlert(alert core.LogAlert) *pb.LogAlert {
+	return &pb.LogAlert{
+		Timestamp:  alert.Timestamp.Unix(),
+		ServerId:   alert.ServerID,
+		Level:      string(alert.Level),
+		Message:    alert.Message,
+		ErrorType:  alert.ErrorType,
+		Severity:   int32(alert.Severity),
+		Suggestion: alert.Suggestion,
+		Plugin:     alert.Plugin,
+	}
+}
@@ -0,0 +1,616 @@
+package grpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/aymc/agent/grpc/pb"
+	"github.com/aymc/agent/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fileTarget ruta resuelta de una petición de archivos y el sandbox que la
+// contiene
+type fileTarget struct {
+	sandbox *utils.Sandbox
+	path    string // absoluta, dentro del sandbox
+	baseDir string // raíz de file_roots con la que se reportan las rutas; vacío = relativas (server_id)
+}
+
+// display retorna la ruta tal como la ve el cliente
+func (t *fileTarget) display(path string) string {
+	if t.baseDir == "" {
+		return t.sandbox.Rel(path)
+	}
+	return filepath.Join(t.baseDir, filepath.FromSlash(t.sandbox.Rel(path)))
+}
+
+// resolveFile limita la ruta al directorio del servidor o, sin server_id, a
+// uno de los directorios extra configurados en file_roots
+func (s *agentServiceImpl) resolveFile(serverID, path string) (*fileTarget, error) {
+	return s.resolvePath(serverID, path, (*utils.Sandbox).Resolve)
+}
+
+// resolveLink es como resolveFile pero sin seguir un enlace simbólico final,
+// para operaciones que actúan sobre la propia entrada (borrar, mover, destino)
+func (s *agentServiceImpl) resolveLink(serverID, path string) (*fileTarget, error) {
+	return s.resolvePath(serverID, path, (*utils.Sandbox).ResolveLink)
+}
+
+func (s *agentServiceImpl) resolvePath(serverID, path string, resolve func(*utils.Sandbox, string) (string, error)) (*fileTarget, error) {
+	if serverID != "" {
+		server, err := s.agent.GetServer(serverID)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "servidor no encontrado: %v", err)
+		}
+		sandbox, err := utils.NewSandbox(server.WorkDir)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "directorio del servidor no disponible: %v", err)
+		}
+		resolved, err := resolve(sandbox, path)
+		if err != nil {
+			return nil, sandboxError(err)
+		}
+		return &fileTarget{sandbox: sandbox, path: resolved}, nil
+	}
+
+	if !filepath.IsAbs(path) {
+		return nil, status.Errorf(codes.InvalidArgument, "se requiere server_id o una ruta absoluta en file_roots")
+	}
+	for _, root := range s.agent.GetConfig().FileRoots {
+		rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(path))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		sandbox, err := utils.NewSandbox(root)
+		if err != nil {
+			log.Printf("[WARN] Directorio de file_roots no disponible %s: %v", root, err)
+			continue
+		}
+		resolved, err := resolve(sandbox, rel)
+		if err != nil {
+			return nil, sandboxError(err)
+		}
+		return &fileTarget{sandbox: sandbox, path: resolved, baseDir: filepath.Clean(root)}, nil
+	}
+
+	return nil, status.Errorf(codes.PermissionDenied, "ruta fuera de los directorios permitidos")
+}
+
+// sandboxError convierte un error de resolución en un error gRPC
+func sandboxError(err error) error {
+	if errors.Is(err, utils.ErrOutsideSandbox) {
+		return status.Errorf(codes.PermissionDenied, "ruta no válida: %v", err)
+	}
+	return status.Errorf(codes.Internal, "error resolviendo ruta: %v", err)
+}
+
+// fsError convierte un error del sistema de archivos en un error gRPC
+func fsError(err error, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	switch {
+	case os.IsNotExist(err):
+		return status.Errorf(codes.NotFound, "%s: %v", msg, err)
+	case os.IsExist(err):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", msg, err)
+	case os.IsPermission(err):
+		return status.Errorf(codes.PermissionDenied, "%s: %v", msg, err)
+	}
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
+
+// ReadFile lee un archivo remoto
+func (s *agentServiceImpl) ReadFile(ctx context.Context, req *pb.FileRequest) (*pb.FileContent, error) {
+	log.Printf("[DEBUG] ReadFile llamado: server=%s path=%s", req.ServerId, req.Path)
+
+	target, err := s.resolveFile(req.ServerId, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leer archivo
+	data, err := os.ReadFile(target.path)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "error leyendo archivo: %v", err)
+	}
+
+	// Obtener info del archivo
+	info, err := os.Stat(target.path)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error obteniendo info: %v", err)
+	}
+
+	return &pb.FileContent{
+		Content:      data,
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime().Unix(),
+	}, nil
+}
+
+// WriteFile escribe un archivo remoto
+func (s *agentServiceImpl) WriteFile(ctx context.Context, req *pb.WriteFileRequest) (*pb.FileResponse, error) {
+	log.Printf("[DEBUG] WriteFile llamado: server=%s path=%s", req.ServerId, req.Path)
+
+	target, err := s.resolveFile(req.ServerId, req.Path)
+	if err != nil {
+		return &pb.FileResponse{
+			Success: false,
+			Message: status.Convert(err).Message(),
+		}, nil
+	}
+	if target.path == target.sandbox.Root() {
+		return &pb.FileResponse{
+			Success: false,
+			Message: "ruta no válida: es el directorio raíz",
+		}, nil
+	}
+
+	// Crear directorios si es necesario
+	if req.CreateDirs {
+		dir := filepath.Dir(target.path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return &pb.FileResponse{
+				Success: false,
+				Message: fmt.Sprintf("error creando directorios: %v", err),
+			}, nil
+		}
+	}
+
+	// Escribir archivo
+	if err := os.WriteFile(target.path, req.Content, 0644); err != nil {
+		return &pb.FileResponse{
+			Success: false,
+			Message: fmt.Sprintf("error escribiendo archivo: %v", err),
+		}, nil
+	}
+
+	return &pb.FileResponse{
+		Success: true,
+		Message: "Archivo escrito correctamente",
+	}, nil
+}
+
+// ListFiles lista archivos en un directorio
+func (s *agentServiceImpl) ListFiles(ctx context.Context, req *pb.DirectoryRequest) (*pb.FileList, error) {
+	log.Printf("[DEBUG] ListFiles llamado: server=%s path=%s", req.ServerId, req.Path)
+
+	target, err := s.resolveFile(req.ServerId, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*pb.FileInfo
+
+	if req.Recursive {
+		// Listado recursivo; Walk no sigue enlaces simbólicos
+		err := filepath.Walk(target.path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil // Continuar con siguientes archivos
+			}
+			if path == target.path {
+				return nil
+			}
+
+			files = append(files, &pb.FileInfo{
+				Name:         info.Name(),
+				Path:         target.display(path),
+				Size:         info.Size(),
+				IsDir:        info.IsDir(),
+				ModifiedTime: info.ModTime().Unix(),
+				Permissions:  int32(info.Mode().Perm()),
+			})
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error listando archivos: %v", err)
+		}
+	} else {
+		// Listado simple
+		entries, err := os.ReadDir(target.path)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "error leyendo directorio: %v", err)
+		}
+
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			files = append(files, &pb.FileInfo{
+				Name:         info.Name(),
+				Path:         target.display(filepath.Join(target.path, info.Name())),
+				Size:         info.Size(),
+				IsDir:        info.IsDir(),
+				ModifiedTime: info.ModTime().Unix(),
+				Permissions:  int32(info.Mode().Perm()),
+			})
+		}
+	}
+
+	return &pb.FileList{Files: files}, nil
+}
+
+// DeleteFile borra un archivo o directorio. Un enlace simbólico se borra sin
+// tocar su destino.
+func (s *agentServiceImpl) DeleteFile(ctx context.Context, req *pb.DeleteFileRequest) (*pb.FileResponse, error) {
+	log.Printf("[INFO] DeleteFile llamado: server=%s path=%s recursive=%v", req.ServerId, req.Path, req.Recursive)
+
+	target, err := s.resolveLink(req.ServerId, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(target.path)
+	if err != nil {
+		return nil, fsError(err, "error accediendo a %s", req.Path)
+	}
+
+	if info.IsDir() && req.Recursive {
+		err = os.RemoveAll(target.path)
+	} else {
+		err = os.Remove(target.path)
+	}
+	if err != nil {
+		if info.IsDir() && !req.Recursive {
+			return nil, status.Errorf(codes.FailedPrecondition, "el directorio no está vacío, usa recursive")
+		}
+		return nil, fsError(err, "error borrando %s", req.Path)
+	}
+
+	return &pb.FileResponse{
+		Success: true,
+		Message: fmt.Sprintf("%s borrado", target.display(target.path)),
+	}, nil
+}
+
+// MoveFile mueve o renombra un archivo o directorio
+func (s *agentServiceImpl) MoveFile(ctx context.Context, req *pb.MoveFileRequest) (*pb.FileResponse, error) {
+	log.Printf("[INFO] MoveFile llamado: server=%s %s -> %s", req.ServerId, req.Source, req.Destination)
+
+	src, dst, err := s.resolveTransfer(req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := replacePath(src.path, dst.path); err != nil {
+		return nil, fsError(err, "error moviendo %s", req.Source)
+	}
+
+	return &pb.FileResponse{
+		Success: true,
+		Message: fmt.Sprintf("%s movido a %s", src.display(src.path), dst.display(dst.path)),
+	}, nil
+}
+
+// CopyFile copia un archivo o un directorio completo. Los enlaces simbólicos
+// dentro de un directorio copiado se omiten. La copia se hace en un nombre
+// temporal junto al destino, así que un destino existente no se toca hasta
+// que la copia termina.
+func (s *agentServiceImpl) CopyFile(ctx context.Context, req *pb.MoveFileRequest) (*pb.FileResponse, error) {
+	log.Printf("[INFO] CopyFile llamado: server=%s %s -> %s", req.ServerId, req.Source, req.Destination)
+
+	src, dst, err := s.resolveTransfer(req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpPath, err := siblingTempPath(dst.path)
+	if err != nil {
+		return nil, fsError(err, "error copiando %s", req.Source)
+	}
+	if err := copyTree(ctx, src.path, tmpPath); err != nil {
+		os.RemoveAll(tmpPath)
+		return nil, fsError(err, "error copiando %s", req.Source)
+	}
+	if err := replacePath(tmpPath, dst.path); err != nil {
+		os.RemoveAll(tmpPath)
+		return nil, fsError(err, "error copiando %s", req.Source)
+	}
+
+	return &pb.FileResponse{
+		Success: true,
+		Message: fmt.Sprintf("%s copiado a %s", src.display(src.path), dst.display(dst.path)),
+	}, nil
+}
+
+// resolveTransfer valida origen y destino de MoveFile/CopyFile. Sin
+// overwrite un destino existente es un error; con overwrite se reemplaza al
+// final con replacePath.
+func (s *agentServiceImpl) resolveTransfer(req *pb.MoveFileRequest, move bool) (*fileTarget, *fileTarget, error) {
+	var src *fileTarget
+	var err error
+	if move {
+		src, err = s.resolveLink(req.ServerId, req.Source)
+	} else {
+		src, err = s.resolveFile(req.ServerId, req.Source)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := os.Lstat(src.path); err != nil {
+		return nil, nil, fsError(err, "error accediendo a %s", req.Source)
+	}
+
+	dst, err := s.resolveLink(req.ServerId, req.Destination)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dst.path == src.path || isSubPath(src.path, dst.path) {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "el destino no puede estar dentro del origen")
+	}
+	if _, err := os.Stat(filepath.Dir(dst.path)); err != nil {
+		return nil, nil, fsError(err, "directorio de destino no disponible")
+	}
+
+	if _, err := os.Lstat(dst.path); err == nil && !req.Overwrite {
+		return nil, nil, status.Errorf(codes.AlreadyExists, "%s ya existe", req.Destination)
+	}
+
+	return src, dst, nil
+}
+
+// CreateDirectory crea un directorio
+func (s *agentServiceImpl) CreateDirectory(ctx context.Context, req *pb.CreateDirectoryRequest) (*pb.FileResponse, error) {
+	log.Printf("[INFO] CreateDirectory llamado: server=%s path=%s", req.ServerId, req.Path)
+
+	target, err := s.resolveFile(req.ServerId, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Parents {
+		err = os.MkdirAll(target.path, 0755)
+	} else {
+		err = os.Mkdir(target.path, 0755)
+	}
+	if err != nil {
+		return nil, fsError(err, "error creando %s", req.Path)
+	}
+
+	return &pb.FileResponse{
+		Success: true,
+		Message: fmt.Sprintf("%s creado", target.display(target.path)),
+	}, nil
+}
+
+// ChangePermissions cambia los permisos unix. Solo se aceptan los bits rwx
+// (sin setuid/setgid/sticky); en modo recursivo los enlaces se omiten.
+func (s *agentServiceImpl) ChangePermissions(ctx context.Context, req *pb.ChangePermissionsRequest) (*pb.FileResponse, error) {
+	log.Printf("[INFO] ChangePermissions llamado: server=%s path=%s mode=%o", req.ServerId, req.Path, req.Mode)
+
+	if req.Mode > 0777 {
+		return nil, status.Errorf(codes.InvalidArgument, "modo no válido %o: solo se permiten permisos rwx", req.Mode)
+	}
+	mode := os.FileMode(req.Mode)
+
+	target, err := s.resolveFile(req.ServerId, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	count := 0
+	if req.Recursive {
+		err = filepath.Walk(target.path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if info.Mode()&os.ModeSymlink != 0 {
+				return nil
+			}
+			count++
+			return os.Chmod(path, mode)
+		})
+	} else {
+		count = 1
+		err = os.Chmod(target.path, mode)
+	}
+	if err != nil {
+		return nil, fsError(err, "error cambiando permisos de %s", req.Path)
+	}
+
+	return &pb.FileResponse{
+		Success: true,
+		Message: fmt.Sprintf("Permisos %04o aplicados a %d elementos", req.Mode, count),
+	}, nil
+}
+
+// CompressFiles comprime una selección de archivos y directorios en zip o
+// tar.gz
+func (s *agentServiceImpl) CompressFiles(ctx context.Context, req *pb.CompressFilesRequest) (*pb.FileResponse, error) {
+	log.Printf("[INFO] CompressFiles llamado: server=%s paths=%d destination=%s", req.ServerId, len(req.Paths), req.Destination)
+
+	if len(req.Paths) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "no se indicaron archivos")
+	}
+
+	format := req.Format
+	if format == "" {
+		detected, err := utils.DetectArchiveFormat(req.Destination)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		format = detected
+	}
+	if format != utils.ArchiveZip && format != utils.ArchiveTarGz && format != utils.ArchiveTar {
+		return nil, status.Errorf(codes.InvalidArgument, "formato no soportado: %s", format)
+	}
+
+	paths := make([]string, 0, len(req.Paths))
+	for _, p := range req.Paths {
+		target, err := s.resolveFile(req.ServerId, p)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(target.path); err != nil {
+			return nil, fsError(err, "error accediendo a %s", p)
+		}
+		paths = append(paths, target.path)
+	}
+
+	dst, err := s.resolveLink(req.ServerId, req.Destination)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(dst.path); err == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "%s ya existe", req.Destination)
+	}
+
+	count, err := utils.CreateArchive(ctx, dst.path, paths, format)
+	if err != nil {
+		return nil, fsError(err, "error comprimiendo")
+	}
+
+	return &pb.FileResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d archivos comprimidos en %s", count, dst.display(dst.path)),
+	}, nil
+}
+
+// ExtractArchive extrae un zip, tar o tar.gz dentro del sandbox. Las entradas
+// que intenten salir del destino hacen fallar la extracción.
+func (s *agentServiceImpl) ExtractArchive(ctx context.Context, req *pb.ExtractArchiveRequest) (*pb.FileResponse, error) {
+	log.Printf("[INFO] ExtractArchive llamado: server=%s path=%s destination=%s", req.ServerId, req.Path, req.Destination)
+
+	src, err := s.resolveFile(req.ServerId, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	format, err := utils.DetectArchiveFormat(src.path)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	destination := req.Destination
+	if destination == "" {
+		destination = path.Dir(filepath.ToSlash(req.Path))
+	}
+	dst, err := s.resolveFile(req.ServerId, destination)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dst.path, 0755); err != nil {
+		return nil, fsError(err, "error creando %s", destination)
+	}
+
+	result, err := utils.ExtractArchive(ctx, src.path, dst.path, format, req.Overwrite)
+	if err != nil {
+		if errors.Is(err, utils.ErrOutsideSandbox) || errors.Is(err, utils.ErrArchiveTooLarge) {
+			return nil, status.Errorf(codes.InvalidArgument, "archivo no válido: %v", err)
+		}
+		return nil, fsError(err, "error extrayendo %s", req.Path)
+	}
+
+	return &pb.FileResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d archivos extraídos en %s (%d omitidos)", result.Files, dst.display(dst.path), result.Skipped),
+	}, nil
+}
+
+// replacePath renombra from a to. Si to existe se aparta con un nombre
+// temporal y solo se borra cuando el renombrado termina; si falla, se
+// restaura, de modo que el destino nunca se pierde.
+func replacePath(from, to string) error {
+	if _, err := os.Lstat(to); err != nil {
+		return os.Rename(from, to)
+	}
+
+	aside, err := siblingTempPath(to)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(to, aside); err != nil {
+		return err
+	}
+	if err := os.Rename(from, to); err != nil {
+		if restoreErr := os.Rename(aside, to); restoreErr != nil {
+			log.Printf("[ERROR] No se pudo restaurar %s (quedó en %s): %v", to, aside, restoreErr)
+		}
+		return err
+	}
+	if err := os.RemoveAll(aside); err != nil {
+		log.Printf("[WARN] No se pudo borrar el destino reemplazado %s: %v", aside, err)
+	}
+	return nil
+}
+
+// siblingTempPath retorna una ruta libre, oculta y en el mismo directorio que
+// path (mismo sistema de archivos, así que el renombrado es atómico)
+func siblingTempPath(path string) (string, error) {
+	suffix := make([]byte, 6)
+	for i := 0; i < 10; i++ {
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+		candidate := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.aymc-%s", filepath.Base(path), hex.EncodeToString(suffix)))
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no se pudo reservar un nombre temporal para %s", path)
+}
+
+// copyTree copia src en dst; si src es un directorio lo copia completo
+func copyTree(ctx context.Context, src, dst string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode().IsRegular():
+			return copyRegularFile(p, target, info.Mode().Perm())
+		}
+		return nil // enlaces y archivos especiales
+	})
+}
+
+// copyRegularFile copia un archivo sin sobrescribir uno existente
+func copyRegularFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// isSubPath indica si child está dentro del directorio parent
+func isSubPath(parent, child string) bool {
+	return strings.HasPrefix(child, parent+string(filepath.Separator))
+}
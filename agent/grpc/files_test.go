@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplacePathReplacesDirectory(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "nuevo")
+	to := filepath.Join(dir, "world")
+	os.MkdirAll(filepath.Join(to, "region"), 0755)
+	os.WriteFile(filepath.Join(to, "region", "r.0.0.mca"), []byte("viejo"), 0644)
+	os.WriteFile(from, []byte("nuevo"), 0644)
+
+	if err := replacePath(from, to); err != nil {
+		t.Fatalf("replacePath() error = %v", err)
+	}
+
+	if data, err := os.ReadFile(to); err != nil || string(data) != "nuevo" {
+		t.Errorf("destino = %q, %v; esperado el archivo nuevo", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("quedaron %d entradas, esperada solo el destino", len(entries))
+	}
+}
+
+func TestReplacePathRestoresDestinationOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	to := filepath.Join(dir, "server.properties")
+	os.WriteFile(to, []byte("original"), 0644)
+
+	// El origen no existe: el renombrado falla después de apartar el destino
+	if err := replacePath(filepath.Join(dir, "no-existe"), to); err == nil {
+		t.Fatal("replacePath() debería fallar sin origen")
+	}
+
+	if data, err := os.ReadFile(to); err != nil || string(data) != "original" {
+		t.Errorf("destino = %q, %v; debería restaurarse", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("quedaron %d entradas, esperada solo el destino", len(entries))
+	}
+}
@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"os"
+
+	pb "github.com/aymc/agent/grpc/pb"
+	"github.com/aymc/agent/security"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Pair instala el token que el backend usará en las llamadas siguientes.
+// El primer emparejamiento exige el código que el agente muestra en su
+// log; con el agente ya emparejado, el token actual en la metadata.
+func (s *agentServiceImpl) Pair(ctx context.Context, req *pb.PairRequest) (*pb.PairResponse, error) {
+	rotating := s.security.IsPaired()
+
+	if err := s.security.Pair(req.PairingCode, security.TokenFromContext(ctx), req.Token); err != nil {
+		log.Printf("[WARN] Emparejamiento rechazado: %v", err)
+		return &pb.PairResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	message := "Agente emparejado"
+	if rotating {
+		message = "Token rotado"
+	}
+
+	return &pb.PairResponse{
+		Success: true,
+		Message: message,
+		AgentId: s.agent.GetConfig().AgentID,
+	}, nil
+}
+
+// CreateCertificateRequest genera una clave nueva y retorna el CSR para
+// que la CA del backend emita el certificado del agente
+func (s *agentServiceImpl) CreateCertificateRequest(ctx context.Context, req *pb.Empty) (*pb.CertificateRequestResponse, error) {
+	var dnsNames []string
+	if hostname, err := os.Hostname(); err == nil {
+		dnsNames = append(dnsNames, hostname)
+	}
+
+	csr, err := s.security.CreateCertificateRequest(s.agent.GetConfig().AgentID, dnsNames)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error generando CSR: %v", err)
+	}
+
+	return &pb.CertificateRequestResponse{Csr: csr}, nil
+}
+
+// InstallCertificate instala el certificado emitido por el backend. Desde
+// ese momento las conexiones nuevas exigen el certificado cliente del
+// backend.
+func (s *agentServiceImpl) InstallCertificate(ctx context.Context, req *pb.InstallCertificateRequest) (*pb.InstallCertificateResponse, error) {
+	fingerprint, err := s.security.InstallCertificate(req.Certificate, req.CaCertificate)
+	if err != nil {
+		log.Printf("[WARN] Certificado rechazado: %v", err)
+		return &pb.InstallCertificateResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	return &pb.InstallCertificateResponse{
+		Success:     true,
+		Message:     "Certificado instalado",
+		Fingerprint: fingerprint,
+	}, nil
+}
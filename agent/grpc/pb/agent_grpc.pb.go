@@ -19,29 +19,49 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AgentService_GetAgentInfo_FullMethodName      = "/agent.AgentService/GetAgentInfo"
-	AgentService_GetSystemMetrics_FullMethodName  = "/agent.AgentService/GetSystemMetrics"
-	AgentService_ListServers_FullMethodName       = "/agent.AgentService/ListServers"
-	AgentService_GetServer_FullMethodName         = "/agent.AgentService/GetServer"
-	AgentService_StartServer_FullMethodName       = "/agent.AgentService/StartServer"
-	AgentService_StopServer_FullMethodName        = "/agent.AgentService/StopServer"
-	AgentService_RestartServer_FullMethodName     = "/agent.AgentService/RestartServer"
-	AgentService_SendCommand_FullMethodName       = "/agent.AgentService/SendCommand"
-	AgentService_StreamLogs_FullMethodName        = "/agent.AgentService/StreamLogs"
-	AgentService_ReadFile_FullMethodName          = "/agent.AgentService/ReadFile"
-	AgentService_WriteFile_FullMethodName         = "/agent.AgentService/WriteFile"
-	AgentService_ListFiles_FullMethodName         = "/agent.AgentService/ListFiles"
-	AgentService_CheckDependencies_FullMethodName = "/agent.AgentService/CheckDependencies"
-	AgentService_InstallJava_FullMethodName       = "/agent.AgentService/InstallJava"
-	AgentService_DownloadServer_FullMethodName    = "/agent.AgentService/DownloadServer"
-	AgentService_InstallPlugin_FullMethodName     = "/agent.AgentService/InstallPlugin"
-	AgentService_UninstallPlugin_FullMethodName   = "/agent.AgentService/UninstallPlugin"
-	AgentService_UpdatePlugin_FullMethodName      = "/agent.AgentService/UpdatePlugin"
-	AgentService_ListPlugins_FullMethodName       = "/agent.AgentService/ListPlugins"
-	AgentService_CreateBackup_FullMethodName      = "/agent.AgentService/CreateBackup"
-	AgentService_RestoreBackup_FullMethodName     = "/agent.AgentService/RestoreBackup"
-	AgentService_Ping_FullMethodName              = "/agent.AgentService/Ping"
-	AgentService_HealthCheck_FullMethodName       = "/agent.AgentService/HealthCheck"
+	AgentService_GetAgentInfo_FullMethodName             = "/agent.AgentService/GetAgentInfo"
+	AgentService_GetSystemMetrics_FullMethodName         = "/agent.AgentService/GetSystemMetrics"
+	AgentService_ListServers_FullMethodName              = "/agent.AgentService/ListServers"
+	AgentService_GetServer_FullMethodName                = "/agent.AgentService/GetServer"
+	AgentService_StartServer_FullMethodName              = "/agent.AgentService/StartServer"
+	AgentService_StopServer_FullMethodName               = "/agent.AgentService/StopServer"
+	AgentService_RestartServer_FullMethodName            = "/agent.AgentService/RestartServer"
+	AgentService_GetServerMetrics_FullMethodName         = "/agent.AgentService/GetServerMetrics"
+	AgentService_EventStream_FullMethodName              = "/agent.AgentService/EventStream"
+	AgentService_SendCommand_FullMethodName              = "/agent.AgentService/SendCommand"
+	AgentService_StreamLogs_FullMethodName               = "/agent.AgentService/StreamLogs"
+	AgentService_ReadFile_FullMethodName                 = "/agent.AgentService/ReadFile"
+	AgentService_WriteFile_FullMethodName                = "/agent.AgentService/WriteFile"
+	AgentService_ListFiles_FullMethodName                = "/agent.AgentService/ListFiles"
+	AgentService_DeleteFile_FullMethodName               = "/agent.AgentService/DeleteFile"
+	AgentService_MoveFile_FullMethodName                 = "/agent.AgentService/MoveFile"
+	AgentService_CopyFile_FullMethodName                 = "/agent.AgentService/CopyFile"
+	AgentService_CreateDirectory_FullMethodName          = "/agent.AgentService/CreateDirectory"
+	AgentService_ChangePermissions_FullMethodName        = "/agent.AgentService/ChangePermissions"
+	AgentService_CompressFiles_FullMethodName            = "/agent.AgentService/CompressFiles"
+	AgentService_ExtractArchive_FullMethodName           = "/agent.AgentService/ExtractArchive"
+	AgentService_UploadFile_FullMethodName               = "/agent.AgentService/UploadFile"
+	AgentService_DownloadFile_FullMethodName             = "/agent.AgentService/DownloadFile"
+	AgentService_GetUploadOffset_FullMethodName          = "/agent.AgentService/GetUploadOffset"
+	AgentService_CheckDependencies_FullMethodName        = "/agent.AgentService/CheckDependencies"
+	AgentService_InstallJava_FullMethodName              = "/agent.AgentService/InstallJava"
+	AgentService_DownloadServer_FullMethodName           = "/agent.AgentService/DownloadServer"
+	AgentService_InstallPlugin_FullMethodName            = "/agent.AgentService/InstallPlugin"
+	AgentService_UninstallPlugin_FullMethodName          = "/agent.AgentService/UninstallPlugin"
+	AgentService_UpdatePlugin_FullMethodName             = "/agent.AgentService/UpdatePlugin"
+	AgentService_ListPlugins_FullMethodName              = "/agent.AgentService/ListPlugins"
+	AgentService_CreateBackup_FullMethodName             = "/agent.AgentService/CreateBackup"
+	AgentService_RestoreBackup_FullMethodName            = "/agent.AgentService/RestoreBackup"
+	AgentService_DownloadBackup_FullMethodName           = "/agent.AgentService/DownloadBackup"
+	AgentService_UploadBackup_FullMethodName             = "/agent.AgentService/UploadBackup"
+	AgentService_DeleteBackup_FullMethodName             = "/agent.AgentService/DeleteBackup"
+	AgentService_ListSnapshots_FullMethodName            = "/agent.AgentService/ListSnapshots"
+	AgentService_PruneSnapshots_FullMethodName           = "/agent.AgentService/PruneSnapshots"
+	AgentService_Ping_FullMethodName                     = "/agent.AgentService/Ping"
+	AgentService_HealthCheck_FullMethodName              = "/agent.AgentService/HealthCheck"
+	AgentService_Pair_FullMethodName                     = "/agent.AgentService/Pair"
+	AgentService_CreateCertificateRequest_FullMethodName = "/agent.AgentService/CreateCertificateRequest"
+	AgentService_InstallCertificate_FullMethodName       = "/agent.AgentService/InstallCertificate"
 )
 
 // AgentServiceClient is the client API for AgentService service.
@@ -59,13 +79,26 @@ type AgentServiceClient interface {
 	StartServer(ctx context.Context, in *StartServerRequest, opts ...grpc.CallOption) (*ServerResponse, error)
 	StopServer(ctx context.Context, in *ServerRequest, opts ...grpc.CallOption) (*ServerResponse, error)
 	RestartServer(ctx context.Context, in *ServerRequest, opts ...grpc.CallOption) (*ServerResponse, error)
+	GetServerMetrics(ctx context.Context, in *ServerRequest, opts ...grpc.CallOption) (*ServerMetrics, error)
+	// Canal de eventos agente -> backend (métricas, estados, crashes, alertas)
+	EventStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[EventStreamRequest, AgentEvent], error)
 	// Comandos y logs
 	SendCommand(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*CommandResponse, error)
-	StreamLogs(ctx context.Context, in *ServerRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
+	StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
 	// Gestión de archivos
 	ReadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*FileContent, error)
 	WriteFile(ctx context.Context, in *WriteFileRequest, opts ...grpc.CallOption) (*FileResponse, error)
 	ListFiles(ctx context.Context, in *DirectoryRequest, opts ...grpc.CallOption) (*FileList, error)
+	DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*FileResponse, error)
+	MoveFile(ctx context.Context, in *MoveFileRequest, opts ...grpc.CallOption) (*FileResponse, error)
+	CopyFile(ctx context.Context, in *MoveFileRequest, opts ...grpc.CallOption) (*FileResponse, error)
+	CreateDirectory(ctx context.Context, in *CreateDirectoryRequest, opts ...grpc.CallOption) (*FileResponse, error)
+	ChangePermissions(ctx context.Context, in *ChangePermissionsRequest, opts ...grpc.CallOption) (*FileResponse, error)
+	CompressFiles(ctx context.Context, in *CompressFilesRequest, opts ...grpc.CallOption) (*FileResponse, error)
+	ExtractArchive(ctx context.Context, in *ExtractArchiveRequest, opts ...grpc.CallOption) (*FileResponse, error)
+	UploadFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[FileChunk, FileTransferResponse], error)
+	DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error)
+	GetUploadOffset(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*UploadOffsetResponse, error)
 	// Instalación y dependencias
 	CheckDependencies(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*DependenciesStatus, error)
 	InstallJava(ctx context.Context, in *JavaInstallRequest, opts ...grpc.CallOption) (*InstallResponse, error)
@@ -78,9 +111,19 @@ type AgentServiceClient interface {
 	// Gestión de backups
 	CreateBackup(ctx context.Context, in *CreateBackupRequest, opts ...grpc.CallOption) (*CreateBackupResponse, error)
 	RestoreBackup(ctx context.Context, in *RestoreBackupRequest, opts ...grpc.CallOption) (*RestoreBackupResponse, error)
+	DownloadBackup(ctx context.Context, in *BackupFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupChunk], error)
+	UploadBackup(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BackupChunk, BackupFileResponse], error)
+	DeleteBackup(ctx context.Context, in *BackupFileRequest, opts ...grpc.CallOption) (*BackupFileResponse, error)
+	ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
+	PruneSnapshots(ctx context.Context, in *PruneSnapshotsRequest, opts ...grpc.CallOption) (*PruneSnapshotsResponse, error)
 	// Heartbeat y health check
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PongResponse, error)
 	HealthCheck(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*HealthStatus, error)
+	// Emparejamiento: el backend provisiona el token del agente
+	Pair(ctx context.Context, in *PairRequest, opts ...grpc.CallOption) (*PairResponse, error)
+	// Certificados mTLS firmados por la CA del backend
+	CreateCertificateRequest(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CertificateRequestResponse, error)
+	InstallCertificate(ctx context.Context, in *InstallCertificateRequest, opts ...grpc.CallOption) (*InstallCertificateResponse, error)
 }
 
 type agentServiceClient struct {
@@ -161,6 +204,29 @@ func (c *agentServiceClient) RestartServer(ctx context.Context, in *ServerReques
 	return out, nil
 }
 
+func (c *agentServiceClient) GetServerMetrics(ctx context.Context, in *ServerRequest, opts ...grpc.CallOption) (*ServerMetrics, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServerMetrics)
+	err := c.cc.Invoke(ctx, AgentService_GetServerMetrics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) EventStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[EventStreamRequest, AgentEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[0], AgentService_EventStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[EventStreamRequest, AgentEvent]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_EventStreamClient = grpc.BidiStreamingClient[EventStreamRequest, AgentEvent]
+
 func (c *agentServiceClient) SendCommand(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*CommandResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CommandResponse)
@@ -171,13 +237,13 @@ func (c *agentServiceClient) SendCommand(ctx context.Context, in *CommandRequest
 	return out, nil
 }
 
-func (c *agentServiceClient) StreamLogs(ctx context.Context, in *ServerRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error) {
+func (c *agentServiceClient) StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[0], AgentService_StreamLogs_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[1], AgentService_StreamLogs_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[ServerRequest, LogEntry]{ClientStream: stream}
+	x := &grpc.GenericClientStream[StreamLogsRequest, LogEntry]{ClientStream: stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -220,6 +286,118 @@ func (c *agentServiceClient) ListFiles(ctx context.Context, in *DirectoryRequest
 	return out, nil
 }
 
+func (c *agentServiceClient) DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*FileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileResponse)
+	err := c.cc.Invoke(ctx, AgentService_DeleteFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) MoveFile(ctx context.Context, in *MoveFileRequest, opts ...grpc.CallOption) (*FileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileResponse)
+	err := c.cc.Invoke(ctx, AgentService_MoveFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CopyFile(ctx context.Context, in *MoveFileRequest, opts ...grpc.CallOption) (*FileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileResponse)
+	err := c.cc.Invoke(ctx, AgentService_CopyFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CreateDirectory(ctx context.Context, in *CreateDirectoryRequest, opts ...grpc.CallOption) (*FileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileResponse)
+	err := c.cc.Invoke(ctx, AgentService_CreateDirectory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ChangePermissions(ctx context.Context, in *ChangePermissionsRequest, opts ...grpc.CallOption) (*FileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileResponse)
+	err := c.cc.Invoke(ctx, AgentService_ChangePermissions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CompressFiles(ctx context.Context, in *CompressFilesRequest, opts ...grpc.CallOption) (*FileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileResponse)
+	err := c.cc.Invoke(ctx, AgentService_CompressFiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ExtractArchive(ctx context.Context, in *ExtractArchiveRequest, opts ...grpc.CallOption) (*FileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileResponse)
+	err := c.cc.Invoke(ctx, AgentService_ExtractArchive_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UploadFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[FileChunk, FileTransferResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[2], AgentService_UploadFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FileChunk, FileTransferResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_UploadFileClient = grpc.ClientStreamingClient[FileChunk, FileTransferResponse]
+
+func (c *agentServiceClient) DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[3], AgentService_DownloadFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadFileRequest, FileChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_DownloadFileClient = grpc.ServerStreamingClient[FileChunk]
+
+func (c *agentServiceClient) GetUploadOffset(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (*UploadOffsetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadOffsetResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetUploadOffset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) CheckDependencies(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*DependenciesStatus, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DependenciesStatus)
@@ -242,7 +420,7 @@ func (c *agentServiceClient) InstallJava(ctx context.Context, in *JavaInstallReq
 
 func (c *agentServiceClient) DownloadServer(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadProgress], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[1], AgentService_DownloadServer_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[4], AgentService_DownloadServer_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -319,6 +497,68 @@ func (c *agentServiceClient) RestoreBackup(ctx context.Context, in *RestoreBacku
 	return out, nil
 }
 
+func (c *agentServiceClient) DownloadBackup(ctx context.Context, in *BackupFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[5], AgentService_DownloadBackup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BackupFileRequest, BackupChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_DownloadBackupClient = grpc.ServerStreamingClient[BackupChunk]
+
+func (c *agentServiceClient) UploadBackup(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BackupChunk, BackupFileResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[6], AgentService_UploadBackup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BackupChunk, BackupFileResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_UploadBackupClient = grpc.ClientStreamingClient[BackupChunk, BackupFileResponse]
+
+func (c *agentServiceClient) DeleteBackup(ctx context.Context, in *BackupFileRequest, opts ...grpc.CallOption) (*BackupFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BackupFileResponse)
+	err := c.cc.Invoke(ctx, AgentService_DeleteBackup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSnapshotsResponse)
+	err := c.cc.Invoke(ctx, AgentService_ListSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) PruneSnapshots(ctx context.Context, in *PruneSnapshotsRequest, opts ...grpc.CallOption) (*PruneSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PruneSnapshotsResponse)
+	err := c.cc.Invoke(ctx, AgentService_PruneSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PongResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PongResponse)
@@ -339,6 +579,36 @@ func (c *agentServiceClient) HealthCheck(ctx context.Context, in *Empty, opts ..
 	return out, nil
 }
 
+func (c *agentServiceClient) Pair(ctx context.Context, in *PairRequest, opts ...grpc.CallOption) (*PairResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PairResponse)
+	err := c.cc.Invoke(ctx, AgentService_Pair_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CreateCertificateRequest(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CertificateRequestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CertificateRequestResponse)
+	err := c.cc.Invoke(ctx, AgentService_CreateCertificateRequest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) InstallCertificate(ctx context.Context, in *InstallCertificateRequest, opts ...grpc.CallOption) (*InstallCertificateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InstallCertificateResponse)
+	err := c.cc.Invoke(ctx, AgentService_InstallCertificate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServiceServer is the server API for AgentService service.
 // All implementations must embed UnimplementedAgentServiceServer
 // for forward compatibility.
@@ -354,13 +624,26 @@ type AgentServiceServer interface {
 	StartServer(context.Context, *StartServerRequest) (*ServerResponse, error)
 	StopServer(context.Context, *ServerRequest) (*ServerResponse, error)
 	RestartServer(context.Context, *ServerRequest) (*ServerResponse, error)
+	GetServerMetrics(context.Context, *ServerRequest) (*ServerMetrics, error)
+	// Canal de eventos agente -> backend (métricas, estados, crashes, alertas)
+	EventStream(grpc.BidiStreamingServer[EventStreamRequest, AgentEvent]) error
 	// Comandos y logs
 	SendCommand(context.Context, *CommandRequest) (*CommandResponse, error)
-	StreamLogs(*ServerRequest, grpc.ServerStreamingServer[LogEntry]) error
+	StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogEntry]) error
 	// Gestión de archivos
 	ReadFile(context.Context, *FileRequest) (*FileContent, error)
 	WriteFile(context.Context, *WriteFileRequest) (*FileResponse, error)
 	ListFiles(context.Context, *DirectoryRequest) (*FileList, error)
+	DeleteFile(context.Context, *DeleteFileRequest) (*FileResponse, error)
+	MoveFile(context.Context, *MoveFileRequest) (*FileResponse, error)
+	CopyFile(context.Context, *MoveFileRequest) (*FileResponse, error)
+	CreateDirectory(context.Context, *CreateDirectoryRequest) (*FileResponse, error)
+	ChangePermissions(context.Context, *ChangePermissionsRequest) (*FileResponse, error)
+	CompressFiles(context.Context, *CompressFilesRequest) (*FileResponse, error)
+	ExtractArchive(context.Context, *ExtractArchiveRequest) (*FileResponse, error)
+	UploadFile(grpc.ClientStreamingServer[FileChunk, FileTransferResponse]) error
+	DownloadFile(*DownloadFileRequest, grpc.ServerStreamingServer[FileChunk]) error
+	GetUploadOffset(context.Context, *FileRequest) (*UploadOffsetResponse, error)
 	// Instalación y dependencias
 	CheckDependencies(context.Context, *Empty) (*DependenciesStatus, error)
 	InstallJava(context.Context, *JavaInstallRequest) (*InstallResponse, error)
@@ -373,9 +656,19 @@ type AgentServiceServer interface {
 	// Gestión de backups
 	CreateBackup(context.Context, *CreateBackupRequest) (*CreateBackupResponse, error)
 	RestoreBackup(context.Context, *RestoreBackupRequest) (*RestoreBackupResponse, error)
+	DownloadBackup(*BackupFileRequest, grpc.ServerStreamingServer[BackupChunk]) error
+	UploadBackup(grpc.ClientStreamingServer[BackupChunk, BackupFileResponse]) error
+	DeleteBackup(context.Context, *BackupFileRequest) (*BackupFileResponse, error)
+	ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error)
+	PruneSnapshots(context.Context, *PruneSnapshotsRequest) (*PruneSnapshotsResponse, error)
 	// Heartbeat y health check
 	Ping(context.Context, *Empty) (*PongResponse, error)
 	HealthCheck(context.Context, *Empty) (*HealthStatus, error)
+	// Emparejamiento: el backend provisiona el token del agente
+	Pair(context.Context, *PairRequest) (*PairResponse, error)
+	// Certificados mTLS firmados por la CA del backend
+	CreateCertificateRequest(context.Context, *Empty) (*CertificateRequestResponse, error)
+	InstallCertificate(context.Context, *InstallCertificateRequest) (*InstallCertificateResponse, error)
 	mustEmbedUnimplementedAgentServiceServer()
 }
 
@@ -407,10 +700,16 @@ func (UnimplementedAgentServiceServer) StopServer(context.Context, *ServerReques
 func (UnimplementedAgentServiceServer) RestartServer(context.Context, *ServerRequest) (*ServerResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RestartServer not implemented")
 }
+func (UnimplementedAgentServiceServer) GetServerMetrics(context.Context, *ServerRequest) (*ServerMetrics, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerMetrics not implemented")
+}
+func (UnimplementedAgentServiceServer) EventStream(grpc.BidiStreamingServer[EventStreamRequest, AgentEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method EventStream not implemented")
+}
 func (UnimplementedAgentServiceServer) SendCommand(context.Context, *CommandRequest) (*CommandResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendCommand not implemented")
 }
-func (UnimplementedAgentServiceServer) StreamLogs(*ServerRequest, grpc.ServerStreamingServer[LogEntry]) error {
+func (UnimplementedAgentServiceServer) StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogEntry]) error {
 	return status.Errorf(codes.Unimplemented, "method StreamLogs not implemented")
 }
 func (UnimplementedAgentServiceServer) ReadFile(context.Context, *FileRequest) (*FileContent, error) {
@@ -422,6 +721,36 @@ func (UnimplementedAgentServiceServer) WriteFile(context.Context, *WriteFileRequ
 func (UnimplementedAgentServiceServer) ListFiles(context.Context, *DirectoryRequest) (*FileList, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListFiles not implemented")
 }
+func (UnimplementedAgentServiceServer) DeleteFile(context.Context, *DeleteFileRequest) (*FileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFile not implemented")
+}
+func (UnimplementedAgentServiceServer) MoveFile(context.Context, *MoveFileRequest) (*FileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveFile not implemented")
+}
+func (UnimplementedAgentServiceServer) CopyFile(context.Context, *MoveFileRequest) (*FileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CopyFile not implemented")
+}
+func (UnimplementedAgentServiceServer) CreateDirectory(context.Context, *CreateDirectoryRequest) (*FileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDirectory not implemented")
+}
+func (UnimplementedAgentServiceServer) ChangePermissions(context.Context, *ChangePermissionsRequest) (*FileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePermissions not implemented")
+}
+func (UnimplementedAgentServiceServer) CompressFiles(context.Context, *CompressFilesRequest) (*FileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompressFiles not implemented")
+}
+func (UnimplementedAgentServiceServer) ExtractArchive(context.Context, *ExtractArchiveRequest) (*FileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExtractArchive not implemented")
+}
+func (UnimplementedAgentServiceServer) UploadFile(grpc.ClientStreamingServer[FileChunk, FileTransferResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
+}
+func (UnimplementedAgentServiceServer) DownloadFile(*DownloadFileRequest, grpc.ServerStreamingServer[FileChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadFile not implemented")
+}
+func (UnimplementedAgentServiceServer) GetUploadOffset(context.Context, *FileRequest) (*UploadOffsetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUploadOffset not implemented")
+}
 func (UnimplementedAgentServiceServer) CheckDependencies(context.Context, *Empty) (*DependenciesStatus, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckDependencies not implemented")
 }
@@ -449,12 +778,36 @@ func (UnimplementedAgentServiceServer) CreateBackup(context.Context, *CreateBack
 func (UnimplementedAgentServiceServer) RestoreBackup(context.Context, *RestoreBackupRequest) (*RestoreBackupResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RestoreBackup not implemented")
 }
+func (UnimplementedAgentServiceServer) DownloadBackup(*BackupFileRequest, grpc.ServerStreamingServer[BackupChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadBackup not implemented")
+}
+func (UnimplementedAgentServiceServer) UploadBackup(grpc.ClientStreamingServer[BackupChunk, BackupFileResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadBackup not implemented")
+}
+func (UnimplementedAgentServiceServer) DeleteBackup(context.Context, *BackupFileRequest) (*BackupFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBackup not implemented")
+}
+func (UnimplementedAgentServiceServer) ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSnapshots not implemented")
+}
+func (UnimplementedAgentServiceServer) PruneSnapshots(context.Context, *PruneSnapshotsRequest) (*PruneSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PruneSnapshots not implemented")
+}
 func (UnimplementedAgentServiceServer) Ping(context.Context, *Empty) (*PongResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
 }
 func (UnimplementedAgentServiceServer) HealthCheck(context.Context, *Empty) (*HealthStatus, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
+func (UnimplementedAgentServiceServer) Pair(context.Context, *PairRequest) (*PairResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pair not implemented")
+}
+func (UnimplementedAgentServiceServer) CreateCertificateRequest(context.Context, *Empty) (*CertificateRequestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCertificateRequest not implemented")
+}
+func (UnimplementedAgentServiceServer) InstallCertificate(context.Context, *InstallCertificateRequest) (*InstallCertificateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InstallCertificate not implemented")
+}
 func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
 func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
 
@@ -602,6 +955,31 @@ func _AgentService_RestartServer_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_GetServerMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetServerMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetServerMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetServerMetrics(ctx, req.(*ServerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_EventStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServiceServer).EventStream(&grpc.GenericServerStream[EventStreamRequest, AgentEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_EventStreamServer = grpc.BidiStreamingServer[EventStreamRequest, AgentEvent]
+
 func _AgentService_SendCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CommandRequest)
 	if err := dec(in); err != nil {
@@ -621,11 +999,11 @@ func _AgentService_SendCommand_Handler(srv interface{}, ctx context.Context, dec
 }
 
 func _AgentService_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(ServerRequest)
+	m := new(StreamLogsRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(AgentServiceServer).StreamLogs(m, &grpc.GenericServerStream[ServerRequest, LogEntry]{ServerStream: stream})
+	return srv.(AgentServiceServer).StreamLogs(m, &grpc.GenericServerStream[StreamLogsRequest, LogEntry]{ServerStream: stream})
 }
 
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
@@ -685,6 +1063,168 @@ func _AgentService_ListFiles_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).DeleteFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_DeleteFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).DeleteFile(ctx, req.(*DeleteFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_MoveFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).MoveFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_MoveFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).MoveFile(ctx, req.(*MoveFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CopyFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CopyFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CopyFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CopyFile(ctx, req.(*MoveFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CreateDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDirectoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CreateDirectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CreateDirectory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CreateDirectory(ctx, req.(*CreateDirectoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ChangePermissions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePermissionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ChangePermissions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ChangePermissions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ChangePermissions(ctx, req.(*ChangePermissionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CompressFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompressFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CompressFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CompressFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CompressFiles(ctx, req.(*CompressFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ExtractArchive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtractArchiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ExtractArchive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ExtractArchive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ExtractArchive(ctx, req.(*ExtractArchiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UploadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServiceServer).UploadFile(&grpc.GenericServerStream[FileChunk, FileTransferResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_UploadFileServer = grpc.ClientStreamingServer[FileChunk, FileTransferResponse]
+
+func _AgentService_DownloadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).DownloadFile(m, &grpc.GenericServerStream[DownloadFileRequest, FileChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_DownloadFileServer = grpc.ServerStreamingServer[FileChunk]
+
+func _AgentService_GetUploadOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetUploadOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetUploadOffset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetUploadOffset(ctx, req.(*FileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_CheckDependencies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -840,6 +1380,78 @@ func _AgentService_RestoreBackup_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_DownloadBackup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).DownloadBackup(m, &grpc.GenericServerStream[BackupFileRequest, BackupChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_DownloadBackupServer = grpc.ServerStreamingServer[BackupChunk]
+
+func _AgentService_UploadBackup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServiceServer).UploadBackup(&grpc.GenericServerStream[BackupChunk, BackupFileResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_UploadBackupServer = grpc.ClientStreamingServer[BackupChunk, BackupFileResponse]
+
+func _AgentService_DeleteBackup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).DeleteBackup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_DeleteBackup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).DeleteBackup(ctx, req.(*BackupFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListSnapshots(ctx, req.(*ListSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_PruneSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PruneSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).PruneSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_PruneSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).PruneSnapshots(ctx, req.(*PruneSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -876,6 +1488,60 @@ func _AgentService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_Pair_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PairRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).Pair(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_Pair_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).Pair(ctx, req.(*PairRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CreateCertificateRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CreateCertificateRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CreateCertificateRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CreateCertificateRequest(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_InstallCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstallCertificateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).InstallCertificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_InstallCertificate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).InstallCertificate(ctx, req.(*InstallCertificateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -911,6 +1577,10 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RestartServer",
 			Handler:    _AgentService_RestartServer_Handler,
 		},
+		{
+			MethodName: "GetServerMetrics",
+			Handler:    _AgentService_GetServerMetrics_Handler,
+		},
 		{
 			MethodName: "SendCommand",
 			Handler:    _AgentService_SendCommand_Handler,
@@ -927,6 +1597,38 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListFiles",
 			Handler:    _AgentService_ListFiles_Handler,
 		},
+		{
+			MethodName: "DeleteFile",
+			Handler:    _AgentService_DeleteFile_Handler,
+		},
+		{
+			MethodName: "MoveFile",
+			Handler:    _AgentService_MoveFile_Handler,
+		},
+		{
+			MethodName: "CopyFile",
+			Handler:    _AgentService_CopyFile_Handler,
+		},
+		{
+			MethodName: "CreateDirectory",
+			Handler:    _AgentService_CreateDirectory_Handler,
+		},
+		{
+			MethodName: "ChangePermissions",
+			Handler:    _AgentService_ChangePermissions_Handler,
+		},
+		{
+			MethodName: "CompressFiles",
+			Handler:    _AgentService_CompressFiles_Handler,
+		},
+		{
+			MethodName: "ExtractArchive",
+			Handler:    _AgentService_ExtractArchive_Handler,
+		},
+		{
+			MethodName: "GetUploadOffset",
+			Handler:    _AgentService_GetUploadOffset_Handler,
+		},
 		{
 			MethodName: "CheckDependencies",
 			Handler:    _AgentService_CheckDependencies_Handler,
@@ -959,6 +1661,18 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RestoreBackup",
 			Handler:    _AgentService_RestoreBackup_Handler,
 		},
+		{
+			MethodName: "DeleteBackup",
+			Handler:    _AgentService_DeleteBackup_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _AgentService_ListSnapshots_Handler,
+		},
+		{
+			MethodName: "PruneSnapshots",
+			Handler:    _AgentService_PruneSnapshots_Handler,
+		},
 		{
 			MethodName: "Ping",
 			Handler:    _AgentService_Ping_Handler,
@@ -967,18 +1681,56 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HealthCheck",
 			Handler:    _AgentService_HealthCheck_Handler,
 		},
+		{
+			MethodName: "Pair",
+			Handler:    _AgentService_Pair_Handler,
+		},
+		{
+			MethodName: "CreateCertificateRequest",
+			Handler:    _AgentService_CreateCertificateRequest_Handler,
+		},
+		{
+			MethodName: "InstallCertificate",
+			Handler:    _AgentService_InstallCertificate_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "EventStream",
+			Handler:       _AgentService_EventStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "StreamLogs",
 			Handler:       _AgentService_StreamLogs_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "UploadFile",
+			Handler:       _AgentService_UploadFile_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadFile",
+			Handler:       _AgentService_DownloadFile_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "DownloadServer",
 			Handler:       _AgentService_DownloadServer_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "DownloadBackup",
+			Handler:       _AgentService_DownloadBackup_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadBackup",
+			Handler:       _AgentService_UploadBackup_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "proto/agent.proto",
 }
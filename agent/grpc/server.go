@@ -3,10 +3,12 @@ package grpc
 import (
 	"log"
 	"net"
+	"path/filepath"
 
 	"github.com/aymc/agent/core"
 	pb "github.com/aymc/agent/grpc/pb"
 	"github.com/aymc/agent/security"
+	"github.com/aymc/agent/utils"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
@@ -39,10 +41,20 @@ func NewServer(agent *core.Agent, secManager *security.SecurityManager) *Server
 		grpc.MaxSendMsgSize(10*1024*1024), // 10MB
 	)
 
+	// Todas las llamadas (excepto Pair) requieren el token del backend
+	opts = append(opts,
+		grpc.UnaryInterceptor(secManager.UnaryInterceptor()),
+		grpc.StreamInterceptor(secManager.StreamInterceptor()),
+	)
+
 	grpcServer := grpc.NewServer(opts...)
 
 	// Registrar servicio AgentService
-	serviceImpl := &agentServiceImpl{agent: agent}
+	serviceImpl := &agentServiceImpl{
+		agent:     agent,
+		security:  secManager,
+		snapshots: utils.NewChunkStore(filepath.Join(agent.GetConfig().BackupDir, "snapshots")),
+	}
 	pb.RegisterAgentServiceServer(grpcServer, serviceImpl)
 
 	// Habilitar reflection para desarrollo
@@ -16,6 +16,7 @@ import (
 
 	"github.com/aymc/agent/core"
 	pb "github.com/aymc/agent/grpc/pb"
+	"github.com/aymc/agent/security"
 	"github.com/aymc/agent/utils"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -25,7 +26,9 @@ import (
 // agentServiceImpl implementa el servicio AgentService
 type agentServiceImpl struct {
 	pb.UnimplementedAgentServiceServer
-	agent *core.Agent
+	agent     *core.Agent
+	security  *security.SecurityManager
+	snapshots *utils.ChunkStore // Almacén de backups incrementales
 }
 
 // GetAgentInfo retorna información del agente
@@ -34,12 +37,13 @@ func (s *agentServiceImpl) GetAgentInfo(ctx context.Context, req *pb.Empty) (*pb
 
 	config := s.agent.GetConfig()
 	servers := s.agent.ListServers()
+	hostInfo := s.agent.GetMonitor().GetHostInfo()
 
 	info := &pb.AgentInfo{
 		AgentId:       config.AgentID,
 		Version:       "0.1.0",
-		Platform:      "linux", // TODO: Detectar dinámicamente
-		PlatformVersion: "unknown",
+		Platform:      hostInfo.Platform,
+		PlatformVersion: hostInfo.PlatformVersion,
 		UptimeSeconds: int64(time.Since(s.agent.GetStartTime()).Seconds()),
 		ActiveServers: int32(len(servers)),
 		MaxServers:    int32(config.MaxServers),
@@ -85,7 +89,7 @@ func (s *agentServiceImpl) ListServers(ctx context.Context, req *pb.Empty) (*pb.
 	pbServers := make([]*pb.ServerInfo, 0, len(servers))
 
 	for _, srv := range servers {
-		pbServers = append(pbServers, convertToProtoServer(srv))
+		pbServers = append(pbServers, s.serverInfo(srv))
 	}
 
 	return &pb.ServerList{Servers: pbServers}, nil
@@ -100,7 +104,36 @@ func (s *agentServiceImpl) GetServer(ctx context.Context, req *pb.ServerRequest)
 		return nil, status.Errorf(codes.NotFound, "servidor no encontrado: %v", err)
 	}
 
-	return convertToProtoServer(server), nil
+	return s.serverInfo(server), nil
+}
+
+// GetServerMetrics retorna el uso de recursos del proceso de un servidor
+func (s *agentServiceImpl) GetServerMetrics(ctx context.Context, req *pb.ServerRequest) (*pb.ServerMetrics, error) {
+	log.Printf("[DEBUG] GetServerMetrics llamado: %s", req.ServerId)
+
+	if _, err := s.agent.GetServer(req.ServerId); err != nil {
+		return nil, status.Errorf(codes.NotFound, "servidor no encontrado: %v", err)
+	}
+
+	metrics, err := s.agent.GetServerMetrics(req.ServerId)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
+	pbMetrics := convertToProtoServerMetrics(req.ServerId, metrics)
+	addGameStats(pbMetrics, s.agent.GetGameStats(req.ServerId))
+	return pbMetrics, nil
+}
+
+// serverInfo convierte un servidor a proto e incluye sus métricas si el
+// proceso está en ejecución
+func (s *agentServiceImpl) serverInfo(srv *core.MinecraftServer) *pb.ServerInfo {
+	info := convertToProtoServer(srv)
+	if metrics, err := s.agent.GetServerMetrics(srv.ID); err == nil {
+		info.Metrics = convertToProtoServerMetrics(srv.ID, metrics)
+		addGameStats(info.Metrics, s.agent.GetGameStats(srv.ID))
+	}
+	return info
 }
 
 // StartServer inicia un servidor
@@ -165,36 +198,19 @@ func (s *agentServiceImpl) StopServer(ctx context.Context, req *pb.ServerRequest
 func (s *agentServiceImpl) RestartServer(ctx context.Context, req *pb.ServerRequest) (*pb.ServerResponse, error) {
 	log.Printf("[INFO] RestartServer llamado: %s", req.ServerId)
 
-	// Detener servidor
-	if err := s.agent.StopServer(req.ServerId); err != nil {
+	if err := s.agent.RestartServer(req.ServerId); err != nil {
 		return &pb.ServerResponse{
 			Success: false,
-			Message: fmt.Sprintf("error deteniendo servidor: %v", err),
+			Message: fmt.Sprintf("error reiniciando servidor: %v", err),
 		}, nil
 	}
 
-	// Esperar un momento
-	time.Sleep(2 * time.Second)
-
-	// Obtener servidor y reiniciar
-	server, err := s.agent.GetServer(req.ServerId)
-	if err != nil {
-		return &pb.ServerResponse{
-			Success: false,
-			Message: fmt.Sprintf("error obteniendo servidor: %v", err),
-		}, nil
-	}
-
-	if err := s.agent.StartServer(server); err != nil {
-		return &pb.ServerResponse{
-			Success: false,
-			Message: fmt.Sprintf("error iniciando servidor: %v", err),
-		}, nil
-	}
+	server, _ := s.agent.GetServer(req.ServerId)
 
 	return &pb.ServerResponse{
 		Success: true,
 		Message: "Servidor reiniciado correctamente",
+		Server:  convertToProtoServer(server),
 	}, nil
 }
 
@@ -216,165 +232,116 @@ func (s *agentServiceImpl) SendCommand(ctx context.Context, req *pb.CommandReque
 	}, nil
 }
 
-// StreamLogs transmite logs en tiempo real
-func (s *agentServiceImpl) StreamLogs(req *pb.ServerRequest, stream pb.AgentService_StreamLogsServer) error {
+// StreamLogs envía el historial solicitado de un servidor y luego sigue su
+// salida en vivo. Cada llamada tiene su propia suscripción al broker, por
+// lo que varios clientes reciben todas las líneas. Las líneas se parsean y
+// los stack traces se agrupan con la línea que los originó.
+func (s *agentServiceImpl) StreamLogs(req *pb.StreamLogsRequest, stream pb.AgentService_StreamLogsServer) error {
 	log.Printf("[INFO] StreamLogs iniciado para: %s", req.ServerId)
 
-	logChan, err := s.agent.GetExecutor().GetLogs(req.ServerId)
+	broker, err := s.agent.GetExecutor().GetLogBroker(req.ServerId)
 	if err != nil {
 		return status.Errorf(codes.NotFound, "servidor no encontrado: %v", err)
 	}
 
-	// Stream de logs
-	for {
-		select {
-		case <-stream.Context().Done():
-			log.Printf("[INFO] StreamLogs cerrado para: %s", req.ServerId)
-			return nil
-		case logLine, ok := <-logChan:
-			if !ok {
-				return nil
-			}
-
-			entry := &pb.LogEntry{
-				Timestamp: time.Now().Unix(),
-				ServerId:  req.ServerId,
-				Level:     "INFO", // TODO: Parsear nivel
-				Source:    "STDOUT",
-				Message:   logLine,
-			}
-
-			if err := stream.Send(entry); err != nil {
-				log.Printf("[ERROR] Error enviando log: %v", err)
-				return err
-			}
-		}
-	}
-}
-
-// ReadFile lee un archivo remoto
-func (s *agentServiceImpl) ReadFile(ctx context.Context, req *pb.FileRequest) (*pb.FileContent, error) {
-	log.Printf("[DEBUG] ReadFile llamado: %s", req.Path)
-
-	// Validar ruta (seguridad básica)
-	if !isValidPath(req.Path) {
-		return nil, status.Errorf(codes.PermissionDenied, "ruta no válida")
+	opts := core.LogReplayOptions{
+		Tail:          int(req.TailLines),
+		AfterSequence: req.AfterSequence,
+		NoHistory:     req.NoHistory,
 	}
-
-	// Leer archivo
-	data, err := os.ReadFile(req.Path)
-	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "error leyendo archivo: %v", err)
+	if req.Since > 0 {
+		opts.Since = time.Unix(req.Since, 0)
 	}
 
-	// Obtener info del archivo
-	info, err := os.Stat(req.Path)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "error obteniendo info: %v", err)
-	}
+	sub := broker.Subscribe(opts)
+	defer func() { sub.Close() }()
 
-	return &pb.FileContent{
-		Content:      data,
-		Size:         info.Size(),
-		ModifiedTime: info.ModTime().Unix(),
-	}, nil
-}
-
-// WriteFile escribe un archivo remoto
-func (s *agentServiceImpl) WriteFile(ctx context.Context, req *pb.WriteFileRequest) (*pb.FileResponse, error) {
-	log.Printf("[DEBUG] WriteFile llamado: %s", req.Path)
-
-	// Validar ruta
-	if !isValidPath(req.Path) {
-		return &pb.FileResponse{
-			Success: false,
-			Message: "ruta no válida",
-		}, nil
-	}
+	assembler := core.NewLogAssembler()
+	flushTimer := time.NewTimer(core.LogFoldDelay)
+	flushTimer.Stop()
+	defer flushTimer.Stop()
 
-	// Crear directorios si es necesario
-	if req.CreateDirs {
-		dir := filepath.Dir(req.Path)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return &pb.FileResponse{
-				Success: false,
-				Message: fmt.Sprintf("error creando directorios: %v", err),
-			}, nil
+	// Última línea consumida del broker (enviada o pendiente de agrupar)
+	var lastSeq uint64
+	send := func(entry *core.StructuredLogEntry) error {
+		if entry == nil {
+			return nil
+		}
+		if err := stream.Send(convertToProtoLogEntry(req.ServerId, entry)); err != nil {
+			log.Printf("[ERROR] Error enviando log: %v", err)
+			return err
 		}
+		return nil
 	}
-
-	// Escribir archivo
-	if err := os.WriteFile(req.Path, req.Content, 0644); err != nil {
-		return &pb.FileResponse{
-			Success: false,
-			Message: fmt.Sprintf("error escribiendo archivo: %v", err),
-		}, nil
+	consume := func(line core.LogLine) error {
+		lastSeq = line.Sequence
+		if err := send(assembler.Add(line)); err != nil {
+			return err
+		}
+		// Esperar un poco por más líneas de stack trace antes de emitir
+		if assembler.HasPending() {
+			flushTimer.Reset(core.LogFoldDelay)
+		}
+		return nil
 	}
 
-	return &pb.FileResponse{
-		Success: true,
-		Message: "Archivo escrito correctamente",
-	}, nil
-}
-
-// ListFiles lista archivos en un directorio
-func (s *agentServiceImpl) ListFiles(ctx context.Context, req *pb.DirectoryRequest) (*pb.FileList, error) {
-	log.Printf("[DEBUG] ListFiles llamado: %s", req.Path)
-
-	// Validar ruta
-	if !isValidPath(req.Path) {
-		return nil, status.Errorf(codes.PermissionDenied, "ruta no válida")
+	// Historial
+	for _, line := range sub.History {
+		if err := consume(line); err != nil {
+			return err
+		}
 	}
 
-	var files []*pb.FileInfo
-
-	if req.Recursive {
-		// Listado recursivo
-		err := filepath.Walk(req.Path, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // Continuar con siguientes archivos
-			}
-
-			files = append(files, &pb.FileInfo{
-				Name:         info.Name(),
-				Path:         path,
-				Size:         info.Size(),
-				IsDir:        info.IsDir(),
-				ModifiedTime: info.ModTime().Unix(),
-				Permissions:  int32(info.Mode().Perm()),
-			})
-
+	// Stream en vivo
+	for {
+		select {
+		case <-stream.Context().Done():
+			log.Printf("[INFO] StreamLogs cerrado para: %s", req.ServerId)
 			return nil
-		})
-
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "error listando archivos: %v", err)
-		}
-	} else {
-		// Listado simple
-		entries, err := os.ReadDir(req.Path)
-		if err != nil {
-			return nil, status.Errorf(codes.NotFound, "error leyendo directorio: %v", err)
-		}
-
-		for _, entry := range entries {
-			info, err := entry.Info()
-			if err != nil {
+		case <-flushTimer.C:
+			if err := send(assembler.Flush()); err != nil {
+				return err
+			}
+		case line, ok := <-sub.C:
+			if !ok {
+				if !sub.Lagged() {
+					return send(assembler.Flush())
+				}
+				// Cliente lento: liberar la suscripción anterior y reanudar
+				// desde la última línea consumida
+				sub.Close()
+				sub = broker.Subscribe(core.LogReplayOptions{AfterSequence: lastSeq})
+				for _, line := range sub.History {
+					if err := consume(line); err != nil {
+						return err
+					}
+				}
 				continue
 			}
 
-			files = append(files, &pb.FileInfo{
-				Name:         info.Name(),
-				Path:         filepath.Join(req.Path, info.Name()),
-				Size:         info.Size(),
-				IsDir:        info.IsDir(),
-				ModifiedTime: info.ModTime().Unix(),
-				Permissions:  int32(info.Mode().Perm()),
-			})
+			if err := consume(line); err != nil {
+				return err
+			}
 		}
 	}
+}
 
-	return &pb.FileList{Files: files}, nil
+func convertToProtoLogEntry(serverID string, entry *core.StructuredLogEntry) *pb.LogEntry {
+	return &pb.LogEntry{
+		Timestamp:  entry.Timestamp.Unix(),
+		ServerId:   serverID,
+		Level:      string(entry.Level),
+		Source:     entry.Source,
+		Message:    entry.Message,
+		Plugin:     entry.Plugin,
+		Sequence:   entry.Sequence,
+		Thread:     entry.Thread,
+		Stream:     entry.Stream,
+		ErrorType:  entry.ErrorType,
+		Severity:   int32(entry.Severity),
+		Suggestion: entry.Suggestion,
+		StackTrace: entry.StackTrace,
+	}
 }
 
 // CheckDependencies verifica dependencias del sistema
@@ -541,16 +508,18 @@ func (s *agentServiceImpl) HealthCheck(ctx context.Context, req *pb.Empty) (*pb.
 
 func convertToProtoServer(srv *core.MinecraftServer) *pb.ServerInfo {
 	return &pb.ServerInfo{
-		Id:          srv.ID,
-		Name:        srv.Name,
-		Type:        srv.Type,
-		Version:     srv.Version,
-		JavaVersion: srv.JavaVersion,
-		Port:        int32(srv.Port),
-		Status:      string(srv.Status),
-		Pid:         int32(srv.PID),
-		StartTime:   srv.StartTime.Unix(),
-		WorkDir:     srv.WorkDir,
+		Id:           srv.ID,
+		Name:         srv.Name,
+		Type:         srv.Type,
+		Version:      srv.Version,
+		JavaVersion:  srv.JavaVersion,
+		Port:         int32(srv.Port),
+		Status:       string(srv.Status),
+		Pid:          int32(srv.PID),
+		StartTime:    srv.StartTime.Unix(),
+		WorkDir:      srv.WorkDir,
+		RestartCount: int32(srv.RestartCount),
+		LastExitCode: int32(srv.LastExitCode),
 		Config: &pb.ServerConfig{
 			MinRam:      srv.Config.MinRAM,
 			MaxRam:      srv.Config.MaxRAM,
@@ -562,23 +531,47 @@ func convertToProtoServer(srv *core.MinecraftServer) *pb.ServerInfo {
 	}
 }
 
-func isValidPath(path string) bool {
-	// Validación básica de seguridad
-	// TODO: Mejorar con whitelist de directorios permitidos
-	abs, err := filepath.Abs(path)
-	if err != nil {
-		return false
+func convertToProtoServerMetrics(serverID string, metrics *core.ProcessMetrics) *pb.ServerMetrics {
+	return &pb.ServerMetrics{
+		ServerId:     serverID,
+		Timestamp:    metrics.Timestamp.Unix(),
+		Pid:          int32(metrics.PID),
+		CpuPercent:   metrics.CPUPercent,
+		MemoryRss:    metrics.MemoryRSS,
+		Threads:      metrics.Threads,
+		OpenFiles:    metrics.OpenFiles,
+		ProcessCount: metrics.ProcessCount,
+		DiskUsed:     metrics.DiskUsed,
 	}
+}
 
-	// No permitir acceso a directorios del sistema críticos
-	forbidden := []string{"/etc/shadow", "/etc/passwd", "/root/.ssh"}
-	for _, f := range forbidden {
-		if abs == f || strings.HasPrefix(abs, f) {
-			return false
-		}
-	}
+// addGameStats completa las métricas con las últimas estadísticas de juego
+func addGameStats(metrics *pb.ServerMetrics, stats *core.GameStats) {
+	if stats == nil {
+		return
+	}
+	metrics.StatsTimestamp = stats.Timestamp.Unix()
+	metrics.GameVersion = stats.Version
+	metrics.PlayersOnline = int32(stats.PlayersOnline)
+	metrics.MaxPlayers = int32(stats.MaxPlayers)
+	metrics.Players = stats.Players
+	metrics.LatencyMs = stats.Latency.Milliseconds()
+	metrics.Tps = stats.TPS
+	metrics.Mspt = stats.MSPT
+}
 
-	return true
+func convertToProtoEvent(event core.ServerEvent) *pb.ServerEvent {
+	return &pb.ServerEvent{
+		Timestamp:   event.Timestamp.Unix(),
+		ServerId:    event.ServerID,
+		Type:        string(event.Type),
+		Status:      string(event.Status),
+		ExitCode:    int32(event.ExitCode),
+		Attempt:     int32(event.Attempt),
+		MaxAttempts: int32(event.MaxAttempts),
+		BackoffMs:   event.Backoff.Milliseconds(),
+		Reason:      event.Reason,
+	}
 }
 
 // InstallPlugin instala un plugin en un servidor
@@ -1134,8 +1127,8 @@ func (s *agentServiceImpl) CreateBackup(ctx context.Context, req *pb.CreateBacku
 		}, nil
 	}
 
-	// Detener servidor si se solicita
-	if req.StopServer {
+	// Detener servidor si se solicita (y si está en ejecución)
+	if req.StopServer && server.Status != core.StatusStopped && server.Status != core.StatusCrashed {
 		log.Printf("[INFO] Deteniendo servidor antes del backup...")
 		if err := s.agent.StopServer(req.ServerId); err != nil {
 			return &pb.CreateBackupResponse{
@@ -1147,20 +1140,6 @@ func (s *agentServiceImpl) CreateBackup(ctx context.Context, req *pb.CreateBacku
 		time.Sleep(2 * time.Second)
 	}
 
-	// Crear directorio de destino si no existe
-	if err := os.MkdirAll(filepath.Dir(req.Destination), 0755); err != nil {
-		return &pb.CreateBackupResponse{
-			Success: false,
-			Message: fmt.Sprintf("Error creando directorio de destino: %v", err),
-		}, nil
-	}
-
-	// Crear el archivo tar.gz
-	backupPath := req.Destination
-	if !strings.HasSuffix(backupPath, ".tar.gz") && req.Compression == "gzip" {
-		backupPath += ".tar.gz"
-	}
-
 	// Determinar qué incluir en el backup
 	includePaths := make(map[string]bool)
 	if req.IncludeWorld {
@@ -1189,9 +1168,68 @@ func (s *agentServiceImpl) CreateBackup(ctx context.Context, req *pb.CreateBacku
 		includePaths = nil // nil significa incluir todo
 	}
 
+	// En modo en línea el servidor sigue en ejecución y solo se desactiva
+	// el guardado mientras se copian los archivos
+	copyFiles := func(fn func(ctx context.Context) error) error {
+		if req.Online && !req.StopServer {
+			return s.agent.WithSavingDisabled(ctx, req.ServerId, fn)
+		}
+		return fn(ctx)
+	}
+
+	// Los backups incrementales se guardan en el almacén deduplicado
+	if req.Incremental {
+		var snapshot *utils.Snapshot
+		err := copyFiles(func(ctx context.Context) error {
+			var err error
+			snapshot, err = s.snapshots.CreateSnapshot(ctx, server.ID, server.WorkDir, includePaths, req.ExcludePaths)
+			return err
+		})
+		if err != nil {
+			return &pb.CreateBackupResponse{
+				Success: false,
+				Message: fmt.Sprintf("Error creando snapshot: %v", err),
+			}, nil
+		}
+		return s.snapshotResponse(snapshot, startTime), nil
+	}
+
+	// Las rutas relativas se guardan en el directorio de backups del agente
+	destination := req.Destination
+	if !filepath.IsAbs(destination) {
+		destination, err = s.agent.ResolveBackupPath(destination)
+		if err != nil {
+			return &pb.CreateBackupResponse{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+	}
+
+	// Crear directorio de destino si no existe
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return &pb.CreateBackupResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creando directorio de destino: %v", err),
+		}, nil
+	}
+
+	// Crear el archivo tar.gz
+	backupPath := destination
+	if !strings.HasSuffix(backupPath, ".tar.gz") && req.Compression == "gzip" {
+		backupPath += ".tar.gz"
+	}
+
 	// Crear el backup
-	size, checksum, err := utils.CreateTarGzBackup(server.WorkDir, backupPath, includePaths, req.ExcludePaths, req.Compression == "gzip")
+	var size int64
+	var checksum string
+	err = copyFiles(func(ctx context.Context) error {
+		var err error
+		size, checksum, err = utils.CreateTarGzBackupContext(ctx, server.WorkDir, backupPath, includePaths, req.ExcludePaths, req.Compression == "gzip")
+		return err
+	})
 	if err != nil {
+		os.Remove(backupPath)
 		return &pb.CreateBackupResponse{
 			Success: false,
 			Message: fmt.Sprintf("Error creando backup: %v", err),
@@ -1226,20 +1264,51 @@ func (s *agentServiceImpl) RestoreBackup(ctx context.Context, req *pb.RestoreBac
 		}, nil
 	}
 
-	// Verificar que el archivo de backup existe
-	if _, err := os.Stat(req.BackupPath); os.IsNotExist(err) {
-		return &pb.RestoreBackupResponse{
-			Success: false,
-			Message: "Archivo de backup no encontrado",
-		}, nil
+	backupPath := req.BackupPath
+	if req.SnapshotId != "" {
+		// Verificar que el snapshot existe
+		if _, err := s.snapshots.GetSnapshot(req.ServerId, req.SnapshotId); err != nil {
+			return &pb.RestoreBackupResponse{
+				Success: false,
+				Message: err.Error(),
+			}, nil
+		}
+	} else {
+		if !filepath.IsAbs(backupPath) {
+			backupPath, err = s.agent.ResolveBackupPath(backupPath)
+			if err != nil {
+				return &pb.RestoreBackupResponse{
+					Success: false,
+					Message: err.Error(),
+				}, nil
+			}
+		}
+
+		// Verificar que el archivo de backup existe
+		if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+			return &pb.RestoreBackupResponse{
+				Success: false,
+				Message: "Archivo de backup no encontrado",
+			}, nil
+		}
 	}
 
 	var safetyBackupPath string
 
-	// Crear backup de seguridad si se solicita
-	if req.BackupBeforeRestore {
+	// Antes de restaurar un snapshot, el backup de seguridad es otro
+	// snapshot: solo ocupa los fragmentos que cambiaron
+	if req.BackupBeforeRestore && req.SnapshotId != "" {
+		log.Printf("[INFO] Creando snapshot de seguridad antes de restaurar...")
+		safety, err := s.snapshots.CreateSnapshot(ctx, req.ServerId, server.WorkDir, nil, nil)
+		if err != nil {
+			log.Printf("[WARN] Error creando snapshot de seguridad: %v", err)
+		} else {
+			safetyBackupPath = safety.ID
+			log.Printf("[INFO] Snapshot de seguridad creado: %s", safety.ID)
+		}
+	} else if req.BackupBeforeRestore {
 		log.Printf("[INFO] Creando backup de seguridad antes de restaurar...")
-		safetyBackupPath = filepath.Join(filepath.Dir(req.BackupPath), fmt.Sprintf("safety-backup-%d.tar.gz", time.Now().Unix()))
+		safetyBackupPath = filepath.Join(filepath.Dir(backupPath), fmt.Sprintf("safety-backup-%d.tar.gz", time.Now().Unix()))
 		
 		_, _, err := utils.CreateTarGzBackup(server.WorkDir, safetyBackupPath, nil, nil, true)
 		if err != nil {
@@ -1250,8 +1319,8 @@ func (s *agentServiceImpl) RestoreBackup(ctx context.Context, req *pb.RestoreBac
 		}
 	}
 
-	// Detener servidor si se solicita
-	if req.StopServer {
+	// Detener servidor si se solicita (y si está en ejecución)
+	if req.StopServer && server.Status != core.StatusStopped && server.Status != core.StatusCrashed {
 		log.Printf("[INFO] Deteniendo servidor antes de restaurar...")
 		if err := s.agent.StopServer(req.ServerId); err != nil {
 			return &pb.RestoreBackupResponse{
@@ -1282,8 +1351,18 @@ func (s *agentServiceImpl) RestoreBackup(ctx context.Context, req *pb.RestoreBac
 		restorePaths["config"] = true
 	}
 
-	// Extraer el backup
-	if err := utils.ExtractTarGzBackup(req.BackupPath, server.WorkDir, restorePaths); err != nil {
+	// Sin selección explícita se restaura todo el backup
+	if len(restorePaths) == 0 {
+		restorePaths = nil
+	}
+
+	// Extraer el backup (o reconstruir el snapshot)
+	if req.SnapshotId != "" {
+		err = s.snapshots.RestoreSnapshot(req.ServerId, req.SnapshotId, server.WorkDir, restorePaths)
+	} else {
+		err = utils.ExtractTarGzBackup(backupPath, server.WorkDir, restorePaths)
+	}
+	if err != nil {
 		return &pb.RestoreBackupResponse{
 			Success: false,
 			Message: fmt.Sprintf("Error restaurando backup: %v", err),
@@ -0,0 +1,278 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pb "github.com/aymc/agent/grpc/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// fileChunkSize tamaño de cada fragmento enviado en DownloadFile
+	fileChunkSize = 1024 * 1024
+
+	// uploadSuffix extensión del archivo parcial de una subida. Se conserva
+	// si la subida se interrumpe para poder reanudarla.
+	uploadSuffix = ".upload"
+)
+
+// UploadFile recibe un archivo en fragmentos. Los datos se escriben en
+// <ruta>.upload a partir del offset del primer mensaje y solo se renombra al
+// destino cuando el stream termina bien y el checksum (si se envió) coincide.
+func (s *agentServiceImpl) UploadFile(stream pb.AgentService_UploadFileServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	target, err := s.resolveFile(first.ServerId, first.Path)
+	if err != nil {
+		return err
+	}
+	if target.path == target.sandbox.Root() {
+		return status.Errorf(codes.InvalidArgument, "ruta no válida: es el directorio raíz")
+	}
+	if info, err := os.Stat(target.path); err == nil && info.IsDir() {
+		return status.Errorf(codes.InvalidArgument, "%s es un directorio", target.display(target.path))
+	}
+
+	if first.CreateDirs {
+		if err := os.MkdirAll(filepath.Dir(target.path), 0755); err != nil {
+			return fsError(err, "error creando directorios")
+		}
+	}
+
+	partPath := target.path + uploadSuffix
+	file, hasher, err := openUploadPart(partPath, first.Offset)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] UploadFile: recibiendo %s desde el byte %d", target.path, first.Offset)
+
+	size, checksum, err := receiveFileChunks(stream, first, io.MultiWriter(file, hasher))
+	if err == nil {
+		if syncErr := file.Sync(); syncErr != nil {
+			err = status.Errorf(codes.Internal, "error guardando archivo: %v", syncErr)
+		}
+	}
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = status.Errorf(codes.Internal, "error guardando archivo: %v", closeErr)
+	}
+	if err != nil {
+		// El parcial se conserva: GetUploadOffset indica desde dónde seguir
+		log.Printf("[WARN] UploadFile: subida de %s interrumpida en el byte %d: %v", target.path, size, err)
+		return err
+	}
+
+	sum := hex.EncodeToString(hasher.Sum(nil))
+	if checksum != "" && !strings.EqualFold(checksum, sum) {
+		os.Remove(partPath)
+		return status.Errorf(codes.DataLoss, "checksum no coincide: esperado %s, recibido %s", checksum, sum)
+	}
+
+	if err := os.Rename(partPath, target.path); err != nil {
+		return fsError(err, "error guardando archivo")
+	}
+
+	log.Printf("[INFO] UploadFile: recibido %s (%d bytes)", target.path, size)
+
+	return stream.SendAndClose(&pb.FileTransferResponse{
+		Success:   true,
+		Message:   "Archivo recibido",
+		Path:      target.display(target.path),
+		SizeBytes: size,
+		Checksum:  sum,
+	})
+}
+
+// openUploadPart abre el archivo parcial de una subida posicionado en offset.
+// offset 0 empieza de nuevo; un offset mayor reanuda y no puede superar lo
+// ya recibido. El hash retornado incluye los bytes anteriores a offset.
+func openUploadPart(path string, offset int64) (*os.File, hash.Hash, error) {
+	if info, err := os.Lstat(path); err == nil && !info.Mode().IsRegular() {
+		return nil, nil, status.Errorf(codes.FailedPrecondition, "%s no es un archivo regular", filepath.Base(path))
+	}
+
+	flags := os.O_RDWR
+	if offset == 0 {
+		flags |= os.O_CREATE
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, status.Errorf(codes.FailedPrecondition, "offset %d no válido: no hay una subida que reanudar", offset)
+		}
+		return nil, nil, fsError(err, "error creando archivo")
+	}
+
+	fail := func(err error) (*os.File, hash.Hash, error) {
+		file.Close()
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return fail(fsError(err, "error leyendo archivo parcial"))
+	}
+	if offset < 0 || offset > info.Size() {
+		return fail(status.Errorf(codes.FailedPrecondition, "offset %d no válido: hay %d bytes recibidos", offset, info.Size()))
+	}
+	if err := file.Truncate(offset); err != nil {
+		return fail(fsError(err, "error preparando archivo parcial"))
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, io.NewSectionReader(file, 0, offset)); err != nil {
+		return fail(fsError(err, "error leyendo archivo parcial"))
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return fail(fsError(err, "error leyendo archivo parcial"))
+	}
+
+	return file, hasher, nil
+}
+
+// receiveFileChunks escribe los fragmentos recibidos en orden. Retorna el
+// offset final y el último checksum enviado por el cliente.
+func receiveFileChunks(stream pb.AgentService_UploadFileServer, chunk *pb.FileChunk, writer io.Writer) (int64, string, error) {
+	offset := chunk.Offset
+	checksum := ""
+	for {
+		if chunk.Offset != offset {
+			return offset, "", status.Errorf(codes.InvalidArgument, "fragmento fuera de orden: offset %d, esperado %d", chunk.Offset, offset)
+		}
+		if chunk.Checksum != "" {
+			checksum = chunk.Checksum
+		}
+
+		n, err := writer.Write(chunk.Data)
+		offset += int64(n)
+		if err != nil {
+			return offset, "", fsError(err, "error escribiendo archivo")
+		}
+
+		chunk, err = stream.Recv()
+		if err == io.EOF {
+			return offset, checksum, nil
+		}
+		if err != nil {
+			return offset, "", err
+		}
+	}
+}
+
+// GetUploadOffset retorna cuántos bytes hay recibidos de una subida
+// interrumpida, para reanudarla desde ahí
+func (s *agentServiceImpl) GetUploadOffset(ctx context.Context, req *pb.FileRequest) (*pb.UploadOffsetResponse, error) {
+	target, err := s.resolveFile(req.ServerId, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(target.path + uploadSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &pb.UploadOffsetResponse{Offset: 0}, nil
+		}
+		return nil, fsError(err, "error leyendo archivo parcial")
+	}
+	if !info.Mode().IsRegular() {
+		return &pb.UploadOffsetResponse{Offset: 0}, nil
+	}
+
+	return &pb.UploadOffsetResponse{Offset: info.Size()}, nil
+}
+
+// DownloadFile envía un archivo, o un rango de él, en fragmentos. El primer
+// mensaje lleva el tamaño total y la fecha de modificación; el último, sin
+// datos, el SHA256 de los bytes enviados.
+func (s *agentServiceImpl) DownloadFile(req *pb.DownloadFileRequest, stream pb.AgentService_DownloadFileServer) error {
+	target, err := s.resolveFile(req.ServerId, req.Path)
+	if err != nil {
+		return err
+	}
+	if req.Length < 0 {
+		return status.Errorf(codes.InvalidArgument, "length no válido: %d", req.Length)
+	}
+
+	file, err := os.Open(target.path)
+	if err != nil {
+		return fsError(err, "error abriendo %s", req.Path)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fsError(err, "error leyendo %s", req.Path)
+	}
+	if info.IsDir() {
+		return status.Errorf(codes.InvalidArgument, "%s es un directorio", target.display(target.path))
+	}
+
+	size := info.Size()
+	start := req.Offset
+	if start < 0 {
+		start = size + start
+		if start < 0 {
+			start = 0
+		}
+	}
+	if req.Offset > 0 && start >= size {
+		return status.Errorf(codes.OutOfRange, "offset %d fuera del archivo (%d bytes)", req.Offset, size)
+	}
+	end := size
+	if req.Length > 0 && start+req.Length < size {
+		end = start + req.Length
+	}
+
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return fsError(err, "error leyendo %s", req.Path)
+	}
+
+	log.Printf("[INFO] DownloadFile: enviando %s (bytes %d-%d de %d)", target.path, start, end, size)
+
+	hasher := sha256.New()
+	reader := io.TeeReader(io.LimitReader(file, end-start), hasher)
+	buffer := make([]byte, fileChunkSize)
+	offset := start
+	first := true
+	for {
+		n, err := io.ReadFull(reader, buffer)
+		if n > 0 || first {
+			chunk := &pb.FileChunk{
+				Data:   buffer[:n],
+				Offset: offset,
+			}
+			if first {
+				chunk.TotalSize = size
+				chunk.ModifiedTime = info.ModTime().Unix()
+				first = false
+			}
+			if err := stream.Send(chunk); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fsError(err, "error leyendo %s", req.Path)
+		}
+	}
+
+	return stream.Send(&pb.FileChunk{
+		Offset:   offset,
+		Checksum: hex.EncodeToString(hasher.Sum(nil)),
+	})
+}
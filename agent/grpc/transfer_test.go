@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOpenUploadPartResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.zip"+uploadSuffix)
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reanudar desde el byte 3 descarta lo recibido después
+	file, hasher, err := openUploadPart(path, 3)
+	if err != nil {
+		t.Fatalf("openUploadPart() error = %v", err)
+	}
+	file.Write([]byte("p!"))
+	hasher.Write([]byte("p!"))
+	file.Close()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "help!" {
+		t.Fatalf("contenido = %q, esperado %q", data, "help!")
+	}
+	want := sha256.Sum256([]byte("help!"))
+	if got := hex.EncodeToString(hasher.Sum(nil)); got != hex.EncodeToString(want[:]) {
+		t.Errorf("checksum = %s, esperado el del archivo completo", got)
+	}
+}
+
+func TestOpenUploadPartRejectsOffsetBeyondReceived(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.zip"+uploadSuffix)
+	if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := openUploadPart(path, 10)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("openUploadPart() error = %v, esperado FailedPrecondition", err)
+	}
+
+	if _, _, err := openUploadPart(filepath.Join(t.TempDir(), "nuevo"+uploadSuffix), 1); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("openUploadPart() sin parcial error = %v, esperado FailedPrecondition", err)
+	}
+}
@@ -0,0 +1,173 @@
+package grpc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	// tunnelHelloPrefix debe coincidir con el del backend:
+	// "AYMC-TUNNEL/1 <agent_id>\n", respuesta "OK\n" o "ERR <motivo>\n"
+	tunnelHelloPrefix = "AYMC-TUNNEL/1"
+
+	tunnelDialTimeout = 10 * time.Second
+	tunnelMinBackoff  = time.Second
+	tunnelMaxBackoff  = time.Minute
+	tunnelKeepAlive   = 30 * time.Second
+)
+
+// ServeTunnel abre un túnel hacia el backend (modo reverse) y sirve gRPC
+// sobre él. El backend actúa como cliente igual que en modo directo, así
+// que TLS y autenticación no cambian. Si el túnel se cae se vuelve a abrir
+// con backoff exponencial hasta que se cancele el contexto.
+func (s *Server) ServeTunnel(ctx context.Context, addr, agentID string) {
+	backoff := tunnelMinBackoff
+
+	for {
+		conn, err := dialTunnel(ctx, addr, agentID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("[WARN] No se pudo abrir el túnel hacia %s: %v (reintento en %v)", addr, err, backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > tunnelMaxBackoff {
+				backoff = tunnelMaxBackoff
+			}
+			continue
+		}
+
+		backoff = tunnelMinBackoff
+		log.Printf("[INFO] Túnel abierto hacia el backend %s", addr)
+
+		listener := newTunnelListener(conn)
+		stop := context.AfterFunc(ctx, func() { listener.Close() })
+		err = s.grpcServer.Serve(listener)
+		stop()
+
+		if err == grpc.ErrServerStopped || ctx.Err() != nil {
+			return
+		}
+		log.Printf("[WARN] Túnel hacia el backend cerrado, reconectando")
+	}
+}
+
+// dialTunnel conecta con el backend y completa el saludo
+func dialTunnel(ctx context.Context, addr, agentID string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: tunnelDialTimeout, KeepAlive: tunnelKeepAlive}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tunnelHandshake(conn, agentID); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// tunnelHandshake envía el saludo y espera la respuesta del backend. La
+// respuesta se lee byte a byte para no consumir el handshake TLS que sigue.
+func tunnelHandshake(conn net.Conn, agentID string) error {
+	conn.SetDeadline(time.Now().Add(tunnelDialTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if _, err := fmt.Fprintf(conn, "%s %s\n", tunnelHelloPrefix, agentID); err != nil {
+		return err
+	}
+
+	reply, err := bufio.NewReaderSize(oneByteReader{conn}, 16).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("sin respuesta del backend: %w", err)
+	}
+	reply = strings.TrimSpace(reply)
+
+	if reply == "OK" {
+		return nil
+	}
+	if reason, ok := strings.CutPrefix(reply, "ERR "); ok {
+		return fmt.Errorf("el backend rechazó el túnel: %s", reason)
+	}
+	return fmt.Errorf("respuesta inesperada del backend: %q", reply)
+}
+
+// oneByteReader limita cada lectura a un byte para que bufio no lea de más
+type oneByteReader struct {
+	conn net.Conn
+}
+
+func (r oneByteReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return r.conn.Read(p)
+}
+
+// tunnelListener entrega una única conexión a grpc.Server.Serve y se cierra
+// cuando esa conexión se cierra, para que Serve retorne
+type tunnelListener struct {
+	conn   net.Conn
+	done   chan struct{}
+	once   sync.Once
+	mu     sync.Mutex
+	served bool
+}
+
+func newTunnelListener(conn net.Conn) *tunnelListener {
+	l := &tunnelListener{done: make(chan struct{})}
+	l.conn = &tunnelConn{Conn: conn, onClose: l.markDone}
+	return l
+}
+
+func (l *tunnelListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	if !l.served {
+		l.served = true
+		l.mu.Unlock()
+		return l.conn, nil
+	}
+	l.mu.Unlock()
+
+	<-l.done
+	return nil, net.ErrClosed
+}
+
+func (l *tunnelListener) Close() error {
+	l.markDone()
+	return l.conn.Close()
+}
+
+func (l *tunnelListener) markDone() {
+	l.once.Do(func() { close(l.done) })
+}
+
+func (l *tunnelListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// tunnelConn avisa al listener cuando gRPC cierra la conexión
+type tunnelConn struct {
+	net.Conn
+	closeOnce sync.Once
+	onClose   func()
+}
+
+func (c *tunnelConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(c.onClose)
+	return err
+}
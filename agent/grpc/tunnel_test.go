@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTunnelHandshake(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		wantErr string
+	}{
+		{"accepted", "OK\n", ""},
+		{"rejected", "ERR unknown agent\n", "unknown agent"},
+		{"garbage", "HELLO\n", "inesperada"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agentSide, backendSide := net.Pipe()
+			defer agentSide.Close()
+			defer backendSide.Close()
+
+			go func() {
+				hello, _ := bufio.NewReader(backendSide).ReadString('\n')
+				if hello != tunnelHelloPrefix+" agent-1\n" {
+					backendSide.Write([]byte("ERR bad hello\n"))
+					return
+				}
+				// Lo que sigue a la respuesta pertenece al handshake TLS
+				backendSide.Write([]byte(tt.reply + "TLS"))
+			}()
+
+			err := tunnelHandshake(agentSide, "agent-1")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("tunnelHandshake() error = %v", err)
+				}
+				buf := make([]byte, 3)
+				agentSide.SetReadDeadline(time.Now().Add(time.Second))
+				if _, err := agentSide.Read(buf); err != nil || string(buf) != "TLS" {
+					t.Fatalf("handshake consumed data after reply: %q, %v", buf, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("tunnelHandshake() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTunnelListenerClosesWithConn(t *testing.T) {
+	a, b := net.Pipe()
+	defer b.Close()
+
+	listener := newTunnelListener(a)
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("first Accept() error = %v", err)
+	}
+
+	accepted := make(chan error, 1)
+	go func() {
+		_, err := listener.Accept()
+		accepted <- err
+	}()
+
+	conn.Close()
+
+	select {
+	case err := <-accepted:
+		if err != net.ErrClosed {
+			t.Fatalf("second Accept() error = %v, want net.ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Accept() did not return after the tunnel closed")
+	}
+}
@@ -162,6 +162,9 @@ create_config() {
 {
   "agent_id": "$(uuidgen 2>/dev/null || echo "agent-$(date +%s)")",
   "backend_url": "localhost:50050",
+  "enroll_url": "${AYMC_ENROLL_URL:-}",
+  "allow_insecure_enroll": ${AYMC_ALLOW_INSECURE_ENROLL:-false},
+  "enrollment_token": "${AYMC_ENROLLMENT_TOKEN:-}",
   "port": 50051,
   "log_level": "info",
   "max_servers": 10,
@@ -13,6 +13,7 @@ import (
 
 	"github.com/aymc/agent/core"
 	"github.com/aymc/agent/grpc"
+	"github.com/aymc/agent/metrics"
 	"github.com/aymc/agent/security"
 )
 
@@ -26,6 +27,7 @@ var (
 	certFile  = flag.String("cert", "", "Ruta al certificado TLS")
 	keyFile   = flag.String("key", "", "Ruta a la clave TLS")
 	configFile = flag.String("config", "/etc/aymc/agent.json", "Archivo de configuración")
+	enrollToken = flag.String("enroll", "", "Token de enrolamiento (primer arranque)")
 	debug     = flag.Bool("debug", false, "Modo debug")
 )
 
@@ -62,12 +64,26 @@ func main() {
 		log.Fatalf("[ERROR] Fallo al inicializar seguridad: %v", err)
 	}
 
+	// Token del backend; sin él solo se acepta Pair con el código mostrado
+	if err := secManager.SetTokenHash(config.AuthTokenHash); err != nil {
+		log.Fatalf("[ERROR] Fallo al configurar autenticación: %v", err)
+	}
+	secManager.SetPairingHandler(func(tokenHash string) error {
+		config.AuthTokenHash = tokenHash
+		return core.SaveConfig(*configFile, config)
+	})
+
 	// Inicializar core del agente
 	agent, err := core.NewAgent(ctx, config)
 	if err != nil {
 		log.Fatalf("[ERROR] Fallo al inicializar agente: %v", err)
 	}
 
+	// Certificado mTLS emitido por la CA del backend al emparejar
+	if err := secManager.LoadInstalledCertificates(config.TLSDir); err != nil {
+		log.Fatalf("[ERROR] Fallo al cargar certificados del backend: %v", err)
+	}
+
 	// Iniciar servidor gRPC
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
 	if err != nil {
@@ -85,9 +101,37 @@ func main() {
 		}
 	}()
 
+	// Primer arranque: canjear el token de enrolamiento. El servidor gRPC ya
+	// escucha para que el backend pueda conectar en cuanto termine.
+	if *enrollToken != "" {
+		config.EnrollmentToken = *enrollToken
+	}
+	if config.EnrollmentToken != "" && !secManager.IsPaired() {
+		enrollAgent(ctx, agent, secManager)
+	}
+
+	// Modo reverse: el agente abre el túnel hacia el backend (NAT, firewalls)
+	if config.TunnelAddress != "" {
+		log.Printf("[INFO] Modo reverse: abriendo túnel hacia %s", config.TunnelAddress)
+		go grpcServer.ServeTunnel(ctx, config.TunnelAddress, config.AgentID)
+	}
+
 	// Iniciar monitoreo de sistema
 	go agent.StartMonitoring(ctx, 5*time.Second)
 
+	// Jugadores, TPS y MSPT de los servidores en ejecución
+	go agent.StartGameStatsCollector(ctx, config.GameStatsInterval)
+
+	// Exportador Prometheus opcional
+	if config.MetricsAddr != "" {
+		exporter := metrics.NewExporter(agent, Version)
+		go func() {
+			if err := exporter.Serve(ctx, config.MetricsAddr); err != nil {
+				log.Printf("[ERROR] Error en servidor de métricas: %v", err)
+			}
+		}()
+	}
+
 	// Manejar señales de sistema
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -104,6 +148,43 @@ func main() {
 	log.Printf("[INFO] Agente detenido correctamente")
 }
 
+// enrollAgent canjea el token de enrolamiento y guarda la identidad y el
+// hash del token gRPC asignados por el backend
+func enrollAgent(ctx context.Context, agent *core.Agent, secManager *security.SecurityManager) {
+	config := agent.GetConfig()
+	hostInfo := agent.GetMonitor().GetHostInfo()
+
+	connectionMode := ""
+	if config.TunnelAddress != "" {
+		connectionMode = "reverse"
+	}
+
+	resp, err := secManager.Enroll(ctx, config.EnrollURL, config.AllowInsecureEnroll, security.EnrollmentRequest{
+		Token:          config.EnrollmentToken,
+		AgentID:        config.AgentID,
+		Hostname:       hostInfo.Hostname,
+		Port:           *port,
+		OS:             fmt.Sprintf("%s (%s)", hostInfo.Platform, hostInfo.PlatformVersion),
+		Version:        Version,
+		CPUCores:       hostInfo.CPUCores,
+		MemoryTotal:    hostInfo.MemoryTotal,
+		DiskTotal:      hostInfo.DiskTotal,
+		ConnectionMode: connectionMode,
+	})
+	if err != nil {
+		log.Printf("[ERROR] Enrolamiento fallido: %v", err)
+		log.Printf("[INFO] El agente puede emparejarse manualmente con el código de emparejamiento")
+		return
+	}
+
+	config.AgentID = resp.AgentID
+	config.AuthTokenHash = security.HashToken(resp.AuthToken)
+	config.EnrollmentToken = ""
+	if err := core.SaveConfig(*configFile, config); err != nil {
+		log.Printf("[ERROR] No se pudo guardar la configuración tras enrolar: %v", err)
+	}
+}
+
 func printBanner() {
 	banner := `
 ╔═══════════════════════════════════════════════════╗
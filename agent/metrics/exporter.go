@@ -0,0 +1,231 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aymc/agent/core"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// namespace prefijo de las métricas exportadas por el agente
+	namespace = "aymc"
+	subsystem = "agent"
+
+	// shutdownTimeout tiempo máximo para cerrar el listener HTTP
+	shutdownTimeout = 5 * time.Second
+)
+
+var serverLabels = []string{"server_id", "name", "type"}
+
+// desc crea la descripción de una métrica del exportador
+func desc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, labels, nil)
+}
+
+// Exporter expone en formato Prometheus las métricas del host y de los
+// servidores del agente. Las métricas del host son las últimas publicadas
+// por el monitoreo de sistema; las de servidores se miden en cada scrape.
+type Exporter struct {
+	agent    *core.Agent
+	version  string
+	registry *prometheus.Registry
+
+	mu     sync.RWMutex
+	system *core.SystemMetrics
+
+	info          *prometheus.Desc
+	uptime        *prometheus.Desc
+	cpu           *prometheus.Desc
+	memoryUsed    *prometheus.Desc
+	memoryTotal   *prometheus.Desc
+	diskUsed      *prometheus.Desc
+	diskTotal     *prometheus.Desc
+	servers       *prometheus.Desc
+	serverUp      *prometheus.Desc
+	serverCPU     *prometheus.Desc
+	serverMemory  *prometheus.Desc
+	serverThreads *prometheus.Desc
+	serverDisk    *prometheus.Desc
+	serverPlayers *prometheus.Desc
+	serverTPS     *prometheus.Desc
+	serverMSPT    *prometheus.Desc
+	serverRestart *prometheus.Desc
+}
+
+// NewExporter crea el exportador con un registro propio que incluye además
+// las métricas del runtime de Go y del proceso del agente
+func NewExporter(agent *core.Agent, version string) *Exporter {
+	e := &Exporter{
+		agent:    agent,
+		version:  version,
+		registry: prometheus.NewRegistry(),
+
+		info:          desc("info", "Agent identity and version, always 1.", "agent_id", "version"),
+		uptime:        desc("uptime_seconds", "Time since the agent was started."),
+		cpu:           desc("cpu_percent", "Host CPU usage."),
+		memoryUsed:    desc("memory_used_bytes", "Host memory in use."),
+		memoryTotal:   desc("memory_total_bytes", "Host memory."),
+		diskUsed:      desc("disk_used_bytes", "Disk in use on the root volume."),
+		diskTotal:     desc("disk_total_bytes", "Size of the root volume."),
+		servers:       desc("servers", "Servers managed by the agent by status.", "status"),
+		serverUp:      desc("server_up", "Whether the server is running.", serverLabels...),
+		serverCPU:     desc("server_cpu_percent", "Server process CPU usage, 100 = one core.", serverLabels...),
+		serverMemory:  desc("server_memory_rss_bytes", "Server process resident memory.", serverLabels...),
+		serverThreads: desc("server_threads", "Threads of the server process and its children.", serverLabels...),
+		serverDisk:    desc("server_disk_used_bytes", "Size of the server directory.", serverLabels...),
+		serverPlayers: desc("server_players_online", "Players connected to the server.", serverLabels...),
+		serverTPS:     desc("server_tps", "Ticks per second, last minute average (Paper and Purpur).", serverLabels...),
+		serverMSPT:    desc("server_mspt", "Milliseconds per tick, last minute average (Paper and Purpur).", serverLabels...),
+		serverRestart: desc("server_restarts", "Automatic restarts within the current restart window.", serverLabels...),
+	}
+
+	e.registry.MustRegister(
+		e,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return e
+}
+
+// Run guarda las métricas de sistema que publica el monitoreo hasta que se
+// cancele ctx
+func (e *Exporter) Run(ctx context.Context) {
+	id, ch := e.agent.Metrics().Subscribe(1)
+	defer e.agent.Metrics().Unsubscribe(id)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case metrics, ok := <-ch:
+			if !ok {
+				return
+			}
+			e.mu.Lock()
+			e.system = metrics
+			e.mu.Unlock()
+		}
+	}
+}
+
+// Handler sirve las métricas en formato Prometheus u OpenMetrics según la
+// cabecera Accept del scraper
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	})
+}
+
+// Serve escucha en addr y sirve /metrics hasta que se cancele ctx
+func (e *Exporter) Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e.Handler())
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go e.Run(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("[INFO] Métricas Prometheus en http://%s/metrics", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Describe implementa prometheus.Collector
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		e.info, e.uptime, e.cpu, e.memoryUsed, e.memoryTotal, e.diskUsed, e.diskTotal,
+		e.servers, e.serverUp, e.serverCPU, e.serverMemory, e.serverThreads, e.serverDisk,
+		e.serverPlayers, e.serverTPS, e.serverMSPT, e.serverRestart,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implementa prometheus.Collector
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	gauge := func(desc *prometheus.Desc, value float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+	}
+
+	gauge(e.info, 1, e.agent.GetConfig().AgentID, e.version)
+	gauge(e.uptime, time.Since(e.agent.GetStartTime()).Seconds())
+
+	// Sin métricas de host hasta la primera muestra del monitoreo
+	e.mu.RLock()
+	system := e.system
+	e.mu.RUnlock()
+	if system != nil {
+		gauge(e.cpu, system.CPUPercent)
+		gauge(e.memoryUsed, float64(system.MemoryUsed))
+		gauge(e.memoryTotal, float64(system.MemoryTotal))
+		gauge(e.diskUsed, float64(system.DiskUsed))
+		gauge(e.diskTotal, float64(system.DiskTotal))
+	}
+
+	e.collectServers(gauge)
+}
+
+// collectServers exporta el estado de cada servidor y, de los que están en
+// ejecución, las métricas de su proceso y las estadísticas de juego
+func (e *Exporter) collectServers(gauge func(desc *prometheus.Desc, value float64, labels ...string)) {
+	byStatus := make(map[core.ServerStatus]int)
+	for _, server := range e.agent.ListServers() {
+		byStatus[server.Status]++
+		labels := []string{server.ID, server.Name, server.Type}
+
+		up := 0.0
+		if server.Status == core.StatusRunning {
+			up = 1
+		}
+		gauge(e.serverUp, up, labels...)
+		gauge(e.serverRestart, float64(server.RestartCount), labels...)
+
+		if server.Status != core.StatusRunning {
+			continue
+		}
+
+		if metrics, err := e.agent.GetServerMetrics(server.ID); err == nil {
+			gauge(e.serverCPU, metrics.CPUPercent, labels...)
+			gauge(e.serverMemory, float64(metrics.MemoryRSS), labels...)
+			gauge(e.serverThreads, float64(metrics.Threads), labels...)
+			gauge(e.serverDisk, float64(metrics.DiskUsed), labels...)
+		}
+
+		if stats := e.agent.GetGameStats(server.ID); stats != nil {
+			gauge(e.serverPlayers, float64(stats.PlayersOnline), labels...)
+			// 0 si el software no tiene los comandos tps/mspt
+			if stats.TPS > 0 {
+				gauge(e.serverTPS, stats.TPS, labels...)
+			}
+			if stats.MSPT > 0 {
+				gauge(e.serverMSPT, stats.MSPT, labels...)
+			}
+		}
+	}
+
+	for _, status := range []core.ServerStatus{
+		core.StatusStopped, core.StatusStarting, core.StatusRunning, core.StatusStopping, core.StatusCrashed,
+	} {
+		gauge(e.servers, float64(byStatus[status]), string(status))
+	}
+}
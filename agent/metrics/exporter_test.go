@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aymc/agent/core"
+)
+
+func TestExporterHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	agent, err := core.NewAgent(ctx, &core.Config{AgentID: "agent-1", WorkDir: t.TempDir(), MaxServers: 5})
+	if err != nil {
+		t.Fatalf("Error creando agente: %v", err)
+	}
+
+	exporter := NewExporter(agent, "0.1.0")
+	go exporter.Run(ctx)
+
+	// El suscriptor puede no estar registrado todavía: publicar hasta recibir
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		agent.Metrics().Publish(&core.SystemMetrics{CPUPercent: 12.5, MemoryTotal: 1024})
+		exporter.mu.RLock()
+		received := exporter.system != nil
+		exporter.mu.RUnlock()
+		if received {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("El exportador no recibió las métricas de sistema")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	recorder := httptest.NewRecorder()
+	exporter.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+
+	for _, want := range []string{
+		`aymc_agent_info{agent_id="agent-1",version="0.1.0"} 1`,
+		`aymc_agent_cpu_percent 12.5`,
+		`aymc_agent_memory_total_bytes 1024`,
+		`aymc_agent_servers{status="running"} 0`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Falta %q en la respuesta:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// AuthMetadataKey clave de metadata con el token ("Bearer <token>")
+	AuthMetadataKey = "authorization"
+
+	// PairMethod método gRPC que se permite sin token mientras el agente
+	// no está emparejado
+	PairMethod = "/agent.AgentService/Pair"
+
+	// maxPairingAttempts intentos fallidos antes de regenerar el código
+	maxPairingAttempts = 5
+
+	// pairingCodeAlphabet caracteres del código (sin 0/O ni 1/I)
+	pairingCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// PairingHandler se llama al emparejar o rotar el token con el hash
+// nuevo, para que el agente lo persista en su configuración
+type PairingHandler func(tokenHash string) error
+
+// HashToken retorna el hash (SHA256 en hex) con el que se guarda un token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenState estado de autenticación del SecurityManager
+type tokenState struct {
+	mu              sync.RWMutex
+	tokenHash       []byte // nil si el agente no está emparejado
+	pairingCode     string
+	pairingAttempts int
+	onPaired        PairingHandler
+}
+
+// SetTokenHash configura el hash del token aceptado. Un hash vacío deja el
+// agente sin emparejar y genera un código de emparejamiento.
+func (sm *SecurityManager) SetTokenHash(tokenHash string) error {
+	sm.auth.mu.Lock()
+	defer sm.auth.mu.Unlock()
+
+	if tokenHash == "" {
+		sm.auth.tokenHash = nil
+		return sm.resetPairingCodeLocked()
+	}
+
+	decoded, err := hex.DecodeString(tokenHash)
+	if err != nil || len(decoded) != sha256.Size {
+		return fmt.Errorf("hash de token inválido: se espera SHA256 en hexadecimal")
+	}
+
+	sm.auth.tokenHash = decoded
+	sm.auth.pairingCode = ""
+	return nil
+}
+
+// SetPairingHandler configura el callback que persiste el hash nuevo
+func (sm *SecurityManager) SetPairingHandler(handler PairingHandler) {
+	sm.auth.mu.Lock()
+	defer sm.auth.mu.Unlock()
+	sm.auth.onPaired = handler
+}
+
+// IsPaired indica si el agente tiene un token configurado
+func (sm *SecurityManager) IsPaired() bool {
+	sm.auth.mu.RLock()
+	defer sm.auth.mu.RUnlock()
+	return sm.auth.tokenHash != nil
+}
+
+// PairingCode retorna el código de emparejamiento vigente ("" si el
+// agente ya está emparejado)
+func (sm *SecurityManager) PairingCode() string {
+	sm.auth.mu.RLock()
+	defer sm.auth.mu.RUnlock()
+	return sm.auth.pairingCode
+}
+
+// ValidateToken compara el token en tiempo constante contra el hash
+// configurado. Sin hash configurado ningún token es válido.
+func (sm *SecurityManager) ValidateToken(token string) bool {
+	sm.auth.mu.RLock()
+	defer sm.auth.mu.RUnlock()
+	return sm.validateTokenLocked(token)
+}
+
+// validateTokenLocked compara el token contra el hash configurado.
+// Requiere sm.auth.mu tomado.
+func (sm *SecurityManager) validateTokenLocked(token string) bool {
+	if sm.auth.tokenHash == nil || token == "" {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	return subtle.ConstantTimeCompare(sum[:], sm.auth.tokenHash) == 1
+}
+
+// Pair instala el token provisto por el backend. Si el agente no está
+// emparejado se exige el código de emparejamiento; si ya lo está, el
+// token actual (rotación). La comprobación y el cambio de token se hacen
+// bajo el mismo lock para que dos emparejamientos simultáneos no puedan
+// usar el mismo código ni pisarse el token.
+func (sm *SecurityManager) Pair(pairingCode, currentToken, newToken string) error {
+	if len(newToken) < 32 {
+		return fmt.Errorf("el token debe tener al menos 32 caracteres")
+	}
+
+	sm.auth.mu.Lock()
+	defer sm.auth.mu.Unlock()
+
+	if sm.auth.tokenHash != nil {
+		if !sm.validateTokenLocked(currentToken) {
+			return fmt.Errorf("token actual inválido")
+		}
+	} else if err := sm.checkPairingCodeLocked(pairingCode); err != nil {
+		return err
+	}
+
+	hash := HashToken(newToken)
+
+	if sm.auth.onPaired != nil {
+		if err := sm.auth.onPaired(hash); err != nil {
+			return fmt.Errorf("error guardando token: %w", err)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(newToken))
+	sm.auth.tokenHash = sum[:]
+	sm.auth.pairingCode = ""
+	sm.auth.pairingAttempts = 0
+
+	log.Printf("[INFO] Agente emparejado con el backend")
+	return nil
+}
+
+// checkPairingCodeLocked valida el código de emparejamiento. Tras varios
+// intentos fallidos se genera un código nuevo. Requiere sm.auth.mu tomado.
+func (sm *SecurityManager) checkPairingCodeLocked(code string) error {
+	expected := sm.auth.pairingCode
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if expected != "" && subtle.ConstantTimeCompare([]byte(normalized), []byte(strings.ReplaceAll(expected, "-", ""))) == 1 {
+		return nil
+	}
+
+	sm.auth.pairingAttempts++
+	if sm.auth.pairingAttempts >= maxPairingAttempts {
+		log.Printf("[WARN] Demasiados intentos de emparejamiento fallidos, generando código nuevo")
+		if err := sm.resetPairingCodeLocked(); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("código de emparejamiento inválido")
+}
+
+// resetPairingCodeLocked genera un código nuevo y lo muestra en el log.
+// Requiere sm.auth.mu tomado.
+func (sm *SecurityManager) resetPairingCodeLocked() error {
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("error generando código de emparejamiento: %w", err)
+	}
+
+	code := make([]byte, 0, 9)
+	for i, b := range raw {
+		if i == 4 {
+			code = append(code, '-')
+		}
+		code = append(code, pairingCodeAlphabet[int(b)%len(pairingCodeAlphabet)])
+	}
+
+	sm.auth.pairingCode = string(code)
+	sm.auth.pairingAttempts = 0
+
+	log.Printf("[WARN] Agente sin emparejar. Código de emparejamiento: %s", sm.auth.pairingCode)
+	return nil
+}
+
+// authorize verifica el token de la metadata de una llamada
+func (sm *SecurityManager) authorize(ctx context.Context, method string) error {
+	// Pair valida su propio código o token
+	if method == PairMethod {
+		return nil
+	}
+
+	if !sm.ValidateToken(TokenFromContext(ctx)) {
+		return status.Error(codes.Unauthenticated, "token de agente inválido o ausente")
+	}
+	return nil
+}
+
+// TokenFromContext extrae el token de la metadata entrante
+func TokenFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(AuthMetadataKey)
+	if len(values) == 0 {
+		return ""
+	}
+
+	token := strings.TrimSpace(values[0])
+	if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	return token
+}
+
+// UnaryInterceptor exige un token válido en las llamadas unarias
+func (sm *SecurityManager) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := sm.authorize(ctx, info.FullMethod); err != nil {
+			log.Printf("[WARN] Llamada rechazada: %s", info.FullMethod)
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor exige un token válido en las llamadas con streams
+func (sm *SecurityManager) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := sm.authorize(ss.Context(), info.FullMethod); err != nil {
+			log.Printf("[WARN] Stream rechazado: %s", info.FullMethod)
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,183 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testToken = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func newUnpairedManager(t *testing.T) *SecurityManager {
+	t.Helper()
+	sm, err := NewSecurityManager("", "")
+	if err != nil {
+		t.Fatalf("Error creando SecurityManager: %v", err)
+	}
+	if err := sm.SetTokenHash(""); err != nil {
+		t.Fatalf("Error configurando hash: %v", err)
+	}
+	return sm
+}
+
+func contextWithToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthMetadataKey, "Bearer "+token))
+}
+
+func TestUnaryInterceptorRejectsMissingToken(t *testing.T) {
+	sm := newUnpairedManager(t)
+	if err := sm.SetTokenHash(HashToken(testToken)); err != nil {
+		t.Fatal(err)
+	}
+
+	interceptor := sm.UnaryInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/agent.AgentService/ListServers"}
+
+	_, err := interceptor(context.Background(), nil, info, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Se esperaba Unauthenticated sin token, obtenido %v", err)
+	}
+
+	_, err = interceptor(contextWithToken("otro-token"), nil, info, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Se esperaba Unauthenticated con token incorrecto, obtenido %v", err)
+	}
+
+	resp, err := interceptor(contextWithToken(testToken), nil, info, handler)
+	if err != nil || resp != "ok" {
+		t.Errorf("Token valido fue rechazado: %v", err)
+	}
+
+	// Pair se permite sin token
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: PairMethod}, handler)
+	if err != nil {
+		t.Errorf("Pair no deberia requerir token: %v", err)
+	}
+}
+
+func TestPairWithCode(t *testing.T) {
+	sm := newUnpairedManager(t)
+
+	code := sm.PairingCode()
+	if len(code) != 9 {
+		t.Fatalf("Codigo de emparejamiento inesperado: %q", code)
+	}
+
+	var saved string
+	sm.SetPairingHandler(func(hash string) error {
+		saved = hash
+		return nil
+	})
+
+	if err := sm.Pair("XXXX-XXXX", "", testToken); err == nil {
+		t.Error("Un codigo incorrecto fue aceptado")
+	}
+	if err := sm.Pair(code, "", "corto"); err == nil {
+		t.Error("Un token corto fue aceptado")
+	}
+	if err := sm.Pair(strings.ToLower(code), "", testToken); err != nil {
+		t.Fatalf("Error emparejando: %v", err)
+	}
+	if saved != HashToken(testToken) {
+		t.Error("El hash del token no fue persistido")
+	}
+	if !sm.IsPaired() || !sm.ValidateToken(testToken) {
+		t.Error("El token emparejado deberia ser valido")
+	}
+	if sm.PairingCode() != "" {
+		t.Error("El codigo deberia invalidarse tras emparejar")
+	}
+}
+
+func TestPairRotation(t *testing.T) {
+	sm := newUnpairedManager(t)
+	if err := sm.SetTokenHash(HashToken(testToken)); err != nil {
+		t.Fatal(err)
+	}
+
+	newToken := strings.Repeat("f", 64)
+	if err := sm.Pair("", "incorrecto", newToken); err == nil {
+		t.Error("La rotacion sin el token actual fue aceptada")
+	}
+	if err := sm.Pair("", testToken, newToken); err != nil {
+		t.Fatalf("Error rotando token: %v", err)
+	}
+	if sm.ValidateToken(testToken) {
+		t.Error("El token anterior deberia invalidarse")
+	}
+	if !sm.ValidateToken(newToken) {
+		t.Error("El token nuevo deberia ser valido")
+	}
+}
+
+func TestPairingCodeRegeneratedAfterFailures(t *testing.T) {
+	sm := newUnpairedManager(t)
+	code := sm.PairingCode()
+
+	for i := 0; i < maxPairingAttempts; i++ {
+		sm.Pair("XXXX-XXXX", "", testToken)
+	}
+
+	if sm.PairingCode() == code {
+		t.Error("El codigo deberia regenerarse tras demasiados intentos fallidos")
+	}
+	if err := sm.Pair(code, "", testToken); err == nil {
+		t.Error("El codigo anterior no deberia seguir siendo valido")
+	}
+}
+
+func TestConcurrentPairUsesCodeOnce(t *testing.T) {
+	sm := newUnpairedManager(t)
+	code := sm.PairingCode()
+
+	var saved sync.Map
+	sm.SetPairingHandler(func(hash string) error {
+		saved.Store(hash, true)
+		return nil
+	})
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	results := make([]error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = sm.Pair(code, "", strings.Repeat(string(rune('a'+i)), 64))
+		}(i)
+	}
+	wg.Wait()
+
+	winner := -1
+	for i, err := range results {
+		if err != nil {
+			continue
+		}
+		if winner != -1 {
+			t.Fatalf("El codigo fue aceptado dos veces (%d y %d)", winner, i)
+		}
+		winner = i
+	}
+	if winner == -1 {
+		t.Fatal("Ningun emparejamiento tuvo exito")
+	}
+
+	token := strings.Repeat(string(rune('a'+winner)), 64)
+	if !sm.ValidateToken(token) {
+		t.Error("El token del emparejamiento ganador deberia ser valido")
+	}
+	count := 0
+	saved.Range(func(_, _ any) bool { count++; return true })
+	if count != 1 {
+		t.Errorf("Solo deberia persistirse un token, se persistieron %d", count)
+	}
+}
@@ -0,0 +1,229 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// Archivos del certificado emitido por la CA del backend
+	agentCertFileName = "agent.pem"
+	agentKeyFileName  = "agent-key.pem"
+	caCertFileName    = "ca.pem"
+)
+
+// certState certificado instalado por el backend. Mientras no haya uno
+// el agente sirve su certificado base y no exige certificado cliente.
+type certState struct {
+	mu         sync.RWMutex
+	dir        string
+	cert       *tls.Certificate
+	clientCAs  *x509.CertPool
+	pendingKey *ecdsa.PrivateKey // clave del último CSR generado
+}
+
+// CertificateFingerprint retorna el SHA256 (hex) de un certificado DER
+func CertificateFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadInstalledCertificates configura el directorio donde se guardan los
+// certificados del backend y los carga si existen. Con certificados
+// instalados el agente exige certificado cliente firmado por la CA.
+func (sm *SecurityManager) LoadInstalledCertificates(dir string) error {
+	sm.certs.mu.Lock()
+	defer sm.certs.mu.Unlock()
+
+	sm.certs.dir = dir
+
+	certPEM, err := os.ReadFile(filepath.Join(dir, agentCertFileName))
+	if os.IsNotExist(err) {
+		log.Printf("[INFO] Sin certificado del backend, usando certificado local hasta el emparejamiento")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	keyPEM, err := os.ReadFile(filepath.Join(dir, agentKeyFileName))
+	if err != nil {
+		return err
+	}
+	caPEM, err := os.ReadFile(filepath.Join(dir, caCertFileName))
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("error cargando certificado instalado: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("certificado de CA inválido en %s", caCertFileName)
+	}
+
+	sm.certs.cert = &cert
+	sm.certs.clientCAs = pool
+
+	log.Printf("[INFO] mTLS habilitado con certificado %s", CertificateFingerprint(cert.Certificate[0]))
+	return nil
+}
+
+// MutualTLSEnabled indica si hay un certificado del backend instalado
+func (sm *SecurityManager) MutualTLSEnabled() bool {
+	sm.certs.mu.RLock()
+	defer sm.certs.mu.RUnlock()
+	return sm.certs.cert != nil
+}
+
+// CreateCertificateRequest genera una clave nueva y retorna un CSR (PEM)
+// para que la CA del backend lo firme. La clave queda pendiente hasta
+// InstallCertificate.
+func (sm *SecurityManager) CreateCertificateRequest(commonName string, dnsNames []string) ([]byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error generando clave: %w", err)
+	}
+
+	template := x509.CertificateRequest{
+		Subject: pkix.Name{
+			Organization: []string{"AYMC Agent"},
+			CommonName:   commonName,
+		},
+		DNSNames: dnsNames,
+	}
+
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &template, key)
+	if err != nil {
+		return nil, fmt.Errorf("error creando CSR: %w", err)
+	}
+
+	sm.certs.mu.Lock()
+	sm.certs.pendingKey = key
+	sm.certs.mu.Unlock()
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER}), nil
+}
+
+// InstallCertificate instala el certificado firmado para el último CSR y
+// la CA del backend. Las conexiones nuevas usan el certificado y exigen
+// certificado cliente. Retorna la huella del certificado instalado.
+func (sm *SecurityManager) InstallCertificate(certPEM, caPEM []byte) (string, error) {
+	sm.certs.mu.Lock()
+	defer sm.certs.mu.Unlock()
+
+	if sm.certs.pendingKey == nil {
+		return "", fmt.Errorf("no hay una solicitud de certificado pendiente")
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", fmt.Errorf("certificado inválido")
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("certificado inválido: %w", err)
+	}
+
+	pub, ok := leaf.PublicKey.(*ecdsa.PublicKey)
+	if !ok || !pub.Equal(&sm.certs.pendingKey.PublicKey) {
+		return "", fmt.Errorf("el certificado no corresponde a la solicitud pendiente")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return "", fmt.Errorf("certificado de CA inválido")
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:     pool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}); err != nil {
+		return "", fmt.Errorf("el certificado no está firmado por la CA: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(sm.certs.pendingKey)
+	if err != nil {
+		return "", err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return "", fmt.Errorf("error cargando par de claves: %w", err)
+	}
+
+	if sm.certs.dir != "" {
+		if err := writeCertificateFiles(sm.certs.dir, certPEM, keyPEM, caPEM); err != nil {
+			return "", err
+		}
+	}
+
+	sm.certs.cert = &cert
+	sm.certs.clientCAs = pool
+	sm.certs.pendingKey = nil
+
+	fingerprint := CertificateFingerprint(leaf.Raw)
+	log.Printf("[INFO] Certificado instalado (%s, expira %s)", fingerprint, leaf.NotAfter.Format(time.RFC3339))
+	return fingerprint, nil
+}
+
+// writeCertificateFiles guarda certificado, clave y CA de forma atómica
+func writeCertificateFiles(dir string, certPEM, keyPEM, caPEM []byte) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{agentKeyFileName, keyPEM},
+		{caCertFileName, caPEM},
+		{agentCertFileName, certPEM}, // el último: su presencia indica un conjunto completo
+	}
+
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path+".tmp", f.data, 0600); err != nil {
+			return err
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// configForClient selecciona el certificado en cada handshake para que un
+// certificado recién instalado se use sin reiniciar el servidor gRPC
+func (sm *SecurityManager) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	sm.certs.mu.RLock()
+	defer sm.certs.mu.RUnlock()
+
+	if sm.certs.cert == nil {
+		return nil, nil
+	}
+
+	config := sm.tlsConfig.Clone()
+	config.GetConfigForClient = nil
+	config.Certificates = []tls.Certificate{*sm.certs.cert}
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	config.ClientCAs = sm.certs.clientCAs
+	return config, nil
+}
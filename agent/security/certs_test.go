@@ -0,0 +1,202 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// testCA CA mínima que emula la del backend
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca *testCA) sign(t *testing.T, pub interface{}, usage x509.ExtKeyUsage, serial int64) ([]byte, []byte) {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, pub, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// clientCert certificado cliente firmado por la CA, como el del backend
+func (ca *testCA) clientCert(t *testing.T) tls.Certificate {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := ca.sign(t, &key.PublicKey, x509.ExtKeyUsageClientAuth, 3)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// issueAgentCert firma el CSR del agente
+func (ca *testCA) issueAgentCert(t *testing.T, csrPEM []byte) []byte {
+	t.Helper()
+	block, _ := pem.Decode(csrPEM)
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("CSR invalido: %v", err)
+	}
+	_, certPEM := ca.sign(t, csr.PublicKey, x509.ExtKeyUsageServerAuth, 2)
+	return certPEM
+}
+
+// handshake conecta un cliente TLS al agente y retorna la huella del
+// certificado presentado por el agente
+func handshake(t *testing.T, sm *SecurityManager, clientCerts []tls.Certificate) (string, error) {
+	t.Helper()
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+
+	go func() {
+		server := tls.Server(serverConn, sm.GetTLSConfig())
+		server.Handshake()
+		// Leer para que el cliente reciba el resultado de la verificación
+		server.Read(make([]byte, 1))
+		server.Close()
+	}()
+
+	client := tls.Client(clientConn, &tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       clientCerts,
+		MinVersion:         tls.VersionTLS13,
+	})
+	if err := client.Handshake(); err != nil {
+		return "", err
+	}
+	// En TLS 1.3 el rechazo del certificado cliente llega tras el handshake
+	client.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := client.Write([]byte{0}); err != nil {
+		return "", err
+	}
+	if _, err := client.Read(make([]byte, 1)); err != nil && err.Error() != "EOF" {
+		return "", err
+	}
+	return CertificateFingerprint(client.ConnectionState().PeerCertificates[0].Raw), nil
+}
+
+func TestInstallCertificateEnablesMutualTLS(t *testing.T) {
+	sm, err := NewSecurityManager("", "")
+	if err != nil {
+		t.Fatalf("Error creando SecurityManager: %v", err)
+	}
+	dir := t.TempDir()
+	if err := sm.LoadInstalledCertificates(dir); err != nil {
+		t.Fatal(err)
+	}
+	if sm.MutualTLSEnabled() {
+		t.Fatal("Sin certificado instalado no deberia exigirse mTLS")
+	}
+
+	// Antes del emparejamiento cualquier cliente puede conectar
+	if _, err := handshake(t, sm, nil); err != nil {
+		t.Fatalf("Handshake sin mTLS fallo: %v", err)
+	}
+
+	ca := newTestCA(t)
+	csr, err := sm.CreateCertificateRequest("agent-1", []string{"host"})
+	if err != nil {
+		t.Fatalf("Error creando CSR: %v", err)
+	}
+	certPEM := ca.issueAgentCert(t, csr)
+
+	fingerprint, err := sm.InstallCertificate(certPEM, ca.certPEM)
+	if err != nil {
+		t.Fatalf("Error instalando certificado: %v", err)
+	}
+	if !sm.MutualTLSEnabled() {
+		t.Fatal("mTLS deberia estar habilitado")
+	}
+
+	// Sin certificado cliente la conexión se rechaza
+	if _, err := handshake(t, sm, nil); err == nil {
+		t.Error("Se esperaba rechazo sin certificado cliente")
+	}
+
+	// Con el certificado del backend se presenta el certificado instalado
+	presented, err := handshake(t, sm, []tls.Certificate{ca.clientCert(t)})
+	if err != nil {
+		t.Fatalf("Handshake mTLS fallo: %v", err)
+	}
+	if presented != fingerprint {
+		t.Errorf("Certificado presentado %s, esperado %s", presented, fingerprint)
+	}
+
+	// Un agente reiniciado carga el certificado desde disco
+	reloaded, _ := NewSecurityManager("", "")
+	if err := reloaded.LoadInstalledCertificates(dir); err != nil {
+		t.Fatalf("Error recargando certificados: %v", err)
+	}
+	if !reloaded.MutualTLSEnabled() {
+		t.Error("El certificado guardado no fue cargado")
+	}
+}
+
+func TestInstallCertificateRejectsForeignKey(t *testing.T) {
+	sm, err := NewSecurityManager("", "")
+	if err != nil {
+		t.Fatalf("Error creando SecurityManager: %v", err)
+	}
+	ca := newTestCA(t)
+
+	// Sin CSR pendiente
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, foreign := ca.sign(t, &key.PublicKey, x509.ExtKeyUsageServerAuth, 4)
+	if _, err := sm.InstallCertificate(foreign, ca.certPEM); err == nil {
+		t.Error("Se acepto un certificado sin solicitud pendiente")
+	}
+
+	// Certificado para otra clave
+	if _, err := sm.CreateCertificateRequest("agent-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm.InstallCertificate(foreign, ca.certPEM); err == nil {
+		t.Error("Se acepto un certificado de otra clave")
+	}
+
+	// Certificado firmado por otra CA
+	csr, _ := sm.CreateCertificateRequest("agent-1", nil)
+	other := newTestCA(t)
+	if _, err := sm.InstallCertificate(other.issueAgentCert(t, csr), ca.certPEM); err == nil {
+		t.Error("Se acepto un certificado de otra CA")
+	}
+}
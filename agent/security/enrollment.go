@@ -0,0 +1,141 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// enrollPath ruta de la API del backend para canjear tokens
+const enrollPath = "/api/v1/agents/enroll"
+
+// EnrollmentRequest datos del agente enviados al canjear un token de
+// enrolamiento
+type EnrollmentRequest struct {
+	Token          string `json:"token"`
+	AgentID        string `json:"agent_id"`
+	Hostname       string `json:"hostname"`
+	IPAddress      string `json:"ip_address,omitempty"` // Vacío: el backend usa la IP de origen
+	Port           int    `json:"port"`
+	OS             string `json:"os"`
+	Version        string `json:"version"`
+	CPUCores       int    `json:"cpu_cores"`
+	MemoryTotal    uint64 `json:"memory_total"`
+	DiskTotal      uint64 `json:"disk_total"`
+	ConnectionMode string `json:"connection_mode,omitempty"` // "reverse" si el agente abre el túnel
+	CSR            string `json:"csr"`
+}
+
+// EnrollmentResponse identidad y credenciales asignadas por el backend
+type EnrollmentResponse struct {
+	ID            string `json:"id"`
+	AgentID       string `json:"agent_id"`
+	AuthToken     string `json:"auth_token"`
+	Certificate   string `json:"certificate"`
+	CACertificate string `json:"ca_certificate"`
+}
+
+// Enroll canjea un token de enrolamiento en el backend. Genera el CSR,
+// instala el certificado recibido y configura el token gRPC. El llamador
+// debe persistir AgentID y el hash del token en la configuración.
+// allowInsecure permite una apiURL con http:// (allow_insecure_enroll).
+func (sm *SecurityManager) Enroll(ctx context.Context, apiURL string, allowInsecure bool, req EnrollmentRequest) (*EnrollmentResponse, error) {
+	endpoint, err := enrollmentURL(apiURL, allowInsecure)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(endpoint, "http://") {
+		log.Printf("[WARN] Enrolando sin HTTPS: las credenciales viajan en claro hasta %s", endpoint)
+	}
+
+	csr, err := sm.CreateCertificateRequest(req.AgentID, []string{req.Hostname})
+	if err != nil {
+		return nil, err
+	}
+	req.CSR = string(csr)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error contactando al backend: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(data, &apiErr)
+		if apiErr.Error == "" {
+			apiErr.Error = resp.Status
+		}
+		return nil, fmt.Errorf("el backend rechazó el enrolamiento: %s", apiErr.Error)
+	}
+
+	var result EnrollmentResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("respuesta de enrolamiento inválida: %w", err)
+	}
+	if len(result.AuthToken) < 32 {
+		return nil, fmt.Errorf("respuesta de enrolamiento sin token válido")
+	}
+
+	if _, err := sm.InstallCertificate([]byte(result.Certificate), []byte(result.CACertificate)); err != nil {
+		return nil, err
+	}
+	if err := sm.SetTokenHash(HashToken(result.AuthToken)); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[INFO] Agente enrolado en el backend como %s (%s)", result.AgentID, result.ID)
+	return &result, nil
+}
+
+// enrollmentURL construye la URL de enrolamiento a partir de la URL de la
+// API del backend. Sin esquema se asume https; http solo se acepta con
+// allowInsecure porque el token y las credenciales viajarían en claro.
+func enrollmentURL(apiURL string, allowInsecure bool) (string, error) {
+	base := strings.TrimSpace(apiURL)
+	if base == "" {
+		return "", fmt.Errorf("enroll_url no configurado")
+	}
+	if !strings.Contains(base, "://") {
+		base = "https://" + base
+	}
+
+	switch {
+	case strings.HasPrefix(base, "https://"):
+	case strings.HasPrefix(base, "http://"):
+		if !allowInsecure {
+			return "", fmt.Errorf("enroll_url debe usar https (allow_insecure_enroll permite http): %s", apiURL)
+		}
+	default:
+		return "", fmt.Errorf("enroll_url debe usar https: %s", apiURL)
+	}
+
+	return strings.TrimRight(base, "/") + enrollPath, nil
+}
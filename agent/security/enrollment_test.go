@@ -0,0 +1,96 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEnroll(t *testing.T) {
+	ca := newTestCA(t)
+	authToken := strings.Repeat("a", 64)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != enrollPath {
+			http.NotFound(w, r)
+			return
+		}
+		var req EnrollmentRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Token != "valido" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid or expired enrollment token"})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(EnrollmentResponse{
+			ID:            "0b8e1c1e-0000-0000-0000-000000000001",
+			AgentID:       req.AgentID,
+			AuthToken:     authToken,
+			Certificate:   string(ca.issueAgentCert(t, []byte(req.CSR))),
+			CACertificate: string(ca.certPEM),
+		})
+	}))
+	defer backend.Close()
+
+	sm := newUnpairedManager(t)
+
+	// httptest.NewServer no usa TLS
+	if _, err := sm.Enroll(context.Background(), backend.URL, false, EnrollmentRequest{Token: "valido", AgentID: "agent-1", Hostname: "host"}); err == nil || !strings.Contains(err.Error(), "https") {
+		t.Errorf("Se esperaba rechazo de http sin allow_insecure_enroll, obtenido %v", err)
+	}
+
+	_, err := sm.Enroll(context.Background(), backend.URL, true, EnrollmentRequest{Token: "usado", AgentID: "agent-1", Hostname: "host"})
+	if err == nil || !strings.Contains(err.Error(), "invalid or expired") {
+		t.Errorf("Se esperaba el error del backend, obtenido %v", err)
+	}
+	if sm.IsPaired() {
+		t.Fatal("Un enrolamiento rechazado no deberia emparejar el agente")
+	}
+
+	resp, err := sm.Enroll(context.Background(), backend.URL+"/", true, EnrollmentRequest{Token: "valido", AgentID: "agent-1", Hostname: "host"})
+	if err != nil {
+		t.Fatalf("Error enrolando: %v", err)
+	}
+	if resp.AgentID != "agent-1" {
+		t.Errorf("AgentID inesperado: %s", resp.AgentID)
+	}
+	if !sm.ValidateToken(authToken) {
+		t.Error("El token recibido deberia ser valido")
+	}
+	if !sm.MutualTLSEnabled() {
+		t.Error("El certificado recibido deberia estar instalado")
+	}
+}
+
+func TestEnrollmentURL(t *testing.T) {
+	cases := map[string]string{
+		"backend.example.com":          "https://backend.example.com" + enrollPath,
+		"https://backend.example.com/": "https://backend.example.com" + enrollPath,
+	}
+	for input, expected := range cases {
+		got, err := enrollmentURL(input, false)
+		if err != nil || got != expected {
+			t.Errorf("enrollmentURL(%q) = %q, %v; esperado %q", input, got, err, expected)
+		}
+	}
+	if _, err := enrollmentURL("", false); err == nil {
+		t.Error("Se esperaba error sin enroll_url")
+	}
+
+	// http falla salvo que se permita explícitamente
+	for _, input := range []string{"http://backend.example.com", "ftp://backend.example.com"} {
+		if _, err := enrollmentURL(input, false); err == nil {
+			t.Errorf("enrollmentURL(%q) debería rechazarse", input)
+		}
+	}
+	if got, err := enrollmentURL("http://localhost:8080", true); err != nil || got != "http://localhost:8080"+enrollPath {
+		t.Errorf("enrollmentURL con allow_insecure_enroll = %q, %v", got, err)
+	}
+	if _, err := enrollmentURL("ftp://backend.example.com", true); err == nil {
+		t.Error("allow_insecure_enroll solo debería permitir http")
+	}
+}
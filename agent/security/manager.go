@@ -21,6 +21,8 @@ type SecurityManager struct {
 	tlsConfig  *tls.Config
 	privateKey *rsa.PrivateKey
 	publicKey  *rsa.PublicKey
+	auth       tokenState // Token de autenticación del backend
+	certs      certState  // Certificado emitido por la CA del backend
 }
 
 // NewSecurityManager crea un nuevo gestor de seguridad
@@ -44,6 +46,9 @@ func NewSecurityManager(certFile, keyFile string) (*SecurityManager, error) {
 		}
 	}
 
+	// Un certificado instalado por el backend reemplaza al base
+	sm.tlsConfig.GetConfigForClient = sm.configForClient
+
 	return sm, nil
 }
 
@@ -199,9 +204,3 @@ func (sm *SecurityManager) GenerateToken() (string, error) {
 	}
 	return fmt.Sprintf("%x", tokenBytes), nil
 }
-
-// ValidateToken valida un token de autenticación
-func (sm *SecurityManager) ValidateToken(token string) bool {
-	// TODO: Implementar validación real de tokens
-	return len(token) == 64 // Validación básica
-}
@@ -39,6 +39,12 @@ if err != nil {
 t.Fatalf("Error creando SecurityManager: %v", err)
 }
 validToken := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+if sm.ValidateToken(validToken) {
+t.Error("Sin hash configurado ningun token deberia ser valido")
+}
+if err := sm.SetTokenHash(HashToken(validToken)); err != nil {
+t.Fatalf("Error configurando hash: %v", err)
+}
 if !sm.ValidateToken(validToken) {
 t.Error("Token valido fue rechazado")
 }
@@ -0,0 +1,342 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Formatos de archivo comprimido del gestor de archivos
+const (
+	ArchiveZip   = "zip"
+	ArchiveTarGz = "tar.gz"
+	ArchiveTar   = "tar"
+)
+
+// Límites de ExtractArchive frente a bombas de descompresión. Son variables
+// para poder reducirlos en los tests.
+var (
+	maxExtractSize    int64 = 20 << 30 // bytes descomprimidos en total
+	maxExtractEntries       = 100000   // entradas del archivo, incluidos directorios
+)
+
+// ErrArchiveTooLarge el archivo supera el tamaño descomprimido o el número
+// de entradas permitidos
+var ErrArchiveTooLarge = errors.New("el archivo supera el límite de extracción")
+
+// ExtractResult resumen de una extracción
+type ExtractResult struct {
+	Files   int // archivos escritos
+	Skipped int // entradas omitidas (existentes sin overwrite, enlaces, dispositivos)
+}
+
+// DetectArchiveFormat deduce el formato por la extensión del archivo
+func DetectArchiveFormat(name string) (string, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"), strings.HasSuffix(lower, ".jar"):
+		return ArchiveZip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveTarGz, nil
+	case strings.HasSuffix(lower, ".tar"):
+		return ArchiveTar, nil
+	}
+	return "", fmt.Errorf("formato de archivo no soportado: %s", filepath.Base(name))
+}
+
+// CreateArchive comprime las rutas indicadas en dest. Cada ruta se guarda
+// con su nombre base como raíz dentro del archivo. Los enlaces simbólicos se
+// omiten para no empaquetar contenido de fuera del directorio. Retorna el
+// número de archivos añadidos.
+func CreateArchive(ctx context.Context, dest string, paths []string, format string) (int, error) {
+	tmpPath := dest + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("error creando archivo: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	var add func(name string, path string, info os.FileInfo) error
+	var closeAll func() error
+
+	switch format {
+	case ArchiveZip:
+		zw := zip.NewWriter(out)
+		add = func(name, path string, info os.FileInfo) error {
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = name
+			if info.IsDir() {
+				header.Name += "/"
+				_, err := zw.CreateHeader(header)
+				return err
+			}
+			header.Method = zip.Deflate
+			w, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			return copyFileTo(ctx, w, path)
+		}
+		closeAll = zw.Close
+	case ArchiveTarGz, ArchiveTar:
+		var gw *gzip.Writer
+		var tw *tar.Writer
+		if format == ArchiveTarGz {
+			gw = gzip.NewWriter(out)
+			tw = tar.NewWriter(gw)
+		} else {
+			tw = tar.NewWriter(out)
+		}
+		add = func(name, path string, info os.FileInfo) error {
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = name
+			if info.IsDir() {
+				header.Name += "/"
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			return copyFileTo(ctx, tw, path)
+		}
+		closeAll = func() error {
+			if err := tw.Close(); err != nil {
+				return err
+			}
+			if gw != nil {
+				return gw.Close()
+			}
+			return nil
+		}
+	default:
+		out.Close()
+		return 0, fmt.Errorf("formato de archivo no soportado: %s", format)
+	}
+
+	count := 0
+	for _, root := range paths {
+		base := filepath.Dir(root)
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// El propio archivo en construcción puede estar dentro de la selección
+			if path == tmpPath || path == dest {
+				return nil
+			}
+			if !info.IsDir() && !info.Mode().IsRegular() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(base, path)
+			if err != nil {
+				return err
+			}
+			if err := add(filepath.ToSlash(rel), path, info); err != nil {
+				return fmt.Errorf("error añadiendo %s: %w", rel, err)
+			}
+			if !info.IsDir() {
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			out.Close()
+			return 0, err
+		}
+	}
+
+	if err := closeAll(); err != nil {
+		out.Close()
+		return 0, fmt.Errorf("error finalizando archivo: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmpPath, dest); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ExtractArchive extrae src dentro de destDir. Cada entrada se resuelve con
+// un Sandbox sobre destDir, así que nombres con "..", rutas absolutas o
+// entradas que pasen por enlaces existentes hacia fuera se rechazan. Los
+// enlaces y dispositivos del archivo se omiten. Sin overwrite, los archivos
+// que ya existen se conservan. Si el archivo supera maxExtractEntries
+// entradas o maxExtractSize bytes descomprimidos la extracción se detiene
+// con ErrArchiveTooLarge; lo ya extraído se conserva.
+func ExtractArchive(ctx context.Context, src, destDir, format string, overwrite bool) (*ExtractResult, error) {
+	sandbox, err := NewSandbox(destDir)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExtractResult{}
+	remaining := maxExtractSize
+	entries := 0
+
+	// countEntry limita el número de entradas procesadas
+	countEntry := func() error {
+		entries++
+		if entries > maxExtractEntries {
+			return fmt.Errorf("%w: más de %d entradas", ErrArchiveTooLarge, maxExtractEntries)
+		}
+		return nil
+	}
+
+	// write crea un archivo de la entrada respetando overwrite
+	write := func(name string, mode os.FileMode, r io.Reader) error {
+		path, err := sandbox.ResolveLink(name)
+		if err != nil {
+			return fmt.Errorf("entrada no válida %q: %w", name, err)
+		}
+		if info, err := os.Lstat(path); err == nil {
+			if !overwrite || info.IsDir() {
+				result.Skipped++
+				return nil
+			}
+			// Reemplazar el archivo (o enlace) en lugar de escribir a través de él
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode.Perm()|0600)
+		if err != nil {
+			return err
+		}
+		// Se cuentan los bytes reales: el tamaño declarado en la cabecera
+		// puede ser falso
+		n, err := io.Copy(f, io.LimitReader(contextReader{ctx: ctx, r: r}, remaining+1))
+		remaining -= n
+		if err == nil && remaining < 0 {
+			err = fmt.Errorf("%w: más de %d bytes descomprimidos", ErrArchiveTooLarge, maxExtractSize)
+		}
+		if err != nil {
+			f.Close()
+			os.Remove(path)
+			return err
+		}
+		result.Files++
+		return f.Close()
+	}
+
+	mkdir := func(name string) error {
+		path, err := sandbox.Resolve(name)
+		if err != nil {
+			return fmt.Errorf("entrada no válida %q: %w", name, err)
+		}
+		return os.MkdirAll(path, 0755)
+	}
+
+	switch format {
+	case ArchiveZip:
+		zr, err := zip.OpenReader(src)
+		if err != nil {
+			return nil, fmt.Errorf("error abriendo zip: %w", err)
+		}
+		defer zr.Close()
+
+		for _, f := range zr.File {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if err := countEntry(); err != nil {
+				return nil, err
+			}
+			switch {
+			case f.FileInfo().IsDir():
+				err = mkdir(f.Name)
+			case f.Mode().IsRegular():
+				var rc io.ReadCloser
+				rc, err = f.Open()
+				if err == nil {
+					err = write(f.Name, f.Mode(), rc)
+					rc.Close()
+				}
+			default:
+				result.Skipped++
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	case ArchiveTarGz, ArchiveTar:
+		file, err := os.Open(src)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		var r io.Reader = file
+		if format == ArchiveTarGz {
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				return nil, fmt.Errorf("error abriendo gzip: %w", err)
+			}
+			defer gz.Close()
+			r = gz
+		}
+
+		tr := tar.NewReader(r)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error leyendo tar: %w", err)
+			}
+			if err := countEntry(); err != nil {
+				return nil, err
+			}
+			switch header.Typeflag {
+			case tar.TypeDir:
+				err = mkdir(header.Name)
+			case tar.TypeReg:
+				err = write(header.Name, os.FileMode(header.Mode), tr)
+			default:
+				result.Skipped++
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("formato de archivo no soportado: %s", format)
+	}
+
+	return result, nil
+}
+
+// copyFileTo copia el contenido de un archivo a w
+func copyFileTo(ctx context.Context, w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, contextReader{ctx: ctx, r: f})
+	return err
+}
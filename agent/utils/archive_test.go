@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	for _, format := range []string{ArchiveZip, ArchiveTarGz, ArchiveTar} {
+		t.Run(format, func(t *testing.T) {
+			source := t.TempDir()
+			writeTestFile(t, filepath.Join(source, "world", "level.dat"), []byte("nivel"))
+			writeTestFile(t, filepath.Join(source, "world", "region", "r.0.0.mca"), []byte("region"))
+			writeTestFile(t, filepath.Join(source, "server.properties"), []byte("motd=hola\n"))
+
+			dest := filepath.Join(source, "backup."+format)
+			count, err := CreateArchive(context.Background(), dest,
+				[]string{filepath.Join(source, "world"), filepath.Join(source, "server.properties")}, format)
+			if err != nil {
+				t.Fatalf("CreateArchive() error = %v", err)
+			}
+			if count != 3 {
+				t.Errorf("CreateArchive() = %d archivos, esperado 3", count)
+			}
+
+			target := t.TempDir()
+			result, err := ExtractArchive(context.Background(), dest, target, format, false)
+			if err != nil {
+				t.Fatalf("ExtractArchive() error = %v", err)
+			}
+			if result.Files != 3 {
+				t.Errorf("ExtractArchive() = %d archivos, esperado 3", result.Files)
+			}
+
+			data, err := os.ReadFile(filepath.Join(target, "world", "region", "r.0.0.mca"))
+			if err != nil || string(data) != "region" {
+				t.Errorf("contenido extraído = %q, %v", data, err)
+			}
+
+			// Sin overwrite los archivos existentes se conservan
+			writeTestFile(t, filepath.Join(target, "server.properties"), []byte("modificado"))
+			result, err = ExtractArchive(context.Background(), dest, target, format, false)
+			if err != nil {
+				t.Fatalf("ExtractArchive() error = %v", err)
+			}
+			if result.Files != 0 || result.Skipped != 3 {
+				t.Errorf("ExtractArchive() sin overwrite = %+v", result)
+			}
+			if data, _ := os.ReadFile(filepath.Join(target, "server.properties")); string(data) != "modificado" {
+				t.Errorf("server.properties sobrescrito sin overwrite")
+			}
+		})
+	}
+}
+
+func TestExtractArchiveRejectsZipSlip(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "evil.zip")
+	f, err := os.Create(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("../../escaped.txt")
+	w.Write([]byte("x"))
+	zw.Close()
+	f.Close()
+
+	target := t.TempDir()
+	_, err = ExtractArchive(context.Background(), src, target, ArchiveZip, true)
+	if !errors.Is(err, ErrOutsideSandbox) {
+		t.Fatalf("ExtractArchive() error = %v, esperado ErrOutsideSandbox", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(filepath.Dir(target)), "escaped.txt")); err == nil {
+		t.Fatal("el archivo escapó del directorio de destino")
+	}
+}
+
+func TestExtractArchiveLimits(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "bomb.zip")
+	f, err := os.Create(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		w, _ := zw.Create(name)
+		w.Write(bytes.Repeat([]byte("x"), 1000))
+	}
+	zw.Close()
+	f.Close()
+
+	defer func(size int64, entries int) {
+		maxExtractSize, maxExtractEntries = size, entries
+	}(maxExtractSize, maxExtractEntries)
+
+	maxExtractSize, maxExtractEntries = 2500, 100
+	target := t.TempDir()
+	_, err = ExtractArchive(context.Background(), src, target, ArchiveZip, false)
+	if !errors.Is(err, ErrArchiveTooLarge) {
+		t.Fatalf("ExtractArchive() error = %v, esperado ErrArchiveTooLarge por tamaño", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "c.txt")); err == nil {
+		t.Error("el archivo que supera el límite no debería quedar a medias")
+	}
+
+	maxExtractSize, maxExtractEntries = 1<<20, 2
+	_, err = ExtractArchive(context.Background(), src, t.TempDir(), ArchiveZip, false)
+	if !errors.Is(err, ErrArchiveTooLarge) {
+		t.Fatalf("ExtractArchive() error = %v, esperado ErrArchiveTooLarge por entradas", err)
+	}
+
+	maxExtractSize, maxExtractEntries = 3000, 3
+	if result, err := ExtractArchive(context.Background(), src, t.TempDir(), ArchiveZip, false); err != nil || result.Files != 3 {
+		t.Errorf("ExtractArchive() en el límite = %+v, %v", result, err)
+	}
+}
+
+func TestDetectArchiveFormat(t *testing.T) {
+	cases := map[string]string{
+		"datapack.zip": ArchiveZip,
+		"mundo.tar.gz": ArchiveTarGz,
+		"mundo.TGZ":    ArchiveTarGz,
+		"plugins.tar":  ArchiveTar,
+	}
+	for name, want := range cases {
+		if got, err := DetectArchiveFormat(name); err != nil || got != want {
+			t.Errorf("DetectArchiveFormat(%q) = %q, %v; esperado %q", name, got, err, want)
+		}
+	}
+	if _, err := DetectArchiveFormat("notas.txt"); err == nil {
+		t.Error("DetectArchiveFormat(notas.txt) debería fallar")
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -17,6 +18,12 @@ import (
 // excludePaths: paths a excluir del backup
 // compress: si es true, usa compresión gzip
 func CreateTarGzBackup(sourceDir, destFile string, includePaths map[string]bool, excludePaths []string, compress bool) (int64, string, error) {
+	return CreateTarGzBackupContext(context.Background(), sourceDir, destFile, includePaths, excludePaths, compress)
+}
+
+// CreateTarGzBackupContext igual que CreateTarGzBackup, pero se interrumpe
+// al cancelarse ctx
+func CreateTarGzBackupContext(ctx context.Context, sourceDir, destFile string, includePaths map[string]bool, excludePaths []string, compress bool) (int64, string, error) {
 	// Crear archivo de destino
 	file, err := os.Create(destFile)
 	if err != nil {
@@ -30,14 +37,13 @@ func CreateTarGzBackup(sourceDir, destFile string, includePaths map[string]bool,
 
 	// Crear writer con o sin compresión
 	var tarWriter *tar.Writer
+	var gzipWriter *gzip.Writer
 	if compress {
-		gzipWriter := gzip.NewWriter(writer)
-		defer gzipWriter.Close()
+		gzipWriter = gzip.NewWriter(writer)
 		tarWriter = tar.NewWriter(gzipWriter)
 	} else {
 		tarWriter = tar.NewWriter(writer)
 	}
-	defer tarWriter.Close()
 
 	// Convertir excludePaths a map para búsqueda rápida
 	excludeMap := make(map[string]bool)
@@ -50,6 +56,9 @@ func CreateTarGzBackup(sourceDir, destFile string, includePaths map[string]bool,
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
 		// Obtener path relativo
 		relPath, err := filepath.Rel(sourceDir, path)
@@ -100,7 +109,7 @@ func CreateTarGzBackup(sourceDir, destFile string, includePaths map[string]bool,
 			}
 			defer file.Close()
 
-			if _, err := io.Copy(tarWriter, file); err != nil {
+			if _, err := io.Copy(tarWriter, contextReader{ctx: ctx, r: file}); err != nil {
 				return err
 			}
 		}
@@ -109,9 +118,27 @@ func CreateTarGzBackup(sourceDir, destFile string, includePaths map[string]bool,
 	})
 
 	if err != nil {
+		tarWriter.Close()
+		if gzipWriter != nil {
+			gzipWriter.Close()
+		}
 		return 0, "", fmt.Errorf("error recorriendo directorio: %w", err)
 	}
 
+	// Cerrar los writers antes de medir: escriben los últimos bloques
+	// y forman parte del tamaño y del checksum
+	if err := tarWriter.Close(); err != nil {
+		return 0, "", fmt.Errorf("error cerrando tar: %w", err)
+	}
+	if gzipWriter != nil {
+		if err := gzipWriter.Close(); err != nil {
+			return 0, "", fmt.Errorf("error cerrando gzip: %w", err)
+		}
+	}
+	if err := file.Sync(); err != nil {
+		return 0, "", fmt.Errorf("error sincronizando archivo: %w", err)
+	}
+
 	// Obtener tamaño del archivo
 	stat, err := os.Stat(destFile)
 	if err != nil {
@@ -242,3 +269,16 @@ func shouldInclude(path string, includeMap map[string]bool) bool {
 
 	return false
 }
+
+// contextReader corta una copia larga cuando se cancela el contexto
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
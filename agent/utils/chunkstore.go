@@ -0,0 +1,690 @@
+package utils
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultChunkSize tamaño de los fragmentos del almacén. Los archivos de
+// región se modifican en el lugar, así que fragmentos fijos permiten
+// reutilizar las partes que no cambiaron.
+const DefaultChunkSize = 1024 * 1024
+
+// ChunkStore es un almacén de backups direccionado por contenido. Cada
+// snapshot es un manifiesto con los archivos del servidor y los hashes de
+// sus fragmentos; un fragmento se guarda una sola vez aunque aparezca en
+// muchos snapshots.
+//
+// Estructura en disco:
+//
+//	<dir>/chunks/ab/abcdef...   fragmentos comprimidos con gzip
+//	<dir>/manifests/<server>/<id>.json
+type ChunkStore struct {
+	dir       string
+	chunkSize int
+
+	// Crear y restaurar snapshots toma el lock de lectura; eliminar
+	// snapshots y recolectar fragmentos toma el de escritura para no
+	// borrar fragmentos que un snapshot en curso acaba de referenciar.
+	mu sync.RWMutex
+}
+
+// SnapshotFile entrada de un archivo o directorio en un snapshot
+type SnapshotFile struct {
+	Path    string      `json:"path"` // Relativa, separada por "/"
+	Mode    os.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mod_time"`
+	Size    int64       `json:"size"`
+	Hash    string      `json:"hash,omitempty"` // SHA256 del archivo completo
+	Chunks  []string    `json:"chunks,omitempty"`
+	Dir     bool        `json:"dir,omitempty"`
+}
+
+// Snapshot manifiesto de un backup incremental
+type Snapshot struct {
+	ID        string         `json:"id"`
+	ServerID  string         `json:"server_id"`
+	Parent    string         `json:"parent,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	Files     []SnapshotFile `json:"files"`
+	TotalSize int64          `json:"total_size"` // Tamaño de los archivos
+	NewChunks int            `json:"new_chunks"` // Fragmentos que no existían
+	NewBytes  int64          `json:"new_bytes"`  // Bytes escritos en disco
+}
+
+// GCStats resultado de una recolección de fragmentos
+type GCStats struct {
+	ChunksRemoved int
+	BytesFreed    int64
+}
+
+// NewChunkStore crea un almacén en dir. Los directorios se crean al
+// guardar el primer snapshot.
+func NewChunkStore(dir string) *ChunkStore {
+	return &ChunkStore{
+		dir:       dir,
+		chunkSize: DefaultChunkSize,
+	}
+}
+
+// CreateSnapshot guarda el estado actual de sourceDir. Los archivos con el
+// mismo tamaño y fecha de modificación que en el snapshot anterior del
+// servidor reutilizan sus fragmentos sin volver a leerse. Si ctx se
+// cancela no se guarda el manifiesto; los fragmentos ya escritos se
+// eliminan en la próxima recolección.
+func (s *ChunkStore) CreateSnapshot(ctx context.Context, serverID, sourceDir string, includePaths map[string]bool, excludePaths []string) (*Snapshot, error) {
+	if err := validateStoreName(serverID); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	previous := make(map[string]SnapshotFile)
+	parent, err := s.latestSnapshot(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	snapshot := &Snapshot{
+		ID:        newSnapshotID(now),
+		ServerID:  serverID,
+		CreatedAt: now,
+	}
+	if parent != nil {
+		snapshot.Parent = parent.ID
+		for _, file := range parent.Files {
+			previous[file.Path] = file
+		}
+	}
+
+	excludeMap := make(map[string]bool)
+	for _, path := range excludePaths {
+		excludeMap[path] = true
+	}
+
+	err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		if shouldExclude(relPath, excludeMap) || (includePaths != nil && !shouldInclude(relPath, includePaths)) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		entry := SnapshotFile{
+			Path:    filepath.ToSlash(relPath),
+			Mode:    info.Mode().Perm(),
+			ModTime: info.ModTime(),
+		}
+
+		switch {
+		case info.IsDir():
+			entry.Dir = true
+		case info.Mode().IsRegular():
+			entry.Size = info.Size()
+			if prev, ok := previous[entry.Path]; ok && !prev.Dir && prev.Size == entry.Size &&
+				prev.ModTime.Equal(entry.ModTime) && s.hasChunks(prev.Chunks) {
+				entry.Hash = prev.Hash
+				entry.Chunks = prev.Chunks
+			} else {
+				if err := s.storeFile(ctx, path, &entry, snapshot); err != nil {
+					return fmt.Errorf("error guardando %s: %w", relPath, err)
+				}
+			}
+			snapshot.TotalSize += entry.Size
+		default:
+			// Enlaces simbólicos, sockets, etc. no se respaldan
+			return nil
+		}
+
+		snapshot.Files = append(snapshot.Files, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error recorriendo directorio: %w", err)
+	}
+
+	if err := s.saveManifest(snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// storeFile divide un archivo en fragmentos y guarda los que no existen
+func (s *ChunkStore) storeFile(ctx context.Context, path string, entry *SnapshotFile, snapshot *Snapshot) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fileHasher := sha256.New()
+	buffer := make([]byte, s.chunkSize)
+	var size int64
+
+	for {
+		n, readErr := io.ReadFull(contextReader{ctx: ctx, r: file}, buffer)
+		if n > 0 {
+			data := buffer[:n]
+			fileHasher.Write(data)
+			size += int64(n)
+
+			sum := sha256.Sum256(data)
+			hash := hex.EncodeToString(sum[:])
+			written, err := s.writeChunk(hash, data)
+			if err != nil {
+				return err
+			}
+			if written > 0 {
+				snapshot.NewChunks++
+				snapshot.NewBytes += written
+			}
+			entry.Chunks = append(entry.Chunks, hash)
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	// El archivo pudo crecer mientras se leía; se guarda lo leído
+	entry.Size = size
+	entry.Hash = hex.EncodeToString(fileHasher.Sum(nil))
+	return nil
+}
+
+// writeChunk guarda un fragmento si no existe. Retorna los bytes escritos
+// (0 si ya estaba en el almacén).
+func (s *ChunkStore) writeChunk(hash string, data []byte) (int64, error) {
+	path := s.chunkPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return 0, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, fmt.Errorf("error creando directorio de fragmentos: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), hash+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("error creando fragmento: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	gzipWriter, _ := gzip.NewWriterLevel(tmp, gzip.BestSpeed)
+	_, err = gzipWriter.Write(data)
+	if closeErr := gzipWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("error escribiendo fragmento: %w", err)
+	}
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("error guardando fragmento: %w", err)
+	}
+
+	return info.Size(), nil
+}
+
+// hasChunks verifica que todos los fragmentos existen en disco
+func (s *ChunkStore) hasChunks(chunks []string) bool {
+	for _, hash := range chunks {
+		if _, err := os.Stat(s.chunkPath(hash)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// RestoreSnapshot reconstruye los archivos de un snapshot en destDir.
+// restorePaths: si no es nil, solo restaura estos paths. Cada archivo se
+// verifica contra su hash antes de reemplazar al existente. Dentro de los
+// directorios restaurados se eliminan los archivos que no están en el
+// snapshot, como regiones o plugins creados después.
+func (s *ChunkStore) RestoreSnapshot(serverID, snapshotID, destDir string, restorePaths map[string]bool) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, err := s.loadManifest(serverID, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	cleanDest := filepath.Clean(destDir)
+	for _, file := range snapshot.Files {
+		relPath := filepath.FromSlash(file.Path)
+		if restorePaths != nil && !shouldInclude(relPath, restorePaths) {
+			continue
+		}
+
+		targetPath := filepath.Join(cleanDest, relPath)
+		if !strings.HasPrefix(targetPath, cleanDest+string(os.PathSeparator)) {
+			return fmt.Errorf("intento de path traversal detectado: %s", file.Path)
+		}
+
+		if file.Dir {
+			if err := os.MkdirAll(targetPath, 0755); err != nil {
+				return fmt.Errorf("error creando directorio: %w", err)
+			}
+			continue
+		}
+
+		if err := s.restoreFile(file, targetPath); err != nil {
+			return fmt.Errorf("error restaurando %s: %w", file.Path, err)
+		}
+	}
+
+	return pruneRestored(snapshot, cleanDest, restorePaths)
+}
+
+// pruneRestored elimina lo que no figura en el snapshot dentro de los
+// directorios restaurados: los de restorePaths o, en una restauración
+// completa, los directorios de primer nivel del snapshot. Los archivos
+// sueltos de primer nivel no se tocan porque el snapshot pudo excluirlos,
+// y los enlaces simbólicos tampoco, ya que nunca se respaldan.
+func pruneRestored(snapshot *Snapshot, destDir string, restorePaths map[string]bool) error {
+	inSnapshot := make(map[string]bool, len(snapshot.Files))
+	var roots []string
+	for _, file := range snapshot.Files {
+		inSnapshot[file.Path] = true
+
+		if !file.Dir {
+			continue
+		}
+		if restorePaths == nil && !strings.Contains(file.Path, "/") ||
+			restorePaths != nil && restorePaths[filepath.FromSlash(file.Path)] {
+			roots = append(roots, file.Path)
+		}
+	}
+
+	for _, root := range roots {
+		err := filepath.Walk(filepath.Join(destDir, filepath.FromSlash(root)), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			relPath, err := filepath.Rel(destDir, path)
+			if err != nil {
+				return err
+			}
+			if inSnapshot[filepath.ToSlash(relPath)] {
+				return nil
+			}
+
+			switch {
+			case info.IsDir():
+				if err := os.RemoveAll(path); err != nil {
+					return err
+				}
+				return filepath.SkipDir
+			case info.Mode().IsRegular():
+				return os.Remove(path)
+			default:
+				return nil
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("error eliminando archivos fuera del snapshot: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// restoreFile reconstruye un archivo a partir de sus fragmentos
+func (s *ChunkStore) restoreFile(file SnapshotFile, targetPath string) error {
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return err
+	}
+
+	tmpPath := targetPath + ".restore"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	hasher := sha256.New()
+	writer := io.MultiWriter(out, hasher)
+	for _, hash := range file.Chunks {
+		if err = s.copyChunk(writer, hash); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		if sum := hex.EncodeToString(hasher.Sum(nil)); sum != file.Hash {
+			err = fmt.Errorf("checksum no coincide: %s, esperado %s", sum, file.Hash)
+		}
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Chmod(tmpPath, file.Mode); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, targetPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Chtimes(targetPath, file.ModTime, file.ModTime)
+}
+
+// copyChunk descomprime un fragmento en writer
+func (s *ChunkStore) copyChunk(writer io.Writer, hash string) error {
+	file, err := os.Open(s.chunkPath(hash))
+	if err != nil {
+		return fmt.Errorf("fragmento %s no disponible: %w", hash, err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("fragmento %s corrupto: %w", hash, err)
+	}
+	defer gzipReader.Close()
+
+	_, err = io.Copy(writer, gzipReader)
+	return err
+}
+
+// ListSnapshots retorna los snapshots de un servidor, del más antiguo al
+// más reciente
+func (s *ChunkStore) ListSnapshots(serverID string) ([]*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.listSnapshots(serverID)
+}
+
+// GetSnapshot carga el manifiesto de un snapshot
+func (s *ChunkStore) GetSnapshot(serverID, snapshotID string) (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.loadManifest(serverID, snapshotID)
+}
+
+// Prune elimina los snapshots indicados y los que excedan keepLast (0 =
+// sin límite), y luego elimina los fragmentos que ya no usa ningún
+// snapshot. Retorna los IDs eliminados.
+func (s *ChunkStore) Prune(serverID string, snapshotIDs []string, keepLast int) ([]string, GCStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots, err := s.listSnapshots(serverID)
+	if err != nil {
+		return nil, GCStats{}, err
+	}
+
+	remove := make(map[string]bool)
+	for _, id := range snapshotIDs {
+		remove[id] = true
+	}
+	if keepLast > 0 && len(snapshots) > keepLast {
+		for _, snapshot := range snapshots[:len(snapshots)-keepLast] {
+			remove[snapshot.ID] = true
+		}
+	}
+
+	var deleted []string
+	for _, snapshot := range snapshots {
+		if !remove[snapshot.ID] {
+			continue
+		}
+		if err := os.Remove(s.manifestPath(serverID, snapshot.ID)); err != nil && !os.IsNotExist(err) {
+			return deleted, GCStats{}, fmt.Errorf("error eliminando snapshot %s: %w", snapshot.ID, err)
+		}
+		deleted = append(deleted, snapshot.ID)
+	}
+
+	stats, err := s.collectGarbage()
+	return deleted, stats, err
+}
+
+// collectGarbage elimina los fragmentos que no referencia ningún
+// manifiesto. Requiere el lock de escritura.
+func (s *ChunkStore) collectGarbage() (GCStats, error) {
+	var stats GCStats
+
+	referenced := make(map[string]bool)
+	manifestsDir := filepath.Join(s.dir, "manifests")
+	err := filepath.Walk(manifestsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		snapshot, err := readManifest(path)
+		if err != nil {
+			return err
+		}
+		for _, file := range snapshot.Files {
+			for _, hash := range file.Chunks {
+				referenced[hash] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Sin la lista completa de referencias no es seguro borrar nada
+		return stats, fmt.Errorf("error leyendo manifiestos: %w", err)
+	}
+
+	chunksDir := filepath.Join(s.dir, "chunks")
+	err = filepath.Walk(chunksDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || referenced[info.Name()] {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		stats.ChunksRemoved++
+		stats.BytesFreed += info.Size()
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("error eliminando fragmentos: %w", err)
+	}
+
+	return stats, nil
+}
+
+// latestSnapshot retorna el snapshot más reciente del servidor o nil
+func (s *ChunkStore) latestSnapshot(serverID string) (*Snapshot, error) {
+	snapshots, err := s.listSnapshots(serverID)
+	if err != nil || len(snapshots) == 0 {
+		return nil, err
+	}
+	return snapshots[len(snapshots)-1], nil
+}
+
+func (s *ChunkStore) listSnapshots(serverID string) ([]*Snapshot, error) {
+	if err := validateStoreName(serverID); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.dir, "manifests", serverID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listando snapshots: %w", err)
+	}
+
+	var snapshots []*Snapshot
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		snapshot, err := readManifest(filepath.Join(s.dir, "manifests", serverID, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+func (s *ChunkStore) loadManifest(serverID, snapshotID string) (*Snapshot, error) {
+	if err := validateStoreName(serverID); err != nil {
+		return nil, err
+	}
+	if err := validateStoreName(snapshotID); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := readManifest(s.manifestPath(serverID, snapshotID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("snapshot no encontrado: %s", snapshotID)
+		}
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// saveManifest escribe el manifiesto de forma atómica
+func (s *ChunkStore) saveManifest(snapshot *Snapshot) error {
+	path := s.manifestPath(snapshot.ServerID, snapshot.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creando directorio de manifiestos: %w", err)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("error serializando manifiesto: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("error escribiendo manifiesto: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error guardando manifiesto: %w", err)
+	}
+
+	return nil
+}
+
+// ManifestChecksum retorna el SHA256 del manifiesto guardado de un snapshot
+func (s *ChunkStore) ManifestChecksum(serverID, snapshotID string) (string, error) {
+	if err := validateStoreName(serverID); err != nil {
+		return "", err
+	}
+	if err := validateStoreName(snapshotID); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(s.manifestPath(serverID, snapshotID))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func readManifest(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("manifiesto inválido %s: %w", filepath.Base(path), err)
+	}
+	return &snapshot, nil
+}
+
+func (s *ChunkStore) chunkPath(hash string) string {
+	return filepath.Join(s.dir, "chunks", hash[:2], hash)
+}
+
+func (s *ChunkStore) manifestPath(serverID, snapshotID string) string {
+	return filepath.Join(s.dir, "manifests", serverID, snapshotID+".json")
+}
+
+// validateStoreName rechaza IDs que no pueden usarse como nombre de archivo
+func validateStoreName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("identificador inválido: %q", name)
+	}
+	return nil
+}
+
+// newSnapshotID genera un ID ordenable por fecha
+func newSnapshotID(now time.Time) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return now.Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestChunkStore(t *testing.T) *ChunkStore {
+	store := NewChunkStore(filepath.Join(t.TempDir(), "store"))
+	store.chunkSize = 1024
+	return store
+}
+
+func TestChunkStoreDeduplicatesUnchangedData(t *testing.T) {
+	store := newTestChunkStore(t)
+	source := t.TempDir()
+
+	region := bytes.Repeat([]byte("r"), 4096)
+	writeTestFile(t, filepath.Join(source, "world", "region", "r.0.0.mca"), region)
+	writeTestFile(t, filepath.Join(source, "server.properties"), []byte("motd=hola\n"))
+
+	first, err := store.CreateSnapshot(context.Background(), "srv", source, nil, nil)
+	if err != nil {
+		t.Fatalf("Error creando snapshot: %v", err)
+	}
+	// 4 fragmentos idénticos del archivo de región + server.properties
+	if first.NewChunks != 2 {
+		t.Errorf("Fragmentos nuevos esperados 2, obtenidos %d", first.NewChunks)
+	}
+
+	// Modificar solo el último fragmento de la región
+	modified := append([]byte{}, region...)
+	copy(modified[3072:], bytes.Repeat([]byte("x"), 1024))
+	writeTestFile(t, filepath.Join(source, "world", "region", "r.0.0.mca"), modified)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(source, "world", "region", "r.0.0.mca"), future, future)
+
+	second, err := store.CreateSnapshot(context.Background(), "srv", source, nil, nil)
+	if err != nil {
+		t.Fatalf("Error creando segundo snapshot: %v", err)
+	}
+	if second.Parent != first.ID {
+		t.Errorf("Parent esperado %s, obtenido %s", first.ID, second.Parent)
+	}
+	if second.NewChunks != 1 {
+		t.Errorf("Solo el fragmento modificado debería guardarse, obtenidos %d", second.NewChunks)
+	}
+
+	// Restaurar el primer snapshot reconstruye el contenido original
+	dest := t.TempDir()
+	if err := store.RestoreSnapshot("srv", first.ID, dest, nil); err != nil {
+		t.Fatalf("Error restaurando snapshot: %v", err)
+	}
+	restored, err := os.ReadFile(filepath.Join(dest, "world", "region", "r.0.0.mca"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(restored, region) {
+		t.Error("El archivo restaurado no coincide con el original")
+	}
+
+	dest = t.TempDir()
+	if err := store.RestoreSnapshot("srv", second.ID, dest, map[string]bool{"world": true}); err != nil {
+		t.Fatalf("Error restaurando snapshot parcial: %v", err)
+	}
+	restored, _ = os.ReadFile(filepath.Join(dest, "world", "region", "r.0.0.mca"))
+	if !bytes.Equal(restored, modified) {
+		t.Error("El archivo restaurado no coincide con la versión modificada")
+	}
+	if _, err := os.Stat(filepath.Join(dest, "server.properties")); !os.IsNotExist(err) {
+		t.Error("server.properties no debería restaurarse en una restauración parcial")
+	}
+}
+
+func TestChunkStorePruneCollectsUnreferencedChunks(t *testing.T) {
+	store := newTestChunkStore(t)
+	source := t.TempDir()
+
+	writeTestFile(t, filepath.Join(source, "a.dat"), bytes.Repeat([]byte("a"), 1024))
+	first, err := store.CreateSnapshot(context.Background(), "srv", source, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestFile(t, filepath.Join(source, "a.dat"), bytes.Repeat([]byte("b"), 1024))
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(source, "a.dat"), future, future)
+	second, err := store.CreateSnapshot(context.Background(), "srv", source, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, stats, err := store.Prune("srv", []string{first.ID}, 0)
+	if err != nil {
+		t.Fatalf("Error en prune: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != first.ID {
+		t.Errorf("Snapshots eliminados inesperados: %v", deleted)
+	}
+	if stats.ChunksRemoved != 1 {
+		t.Errorf("Fragmentos eliminados esperados 1, obtenidos %d", stats.ChunksRemoved)
+	}
+
+	snapshots, err := store.ListSnapshots("srv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 || snapshots[0].ID != second.ID {
+		t.Fatalf("Solo debería quedar el segundo snapshot")
+	}
+
+	// El snapshot restante sigue siendo restaurable
+	dest := t.TempDir()
+	if err := store.RestoreSnapshot("srv", second.ID, dest, nil); err != nil {
+		t.Fatalf("Error restaurando tras prune: %v", err)
+	}
+
+	// keepLast elimina todo lo que exceda el límite
+	if _, err := store.CreateSnapshot(context.Background(), "srv", source, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	deleted, _, err = store.Prune("srv", nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0] != second.ID {
+		t.Errorf("keepLast debería eliminar el snapshot más antiguo: %v", deleted)
+	}
+}
+
+func TestChunkStoreRestoreRemovesFilesCreatedAfterSnapshot(t *testing.T) {
+	store := newTestChunkStore(t)
+	source := t.TempDir()
+
+	writeTestFile(t, filepath.Join(source, "world", "region", "r.0.0.mca"), []byte("región original"))
+	writeTestFile(t, filepath.Join(source, "plugins", "Essentials.jar"), []byte("plugin"))
+	writeTestFile(t, filepath.Join(source, "server.properties"), []byte("motd=AYMC"))
+
+	snapshot, err := store.CreateSnapshot(context.Background(), "srv", source, nil, []string{"logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cambios posteriores al snapshot
+	writeTestFile(t, filepath.Join(source, "world", "region", "r.5.5.mca"), []byte("región nueva"))
+	writeTestFile(t, filepath.Join(source, "world", "DIM1", "region", "r.0.0.mca"), []byte("end nuevo"))
+	writeTestFile(t, filepath.Join(source, "plugins", "Nuevo.jar"), []byte("plugin nuevo"))
+	writeTestFile(t, filepath.Join(source, "logs", "latest.log"), []byte("log excluido"))
+
+	if err := store.RestoreSnapshot("srv", snapshot.ID, source, map[string]bool{"world": true}); err != nil {
+		t.Fatalf("Error restaurando snapshot parcial: %v", err)
+	}
+	for _, path := range []string{filepath.Join("world", "region", "r.5.5.mca"), filepath.Join("world", "DIM1")} {
+		if _, err := os.Stat(filepath.Join(source, path)); !os.IsNotExist(err) {
+			t.Errorf("%s debería eliminarse al restaurar world", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(source, "world", "region", "r.0.0.mca")); err != nil {
+		t.Errorf("La región del snapshot debería seguir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(source, "plugins", "Nuevo.jar")); err != nil {
+		t.Error("Una restauración parcial de world no debería tocar plugins")
+	}
+
+	if err := store.RestoreSnapshot("srv", snapshot.ID, source, nil); err != nil {
+		t.Fatalf("Error restaurando snapshot completo: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(source, "plugins", "Nuevo.jar")); !os.IsNotExist(err) {
+		t.Error("Nuevo.jar debería eliminarse al restaurar el snapshot completo")
+	}
+	if _, err := os.Stat(filepath.Join(source, "logs", "latest.log")); err != nil {
+		t.Error("Los directorios excluidos del snapshot no deberían tocarse")
+	}
+}
+
+func TestChunkStoreRejectsInvalidIDs(t *testing.T) {
+	store := newTestChunkStore(t)
+
+	if _, err := store.CreateSnapshot(context.Background(), "../escape", t.TempDir(), nil, nil); err == nil {
+		t.Error("Debería rechazar IDs de servidor con separadores")
+	}
+	if err := store.RestoreSnapshot("srv", "../../manifest", t.TempDir(), nil); err == nil {
+		t.Error("Debería rechazar IDs de snapshot con separadores")
+	}
+}
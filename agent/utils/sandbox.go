@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrOutsideSandbox se retorna cuando una ruta apunta fuera del directorio
+// permitido (rutas absolutas, "..", o enlaces simbólicos que escapan)
+var ErrOutsideSandbox = errors.New("la ruta sale del directorio permitido")
+
+// Sandbox restringe el acceso a archivos a un directorio raíz. Las rutas se
+// interpretan siempre relativas a la raíz y se resuelven siguiendo los
+// enlaces simbólicos antes de comprobar que no escapan.
+type Sandbox struct {
+	root string // absoluta y con enlaces resueltos
+}
+
+// NewSandbox crea un sandbox sobre un directorio existente
+func NewSandbox(root string) (*Sandbox, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("directorio raíz no disponible: %w", err)
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s no es un directorio", root)
+	}
+	return &Sandbox{root: resolved}, nil
+}
+
+// Root retorna el directorio raíz del sandbox
+func (sb *Sandbox) Root() string {
+	return sb.root
+}
+
+// Resolve convierte una ruta relativa en una ruta absoluta dentro del
+// sandbox. La ruta no tiene que existir; si existe (o existe parte de ella)
+// se siguen los enlaces simbólicos y el destino debe quedar dentro.
+func (sb *Sandbox) Resolve(path string) (string, error) {
+	if err := checkRelative(path); err != nil {
+		return "", err
+	}
+
+	resolved, err := resolveExisting(filepath.Join(sb.root, path))
+	if err != nil {
+		return "", err
+	}
+	if !sb.contains(resolved) {
+		return "", ErrOutsideSandbox
+	}
+	return resolved, nil
+}
+
+// ResolveLink es como Resolve pero no sigue un enlace simbólico en el último
+// elemento: la ruta retornada es el propio enlace. Es lo que necesitan
+// borrar, mover o sobrescribir, que actúan sobre el enlace y no su destino.
+// La raíz del sandbox no se puede resolver así.
+func (sb *Sandbox) ResolveLink(path string) (string, error) {
+	if err := checkRelative(path); err != nil {
+		return "", err
+	}
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if clean == "." {
+		return "", fmt.Errorf("%w: la operación no se permite sobre el directorio raíz", ErrOutsideSandbox)
+	}
+
+	parent, err := sb.Resolve(filepath.Dir(clean))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, filepath.Base(clean)), nil
+}
+
+// Rel retorna la ruta relativa a la raíz de una ruta resuelta por Resolve,
+// con "/" como separador ("." para la raíz)
+func (sb *Sandbox) Rel(path string) string {
+	rel, err := filepath.Rel(sb.root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// contains indica si una ruta absoluta y limpia está dentro de la raíz
+func (sb *Sandbox) contains(path string) bool {
+	if path == sb.root {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(sb.root, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// resolveExisting sigue los enlaces simbólicos de la parte existente más
+// larga de la ruta y le añade el resto sin cambios. Un enlace roto se
+// rechaza: escribir en él crearía su destino, que puede estar fuera.
+func resolveExisting(path string) (string, error) {
+	existing := filepath.Clean(path)
+	var rest []string
+
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: enlace simbólico roto", ErrOutsideSandbox)
+		}
+		return "", err
+	}
+
+	return filepath.Join(append([]string{resolved}, rest...)...), nil
+}
+
+// checkRelative rechaza rutas absolutas y con ".."
+func checkRelative(path string) error {
+	if strings.ContainsRune(path, 0) {
+		return ErrOutsideSandbox
+	}
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return fmt.Errorf("%w: no se permiten rutas absolutas", ErrOutsideSandbox)
+	}
+	for _, part := range strings.FieldsFunc(path, isPathSeparator) {
+		if part == ".." {
+			return fmt.Errorf("%w: no se permite \"..\"", ErrOutsideSandbox)
+		}
+	}
+	return nil
+}
+
+func isPathSeparator(r rune) bool {
+	return r == '/' || r == filepath.Separator
+}
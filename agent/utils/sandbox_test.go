@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestSandbox(t *testing.T) (*Sandbox, string) {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "server")
+	writeTestFile(t, filepath.Join(root, "plugins", "config.yml"), []byte("a: 1\n"))
+
+	sb, err := NewSandbox(root)
+	if err != nil {
+		t.Fatalf("Error creando sandbox: %v", err)
+	}
+	return sb, root
+}
+
+func TestSandboxResolvesPathsInsideRoot(t *testing.T) {
+	sb, _ := newTestSandbox(t)
+
+	for _, path := range []string{"", ".", "plugins/config.yml", "plugins/./config.yml", "world/nuevo.dat"} {
+		resolved, err := sb.Resolve(path)
+		if err != nil {
+			t.Errorf("Resolve(%q) error = %v", path, err)
+			continue
+		}
+		if !sb.contains(resolved) {
+			t.Errorf("Resolve(%q) = %s, fuera de %s", path, resolved, sb.Root())
+		}
+	}
+
+	resolved, _ := sb.Resolve("plugins/config.yml")
+	if got := sb.Rel(resolved); got != "plugins/config.yml" {
+		t.Errorf("Rel() = %q, esperado plugins/config.yml", got)
+	}
+}
+
+func TestSandboxRejectsEscapes(t *testing.T) {
+	sb, root := newTestSandbox(t)
+	outside := t.TempDir()
+	writeTestFile(t, filepath.Join(outside, "secret"), []byte("x"))
+
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("sin soporte de enlaces simbólicos: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "missing"), filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "plugins"), filepath.Join(root, "inside")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		"../secret",
+		"plugins/../../secret",
+		"/etc/passwd",
+		filepath.Join(outside, "secret"),
+		"escape/secret",
+		"escape/new-file",
+		"dangling",
+		"bad\x00name",
+	} {
+		if _, err := sb.Resolve(path); !errors.Is(err, ErrOutsideSandbox) {
+			t.Errorf("Resolve(%q) error = %v, esperado ErrOutsideSandbox", path, err)
+		}
+	}
+
+	// Los enlaces que apuntan dentro de la raíz se permiten
+	resolved, err := sb.Resolve("inside/config.yml")
+	if err != nil {
+		t.Fatalf("Resolve(inside/config.yml) error = %v", err)
+	}
+	if got := sb.Rel(resolved); got != "plugins/config.yml" {
+		t.Errorf("Rel() = %q, esperado plugins/config.yml", got)
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/aymc/backend/services/agents"
 	"github.com/gin-gonic/gin"
@@ -37,6 +38,9 @@ type AgentResponse struct {
 	LastSeen        string              `json:"last_seen,omitempty"`
 	ConsecutiveFails int                `json:"consecutive_fails"`
 	Metrics         *agents.AgentMetrics `json:"metrics,omitempty"`
+	CertFingerprint string              `json:"cert_fingerprint,omitempty"`
+	CertExpiresAt   *time.Time          `json:"cert_expires_at,omitempty"`
+	CertRevoked     bool                `json:"cert_revoked"`
 }
 
 // AgentListResponse representa una lista de agentes
@@ -92,6 +96,9 @@ func (h *AgentHandler) ListAgents(c *gin.Context) {
 			IsHealthy:        isHealthy,
 			LastSeen:         lastSeen,
 			ConsecutiveFails: conn.GetConsecutiveFails(),
+			CertFingerprint:  agent.CertFingerprint,
+			CertExpiresAt:    agent.CertExpiresAt,
+			CertRevoked:      agent.CertRevokedAt != nil,
 		}
 
 		agents = append(agents, agentResp)
@@ -163,6 +170,9 @@ func (h *AgentHandler) GetAgent(c *gin.Context) {
 		LastSeen:         lastSeen,
 		ConsecutiveFails: conn.GetConsecutiveFails(),
 		Metrics:          conn.GetMetrics(),
+		CertFingerprint:  agent.CertFingerprint,
+		CertExpiresAt:    agent.CertExpiresAt,
+		CertRevoked:      agent.CertRevokedAt != nil,
 	}
 
 	c.JSON(http.StatusOK, agentResp)
@@ -309,3 +319,142 @@ func (h *AgentHandler) GetAgentStats(c *gin.Context) {
 
 	c.JSON(http.StatusOK, stats)
 }
+
+// PairAgentRequest cuerpo de la solicitud de emparejamiento
+type PairAgentRequest struct {
+	PairingCode string `json:"pairing_code"`
+}
+
+// PairAgent provisiona (o rota) el token que el agente exige en gRPC
+// @Summary Pair agent
+// @Description Provision the token the agent requires on every gRPC call. The pairing code is printed in the agent log and is only needed for the first pairing.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID (UUID)"
+// @Param request body PairAgentRequest false "Pairing code"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /api/v1/agents/{id}/pair [post]
+// @Security BearerAuth
+func (h *AgentHandler) PairAgent(c *gin.Context) {
+	agentIDStr := c.Param("id")
+
+	// Validar UUID
+	agentID, err := uuid.Parse(agentIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid agent ID", zap.String("id", agentIDStr), zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID format"})
+		return
+	}
+
+	// El cuerpo es opcional: la rotación de un agente ya emparejado no
+	// necesita código
+	var req PairAgentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	if err := h.agentService.PairAgent(c.Request.Context(), agentID, req.PairingCode); err != nil {
+		h.logger.Error("Failed to pair agent",
+			zap.String("agent_id", agentIDStr),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Failed to pair agent",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Agent paired successfully"})
+}
+
+// RotateAgentCertificate emite un certificado nuevo al agente
+// @Summary Rotate agent certificate
+// @Description Issue a new mTLS certificate to a connected agent and pin its fingerprint
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID (UUID)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /api/v1/agents/{id}/certificate/rotate [post]
+// @Security BearerAuth
+func (h *AgentHandler) RotateAgentCertificate(c *gin.Context) {
+	agentIDStr := c.Param("id")
+
+	// Validar UUID
+	agentID, err := uuid.Parse(agentIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid agent ID", zap.String("id", agentIDStr), zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID format"})
+		return
+	}
+
+	registry := h.agentService.GetRegistry()
+	if err := registry.RotateCertificate(c.Request.Context(), agentID); err != nil {
+		h.logger.Error("Failed to rotate agent certificate",
+			zap.String("agent_id", agentIDStr),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Failed to rotate agent certificate",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	conn, err := registry.GetAgent(agentID)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Certificate rotated"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Certificate rotated",
+		"cert_fingerprint": conn.Agent.CertFingerprint,
+		"cert_expires_at":  conn.Agent.CertExpiresAt,
+	})
+}
+
+// RevokeAgentCertificate revoca el certificado del agente
+// @Summary Revoke agent certificate
+// @Description Revoke the agent certificate and disconnect it. The agent must be paired again to reconnect.
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID (UUID)"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/agents/{id}/certificate/revoke [post]
+// @Security BearerAuth
+func (h *AgentHandler) RevokeAgentCertificate(c *gin.Context) {
+	agentIDStr := c.Param("id")
+
+	// Validar UUID
+	agentID, err := uuid.Parse(agentIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid agent ID", zap.String("id", agentIDStr), zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID format"})
+		return
+	}
+
+	if err := h.agentService.GetRegistry().RevokeCertificate(agentID); err != nil {
+		h.logger.Error("Failed to revoke agent certificate",
+			zap.String("agent_id", agentIDStr),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Failed to revoke agent certificate",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Certificate revoked"})
+}
@@ -0,0 +1,462 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aymc/backend/api/rest/middleware"
+	"github.com/aymc/backend/database/models"
+	"github.com/aymc/backend/services/alerts"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AlertHandler handles alert rule, notification channel and alert endpoints
+type AlertHandler struct {
+	alertService *alerts.Service
+	validator    *validator.Validate
+	logger       *zap.Logger
+}
+
+// NewAlertHandler creates a new alert handler
+func NewAlertHandler(alertService *alerts.Service, logger *zap.Logger) *AlertHandler {
+	return &AlertHandler{
+		alertService: alertService,
+		validator:    validator.New(),
+		logger:       logger,
+	}
+}
+
+// ListRules lists the alert rules of the user
+// @Summary List alert rules
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.AlertRule
+// @Router /api/v1/alerts/rules [get]
+func (h *AlertHandler) ListRules(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	rules, err := h.alertService.ListRules(c.Request.Context(), userID, user.IsAdmin())
+	if err != nil {
+		h.respondError(c, err, "Failed to list alert rules")
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetRule retrieves an alert rule by ID
+// @Summary Get alert rule
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rule ID (UUID)"
+// @Success 200 {object} models.AlertRule
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/alerts/rules/{id} [get]
+func (h *AlertHandler) GetRule(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	ruleID, ok := parseIDParam(c, "rule")
+	if !ok {
+		return
+	}
+
+	rule, err := h.alertService.GetRule(c.Request.Context(), ruleID, userID, user.IsAdmin())
+	if err != nil {
+		h.respondError(c, err, "Failed to retrieve alert rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateRule creates an alert rule
+// @Summary Create alert rule
+// @Description Rule types: cpu, memory, disk (server or agent), tps, crash_loop, backup_failed (server only), agent_offline (agent only). Omit target_id to apply the rule to all servers of the user or all agents.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body alerts.CreateRuleRequest true "Rule data"
+// @Success 201 {object} models.AlertRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/alerts/rules [post]
+func (h *AlertHandler) CreateRule(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	var req alerts.CreateRuleRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	rule, err := h.alertService.CreateRule(c.Request.Context(), userID, user.IsAdmin(), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create alert rule")
+		return
+	}
+
+	h.logger.Info("Alert rule created",
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("type", string(rule.Type)),
+		zap.String("user_id", userID.String()),
+	)
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule updates an alert rule
+// @Summary Update alert rule
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rule ID (UUID)"
+// @Param request body alerts.UpdateRuleRequest true "Fields to update"
+// @Success 200 {object} models.AlertRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/alerts/rules/{id} [put]
+func (h *AlertHandler) UpdateRule(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	ruleID, ok := parseIDParam(c, "rule")
+	if !ok {
+		return
+	}
+
+	var req alerts.UpdateRuleRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	rule, err := h.alertService.UpdateRule(c.Request.Context(), ruleID, userID, user.IsAdmin(), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update alert rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule deletes an alert rule and its alerts
+// @Summary Delete alert rule
+// @Tags alerts
+// @Security BearerAuth
+// @Param id path string true "Rule ID (UUID)"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/alerts/rules/{id} [delete]
+func (h *AlertHandler) DeleteRule(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	ruleID, ok := parseIDParam(c, "rule")
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.DeleteRule(c.Request.Context(), ruleID, userID, user.IsAdmin()); err != nil {
+		h.respondError(c, err, "Failed to delete alert rule")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListChannels lists the notification channels of the user
+// @Summary List notification channels
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.NotificationChannel
+// @Router /api/v1/alerts/channels [get]
+func (h *AlertHandler) ListChannels(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	channels, err := h.alertService.ListChannels(c.Request.Context(), userID, user.IsAdmin())
+	if err != nil {
+		h.respondError(c, err, "Failed to list notification channels")
+		return
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
+// CreateChannel creates a notification channel
+// @Summary Create notification channel
+// @Description Channel types: webhook (url, optional secret for HMAC signing), discord (url) and email (comma-separated recipients, requires SMTP).
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body alerts.CreateChannelRequest true "Channel data"
+// @Success 201 {object} models.NotificationChannel
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/alerts/channels [post]
+func (h *AlertHandler) CreateChannel(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+
+	var req alerts.CreateChannelRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	channel, err := h.alertService.CreateChannel(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create notification channel")
+		return
+	}
+
+	c.JSON(http.StatusCreated, channel)
+}
+
+// UpdateChannel updates a notification channel
+// @Summary Update notification channel
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Channel ID (UUID)"
+// @Param request body alerts.UpdateChannelRequest true "Fields to update"
+// @Success 200 {object} models.NotificationChannel
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/alerts/channels/{id} [put]
+func (h *AlertHandler) UpdateChannel(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	channelID, ok := parseIDParam(c, "channel")
+	if !ok {
+		return
+	}
+
+	var req alerts.UpdateChannelRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	channel, err := h.alertService.UpdateChannel(c.Request.Context(), channelID, userID, user.IsAdmin(), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update notification channel")
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+// DeleteChannel deletes a notification channel
+// @Summary Delete notification channel
+// @Tags alerts
+// @Security BearerAuth
+// @Param id path string true "Channel ID (UUID)"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/alerts/channels/{id} [delete]
+func (h *AlertHandler) DeleteChannel(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	channelID, ok := parseIDParam(c, "channel")
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.DeleteChannel(c.Request.Context(), channelID, userID, user.IsAdmin()); err != nil {
+		h.respondError(c, err, "Failed to delete notification channel")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// TestChannel sends a test notification through a channel
+// @Summary Test notification channel
+// @Description Delivers a test notification synchronously and reports the delivery error, if any.
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Channel ID (UUID)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /api/v1/alerts/channels/{id}/test [post]
+func (h *AlertHandler) TestChannel(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	channelID, ok := parseIDParam(c, "channel")
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.TestChannel(c.Request.Context(), channelID, userID, user.IsAdmin()); err != nil {
+		h.respondError(c, err, "Failed to test notification channel")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Test notification delivered",
+	})
+}
+
+// ListAlerts lists fired alerts, newest first
+// @Summary List alerts
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "firing or resolved"
+// @Param target_id query string false "Server or agent ID (UUID)"
+// @Param rule_id query string false "Rule ID (UUID)"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} alerts.AlertListResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/alerts [get]
+func (h *AlertHandler) ListAlerts(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	filter := alerts.AlertFilter{
+		Status: models.AlertStatus(c.Query("status")),
+	}
+	if filter.Status != "" && filter.Status != models.AlertStatusFiring && filter.Status != models.AlertStatusResolved {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid status",
+		})
+		return
+	}
+
+	for param, target := range map[string]**uuid.UUID{"target_id": &filter.TargetID, "rule_id": &filter.RuleID} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Invalid " + param,
+			})
+			return
+		}
+		*target = &id
+	}
+
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	filter.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	response, err := h.alertService.ListAlerts(c.Request.Context(), userID, user.IsAdmin(), filter)
+	if err != nil {
+		h.respondError(c, err, "Failed to list alerts")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AcknowledgeAlert acknowledges a firing alert, which stops its reminders
+// @Summary Acknowledge alert
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alert ID (UUID)"
+// @Success 200 {object} models.Alert
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/alerts/{id}/acknowledge [post]
+func (h *AlertHandler) AcknowledgeAlert(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	alertID, ok := parseIDParam(c, "alert")
+	if !ok {
+		return
+	}
+
+	alert, err := h.alertService.AcknowledgeAlert(c.Request.Context(), alertID, userID, user.IsAdmin())
+	if err != nil {
+		h.respondError(c, err, "Failed to acknowledge alert")
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+// bindAndValidate binds and validates a JSON request body. It writes a 400
+// response and returns false if either step fails.
+func (h *AlertHandler) bindAndValidate(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// parseIDParam parses the :id path parameter. It writes a 400 response and
+// returns false if it is not a UUID.
+func parseIDParam(c *gin.Context, resource string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid " + resource + " ID",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondError maps alert service errors to HTTP responses
+func (h *AlertHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, alerts.ErrRuleNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Alert rule not found"})
+	case errors.Is(err, alerts.ErrChannelNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Notification channel not found"})
+	case errors.Is(err, alerts.ErrAlertNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Alert not found"})
+	case errors.Is(err, alerts.ErrServerNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Server not found"})
+	case errors.Is(err, alerts.ErrAgentNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Agent not found"})
+	case errors.Is(err, alerts.ErrInvalidRule), errors.Is(err, alerts.ErrInvalidChannel):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, alerts.ErrAlertResolved):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Alert is already resolved"})
+	case errors.Is(err, alerts.ErrDeliveryFailed):
+		c.JSON(http.StatusBadGateway, ErrorResponse{
+			Error:   "Notification delivery failed",
+			Details: err.Error(),
+		})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: message,
+		})
+	}
+}
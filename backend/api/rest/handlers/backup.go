@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 
+	"github.com/aymc/backend/api/rest/middleware"
 	"github.com/aymc/backend/database/models"
 	"github.com/aymc/backend/services/backup"
 
@@ -163,7 +167,7 @@ func (h *BackupHandler) RestoreBackup(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Restauración de backup iniciada"})
+	c.JSON(http.StatusOK, gin.H{"message": "Backup restaurado exitosamente"})
 }
 
 // GetBackupConfig obtiene la configuración de backups de un servidor
@@ -273,6 +277,119 @@ func (h *BackupHandler) RunManualBackup(c *gin.Context) {
 	c.JSON(http.StatusCreated, backup)
 }
 
+// DownloadBackup descarga el archivo de un backup completado
+// GET /api/v1/backups/:backup_id/download
+func (h *BackupHandler) DownloadBackup(c *gin.Context) {
+	backupIDStr := c.Param("backup_id")
+	backupID, err := uuid.Parse(backupIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de backup inválido"})
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	download, err := h.backupService.OpenBackup(c.Request.Context(), backupID, userID, user.IsAdmin())
+	if err != nil {
+		switch {
+		case errors.Is(err, backup.ErrBackupNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Backup no encontrado"})
+		case errors.Is(err, backup.ErrBackupNotDownloadable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("Error opening backup", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	defer download.Close()
+
+	// Un backup puede pesar varios GB: la descarga dura lo que el cliente
+	liftDeadlines(c)
+
+	contentType := "application/x-tar"
+	if download.Compression == "gzip" {
+		contentType = "application/gzip"
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": download.Filename}))
+	if download.Size >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(download.Size, 10))
+	}
+	if download.Checksum != "" {
+		header.Set("X-Checksum-Sha256", download.Checksum)
+	}
+	c.Status(http.StatusOK)
+
+	if _, err := io.Copy(c.Writer, download); err != nil {
+		// Las cabeceras ya se enviaron; el cliente verá la conexión cortada
+		h.logger.Warn("Backup download interrupted",
+			zap.String("backup_id", backupID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// ImportBackup importa un archivo .tar.gz, .tgz o .tar como backup del
+// servidor. El archivo se envía en el campo "file" de un multipart/form-data.
+// POST /api/v1/servers/:id/backups/import
+func (h *BackupHandler) ImportBackup(c *gin.Context) {
+	serverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de servidor inválido"})
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	// Leer el cuerpo por partes para no cargar el archivo entero en memoria
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Se esperaba un cuerpo multipart/form-data"})
+		return
+	}
+
+	liftDeadlines(c)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Falta el campo \"file\""})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		imported, err := h.backupService.ImportBackup(c.Request.Context(), serverID, part.FileName(), part, userID, user.IsAdmin())
+		part.Close()
+		if err != nil {
+			switch {
+			case errors.Is(err, backup.ErrInvalidBackupArchive):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, backup.ErrServerNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Servidor no encontrado"})
+			default:
+				h.logger.Error("Error importing backup", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusCreated, imported)
+		return
+	}
+}
+
 // getUserIDFromContext extrae el ID del usuario del contexto
 func getUserIDFromContext(c *gin.Context) uuid.UUID {
 	// TODO: Implementar extracción real del JWT
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/aymc/backend/api/rest/middleware"
+	"github.com/aymc/backend/services/agents"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CreateEnrollmentTokenRequest cuerpo para crear un token de enrolamiento
+type CreateEnrollmentTokenRequest struct {
+	Description    string `json:"description" binding:"max=255"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"min=0"` // 0 = 24h
+}
+
+// CreateEnrollmentToken crea un token de enrolamiento de un solo uso
+// @Summary Create enrollment token
+// @Description Create a single-use token that a new agent redeems on first boot. The token is only shown in this response.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param request body CreateEnrollmentTokenRequest true "Token options"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/agents/enrollment-tokens [post]
+// @Security BearerAuth
+func (h *AgentHandler) CreateEnrollmentToken(c *gin.Context) {
+	var req CreateEnrollmentTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+	ttl := time.Duration(req.ExpiresInHours) * time.Hour
+
+	record, token, err := h.agentService.CreateEnrollmentToken(c.Request.Context(), userID, req.Description, ttl)
+	if err != nil {
+		h.logger.Error("Failed to create enrollment token", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":          record.ID,
+		"token":       token,
+		"description": record.Description,
+		"expires_at":  record.ExpiresAt,
+	})
+}
+
+// ListEnrollmentTokens lista los tokens de enrolamiento
+// @Summary List enrollment tokens
+// @Description List enrollment tokens and whether they have been used
+// @Tags agents
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/agents/enrollment-tokens [get]
+// @Security BearerAuth
+func (h *AgentHandler) ListEnrollmentTokens(c *gin.Context) {
+	tokens, err := h.agentService.ListEnrollmentTokens(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list enrollment tokens", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list enrollment tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tokens": tokens,
+		"total":  len(tokens),
+	})
+}
+
+// DeleteEnrollmentToken revoca un token de enrolamiento
+// @Summary Delete enrollment token
+// @Description Revoke an enrollment token so it can no longer be redeemed
+// @Tags agents
+// @Produce json
+// @Param token_id path string true "Token ID (UUID)"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/agents/enrollment-tokens/{token_id} [delete]
+// @Security BearerAuth
+func (h *AgentHandler) DeleteEnrollmentToken(c *gin.Context) {
+	tokenID, err := uuid.Parse(c.Param("token_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID format"})
+		return
+	}
+
+	if err := h.agentService.DeleteEnrollmentToken(c.Request.Context(), tokenID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Enrollment token deleted"})
+}
+
+// EnrollAgent canjea un token de enrolamiento (llamado por el agente)
+// @Summary Enroll agent
+// @Description Redeem a single-use enrollment token. Creates the agent and returns its identity, gRPC token and mTLS certificate.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param request body agents.EnrollmentRequest true "Agent information"
+// @Success 201 {object} agents.EnrollmentResult
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/agents/enroll [post]
+func (h *AgentHandler) EnrollAgent(c *gin.Context) {
+	var req agents.EnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	// Sin IP explícita se usa la dirección desde la que conecta el agente
+	if req.IPAddress == "" {
+		req.IPAddress = c.ClientIP()
+	}
+	if net.ParseIP(req.IPAddress) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid IP address"})
+		return
+	}
+
+	result, err := h.agentService.EnrollAgent(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, agents.ErrInvalidEnrollmentToken):
+			h.logger.Warn("Rejected enrollment", zap.String("client_ip", c.ClientIP()))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, agents.ErrAgentAlreadyExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("Failed to enroll agent", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
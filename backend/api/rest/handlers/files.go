@@ -0,0 +1,678 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aymc/backend/api/rest/middleware"
+	"github.com/aymc/backend/services/server"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// FileHandler handles the server file manager endpoints
+type FileHandler struct {
+	serverService *server.ServerService
+	logger        *zap.Logger
+}
+
+// NewFileHandler creates a new file handler
+func NewFileHandler(serverService *server.ServerService, logger *zap.Logger) *FileHandler {
+	return &FileHandler{
+		serverService: serverService,
+		logger:        logger,
+	}
+}
+
+// fileContext extracts the caller and the server ID from the request
+func (h *FileHandler) fileContext(c *gin.Context) (serverID, userID uuid.UUID, isAdmin bool, ok bool) {
+	userID = middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+	isAdmin = user.IsAdmin()
+
+	serverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid server ID",
+		})
+		return uuid.Nil, uuid.Nil, false, false
+	}
+	return serverID, userID, isAdmin, true
+}
+
+// bindFileRequest binds a JSON body, writing the 400 response on failure
+func bindFileRequest(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Details: err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// respondFileError maps file manager errors to HTTP responses
+func (h *FileHandler) respondFileError(c *gin.Context, err error, action string) {
+	var fileErr *server.FileError
+	details := ""
+	if errors.As(err, &fileErr) {
+		details = fileErr.Message
+	}
+
+	switch {
+	case errors.Is(err, server.ErrServerNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Server not found"})
+	case errors.Is(err, server.ErrAgentOffline):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Agent is offline"})
+	case errors.Is(err, server.ErrFileNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found", Details: details})
+	case errors.Is(err, server.ErrFileAccessDenied):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Path is outside the server directory", Details: details})
+	case errors.Is(err, server.ErrFileAlreadyExists):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "File already exists", Details: details})
+	case errors.Is(err, server.ErrInvalidFileRequest):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid file request", Details: details})
+	case errors.Is(err, server.ErrChecksumMismatch):
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: "Checksum mismatch", Details: details})
+	case errors.Is(err, server.ErrRangeNotSatisfiable):
+		c.JSON(http.StatusRequestedRangeNotSatisfiable, ErrorResponse{Error: "Range not satisfiable", Details: details})
+	default:
+		h.logger.Error("Failed to "+action, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Failed to " + action,
+		})
+	}
+}
+
+// List lists a directory of the server
+// @Summary List server files
+// @Tags files
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param path query string false "Directory relative to the server root" default(.)
+// @Param recursive query bool false "List subdirectories"
+// @Success 200 {array} server.FileEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files [get]
+func (h *FileHandler) List(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	filePath := c.DefaultQuery("path", ".")
+	recursive, _ := strconv.ParseBool(c.Query("recursive"))
+
+	files, err := h.serverService.ListFiles(serverID, userID, isAdmin, filePath, recursive)
+	if err != nil {
+		h.respondFileError(c, err, "list files")
+		return
+	}
+
+	c.JSON(http.StatusOK, files)
+}
+
+// Read returns the content of a text file
+// @Summary Read server file
+// @Tags files
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param path query string true "File relative to the server root"
+// @Success 200 {object} server.FileContentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/content [get]
+func (h *FileHandler) Read(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	filePath := c.Query("path")
+	if filePath == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "path is required",
+		})
+		return
+	}
+
+	content, err := h.serverService.ReadFile(serverID, userID, isAdmin, filePath)
+	if err != nil {
+		h.respondFileError(c, err, "read file")
+		return
+	}
+
+	c.JSON(http.StatusOK, content)
+}
+
+// Write writes a text file
+// @Summary Write server file
+// @Tags files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param request body server.WriteFileRequest true "File content"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/content [put]
+func (h *FileHandler) Write(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	var req server.WriteFileRequest
+	if !bindFileRequest(c, &req) {
+		return
+	}
+
+	if err := h.serverService.WriteFile(serverID, userID, isAdmin, &req); err != nil {
+		h.respondFileError(c, err, "write file")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "File written successfully",
+	})
+}
+
+// Delete deletes a file or directory
+// @Summary Delete server file
+// @Tags files
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param path query string true "File or directory relative to the server root"
+// @Param recursive query bool false "Delete non-empty directories"
+// @Success 200 {object} server.FileOperationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files [delete]
+func (h *FileHandler) Delete(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	filePath := c.Query("path")
+	if filePath == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "path is required",
+		})
+		return
+	}
+	recursive, _ := strconv.ParseBool(c.Query("recursive"))
+
+	resp, err := h.serverService.DeleteFile(serverID, userID, isAdmin, filePath, recursive)
+	if err != nil {
+		h.respondFileError(c, err, "delete file")
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Move moves or renames a file or directory
+// @Summary Move or rename server file
+// @Tags files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param request body server.MoveFileRequest true "Source and destination"
+// @Success 200 {object} server.FileOperationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/move [post]
+func (h *FileHandler) Move(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	var req server.MoveFileRequest
+	if !bindFileRequest(c, &req) {
+		return
+	}
+
+	resp, err := h.serverService.MoveFile(serverID, userID, isAdmin, &req)
+	if err != nil {
+		h.respondFileError(c, err, "move file")
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Copy copies a file or directory
+// @Summary Copy server file
+// @Tags files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param request body server.MoveFileRequest true "Source and destination"
+// @Success 200 {object} server.FileOperationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/copy [post]
+func (h *FileHandler) Copy(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	var req server.MoveFileRequest
+	if !bindFileRequest(c, &req) {
+		return
+	}
+
+	resp, err := h.serverService.CopyFile(serverID, userID, isAdmin, &req)
+	if err != nil {
+		h.respondFileError(c, err, "copy file")
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Mkdir creates a directory
+// @Summary Create server directory
+// @Tags files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param request body server.CreateDirectoryRequest true "Directory"
+// @Success 200 {object} server.FileOperationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/mkdir [post]
+func (h *FileHandler) Mkdir(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	var req server.CreateDirectoryRequest
+	if !bindFileRequest(c, &req) {
+		return
+	}
+
+	resp, err := h.serverService.CreateDirectory(serverID, userID, isAdmin, &req)
+	if err != nil {
+		h.respondFileError(c, err, "create directory")
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Chmod changes unix permissions
+// @Summary Change server file permissions
+// @Tags files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param request body server.ChangePermissionsRequest true "Path and octal mode"
+// @Success 200 {object} server.FileOperationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/chmod [post]
+func (h *FileHandler) Chmod(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	var req server.ChangePermissionsRequest
+	if !bindFileRequest(c, &req) {
+		return
+	}
+
+	mode, err := strconv.ParseUint(req.Mode, 8, 32)
+	if err != nil || mode > 0777 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid mode",
+			Details: "mode must be an octal value between 000 and 777",
+		})
+		return
+	}
+
+	resp, err := h.serverService.ChangePermissions(serverID, userID, isAdmin, &req, uint32(mode))
+	if err != nil {
+		h.respondFileError(c, err, "change permissions")
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Compress archives a selection of files
+// @Summary Compress server files
+// @Tags files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param request body server.CompressFilesRequest true "Files and destination archive"
+// @Success 200 {object} server.FileOperationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/compress [post]
+func (h *FileHandler) Compress(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	var req server.CompressFilesRequest
+	if !bindFileRequest(c, &req) {
+		return
+	}
+
+	resp, err := h.serverService.CompressFiles(serverID, userID, isAdmin, &req)
+	if err != nil {
+		h.respondFileError(c, err, "compress files")
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Extract extracts an archive
+// @Summary Extract server archive
+// @Tags files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param request body server.ExtractArchiveRequest true "Archive and destination directory"
+// @Success 200 {object} server.FileOperationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/extract [post]
+func (h *FileHandler) Extract(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	var req server.ExtractArchiveRequest
+	if !bindFileRequest(c, &req) {
+		return
+	}
+
+	resp, err := h.serverService.ExtractArchive(serverID, userID, isAdmin, &req)
+	if err != nil {
+		h.respondFileError(c, err, "extract archive")
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// liftDeadlines removes the HTTP server read/write timeouts for a transfer
+// that may legitimately take longer than a regular request
+func liftDeadlines(c *gin.Context) {
+	rc := http.NewResponseController(c.Writer)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+}
+
+// parseByteRange parses a single "bytes=" Range header into an agent
+// offset/length pair. Unsupported or malformed ranges return ok=false and
+// the whole file is served, as RFC 9110 allows.
+func parseByteRange(header string) (offset, length int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	if first == "" {
+		// Suffix range: the last N bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		return -n, 0, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+	if last == "" {
+		return start, 0, true
+	}
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || end < start {
+		return 0, 0, false
+	}
+	return start, end - start + 1, true
+}
+
+// Upload streams a multipart upload to the server directory
+// @Summary Upload server file
+// @Description Streams the "file" part of a multipart body to the agent in chunks. Interrupted uploads can be resumed from the offset returned by GET /files/upload.
+// @Tags files
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param path query string true "Destination file relative to the server root"
+// @Param offset query int false "Resume offset" default(0)
+// @Param sha256 query string false "Expected SHA256 of the complete file"
+// @Param create_dirs query bool false "Create missing parent directories"
+// @Param file formData file true "File content"
+// @Success 200 {object} server.FileTransferResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/upload [post]
+func (h *FileHandler) Upload(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	req := server.UploadFileRequest{
+		Path:     c.Query("path"),
+		Checksum: c.Query("sha256"),
+	}
+	if req.Path == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "path is required",
+		})
+		return
+	}
+	if v := c.Query("offset"); v != "" {
+		offset, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Invalid offset",
+			})
+			return
+		}
+		req.Offset = offset
+	}
+	req.CreateDirs, _ = strconv.ParseBool(c.Query("create_dirs"))
+
+	// Read the body part by part instead of ParseMultipartForm, which would
+	// buffer the whole file before the first byte reaches the agent
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Expected a multipart/form-data body",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	liftDeadlines(c)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Missing \"file\" field",
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid multipart body",
+				Details: err.Error(),
+			})
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		resp, err := h.serverService.UploadFile(c.Request.Context(), serverID, userID, isAdmin, &req, part)
+		part.Close()
+		if err != nil {
+			h.respondFileError(c, err, "upload file")
+			return
+		}
+
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+}
+
+// GetUploadOffset returns the resume offset of an interrupted upload
+// @Summary Get upload resume offset
+// @Tags files
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param path query string true "Destination file relative to the server root"
+// @Success 200 {object} server.UploadOffsetResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/upload [get]
+func (h *FileHandler) GetUploadOffset(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	filePath := c.Query("path")
+	if filePath == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "path is required",
+		})
+		return
+	}
+
+	resp, err := h.serverService.GetUploadOffset(serverID, userID, isAdmin, filePath)
+	if err != nil {
+		h.respondFileError(c, err, "get upload offset")
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Download streams a file from the server directory
+// @Summary Download server file
+// @Description Streams the file from the agent without buffering it. Supports a single "bytes=" Range.
+// @Tags files
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param path query string true "File relative to the server root"
+// @Param Range header string false "Byte range, e.g. bytes=0-1048575"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 416 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/files/download [get]
+func (h *FileHandler) Download(c *gin.Context) {
+	serverID, userID, isAdmin, ok := h.fileContext(c)
+	if !ok {
+		return
+	}
+
+	filePath := c.Query("path")
+	if filePath == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "path is required",
+		})
+		return
+	}
+
+	offset, length, partial := parseByteRange(c.GetHeader("Range"))
+
+	download, err := h.serverService.DownloadFile(c.Request.Context(), serverID, userID, isAdmin, filePath, offset, length)
+	if err != nil {
+		h.respondFileError(c, err, "download file")
+		return
+	}
+	defer download.Close()
+
+	liftDeadlines(c)
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(filePath)}))
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("Content-Length", strconv.FormatInt(download.Length, 10))
+	header.Set("Last-Modified", time.Unix(download.ModifiedTime, 0).UTC().Format(http.TimeFormat))
+
+	statusCode := http.StatusOK
+	if partial && download.Length > 0 {
+		statusCode = http.StatusPartialContent
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d",
+			download.Offset, download.Offset+download.Length-1, download.TotalSize))
+	}
+	c.Status(statusCode)
+
+	if _, err := io.Copy(c.Writer, download); err != nil {
+		// Headers are already sent; the client sees a short body
+		h.logger.Warn("File download interrupted",
+			zap.String("server_id", serverID.String()),
+			zap.String("path", filePath),
+			zap.Error(err),
+		)
+	}
+}
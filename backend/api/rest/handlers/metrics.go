@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aymc/backend/api/rest/middleware"
+	"github.com/aymc/backend/services/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// MetricsHandler handles metrics history endpoints
+type MetricsHandler struct {
+	metricsService *metrics.Service
+	logger         *zap.Logger
+}
+
+// NewMetricsHandler creates a new metrics history handler
+func NewMetricsHandler(metricsService *metrics.Service, logger *zap.Logger) *MetricsHandler {
+	return &MetricsHandler{
+		metricsService: metricsService,
+		logger:         logger,
+	}
+}
+
+// ServerHistory returns the metrics history of a server
+// @Summary Get server metrics history
+// @Description Time series of process and game metrics. Resolution is raw, 1m, 5m, 1h or auto (default).
+// @Tags servers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param metrics query string false "Comma-separated metric names (default: all)"
+// @Param from query string false "Range start, RFC 3339 (default: 1 hour before to)"
+// @Param to query string false "Range end, RFC 3339 (default: now)"
+// @Param resolution query string false "raw, 1m, 5m, 1h or auto"
+// @Success 200 {object} metrics.HistoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/metrics/history [get]
+func (h *MetricsHandler) ServerHistory(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	serverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid server ID",
+		})
+		return
+	}
+
+	query, ok := h.parseHistoryQuery(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.metricsService.ServerHistory(c.Request.Context(), serverID, userID, user.IsAdmin(), query)
+	if err != nil {
+		h.historyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// AgentHistory returns the system metrics history of an agent
+// @Summary Get agent metrics history
+// @Description Time series of host CPU, memory and disk usage. Resolution is raw, 1m, 5m, 1h or auto (default).
+// @Tags agents
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Agent ID (UUID)"
+// @Param metrics query string false "Comma-separated metric names (default: all)"
+// @Param from query string false "Range start, RFC 3339 (default: 1 hour before to)"
+// @Param to query string false "Range end, RFC 3339 (default: now)"
+// @Param resolution query string false "raw, 1m, 5m, 1h or auto"
+// @Success 200 {object} metrics.HistoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/agents/{id}/metrics/history [get]
+func (h *MetricsHandler) AgentHistory(c *gin.Context) {
+	agentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid agent ID",
+		})
+		return
+	}
+
+	query, ok := h.parseHistoryQuery(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.metricsService.AgentHistory(c.Request.Context(), agentID, query)
+	if err != nil {
+		h.historyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// parseHistoryQuery reads the range, metrics and resolution query parameters.
+// It writes a 400 response and returns false if any is malformed.
+func (h *MetricsHandler) parseHistoryQuery(c *gin.Context) (metrics.HistoryQuery, bool) {
+	query := metrics.HistoryQuery{
+		Resolution: metrics.Resolution(c.Query("resolution")),
+	}
+
+	if names := c.Query("metrics"); names != "" {
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				query.Metrics = append(query.Metrics, name)
+			}
+		}
+	}
+
+	if !parseTimestampParam(c, "from", &query.From) || !parseTimestampParam(c, "to", &query.To) {
+		return query, false
+	}
+
+	return query, true
+}
+
+// parseTimestampParam parses an optional RFC 3339 query parameter into
+// target. It writes a 400 response and returns false if it is malformed.
+func parseTimestampParam(c *gin.Context, param string, target *time.Time) bool {
+	value := c.Query(param)
+	if value == "" {
+		return true
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid " + param + " timestamp",
+			Details: "Use RFC 3339, e.g. 2024-01-15T10:00:00Z",
+		})
+		return false
+	}
+	*target = parsed
+	return true
+}
+
+// historyError maps metrics history errors to HTTP responses
+func (h *MetricsHandler) historyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, metrics.ErrServerNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: "Server not found",
+		})
+	case errors.Is(err, metrics.ErrAgentNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: "Agent not found",
+		})
+	case errors.Is(err, metrics.ErrInvalidMetric),
+		errors.Is(err, metrics.ErrInvalidResolution),
+		errors.Is(err, metrics.ErrInvalidRange),
+		errors.Is(err, metrics.ErrTooManyPoints):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+	default:
+		h.logger.Error("Failed to get metrics history", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to retrieve metrics history",
+			Details: err.Error(),
+		})
+	}
+}
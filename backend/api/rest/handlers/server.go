@@ -466,3 +466,56 @@ func (h *ServerHandler) GetStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, status)
 }
+
+// GetMetrics retrieves live resource usage of the server process
+// @Summary Get server process metrics
+// @Description CPU, RSS, threads, open files and disk usage of the server process and its children
+// @Tags servers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Success 200 {object} server.ServerMetricsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/metrics [get]
+func (h *ServerHandler) GetMetrics(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+	isAdmin := user.IsAdmin()
+
+	serverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid server ID",
+		})
+		return
+	}
+
+	metrics, err := h.serverService.GetMetrics(c.Request.Context(), serverID, userID, isAdmin)
+	if err != nil {
+		switch {
+		case errors.Is(err, server.ErrServerNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: "Server not found",
+			})
+		case errors.Is(err, server.ErrAgentOffline):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Agent is offline",
+			})
+		case errors.Is(err, server.ErrServerNotRunning):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error: "Server is not running",
+			})
+		default:
+			h.logger.Error("Failed to get server metrics", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Failed to retrieve server metrics",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
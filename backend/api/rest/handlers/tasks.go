@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aymc/backend/api/rest/middleware"
+	"github.com/aymc/backend/services/tasks"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TaskHandler handles scheduled task endpoints
+type TaskHandler struct {
+	taskService *tasks.Service
+	validator   *validator.Validate
+	logger      *zap.Logger
+}
+
+// NewTaskHandler creates a new scheduled task handler
+func NewTaskHandler(taskService *tasks.Service, logger *zap.Logger) *TaskHandler {
+	return &TaskHandler{
+		taskService: taskService,
+		validator:   validator.New(),
+		logger:      logger,
+	}
+}
+
+// ListTasks lists the scheduled tasks of a server
+// @Summary List scheduled tasks
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Success 200 {array} models.ScheduledTask
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/tasks [get]
+func (h *TaskHandler) ListTasks(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	serverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid server ID",
+		})
+		return
+	}
+
+	list, err := h.taskService.ListTasks(c.Request.Context(), serverID, userID, user.IsAdmin())
+	if err != nil {
+		h.respondError(c, err, "Failed to list scheduled tasks")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// CreateTask creates a scheduled task for a server
+// @Summary Create scheduled task
+// @Description Actions run in order: command, wait, restart, backup, stop, start. Restart and stop accept in-game countdown warnings.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Server ID (UUID)"
+// @Param request body tasks.CreateTaskRequest true "Task data"
+// @Success 201 {object} models.ScheduledTask
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/servers/{id}/tasks [post]
+func (h *TaskHandler) CreateTask(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	serverID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid server ID",
+		})
+		return
+	}
+
+	var req tasks.CreateTaskRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	task, err := h.taskService.CreateTask(c.Request.Context(), serverID, userID, user.IsAdmin(), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create scheduled task")
+		return
+	}
+
+	h.logger.Info("Scheduled task created",
+		zap.String("task_id", task.ID.String()),
+		zap.String("server_id", serverID.String()),
+		zap.String("schedule", task.Schedule),
+	)
+
+	c.JSON(http.StatusCreated, task)
+}
+
+// GetTask retrieves a scheduled task by ID
+// @Summary Get scheduled task
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param task_id path string true "Task ID (UUID)"
+// @Success 200 {object} models.ScheduledTask
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/tasks/{task_id} [get]
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	taskID, ok := parseTaskID(c)
+	if !ok {
+		return
+	}
+
+	task, err := h.taskService.GetTask(c.Request.Context(), taskID, userID, user.IsAdmin())
+	if err != nil {
+		h.respondError(c, err, "Failed to retrieve scheduled task")
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// UpdateTask updates a scheduled task
+// @Summary Update scheduled task
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param task_id path string true "Task ID (UUID)"
+// @Param request body tasks.UpdateTaskRequest true "Fields to update"
+// @Success 200 {object} models.ScheduledTask
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/tasks/{task_id} [put]
+func (h *TaskHandler) UpdateTask(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	taskID, ok := parseTaskID(c)
+	if !ok {
+		return
+	}
+
+	var req tasks.UpdateTaskRequest
+	if !h.bindAndValidate(c, &req) {
+		return
+	}
+
+	task, err := h.taskService.UpdateTask(c.Request.Context(), taskID, userID, user.IsAdmin(), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update scheduled task")
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// DeleteTask deletes a scheduled task and its run history
+// @Summary Delete scheduled task
+// @Tags tasks
+// @Security BearerAuth
+// @Param task_id path string true "Task ID (UUID)"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/tasks/{task_id} [delete]
+func (h *TaskHandler) DeleteTask(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	taskID, ok := parseTaskID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.taskService.DeleteTask(c.Request.Context(), taskID, userID, user.IsAdmin()); err != nil {
+		h.respondError(c, err, "Failed to delete scheduled task")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunTask runs a scheduled task immediately
+// @Summary Run scheduled task now
+// @Description Starts a run in the background and returns it. Follow its progress in the run history.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param task_id path string true "Task ID (UUID)"
+// @Success 202 {object} models.ScheduledTaskRun
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/tasks/{task_id}/run [post]
+func (h *TaskHandler) RunTask(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	taskID, ok := parseTaskID(c)
+	if !ok {
+		return
+	}
+
+	run, err := h.taskService.RunTask(c.Request.Context(), taskID, userID, user.IsAdmin())
+	if err != nil {
+		h.respondError(c, err, "Failed to run scheduled task")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// ListRuns lists the run history of a scheduled task
+// @Summary List scheduled task runs
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param task_id path string true "Task ID (UUID)"
+// @Param limit query int false "Page size (default 20, max 50)"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} tasks.TaskRunListResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/tasks/{task_id}/runs [get]
+func (h *TaskHandler) ListRuns(c *gin.Context) {
+	userID := middleware.MustGetUserID(c)
+	user := middleware.MustGetUser(c)
+
+	taskID, ok := parseTaskID(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	runs, err := h.taskService.ListRuns(c.Request.Context(), taskID, userID, user.IsAdmin(), limit, offset)
+	if err != nil {
+		h.respondError(c, err, "Failed to list task runs")
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// bindAndValidate binds and validates a JSON request body. It writes a 400
+// response and returns false if either step fails.
+func (h *TaskHandler) bindAndValidate(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Details: err.Error(),
+		})
+		return false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Details: err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+// parseTaskID parses the :task_id path parameter. It writes a 400 response
+// and returns false if it is not a UUID.
+func parseTaskID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid task ID",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondError maps scheduled task errors to HTTP responses
+func (h *TaskHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, tasks.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Scheduled task not found"})
+	case errors.Is(err, tasks.ErrServerNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Server not found"})
+	case errors.Is(err, tasks.ErrInvalidTask), errors.Is(err, tasks.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, tasks.ErrTaskRunning):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Scheduled task is already running"})
+	default:
+		h.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: message,
+		})
+	}
+}
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"time"
@@ -10,11 +11,15 @@ import (
 	"github.com/aymc/backend/api/rest/middleware"
 	"github.com/aymc/backend/api/websocket"
 	"github.com/aymc/backend/config"
+	"github.com/aymc/backend/pkg/telemetry"
 	"github.com/aymc/backend/services/agents"
+	"github.com/aymc/backend/services/alerts"
 	"github.com/aymc/backend/services/auth"
 	"github.com/aymc/backend/services/backup"
 	"github.com/aymc/backend/services/marketplace"
+	"github.com/aymc/backend/services/metrics"
 	"github.com/aymc/backend/services/server"
+	"github.com/aymc/backend/services/tasks"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -29,13 +34,17 @@ type Server struct {
 	agentHandler      *handlers.AgentHandler
 	marketplaceHandler *handlers.MarketplaceHandler
 	backupHandler     *handlers.BackupHandler
+	fileHandler       *handlers.FileHandler
+	metricsHandler    *handlers.MetricsHandler
+	alertHandler      *handlers.AlertHandler
+	taskHandler       *handlers.TaskHandler
 	wsHandler         *websocket.Handler
 	jwtService        *auth.JWTService
 	logger            *zap.Logger
 }
 
 // NewServer creates a new REST API server
-func NewServer(cfg *config.Config, jwtService *auth.JWTService, authService *auth.AuthService, serverService *server.ServerService, agentService *agents.AgentService, marketplaceService *marketplace.Service, backupService *backup.Service, backupScheduler *backup.Scheduler, wsHub *websocket.Hub, logger *zap.Logger) *Server {
+func NewServer(cfg *config.Config, jwtService *auth.JWTService, authService *auth.AuthService, serverService *server.ServerService, agentService *agents.AgentService, marketplaceService *marketplace.Service, backupService *backup.Service, backupScheduler *backup.Scheduler, metricsService *metrics.Service, alertService *alerts.Service, taskService *tasks.Service, wsHub *websocket.Hub, logger *zap.Logger) *Server {
 	// Set Gin mode based on environment
 	if cfg.Server.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -51,6 +60,10 @@ func NewServer(cfg *config.Config, jwtService *auth.JWTService, authService *aut
 	agentHandler := handlers.NewAgentHandler(agentService, logger)
 	marketplaceHandler := handlers.NewMarketplaceHandler(marketplaceService, logger)
 	backupHandler := handlers.NewBackupHandler(backupService, backupScheduler, logger)
+	fileHandler := handlers.NewFileHandler(serverService, logger)
+	metricsHandler := handlers.NewMetricsHandler(metricsService, logger)
+	alertHandler := handlers.NewAlertHandler(alertService, logger)
+	taskHandler := handlers.NewTaskHandler(taskService, logger)
 	wsHandler := websocket.NewHandler(wsHub, jwtService, logger)
 
 	server := &Server{
@@ -61,6 +74,10 @@ func NewServer(cfg *config.Config, jwtService *auth.JWTService, authService *aut
 		agentHandler:      agentHandler,
 		marketplaceHandler: marketplaceHandler,
 		backupHandler:     backupHandler,
+		fileHandler:       fileHandler,
+		metricsHandler:    metricsHandler,
+		alertHandler:      alertHandler,
+		taskHandler:       taskHandler,
 		wsHandler:         wsHandler,
 		jwtService:        jwtService,
 		logger:            logger,
@@ -94,6 +111,11 @@ func (s *Server) setupRoutes() {
 	s.router.GET("/health", s.healthCheck)
 	s.router.GET("/", s.welcome)
 
+	// Prometheus/OpenMetrics scrape endpoint
+	if s.config.Metrics.ExporterEnabled {
+		s.router.GET("/metrics", s.metricsAuthMiddleware(), gin.WrapH(telemetry.Handler()))
+	}
+
 	// WebSocket endpoint (authentication handled in handler)
 	s.router.GET("/api/v1/ws", s.wsHandler.HandleWebSocket)
 
@@ -108,6 +130,9 @@ func (s *Server) setupRoutes() {
 			authPublic.POST("/refresh", s.authHandler.RefreshToken)
 		}
 
+		// Agent enrollment (authenticated by the single-use enrollment token)
+		v1.POST("/agents/enroll", s.agentHandler.EnrollAgent)
+
 		// Protected auth routes (require authentication)
 		authProtected := v1.Group("/auth")
 		authProtected.Use(middleware.AuthMiddleware(s.jwtService, s.logger))
@@ -135,6 +160,23 @@ func (s *Server) setupRoutes() {
 				servers.POST("/:id/stop", s.serverHandler.Stop)
 				servers.POST("/:id/restart", s.serverHandler.Restart)
 				servers.GET("/:id/status", s.serverHandler.GetStatus)
+				servers.GET("/:id/metrics", s.serverHandler.GetMetrics)
+				servers.GET("/:id/metrics/history", s.metricsHandler.ServerHistory)
+
+				// Server file manager routes
+				servers.GET("/:id/files", s.fileHandler.List)
+				servers.DELETE("/:id/files", s.fileHandler.Delete)
+				servers.GET("/:id/files/content", s.fileHandler.Read)
+				servers.PUT("/:id/files/content", s.fileHandler.Write)
+				servers.POST("/:id/files/move", s.fileHandler.Move)
+				servers.POST("/:id/files/copy", s.fileHandler.Copy)
+				servers.POST("/:id/files/mkdir", s.fileHandler.Mkdir)
+				servers.POST("/:id/files/chmod", s.fileHandler.Chmod)
+				servers.POST("/:id/files/compress", s.fileHandler.Compress)
+				servers.POST("/:id/files/extract", s.fileHandler.Extract)
+				servers.GET("/:id/files/download", s.fileHandler.Download)
+				servers.POST("/:id/files/upload", s.fileHandler.Upload)
+				servers.GET("/:id/files/upload", s.fileHandler.GetUploadOffset)
 			}
 
 			// Agent management routes
@@ -145,6 +187,13 @@ func (s *Server) setupRoutes() {
 				agents.GET("/:id", s.agentHandler.GetAgent)
 				agents.GET("/:id/health", s.agentHandler.GetAgentHealth)
 				agents.GET("/:id/metrics", s.agentHandler.GetAgentMetrics)
+				agents.GET("/:id/metrics/history", s.metricsHandler.AgentHistory)
+				agents.POST("/enrollment-tokens", middleware.RequireAdmin(), s.agentHandler.CreateEnrollmentToken)
+				agents.GET("/enrollment-tokens", middleware.RequireAdmin(), s.agentHandler.ListEnrollmentTokens)
+				agents.DELETE("/enrollment-tokens/:token_id", middleware.RequireAdmin(), s.agentHandler.DeleteEnrollmentToken)
+				agents.POST("/:id/pair", middleware.RequireAdmin(), s.agentHandler.PairAgent)
+				agents.POST("/:id/certificate/rotate", middleware.RequireAdmin(), s.agentHandler.RotateAgentCertificate)
+				agents.POST("/:id/certificate/revoke", middleware.RequireAdmin(), s.agentHandler.RevokeAgentCertificate)
 			}
 
 			// Marketplace routes
@@ -171,16 +220,53 @@ func (s *Server) setupRoutes() {
 				backups.GET("/:backup_id", s.backupHandler.GetBackup)
 				backups.DELETE("/:backup_id", s.backupHandler.DeleteBackup)
 				backups.POST("/:backup_id/restore", s.backupHandler.RestoreBackup)
+				backups.GET("/:backup_id/download", s.backupHandler.DownloadBackup)
 			}
 
 			// Server backup management
 			servers.GET("/:id/backups", s.backupHandler.ListBackups)
 			servers.POST("/:id/backups", s.backupHandler.CreateBackup)
 			servers.POST("/:id/backups/manual", s.backupHandler.RunManualBackup)
+			servers.POST("/:id/backups/import", s.backupHandler.ImportBackup)
 			servers.GET("/:id/backup-config", s.backupHandler.GetBackupConfig)
 			servers.PUT("/:id/backup-config", s.backupHandler.UpdateBackupConfig)
 			servers.GET("/:id/backup-stats", s.backupHandler.GetBackupStats)
 
+			// Scheduled task routes
+			taskRoutes := api.Group("/tasks")
+			{
+				taskRoutes.GET("/:task_id", s.taskHandler.GetTask)
+				taskRoutes.PUT("/:task_id", s.taskHandler.UpdateTask)
+				taskRoutes.DELETE("/:task_id", s.taskHandler.DeleteTask)
+				taskRoutes.POST("/:task_id/run", s.taskHandler.RunTask)
+				taskRoutes.GET("/:task_id/runs", s.taskHandler.ListRuns)
+			}
+
+			// Server scheduled tasks
+			servers.GET("/:id/tasks", s.taskHandler.ListTasks)
+			servers.POST("/:id/tasks", s.taskHandler.CreateTask)
+
+			// Alert routes
+			alertRoutes := api.Group("/alerts")
+			{
+				alertRoutes.GET("", s.alertHandler.ListAlerts)
+				alertRoutes.POST("/:id/acknowledge", s.alertHandler.AcknowledgeAlert)
+
+				// Alert rules
+				alertRoutes.GET("/rules", s.alertHandler.ListRules)
+				alertRoutes.POST("/rules", s.alertHandler.CreateRule)
+				alertRoutes.GET("/rules/:id", s.alertHandler.GetRule)
+				alertRoutes.PUT("/rules/:id", s.alertHandler.UpdateRule)
+				alertRoutes.DELETE("/rules/:id", s.alertHandler.DeleteRule)
+
+				// Notification channels
+				alertRoutes.GET("/channels", s.alertHandler.ListChannels)
+				alertRoutes.POST("/channels", s.alertHandler.CreateChannel)
+				alertRoutes.PUT("/channels/:id", s.alertHandler.UpdateChannel)
+				alertRoutes.DELETE("/channels/:id", s.alertHandler.DeleteChannel)
+				alertRoutes.POST("/channels/:id/test", s.alertHandler.TestChannel)
+			}
+
 			// Protected example endpoint
 			api.GET("/protected", func(c *gin.Context) {
 				user := middleware.MustGetUser(c)
@@ -320,6 +406,23 @@ func (s *Server) requestIDMiddleware() gin.HandlerFunc {
 	}
 }
 
+// metricsAuthMiddleware requires the configured bearer token on /metrics.
+// Without a token the endpoint is public, as usual for Prometheus targets.
+func (s *Server) metricsAuthMiddleware() gin.HandlerFunc {
+	expected := []byte("Bearer " + s.config.Metrics.ExporterToken)
+	return func(c *gin.Context) {
+		if s.config.Metrics.ExporterToken == "" {
+			c.Next()
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), expected) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid metrics token"})
+			return
+		}
+		c.Next()
+	}
+}
+
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	addr := fmt.Sprintf("%s:%s", s.config.Server.Host, s.config.Server.Port)
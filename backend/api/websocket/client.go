@@ -2,8 +2,10 @@ package websocket
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
+	"github.com/aymc/backend/database"
 	"github.com/aymc/backend/database/models"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
@@ -179,21 +181,67 @@ func (c *Client) handleSubscribe(data json.RawMessage) {
 		return
 	}
 
-	// TODO: Validar permisos del usuario para suscribirse a cada canal
-	// Por ahora, permitir todas las suscripciones
-
+	// Los canales no permitidos se rechazan antes de suscribir, así que
+	// tampoco llegan a activar observadores como el relay de logs
+	allowed := make([]string, 0, len(subMsg.Channels))
 	for _, channel := range subMsg.Channels {
+		if !c.canSubscribe(channel) {
+			c.logger.Warn("Subscription denied",
+				zap.String("user_id", c.user.ID.String()),
+				zap.String("channel", channel),
+			)
+			c.sendError("FORBIDDEN", "Not allowed to subscribe to channel", channel)
+			continue
+		}
 		c.hub.subscribeToChannel(c, channel)
+		allowed = append(allowed, channel)
+	}
+
+	if len(allowed) == 0 {
+		return
 	}
 
 	c.logger.Info("Client subscribed to channels",
 		zap.String("user_id", c.user.ID.String()),
-		zap.Strings("channels", subMsg.Channels),
+		zap.Strings("channels", allowed),
 		zap.Int("total_subscriptions", len(c.subscriptions)),
 	)
 
 	// Enviar confirmación
-	c.sendSuccess("SUBSCRIBED", "Successfully subscribed to channels", subMsg.Channels)
+	c.sendSuccess("SUBSCRIBED", "Successfully subscribed to channels", allowed)
+}
+
+// canSubscribe indica si el usuario puede suscribirse a un canal. Los
+// canales de un servidor son de su dueño y los de notificaciones, del propio
+// usuario; los administradores pueden suscribirse a todos.
+func (c *Client) canSubscribe(channel string) bool {
+	if c.user.IsAdmin() {
+		return true
+	}
+
+	if serverID, _, ok := ParseServerChannel(channel); ok {
+		var count int64
+		err := database.GetDB().Model(&models.Server{}).
+			Where("id = ? AND user_id = ?", serverID, c.user.ID).
+			Count(&count).Error
+		if err != nil {
+			c.logger.Error("Failed to check server ownership",
+				zap.String("channel", channel),
+				zap.Error(err),
+			)
+			return false
+		}
+		return count > 0
+	}
+
+	if strings.HasPrefix(channel, "server:") {
+		return false // server:<id inválido>:...
+	}
+	if strings.HasPrefix(channel, "user:") {
+		return channel == BuildUserChannel(c.user.ID)
+	}
+
+	return true
 }
 
 // handleUnsubscribe maneja solicitud de cancelación de suscripción
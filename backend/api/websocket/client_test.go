@@ -0,0 +1,76 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aymc/backend/database"
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// useSubscribeTestDB sustituye la base de datos global por SQLite en memoria
+// con un servidor de owner
+func useSubscribeTestDB(t *testing.T, owner uuid.UUID) uuid.UUID {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec(`CREATE TABLE servers (id TEXT PRIMARY KEY, user_id TEXT)`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	serverID := uuid.New()
+	db.Exec(`INSERT INTO servers (id, user_id) VALUES (?, ?)`, serverID, owner)
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+
+	return serverID
+}
+
+func subscribe(client *Client, channels ...string) {
+	data, _ := json.Marshal(SubscribeMessage{Channels: channels})
+	client.handleSubscribe(data)
+}
+
+func TestSubscribeRequiresServerOwnership(t *testing.T) {
+	owner := &models.User{ID: uuid.New(), Role: models.RoleUser}
+	other := &models.User{ID: uuid.New(), Role: models.RoleUser}
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	serverID := useSubscribeTestDB(t, owner.ID)
+
+	hub := NewHub(zap.NewNop())
+	logs := BuildServerLogsChannel(serverID)
+
+	intruder := NewClient(hub, nil, other, zap.NewNop())
+	subscribe(intruder, logs, BuildServerStatusChannel(serverID), BuildUserChannel(owner.ID), "server:not-a-uuid:logs")
+	if len(intruder.subscriptions) != 0 {
+		t.Errorf("a user subscribed to another user's channels: %v", intruder.subscriptions)
+	}
+	if count := hub.SubscriberCount(logs); count != 0 {
+		t.Errorf("SubscriberCount(%s) = %d after a denied subscription", logs, count)
+	}
+
+	subscribe(intruder, BuildUserChannel(other.ID))
+	if !intruder.subscriptions[BuildUserChannel(other.ID)] {
+		t.Error("a user should be able to subscribe to their own notifications")
+	}
+
+	for _, user := range []*models.User{owner, admin} {
+		client := NewClient(hub, nil, user, zap.NewNop())
+		subscribe(client, logs)
+		if !client.subscriptions[logs] {
+			t.Errorf("user with role %s could not subscribe to %s", user.Role, logs)
+		}
+	}
+	if count := hub.SubscriberCount(logs); count != 2 {
+		t.Errorf("SubscriberCount(%s) = %d, want 2", logs, count)
+	}
+}
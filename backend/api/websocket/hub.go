@@ -3,6 +3,7 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,6 +28,9 @@ type Hub struct {
 	// Suscripciones: canal -> conjunto de clientes
 	subscriptions map[string]map[*Client]bool
 
+	// Observadores de activación/desactivación de canales
+	observers []ChannelObserver
+
 	// Mutex para acceso concurrente
 	mu sync.RWMutex
 
@@ -38,6 +42,15 @@ type Hub struct {
 	cancel context.CancelFunc
 }
 
+// ChannelObserver recibe notificaciones cuando un canal gana su primer
+// suscriptor o pierde el último. Se invoca fuera del lock del Hub y desde
+// goroutines distintas, así que las llamadas pueden llegar desordenadas: el
+// observador debe comprobar SubscriberCount antes de actuar.
+type ChannelObserver interface {
+	ChannelActivated(channel string)
+	ChannelDeactivated(channel string)
+}
+
 // NewHub crea una nueva instancia de Hub
 func NewHub(logger *zap.Logger) *Hub {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -106,12 +119,14 @@ func (h *Hub) registerClient(client *Client) {
 // unregisterClient cancela el registro de un cliente
 func (h *Hub) unregisterClient(client *Client) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
 
+	var emptied []string
 	if _, ok := h.clients[client]; ok {
 		// Remover de todos los canales suscritos
 		for channel := range client.subscriptions {
-			h.unsubscribeFromChannel(client, channel)
+			if h.removeSubscriptionLocked(client, channel) {
+				emptied = append(emptied, channel)
+			}
 		}
 
 		delete(h.clients, client)
@@ -123,15 +138,24 @@ func (h *Hub) unregisterClient(client *Client) {
 			zap.Int("total_clients", len(h.clients)),
 		)
 	}
+	observers := h.observers
+	h.mu.Unlock()
+
+	for _, channel := range emptied {
+		for _, observer := range observers {
+			observer.ChannelDeactivated(channel)
+		}
+	}
 }
 
 // subscribeToChannel suscribe un cliente a un canal
 func (h *Hub) subscribeToChannel(client *Client, channel string) {
 	h.mu.Lock()
-	defer h.mu.Unlock()
 
+	activated := false
 	if h.subscriptions[channel] == nil {
 		h.subscriptions[channel] = make(map[*Client]bool)
+		activated = true
 	}
 
 	h.subscriptions[channel][client] = true
@@ -142,14 +166,41 @@ func (h *Hub) subscribeToChannel(client *Client, channel string) {
 		zap.String("channel", channel),
 		zap.Int("subscribers", len(h.subscriptions[channel])),
 	)
+	observers := h.observers
+	h.mu.Unlock()
+
+	if activated {
+		for _, observer := range observers {
+			observer.ChannelActivated(channel)
+		}
+	}
 }
 
 // unsubscribeFromChannel cancela la suscripción de un cliente a un canal
 func (h *Hub) unsubscribeFromChannel(client *Client, channel string) {
+	h.mu.Lock()
+	emptied := h.removeSubscriptionLocked(client, channel)
+	observers := h.observers
+	h.mu.Unlock()
+
+	if emptied {
+		for _, observer := range observers {
+			observer.ChannelDeactivated(channel)
+		}
+	}
+}
+
+// removeSubscriptionLocked elimina la suscripción y retorna true si el canal
+// quedó sin suscriptores. Requiere tener h.mu tomado.
+func (h *Hub) removeSubscriptionLocked(client *Client, channel string) bool {
+	emptied := false
 	if clients, ok := h.subscriptions[channel]; ok {
-		delete(clients, client)
-		if len(clients) == 0 {
-			delete(h.subscriptions, channel)
+		if _, subscribed := clients[client]; subscribed {
+			delete(clients, client)
+			if len(clients) == 0 {
+				delete(h.subscriptions, channel)
+				emptied = true
+			}
 		}
 	}
 	delete(client.subscriptions, channel)
@@ -158,6 +209,22 @@ func (h *Hub) unsubscribeFromChannel(client *Client, channel string) {
 		zap.String("user_id", client.user.ID.String()),
 		zap.String("channel", channel),
 	)
+
+	return emptied
+}
+
+// SubscriberCount retorna los suscriptores actuales de un canal
+func (h *Hub) SubscriberCount(channel string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscriptions[channel])
+}
+
+// AddChannelObserver registra un observador de canales
+func (h *Hub) AddChannelObserver(observer ChannelObserver) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.observers = append(h.observers, observer)
 }
 
 // broadcastMessage envía un mensaje a todos los clientes suscritos al canal
@@ -236,6 +303,12 @@ func (h *Hub) BroadcastServerMetrics(serverID uuid.UUID, metrics ServerMetrics)
 	h.broadcast <- message
 }
 
+// BroadcastAgentMetrics envía métricas de un agente
+func (h *Hub) BroadcastAgentMetrics(agentID uuid.UUID, metrics AgentMetrics) {
+	message := NewAgentMetricsMessage(agentID, metrics)
+	h.broadcast <- message
+}
+
 // BroadcastServerStatus envía cambio de estado de un servidor
 func (h *Hub) BroadcastServerStatus(serverID uuid.UUID, status ServerStatusChange) {
 	message := NewServerStatusMessage(serverID, status)
@@ -314,3 +387,26 @@ func (h *Hub) GetSubscriptionCount() int {
 	defer h.mu.RUnlock()
 	return len(h.subscriptions)
 }
+
+// GetSubscriptionsByType retorna el número de suscripciones de clientes
+// agrupadas por tipo de canal ("server:logs", "agent:metrics", ...)
+func (h *Hub) GetSubscriptionsByType() map[string]int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for channel, clients := range h.subscriptions {
+		counts[channelKind(channel)] += len(clients)
+	}
+	return counts
+}
+
+// channelKind quita el ID del recurso de un canal <recurso>:<id>:<tipo>.
+// Los canales con otro formato se agrupan como "other".
+func channelKind(channel string) string {
+	parts := strings.Split(channel, ":")
+	if len(parts) != 3 {
+		return "other"
+	}
+	return parts[0] + ":" + parts[2]
+}
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,7 @@ const (
 	// Tipos de mensajes de servidor a cliente
 	MessageTypeLogEntry     MessageType = "log_entry"
 	MessageTypeMetrics      MessageType = "metrics"
+	MessageTypeAgentMetrics MessageType = "agent_metrics"
 	MessageTypeServerStatus MessageType = "server_status"
 	MessageTypeAlert        MessageType = "alert"
 	MessageTypeNotification MessageType = "notification"
@@ -51,6 +53,16 @@ type LogEntry struct {
 	Source    string    `json:"source"`    // server, plugin, etc.
 	Message   string    `json:"message"`
 	Exception string    `json:"exception,omitempty"`
+
+	// Metadata extraída por el agente
+	Sequence   uint64   `json:"sequence,omitempty"`
+	Thread     string   `json:"thread,omitempty"`
+	Stream     string   `json:"stream,omitempty"` // STDOUT, STDERR
+	Plugin     string   `json:"plugin,omitempty"`
+	ErrorType  string   `json:"error_type,omitempty"`
+	Severity   int32    `json:"severity,omitempty"`
+	Suggestion string   `json:"suggestion,omitempty"`
+	StackTrace []string `json:"stack_trace,omitempty"`
 }
 
 // ServerMetrics representa métricas en tiempo real de un servidor
@@ -67,6 +79,21 @@ type ServerMetrics struct {
 	UptimeSeconds int64     `json:"uptime_seconds"`
 }
 
+// AgentMetrics representa métricas del sistema de un agente
+type AgentMetrics struct {
+	AgentID       uuid.UUID `json:"agent_id"`
+	Timestamp     time.Time `json:"timestamp"`
+	CPUPercent    float64   `json:"cpu_percent"`
+	MemoryUsed    uint64    `json:"memory_used"`
+	MemoryTotal   uint64    `json:"memory_total"`
+	MemoryPercent float64   `json:"memory_percent"`
+	DiskUsed      uint64    `json:"disk_used"`
+	DiskTotal     uint64    `json:"disk_total"`
+	DiskPercent   float64   `json:"disk_percent"`
+	NetworkSent   uint64    `json:"network_sent"`
+	NetworkRecv   uint64    `json:"network_recv"`
+}
+
 // ServerStatusChange representa un cambio de estado de un servidor
 type ServerStatusChange struct {
 	ServerID   uuid.UUID `json:"server_id"`
@@ -134,6 +161,22 @@ func BuildChannel(channelType ChannelType, resourceID uuid.UUID) string {
 	}
 }
 
+// ParseServerChannel extrae el ID de servidor y el tipo de un canal
+// con formato server:<id>:<tipo>
+func ParseServerChannel(channel string) (uuid.UUID, ChannelType, bool) {
+	parts := strings.Split(channel, ":")
+	if len(parts) != 3 || parts[0] != "server" {
+		return uuid.Nil, "", false
+	}
+
+	serverID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+
+	return serverID, ChannelType(parts[2]), true
+}
+
 // BuildUserChannel construye el canal de notificaciones de un usuario
 func BuildUserChannel(userID uuid.UUID) string {
 	return "user:" + userID.String() + ":notifications"
@@ -154,6 +197,11 @@ func BuildServerStatusChannel(serverID uuid.UUID) string {
 	return "server:" + serverID.String() + ":status"
 }
 
+// BuildAgentMetricsChannel construye el canal de métricas de un agente
+func BuildAgentMetricsChannel(agentID uuid.UUID) string {
+	return "agent:" + agentID.String() + ":metrics"
+}
+
 // NewMessage crea un nuevo mensaje con timestamp actual
 func NewMessage(msgType MessageType, channel string, data interface{}) Message {
 	return Message{
@@ -176,6 +224,12 @@ func NewMetricsMessage(serverID uuid.UUID, metrics ServerMetrics) Message {
 	return NewMessage(MessageTypeMetrics, channel, metrics)
 }
 
+// NewAgentMetricsMessage crea un mensaje de métricas de agente
+func NewAgentMetricsMessage(agentID uuid.UUID, metrics AgentMetrics) Message {
+	channel := BuildAgentMetricsChannel(agentID)
+	return NewMessage(MessageTypeAgentMetrics, channel, metrics)
+}
+
 // NewServerStatusMessage crea un mensaje de cambio de estado
 func NewServerStatusMessage(serverID uuid.UUID, status ServerStatusChange) Message {
 	channel := BuildServerStatusChannel(serverID)
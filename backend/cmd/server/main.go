@@ -14,11 +14,15 @@ import (
 	"github.com/aymc/backend/database"
 	"github.com/aymc/backend/database/migrations"
 	"github.com/aymc/backend/pkg/logger"
+	"github.com/aymc/backend/pkg/telemetry"
 	"github.com/aymc/backend/services/agents"
+	"github.com/aymc/backend/services/alerts"
 	"github.com/aymc/backend/services/auth"
 	"github.com/aymc/backend/services/backup"
 	"github.com/aymc/backend/services/marketplace"
+	"github.com/aymc/backend/services/metrics"
 	"github.com/aymc/backend/services/server"
+	"github.com/aymc/backend/services/tasks"
 	"go.uber.org/zap"
 )
 
@@ -62,8 +66,15 @@ func main() {
 	authService := auth.NewAuthService(jwtService, logger.GetLogger())
 	logger.Info("Auth service initialized")
 
+	// Initialize internal CA for agent mTLS
+	agentCA, err := agents.LoadOrCreateCA(cfg.Agent.CADir)
+	if err != nil {
+		logger.Fatal("Failed to initialize agent CA", zap.Error(err))
+	}
+	logger.Info("Agent CA initialized", zap.String("dir", cfg.Agent.CADir))
+
 	// Initialize agent registry
-	agentRegistry := agents.NewAgentRegistry(logger.GetLogger())
+	agentRegistry := agents.NewAgentRegistry(agentCA, logger.GetLogger())
 	logger.Info("Agent registry initialized")
 
 	// Load agents from database and connect
@@ -72,6 +83,16 @@ func main() {
 		logger.Warn("Failed to load agents from database", zap.Error(err))
 	}
 
+	// Accept tunnels from agents in reverse mode (behind NAT/firewalls)
+	var tunnelServer *agents.TunnelServer
+	if cfg.Agent.TunnelAddr != "" {
+		tunnelServer = agents.NewTunnelServer(agentRegistry, cfg.Agent.TunnelAddr, logger.GetLogger())
+		if err := tunnelServer.Start(); err != nil {
+			logger.Fatal("Failed to start agent tunnel server", zap.Error(err))
+		}
+		logger.Info("Agent tunnel server started", zap.String("addr", cfg.Agent.TunnelAddr))
+	}
+
 	// Initialize health monitor
 	healthMonitor := agents.NewHealthMonitor(agentRegistry, 30*time.Second, logger.GetLogger())
 	if err := healthMonitor.Start(); err != nil {
@@ -92,8 +113,39 @@ func main() {
 	logger.Info("Marketplace service initialized")
 
 	// Initialize backup service
-	backupDir := cfg.Server.Host + "/backups" // TODO: hacer esto configurable
-	backupService := backup.NewService(database.GetDB(), agentService, logger.GetLogger(), backupDir)
+	backupService := backup.NewService(database.GetDB(), agentService, logger.GetLogger(), cfg.Backup.Dir)
+	if cfg.Backup.S3.Bucket != "" {
+		s3Storage, err := backup.NewS3Storage(backup.S3Config{
+			Endpoint:  cfg.Backup.S3.Endpoint,
+			Region:    cfg.Backup.S3.Region,
+			Bucket:    cfg.Backup.S3.Bucket,
+			AccessKey: cfg.Backup.S3.AccessKey,
+			SecretKey: cfg.Backup.S3.SecretKey,
+			Prefix:    cfg.Backup.S3.Prefix,
+			PathStyle: cfg.Backup.S3.PathStyle,
+		})
+		if err != nil {
+			logger.Fatal("Failed to configure S3 backup storage", zap.Error(err))
+		}
+		backupService.SetStorage(s3Storage)
+		logger.Info("S3 backup storage configured", zap.String("bucket", cfg.Backup.S3.Bucket))
+	}
+	if cfg.Backup.SFTP.Host != "" {
+		sftpStorage, err := backup.NewSFTPStorage(backup.SFTPConfig{
+			Host:           cfg.Backup.SFTP.Host,
+			Port:           cfg.Backup.SFTP.Port,
+			User:           cfg.Backup.SFTP.User,
+			Password:       cfg.Backup.SFTP.Password,
+			PrivateKeyFile: cfg.Backup.SFTP.PrivateKeyFile,
+			HostKey:        cfg.Backup.SFTP.HostKey,
+			Dir:            cfg.Backup.SFTP.Dir,
+		})
+		if err != nil {
+			logger.Fatal("Failed to configure SFTP backup storage", zap.Error(err))
+		}
+		backupService.SetStorage(sftpStorage)
+		logger.Info("SFTP backup storage configured", zap.String("host", cfg.Backup.SFTP.Host))
+	}
 	logger.Info("Backup service initialized")
 
 	// Initialize backup scheduler
@@ -103,6 +155,17 @@ func main() {
 	}
 	logger.Info("Backup scheduler started")
 
+	// Store metrics history (raw samples and 1m/5m/1h rollups)
+	metricsService := metrics.NewService(database.GetDB(), agentService, metrics.Config{
+		SampleInterval:    cfg.Metrics.SampleInterval,
+		RawRetention:      cfg.Metrics.RawRetention,
+		Rollup1mRetention: cfg.Metrics.Rollup1mRetention,
+		Rollup5mRetention: cfg.Metrics.Rollup5mRetention,
+		Rollup1hRetention: cfg.Metrics.Rollup1hRetention,
+	}, logger.GetLogger())
+	metricsService.Start()
+	logger.Info("Metrics history started")
+
 	// Initialize WebSocket hub
 	wsHub := websocket.NewHub(logger.GetLogger())
 	logger.Info("WebSocket hub initialized")
@@ -110,8 +173,46 @@ func main() {
 	// Start WebSocket hub in a goroutine
 	go wsHub.Run()
 
+	// Export servers, agents and WebSocket state to Prometheus
+	telemetry.Registry.MustRegister(metrics.NewCollector(metricsService, healthMonitor, wsHub))
+
+	// Evaluate alert rules and deliver notifications
+	alertService := alerts.NewService(database.GetDB(), wsHub, healthMonitor, alerts.Config{
+		EvaluationInterval: cfg.Alerts.EvaluationInterval,
+		SMTP: alerts.SMTPConfig{
+			Host:     cfg.Alerts.SMTP.Host,
+			Port:     cfg.Alerts.SMTP.Port,
+			Username: cfg.Alerts.SMTP.Username,
+			Password: cfg.Alerts.SMTP.Password,
+			From:     cfg.Alerts.SMTP.From,
+		},
+		AllowHTTPWebhooks: cfg.Alerts.AllowHTTPWebhooks,
+	}, logger.GetLogger())
+	backupService.SetResultHandler(alertService.HandleBackupResult)
+	alertService.Start()
+	logger.Info("Alert service started")
+
+	// Run scheduled server tasks (restarts, commands, announcements...)
+	taskService := tasks.NewService(database.GetDB(), serverService, agentService, backupService, wsHub, logger.GetLogger())
+	if err := taskService.Start(); err != nil {
+		logger.Fatal("Failed to start scheduled tasks", zap.Error(err))
+	}
+	logger.Info("Scheduled tasks started")
+
+	// Start agent event streams (agent -> backend -> WebSocket)
+	eventStreams := agents.NewEventStreamManager(agentRegistry, wsHub, logger.GetLogger())
+	eventStreams.SetServerEventHandler(alertService.HandleServerEvent)
+	if err := eventStreams.Start(); err != nil {
+		logger.Fatal("Failed to start agent event streams", zap.Error(err))
+	}
+	logger.Info("Agent event streams started")
+
+	// Relay agent log streams to WebSocket log channels on demand
+	logRelay := agents.NewLogRelay(agentService, wsHub, logger.GetLogger())
+	logRelay.Start()
+
 	// Initialize REST API server
-	apiServer := rest.NewServer(cfg, jwtService, authService, serverService, agentService, marketplaceService, backupService, backupScheduler, wsHub, logger.GetLogger())
+	apiServer := rest.NewServer(cfg, jwtService, authService, serverService, agentService, marketplaceService, backupService, backupScheduler, metricsService, alertService, taskService, wsHub, logger.GetLogger())
 	logger.Info("REST API server initialized")
 
 	// Start server in a goroutine
@@ -128,10 +229,27 @@ func main() {
 
 	logger.Info("Shutting down server...")
 
+	// Stop scheduled tasks, cancelling runs in progress
+	taskService.Stop()
+	logger.Info("Scheduled tasks stopped")
+
 	// Stop backup scheduler
 	backupScheduler.Stop()
 	logger.Info("Backup scheduler stopped")
 
+	// Stop metrics history
+	metricsService.Stop()
+
+	// Stop alert evaluation and wait for pending deliveries
+	alertService.Stop()
+	logger.Info("Alert service stopped")
+
+	// Stop agent event streams and log relay before the hub they publish to
+	eventStreams.Stop()
+	logger.Info("Agent event streams stopped")
+	logRelay.Stop()
+	logger.Info("Log relay stopped")
+
 	// Stop WebSocket hub
 	wsHub.Stop()
 	logger.Info("WebSocket hub stopped")
@@ -140,6 +258,12 @@ func main() {
 	healthMonitor.Stop()
 	logger.Info("Health monitor stopped")
 
+	// Stop accepting agent tunnels
+	if tunnelServer != nil {
+		tunnelServer.Stop()
+		logger.Info("Agent tunnel server stopped")
+	}
+
 	// Shutdown agent registry (close all connections)
 	agentRegistry.Shutdown()
 	logger.Info("Agent registry shutdown complete")
@@ -19,6 +19,9 @@ type Config struct {
 	RateLimit   RateLimitConfig
 	Upload      UploadConfig
 	Marketplace MarketplaceConfig
+	Backup      BackupStorageConfig
+	Metrics     MetricsConfig
+	Alerts      AlertsConfig
 }
 
 // ServerConfig holds server-specific configuration
@@ -60,6 +63,8 @@ type JWTConfig struct {
 type AgentConfig struct {
 	GRPCTimeout          time.Duration
 	HealthCheckInterval  time.Duration
+	CADir                string // CA interna que emite los certificados mTLS
+	TunnelAddr           string // Escucha de túneles de agentes en modo reverse (vacío = deshabilitado)
 }
 
 // LoggingConfig holds logging configuration
@@ -93,6 +98,62 @@ type MarketplaceConfig struct {
 	SpigotAPIURL     string
 }
 
+// BackupStorageConfig holds backup storage configuration
+type BackupStorageConfig struct {
+	Dir  string // Directorio del almacenamiento local
+	S3   S3StorageConfig
+	SFTP SFTPStorageConfig
+}
+
+// S3StorageConfig holds S3-compatible storage configuration (AWS, MinIO, etc.)
+type S3StorageConfig struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Prefix    string
+	PathStyle bool
+}
+
+// SFTPStorageConfig holds SFTP storage configuration
+type SFTPStorageConfig struct {
+	Host           string
+	Port           int
+	User           string
+	Password       string
+	PrivateKeyFile string
+	HostKey        string // Clave pública del servidor en formato authorized_keys
+	Dir            string
+}
+
+// MetricsConfig holds metrics history and Prometheus exporter configuration
+type MetricsConfig struct {
+	SampleInterval    time.Duration
+	RawRetention      time.Duration
+	Rollup1mRetention time.Duration
+	Rollup5mRetention time.Duration
+	Rollup1hRetention time.Duration
+	ExporterEnabled   bool   // Serve /metrics for Prometheus
+	ExporterToken     string // Bearer token required by /metrics (empty = no auth)
+}
+
+// AlertsConfig holds alert rules evaluation and notification configuration
+type AlertsConfig struct {
+	EvaluationInterval time.Duration
+	SMTP               SMTPConfig
+	AllowHTTPWebhooks  bool // Accept plain http webhook/Discord URLs (https only by default)
+}
+
+// SMTPConfig holds the SMTP server used by email notification channels
+type SMTPConfig struct {
+	Host     string // Empty = email channels disabled
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
 // Load loads configuration from environment variables and config file
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
@@ -144,6 +205,8 @@ func Load() (*Config, error) {
 		Agent: AgentConfig{
 			GRPCTimeout:         viper.GetDuration("AGENT_GRPC_TIMEOUT"),
 			HealthCheckInterval: viper.GetDuration("AGENT_HEALTH_CHECK_INTERVAL"),
+			CADir:               viper.GetString("AGENT_CA_DIR"),
+			TunnelAddr:          viper.GetString("AGENT_TUNNEL_ADDR"),
 		},
 		Logging: LoggingConfig{
 			Level:  viper.GetString("LOG_LEVEL"),
@@ -166,6 +229,47 @@ func Load() (*Config, error) {
 			ModrinthAPIURL:   viper.GetString("MODRINTH_API_URL"),
 			SpigotAPIURL:     viper.GetString("SPIGOT_API_URL"),
 		},
+		Backup: BackupStorageConfig{
+			Dir: viper.GetString("BACKUP_DIR"),
+			S3: S3StorageConfig{
+				Endpoint:  viper.GetString("BACKUP_S3_ENDPOINT"),
+				Region:    viper.GetString("BACKUP_S3_REGION"),
+				Bucket:    viper.GetString("BACKUP_S3_BUCKET"),
+				AccessKey: viper.GetString("BACKUP_S3_ACCESS_KEY"),
+				SecretKey: viper.GetString("BACKUP_S3_SECRET_KEY"),
+				Prefix:    viper.GetString("BACKUP_S3_PREFIX"),
+				PathStyle: viper.GetBool("BACKUP_S3_PATH_STYLE"),
+			},
+			SFTP: SFTPStorageConfig{
+				Host:           viper.GetString("BACKUP_SFTP_HOST"),
+				Port:           viper.GetInt("BACKUP_SFTP_PORT"),
+				User:           viper.GetString("BACKUP_SFTP_USER"),
+				Password:       viper.GetString("BACKUP_SFTP_PASSWORD"),
+				PrivateKeyFile: viper.GetString("BACKUP_SFTP_PRIVATE_KEY_FILE"),
+				HostKey:        viper.GetString("BACKUP_SFTP_HOST_KEY"),
+				Dir:            viper.GetString("BACKUP_SFTP_DIR"),
+			},
+		},
+		Metrics: MetricsConfig{
+			SampleInterval:    viper.GetDuration("METRICS_SAMPLE_INTERVAL"),
+			RawRetention:      viper.GetDuration("METRICS_RAW_RETENTION"),
+			Rollup1mRetention: viper.GetDuration("METRICS_1M_RETENTION"),
+			Rollup5mRetention: viper.GetDuration("METRICS_5M_RETENTION"),
+			Rollup1hRetention: viper.GetDuration("METRICS_1H_RETENTION"),
+			ExporterEnabled:   viper.GetBool("METRICS_EXPORTER_ENABLED"),
+			ExporterToken:     viper.GetString("METRICS_EXPORTER_TOKEN"),
+		},
+		Alerts: AlertsConfig{
+			EvaluationInterval: viper.GetDuration("ALERTS_EVALUATION_INTERVAL"),
+			SMTP: SMTPConfig{
+				Host:     viper.GetString("SMTP_HOST"),
+				Port:     viper.GetInt("SMTP_PORT"),
+				Username: viper.GetString("SMTP_USERNAME"),
+				Password: viper.GetString("SMTP_PASSWORD"),
+				From:     viper.GetString("SMTP_FROM"),
+			},
+			AllowHTTPWebhooks: viper.GetBool("ALERTS_ALLOW_HTTP_WEBHOOKS"),
+		},
 	}
 
 	// Validate configuration
@@ -217,6 +321,8 @@ func setDefaults() {
 
 	viper.SetDefault("AGENT_GRPC_TIMEOUT", "30s")
 	viper.SetDefault("AGENT_HEALTH_CHECK_INTERVAL", "30s")
+	viper.SetDefault("AGENT_CA_DIR", "./data/ca")
+	viper.SetDefault("AGENT_TUNNEL_ADDR", ":50052")
 
 	viper.SetDefault("LOG_LEVEL", "info")
 	viper.SetDefault("LOG_FORMAT", "json")
@@ -232,6 +338,22 @@ func setDefaults() {
 
 	viper.SetDefault("MODRINTH_API_URL", "https://api.modrinth.com/v2")
 	viper.SetDefault("SPIGOT_API_URL", "https://api.spiget.org/v2")
+
+	viper.SetDefault("BACKUP_DIR", "./backups")
+	viper.SetDefault("BACKUP_S3_REGION", "us-east-1")
+	viper.SetDefault("BACKUP_S3_PATH_STYLE", true)
+	viper.SetDefault("BACKUP_SFTP_PORT", 22)
+
+	viper.SetDefault("METRICS_SAMPLE_INTERVAL", "30s")
+	viper.SetDefault("METRICS_RAW_RETENTION", "24h")
+	viper.SetDefault("METRICS_1M_RETENTION", "168h")  // 7 days
+	viper.SetDefault("METRICS_5M_RETENTION", "720h")  // 30 days
+	viper.SetDefault("METRICS_1H_RETENTION", "8760h") // 1 year
+	viper.SetDefault("METRICS_EXPORTER_ENABLED", true)
+
+	viper.SetDefault("ALERTS_EVALUATION_INTERVAL", "30s")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("SMTP_FROM", "aymc@localhost")
 }
 
 // IsDevelopment returns true if running in development mode
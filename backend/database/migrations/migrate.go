@@ -53,12 +53,60 @@ func RunMigrations(db *gorm.DB, log *zap.Logger) error {
 		return err
 	}
 
+	log.Info("Migrating enrollment_tokens table...")
+	if err := db.AutoMigrate(&models.EnrollmentToken{}); err != nil {
+		log.Error("Failed to migrate enrollment_tokens", zap.Error(err))
+		return err
+	}
+
 	log.Info("Migrating server_metrics table...")
 	if err := db.AutoMigrate(&models.ServerMetric{}); err != nil {
 		log.Error("Failed to migrate server_metrics", zap.Error(err))
 		return err
 	}
 
+	log.Info("Migrating agent_metrics table...")
+	if err := db.AutoMigrate(&models.AgentMetric{}); err != nil {
+		log.Error("Failed to migrate agent_metrics", zap.Error(err))
+		return err
+	}
+
+	log.Info("Migrating metric_rollups table...")
+	if err := db.AutoMigrate(&models.MetricRollup{}); err != nil {
+		log.Error("Failed to migrate metric_rollups", zap.Error(err))
+		return err
+	}
+
+	log.Info("Migrating notification_channels table...")
+	if err := db.AutoMigrate(&models.NotificationChannel{}); err != nil {
+		log.Error("Failed to migrate notification_channels", zap.Error(err))
+		return err
+	}
+
+	log.Info("Migrating alert_rules table...")
+	if err := db.AutoMigrate(&models.AlertRule{}); err != nil {
+		log.Error("Failed to migrate alert_rules", zap.Error(err))
+		return err
+	}
+
+	log.Info("Migrating alerts table...")
+	if err := db.AutoMigrate(&models.Alert{}); err != nil {
+		log.Error("Failed to migrate alerts", zap.Error(err))
+		return err
+	}
+
+	log.Info("Migrating scheduled_tasks table...")
+	if err := db.AutoMigrate(&models.ScheduledTask{}); err != nil {
+		log.Error("Failed to migrate scheduled_tasks", zap.Error(err))
+		return err
+	}
+
+	log.Info("Migrating scheduled_task_runs table...")
+	if err := db.AutoMigrate(&models.ScheduledTaskRun{}); err != nil {
+		log.Error("Failed to migrate scheduled_task_runs", zap.Error(err))
+		return err
+	}
+
 	// Create indexes
 	if err := createIndexes(db); err != nil {
 		log.Error("Failed to create indexes", zap.Error(err))
@@ -111,6 +159,14 @@ func DropAllTables(db *gorm.DB, log *zap.Logger) error {
 	log.Warn("Dropping all tables...")
 
 	err := db.Migrator().DropTable(
+		&models.ScheduledTaskRun{},
+		&models.ScheduledTask{},
+		&models.Alert{},
+		"alert_rule_channels",
+		&models.AlertRule{},
+		&models.NotificationChannel{},
+		&models.MetricRollup{},
+		&models.AgentMetric{},
 		&models.ServerMetric{},
 		&models.Backup{},
 		&models.ServerPlugin{},
@@ -17,23 +17,39 @@ const (
 	AgentStatusError   AgentStatus = "error"
 )
 
+// AgentConnectionMode represents who opens the gRPC connection
+type AgentConnectionMode string
+
+const (
+	// ConnectionModeDirect the backend dials the agent at ip:port
+	ConnectionModeDirect AgentConnectionMode = "direct"
+	// ConnectionModeReverse the agent opens a tunnel to the backend (NAT, firewalls)
+	ConnectionModeReverse AgentConnectionMode = "reverse"
+)
+
 // Agent represents a remote agent connected to the system
 type Agent struct {
-	ID                  uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	AgentID             string      `gorm:"size:100;uniqueIndex;not null" json:"agent_id" validate:"required"`
-	Hostname            string      `gorm:"size:255;not null" json:"hostname" validate:"required"`
-	IPAddress           string      `gorm:"type:inet;not null" json:"ip_address" validate:"required,ip"`
-	Port                int         `gorm:"default:50051" json:"port"`
-	Status              AgentStatus `gorm:"type:varchar(20);default:offline" json:"status"`
-	Version             string      `gorm:"size:20" json:"version"`
-	OS                  string      `gorm:"size:50" json:"os"`
-	CPUCores            int         `json:"cpu_cores"`
-	MemoryTotal         int64       `json:"memory_total"`
-	DiskTotal           int64       `json:"disk_total"`
-	LastSeen            *time.Time  `json:"last_seen"`
-	HealthCheckInterval int         `gorm:"default:30" json:"health_check_interval"`
-	CreatedAt           time.Time   `json:"created_at"`
-	UpdatedAt           time.Time   `json:"updated_at"`
+	ID                  uuid.UUID           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AgentID             string              `gorm:"size:100;uniqueIndex;not null" json:"agent_id" validate:"required"`
+	Hostname            string              `gorm:"size:255;not null" json:"hostname" validate:"required"`
+	IPAddress           string              `gorm:"type:inet;not null" json:"ip_address" validate:"required,ip"`
+	Port                int                 `gorm:"default:50051" json:"port"`
+	Status              AgentStatus         `gorm:"type:varchar(20);default:offline" json:"status"`
+	ConnectionMode      AgentConnectionMode `gorm:"type:varchar(10);default:direct" json:"connection_mode"`
+	Version             string              `gorm:"size:20" json:"version"`
+	OS                  string              `gorm:"size:50" json:"os"`
+	CPUCores            int                 `json:"cpu_cores"`
+	MemoryTotal         int64               `json:"memory_total"`
+	DiskTotal           int64               `json:"disk_total"`
+	LastSeen            *time.Time          `json:"last_seen"`
+	HealthCheckInterval int                 `gorm:"default:30" json:"health_check_interval"`
+	AuthToken           string              `gorm:"type:text" json:"-"`                        // Token que el agente exige en cada llamada gRPC
+	CertFingerprint     string              `gorm:"size:64" json:"cert_fingerprint,omitempty"` // SHA256 del certificado fijado
+	CertSerial          string              `gorm:"size:40" json:"cert_serial,omitempty"`
+	CertExpiresAt       *time.Time          `json:"cert_expires_at,omitempty"`
+	CertRevokedAt       *time.Time          `json:"cert_revoked_at,omitempty"`
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
 
 	// Relations
 	Servers []Server `gorm:"foreignKey:AgentID" json:"servers,omitempty"`
@@ -72,6 +88,11 @@ func (a *Agent) UpdateLastSeen() {
 	a.LastSeen = &now
 }
 
+// IsReverse checks if the agent connects through a tunnel opened by itself
+func (a *Agent) IsReverse() bool {
+	return a.ConnectionMode == ConnectionModeReverse
+}
+
 // GetAddress returns the full gRPC address (ip:port)
 func (a *Agent) GetAddress() string {
 	return fmt.Sprintf("%s:%d", a.IPAddress, a.Port)
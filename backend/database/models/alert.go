@@ -0,0 +1,172 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AlertRuleType represents the condition evaluated by an alert rule
+type AlertRuleType string
+
+const (
+	AlertRuleCPU          AlertRuleType = "cpu"           // Average CPU % over the window above threshold
+	AlertRuleMemory       AlertRuleType = "memory"        // Average memory % over the window above threshold
+	AlertRuleTPS          AlertRuleType = "tps"           // Average TPS over the window below threshold
+	AlertRuleDisk         AlertRuleType = "disk"          // Average disk % of the agent volume above threshold
+	AlertRuleAgentOffline AlertRuleType = "agent_offline" // Agent offline for longer than the window
+	AlertRuleCrashLoop    AlertRuleType = "crash_loop"    // At least threshold crashes within the window
+	AlertRuleBackupFailed AlertRuleType = "backup_failed" // A backup failed; resolves on the next successful one
+)
+
+// Alert rule target types
+const (
+	AlertTargetServer = "server"
+	AlertTargetAgent  = "agent"
+)
+
+// AlertSeverity represents how urgent an alert is
+type AlertSeverity string
+
+const (
+	AlertSeverityInfo     AlertSeverity = "info"
+	AlertSeverityWarning  AlertSeverity = "warning"
+	AlertSeverityError    AlertSeverity = "error"
+	AlertSeverityCritical AlertSeverity = "critical"
+)
+
+// AlertStatus represents the state of a fired alert
+type AlertStatus string
+
+const (
+	AlertStatusFiring   AlertStatus = "firing"
+	AlertStatusResolved AlertStatus = "resolved"
+)
+
+// NotificationChannelType represents where notifications are delivered
+type NotificationChannelType string
+
+const (
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+	NotificationChannelDiscord NotificationChannelType = "discord"
+	NotificationChannelEmail   NotificationChannelType = "email"
+)
+
+// AlertRule represents a user-defined alert condition over a server or agent
+type AlertRule struct {
+	ID              uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID          uuid.UUID     `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name            string        `gorm:"size:100;not null" json:"name"`
+	Type            AlertRuleType `gorm:"type:varchar(20);not null" json:"type"`
+	TargetType      string        `gorm:"type:varchar(10);not null" json:"target_type"` // server, agent
+	TargetID        *uuid.UUID    `gorm:"type:uuid;index" json:"target_id,omitempty"`   // Nil = all servers of the user, or all agents
+	Threshold       float64       `json:"threshold"`
+	WindowSeconds   int           `json:"window_seconds"`
+	CooldownSeconds int           `json:"cooldown_seconds"` // Minimum time between notifications
+	Severity        AlertSeverity `gorm:"type:varchar(20)" json:"severity"`
+	Enabled         bool          `json:"enabled"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+
+	// Relations
+	Channels []NotificationChannel `gorm:"many2many:alert_rule_channels" json:"channels,omitempty"`
+}
+
+// TableName specifies the table name for AlertRule model
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
+
+// BeforeCreate hook for AlertRule
+func (r *AlertRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Window returns the evaluation window of the rule
+func (r *AlertRule) Window() time.Duration {
+	return time.Duration(r.WindowSeconds) * time.Second
+}
+
+// Cooldown returns the minimum time between notifications of the rule
+func (r *AlertRule) Cooldown() time.Duration {
+	return time.Duration(r.CooldownSeconds) * time.Second
+}
+
+// Alert represents an alert fired by a rule for one server or agent
+type Alert struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RuleID         uuid.UUID     `gorm:"type:uuid;not null;index:idx_alerts_rule_target,priority:1" json:"rule_id"`
+	UserID         uuid.UUID     `gorm:"type:uuid;not null;index" json:"user_id"`
+	TargetType     string        `gorm:"type:varchar(10);not null" json:"target_type"`
+	TargetID       uuid.UUID     `gorm:"type:uuid;not null;index:idx_alerts_rule_target,priority:2" json:"target_id"`
+	TargetName     string        `gorm:"size:255" json:"target_name"`
+	Severity       AlertSeverity `gorm:"type:varchar(20)" json:"severity"`
+	Status         AlertStatus   `gorm:"type:varchar(20);not null;index" json:"status"`
+	Title          string        `gorm:"size:255" json:"title"`
+	Message        string        `gorm:"type:text" json:"message"`
+	Value          float64       `json:"value"` // Observed value when the alert fired or was last notified
+	Threshold      float64       `json:"threshold"`
+	FiredAt        time.Time     `gorm:"not null" json:"fired_at"`
+	LastNotifiedAt *time.Time    `json:"last_notified_at,omitempty"`
+	ResolvedAt     *time.Time    `json:"resolved_at,omitempty"`
+	AcknowledgedAt *time.Time    `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy *uuid.UUID    `gorm:"type:uuid" json:"acknowledged_by,omitempty"`
+
+	// Relations
+	Rule *AlertRule `gorm:"foreignKey:RuleID" json:"rule,omitempty"`
+}
+
+// TableName specifies the table name for Alert model
+func (Alert) TableName() string {
+	return "alerts"
+}
+
+// BeforeCreate hook for Alert
+func (a *Alert) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsFiring checks if the alert has not been resolved
+func (a *Alert) IsFiring() bool {
+	return a.Status == AlertStatusFiring
+}
+
+// IsAcknowledged checks if a user acknowledged the alert
+func (a *Alert) IsAcknowledged() bool {
+	return a.AcknowledgedAt != nil
+}
+
+// NotificationChannel represents a destination for alert notifications.
+// Alerts are always broadcast over WebSocket; channels add external delivery.
+type NotificationChannel struct {
+	ID         uuid.UUID               `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID               `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string                  `gorm:"size:100;not null" json:"name"`
+	Type       NotificationChannelType `gorm:"type:varchar(20);not null" json:"type"`
+	URL        string                  `gorm:"type:text" json:"url,omitempty"`        // webhook, discord
+	Secret     string                  `gorm:"size:255" json:"-"`                     // Signs webhook payloads (HMAC-SHA256)
+	Recipients string                  `gorm:"type:text" json:"recipients,omitempty"` // email, comma-separated
+	Enabled    bool                    `json:"enabled"`
+	CreatedAt  time.Time               `json:"created_at"`
+	UpdatedAt  time.Time               `json:"updated_at"`
+}
+
+// TableName specifies the table name for NotificationChannel model
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
+
+// BeforeCreate hook for NotificationChannel
+func (c *NotificationChannel) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
@@ -15,6 +15,10 @@ const (
 	BackupTypeWorld   BackupType = "world"
 	BackupTypePlugins BackupType = "plugins"
 	BackupTypeConfig  BackupType = "config"
+
+	// BackupTypeImported is an archive uploaded by a user instead of created
+	// by the agent
+	BackupTypeImported BackupType = "imported"
 )
 
 // BackupStatus represents the status of a backup
@@ -37,6 +41,10 @@ type Backup struct {
 	BackupType  BackupType   `gorm:"type:varchar(20)" json:"backup_type"`
 	Status      BackupStatus `gorm:"type:varchar(20);default:pending" json:"status"`
 	Compression string       `gorm:"size:10;default:gzip" json:"compression"`
+	StorageType string       `gorm:"size:10" json:"storage_type,omitempty"`
+	Checksum    string       `gorm:"size:64" json:"checksum,omitempty"` // SHA256 del archivo
+	DurationMs  int64        `json:"duration_ms"`
+	Error       string       `gorm:"type:text" json:"error,omitempty"`
 	CreatedBy   *uuid.UUID   `gorm:"type:uuid" json:"created_by"`
 	CreatedAt   time.Time    `json:"created_at"`
 	CompletedAt *time.Time   `json:"completed_at,omitempty"`
@@ -71,9 +79,12 @@ func (b *Backup) MarkCompleted() {
 	b.CompletedAt = &now
 }
 
-// MarkFailed marks the backup as failed
-func (b *Backup) MarkFailed() {
+// MarkFailed marks the backup as failed with the given reason
+func (b *Backup) MarkFailed(reason string) {
+	now := time.Now()
 	b.Status = BackupStatusFailed
+	b.CompletedAt = &now
+	b.Error = reason
 }
 
 // FileSizeMB retorna el tamaño del backup en MB
@@ -98,6 +109,7 @@ type BackupConfig struct {
 	MaxBackups        int            `gorm:"default:10" json:"max_backups"`
 	RetentionDays     int            `gorm:"default:30" json:"retention_days"`
 	CompressBackups   bool           `gorm:"default:true" json:"compress_backups"`
+	OnlineBackup      bool           `gorm:"default:true" json:"online_backup"` // save-off/save-all en lugar de una copia en caliente
 	IncludeWorld      bool           `gorm:"default:true" json:"include_world"`
 	IncludePlugins    bool           `gorm:"default:true" json:"include_plugins"`
 	IncludeConfig     bool           `gorm:"default:true" json:"include_config"`
@@ -166,6 +178,7 @@ type UpdateBackupConfigRequest struct {
 	MaxBackups       *int       `json:"max_backups" validate:"omitempty,min=1,max=100"`
 	RetentionDays    *int       `json:"retention_days" validate:"omitempty,min=1,max=365"`
 	CompressBackups  *bool      `json:"compress_backups"`
+	OnlineBackup     *bool      `json:"online_backup"`
 	IncludeWorld     *bool      `json:"include_world"`
 	IncludePlugins   *bool      `json:"include_plugins"`
 	IncludeConfig    *bool      `json:"include_config"`
@@ -173,7 +186,7 @@ type UpdateBackupConfigRequest struct {
 	ExcludePaths     []string   `json:"exclude_paths"`
 	NotifyOnComplete *bool      `json:"notify_on_complete"`
 	NotifyOnFailure  *bool      `json:"notify_on_failure"`
-	StorageType      string     `json:"storage_type" validate:"omitempty,oneof=local s3"`
+	StorageType      string     `json:"storage_type" validate:"omitempty,oneof=local s3 sftp snapshot"`
 	StoragePath      string     `json:"storage_path"`
 }
 
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EnrollmentToken represents a single-use token an agent redeems to register itself
+type EnrollmentToken struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TokenHash   string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	TokenPrefix string     `gorm:"size:8" json:"token_prefix"` // Para identificar el token en listados
+	Description string     `gorm:"size:255" json:"description"`
+	CreatedBy   uuid.UUID  `gorm:"type:uuid" json:"created_by"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at,omitempty"`
+	AgentID     *uuid.UUID `gorm:"type:uuid" json:"agent_id,omitempty"` // Agente creado al canjearlo
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for EnrollmentToken model
+func (EnrollmentToken) TableName() string {
+	return "enrollment_tokens"
+}
+
+// BeforeCreate hook for EnrollmentToken
+func (t *EnrollmentToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsUsable checks if the token can still be redeemed
+func (t *EnrollmentToken) IsUsable() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
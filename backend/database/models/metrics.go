@@ -6,6 +6,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// Metric rollup source types
+const (
+	MetricSourceServer = "server"
+	MetricSourceAgent  = "agent"
+)
+
 // ServerMetric represents performance metrics for a server
 type ServerMetric struct {
 	ID            uint      `gorm:"primary_key;autoIncrement" json:"id"`
@@ -13,8 +19,11 @@ type ServerMetric struct {
 	Timestamp     time.Time `gorm:"default:CURRENT_TIMESTAMP;index:idx_metrics_server_timestamp" json:"timestamp"`
 	CPUPercent    float64   `json:"cpu_percent"`
 	MemoryUsed    int64     `json:"memory_used"`
-	PlayersOnline int       `json:"players_online"`
-	TPS           float64   `json:"tps"`
+	DiskUsed      int64     `json:"disk_used"`
+	Threads       int       `json:"threads"`
+	PlayersOnline *int      `json:"players_online"` // Nil when the agent had no game stats
+	TPS           *float64  `json:"tps"`            // Nil when the server software does not report it
+	MSPT          *float64  `json:"mspt"`
 	UptimeSeconds int64     `json:"uptime_seconds"`
 
 	// Relations
@@ -25,3 +34,39 @@ type ServerMetric struct {
 func (ServerMetric) TableName() string {
 	return "server_metrics"
 }
+
+// AgentMetric represents system metrics of an agent host
+type AgentMetric struct {
+	ID            uint      `gorm:"primary_key;autoIncrement" json:"id"`
+	AgentID       uuid.UUID `gorm:"type:uuid;not null;index:idx_agent_metrics_agent_timestamp" json:"agent_id"`
+	Timestamp     time.Time `gorm:"not null;index:idx_agent_metrics_agent_timestamp" json:"timestamp"`
+	CPUPercent    float64   `json:"cpu_percent"`
+	MemoryUsed    int64     `json:"memory_used"`
+	MemoryPercent float64   `json:"memory_percent"`
+	DiskUsed      int64     `json:"disk_used"`
+	DiskPercent   float64   `json:"disk_percent"`
+}
+
+// TableName specifies the table name for AgentMetric model
+func (AgentMetric) TableName() string {
+	return "agent_metrics"
+}
+
+// MetricRollup aggregates the samples of one metric over a time bucket
+type MetricRollup struct {
+	ID          uint      `gorm:"primary_key;autoIncrement" json:"-"`
+	SourceType  string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_metric_rollups_key,priority:1" json:"source_type"`
+	SourceID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_metric_rollups_key,priority:2" json:"source_id"`
+	Resolution  string    `gorm:"type:varchar(4);not null;uniqueIndex:idx_metric_rollups_key,priority:3;index:idx_metric_rollups_resolution,priority:1" json:"resolution"` // 1m, 5m, 1h
+	Metric      string    `gorm:"type:varchar(32);not null;uniqueIndex:idx_metric_rollups_key,priority:4" json:"metric"`
+	BucketStart time.Time `gorm:"not null;uniqueIndex:idx_metric_rollups_key,priority:5;index:idx_metric_rollups_resolution,priority:2" json:"bucket_start"`
+	Count       int64     `gorm:"not null" json:"count"`
+	Sum         float64   `gorm:"not null" json:"sum"`
+	Min         float64   `gorm:"not null" json:"min"`
+	Max         float64   `gorm:"not null" json:"max"`
+}
+
+// TableName specifies the table name for MetricRollup model
+func (MetricRollup) TableName() string {
+	return "metric_rollups"
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// TaskActionType represents a step of a scheduled task
+type TaskActionType string
+
+const (
+	TaskActionCommand TaskActionType = "command" // Send a console command
+	TaskActionWait    TaskActionType = "wait"    // Pause before the next action
+	TaskActionRestart TaskActionType = "restart" // Restart the server, optionally after in-game warnings
+	TaskActionBackup  TaskActionType = "backup"  // Create a backup and wait until it finishes
+	TaskActionStop    TaskActionType = "stop"    // Stop the server, optionally after in-game warnings
+	TaskActionStart   TaskActionType = "start"   // Start the server
+)
+
+// TaskRunStatus represents the state of a scheduled task run
+type TaskRunStatus string
+
+const (
+	TaskRunRunning TaskRunStatus = "running"
+	TaskRunSuccess TaskRunStatus = "success"
+	TaskRunFailed  TaskRunStatus = "failed"
+	TaskRunSkipped TaskRunStatus = "skipped" // Server was not running and the task skips stopped servers
+)
+
+// Scheduled task run triggers
+const (
+	TaskTriggerSchedule = "schedule"
+	TaskTriggerManual   = "manual"
+)
+
+// TaskAction represents one action of a scheduled task
+type TaskAction struct {
+	Type            TaskActionType `json:"type" validate:"required,oneof=command wait restart backup stop start"`
+	Command         string         `json:"command,omitempty"`           // command: console command, without leading slash
+	Seconds         int            `json:"seconds,omitempty"`           // wait: pause duration
+	Warnings        []int          `json:"warnings,omitempty"`          // restart, stop: seconds before the action to warn players, e.g. [300, 60, 10]
+	Message         string         `json:"message,omitempty"`           // restart, stop: warning text, {time} is replaced by the remaining time
+	ContinueOnError bool           `json:"continue_on_error,omitempty"` // Run the next actions even if this one fails
+}
+
+// ScheduledTask represents a cron-scheduled sequence of actions on a server
+type ScheduledTask struct {
+	ID            uuid.UUID                       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ServerID      uuid.UUID                       `gorm:"type:uuid;not null;index" json:"server_id"`
+	UserID        uuid.UUID                       `gorm:"type:uuid;not null;index" json:"user_id"` // Owner of the server
+	Name          string                          `gorm:"size:100;not null" json:"name"`
+	Schedule      string                          `gorm:"size:100;not null" json:"schedule"` // Cron expression, optional seconds field
+	Actions       datatypes.JSONSlice[TaskAction] `gorm:"type:jsonb;not null" json:"actions"`
+	Enabled       bool                            `json:"enabled"`
+	SkipIfStopped bool                            `json:"skip_if_stopped"` // Skip runs while the server is not running
+	LastRunAt     *time.Time                      `json:"last_run_at,omitempty"`
+	LastRunStatus TaskRunStatus                   `gorm:"size:20" json:"last_run_status,omitempty"`
+	NextRunAt     *time.Time                      `json:"next_run_at,omitempty"`
+	CreatedAt     time.Time                       `json:"created_at"`
+	UpdatedAt     time.Time                       `json:"updated_at"`
+}
+
+// TableName specifies the table name for ScheduledTask model
+func (ScheduledTask) TableName() string {
+	return "scheduled_tasks"
+}
+
+// BeforeCreate hook for ScheduledTask
+func (t *ScheduledTask) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// TaskStepResult represents the outcome of one action of a task run
+type TaskStepResult struct {
+	Index      int            `json:"index"`
+	Type       TaskActionType `json:"type"`
+	Status     TaskRunStatus  `json:"status"` // success, failed
+	Output     string         `json:"output,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	StartedAt  time.Time      `json:"started_at"`
+	DurationMs int64          `json:"duration_ms"`
+}
+
+// ScheduledTaskRun represents one execution of a scheduled task
+type ScheduledTaskRun struct {
+	ID          uuid.UUID                           `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TaskID      uuid.UUID                           `gorm:"type:uuid;not null;index" json:"task_id"`
+	ServerID    uuid.UUID                           `gorm:"type:uuid;not null;index" json:"server_id"`
+	Trigger     string                              `gorm:"size:20;not null" json:"trigger"` // schedule, manual
+	TriggeredBy *uuid.UUID                          `gorm:"type:uuid" json:"triggered_by,omitempty"`
+	Status      TaskRunStatus                       `gorm:"size:20;not null;index" json:"status"`
+	Steps       datatypes.JSONSlice[TaskStepResult] `gorm:"type:jsonb" json:"steps"`
+	FailedStep  *int                                `json:"failed_step,omitempty"` // Index of the first failed action
+	Error       string                              `gorm:"type:text" json:"error,omitempty"`
+	StartedAt   time.Time                           `gorm:"not null;index" json:"started_at"`
+	FinishedAt  *time.Time                          `json:"finished_at,omitempty"`
+	DurationMs  int64                               `json:"duration_ms"`
+}
+
+// TableName specifies the table name for ScheduledTaskRun model
+func (ScheduledTaskRun) TableName() string {
+	return "scheduled_task_runs"
+}
+
+// BeforeCreate hook for ScheduledTaskRun
+func (r *ScheduledTaskRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsFinished returns true once the run has ended
+func (r *ScheduledTaskRun) IsFinished() bool {
+	return r.Status != TaskRunRunning
+}
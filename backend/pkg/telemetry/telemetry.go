@@ -0,0 +1,122 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Namespace prefixes every metric exported by the backend
+const Namespace = "aymc"
+
+// Registry holds the backend Prometheus metrics. It is separate from the
+// default registry so only AYMC, Go runtime and process metrics are exported.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// GRPCClientDuration measures unary calls from the backend to agents
+	GRPCClientDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "grpc_client",
+		Name:      "handling_seconds",
+		Help:      "Latency of unary gRPC calls to agents.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"grpc_service", "grpc_method", "grpc_code"})
+
+	// GRPCClientStreams counts streams opened to agents
+	GRPCClientStreams = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "grpc_client",
+		Name:      "streams_started_total",
+		Help:      "Streaming gRPC calls opened to agents.",
+	}, []string{"grpc_service", "grpc_method"})
+
+	// AgentHealthChecks counts health monitor pings by result
+	AgentHealthChecks = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "agent",
+		Name:      "health_checks_total",
+		Help:      "Agent health checks run by the health monitor.",
+	}, []string{"result"}) // success, failure
+
+	// BackupJobs counts finished backup jobs by type and outcome
+	BackupJobs = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "backup",
+		Name:      "jobs_total",
+		Help:      "Finished backup jobs.",
+	}, []string{"type", "status"}) // status: completed, failed
+
+	// BackupDuration measures successful backup jobs
+	BackupDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "backup",
+		Name:      "duration_seconds",
+		Help:      "Duration of completed backup jobs.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 14), // 1s .. ~2h
+	}, []string{"type"})
+
+	// BackupSize measures the archives of successful backup jobs
+	BackupSize = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "backup",
+		Name:      "size_bytes",
+		Help:      "Size of completed backup archives.",
+		Buckets:   prometheus.ExponentialBuckets(1<<20, 4, 10), // 1MiB .. 256GiB
+	}, []string{"type"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the registry in the Prometheus text or OpenMetrics format,
+// depending on the Accept header of the scraper
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	})
+}
+
+// UnaryClientInterceptor records the latency and status code of unary calls
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		service, name := splitMethod(method)
+		GRPCClientDuration.WithLabelValues(service, name, status.Code(err).String()).
+			Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// StreamClientInterceptor counts opened streams. Their latency is not
+// recorded: streams last as long as the subscription that opened them.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		service, name := splitMethod(method)
+		GRPCClientStreams.WithLabelValues(service, name).Inc()
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// splitMethod splits "/package.Service/Method" into service and method
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSplitMethod(t *testing.T) {
+	service, method := splitMethod("/aymc.agent.AgentService/StartServer")
+	if service != "aymc.agent.AgentService" || method != "StartServer" {
+		t.Errorf("got %q, %q", service, method)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.NotFound, "server not found")
+	}
+
+	err := interceptor(context.Background(), "/aymc.agent.AgentService/GetServer", nil, nil, nil, invoker)
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("interceptor changed the error: %v", err)
+	}
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "aymc_grpc_client_handling_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["grpc_method"] == "GetServer" && labels["grpc_code"] == "NotFound" &&
+				metric.GetHistogram().GetSampleCount() == 1 {
+				return
+			}
+		}
+	}
+	t.Error("GetServer call with code NotFound was not recorded")
+}
@@ -290,6 +290,9 @@ type ServerInfo struct {
 	StartTime     int64                  `protobuf:"varint,9,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
 	WorkDir       string                 `protobuf:"bytes,10,opt,name=work_dir,json=workDir,proto3" json:"work_dir,omitempty"`
 	Config        *ServerConfig          `protobuf:"bytes,11,opt,name=config,proto3" json:"config,omitempty"`
+	RestartCount  int32                  `protobuf:"varint,12,opt,name=restart_count,json=restartCount,proto3" json:"restart_count,omitempty"` // reinicios automáticos en la ventana actual
+	LastExitCode  int32                  `protobuf:"varint,13,opt,name=last_exit_code,json=lastExitCode,proto3" json:"last_exit_code,omitempty"`
+	Metrics       *ServerMetrics         `protobuf:"bytes,14,opt,name=metrics,proto3" json:"metrics,omitempty"` // solo si el proceso está en ejecución
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -401,6 +404,202 @@ func (x *ServerInfo) GetConfig() *ServerConfig {
 	return nil
 }
 
+func (x *ServerInfo) GetRestartCount() int32 {
+	if x != nil {
+		return x.RestartCount
+	}
+	return 0
+}
+
+func (x *ServerInfo) GetLastExitCode() int32 {
+	if x != nil {
+		return x.LastExitCode
+	}
+	return 0
+}
+
+func (x *ServerInfo) GetMetrics() *ServerMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+// Uso de recursos del proceso de un servidor (Java y sus hijos)
+type ServerMetrics struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ServerId     string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Timestamp    int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Pid          int32                  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
+	CpuPercent   float64                `protobuf:"fixed64,4,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"` // 100 = un núcleo completo
+	MemoryRss    uint64                 `protobuf:"varint,5,opt,name=memory_rss,json=memoryRss,proto3" json:"memory_rss,omitempty"`
+	Threads      int32                  `protobuf:"varint,6,opt,name=threads,proto3" json:"threads,omitempty"`
+	OpenFiles    int32                  `protobuf:"varint,7,opt,name=open_files,json=openFiles,proto3" json:"open_files,omitempty"`
+	ProcessCount int32                  `protobuf:"varint,8,opt,name=process_count,json=processCount,proto3" json:"process_count,omitempty"` // proceso principal y descendientes
+	DiskUsed     uint64                 `protobuf:"varint,9,opt,name=disk_used,json=diskUsed,proto3" json:"disk_used,omitempty"`             // tamaño del directorio de trabajo
+	// Estadísticas de juego (Server List Ping, Query y consola de Paper),
+	// recolectadas periódicamente. stats_timestamp 0 = aún sin datos.
+	StatsTimestamp int64    `protobuf:"varint,10,opt,name=stats_timestamp,json=statsTimestamp,proto3" json:"stats_timestamp,omitempty"`
+	GameVersion    string   `protobuf:"bytes,11,opt,name=game_version,json=gameVersion,proto3" json:"game_version,omitempty"`
+	PlayersOnline  int32    `protobuf:"varint,12,opt,name=players_online,json=playersOnline,proto3" json:"players_online,omitempty"`
+	MaxPlayers     int32    `protobuf:"varint,13,opt,name=max_players,json=maxPlayers,proto3" json:"max_players,omitempty"`
+	Players        []string `protobuf:"bytes,14,rep,name=players,proto3" json:"players,omitempty"`
+	LatencyMs      int64    `protobuf:"varint,15,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	Tps            float64  `protobuf:"fixed64,16,opt,name=tps,proto3" json:"tps,omitempty"`   // media del último minuto, 0 si no disponible
+	Mspt           float64  `protobuf:"fixed64,17,opt,name=mspt,proto3" json:"mspt,omitempty"` // media del último minuto, 0 si no disponible
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ServerMetrics) Reset() {
+	*x = ServerMetrics{}
+	mi := &file_proto_agent_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMetrics) ProtoMessage() {}
+
+func (x *ServerMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMetrics.ProtoReflect.Descriptor instead.
+func (*ServerMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ServerMetrics) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *ServerMetrics) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetMemoryRss() uint64 {
+	if x != nil {
+		return x.MemoryRss
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetThreads() int32 {
+	if x != nil {
+		return x.Threads
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetOpenFiles() int32 {
+	if x != nil {
+		return x.OpenFiles
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetProcessCount() int32 {
+	if x != nil {
+		return x.ProcessCount
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetDiskUsed() uint64 {
+	if x != nil {
+		return x.DiskUsed
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetStatsTimestamp() int64 {
+	if x != nil {
+		return x.StatsTimestamp
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetGameVersion() string {
+	if x != nil {
+		return x.GameVersion
+	}
+	return ""
+}
+
+func (x *ServerMetrics) GetPlayersOnline() int32 {
+	if x != nil {
+		return x.PlayersOnline
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetMaxPlayers() int32 {
+	if x != nil {
+		return x.MaxPlayers
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetPlayers() []string {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+func (x *ServerMetrics) GetLatencyMs() int64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetTps() float64 {
+	if x != nil {
+		return x.Tps
+	}
+	return 0
+}
+
+func (x *ServerMetrics) GetMspt() float64 {
+	if x != nil {
+		return x.Mspt
+	}
+	return 0
+}
+
 type ServerConfig struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MinRam        string                 `protobuf:"bytes,1,opt,name=min_ram,json=minRam,proto3" json:"min_ram,omitempty"`
@@ -415,7 +614,7 @@ type ServerConfig struct {
 
 func (x *ServerConfig) Reset() {
 	*x = ServerConfig{}
-	mi := &file_proto_agent_proto_msgTypes[4]
+	mi := &file_proto_agent_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -427,7 +626,7 @@ func (x *ServerConfig) String() string {
 func (*ServerConfig) ProtoMessage() {}
 
 func (x *ServerConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[4]
+	mi := &file_proto_agent_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -440,7 +639,7 @@ func (x *ServerConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerConfig.ProtoReflect.Descriptor instead.
 func (*ServerConfig) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{4}
+	return file_proto_agent_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ServerConfig) GetMinRam() string {
@@ -494,7 +693,7 @@ type ServerList struct {
 
 func (x *ServerList) Reset() {
 	*x = ServerList{}
-	mi := &file_proto_agent_proto_msgTypes[5]
+	mi := &file_proto_agent_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -506,7 +705,7 @@ func (x *ServerList) String() string {
 func (*ServerList) ProtoMessage() {}
 
 func (x *ServerList) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[5]
+	mi := &file_proto_agent_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -519,7 +718,7 @@ func (x *ServerList) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerList.ProtoReflect.Descriptor instead.
 func (*ServerList) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{5}
+	return file_proto_agent_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ServerList) GetServers() []*ServerInfo {
@@ -538,7 +737,7 @@ type ServerRequest struct {
 
 func (x *ServerRequest) Reset() {
 	*x = ServerRequest{}
-	mi := &file_proto_agent_proto_msgTypes[6]
+	mi := &file_proto_agent_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -550,7 +749,7 @@ func (x *ServerRequest) String() string {
 func (*ServerRequest) ProtoMessage() {}
 
 func (x *ServerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[6]
+	mi := &file_proto_agent_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -563,7 +762,7 @@ func (x *ServerRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerRequest.ProtoReflect.Descriptor instead.
 func (*ServerRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{6}
+	return file_proto_agent_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ServerRequest) GetServerId() string {
@@ -586,7 +785,7 @@ type StartServerRequest struct {
 
 func (x *StartServerRequest) Reset() {
 	*x = StartServerRequest{}
-	mi := &file_proto_agent_proto_msgTypes[7]
+	mi := &file_proto_agent_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -598,7 +797,7 @@ func (x *StartServerRequest) String() string {
 func (*StartServerRequest) ProtoMessage() {}
 
 func (x *StartServerRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[7]
+	mi := &file_proto_agent_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -611,7 +810,7 @@ func (x *StartServerRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartServerRequest.ProtoReflect.Descriptor instead.
 func (*StartServerRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{7}
+	return file_proto_agent_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *StartServerRequest) GetServerId() string {
@@ -660,7 +859,7 @@ type ServerResponse struct {
 
 func (x *ServerResponse) Reset() {
 	*x = ServerResponse{}
-	mi := &file_proto_agent_proto_msgTypes[8]
+	mi := &file_proto_agent_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -672,7 +871,7 @@ func (x *ServerResponse) String() string {
 func (*ServerResponse) ProtoMessage() {}
 
 func (x *ServerResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[8]
+	mi := &file_proto_agent_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -685,7 +884,7 @@ func (x *ServerResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerResponse.ProtoReflect.Descriptor instead.
 func (*ServerResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{8}
+	return file_proto_agent_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ServerResponse) GetSuccess() bool {
@@ -709,30 +908,37 @@ func (x *ServerResponse) GetServer() *ServerInfo {
 	return nil
 }
 
-// Comandos
-type CommandRequest struct {
+// Eventos de ciclo de vida del servidor
+type ServerEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
-	Command       string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ServerId      string                 `protobuf:"bytes,2,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // started, stopped, crashed, restart_attempt, restart_failed, restart_limit
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	ExitCode      int32                  `protobuf:"varint,5,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Attempt       int32                  `protobuf:"varint,6,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	MaxAttempts   int32                  `protobuf:"varint,7,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`
+	BackoffMs     int64                  `protobuf:"varint,8,opt,name=backoff_ms,json=backoffMs,proto3" json:"backoff_ms,omitempty"`
+	Reason        string                 `protobuf:"bytes,9,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CommandRequest) Reset() {
-	*x = CommandRequest{}
-	mi := &file_proto_agent_proto_msgTypes[9]
+func (x *ServerEvent) Reset() {
+	*x = ServerEvent{}
+	mi := &file_proto_agent_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CommandRequest) String() string {
+func (x *ServerEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CommandRequest) ProtoMessage() {}
+func (*ServerEvent) ProtoMessage() {}
 
-func (x *CommandRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[9]
+func (x *ServerEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -743,114 +949,98 @@ func (x *CommandRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CommandRequest.ProtoReflect.Descriptor instead.
-func (*CommandRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ServerEvent.ProtoReflect.Descriptor instead.
+func (*ServerEvent) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *CommandRequest) GetServerId() string {
+func (x *ServerEvent) GetTimestamp() int64 {
 	if x != nil {
-		return x.ServerId
+		return x.Timestamp
 	}
-	return ""
+	return 0
 }
 
-func (x *CommandRequest) GetCommand() string {
+func (x *ServerEvent) GetServerId() string {
 	if x != nil {
-		return x.Command
+		return x.ServerId
 	}
 	return ""
 }
 
-type CommandResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Output        string                 `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *CommandResponse) Reset() {
-	*x = CommandResponse{}
-	mi := &file_proto_agent_proto_msgTypes[10]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ServerEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
 }
 
-func (x *CommandResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ServerEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
 }
 
-func (*CommandResponse) ProtoMessage() {}
-
-func (x *CommandResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[10]
+func (x *ServerEvent) GetExitCode() int32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.ExitCode
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use CommandResponse.ProtoReflect.Descriptor instead.
-func (*CommandResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{10}
+func (x *ServerEvent) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
 }
 
-func (x *CommandResponse) GetSuccess() bool {
+func (x *ServerEvent) GetMaxAttempts() int32 {
 	if x != nil {
-		return x.Success
+		return x.MaxAttempts
 	}
-	return false
+	return 0
 }
 
-func (x *CommandResponse) GetMessage() string {
+func (x *ServerEvent) GetBackoffMs() int64 {
 	if x != nil {
-		return x.Message
+		return x.BackoffMs
 	}
-	return ""
+	return 0
 }
 
-func (x *CommandResponse) GetOutput() string {
+func (x *ServerEvent) GetReason() string {
 	if x != nil {
-		return x.Output
+		return x.Reason
 	}
 	return ""
 }
 
-// Logs
-type LogEntry struct {
+// Stream de eventos del agente
+type EventStreamRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	ServerId      string                 `protobuf:"bytes,2,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
-	Level         string                 `protobuf:"bytes,3,opt,name=level,proto3" json:"level,omitempty"`   // INFO, WARN, ERROR, DEBUG
-	Source        string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"` // STDOUT, STDERR
-	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
-	Plugin        string                 `protobuf:"bytes,6,opt,name=plugin,proto3" json:"plugin,omitempty"` // plugin que generó el log (si se detecta)
-	File          string                 `protobuf:"bytes,7,opt,name=file,proto3" json:"file,omitempty"`     // archivo de origen (si se detecta)
-	Line          int32                  `protobuf:"varint,8,opt,name=line,proto3" json:"line,omitempty"`    // línea de origen (si se detecta)
+	EventTypes    []string               `protobuf:"bytes,2,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"` // metrics, server_event, log_alert (vacío = todos)
+	Ping          bool                   `protobuf:"varint,3,opt,name=ping,proto3" json:"ping,omitempty"`                              // heartbeat del backend, el agente responde con pong
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LogEntry) Reset() {
-	*x = LogEntry{}
+func (x *EventStreamRequest) Reset() {
+	*x = EventStreamRequest{}
 	mi := &file_proto_agent_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LogEntry) String() string {
+func (x *EventStreamRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogEntry) ProtoMessage() {}
+func (*EventStreamRequest) ProtoMessage() {}
 
-func (x *LogEntry) ProtoReflect() protoreflect.Message {
+func (x *EventStreamRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -862,91 +1052,62 @@ func (x *LogEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
-func (*LogEntry) Descriptor() ([]byte, []int) {
+// Deprecated: Use EventStreamRequest.ProtoReflect.Descriptor instead.
+func (*EventStreamRequest) Descriptor() ([]byte, []int) {
 	return file_proto_agent_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *LogEntry) GetTimestamp() int64 {
+func (x *EventStreamRequest) GetTimestamp() int64 {
 	if x != nil {
 		return x.Timestamp
 	}
 	return 0
 }
 
-func (x *LogEntry) GetServerId() string {
+func (x *EventStreamRequest) GetEventTypes() []string {
 	if x != nil {
-		return x.ServerId
+		return x.EventTypes
 	}
-	return ""
+	return nil
 }
 
-func (x *LogEntry) GetLevel() string {
+func (x *EventStreamRequest) GetPing() bool {
 	if x != nil {
-		return x.Level
+		return x.Ping
 	}
-	return ""
-}
-
-func (x *LogEntry) GetSource() string {
-	if x != nil {
-		return x.Source
-	}
-	return ""
-}
-
-func (x *LogEntry) GetMessage() string {
-	if x != nil {
-		return x.Message
-	}
-	return ""
-}
-
-func (x *LogEntry) GetPlugin() string {
-	if x != nil {
-		return x.Plugin
-	}
-	return ""
-}
-
-func (x *LogEntry) GetFile() string {
-	if x != nil {
-		return x.File
-	}
-	return ""
-}
-
-func (x *LogEntry) GetLine() int32 {
-	if x != nil {
-		return x.Line
-	}
-	return 0
+	return false
 }
 
-// Archivos
-type FileRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
-	Length        int64                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+type AgentEvent struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	AgentId   string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Sequence  uint64                 `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*AgentEvent_Metrics
+	//	*AgentEvent_ServerEvent
+	//	*AgentEvent_LogAlert
+	//	*AgentEvent_Pong
+	Payload       isAgentEvent_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileRequest) Reset() {
-	*x = FileRequest{}
+func (x *AgentEvent) Reset() {
+	*x = AgentEvent{}
 	mi := &file_proto_agent_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileRequest) String() string {
+func (x *AgentEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileRequest) ProtoMessage() {}
+func (*AgentEvent) ProtoMessage() {}
 
-func (x *FileRequest) ProtoReflect() protoreflect.Message {
+func (x *AgentEvent) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -958,55 +1119,132 @@ func (x *FileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileRequest.ProtoReflect.Descriptor instead.
-func (*FileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use AgentEvent.ProtoReflect.Descriptor instead.
+func (*AgentEvent) Descriptor() ([]byte, []int) {
 	return file_proto_agent_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *FileRequest) GetPath() string {
+func (x *AgentEvent) GetTimestamp() int64 {
 	if x != nil {
-		return x.Path
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *AgentEvent) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *FileRequest) GetOffset() int64 {
+func (x *AgentEvent) GetSequence() uint64 {
 	if x != nil {
-		return x.Offset
+		return x.Sequence
 	}
 	return 0
 }
 
-func (x *FileRequest) GetLength() int64 {
+func (x *AgentEvent) GetPayload() isAgentEvent_Payload {
 	if x != nil {
-		return x.Length
+		return x.Payload
 	}
-	return 0
+	return nil
 }
 
-type FileContent struct {
+func (x *AgentEvent) GetMetrics() *SystemMetrics {
+	if x != nil {
+		if x, ok := x.Payload.(*AgentEvent_Metrics); ok {
+			return x.Metrics
+		}
+	}
+	return nil
+}
+
+func (x *AgentEvent) GetServerEvent() *ServerEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*AgentEvent_ServerEvent); ok {
+			return x.ServerEvent
+		}
+	}
+	return nil
+}
+
+func (x *AgentEvent) GetLogAlert() *LogAlert {
+	if x != nil {
+		if x, ok := x.Payload.(*AgentEvent_LogAlert); ok {
+			return x.LogAlert
+		}
+	}
+	return nil
+}
+
+func (x *AgentEvent) GetPong() *PongResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*AgentEvent_Pong); ok {
+			return x.Pong
+		}
+	}
+	return nil
+}
+
+type isAgentEvent_Payload interface {
+	isAgentEvent_Payload()
+}
+
+type AgentEvent_Metrics struct {
+	Metrics *SystemMetrics `protobuf:"bytes,10,opt,name=metrics,proto3,oneof"`
+}
+
+type AgentEvent_ServerEvent struct {
+	ServerEvent *ServerEvent `protobuf:"bytes,11,opt,name=server_event,json=serverEvent,proto3,oneof"`
+}
+
+type AgentEvent_LogAlert struct {
+	LogAlert *LogAlert `protobuf:"bytes,12,opt,name=log_alert,json=logAlert,proto3,oneof"`
+}
+
+type AgentEvent_Pong struct {
+	Pong *PongResponse `protobuf:"bytes,13,opt,name=pong,proto3,oneof"`
+}
+
+func (*AgentEvent_Metrics) isAgentEvent_Payload() {}
+
+func (*AgentEvent_ServerEvent) isAgentEvent_Payload() {}
+
+func (*AgentEvent_LogAlert) isAgentEvent_Payload() {}
+
+func (*AgentEvent_Pong) isAgentEvent_Payload() {}
+
+// Alerta detectada en los logs de un servidor
+type LogAlert struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Content       []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
-	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
-	ModifiedTime  int64                  `protobuf:"varint,3,opt,name=modified_time,json=modifiedTime,proto3" json:"modified_time,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ServerId      string                 `protobuf:"bytes,2,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Level         string                 `protobuf:"bytes,3,opt,name=level,proto3" json:"level,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	ErrorType     string                 `protobuf:"bytes,5,opt,name=error_type,json=errorType,proto3" json:"error_type,omitempty"`
+	Severity      int32                  `protobuf:"varint,6,opt,name=severity,proto3" json:"severity,omitempty"` // 1 (baja) - 5 (crítica), ver core.ErrorPattern
+	Suggestion    string                 `protobuf:"bytes,7,opt,name=suggestion,proto3" json:"suggestion,omitempty"`
+	Plugin        string                 `protobuf:"bytes,8,opt,name=plugin,proto3" json:"plugin,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileContent) Reset() {
-	*x = FileContent{}
+func (x *LogAlert) Reset() {
+	*x = LogAlert{}
 	mi := &file_proto_agent_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileContent) String() string {
+func (x *LogAlert) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileContent) ProtoMessage() {}
+func (*LogAlert) ProtoMessage() {}
 
-func (x *FileContent) ProtoReflect() protoreflect.Message {
+func (x *LogAlert) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1018,55 +1256,90 @@ func (x *FileContent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileContent.ProtoReflect.Descriptor instead.
-func (*FileContent) Descriptor() ([]byte, []int) {
+// Deprecated: Use LogAlert.ProtoReflect.Descriptor instead.
+func (*LogAlert) Descriptor() ([]byte, []int) {
 	return file_proto_agent_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *FileContent) GetContent() []byte {
+func (x *LogAlert) GetTimestamp() int64 {
 	if x != nil {
-		return x.Content
+		return x.Timestamp
 	}
-	return nil
+	return 0
 }
 
-func (x *FileContent) GetSize() int64 {
+func (x *LogAlert) GetServerId() string {
 	if x != nil {
-		return x.Size
+		return x.ServerId
 	}
-	return 0
+	return ""
 }
 
-func (x *FileContent) GetModifiedTime() int64 {
+func (x *LogAlert) GetLevel() string {
 	if x != nil {
-		return x.ModifiedTime
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogAlert) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogAlert) GetErrorType() string {
+	if x != nil {
+		return x.ErrorType
+	}
+	return ""
+}
+
+func (x *LogAlert) GetSeverity() int32 {
+	if x != nil {
+		return x.Severity
 	}
 	return 0
 }
 
-type WriteFileRequest struct {
+func (x *LogAlert) GetSuggestion() string {
+	if x != nil {
+		return x.Suggestion
+	}
+	return ""
+}
+
+func (x *LogAlert) GetPlugin() string {
+	if x != nil {
+		return x.Plugin
+	}
+	return ""
+}
+
+// Comandos
+type CommandRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
-	CreateDirs    bool                   `protobuf:"varint,3,opt,name=create_dirs,json=createDirs,proto3" json:"create_dirs,omitempty"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Command       string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WriteFileRequest) Reset() {
-	*x = WriteFileRequest{}
+func (x *CommandRequest) Reset() {
+	*x = CommandRequest{}
 	mi := &file_proto_agent_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WriteFileRequest) String() string {
+func (x *CommandRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WriteFileRequest) ProtoMessage() {}
+func (*CommandRequest) ProtoMessage() {}
 
-func (x *WriteFileRequest) ProtoReflect() protoreflect.Message {
+func (x *CommandRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1078,54 +1351,48 @@ func (x *WriteFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WriteFileRequest.ProtoReflect.Descriptor instead.
-func (*WriteFileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use CommandRequest.ProtoReflect.Descriptor instead.
+func (*CommandRequest) Descriptor() ([]byte, []int) {
 	return file_proto_agent_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *WriteFileRequest) GetPath() string {
+func (x *CommandRequest) GetServerId() string {
 	if x != nil {
-		return x.Path
+		return x.ServerId
 	}
 	return ""
 }
 
-func (x *WriteFileRequest) GetContent() []byte {
-	if x != nil {
-		return x.Content
-	}
-	return nil
-}
-
-func (x *WriteFileRequest) GetCreateDirs() bool {
+func (x *CommandRequest) GetCommand() string {
 	if x != nil {
-		return x.CreateDirs
+		return x.Command
 	}
-	return false
+	return ""
 }
 
-type FileResponse struct {
+type CommandResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Output        string                 `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileResponse) Reset() {
-	*x = FileResponse{}
+func (x *CommandResponse) Reset() {
+	*x = CommandResponse{}
 	mi := &file_proto_agent_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileResponse) String() string {
+func (x *CommandResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileResponse) ProtoMessage() {}
+func (*CommandResponse) ProtoMessage() {}
 
-func (x *FileResponse) ProtoReflect() protoreflect.Message {
+func (x *CommandResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1137,47 +1404,58 @@ func (x *FileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileResponse.ProtoReflect.Descriptor instead.
-func (*FileResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use CommandResponse.ProtoReflect.Descriptor instead.
+func (*CommandResponse) Descriptor() ([]byte, []int) {
 	return file_proto_agent_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *FileResponse) GetSuccess() bool {
+func (x *CommandResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *FileResponse) GetMessage() string {
+func (x *CommandResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-type DirectoryRequest struct {
+func (x *CommandResponse) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+// Logs
+type StreamLogsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Recursive     bool                   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	TailLines     int32                  `protobuf:"varint,2,opt,name=tail_lines,json=tailLines,proto3" json:"tail_lines,omitempty"`             // reenviar las últimas N líneas antes de seguir en vivo
+	Since         int64                  `protobuf:"varint,3,opt,name=since,proto3" json:"since,omitempty"`                                      // reenviar líneas desde este unix timestamp
+	AfterSequence uint64                 `protobuf:"varint,4,opt,name=after_sequence,json=afterSequence,proto3" json:"after_sequence,omitempty"` // reanudar tras la última secuencia recibida
+	NoHistory     bool                   `protobuf:"varint,5,opt,name=no_history,json=noHistory,proto3" json:"no_history,omitempty"`             // solo líneas nuevas
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DirectoryRequest) Reset() {
-	*x = DirectoryRequest{}
+func (x *StreamLogsRequest) Reset() {
+	*x = StreamLogsRequest{}
 	mi := &file_proto_agent_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DirectoryRequest) String() string {
+func (x *StreamLogsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DirectoryRequest) ProtoMessage() {}
+func (*StreamLogsRequest) ProtoMessage() {}
 
-func (x *DirectoryRequest) ProtoReflect() protoreflect.Message {
+func (x *StreamLogsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1189,46 +1467,81 @@ func (x *DirectoryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DirectoryRequest.ProtoReflect.Descriptor instead.
-func (*DirectoryRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use StreamLogsRequest.ProtoReflect.Descriptor instead.
+func (*StreamLogsRequest) Descriptor() ([]byte, []int) {
 	return file_proto_agent_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *DirectoryRequest) GetPath() string {
+func (x *StreamLogsRequest) GetServerId() string {
 	if x != nil {
-		return x.Path
+		return x.ServerId
 	}
 	return ""
 }
 
-func (x *DirectoryRequest) GetRecursive() bool {
+func (x *StreamLogsRequest) GetTailLines() int32 {
 	if x != nil {
-		return x.Recursive
+		return x.TailLines
 	}
-	return false
+	return 0
 }
 
-type FileList struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *StreamLogsRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
 }
 
-func (x *FileList) Reset() {
-	*x = FileList{}
-	mi := &file_proto_agent_proto_msgTypes[17]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *StreamLogsRequest) GetAfterSequence() uint64 {
+	if x != nil {
+		return x.AfterSequence
+	}
+	return 0
 }
 
-func (x *FileList) String() string {
+func (x *StreamLogsRequest) GetNoHistory() bool {
+	if x != nil {
+		return x.NoHistory
+	}
+	return false
+}
+
+type LogEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ServerId      string                 `protobuf:"bytes,2,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Level         string                 `protobuf:"bytes,3,opt,name=level,proto3" json:"level,omitempty"`   // DEBUG, INFO, WARN, ERROR, SEVERE, UNKNOWN
+	Source        string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"` // SERVER, ASYNC, NETWORK, WORLD, PLAYER, PLUGIN
+	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	Plugin        string                 `protobuf:"bytes,6,opt,name=plugin,proto3" json:"plugin,omitempty"`                            // plugin que generó el log (si se detecta)
+	File          string                 `protobuf:"bytes,7,opt,name=file,proto3" json:"file,omitempty"`                                // archivo de origen (si se detecta)
+	Line          int32                  `protobuf:"varint,8,opt,name=line,proto3" json:"line,omitempty"`                               // línea de origen (si se detecta)
+	Sequence      uint64                 `protobuf:"varint,9,opt,name=sequence,proto3" json:"sequence,omitempty"`                       // secuencia monótona por servidor (última línea incluida)
+	Thread        string                 `protobuf:"bytes,10,opt,name=thread,proto3" json:"thread,omitempty"`                           // hilo que generó el log (ej: Server thread)
+	Stream        string                 `protobuf:"bytes,11,opt,name=stream,proto3" json:"stream,omitempty"`                           // STDOUT, STDERR
+	ErrorType     string                 `protobuf:"bytes,12,opt,name=error_type,json=errorType,proto3" json:"error_type,omitempty"`    // patrón de error conocido (si se detecta)
+	Severity      int32                  `protobuf:"varint,13,opt,name=severity,proto3" json:"severity,omitempty"`                      // 1-5
+	Suggestion    string                 `protobuf:"bytes,14,opt,name=suggestion,proto3" json:"suggestion,omitempty"`                   // solución sugerida para el error
+	StackTrace    []string               `protobuf:"bytes,15,rep,name=stack_trace,json=stackTrace,proto3" json:"stack_trace,omitempty"` // líneas de stack trace agrupadas
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	mi := &file_proto_agent_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileList) ProtoMessage() {}
+func (*LogEntry) ProtoMessage() {}
 
-func (x *FileList) ProtoReflect() protoreflect.Message {
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1240,45 +1553,2307 @@ func (x *FileList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileList.ProtoReflect.Descriptor instead.
-func (*FileList) Descriptor() ([]byte, []int) {
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
 	return file_proto_agent_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *FileList) GetFiles() []*FileInfo {
+func (x *LogEntry) GetTimestamp() int64 {
 	if x != nil {
-		return x.Files
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *LogEntry) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *LogEntry) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogEntry) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *LogEntry) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogEntry) GetPlugin() string {
+	if x != nil {
+		return x.Plugin
+	}
+	return ""
+}
+
+func (x *LogEntry) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *LogEntry) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *LogEntry) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *LogEntry) GetThread() string {
+	if x != nil {
+		return x.Thread
+	}
+	return ""
+}
+
+func (x *LogEntry) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *LogEntry) GetErrorType() string {
+	if x != nil {
+		return x.ErrorType
+	}
+	return ""
+}
+
+func (x *LogEntry) GetSeverity() int32 {
+	if x != nil {
+		return x.Severity
+	}
+	return 0
+}
+
+func (x *LogEntry) GetSuggestion() string {
+	if x != nil {
+		return x.Suggestion
+	}
+	return ""
+}
+
+func (x *LogEntry) GetStackTrace() []string {
+	if x != nil {
+		return x.StackTrace
 	}
 	return nil
 }
 
-type FileInfo struct {
+// Archivos. Con server_id las rutas son relativas al directorio del
+// servidor; sin él deben ser absolutas y estar en file_roots del agente.
+type FileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
-	IsDir         bool                   `protobuf:"varint,4,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
-	ModifiedTime  int64                  `protobuf:"varint,5,opt,name=modified_time,json=modifiedTime,proto3" json:"modified_time,omitempty"`
-	Permissions   int32                  `protobuf:"varint,6,opt,name=permissions,proto3" json:"permissions,omitempty"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Length        int64                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	ServerId      string                 `protobuf:"bytes,4,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FileInfo) Reset() {
-	*x = FileInfo{}
+func (x *FileRequest) Reset() {
+	*x = FileRequest{}
+	mi := &file_proto_agent_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileRequest) ProtoMessage() {}
+
+func (x *FileRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileRequest.ProtoReflect.Descriptor instead.
+func (*FileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *FileRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *FileRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+type FileContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	ModifiedTime  int64                  `protobuf:"varint,3,opt,name=modified_time,json=modifiedTime,proto3" json:"modified_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileContent) Reset() {
+	*x = FileContent{}
+	mi := &file_proto_agent_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileContent) ProtoMessage() {}
+
+func (x *FileContent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileContent.ProtoReflect.Descriptor instead.
+func (*FileContent) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *FileContent) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *FileContent) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileContent) GetModifiedTime() int64 {
+	if x != nil {
+		return x.ModifiedTime
+	}
+	return 0
+}
+
+type WriteFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	CreateDirs    bool                   `protobuf:"varint,3,opt,name=create_dirs,json=createDirs,proto3" json:"create_dirs,omitempty"`
+	ServerId      string                 `protobuf:"bytes,4,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteFileRequest) Reset() {
+	*x = WriteFileRequest{}
+	mi := &file_proto_agent_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteFileRequest) ProtoMessage() {}
+
+func (x *WriteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteFileRequest.ProtoReflect.Descriptor instead.
+func (*WriteFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WriteFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *WriteFileRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *WriteFileRequest) GetCreateDirs() bool {
+	if x != nil {
+		return x.CreateDirs
+	}
+	return false
+}
+
+func (x *WriteFileRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+type FileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileResponse) Reset() {
+	*x = FileResponse{}
+	mi := &file_proto_agent_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileResponse) ProtoMessage() {}
+
+func (x *FileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileResponse.ProtoReflect.Descriptor instead.
+func (*FileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *FileResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *FileResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type DirectoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Recursive     bool                   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	ServerId      string                 `protobuf:"bytes,3,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirectoryRequest) Reset() {
+	*x = DirectoryRequest{}
+	mi := &file_proto_agent_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirectoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirectoryRequest) ProtoMessage() {}
+
+func (x *DirectoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirectoryRequest.ProtoReflect.Descriptor instead.
+func (*DirectoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DirectoryRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DirectoryRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+func (x *DirectoryRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+type FileList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileList) Reset() {
+	*x = FileList{}
+	mi := &file_proto_agent_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileList) ProtoMessage() {}
+
+func (x *FileList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileList.ProtoReflect.Descriptor instead.
+func (*FileList) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *FileList) GetFiles() []*FileInfo {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type FileInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // relativa al directorio del servidor si se usó server_id
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	IsDir         bool                   `protobuf:"varint,4,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	ModifiedTime  int64                  `protobuf:"varint,5,opt,name=modified_time,json=modifiedTime,proto3" json:"modified_time,omitempty"`
+	Permissions   int32                  `protobuf:"varint,6,opt,name=permissions,proto3" json:"permissions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
+	mi := &file_proto_agent_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileInfo) ProtoMessage() {}
+
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *FileInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileInfo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileInfo) GetIsDir() bool {
+	if x != nil {
+		return x.IsDir
+	}
+	return false
+}
+
+func (x *FileInfo) GetModifiedTime() int64 {
+	if x != nil {
+		return x.ModifiedTime
+	}
+	return 0
+}
+
+func (x *FileInfo) GetPermissions() int32 {
+	if x != nil {
+		return x.Permissions
+	}
+	return 0
+}
+
+type DeleteFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Recursive     bool                   `protobuf:"varint,3,opt,name=recursive,proto3" json:"recursive,omitempty"` // necesario para borrar directorios con contenido
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFileRequest) Reset() {
+	*x = DeleteFileRequest{}
+	mi := &file_proto_agent_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileRequest) ProtoMessage() {}
+
+func (x *DeleteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DeleteFileRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *DeleteFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DeleteFileRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+// Mover/renombrar y copiar
+type MoveFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Destination   string                 `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"`
+	Overwrite     bool                   `protobuf:"varint,4,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveFileRequest) Reset() {
+	*x = MoveFileRequest{}
+	mi := &file_proto_agent_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveFileRequest) ProtoMessage() {}
+
+func (x *MoveFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveFileRequest.ProtoReflect.Descriptor instead.
+func (*MoveFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *MoveFileRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *MoveFileRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *MoveFileRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *MoveFileRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+type CreateDirectoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Parents       bool                   `protobuf:"varint,3,opt,name=parents,proto3" json:"parents,omitempty"` // crear también los directorios intermedios
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDirectoryRequest) Reset() {
+	*x = CreateDirectoryRequest{}
+	mi := &file_proto_agent_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDirectoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDirectoryRequest) ProtoMessage() {}
+
+func (x *CreateDirectoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDirectoryRequest.ProtoReflect.Descriptor instead.
+func (*CreateDirectoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CreateDirectoryRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *CreateDirectoryRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *CreateDirectoryRequest) GetParents() bool {
+	if x != nil {
+		return x.Parents
+	}
+	return false
+}
+
+type ChangePermissionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Mode          uint32                 `protobuf:"varint,3,opt,name=mode,proto3" json:"mode,omitempty"` // permisos unix, p. ej. 0644
+	Recursive     bool                   `protobuf:"varint,4,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangePermissionsRequest) Reset() {
+	*x = ChangePermissionsRequest{}
+	mi := &file_proto_agent_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangePermissionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePermissionsRequest) ProtoMessage() {}
+
+func (x *ChangePermissionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangePermissionsRequest.ProtoReflect.Descriptor instead.
+func (*ChangePermissionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ChangePermissionsRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *ChangePermissionsRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ChangePermissionsRequest) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *ChangePermissionsRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+type CompressFilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Paths         []string               `protobuf:"bytes,2,rep,name=paths,proto3" json:"paths,omitempty"`
+	Destination   string                 `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"`
+	Format        string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"` // zip, tar.gz (vacío = según la extensión de destination)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompressFilesRequest) Reset() {
+	*x = CompressFilesRequest{}
+	mi := &file_proto_agent_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompressFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompressFilesRequest) ProtoMessage() {}
+
+func (x *CompressFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompressFilesRequest.ProtoReflect.Descriptor instead.
+func (*CompressFilesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CompressFilesRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *CompressFilesRequest) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *CompressFilesRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *CompressFilesRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type ExtractArchiveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Destination   string                 `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"` // vacío = directorio del archivo
+	Overwrite     bool                   `protobuf:"varint,4,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractArchiveRequest) Reset() {
+	*x = ExtractArchiveRequest{}
+	mi := &file_proto_agent_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractArchiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractArchiveRequest) ProtoMessage() {}
+
+func (x *ExtractArchiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractArchiveRequest.ProtoReflect.Descriptor instead.
+func (*ExtractArchiveRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ExtractArchiveRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *ExtractArchiveRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ExtractArchiveRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *ExtractArchiveRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+// Transferencia de archivos grandes por fragmentos. Las subidas se escriben
+// en un archivo parcial que sobrevive a una interrupción y se reanudan
+// enviando como primer offset el que retorna GetUploadOffset.
+type FileChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"` // solo en el primer mensaje de una subida
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                         // solo en el primer mensaje de una subida
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Offset        int64                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`                                 // posición de data en el archivo
+	TotalSize     int64                  `protobuf:"varint,5,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`          // descargas: tamaño total del archivo
+	ModifiedTime  int64                  `protobuf:"varint,6,opt,name=modified_time,json=modifiedTime,proto3" json:"modified_time,omitempty"` // descargas: en el primer mensaje
+	CreateDirs    bool                   `protobuf:"varint,7,opt,name=create_dirs,json=createDirs,proto3" json:"create_dirs,omitempty"`       // solo en el primer mensaje de una subida
+	Checksum      string                 `protobuf:"bytes,8,opt,name=checksum,proto3" json:"checksum,omitempty"`                              // SHA256. Subidas: del archivo completo, opcional. Descargas: del rango enviado, en un último mensaje sin datos
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileChunk) Reset() {
+	*x = FileChunk{}
+	mi := &file_proto_agent_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileChunk) ProtoMessage() {}
+
+func (x *FileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileChunk.ProtoReflect.Descriptor instead.
+func (*FileChunk) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *FileChunk) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *FileChunk) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *FileChunk) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *FileChunk) GetTotalSize() int64 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+func (x *FileChunk) GetModifiedTime() int64 {
+	if x != nil {
+		return x.ModifiedTime
+	}
+	return 0
+}
+
+func (x *FileChunk) GetCreateDirs() bool {
+	if x != nil {
+		return x.CreateDirs
+	}
+	return false
+}
+
+func (x *FileChunk) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+type DownloadFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"` // negativo = los últimos -offset bytes
+	Length        int64                  `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"` // 0 = hasta el final
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileRequest) Reset() {
+	*x = DownloadFileRequest{}
+	mi := &file_proto_agent_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileRequest) ProtoMessage() {}
+
+func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
+func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DownloadFileRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *DownloadFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DownloadFileRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DownloadFileRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+type FileTransferResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Checksum      string                 `protobuf:"bytes,5,opt,name=checksum,proto3" json:"checksum,omitempty"` // SHA256 del archivo completo
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileTransferResponse) Reset() {
+	*x = FileTransferResponse{}
+	mi := &file_proto_agent_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileTransferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileTransferResponse) ProtoMessage() {}
+
+func (x *FileTransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileTransferResponse.ProtoReflect.Descriptor instead.
+func (*FileTransferResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *FileTransferResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *FileTransferResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *FileTransferResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileTransferResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *FileTransferResponse) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+type UploadOffsetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"` // bytes ya recibidos de una subida interrumpida (0 = ninguna)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadOffsetResponse) Reset() {
+	*x = UploadOffsetResponse{}
+	mi := &file_proto_agent_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadOffsetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadOffsetResponse) ProtoMessage() {}
+
+func (x *UploadOffsetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadOffsetResponse.ProtoReflect.Descriptor instead.
+func (*UploadOffsetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *UploadOffsetResponse) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// Dependencias
+type DependenciesStatus struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	JavaInstalled   bool                   `protobuf:"varint,1,opt,name=java_installed,json=javaInstalled,proto3" json:"java_installed,omitempty"`
+	JavaVersion     string                 `protobuf:"bytes,2,opt,name=java_version,json=javaVersion,proto3" json:"java_version,omitempty"`
+	JavaPaths       []string               `protobuf:"bytes,3,rep,name=java_paths,json=javaPaths,proto3" json:"java_paths,omitempty"`
+	ScreenInstalled bool                   `protobuf:"varint,4,opt,name=screen_installed,json=screenInstalled,proto3" json:"screen_installed,omitempty"`
+	TmuxInstalled   bool                   `protobuf:"varint,5,opt,name=tmux_installed,json=tmuxInstalled,proto3" json:"tmux_installed,omitempty"`
+	Environment     map[string]string      `protobuf:"bytes,6,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DependenciesStatus) Reset() {
+	*x = DependenciesStatus{}
+	mi := &file_proto_agent_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DependenciesStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DependenciesStatus) ProtoMessage() {}
+
+func (x *DependenciesStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DependenciesStatus.ProtoReflect.Descriptor instead.
+func (*DependenciesStatus) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DependenciesStatus) GetJavaInstalled() bool {
+	if x != nil {
+		return x.JavaInstalled
+	}
+	return false
+}
+
+func (x *DependenciesStatus) GetJavaVersion() string {
+	if x != nil {
+		return x.JavaVersion
+	}
+	return ""
+}
+
+func (x *DependenciesStatus) GetJavaPaths() []string {
+	if x != nil {
+		return x.JavaPaths
+	}
+	return nil
+}
+
+func (x *DependenciesStatus) GetScreenInstalled() bool {
+	if x != nil {
+		return x.ScreenInstalled
+	}
+	return false
+}
+
+func (x *DependenciesStatus) GetTmuxInstalled() bool {
+	if x != nil {
+		return x.TmuxInstalled
+	}
+	return false
+}
+
+func (x *DependenciesStatus) GetEnvironment() map[string]string {
+	if x != nil {
+		return x.Environment
+	}
+	return nil
+}
+
+type JavaInstallRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"` // 8, 11, 17, 21
+	UseSudo       bool                   `protobuf:"varint,2,opt,name=use_sudo,json=useSudo,proto3" json:"use_sudo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JavaInstallRequest) Reset() {
+	*x = JavaInstallRequest{}
+	mi := &file_proto_agent_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JavaInstallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JavaInstallRequest) ProtoMessage() {}
+
+func (x *JavaInstallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JavaInstallRequest.ProtoReflect.Descriptor instead.
+func (*JavaInstallRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *JavaInstallRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *JavaInstallRequest) GetUseSudo() bool {
+	if x != nil {
+		return x.UseSudo
+	}
+	return false
+}
+
+type InstallResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Logs          []string               `protobuf:"bytes,3,rep,name=logs,proto3" json:"logs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallResponse) Reset() {
+	*x = InstallResponse{}
+	mi := &file_proto_agent_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallResponse) ProtoMessage() {}
+
+func (x *InstallResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallResponse.ProtoReflect.Descriptor instead.
+func (*InstallResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *InstallResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *InstallResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *InstallResponse) GetLogs() []string {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+type DownloadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Destination   string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`                           // hash para validación
+	ServerType    string                 `protobuf:"bytes,4,opt,name=server_type,json=serverType,proto3" json:"server_type,omitempty"` // paper, purpur, velocity
+	Version       string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadRequest) Reset() {
+	*x = DownloadRequest{}
+	mi := &file_proto_agent_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadRequest) ProtoMessage() {}
+
+func (x *DownloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadRequest.ProtoReflect.Descriptor instead.
+func (*DownloadRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *DownloadRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *DownloadRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *DownloadRequest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *DownloadRequest) GetServerType() string {
+	if x != nil {
+		return x.ServerType
+	}
+	return ""
+}
+
+func (x *DownloadRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type DownloadProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Downloaded    int64                  `protobuf:"varint,1,opt,name=downloaded,proto3" json:"downloaded,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Percent       float64                `protobuf:"fixed64,3,opt,name=percent,proto3" json:"percent,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Complete      bool                   `protobuf:"varint,5,opt,name=complete,proto3" json:"complete,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadProgress) Reset() {
+	*x = DownloadProgress{}
+	mi := &file_proto_agent_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadProgress) ProtoMessage() {}
+
+func (x *DownloadProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadProgress.ProtoReflect.Descriptor instead.
+func (*DownloadProgress) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *DownloadProgress) GetDownloaded() int64 {
+	if x != nil {
+		return x.Downloaded
+	}
+	return 0
+}
+
+func (x *DownloadProgress) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *DownloadProgress) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *DownloadProgress) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DownloadProgress) GetComplete() bool {
+	if x != nil {
+		return x.Complete
+	}
+	return false
+}
+
+func (x *DownloadProgress) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Emparejamiento
+type PairRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PairingCode   string                 `protobuf:"bytes,1,opt,name=pairing_code,json=pairingCode,proto3" json:"pairing_code,omitempty"` // código mostrado por el agente (primer emparejamiento)
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`                                // token nuevo generado por el backend
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PairRequest) Reset() {
+	*x = PairRequest{}
+	mi := &file_proto_agent_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PairRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PairRequest) ProtoMessage() {}
+
+func (x *PairRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PairRequest.ProtoReflect.Descriptor instead.
+func (*PairRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *PairRequest) GetPairingCode() string {
+	if x != nil {
+		return x.PairingCode
+	}
+	return ""
+}
+
+func (x *PairRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type PairResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	AgentId       string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PairResponse) Reset() {
+	*x = PairResponse{}
+	mi := &file_proto_agent_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PairResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PairResponse) ProtoMessage() {}
+
+func (x *PairResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PairResponse.ProtoReflect.Descriptor instead.
+func (*PairResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *PairResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PairResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PairResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+// Certificados mTLS
+type CertificateRequestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Csr           []byte                 `protobuf:"bytes,1,opt,name=csr,proto3" json:"csr,omitempty"` // CSR en PEM; la clave privada nunca sale del agente
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CertificateRequestResponse) Reset() {
+	*x = CertificateRequestResponse{}
+	mi := &file_proto_agent_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CertificateRequestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CertificateRequestResponse) ProtoMessage() {}
+
+func (x *CertificateRequestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CertificateRequestResponse.ProtoReflect.Descriptor instead.
+func (*CertificateRequestResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CertificateRequestResponse) GetCsr() []byte {
+	if x != nil {
+		return x.Csr
+	}
+	return nil
+}
+
+type InstallCertificateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Certificate   []byte                 `protobuf:"bytes,1,opt,name=certificate,proto3" json:"certificate,omitempty"`                          // certificado del agente en PEM
+	CaCertificate []byte                 `protobuf:"bytes,2,opt,name=ca_certificate,json=caCertificate,proto3" json:"ca_certificate,omitempty"` // CA que firma los certificados cliente del backend
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallCertificateRequest) Reset() {
+	*x = InstallCertificateRequest{}
+	mi := &file_proto_agent_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallCertificateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallCertificateRequest) ProtoMessage() {}
+
+func (x *InstallCertificateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallCertificateRequest.ProtoReflect.Descriptor instead.
+func (*InstallCertificateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *InstallCertificateRequest) GetCertificate() []byte {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *InstallCertificateRequest) GetCaCertificate() []byte {
+	if x != nil {
+		return x.CaCertificate
+	}
+	return nil
+}
+
+type InstallCertificateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Fingerprint   string                 `protobuf:"bytes,3,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"` // SHA256 del certificado instalado
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallCertificateResponse) Reset() {
+	*x = InstallCertificateResponse{}
+	mi := &file_proto_agent_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallCertificateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallCertificateResponse) ProtoMessage() {}
+
+func (x *InstallCertificateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallCertificateResponse.ProtoReflect.Descriptor instead.
+func (*InstallCertificateResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *InstallCertificateResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *InstallCertificateResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *InstallCertificateResponse) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+// Health y ping
+type PongResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PongResponse) Reset() {
+	*x = PongResponse{}
+	mi := &file_proto_agent_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PongResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PongResponse) ProtoMessage() {}
+
+func (x *PongResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PongResponse.ProtoReflect.Descriptor instead.
+func (*PongResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *PongResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *PongResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type HealthStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Healthy       bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Checks        map[string]string      `protobuf:"bytes,3,rep,name=checks,proto3" json:"checks,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthStatus) Reset() {
+	*x = HealthStatus{}
+	mi := &file_proto_agent_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthStatus) ProtoMessage() {}
+
+func (x *HealthStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthStatus.ProtoReflect.Descriptor instead.
+func (*HealthStatus) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *HealthStatus) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *HealthStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HealthStatus) GetChecks() map[string]string {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+func (x *HealthStatus) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// Gestión de plugins
+type InstallPluginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	PluginName    string                 `protobuf:"bytes,2,opt,name=plugin_name,json=pluginName,proto3" json:"plugin_name,omitempty"`
+	DownloadUrl   string                 `protobuf:"bytes,3,opt,name=download_url,json=downloadUrl,proto3" json:"download_url,omitempty"`
+	FileName      string                 `protobuf:"bytes,4,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Version       string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
+	AutoRestart   bool                   `protobuf:"varint,6,opt,name=auto_restart,json=autoRestart,proto3" json:"auto_restart,omitempty"` // reiniciar servidor después de instalar
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallPluginRequest) Reset() {
+	*x = InstallPluginRequest{}
+	mi := &file_proto_agent_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallPluginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallPluginRequest) ProtoMessage() {}
+
+func (x *InstallPluginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallPluginRequest.ProtoReflect.Descriptor instead.
+func (*InstallPluginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *InstallPluginRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *InstallPluginRequest) GetPluginName() string {
+	if x != nil {
+		return x.PluginName
+	}
+	return ""
+}
+
+func (x *InstallPluginRequest) GetDownloadUrl() string {
+	if x != nil {
+		return x.DownloadUrl
+	}
+	return ""
+}
+
+func (x *InstallPluginRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *InstallPluginRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *InstallPluginRequest) GetAutoRestart() bool {
+	if x != nil {
+		return x.AutoRestart
+	}
+	return false
+}
+
+type UninstallPluginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	PluginName    string                 `protobuf:"bytes,2,opt,name=plugin_name,json=pluginName,proto3" json:"plugin_name,omitempty"`
+	DeleteConfig  bool                   `protobuf:"varint,3,opt,name=delete_config,json=deleteConfig,proto3" json:"delete_config,omitempty"` // eliminar archivos de configuración
+	DeleteData    bool                   `protobuf:"varint,4,opt,name=delete_data,json=deleteData,proto3" json:"delete_data,omitempty"`       // eliminar datos del plugin
+	AutoRestart   bool                   `protobuf:"varint,5,opt,name=auto_restart,json=autoRestart,proto3" json:"auto_restart,omitempty"`    // reiniciar servidor después de desinstalar
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UninstallPluginRequest) Reset() {
+	*x = UninstallPluginRequest{}
+	mi := &file_proto_agent_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UninstallPluginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UninstallPluginRequest) ProtoMessage() {}
+
+func (x *UninstallPluginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UninstallPluginRequest.ProtoReflect.Descriptor instead.
+func (*UninstallPluginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *UninstallPluginRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *UninstallPluginRequest) GetPluginName() string {
+	if x != nil {
+		return x.PluginName
+	}
+	return ""
+}
+
+func (x *UninstallPluginRequest) GetDeleteConfig() bool {
+	if x != nil {
+		return x.DeleteConfig
+	}
+	return false
+}
+
+func (x *UninstallPluginRequest) GetDeleteData() bool {
+	if x != nil {
+		return x.DeleteData
+	}
+	return false
+}
+
+func (x *UninstallPluginRequest) GetAutoRestart() bool {
+	if x != nil {
+		return x.AutoRestart
+	}
+	return false
+}
+
+type UpdatePluginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	PluginName    string                 `protobuf:"bytes,2,opt,name=plugin_name,json=pluginName,proto3" json:"plugin_name,omitempty"`
+	DownloadUrl   string                 `protobuf:"bytes,3,opt,name=download_url,json=downloadUrl,proto3" json:"download_url,omitempty"`
+	FileName      string                 `protobuf:"bytes,4,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	NewVersion    string                 `protobuf:"bytes,5,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	AutoRestart   bool                   `protobuf:"varint,6,opt,name=auto_restart,json=autoRestart,proto3" json:"auto_restart,omitempty"` // reiniciar servidor después de actualizar
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePluginRequest) Reset() {
+	*x = UpdatePluginRequest{}
+	mi := &file_proto_agent_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePluginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePluginRequest) ProtoMessage() {}
+
+func (x *UpdatePluginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePluginRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePluginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *UpdatePluginRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *UpdatePluginRequest) GetPluginName() string {
+	if x != nil {
+		return x.PluginName
+	}
+	return ""
+}
+
+func (x *UpdatePluginRequest) GetDownloadUrl() string {
+	if x != nil {
+		return x.DownloadUrl
+	}
+	return ""
+}
+
+func (x *UpdatePluginRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *UpdatePluginRequest) GetNewVersion() string {
+	if x != nil {
+		return x.NewVersion
+	}
+	return ""
+}
+
+func (x *UpdatePluginRequest) GetAutoRestart() bool {
+	if x != nil {
+		return x.AutoRestart
+	}
+	return false
+}
+
+type ListPluginsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ServerId        string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	IncludeDisabled bool                   `protobuf:"varint,2,opt,name=include_disabled,json=includeDisabled,proto3" json:"include_disabled,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListPluginsRequest) Reset() {
+	*x = ListPluginsRequest{}
+	mi := &file_proto_agent_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPluginsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPluginsRequest) ProtoMessage() {}
+
+func (x *ListPluginsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPluginsRequest.ProtoReflect.Descriptor instead.
+func (*ListPluginsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ListPluginsRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *ListPluginsRequest) GetIncludeDisabled() bool {
+	if x != nil {
+		return x.IncludeDisabled
+	}
+	return false
+}
+
+type PluginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Plugin        *PluginInfo            `protobuf:"bytes,3,opt,name=plugin,proto3" json:"plugin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginResponse) Reset() {
+	*x = PluginResponse{}
+	mi := &file_proto_agent_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FileInfo) String() string {
+func (x *PluginResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileInfo) ProtoMessage() {}
+func (*PluginResponse) ProtoMessage() {}
 
-func (x *FileInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[18]
+func (x *PluginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1289,81 +3864,62 @@ func (x *FileInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
-func (*FileInfo) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{18}
-}
-
-func (x *FileInfo) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *FileInfo) GetPath() string {
-	if x != nil {
-		return x.Path
-	}
-	return ""
-}
-
-func (x *FileInfo) GetSize() int64 {
-	if x != nil {
-		return x.Size
-	}
-	return 0
+// Deprecated: Use PluginResponse.ProtoReflect.Descriptor instead.
+func (*PluginResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{51}
 }
 
-func (x *FileInfo) GetIsDir() bool {
+func (x *PluginResponse) GetSuccess() bool {
 	if x != nil {
-		return x.IsDir
+		return x.Success
 	}
 	return false
 }
 
-func (x *FileInfo) GetModifiedTime() int64 {
+func (x *PluginResponse) GetMessage() string {
 	if x != nil {
-		return x.ModifiedTime
+		return x.Message
 	}
-	return 0
+	return ""
 }
 
-func (x *FileInfo) GetPermissions() int32 {
+func (x *PluginResponse) GetPlugin() *PluginInfo {
 	if x != nil {
-		return x.Permissions
+		return x.Plugin
 	}
-	return 0
+	return nil
 }
 
-// Dependencias
-type DependenciesStatus struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	JavaInstalled   bool                   `protobuf:"varint,1,opt,name=java_installed,json=javaInstalled,proto3" json:"java_installed,omitempty"`
-	JavaVersion     string                 `protobuf:"bytes,2,opt,name=java_version,json=javaVersion,proto3" json:"java_version,omitempty"`
-	JavaPaths       []string               `protobuf:"bytes,3,rep,name=java_paths,json=javaPaths,proto3" json:"java_paths,omitempty"`
-	ScreenInstalled bool                   `protobuf:"varint,4,opt,name=screen_installed,json=screenInstalled,proto3" json:"screen_installed,omitempty"`
-	TmuxInstalled   bool                   `protobuf:"varint,5,opt,name=tmux_installed,json=tmuxInstalled,proto3" json:"tmux_installed,omitempty"`
-	Environment     map[string]string      `protobuf:"bytes,6,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+type PluginInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Author        string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Enabled       bool                   `protobuf:"varint,5,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	FileName      string                 `protobuf:"bytes,6,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	FileSize      int64                  `protobuf:"varint,7,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	InstalledAt   int64                  `protobuf:"varint,8,opt,name=installed_at,json=installedAt,proto3" json:"installed_at,omitempty"`
+	Dependencies  []string               `protobuf:"bytes,9,rep,name=dependencies,proto3" json:"dependencies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DependenciesStatus) Reset() {
-	*x = DependenciesStatus{}
-	mi := &file_proto_agent_proto_msgTypes[19]
+func (x *PluginInfo) Reset() {
+	*x = PluginInfo{}
+	mi := &file_proto_agent_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DependenciesStatus) String() string {
+func (x *PluginInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DependenciesStatus) ProtoMessage() {}
+func (*PluginInfo) ProtoMessage() {}
 
-func (x *DependenciesStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[19]
+func (x *PluginInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1374,76 +3930,97 @@ func (x *DependenciesStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DependenciesStatus.ProtoReflect.Descriptor instead.
-func (*DependenciesStatus) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use PluginInfo.ProtoReflect.Descriptor instead.
+func (*PluginInfo) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{52}
 }
 
-func (x *DependenciesStatus) GetJavaInstalled() bool {
+func (x *PluginInfo) GetName() string {
 	if x != nil {
-		return x.JavaInstalled
+		return x.Name
 	}
-	return false
+	return ""
 }
 
-func (x *DependenciesStatus) GetJavaVersion() string {
+func (x *PluginInfo) GetVersion() string {
 	if x != nil {
-		return x.JavaVersion
+		return x.Version
 	}
 	return ""
 }
 
-func (x *DependenciesStatus) GetJavaPaths() []string {
+func (x *PluginInfo) GetDescription() string {
 	if x != nil {
-		return x.JavaPaths
+		return x.Description
 	}
-	return nil
+	return ""
 }
 
-func (x *DependenciesStatus) GetScreenInstalled() bool {
+func (x *PluginInfo) GetAuthor() string {
 	if x != nil {
-		return x.ScreenInstalled
+		return x.Author
 	}
-	return false
+	return ""
 }
 
-func (x *DependenciesStatus) GetTmuxInstalled() bool {
+func (x *PluginInfo) GetEnabled() bool {
 	if x != nil {
-		return x.TmuxInstalled
+		return x.Enabled
 	}
 	return false
 }
 
-func (x *DependenciesStatus) GetEnvironment() map[string]string {
+func (x *PluginInfo) GetFileName() string {
 	if x != nil {
-		return x.Environment
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *PluginInfo) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *PluginInfo) GetInstalledAt() int64 {
+	if x != nil {
+		return x.InstalledAt
+	}
+	return 0
+}
+
+func (x *PluginInfo) GetDependencies() []string {
+	if x != nil {
+		return x.Dependencies
 	}
 	return nil
 }
 
-type JavaInstallRequest struct {
+type PluginList struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"` // 8, 11, 17, 21
-	UseSudo       bool                   `protobuf:"varint,2,opt,name=use_sudo,json=useSudo,proto3" json:"use_sudo,omitempty"`
+	Plugins       []*PluginInfo          `protobuf:"bytes,1,rep,name=plugins,proto3" json:"plugins,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *JavaInstallRequest) Reset() {
-	*x = JavaInstallRequest{}
-	mi := &file_proto_agent_proto_msgTypes[20]
+func (x *PluginList) Reset() {
+	*x = PluginList{}
+	mi := &file_proto_agent_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JavaInstallRequest) String() string {
+func (x *PluginList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JavaInstallRequest) ProtoMessage() {}
+func (*PluginList) ProtoMessage() {}
 
-func (x *JavaInstallRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[20]
+func (x *PluginList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1454,49 +4031,59 @@ func (x *JavaInstallRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JavaInstallRequest.ProtoReflect.Descriptor instead.
-func (*JavaInstallRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use PluginList.ProtoReflect.Descriptor instead.
+func (*PluginList) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{53}
 }
 
-func (x *JavaInstallRequest) GetVersion() string {
+func (x *PluginList) GetPlugins() []*PluginInfo {
 	if x != nil {
-		return x.Version
+		return x.Plugins
 	}
-	return ""
+	return nil
 }
 
-func (x *JavaInstallRequest) GetUseSudo() bool {
+func (x *PluginList) GetTotal() int32 {
 	if x != nil {
-		return x.UseSudo
+		return x.Total
 	}
-	return false
-}
-
-type InstallResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Logs          []string               `protobuf:"bytes,3,rep,name=logs,proto3" json:"logs,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	return 0
 }
 
-func (x *InstallResponse) Reset() {
-	*x = InstallResponse{}
-	mi := &file_proto_agent_proto_msgTypes[21]
+// Gestión de backups
+type CreateBackupRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ServerId       string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	BackupType     string                 `protobuf:"bytes,2,opt,name=backup_type,json=backupType,proto3" json:"backup_type,omitempty"`  // full, world, plugins, config
+	Destination    string                 `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"`                  // ruta donde guardar el backup
+	Compression    string                 `protobuf:"bytes,4,opt,name=compression,proto3" json:"compression,omitempty"`                  // gzip, bzip2, none
+	StopServer     bool                   `protobuf:"varint,5,opt,name=stop_server,json=stopServer,proto3" json:"stop_server,omitempty"` // detener servidor antes de hacer backup
+	IncludeWorld   bool                   `protobuf:"varint,6,opt,name=include_world,json=includeWorld,proto3" json:"include_world,omitempty"`
+	IncludePlugins bool                   `protobuf:"varint,7,opt,name=include_plugins,json=includePlugins,proto3" json:"include_plugins,omitempty"`
+	IncludeConfig  bool                   `protobuf:"varint,8,opt,name=include_config,json=includeConfig,proto3" json:"include_config,omitempty"`
+	IncludeLogs    bool                   `protobuf:"varint,9,opt,name=include_logs,json=includeLogs,proto3" json:"include_logs,omitempty"`
+	ExcludePaths   []string               `protobuf:"bytes,10,rep,name=exclude_paths,json=excludePaths,proto3" json:"exclude_paths,omitempty"` // rutas a excluir
+	Incremental    bool                   `protobuf:"varint,11,opt,name=incremental,proto3" json:"incremental,omitempty"`                      // guardar en el almacén deduplicado en lugar de un tar.gz
+	Online         bool                   `protobuf:"varint,12,opt,name=online,proto3" json:"online,omitempty"`                                // save-off + save-all flush durante la copia en lugar de detener el servidor
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateBackupRequest) Reset() {
+	*x = CreateBackupRequest{}
+	mi := &file_proto_agent_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InstallResponse) String() string {
+func (x *CreateBackupRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InstallResponse) ProtoMessage() {}
+func (*CreateBackupRequest) ProtoMessage() {}
 
-func (x *InstallResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[21]
+func (x *CreateBackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1507,135 +4094,124 @@ func (x *InstallResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InstallResponse.ProtoReflect.Descriptor instead.
-func (*InstallResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use CreateBackupRequest.ProtoReflect.Descriptor instead.
+func (*CreateBackupRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{54}
 }
 
-func (x *InstallResponse) GetSuccess() bool {
+func (x *CreateBackupRequest) GetServerId() string {
 	if x != nil {
-		return x.Success
+		return x.ServerId
 	}
-	return false
+	return ""
 }
 
-func (x *InstallResponse) GetMessage() string {
+func (x *CreateBackupRequest) GetBackupType() string {
 	if x != nil {
-		return x.Message
+		return x.BackupType
 	}
 	return ""
 }
 
-func (x *InstallResponse) GetLogs() []string {
+func (x *CreateBackupRequest) GetDestination() string {
 	if x != nil {
-		return x.Logs
+		return x.Destination
 	}
-	return nil
-}
-
-type DownloadRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
-	Destination   string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
-	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`                           // hash para validación
-	ServerType    string                 `protobuf:"bytes,4,opt,name=server_type,json=serverType,proto3" json:"server_type,omitempty"` // paper, purpur, velocity
-	Version       string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	return ""
 }
 
-func (x *DownloadRequest) Reset() {
-	*x = DownloadRequest{}
-	mi := &file_proto_agent_proto_msgTypes[22]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *CreateBackupRequest) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
 }
 
-func (x *DownloadRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *CreateBackupRequest) GetStopServer() bool {
+	if x != nil {
+		return x.StopServer
+	}
+	return false
 }
 
-func (*DownloadRequest) ProtoMessage() {}
-
-func (x *DownloadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[22]
+func (x *CreateBackupRequest) GetIncludeWorld() bool {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.IncludeWorld
 	}
-	return mi.MessageOf(x)
+	return false
 }
 
-// Deprecated: Use DownloadRequest.ProtoReflect.Descriptor instead.
-func (*DownloadRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{22}
+func (x *CreateBackupRequest) GetIncludePlugins() bool {
+	if x != nil {
+		return x.IncludePlugins
+	}
+	return false
 }
 
-func (x *DownloadRequest) GetUrl() string {
+func (x *CreateBackupRequest) GetIncludeConfig() bool {
 	if x != nil {
-		return x.Url
+		return x.IncludeConfig
 	}
-	return ""
+	return false
 }
 
-func (x *DownloadRequest) GetDestination() string {
+func (x *CreateBackupRequest) GetIncludeLogs() bool {
 	if x != nil {
-		return x.Destination
+		return x.IncludeLogs
 	}
-	return ""
+	return false
 }
 
-func (x *DownloadRequest) GetSha256() string {
+func (x *CreateBackupRequest) GetExcludePaths() []string {
 	if x != nil {
-		return x.Sha256
+		return x.ExcludePaths
 	}
-	return ""
+	return nil
 }
 
-func (x *DownloadRequest) GetServerType() string {
+func (x *CreateBackupRequest) GetIncremental() bool {
 	if x != nil {
-		return x.ServerType
+		return x.Incremental
 	}
-	return ""
+	return false
 }
 
-func (x *DownloadRequest) GetVersion() string {
+func (x *CreateBackupRequest) GetOnline() bool {
 	if x != nil {
-		return x.Version
+		return x.Online
 	}
-	return ""
+	return false
 }
 
-type DownloadProgress struct {
+type CreateBackupResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Downloaded    int64                  `protobuf:"varint,1,opt,name=downloaded,proto3" json:"downloaded,omitempty"`
-	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
-	Percent       float64                `protobuf:"fixed64,3,opt,name=percent,proto3" json:"percent,omitempty"`
-	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
-	Complete      bool                   `protobuf:"varint,5,opt,name=complete,proto3" json:"complete,omitempty"`
-	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	BackupPath    string                 `protobuf:"bytes,3,opt,name=backup_path,json=backupPath,proto3" json:"backup_path,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Checksum      string                 `protobuf:"bytes,5,opt,name=checksum,proto3" json:"checksum,omitempty"` // SHA256
+	DurationMs    int64                  `protobuf:"varint,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	SnapshotId    string                 `protobuf:"bytes,7,opt,name=snapshot_id,json=snapshotId,proto3" json:"snapshot_id,omitempty"` // ID del snapshot si el backup es incremental
+	NewBytes      int64                  `protobuf:"varint,8,opt,name=new_bytes,json=newBytes,proto3" json:"new_bytes,omitempty"`      // bytes nuevos escritos en el almacén
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DownloadProgress) Reset() {
-	*x = DownloadProgress{}
-	mi := &file_proto_agent_proto_msgTypes[23]
+func (x *CreateBackupResponse) Reset() {
+	*x = CreateBackupResponse{}
+	mi := &file_proto_agent_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DownloadProgress) String() string {
+func (x *CreateBackupResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadProgress) ProtoMessage() {}
+func (*CreateBackupResponse) ProtoMessage() {}
 
-func (x *DownloadProgress) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[23]
+func (x *CreateBackupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1646,77 +4222,96 @@ func (x *DownloadProgress) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadProgress.ProtoReflect.Descriptor instead.
-func (*DownloadProgress) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use CreateBackupResponse.ProtoReflect.Descriptor instead.
+func (*CreateBackupResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{55}
 }
 
-func (x *DownloadProgress) GetDownloaded() int64 {
+func (x *CreateBackupResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Downloaded
+		return x.Success
 	}
-	return 0
+	return false
 }
 
-func (x *DownloadProgress) GetTotal() int64 {
+func (x *CreateBackupResponse) GetMessage() string {
 	if x != nil {
-		return x.Total
+		return x.Message
 	}
-	return 0
+	return ""
 }
 
-func (x *DownloadProgress) GetPercent() float64 {
+func (x *CreateBackupResponse) GetBackupPath() string {
 	if x != nil {
-		return x.Percent
+		return x.BackupPath
+	}
+	return ""
+}
+
+func (x *CreateBackupResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
 	}
 	return 0
 }
 
-func (x *DownloadProgress) GetStatus() string {
+func (x *CreateBackupResponse) GetChecksum() string {
 	if x != nil {
-		return x.Status
+		return x.Checksum
 	}
 	return ""
 }
 
-func (x *DownloadProgress) GetComplete() bool {
+func (x *CreateBackupResponse) GetDurationMs() int64 {
 	if x != nil {
-		return x.Complete
+		return x.DurationMs
 	}
-	return false
+	return 0
 }
 
-func (x *DownloadProgress) GetError() string {
+func (x *CreateBackupResponse) GetSnapshotId() string {
 	if x != nil {
-		return x.Error
+		return x.SnapshotId
 	}
 	return ""
 }
 
-// Health y ping
-type PongResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *CreateBackupResponse) GetNewBytes() int64 {
+	if x != nil {
+		return x.NewBytes
+	}
+	return 0
 }
 
-func (x *PongResponse) Reset() {
-	*x = PongResponse{}
-	mi := &file_proto_agent_proto_msgTypes[24]
+type RestoreBackupRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ServerId            string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	BackupPath          string                 `protobuf:"bytes,2,opt,name=backup_path,json=backupPath,proto3" json:"backup_path,omitempty"`
+	StopServer          bool                   `protobuf:"varint,3,opt,name=stop_server,json=stopServer,proto3" json:"stop_server,omitempty"` // detener servidor antes de restaurar
+	RestoreWorld        bool                   `protobuf:"varint,4,opt,name=restore_world,json=restoreWorld,proto3" json:"restore_world,omitempty"`
+	RestorePlugins      bool                   `protobuf:"varint,5,opt,name=restore_plugins,json=restorePlugins,proto3" json:"restore_plugins,omitempty"`
+	RestoreConfig       bool                   `protobuf:"varint,6,opt,name=restore_config,json=restoreConfig,proto3" json:"restore_config,omitempty"`
+	BackupBeforeRestore bool                   `protobuf:"varint,7,opt,name=backup_before_restore,json=backupBeforeRestore,proto3" json:"backup_before_restore,omitempty"` // crear backup de seguridad antes de restaurar
+	SnapshotId          string                 `protobuf:"bytes,8,opt,name=snapshot_id,json=snapshotId,proto3" json:"snapshot_id,omitempty"`                               // restaurar un snapshot incremental en lugar de backup_path
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *RestoreBackupRequest) Reset() {
+	*x = RestoreBackupRequest{}
+	mi := &file_proto_agent_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PongResponse) String() string {
+func (x *RestoreBackupRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PongResponse) ProtoMessage() {}
+func (*RestoreBackupRequest) ProtoMessage() {}
 
-func (x *PongResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[24]
+func (x *RestoreBackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1727,121 +4322,92 @@ func (x *PongResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PongResponse.ProtoReflect.Descriptor instead.
-func (*PongResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use RestoreBackupRequest.ProtoReflect.Descriptor instead.
+func (*RestoreBackupRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{56}
 }
 
-func (x *PongResponse) GetTimestamp() int64 {
+func (x *RestoreBackupRequest) GetServerId() string {
 	if x != nil {
-		return x.Timestamp
+		return x.ServerId
 	}
-	return 0
+	return ""
 }
 
-func (x *PongResponse) GetMessage() string {
+func (x *RestoreBackupRequest) GetBackupPath() string {
 	if x != nil {
-		return x.Message
+		return x.BackupPath
 	}
 	return ""
 }
 
-type HealthStatus struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Healthy       bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	Checks        map[string]string      `protobuf:"bytes,3,rep,name=checks,proto3" json:"checks,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *HealthStatus) Reset() {
-	*x = HealthStatus{}
-	mi := &file_proto_agent_proto_msgTypes[25]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *HealthStatus) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*HealthStatus) ProtoMessage() {}
-
-func (x *HealthStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[25]
+func (x *RestoreBackupRequest) GetStopServer() bool {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.StopServer
 	}
-	return mi.MessageOf(x)
+	return false
 }
 
-// Deprecated: Use HealthStatus.ProtoReflect.Descriptor instead.
-func (*HealthStatus) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{25}
+func (x *RestoreBackupRequest) GetRestoreWorld() bool {
+	if x != nil {
+		return x.RestoreWorld
+	}
+	return false
 }
 
-func (x *HealthStatus) GetHealthy() bool {
+func (x *RestoreBackupRequest) GetRestorePlugins() bool {
 	if x != nil {
-		return x.Healthy
+		return x.RestorePlugins
 	}
 	return false
 }
 
-func (x *HealthStatus) GetStatus() string {
+func (x *RestoreBackupRequest) GetRestoreConfig() bool {
 	if x != nil {
-		return x.Status
+		return x.RestoreConfig
 	}
-	return ""
+	return false
 }
 
-func (x *HealthStatus) GetChecks() map[string]string {
+func (x *RestoreBackupRequest) GetBackupBeforeRestore() bool {
 	if x != nil {
-		return x.Checks
+		return x.BackupBeforeRestore
 	}
-	return nil
+	return false
 }
 
-func (x *HealthStatus) GetTimestamp() int64 {
+func (x *RestoreBackupRequest) GetSnapshotId() string {
 	if x != nil {
-		return x.Timestamp
+		return x.SnapshotId
 	}
-	return 0
+	return ""
 }
 
-// Gestión de plugins
-type InstallPluginRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
-	PluginName    string                 `protobuf:"bytes,2,opt,name=plugin_name,json=pluginName,proto3" json:"plugin_name,omitempty"`
-	DownloadUrl   string                 `protobuf:"bytes,3,opt,name=download_url,json=downloadUrl,proto3" json:"download_url,omitempty"`
-	FileName      string                 `protobuf:"bytes,4,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
-	Version       string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
-	AutoRestart   bool                   `protobuf:"varint,6,opt,name=auto_restart,json=autoRestart,proto3" json:"auto_restart,omitempty"` // reiniciar servidor después de instalar
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type RestoreBackupResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Success          bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message          string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	DurationMs       int64                  `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	SafetyBackupPath string                 `protobuf:"bytes,4,opt,name=safety_backup_path,json=safetyBackupPath,proto3" json:"safety_backup_path,omitempty"` // path del backup de seguridad si se creó
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *InstallPluginRequest) Reset() {
-	*x = InstallPluginRequest{}
-	mi := &file_proto_agent_proto_msgTypes[26]
+func (x *RestoreBackupResponse) Reset() {
+	*x = RestoreBackupResponse{}
+	mi := &file_proto_agent_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *InstallPluginRequest) String() string {
+func (x *RestoreBackupResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*InstallPluginRequest) ProtoMessage() {}
+func (*RestoreBackupResponse) ProtoMessage() {}
 
-func (x *InstallPluginRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[26]
+func (x *RestoreBackupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1852,79 +4418,109 @@ func (x *InstallPluginRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use InstallPluginRequest.ProtoReflect.Descriptor instead.
-func (*InstallPluginRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use RestoreBackupResponse.ProtoReflect.Descriptor instead.
+func (*RestoreBackupResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{57}
 }
 
-func (x *InstallPluginRequest) GetServerId() string {
+func (x *RestoreBackupResponse) GetSuccess() bool {
 	if x != nil {
-		return x.ServerId
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *InstallPluginRequest) GetPluginName() string {
+func (x *RestoreBackupResponse) GetMessage() string {
 	if x != nil {
-		return x.PluginName
+		return x.Message
 	}
 	return ""
 }
 
-func (x *InstallPluginRequest) GetDownloadUrl() string {
+func (x *RestoreBackupResponse) GetDurationMs() int64 {
 	if x != nil {
-		return x.DownloadUrl
+		return x.DurationMs
 	}
-	return ""
+	return 0
 }
 
-func (x *InstallPluginRequest) GetFileName() string {
+func (x *RestoreBackupResponse) GetSafetyBackupPath() string {
 	if x != nil {
-		return x.FileName
+		return x.SafetyBackupPath
 	}
 	return ""
 }
 
-func (x *InstallPluginRequest) GetVersion() string {
+// Transferencia de archivos de backup (relativos al directorio de backups del agente)
+type BackupFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupFileRequest) Reset() {
+	*x = BackupFileRequest{}
+	mi := &file_proto_agent_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupFileRequest) ProtoMessage() {}
+
+func (x *BackupFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[58]
 	if x != nil {
-		return x.Version
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *InstallPluginRequest) GetAutoRestart() bool {
+// Deprecated: Use BackupFileRequest.ProtoReflect.Descriptor instead.
+func (*BackupFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *BackupFileRequest) GetPath() string {
 	if x != nil {
-		return x.AutoRestart
+		return x.Path
 	}
-	return false
+	return ""
 }
 
-type UninstallPluginRequest struct {
+type BackupChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
-	PluginName    string                 `protobuf:"bytes,2,opt,name=plugin_name,json=pluginName,proto3" json:"plugin_name,omitempty"`
-	DeleteConfig  bool                   `protobuf:"varint,3,opt,name=delete_config,json=deleteConfig,proto3" json:"delete_config,omitempty"` // eliminar archivos de configuración
-	DeleteData    bool                   `protobuf:"varint,4,opt,name=delete_data,json=deleteData,proto3" json:"delete_data,omitempty"`       // eliminar datos del plugin
-	AutoRestart   bool                   `protobuf:"varint,5,opt,name=auto_restart,json=autoRestart,proto3" json:"auto_restart,omitempty"`    // reiniciar servidor después de desinstalar
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // solo en el primer mensaje de una subida
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	TotalSize     int64                  `protobuf:"varint,4,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"` // tamaño total del archivo (si se conoce)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UninstallPluginRequest) Reset() {
-	*x = UninstallPluginRequest{}
-	mi := &file_proto_agent_proto_msgTypes[27]
+func (x *BackupChunk) Reset() {
+	*x = BackupChunk{}
+	mi := &file_proto_agent_proto_msgTypes[59]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UninstallPluginRequest) String() string {
+func (x *BackupChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UninstallPluginRequest) ProtoMessage() {}
+func (*BackupChunk) ProtoMessage() {}
 
-func (x *UninstallPluginRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[27]
+func (x *BackupChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[59]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1935,73 +4531,65 @@ func (x *UninstallPluginRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UninstallPluginRequest.ProtoReflect.Descriptor instead.
-func (*UninstallPluginRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{27}
-}
-
-func (x *UninstallPluginRequest) GetServerId() string {
-	if x != nil {
-		return x.ServerId
-	}
-	return ""
+// Deprecated: Use BackupChunk.ProtoReflect.Descriptor instead.
+func (*BackupChunk) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{59}
 }
 
-func (x *UninstallPluginRequest) GetPluginName() string {
+func (x *BackupChunk) GetPath() string {
 	if x != nil {
-		return x.PluginName
+		return x.Path
 	}
 	return ""
 }
 
-func (x *UninstallPluginRequest) GetDeleteConfig() bool {
+func (x *BackupChunk) GetData() []byte {
 	if x != nil {
-		return x.DeleteConfig
+		return x.Data
 	}
-	return false
+	return nil
 }
 
-func (x *UninstallPluginRequest) GetDeleteData() bool {
+func (x *BackupChunk) GetOffset() int64 {
 	if x != nil {
-		return x.DeleteData
+		return x.Offset
 	}
-	return false
+	return 0
 }
 
-func (x *UninstallPluginRequest) GetAutoRestart() bool {
+func (x *BackupChunk) GetTotalSize() int64 {
 	if x != nil {
-		return x.AutoRestart
+		return x.TotalSize
 	}
-	return false
+	return 0
 }
 
-type UpdatePluginRequest struct {
+type BackupFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
-	PluginName    string                 `protobuf:"bytes,2,opt,name=plugin_name,json=pluginName,proto3" json:"plugin_name,omitempty"`
-	DownloadUrl   string                 `protobuf:"bytes,3,opt,name=download_url,json=downloadUrl,proto3" json:"download_url,omitempty"`
-	FileName      string                 `protobuf:"bytes,4,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
-	NewVersion    string                 `protobuf:"bytes,5,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
-	AutoRestart   bool                   `protobuf:"varint,6,opt,name=auto_restart,json=autoRestart,proto3" json:"auto_restart,omitempty"` // reiniciar servidor después de actualizar
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Checksum      string                 `protobuf:"bytes,5,opt,name=checksum,proto3" json:"checksum,omitempty"` // SHA256
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
-
-func (x *UpdatePluginRequest) Reset() {
-	*x = UpdatePluginRequest{}
-	mi := &file_proto_agent_proto_msgTypes[28]
+
+func (x *BackupFileResponse) Reset() {
+	*x = BackupFileResponse{}
+	mi := &file_proto_agent_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdatePluginRequest) String() string {
+func (x *BackupFileResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdatePluginRequest) ProtoMessage() {}
+func (*BackupFileResponse) ProtoMessage() {}
 
-func (x *UpdatePluginRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[28]
+func (x *BackupFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2012,76 +4600,69 @@ func (x *UpdatePluginRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdatePluginRequest.ProtoReflect.Descriptor instead.
-func (*UpdatePluginRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{28}
+// Deprecated: Use BackupFileResponse.ProtoReflect.Descriptor instead.
+func (*BackupFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{60}
 }
 
-func (x *UpdatePluginRequest) GetServerId() string {
+func (x *BackupFileResponse) GetSuccess() bool {
 	if x != nil {
-		return x.ServerId
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *UpdatePluginRequest) GetPluginName() string {
+func (x *BackupFileResponse) GetMessage() string {
 	if x != nil {
-		return x.PluginName
+		return x.Message
 	}
 	return ""
 }
 
-func (x *UpdatePluginRequest) GetDownloadUrl() string {
+func (x *BackupFileResponse) GetPath() string {
 	if x != nil {
-		return x.DownloadUrl
+		return x.Path
 	}
 	return ""
 }
 
-func (x *UpdatePluginRequest) GetFileName() string {
+func (x *BackupFileResponse) GetSizeBytes() int64 {
 	if x != nil {
-		return x.FileName
+		return x.SizeBytes
 	}
-	return ""
+	return 0
 }
 
-func (x *UpdatePluginRequest) GetNewVersion() string {
+func (x *BackupFileResponse) GetChecksum() string {
 	if x != nil {
-		return x.NewVersion
+		return x.Checksum
 	}
 	return ""
 }
 
-func (x *UpdatePluginRequest) GetAutoRestart() bool {
-	if x != nil {
-		return x.AutoRestart
-	}
-	return false
-}
-
-type ListPluginsRequest struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	ServerId        string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
-	IncludeDisabled bool                   `protobuf:"varint,2,opt,name=include_disabled,json=includeDisabled,proto3" json:"include_disabled,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+// Snapshots incrementales del almacén deduplicado
+type ListSnapshotsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListPluginsRequest) Reset() {
-	*x = ListPluginsRequest{}
-	mi := &file_proto_agent_proto_msgTypes[29]
+func (x *ListSnapshotsRequest) Reset() {
+	*x = ListSnapshotsRequest{}
+	mi := &file_proto_agent_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListPluginsRequest) String() string {
+func (x *ListSnapshotsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListPluginsRequest) ProtoMessage() {}
+func (*ListSnapshotsRequest) ProtoMessage() {}
 
-func (x *ListPluginsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[29]
+func (x *ListSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2092,49 +4673,45 @@ func (x *ListPluginsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListPluginsRequest.ProtoReflect.Descriptor instead.
-func (*ListPluginsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{29}
+// Deprecated: Use ListSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{61}
 }
 
-func (x *ListPluginsRequest) GetServerId() string {
+func (x *ListSnapshotsRequest) GetServerId() string {
 	if x != nil {
 		return x.ServerId
 	}
 	return ""
 }
 
-func (x *ListPluginsRequest) GetIncludeDisabled() bool {
-	if x != nil {
-		return x.IncludeDisabled
-	}
-	return false
-}
-
-type PluginResponse struct {
+type SnapshotInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Plugin        *PluginInfo            `protobuf:"bytes,3,opt,name=plugin,proto3" json:"plugin,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ParentId      string                 `protobuf:"bytes,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	FileCount     int32                  `protobuf:"varint,4,opt,name=file_count,json=fileCount,proto3" json:"file_count,omitempty"`
+	TotalSize     int64                  `protobuf:"varint,5,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	NewBytes      int64                  `protobuf:"varint,6,opt,name=new_bytes,json=newBytes,proto3" json:"new_bytes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PluginResponse) Reset() {
-	*x = PluginResponse{}
-	mi := &file_proto_agent_proto_msgTypes[30]
+func (x *SnapshotInfo) Reset() {
+	*x = SnapshotInfo{}
+	mi := &file_proto_agent_proto_msgTypes[62]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PluginResponse) String() string {
+func (x *SnapshotInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PluginResponse) ProtoMessage() {}
+func (*SnapshotInfo) ProtoMessage() {}
 
-func (x *PluginResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[30]
+func (x *SnapshotInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[62]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2145,62 +4722,75 @@ func (x *PluginResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PluginResponse.ProtoReflect.Descriptor instead.
-func (*PluginResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{30}
+// Deprecated: Use SnapshotInfo.ProtoReflect.Descriptor instead.
+func (*SnapshotInfo) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{62}
 }
 
-func (x *PluginResponse) GetSuccess() bool {
+func (x *SnapshotInfo) GetId() string {
 	if x != nil {
-		return x.Success
+		return x.Id
 	}
-	return false
+	return ""
 }
 
-func (x *PluginResponse) GetMessage() string {
+func (x *SnapshotInfo) GetParentId() string {
 	if x != nil {
-		return x.Message
+		return x.ParentId
 	}
 	return ""
 }
 
-func (x *PluginResponse) GetPlugin() *PluginInfo {
+func (x *SnapshotInfo) GetCreatedAt() int64 {
 	if x != nil {
-		return x.Plugin
+		return x.CreatedAt
 	}
-	return nil
+	return 0
 }
 
-type PluginInfo struct {
+func (x *SnapshotInfo) GetFileCount() int32 {
+	if x != nil {
+		return x.FileCount
+	}
+	return 0
+}
+
+func (x *SnapshotInfo) GetTotalSize() int64 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+func (x *SnapshotInfo) GetNewBytes() int64 {
+	if x != nil {
+		return x.NewBytes
+	}
+	return 0
+}
+
+type ListSnapshotsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Author        string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
-	Enabled       bool                   `protobuf:"varint,5,opt,name=enabled,proto3" json:"enabled,omitempty"`
-	FileName      string                 `protobuf:"bytes,6,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
-	FileSize      int64                  `protobuf:"varint,7,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
-	InstalledAt   int64                  `protobuf:"varint,8,opt,name=installed_at,json=installedAt,proto3" json:"installed_at,omitempty"`
-	Dependencies  []string               `protobuf:"bytes,9,rep,name=dependencies,proto3" json:"dependencies,omitempty"`
+	Snapshots     []*SnapshotInfo        `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PluginInfo) Reset() {
-	*x = PluginInfo{}
-	mi := &file_proto_agent_proto_msgTypes[31]
+func (x *ListSnapshotsResponse) Reset() {
+	*x = ListSnapshotsResponse{}
+	mi := &file_proto_agent_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PluginInfo) String() string {
+func (x *ListSnapshotsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PluginInfo) ProtoMessage() {}
+func (*ListSnapshotsResponse) ProtoMessage() {}
 
-func (x *PluginInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[31]
+func (x *ListSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2211,97 +4801,104 @@ func (x *PluginInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PluginInfo.ProtoReflect.Descriptor instead.
-func (*PluginInfo) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{31}
+// Deprecated: Use ListSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{63}
 }
 
-func (x *PluginInfo) GetName() string {
+func (x *ListSnapshotsResponse) GetSnapshots() []*SnapshotInfo {
 	if x != nil {
-		return x.Name
+		return x.Snapshots
 	}
-	return ""
+	return nil
 }
 
-func (x *PluginInfo) GetVersion() string {
-	if x != nil {
-		return x.Version
-	}
-	return ""
+type PruneSnapshotsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	SnapshotIds   []string               `protobuf:"bytes,2,rep,name=snapshot_ids,json=snapshotIds,proto3" json:"snapshot_ids,omitempty"` // snapshots a eliminar
+	KeepLast      int32                  `protobuf:"varint,3,opt,name=keep_last,json=keepLast,proto3" json:"keep_last,omitempty"`         // conservar solo los N más recientes (0 = sin límite)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PluginInfo) GetDescription() string {
-	if x != nil {
-		return x.Description
-	}
-	return ""
+func (x *PruneSnapshotsRequest) Reset() {
+	*x = PruneSnapshotsRequest{}
+	mi := &file_proto_agent_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *PluginInfo) GetAuthor() string {
-	if x != nil {
-		return x.Author
-	}
-	return ""
+func (x *PruneSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *PluginInfo) GetEnabled() bool {
+func (*PruneSnapshotsRequest) ProtoMessage() {}
+
+func (x *PruneSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[64]
 	if x != nil {
-		return x.Enabled
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return false
+	return mi.MessageOf(x)
 }
 
-func (x *PluginInfo) GetFileName() string {
-	if x != nil {
-		return x.FileName
-	}
-	return ""
+// Deprecated: Use PruneSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*PruneSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{64}
 }
 
-func (x *PluginInfo) GetFileSize() int64 {
+func (x *PruneSnapshotsRequest) GetServerId() string {
 	if x != nil {
-		return x.FileSize
+		return x.ServerId
 	}
-	return 0
+	return ""
 }
 
-func (x *PluginInfo) GetInstalledAt() int64 {
+func (x *PruneSnapshotsRequest) GetSnapshotIds() []string {
 	if x != nil {
-		return x.InstalledAt
+		return x.SnapshotIds
 	}
-	return 0
+	return nil
 }
 
-func (x *PluginInfo) GetDependencies() []string {
+func (x *PruneSnapshotsRequest) GetKeepLast() int32 {
 	if x != nil {
-		return x.Dependencies
+		return x.KeepLast
 	}
-	return nil
+	return 0
 }
 
-type PluginList struct {
+type PruneSnapshotsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Plugins       []*PluginInfo          `protobuf:"bytes,1,rep,name=plugins,proto3" json:"plugins,omitempty"`
-	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	DeletedIds    []string               `protobuf:"bytes,3,rep,name=deleted_ids,json=deletedIds,proto3" json:"deleted_ids,omitempty"`
+	ChunksRemoved int32                  `protobuf:"varint,4,opt,name=chunks_removed,json=chunksRemoved,proto3" json:"chunks_removed,omitempty"`
+	BytesFreed    int64                  `protobuf:"varint,5,opt,name=bytes_freed,json=bytesFreed,proto3" json:"bytes_freed,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PluginList) Reset() {
-	*x = PluginList{}
-	mi := &file_proto_agent_proto_msgTypes[32]
+func (x *PruneSnapshotsResponse) Reset() {
+	*x = PruneSnapshotsResponse{}
+	mi := &file_proto_agent_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PluginList) String() string {
+func (x *PruneSnapshotsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PluginList) ProtoMessage() {}
+func (*PruneSnapshotsResponse) ProtoMessage() {}
 
-func (x *PluginList) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[32]
+func (x *PruneSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2312,21 +4909,42 @@ func (x *PluginList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PluginList.ProtoReflect.Descriptor instead.
-func (*PluginList) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{32}
+// Deprecated: Use PruneSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*PruneSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{65}
 }
 
-func (x *PluginList) GetPlugins() []*PluginInfo {
+func (x *PruneSnapshotsResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Plugins
+		return x.Success
+	}
+	return false
+}
+
+func (x *PruneSnapshotsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PruneSnapshotsResponse) GetDeletedIds() []string {
+	if x != nil {
+		return x.DeletedIds
 	}
 	return nil
 }
 
-func (x *PluginList) GetTotal() int32 {
+func (x *PruneSnapshotsResponse) GetChunksRemoved() int32 {
 	if x != nil {
-		return x.Total
+		return x.ChunksRemoved
+	}
+	return 0
+}
+
+func (x *PruneSnapshotsResponse) GetBytesFreed() int64 {
+	if x != nil {
+		return x.BytesFreed
 	}
 	return 0
 }
@@ -2362,7 +4980,7 @@ const file_proto_agent_proto_rawDesc = "" +
 	"\fnetwork_recv\x18\n" +
 	" \x01(\x04R\vnetworkRecv\x12\x1d\n" +
 	"\n" +
-	"open_ports\x18\v \x03(\x05R\topenPorts\"\xa6\x02\n" +
+	"open_ports\x18\v \x03(\x05R\topenPorts\"\xa1\x03\n" +
 	"\n" +
 	"ServerInfo\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
@@ -2377,7 +4995,34 @@ const file_proto_agent_proto_rawDesc = "" +
 	"start_time\x18\t \x01(\x03R\tstartTime\x12\x19\n" +
 	"\bwork_dir\x18\n" +
 	" \x01(\tR\aworkDir\x12+\n" +
-	"\x06config\x18\v \x01(\v2\x13.agent.ServerConfigR\x06config\"\xa0\x02\n" +
+	"\x06config\x18\v \x01(\v2\x13.agent.ServerConfigR\x06config\x12#\n" +
+	"\rrestart_count\x18\f \x01(\x05R\frestartCount\x12$\n" +
+	"\x0elast_exit_code\x18\r \x01(\x05R\flastExitCode\x12.\n" +
+	"\ametrics\x18\x0e \x01(\v2\x14.agent.ServerMetricsR\ametrics\"\x8a\x04\n" +
+	"\rServerMetrics\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x10\n" +
+	"\x03pid\x18\x03 \x01(\x05R\x03pid\x12\x1f\n" +
+	"\vcpu_percent\x18\x04 \x01(\x01R\n" +
+	"cpuPercent\x12\x1d\n" +
+	"\n" +
+	"memory_rss\x18\x05 \x01(\x04R\tmemoryRss\x12\x18\n" +
+	"\athreads\x18\x06 \x01(\x05R\athreads\x12\x1d\n" +
+	"\n" +
+	"open_files\x18\a \x01(\x05R\topenFiles\x12#\n" +
+	"\rprocess_count\x18\b \x01(\x05R\fprocessCount\x12\x1b\n" +
+	"\tdisk_used\x18\t \x01(\x04R\bdiskUsed\x12'\n" +
+	"\x0fstats_timestamp\x18\n" +
+	" \x01(\x03R\x0estatsTimestamp\x12!\n" +
+	"\fgame_version\x18\v \x01(\tR\vgameVersion\x12%\n" +
+	"\x0eplayers_online\x18\f \x01(\x05R\rplayersOnline\x12\x1f\n" +
+	"\vmax_players\x18\r \x01(\x05R\n" +
+	"maxPlayers\x12\x18\n" +
+	"\aplayers\x18\x0e \x03(\tR\aplayers\x12\x1d\n" +
+	"\n" +
+	"latency_ms\x18\x0f \x01(\x03R\tlatencyMs\x12\x10\n" +
+	"\x03tps\x18\x10 \x01(\x01R\x03tps\x12\x12\n" +
+	"\x04mspt\x18\x11 \x01(\x01R\x04mspt\"\xa0\x02\n" +
 	"\fServerConfig\x12\x17\n" +
 	"\amin_ram\x18\x01 \x01(\tR\x06minRam\x12\x17\n" +
 	"\amax_ram\x18\x02 \x01(\tR\x06maxRam\x12\x1b\n" +
@@ -2403,14 +5048,61 @@ const file_proto_agent_proto_rawDesc = "" +
 	"\x0eServerResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12)\n" +
-	"\x06server\x18\x03 \x01(\v2\x11.agent.ServerInfoR\x06server\"G\n" +
+	"\x06server\x18\x03 \x01(\v2\x11.agent.ServerInfoR\x06server\"\x85\x02\n" +
+	"\vServerEvent\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x1b\n" +
+	"\tserver_id\x18\x02 \x01(\tR\bserverId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1b\n" +
+	"\texit_code\x18\x05 \x01(\x05R\bexitCode\x12\x18\n" +
+	"\aattempt\x18\x06 \x01(\x05R\aattempt\x12!\n" +
+	"\fmax_attempts\x18\a \x01(\x05R\vmaxAttempts\x12\x1d\n" +
+	"\n" +
+	"backoff_ms\x18\b \x01(\x03R\tbackoffMs\x12\x16\n" +
+	"\x06reason\x18\t \x01(\tR\x06reason\"g\n" +
+	"\x12EventStreamRequest\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x1f\n" +
+	"\vevent_types\x18\x02 \x03(\tR\n" +
+	"eventTypes\x12\x12\n" +
+	"\x04ping\x18\x03 \x01(\bR\x04ping\"\xb2\x02\n" +
+	"\n" +
+	"AgentEvent\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1a\n" +
+	"\bsequence\x18\x03 \x01(\x04R\bsequence\x120\n" +
+	"\ametrics\x18\n" +
+	" \x01(\v2\x14.agent.SystemMetricsH\x00R\ametrics\x127\n" +
+	"\fserver_event\x18\v \x01(\v2\x12.agent.ServerEventH\x00R\vserverEvent\x12.\n" +
+	"\tlog_alert\x18\f \x01(\v2\x0f.agent.LogAlertH\x00R\blogAlert\x12)\n" +
+	"\x04pong\x18\r \x01(\v2\x13.agent.PongResponseH\x00R\x04pongB\t\n" +
+	"\apayload\"\xe8\x01\n" +
+	"\bLogAlert\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x1b\n" +
+	"\tserver_id\x18\x02 \x01(\tR\bserverId\x12\x14\n" +
+	"\x05level\x18\x03 \x01(\tR\x05level\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"error_type\x18\x05 \x01(\tR\terrorType\x12\x1a\n" +
+	"\bseverity\x18\x06 \x01(\x05R\bseverity\x12\x1e\n" +
+	"\n" +
+	"suggestion\x18\a \x01(\tR\n" +
+	"suggestion\x12\x16\n" +
+	"\x06plugin\x18\b \x01(\tR\x06plugin\"G\n" +
 	"\x0eCommandRequest\x12\x1b\n" +
 	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x18\n" +
 	"\acommand\x18\x02 \x01(\tR\acommand\"]\n" +
 	"\x0fCommandResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
-	"\x06output\x18\x03 \x01(\tR\x06output\"\xcd\x01\n" +
+	"\x06output\x18\x03 \x01(\tR\x06output\"\xab\x01\n" +
+	"\x11StreamLogsRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x1d\n" +
+	"\n" +
+	"tail_lines\x18\x02 \x01(\x05R\ttailLines\x12\x14\n" +
+	"\x05since\x18\x03 \x01(\x03R\x05since\x12%\n" +
+	"\x0eafter_sequence\x18\x04 \x01(\x04R\rafterSequence\x12\x1d\n" +
+	"\n" +
+	"no_history\x18\x05 \x01(\bR\tnoHistory\"\x95\x03\n" +
 	"\bLogEntry\x12\x1c\n" +
 	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x1b\n" +
 	"\tserver_id\x18\x02 \x01(\tR\bserverId\x12\x14\n" +
@@ -2419,26 +5111,41 @@ const file_proto_agent_proto_rawDesc = "" +
 	"\amessage\x18\x05 \x01(\tR\amessage\x12\x16\n" +
 	"\x06plugin\x18\x06 \x01(\tR\x06plugin\x12\x12\n" +
 	"\x04file\x18\a \x01(\tR\x04file\x12\x12\n" +
-	"\x04line\x18\b \x01(\x05R\x04line\"Q\n" +
+	"\x04line\x18\b \x01(\x05R\x04line\x12\x1a\n" +
+	"\bsequence\x18\t \x01(\x04R\bsequence\x12\x16\n" +
+	"\x06thread\x18\n" +
+	" \x01(\tR\x06thread\x12\x16\n" +
+	"\x06stream\x18\v \x01(\tR\x06stream\x12\x1d\n" +
+	"\n" +
+	"error_type\x18\f \x01(\tR\terrorType\x12\x1a\n" +
+	"\bseverity\x18\r \x01(\x05R\bseverity\x12\x1e\n" +
+	"\n" +
+	"suggestion\x18\x0e \x01(\tR\n" +
+	"suggestion\x12\x1f\n" +
+	"\vstack_trace\x18\x0f \x03(\tR\n" +
+	"stackTrace\"n\n" +
 	"\vFileRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
 	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x16\n" +
-	"\x06length\x18\x03 \x01(\x03R\x06length\"`\n" +
+	"\x06length\x18\x03 \x01(\x03R\x06length\x12\x1b\n" +
+	"\tserver_id\x18\x04 \x01(\tR\bserverId\"`\n" +
 	"\vFileContent\x12\x18\n" +
 	"\acontent\x18\x01 \x01(\fR\acontent\x12\x12\n" +
 	"\x04size\x18\x02 \x01(\x03R\x04size\x12#\n" +
-	"\rmodified_time\x18\x03 \x01(\x03R\fmodifiedTime\"a\n" +
+	"\rmodified_time\x18\x03 \x01(\x03R\fmodifiedTime\"~\n" +
 	"\x10WriteFileRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
 	"\acontent\x18\x02 \x01(\fR\acontent\x12\x1f\n" +
 	"\vcreate_dirs\x18\x03 \x01(\bR\n" +
-	"createDirs\"B\n" +
+	"createDirs\x12\x1b\n" +
+	"\tserver_id\x18\x04 \x01(\tR\bserverId\"B\n" +
 	"\fFileResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"D\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"a\n" +
 	"\x10DirectoryRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1c\n" +
-	"\trecursive\x18\x02 \x01(\bR\trecursive\"1\n" +
+	"\trecursive\x18\x02 \x01(\bR\trecursive\x12\x1b\n" +
+	"\tserver_id\x18\x03 \x01(\tR\bserverId\"1\n" +
 	"\bFileList\x12%\n" +
 	"\x05files\x18\x01 \x03(\v2\x0f.agent.FileInfoR\x05files\"\xa4\x01\n" +
 	"\bFileInfo\x12\x12\n" +
@@ -2447,7 +5154,60 @@ const file_proto_agent_proto_rawDesc = "" +
 	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x15\n" +
 	"\x06is_dir\x18\x04 \x01(\bR\x05isDir\x12#\n" +
 	"\rmodified_time\x18\x05 \x01(\x03R\fmodifiedTime\x12 \n" +
-	"\vpermissions\x18\x06 \x01(\x05R\vpermissions\"\xdd\x02\n" +
+	"\vpermissions\x18\x06 \x01(\x05R\vpermissions\"b\n" +
+	"\x11DeleteFileRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x1c\n" +
+	"\trecursive\x18\x03 \x01(\bR\trecursive\"\x86\x01\n" +
+	"\x0fMoveFileRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12 \n" +
+	"\vdestination\x18\x03 \x01(\tR\vdestination\x12\x1c\n" +
+	"\toverwrite\x18\x04 \x01(\bR\toverwrite\"c\n" +
+	"\x16CreateDirectoryRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
+	"\aparents\x18\x03 \x01(\bR\aparents\"}\n" +
+	"\x18ChangePermissionsRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\rR\x04mode\x12\x1c\n" +
+	"\trecursive\x18\x04 \x01(\bR\trecursive\"\x83\x01\n" +
+	"\x14CompressFilesRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x14\n" +
+	"\x05paths\x18\x02 \x03(\tR\x05paths\x12 \n" +
+	"\vdestination\x18\x03 \x01(\tR\vdestination\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\"\x88\x01\n" +
+	"\x15ExtractArchiveRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12 \n" +
+	"\vdestination\x18\x03 \x01(\tR\vdestination\x12\x1c\n" +
+	"\toverwrite\x18\x04 \x01(\bR\toverwrite\"\xe9\x01\n" +
+	"\tFileChunk\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x03R\x06offset\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x05 \x01(\x03R\ttotalSize\x12#\n" +
+	"\rmodified_time\x18\x06 \x01(\x03R\fmodifiedTime\x12\x1f\n" +
+	"\vcreate_dirs\x18\a \x01(\bR\n" +
+	"createDirs\x12\x1a\n" +
+	"\bchecksum\x18\b \x01(\tR\bchecksum\"v\n" +
+	"\x13DownloadFileRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x03R\x06length\"\x99\x01\n" +
+	"\x14FileTransferResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\x12\x1a\n" +
+	"\bchecksum\x18\x05 \x01(\tR\bchecksum\".\n" +
+	"\x14UploadOffsetResponse\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x03R\x06offset\"\xdd\x02\n" +
 	"\x12DependenciesStatus\x12%\n" +
 	"\x0ejava_installed\x18\x01 \x01(\bR\rjavaInstalled\x12!\n" +
 	"\fjava_version\x18\x02 \x01(\tR\vjavaVersion\x12\x1d\n" +
@@ -2482,6 +5242,22 @@ const file_proto_agent_proto_rawDesc = "" +
 	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1a\n" +
 	"\bcomplete\x18\x05 \x01(\bR\bcomplete\x12\x14\n" +
 	"\x05error\x18\x06 \x01(\tR\x05error\"F\n" +
+	"\vPairRequest\x12!\n" +
+	"\fpairing_code\x18\x01 \x01(\tR\vpairingCode\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\"]\n" +
+	"\fPairResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x19\n" +
+	"\bagent_id\x18\x03 \x01(\tR\aagentId\".\n" +
+	"\x1aCertificateRequestResponse\x12\x10\n" +
+	"\x03csr\x18\x01 \x01(\fR\x03csr\"d\n" +
+	"\x19InstallCertificateRequest\x12 \n" +
+	"\vcertificate\x18\x01 \x01(\fR\vcertificate\x12%\n" +
+	"\x0eca_certificate\x18\x02 \x01(\fR\rcaCertificate\"r\n" +
+	"\x1aInstallCertificateResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12 \n" +
+	"\vfingerprint\x18\x03 \x01(\tR\vfingerprint\"F\n" +
 	"\fPongResponse\x12\x1c\n" +
 	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\"\xd2\x01\n" +
@@ -2539,7 +5315,95 @@ const file_proto_agent_proto_rawDesc = "" +
 	"\n" +
 	"PluginList\x12+\n" +
 	"\aplugins\x18\x01 \x03(\v2\x11.agent.PluginInfoR\aplugins\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x05R\x05total2\xe1\t\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\xaf\x03\n" +
+	"\x13CreateBackupRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x1f\n" +
+	"\vbackup_type\x18\x02 \x01(\tR\n" +
+	"backupType\x12 \n" +
+	"\vdestination\x18\x03 \x01(\tR\vdestination\x12 \n" +
+	"\vcompression\x18\x04 \x01(\tR\vcompression\x12\x1f\n" +
+	"\vstop_server\x18\x05 \x01(\bR\n" +
+	"stopServer\x12#\n" +
+	"\rinclude_world\x18\x06 \x01(\bR\fincludeWorld\x12'\n" +
+	"\x0finclude_plugins\x18\a \x01(\bR\x0eincludePlugins\x12%\n" +
+	"\x0einclude_config\x18\b \x01(\bR\rincludeConfig\x12!\n" +
+	"\finclude_logs\x18\t \x01(\bR\vincludeLogs\x12#\n" +
+	"\rexclude_paths\x18\n" +
+	" \x03(\tR\fexcludePaths\x12 \n" +
+	"\vincremental\x18\v \x01(\bR\vincremental\x12\x16\n" +
+	"\x06online\x18\f \x01(\bR\x06online\"\x85\x02\n" +
+	"\x14CreateBackupResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\vbackup_path\x18\x03 \x01(\tR\n" +
+	"backupPath\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\x12\x1a\n" +
+	"\bchecksum\x18\x05 \x01(\tR\bchecksum\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x03R\n" +
+	"durationMs\x12\x1f\n" +
+	"\vsnapshot_id\x18\a \x01(\tR\n" +
+	"snapshotId\x12\x1b\n" +
+	"\tnew_bytes\x18\b \x01(\x03R\bnewBytes\"\xbf\x02\n" +
+	"\x14RestoreBackupRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12\x1f\n" +
+	"\vbackup_path\x18\x02 \x01(\tR\n" +
+	"backupPath\x12\x1f\n" +
+	"\vstop_server\x18\x03 \x01(\bR\n" +
+	"stopServer\x12#\n" +
+	"\rrestore_world\x18\x04 \x01(\bR\frestoreWorld\x12'\n" +
+	"\x0frestore_plugins\x18\x05 \x01(\bR\x0erestorePlugins\x12%\n" +
+	"\x0erestore_config\x18\x06 \x01(\bR\rrestoreConfig\x122\n" +
+	"\x15backup_before_restore\x18\a \x01(\bR\x13backupBeforeRestore\x12\x1f\n" +
+	"\vsnapshot_id\x18\b \x01(\tR\n" +
+	"snapshotId\"\x9a\x01\n" +
+	"\x15RestoreBackupResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\vduration_ms\x18\x03 \x01(\x03R\n" +
+	"durationMs\x12,\n" +
+	"\x12safety_backup_path\x18\x04 \x01(\tR\x10safetyBackupPath\"'\n" +
+	"\x11BackupFileRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"l\n" +
+	"\vBackupChunk\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x04 \x01(\x03R\ttotalSize\"\x97\x01\n" +
+	"\x12BackupFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\x12\x1a\n" +
+	"\bchecksum\x18\x05 \x01(\tR\bchecksum\"3\n" +
+	"\x14ListSnapshotsRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\"\xb5\x01\n" +
+	"\fSnapshotInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tparent_id\x18\x02 \x01(\tR\bparentId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"file_count\x18\x04 \x01(\x05R\tfileCount\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x05 \x01(\x03R\ttotalSize\x12\x1b\n" +
+	"\tnew_bytes\x18\x06 \x01(\x03R\bnewBytes\"J\n" +
+	"\x15ListSnapshotsResponse\x121\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x13.agent.SnapshotInfoR\tsnapshots\"t\n" +
+	"\x15PruneSnapshotsRequest\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12!\n" +
+	"\fsnapshot_ids\x18\x02 \x03(\tR\vsnapshotIds\x12\x1b\n" +
+	"\tkeep_last\x18\x03 \x01(\x05R\bkeepLast\"\xb5\x01\n" +
+	"\x16PruneSnapshotsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\vdeleted_ids\x18\x03 \x03(\tR\n" +
+	"deletedIds\x12%\n" +
+	"\x0echunks_removed\x18\x04 \x01(\x05R\rchunksRemoved\x12\x1f\n" +
+	"\vbytes_freed\x18\x05 \x01(\x03R\n" +
+	"bytesFreed2\xc3\x15\n" +
 	"\fAgentService\x12.\n" +
 	"\fGetAgentInfo\x12\f.agent.Empty\x1a\x10.agent.AgentInfo\x126\n" +
 	"\x10GetSystemMetrics\x12\f.agent.Empty\x1a\x14.agent.SystemMetrics\x12.\n" +
@@ -2548,22 +5412,46 @@ const file_proto_agent_proto_rawDesc = "" +
 	"\vStartServer\x12\x19.agent.StartServerRequest\x1a\x15.agent.ServerResponse\x129\n" +
 	"\n" +
 	"StopServer\x12\x14.agent.ServerRequest\x1a\x15.agent.ServerResponse\x12<\n" +
-	"\rRestartServer\x12\x14.agent.ServerRequest\x1a\x15.agent.ServerResponse\x12<\n" +
-	"\vSendCommand\x12\x15.agent.CommandRequest\x1a\x16.agent.CommandResponse\x125\n" +
+	"\rRestartServer\x12\x14.agent.ServerRequest\x1a\x15.agent.ServerResponse\x12>\n" +
+	"\x10GetServerMetrics\x12\x14.agent.ServerRequest\x1a\x14.agent.ServerMetrics\x12?\n" +
+	"\vEventStream\x12\x19.agent.EventStreamRequest\x1a\x11.agent.AgentEvent(\x010\x01\x12<\n" +
+	"\vSendCommand\x12\x15.agent.CommandRequest\x1a\x16.agent.CommandResponse\x129\n" +
 	"\n" +
-	"StreamLogs\x12\x14.agent.ServerRequest\x1a\x0f.agent.LogEntry0\x01\x122\n" +
+	"StreamLogs\x12\x18.agent.StreamLogsRequest\x1a\x0f.agent.LogEntry0\x01\x122\n" +
 	"\bReadFile\x12\x12.agent.FileRequest\x1a\x12.agent.FileContent\x129\n" +
 	"\tWriteFile\x12\x17.agent.WriteFileRequest\x1a\x13.agent.FileResponse\x125\n" +
-	"\tListFiles\x12\x17.agent.DirectoryRequest\x1a\x0f.agent.FileList\x12C\n" +
+	"\tListFiles\x12\x17.agent.DirectoryRequest\x1a\x0f.agent.FileList\x12;\n" +
+	"\n" +
+	"DeleteFile\x12\x18.agent.DeleteFileRequest\x1a\x13.agent.FileResponse\x127\n" +
+	"\bMoveFile\x12\x16.agent.MoveFileRequest\x1a\x13.agent.FileResponse\x127\n" +
+	"\bCopyFile\x12\x16.agent.MoveFileRequest\x1a\x13.agent.FileResponse\x12E\n" +
+	"\x0fCreateDirectory\x12\x1d.agent.CreateDirectoryRequest\x1a\x13.agent.FileResponse\x12I\n" +
+	"\x11ChangePermissions\x12\x1f.agent.ChangePermissionsRequest\x1a\x13.agent.FileResponse\x12A\n" +
+	"\rCompressFiles\x12\x1b.agent.CompressFilesRequest\x1a\x13.agent.FileResponse\x12C\n" +
+	"\x0eExtractArchive\x12\x1c.agent.ExtractArchiveRequest\x1a\x13.agent.FileResponse\x12=\n" +
+	"\n" +
+	"UploadFile\x12\x10.agent.FileChunk\x1a\x1b.agent.FileTransferResponse(\x01\x12>\n" +
+	"\fDownloadFile\x12\x1a.agent.DownloadFileRequest\x1a\x10.agent.FileChunk0\x01\x12B\n" +
+	"\x0fGetUploadOffset\x12\x12.agent.FileRequest\x1a\x1b.agent.UploadOffsetResponse\x12C\n" +
 	"\rInstallPlugin\x12\x1b.agent.InstallPluginRequest\x1a\x15.agent.PluginResponse\x12G\n" +
 	"\x0fUninstallPlugin\x12\x1d.agent.UninstallPluginRequest\x1a\x15.agent.PluginResponse\x12A\n" +
 	"\fUpdatePlugin\x12\x1a.agent.UpdatePluginRequest\x1a\x15.agent.PluginResponse\x12;\n" +
-	"\vListPlugins\x12\x19.agent.ListPluginsRequest\x1a\x11.agent.PluginList\x12<\n" +
+	"\vListPlugins\x12\x19.agent.ListPluginsRequest\x1a\x11.agent.PluginList\x12G\n" +
+	"\fCreateBackup\x12\x1a.agent.CreateBackupRequest\x1a\x1b.agent.CreateBackupResponse\x12J\n" +
+	"\rRestoreBackup\x12\x1b.agent.RestoreBackupRequest\x1a\x1c.agent.RestoreBackupResponse\x12@\n" +
+	"\x0eDownloadBackup\x12\x18.agent.BackupFileRequest\x1a\x12.agent.BackupChunk0\x01\x12?\n" +
+	"\fUploadBackup\x12\x12.agent.BackupChunk\x1a\x19.agent.BackupFileResponse(\x01\x12C\n" +
+	"\fDeleteBackup\x12\x18.agent.BackupFileRequest\x1a\x19.agent.BackupFileResponse\x12J\n" +
+	"\rListSnapshots\x12\x1b.agent.ListSnapshotsRequest\x1a\x1c.agent.ListSnapshotsResponse\x12M\n" +
+	"\x0ePruneSnapshots\x12\x1c.agent.PruneSnapshotsRequest\x1a\x1d.agent.PruneSnapshotsResponse\x12<\n" +
 	"\x11CheckDependencies\x12\f.agent.Empty\x1a\x19.agent.DependenciesStatus\x12@\n" +
 	"\vInstallJava\x12\x19.agent.JavaInstallRequest\x1a\x16.agent.InstallResponse\x12C\n" +
 	"\x0eDownloadServer\x12\x16.agent.DownloadRequest\x1a\x17.agent.DownloadProgress0\x01\x12)\n" +
 	"\x04Ping\x12\f.agent.Empty\x1a\x13.agent.PongResponse\x120\n" +
-	"\vHealthCheck\x12\f.agent.Empty\x1a\x13.agent.HealthStatusB\x1fZ\x1dgithub.com/aymc/backend/protob\x06proto3"
+	"\vHealthCheck\x12\f.agent.Empty\x1a\x13.agent.HealthStatus\x12/\n" +
+	"\x04Pair\x12\x12.agent.PairRequest\x1a\x13.agent.PairResponse\x12K\n" +
+	"\x18CreateCertificateRequest\x12\f.agent.Empty\x1a!.agent.CertificateRequestResponse\x12Y\n" +
+	"\x12InstallCertificate\x12 .agent.InstallCertificateRequest\x1a!.agent.InstallCertificateResponseB\x1fZ\x1dgithub.com/aymc/backend/protob\x06proto3"
 
 var (
 	file_proto_agent_proto_rawDescOnce sync.Once
@@ -2577,103 +5465,186 @@ func file_proto_agent_proto_rawDescGZIP() []byte {
 	return file_proto_agent_proto_rawDescData
 }
 
-var file_proto_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
+var file_proto_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 69)
 var file_proto_agent_proto_goTypes = []any{
-	(*Empty)(nil),                  // 0: agent.Empty
-	(*AgentInfo)(nil),              // 1: agent.AgentInfo
-	(*SystemMetrics)(nil),          // 2: agent.SystemMetrics
-	(*ServerInfo)(nil),             // 3: agent.ServerInfo
-	(*ServerConfig)(nil),           // 4: agent.ServerConfig
-	(*ServerList)(nil),             // 5: agent.ServerList
-	(*ServerRequest)(nil),          // 6: agent.ServerRequest
-	(*StartServerRequest)(nil),     // 7: agent.StartServerRequest
-	(*ServerResponse)(nil),         // 8: agent.ServerResponse
-	(*CommandRequest)(nil),         // 9: agent.CommandRequest
-	(*CommandResponse)(nil),        // 10: agent.CommandResponse
-	(*LogEntry)(nil),               // 11: agent.LogEntry
-	(*FileRequest)(nil),            // 12: agent.FileRequest
-	(*FileContent)(nil),            // 13: agent.FileContent
-	(*WriteFileRequest)(nil),       // 14: agent.WriteFileRequest
-	(*FileResponse)(nil),           // 15: agent.FileResponse
-	(*DirectoryRequest)(nil),       // 16: agent.DirectoryRequest
-	(*FileList)(nil),               // 17: agent.FileList
-	(*FileInfo)(nil),               // 18: agent.FileInfo
-	(*DependenciesStatus)(nil),     // 19: agent.DependenciesStatus
-	(*JavaInstallRequest)(nil),     // 20: agent.JavaInstallRequest
-	(*InstallResponse)(nil),        // 21: agent.InstallResponse
-	(*DownloadRequest)(nil),        // 22: agent.DownloadRequest
-	(*DownloadProgress)(nil),       // 23: agent.DownloadProgress
-	(*PongResponse)(nil),           // 24: agent.PongResponse
-	(*HealthStatus)(nil),           // 25: agent.HealthStatus
-	(*InstallPluginRequest)(nil),   // 26: agent.InstallPluginRequest
-	(*UninstallPluginRequest)(nil), // 27: agent.UninstallPluginRequest
-	(*UpdatePluginRequest)(nil),    // 28: agent.UpdatePluginRequest
-	(*ListPluginsRequest)(nil),     // 29: agent.ListPluginsRequest
-	(*PluginResponse)(nil),         // 30: agent.PluginResponse
-	(*PluginInfo)(nil),             // 31: agent.PluginInfo
-	(*PluginList)(nil),             // 32: agent.PluginList
-	nil,                            // 33: agent.ServerConfig.CustomArgsEntry
-	nil,                            // 34: agent.DependenciesStatus.EnvironmentEntry
-	nil,                            // 35: agent.HealthStatus.ChecksEntry
+	(*Empty)(nil),                      // 0: agent.Empty
+	(*AgentInfo)(nil),                  // 1: agent.AgentInfo
+	(*SystemMetrics)(nil),              // 2: agent.SystemMetrics
+	(*ServerInfo)(nil),                 // 3: agent.ServerInfo
+	(*ServerMetrics)(nil),              // 4: agent.ServerMetrics
+	(*ServerConfig)(nil),               // 5: agent.ServerConfig
+	(*ServerList)(nil),                 // 6: agent.ServerList
+	(*ServerRequest)(nil),              // 7: agent.ServerRequest
+	(*StartServerRequest)(nil),         // 8: agent.StartServerRequest
+	(*ServerResponse)(nil),             // 9: agent.ServerResponse
+	(*ServerEvent)(nil),                // 10: agent.ServerEvent
+	(*EventStreamRequest)(nil),         // 11: agent.EventStreamRequest
+	(*AgentEvent)(nil),                 // 12: agent.AgentEvent
+	(*LogAlert)(nil),                   // 13: agent.LogAlert
+	(*CommandRequest)(nil),             // 14: agent.CommandRequest
+	(*CommandResponse)(nil),            // 15: agent.CommandResponse
+	(*StreamLogsRequest)(nil),          // 16: agent.StreamLogsRequest
+	(*LogEntry)(nil),                   // 17: agent.LogEntry
+	(*FileRequest)(nil),                // 18: agent.FileRequest
+	(*FileContent)(nil),                // 19: agent.FileContent
+	(*WriteFileRequest)(nil),           // 20: agent.WriteFileRequest
+	(*FileResponse)(nil),               // 21: agent.FileResponse
+	(*DirectoryRequest)(nil),           // 22: agent.DirectoryRequest
+	(*FileList)(nil),                   // 23: agent.FileList
+	(*FileInfo)(nil),                   // 24: agent.FileInfo
+	(*DeleteFileRequest)(nil),          // 25: agent.DeleteFileRequest
+	(*MoveFileRequest)(nil),            // 26: agent.MoveFileRequest
+	(*CreateDirectoryRequest)(nil),     // 27: agent.CreateDirectoryRequest
+	(*ChangePermissionsRequest)(nil),   // 28: agent.ChangePermissionsRequest
+	(*CompressFilesRequest)(nil),       // 29: agent.CompressFilesRequest
+	(*ExtractArchiveRequest)(nil),      // 30: agent.ExtractArchiveRequest
+	(*FileChunk)(nil),                  // 31: agent.FileChunk
+	(*DownloadFileRequest)(nil),        // 32: agent.DownloadFileRequest
+	(*FileTransferResponse)(nil),       // 33: agent.FileTransferResponse
+	(*UploadOffsetResponse)(nil),       // 34: agent.UploadOffsetResponse
+	(*DependenciesStatus)(nil),         // 35: agent.DependenciesStatus
+	(*JavaInstallRequest)(nil),         // 36: agent.JavaInstallRequest
+	(*InstallResponse)(nil),            // 37: agent.InstallResponse
+	(*DownloadRequest)(nil),            // 38: agent.DownloadRequest
+	(*DownloadProgress)(nil),           // 39: agent.DownloadProgress
+	(*PairRequest)(nil),                // 40: agent.PairRequest
+	(*PairResponse)(nil),               // 41: agent.PairResponse
+	(*CertificateRequestResponse)(nil), // 42: agent.CertificateRequestResponse
+	(*InstallCertificateRequest)(nil),  // 43: agent.InstallCertificateRequest
+	(*InstallCertificateResponse)(nil), // 44: agent.InstallCertificateResponse
+	(*PongResponse)(nil),               // 45: agent.PongResponse
+	(*HealthStatus)(nil),               // 46: agent.HealthStatus
+	(*InstallPluginRequest)(nil),       // 47: agent.InstallPluginRequest
+	(*UninstallPluginRequest)(nil),     // 48: agent.UninstallPluginRequest
+	(*UpdatePluginRequest)(nil),        // 49: agent.UpdatePluginRequest
+	(*ListPluginsRequest)(nil),         // 50: agent.ListPluginsRequest
+	(*PluginResponse)(nil),             // 51: agent.PluginResponse
+	(*PluginInfo)(nil),                 // 52: agent.PluginInfo
+	(*PluginList)(nil),                 // 53: agent.PluginList
+	(*CreateBackupRequest)(nil),        // 54: agent.CreateBackupRequest
+	(*CreateBackupResponse)(nil),       // 55: agent.CreateBackupResponse
+	(*RestoreBackupRequest)(nil),       // 56: agent.RestoreBackupRequest
+	(*RestoreBackupResponse)(nil),      // 57: agent.RestoreBackupResponse
+	(*BackupFileRequest)(nil),          // 58: agent.BackupFileRequest
+	(*BackupChunk)(nil),                // 59: agent.BackupChunk
+	(*BackupFileResponse)(nil),         // 60: agent.BackupFileResponse
+	(*ListSnapshotsRequest)(nil),       // 61: agent.ListSnapshotsRequest
+	(*SnapshotInfo)(nil),               // 62: agent.SnapshotInfo
+	(*ListSnapshotsResponse)(nil),      // 63: agent.ListSnapshotsResponse
+	(*PruneSnapshotsRequest)(nil),      // 64: agent.PruneSnapshotsRequest
+	(*PruneSnapshotsResponse)(nil),     // 65: agent.PruneSnapshotsResponse
+	nil,                                // 66: agent.ServerConfig.CustomArgsEntry
+	nil,                                // 67: agent.DependenciesStatus.EnvironmentEntry
+	nil,                                // 68: agent.HealthStatus.ChecksEntry
 }
 var file_proto_agent_proto_depIdxs = []int32{
-	4,  // 0: agent.ServerInfo.config:type_name -> agent.ServerConfig
-	33, // 1: agent.ServerConfig.custom_args:type_name -> agent.ServerConfig.CustomArgsEntry
-	3,  // 2: agent.ServerList.servers:type_name -> agent.ServerInfo
-	4,  // 3: agent.StartServerRequest.config:type_name -> agent.ServerConfig
-	3,  // 4: agent.ServerResponse.server:type_name -> agent.ServerInfo
-	18, // 5: agent.FileList.files:type_name -> agent.FileInfo
-	34, // 6: agent.DependenciesStatus.environment:type_name -> agent.DependenciesStatus.EnvironmentEntry
-	35, // 7: agent.HealthStatus.checks:type_name -> agent.HealthStatus.ChecksEntry
-	31, // 8: agent.PluginResponse.plugin:type_name -> agent.PluginInfo
-	31, // 9: agent.PluginList.plugins:type_name -> agent.PluginInfo
-	0,  // 10: agent.AgentService.GetAgentInfo:input_type -> agent.Empty
-	0,  // 11: agent.AgentService.GetSystemMetrics:input_type -> agent.Empty
-	0,  // 12: agent.AgentService.ListServers:input_type -> agent.Empty
-	6,  // 13: agent.AgentService.GetServer:input_type -> agent.ServerRequest
-	7,  // 14: agent.AgentService.StartServer:input_type -> agent.StartServerRequest
-	6,  // 15: agent.AgentService.StopServer:input_type -> agent.ServerRequest
-	6,  // 16: agent.AgentService.RestartServer:input_type -> agent.ServerRequest
-	9,  // 17: agent.AgentService.SendCommand:input_type -> agent.CommandRequest
-	6,  // 18: agent.AgentService.StreamLogs:input_type -> agent.ServerRequest
-	12, // 19: agent.AgentService.ReadFile:input_type -> agent.FileRequest
-	14, // 20: agent.AgentService.WriteFile:input_type -> agent.WriteFileRequest
-	16, // 21: agent.AgentService.ListFiles:input_type -> agent.DirectoryRequest
-	26, // 22: agent.AgentService.InstallPlugin:input_type -> agent.InstallPluginRequest
-	27, // 23: agent.AgentService.UninstallPlugin:input_type -> agent.UninstallPluginRequest
-	28, // 24: agent.AgentService.UpdatePlugin:input_type -> agent.UpdatePluginRequest
-	29, // 25: agent.AgentService.ListPlugins:input_type -> agent.ListPluginsRequest
-	0,  // 26: agent.AgentService.CheckDependencies:input_type -> agent.Empty
-	20, // 27: agent.AgentService.InstallJava:input_type -> agent.JavaInstallRequest
-	22, // 28: agent.AgentService.DownloadServer:input_type -> agent.DownloadRequest
-	0,  // 29: agent.AgentService.Ping:input_type -> agent.Empty
-	0,  // 30: agent.AgentService.HealthCheck:input_type -> agent.Empty
-	1,  // 31: agent.AgentService.GetAgentInfo:output_type -> agent.AgentInfo
-	2,  // 32: agent.AgentService.GetSystemMetrics:output_type -> agent.SystemMetrics
-	5,  // 33: agent.AgentService.ListServers:output_type -> agent.ServerList
-	3,  // 34: agent.AgentService.GetServer:output_type -> agent.ServerInfo
-	8,  // 35: agent.AgentService.StartServer:output_type -> agent.ServerResponse
-	8,  // 36: agent.AgentService.StopServer:output_type -> agent.ServerResponse
-	8,  // 37: agent.AgentService.RestartServer:output_type -> agent.ServerResponse
-	10, // 38: agent.AgentService.SendCommand:output_type -> agent.CommandResponse
-	11, // 39: agent.AgentService.StreamLogs:output_type -> agent.LogEntry
-	13, // 40: agent.AgentService.ReadFile:output_type -> agent.FileContent
-	15, // 41: agent.AgentService.WriteFile:output_type -> agent.FileResponse
-	17, // 42: agent.AgentService.ListFiles:output_type -> agent.FileList
-	30, // 43: agent.AgentService.InstallPlugin:output_type -> agent.PluginResponse
-	30, // 44: agent.AgentService.UninstallPlugin:output_type -> agent.PluginResponse
-	30, // 45: agent.AgentService.UpdatePlugin:output_type -> agent.PluginResponse
-	32, // 46: agent.AgentService.ListPlugins:output_type -> agent.PluginList
-	19, // 47: agent.AgentService.CheckDependencies:output_type -> agent.DependenciesStatus
-	21, // 48: agent.AgentService.InstallJava:output_type -> agent.InstallResponse
-	23, // 49: agent.AgentService.DownloadServer:output_type -> agent.DownloadProgress
-	24, // 50: agent.AgentService.Ping:output_type -> agent.PongResponse
-	25, // 51: agent.AgentService.HealthCheck:output_type -> agent.HealthStatus
-	31, // [31:52] is the sub-list for method output_type
-	10, // [10:31] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	5,  // 0: agent.ServerInfo.config:type_name -> agent.ServerConfig
+	4,  // 1: agent.ServerInfo.metrics:type_name -> agent.ServerMetrics
+	66, // 2: agent.ServerConfig.custom_args:type_name -> agent.ServerConfig.CustomArgsEntry
+	3,  // 3: agent.ServerList.servers:type_name -> agent.ServerInfo
+	5,  // 4: agent.StartServerRequest.config:type_name -> agent.ServerConfig
+	3,  // 5: agent.ServerResponse.server:type_name -> agent.ServerInfo
+	2,  // 6: agent.AgentEvent.metrics:type_name -> agent.SystemMetrics
+	10, // 7: agent.AgentEvent.server_event:type_name -> agent.ServerEvent
+	13, // 8: agent.AgentEvent.log_alert:type_name -> agent.LogAlert
+	45, // 9: agent.AgentEvent.pong:type_name -> agent.PongResponse
+	24, // 10: agent.FileList.files:type_name -> agent.FileInfo
+	67, // 11: agent.DependenciesStatus.environment:type_name -> agent.DependenciesStatus.EnvironmentEntry
+	68, // 12: agent.HealthStatus.checks:type_name -> agent.HealthStatus.ChecksEntry
+	52, // 13: agent.PluginResponse.plugin:type_name -> agent.PluginInfo
+	52, // 14: agent.PluginList.plugins:type_name -> agent.PluginInfo
+	62, // 15: agent.ListSnapshotsResponse.snapshots:type_name -> agent.SnapshotInfo
+	0,  // 16: agent.AgentService.GetAgentInfo:input_type -> agent.Empty
+	0,  // 17: agent.AgentService.GetSystemMetrics:input_type -> agent.Empty
+	0,  // 18: agent.AgentService.ListServers:input_type -> agent.Empty
+	7,  // 19: agent.AgentService.GetServer:input_type -> agent.ServerRequest
+	8,  // 20: agent.AgentService.StartServer:input_type -> agent.StartServerRequest
+	7,  // 21: agent.AgentService.StopServer:input_type -> agent.ServerRequest
+	7,  // 22: agent.AgentService.RestartServer:input_type -> agent.ServerRequest
+	7,  // 23: agent.AgentService.GetServerMetrics:input_type -> agent.ServerRequest
+	11, // 24: agent.AgentService.EventStream:input_type -> agent.EventStreamRequest
+	14, // 25: agent.AgentService.SendCommand:input_type -> agent.CommandRequest
+	16, // 26: agent.AgentService.StreamLogs:input_type -> agent.StreamLogsRequest
+	18, // 27: agent.AgentService.ReadFile:input_type -> agent.FileRequest
+	20, // 28: agent.AgentService.WriteFile:input_type -> agent.WriteFileRequest
+	22, // 29: agent.AgentService.ListFiles:input_type -> agent.DirectoryRequest
+	25, // 30: agent.AgentService.DeleteFile:input_type -> agent.DeleteFileRequest
+	26, // 31: agent.AgentService.MoveFile:input_type -> agent.MoveFileRequest
+	26, // 32: agent.AgentService.CopyFile:input_type -> agent.MoveFileRequest
+	27, // 33: agent.AgentService.CreateDirectory:input_type -> agent.CreateDirectoryRequest
+	28, // 34: agent.AgentService.ChangePermissions:input_type -> agent.ChangePermissionsRequest
+	29, // 35: agent.AgentService.CompressFiles:input_type -> agent.CompressFilesRequest
+	30, // 36: agent.AgentService.ExtractArchive:input_type -> agent.ExtractArchiveRequest
+	31, // 37: agent.AgentService.UploadFile:input_type -> agent.FileChunk
+	32, // 38: agent.AgentService.DownloadFile:input_type -> agent.DownloadFileRequest
+	18, // 39: agent.AgentService.GetUploadOffset:input_type -> agent.FileRequest
+	47, // 40: agent.AgentService.InstallPlugin:input_type -> agent.InstallPluginRequest
+	48, // 41: agent.AgentService.UninstallPlugin:input_type -> agent.UninstallPluginRequest
+	49, // 42: agent.AgentService.UpdatePlugin:input_type -> agent.UpdatePluginRequest
+	50, // 43: agent.AgentService.ListPlugins:input_type -> agent.ListPluginsRequest
+	54, // 44: agent.AgentService.CreateBackup:input_type -> agent.CreateBackupRequest
+	56, // 45: agent.AgentService.RestoreBackup:input_type -> agent.RestoreBackupRequest
+	58, // 46: agent.AgentService.DownloadBackup:input_type -> agent.BackupFileRequest
+	59, // 47: agent.AgentService.UploadBackup:input_type -> agent.BackupChunk
+	58, // 48: agent.AgentService.DeleteBackup:input_type -> agent.BackupFileRequest
+	61, // 49: agent.AgentService.ListSnapshots:input_type -> agent.ListSnapshotsRequest
+	64, // 50: agent.AgentService.PruneSnapshots:input_type -> agent.PruneSnapshotsRequest
+	0,  // 51: agent.AgentService.CheckDependencies:input_type -> agent.Empty
+	36, // 52: agent.AgentService.InstallJava:input_type -> agent.JavaInstallRequest
+	38, // 53: agent.AgentService.DownloadServer:input_type -> agent.DownloadRequest
+	0,  // 54: agent.AgentService.Ping:input_type -> agent.Empty
+	0,  // 55: agent.AgentService.HealthCheck:input_type -> agent.Empty
+	40, // 56: agent.AgentService.Pair:input_type -> agent.PairRequest
+	0,  // 57: agent.AgentService.CreateCertificateRequest:input_type -> agent.Empty
+	43, // 58: agent.AgentService.InstallCertificate:input_type -> agent.InstallCertificateRequest
+	1,  // 59: agent.AgentService.GetAgentInfo:output_type -> agent.AgentInfo
+	2,  // 60: agent.AgentService.GetSystemMetrics:output_type -> agent.SystemMetrics
+	6,  // 61: agent.AgentService.ListServers:output_type -> agent.ServerList
+	3,  // 62: agent.AgentService.GetServer:output_type -> agent.ServerInfo
+	9,  // 63: agent.AgentService.StartServer:output_type -> agent.ServerResponse
+	9,  // 64: agent.AgentService.StopServer:output_type -> agent.ServerResponse
+	9,  // 65: agent.AgentService.RestartServer:output_type -> agent.ServerResponse
+	4,  // 66: agent.AgentService.GetServerMetrics:output_type -> agent.ServerMetrics
+	12, // 67: agent.AgentService.EventStream:output_type -> agent.AgentEvent
+	15, // 68: agent.AgentService.SendCommand:output_type -> agent.CommandResponse
+	17, // 69: agent.AgentService.StreamLogs:output_type -> agent.LogEntry
+	19, // 70: agent.AgentService.ReadFile:output_type -> agent.FileContent
+	21, // 71: agent.AgentService.WriteFile:output_type -> agent.FileResponse
+	23, // 72: agent.AgentService.ListFiles:output_type -> agent.FileList
+	21, // 73: agent.AgentService.DeleteFile:output_type -> agent.FileResponse
+	21, // 74: agent.AgentService.MoveFile:output_type -> agent.FileResponse
+	21, // 75: agent.AgentService.CopyFile:output_type -> agent.FileResponse
+	21, // 76: agent.AgentService.CreateDirectory:output_type -> agent.FileResponse
+	21, // 77: agent.AgentService.ChangePermissions:output_type -> agent.FileResponse
+	21, // 78: agent.AgentService.CompressFiles:output_type -> agent.FileResponse
+	21, // 79: agent.AgentService.ExtractArchive:output_type -> agent.FileResponse
+	33, // 80: agent.AgentService.UploadFile:output_type -> agent.FileTransferResponse
+	31, // 81: agent.AgentService.DownloadFile:output_type -> agent.FileChunk
+	34, // 82: agent.AgentService.GetUploadOffset:output_type -> agent.UploadOffsetResponse
+	51, // 83: agent.AgentService.InstallPlugin:output_type -> agent.PluginResponse
+	51, // 84: agent.AgentService.UninstallPlugin:output_type -> agent.PluginResponse
+	51, // 85: agent.AgentService.UpdatePlugin:output_type -> agent.PluginResponse
+	53, // 86: agent.AgentService.ListPlugins:output_type -> agent.PluginList
+	55, // 87: agent.AgentService.CreateBackup:output_type -> agent.CreateBackupResponse
+	57, // 88: agent.AgentService.RestoreBackup:output_type -> agent.RestoreBackupResponse
+	59, // 89: agent.AgentService.DownloadBackup:output_type -> agent.BackupChunk
+	60, // 90: agent.AgentService.UploadBackup:output_type -> agent.BackupFileResponse
+	60, // 91: agent.AgentService.DeleteBackup:output_type -> agent.BackupFileResponse
+	63, // 92: agent.AgentService.ListSnapshots:output_type -> agent.ListSnapshotsResponse
+	65, // 93: agent.AgentService.PruneSnapshots:output_type -> agent.PruneSnapshotsResponse
+	35, // 94: agent.AgentService.CheckDependencies:output_type -> agent.DependenciesStatus
+	37, // 95: agent.AgentService.InstallJava:output_type -> agent.InstallResponse
+	39, // 96: agent.AgentService.DownloadServer:output_type -> agent.DownloadProgress
+	45, // 97: agent.AgentService.Ping:output_type -> agent.PongResponse
+	46, // 98: agent.AgentService.HealthCheck:output_type -> agent.HealthStatus
+	41, // 99: agent.AgentService.Pair:output_type -> agent.PairResponse
+	42, // 100: agent.AgentService.CreateCertificateRequest:output_type -> agent.CertificateRequestResponse
+	44, // 101: agent.AgentService.InstallCertificate:output_type -> agent.InstallCertificateResponse
+	59, // [59:102] is the sub-list for method output_type
+	16, // [16:59] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_proto_agent_proto_init() }
@@ -2681,13 +5652,19 @@ func file_proto_agent_proto_init() {
 	if File_proto_agent_proto != nil {
 		return
 	}
+	file_proto_agent_proto_msgTypes[12].OneofWrappers = []any{
+		(*AgentEvent_Metrics)(nil),
+		(*AgentEvent_ServerEvent)(nil),
+		(*AgentEvent_LogAlert)(nil),
+		(*AgentEvent_Pong)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_agent_proto_rawDesc), len(file_proto_agent_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   36,
+			NumMessages:   69,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
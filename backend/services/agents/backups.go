@@ -0,0 +1,226 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	pb "github.com/aymc/backend/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// backupUploadChunkSize tamaño de cada fragmento enviado en UploadBackup
+const backupUploadChunkSize = 1024 * 1024
+
+// healthyClient obtiene el cliente gRPC de un agente sano
+func (s *AgentService) healthyClient(agentID uuid.UUID) (pb.AgentServiceClient, error) {
+	agent, err := s.registry.GetAgent(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	if !agent.IsHealthy() {
+		return nil, fmt.Errorf("agent is not healthy")
+	}
+
+	client := agent.GetClient()
+	if client == nil {
+		return nil, fmt.Errorf("agent not connected")
+	}
+
+	return client, nil
+}
+
+// DownloadBackup abre un archivo del directorio de backups del agente.
+// Retorna un lector sobre el stream y el tamaño total anunciado por el agente.
+// El llamador debe cerrar el lector.
+func (s *AgentService) DownloadBackup(ctx context.Context, agentID uuid.UUID, path string) (io.ReadCloser, int64, error) {
+	s.logger.Info("Downloading backup from agent",
+		zap.String("agent_id", agentID.String()),
+		zap.String("path", path),
+	)
+
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	streamCtx, cancel := context.WithTimeout(ctx, BackupOperationTimeout)
+
+	stream, err := client.DownloadBackup(streamCtx, &pb.BackupFileRequest{Path: path})
+	if err != nil {
+		cancel()
+		return nil, 0, fmt.Errorf("failed to download backup: %w", err)
+	}
+
+	// Leer el primer fragmento para detectar errores antes de retornar
+	first, err := stream.Recv()
+	if err == io.EOF {
+		cancel()
+		return io.NopCloser(&backupStreamReader{}), 0, nil
+	}
+	if err != nil {
+		cancel()
+		return nil, 0, fmt.Errorf("failed to download backup: %w", err)
+	}
+
+	reader := &backupStreamReader{
+		stream:  stream,
+		cancel:  cancel,
+		pending: first.Data,
+		offset:  int64(len(first.Data)),
+	}
+
+	return reader, first.TotalSize, nil
+}
+
+// backupStreamReader adapta el stream de DownloadBackup a io.Reader
+type backupStreamReader struct {
+	stream  pb.AgentService_DownloadBackupClient
+	cancel  context.CancelFunc
+	pending []byte
+	offset  int64
+	done    bool
+}
+
+func (r *backupStreamReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done || r.stream == nil {
+			return 0, io.EOF
+		}
+
+		chunk, err := r.stream.Recv()
+		if err == io.EOF {
+			r.done = true
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to download backup: %w", err)
+		}
+		if chunk.Offset != r.offset {
+			return 0, fmt.Errorf("backup chunk out of order: offset %d, expected %d", chunk.Offset, r.offset)
+		}
+
+		r.pending = chunk.Data
+		r.offset += int64(len(chunk.Data))
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *backupStreamReader) Close() error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return nil
+}
+
+// UploadBackup envía el contenido de reader al directorio de backups del
+// agente. path es relativo a ese directorio.
+func (s *AgentService) UploadBackup(ctx context.Context, agentID uuid.UUID, path string, reader io.Reader) (*pb.BackupFileResponse, error) {
+	s.logger.Info("Uploading backup to agent",
+		zap.String("agent_id", agentID.String()),
+		zap.String("path", path),
+	)
+
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, BackupOperationTimeout)
+	defer cancel()
+
+	stream, err := client.UploadBackup(timeoutCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload backup: %w", err)
+	}
+
+	buffer := make([]byte, backupUploadChunkSize)
+	var offset int64
+	sent := false
+	for {
+		n, readErr := reader.Read(buffer)
+		if n > 0 || !sent {
+			chunk := &pb.BackupChunk{
+				Path:   path,
+				Data:   buffer[:n],
+				Offset: offset,
+			}
+			if err := stream.Send(chunk); err != nil {
+				// El error real llega en CloseAndRecv
+				_, recvErr := stream.CloseAndRecv()
+				if recvErr == nil {
+					recvErr = err
+				}
+				return nil, fmt.Errorf("failed to upload backup: %w", recvErr)
+			}
+			offset += int64(n)
+			sent = true
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", readErr)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload backup: %w", err)
+	}
+
+	return resp, nil
+}
+
+// DeleteBackup elimina un archivo del directorio de backups del agente
+func (s *AgentService) DeleteBackup(ctx context.Context, agentID uuid.UUID, path string) error {
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := client.DeleteBackup(timeoutCtx, &pb.BackupFileRequest{Path: path})
+	if err != nil {
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("agent failed to delete backup: %s", resp.Message)
+	}
+
+	return nil
+}
+
+// PruneSnapshots elimina snapshots incrementales del agente y los
+// fragmentos que quedan sin referencia. Un rechazo del agente se retorna
+// en la respuesta.
+func (s *AgentService) PruneSnapshots(ctx context.Context, agentID uuid.UUID, req *pb.PruneSnapshotsRequest) (*pb.PruneSnapshotsResponse, error) {
+	s.logger.Info("Pruning snapshots on agent",
+		zap.String("agent_id", agentID.String()),
+		zap.String("server_id", req.ServerId),
+		zap.Strings("snapshot_ids", req.SnapshotIds),
+	)
+
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, BackupOperationTimeout)
+	defer cancel()
+
+	resp, err := client.PruneSnapshots(timeoutCtx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune snapshots: %w", err)
+	}
+
+	return resp, nil
+}
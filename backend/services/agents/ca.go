@@ -0,0 +1,367 @@
+package agents
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+)
+
+const (
+	// caValidity validez de la CA interna
+	caValidity = 10 * 365 * 24 * time.Hour
+
+	// AgentCertValidity validez de los certificados emitidos a los agentes
+	AgentCertValidity = 90 * 24 * time.Hour
+
+	// CertRenewBefore margen antes de la expiración en el que se rota un
+	// certificado (de agente o el cliente del backend)
+	CertRenewBefore = 30 * 24 * time.Hour
+
+	// clientCertValidity validez del certificado cliente del backend
+	clientCertValidity = 365 * 24 * time.Hour
+
+	caCertFileName     = "ca.pem"
+	caKeyFileName      = "ca-key.pem"
+	clientCertFileName = "backend.pem"
+	clientKeyFileName  = "backend-key.pem"
+)
+
+// ErrCertificateRevoked se retorna al conectar con un agente cuyo
+// certificado fue revocado
+var ErrCertificateRevoked = errors.New("agent certificate revoked")
+
+// CertificateAuthority CA interna que firma los certificados de los agentes
+// y el certificado cliente con el que el backend se autentica ante ellos
+type CertificateAuthority struct {
+	dir     string
+	cert    *x509.Certificate
+	certPEM []byte
+	key     *ecdsa.PrivateKey
+	pool    *x509.CertPool
+	client  *tls.Certificate
+	mu      sync.RWMutex
+}
+
+// LoadOrCreateCA carga la CA desde dir o la crea si no existe
+func LoadOrCreateCA(dir string) (*CertificateAuthority, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create CA directory: %w", err)
+	}
+
+	ca := &CertificateAuthority{dir: dir}
+
+	certPEM, err := os.ReadFile(filepath.Join(dir, caCertFileName))
+	if os.IsNotExist(err) {
+		if err := ca.create(); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	} else if err := ca.load(certPEM); err != nil {
+		return nil, err
+	}
+
+	ca.pool = x509.NewCertPool()
+	ca.pool.AddCert(ca.cert)
+
+	if err := ca.ensureClientCertificate(); err != nil {
+		return nil, err
+	}
+
+	return ca, nil
+}
+
+// create genera la clave y el certificado raíz
+func (ca *CertificateAuthority) create() error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate CA key: %w", err)
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"AYMC"},
+			CommonName:   "AYMC Internal CA",
+		},
+		NotBefore:             now.Add(-5 * time.Minute),
+		NotAfter:              now.Add(caValidity),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		MaxPathLenZero:        true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM, err := encodeECKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(filepath.Join(ca.dir, caKeyFileName), keyPEM); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(ca.dir, caCertFileName), certPEM); err != nil {
+		return err
+	}
+
+	return ca.load(certPEM)
+}
+
+// load carga el certificado raíz y su clave
+func (ca *CertificateAuthority) load(certPEM []byte) error {
+	keyPEM, err := os.ReadFile(filepath.Join(ca.dir, caKeyFileName))
+	if err != nil {
+		return fmt.Errorf("failed to read CA key: %w", err)
+	}
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid CA key pair: %w", err)
+	}
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("unsupported CA key type")
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("invalid CA certificate: %w", err)
+	}
+
+	ca.cert = cert
+	ca.certPEM = certPEM
+	ca.key = key
+	return nil
+}
+
+// ensureClientCertificate carga el certificado cliente del backend y lo
+// renueva si falta o está por expirar
+func (ca *CertificateAuthority) ensureClientCertificate() error {
+	certPath := filepath.Join(ca.dir, clientCertFileName)
+	keyPath := filepath.Join(ca.dir, clientKeyFileName)
+
+	if pair, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		leaf, err := x509.ParseCertificate(pair.Certificate[0])
+		if err == nil && time.Until(leaf.NotAfter) > CertRenewBefore && leaf.CheckSignatureFrom(ca.cert) == nil {
+			ca.mu.Lock()
+			ca.client = &pair
+			ca.mu.Unlock()
+			return nil
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate backend key: %w", err)
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"AYMC"},
+			CommonName:   "AYMC Backend",
+		},
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(clientCertValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return fmt.Errorf("failed to create backend certificate: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM, err := encodeECKey(key)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(keyPath, keyPEM); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(certPath, certPEM); err != nil {
+		return err
+	}
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+
+	ca.mu.Lock()
+	ca.client = &pair
+	ca.mu.Unlock()
+	return nil
+}
+
+// CertPEM retorna el certificado raíz en PEM
+func (ca *CertificateAuthority) CertPEM() []byte {
+	return ca.certPEM
+}
+
+// SignAgentCSR emite el certificado de servidor de un agente a partir de
+// su CSR. Se agregan como SAN el hostname e IP registrados del agente.
+func (ca *CertificateAuthority) SignAgentCSR(csrPEM []byte, agent *models.Agent) (*x509.Certificate, []byte, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, nil, fmt.Errorf("invalid certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid certificate request: %w", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, nil, fmt.Errorf("invalid certificate request signature: %w", err)
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dnsNames := csr.DNSNames
+	if agent.Hostname != "" {
+		dnsNames = appendUnique(dnsNames, agent.Hostname)
+	}
+	var ips []net.IP
+	if ip := net.ParseIP(agent.IPAddress); ip != nil {
+		ips = append(ips, ip)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"AYMC Agent"},
+			CommonName:   agent.AgentID,
+		},
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(AgentCertValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:    dnsNames,
+		IPAddresses: ips,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, csr.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign agent certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+// ClientTLSConfig retorna la configuración TLS para conectar con un
+// agente. Con una huella fijada solo se acepta ese certificado, firmado por
+// la CA; sin huella (primer emparejamiento) se acepta cualquiera.
+func (ca *CertificateAuthority) ClientTLSConfig(pinnedFingerprint string) *tls.Config {
+	ca.mu.RLock()
+	client := ca.client
+	ca.mu.RUnlock()
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{*client},
+		MinVersion:   tls.VersionTLS13,
+		// Los agentes se identifican por la huella fijada, no por hostname
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if pinnedFingerprint == "" {
+				return nil
+			}
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("agent presented no certificate")
+			}
+			if CertificateFingerprint(rawCerts[0]) != pinnedFingerprint {
+				return fmt.Errorf("agent certificate does not match pinned fingerprint")
+			}
+
+			leaf, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			_, err = leaf.Verify(x509.VerifyOptions{
+				Roots:     ca.pool,
+				KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			})
+			return err
+		},
+	}
+}
+
+// CertificateFingerprint retorna el SHA256 (hex) de un certificado DER
+func CertificateFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// randomSerial genera un número de serie aleatorio de 128 bits
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serial, nil
+}
+
+// encodeECKey codifica una clave ECDSA en PEM
+func encodeECKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// writeFileAtomic escribe un archivo privado (0600) vía temporal y rename
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.WriteFile(path+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// appendUnique agrega value si no está en list
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,154 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	pb "github.com/aymc/backend/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// CertCheckInterval cada cuánto se buscan certificados por expirar
+const CertCheckInterval = 1 * time.Hour
+
+// issueCertificate pide un CSR al agente, lo firma con la CA, instala el
+// certificado en el agente y fija su huella en la base de datos
+func (r *AgentRegistry) issueCertificate(ctx context.Context, client pb.AgentServiceClient, agent *models.Agent, opts ...grpc.CallOption) error {
+	csrResp, err := client.CreateCertificateRequest(ctx, &pb.Empty{}, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to get certificate request: %w", err)
+	}
+
+	cert, certPEM, err := r.ca.SignAgentCSR(csrResp.Csr, agent)
+	if err != nil {
+		return err
+	}
+	fingerprint := CertificateFingerprint(cert.Raw)
+
+	resp, err := client.InstallCertificate(ctx, &pb.InstallCertificateRequest{
+		Certificate:   certPEM,
+		CaCertificate: r.ca.CertPEM(),
+	}, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to install certificate: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("agent rejected certificate: %s", resp.Message)
+	}
+	if resp.Fingerprint != fingerprint {
+		return fmt.Errorf("agent installed an unexpected certificate")
+	}
+
+	expiresAt := cert.NotAfter
+	if err := r.db.Model(&models.Agent{}).Where("id = ?", agent.ID).Updates(map[string]interface{}{
+		"cert_fingerprint": fingerprint,
+		"cert_serial":      cert.SerialNumber.Text(16),
+		"cert_expires_at":  expiresAt,
+		"cert_revoked_at":  nil,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to save agent certificate: %w", err)
+	}
+
+	agent.CertFingerprint = fingerprint
+	agent.CertSerial = cert.SerialNumber.Text(16)
+	agent.CertExpiresAt = &expiresAt
+	agent.CertRevokedAt = nil
+
+	r.logger.Info("Agent certificate issued",
+		zap.String("agent_id", agent.ID.String()),
+		zap.String("fingerprint", fingerprint),
+		zap.Time("expires_at", expiresAt),
+	)
+
+	return nil
+}
+
+// RotateCertificate emite un certificado nuevo a un agente conectado y
+// reconecta fijando la huella nueva
+func (r *AgentRegistry) RotateCertificate(ctx context.Context, agentID uuid.UUID) error {
+	conn, err := r.GetAgent(agentID)
+	if err != nil {
+		return err
+	}
+
+	// Copia para no modificar el agente de la conexión a medio rotar
+	agent := *conn.Agent
+
+	rotateCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := r.issueCertificate(rotateCtx, conn.GetClient(), &agent); err != nil {
+		return err
+	}
+
+	// En modo reverse no se puede volver a marcar: se cierra el túnel y el
+	// agente abre otro, que se verifica con la huella nueva
+	if agent.IsReverse() {
+		conn.mu.Lock()
+		conn.Agent = &agent
+		conn.mu.Unlock()
+		return conn.Disconnect()
+	}
+
+	return conn.Reconnect(ctx, &agent)
+}
+
+// RevokeCertificate revoca el certificado de un agente y lo desconecta.
+// El backend no vuelve a conectar hasta que el agente sea emparejado de
+// nuevo.
+func (r *AgentRegistry) RevokeCertificate(agentID uuid.UUID) error {
+	now := time.Now()
+	result := r.db.Model(&models.Agent{}).Where("id = ?", agentID).Update("cert_revoked_at", now)
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke agent certificate: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("agent not found")
+	}
+
+	r.logger.Warn("Agent certificate revoked", zap.String("agent_id", agentID.String()))
+
+	if conn, err := r.GetAgent(agentID); err == nil {
+		conn.mu.Lock()
+		conn.Agent.CertRevokedAt = &now
+		conn.mu.Unlock()
+
+		if err := r.Unregister(agentID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RotateExpiringCertificates rota los certificados que expiran dentro de
+// CertRenewBefore y emite uno a los agentes emparejados que aún no lo
+// tienen. También renueva el certificado cliente del backend.
+func (r *AgentRegistry) RotateExpiringCertificates(ctx context.Context) {
+	if err := r.ca.ensureClientCertificate(); err != nil {
+		r.logger.Error("Failed to renew backend client certificate", zap.Error(err))
+	}
+
+	for _, conn := range r.GetOnlineAgents() {
+		conn.mu.RLock()
+		agent := conn.Agent
+		due := agent.AuthToken != "" &&
+			(agent.CertExpiresAt == nil || time.Until(*agent.CertExpiresAt) < CertRenewBefore)
+		conn.mu.RUnlock()
+
+		if !due {
+			continue
+		}
+
+		if err := r.RotateCertificate(ctx, agent.ID); err != nil {
+			r.logger.Warn("Failed to rotate agent certificate",
+				zap.String("agent_id", agent.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
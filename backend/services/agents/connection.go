@@ -3,14 +3,16 @@ package agents
 import (
 	"context"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
 	"github.com/aymc/backend/database/models"
+	"github.com/aymc/backend/pkg/telemetry"
 	pb "github.com/aymc/backend/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
 )
 
 // AgentStatus representa el estado de conexión del agente
@@ -48,15 +50,18 @@ type AgentConnection struct {
 	status          AgentStatus
 	metrics         *AgentMetrics
 	consecutiveFails int
+	ca              *CertificateAuthority
+	tunnel          *tunnelDialer // Túnel abierto por el agente (modo reverse)
 	mu              sync.RWMutex
 	logger          *zap.Logger
 }
 
 // NewAgentConnection crea una nueva conexión al agente
-func NewAgentConnection(agent *models.Agent, logger *zap.Logger) *AgentConnection {
+func NewAgentConnection(agent *models.Agent, ca *CertificateAuthority, logger *zap.Logger) *AgentConnection {
 	return &AgentConnection{
 		ID:              agent.ID.String(),
 		Agent:           agent,
+		ca:              ca,
 		status:          AgentStatusOffline,
 		consecutiveFails: 0,
 		metrics:         &AgentMetrics{},
@@ -64,41 +69,76 @@ func NewAgentConnection(agent *models.Agent, logger *zap.Logger) *AgentConnectio
 	}
 }
 
-// Connect establece la conexión gRPC con el agente
+// Connect establece la conexión gRPC con el agente. La conexión nueva se
+// verifica antes de reemplazar la actual, así un intento fallido (p. ej. un
+// túnel abierto por alguien que no es el agente) no tumba una conexión sana.
 func (ac *AgentConnection) Connect(ctx context.Context) error {
 	ac.mu.Lock()
-	defer ac.mu.Unlock()
+	agent := ac.Agent
+
+	// Un certificado revocado solo se reemplaza emparejando de nuevo
+	if agent.CertRevokedAt != nil {
+		if ac.conn != nil {
+			ac.conn.Close()
+			ac.conn = nil
+			ac.Client = nil
+		}
+		ac.status = AgentStatusError
+		ac.mu.Unlock()
+		return ErrCertificateRevoked
+	}
 
-	if ac.conn != nil {
-		ac.logger.Warn("Agent already connected, closing existing connection")
-		ac.conn.Close()
+	// En modo reverse gRPC corre sobre el túnel que abrió el agente. Cada
+	// túnel sirve para una sola conexión; si se cae hay que esperar a que el
+	// agente abra otro
+	var tunnel *tunnelDialer
+	if agent.IsReverse() {
+		if ac.tunnel == nil {
+			if ac.conn == nil {
+				ac.status = AgentStatusOffline
+			}
+			ac.mu.Unlock()
+			return errNoTunnel
+		}
+		tunnel = ac.tunnel
+		ac.tunnel = nil
 	}
 
-	ac.status = AgentStatusConnecting
-	ac.logger.Info("Connecting to agent", zap.String("address", ac.Agent.GetAddress()))
+	if ac.conn == nil {
+		ac.status = AgentStatusConnecting
+	}
+	ac.mu.Unlock()
+
+	ac.logger.Info("Connecting to agent", zap.String("address", agent.GetAddress()))
 
 	// Crear conexión gRPC con timeout
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	// TODO: Agregar TLS en producción
-	conn, err := grpc.DialContext(
-		ctx,
-		ac.Agent.GetAddress(),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-	)
+	conn, err := ac.dial(ctx, agent, tunnel)
 	if err != nil {
-		ac.status = AgentStatusError
+		ac.mu.Lock()
+		if ac.conn == nil {
+			ac.status = AgentStatusError
+		}
+		ac.mu.Unlock()
 		ac.logger.Error("Failed to connect to agent", zap.Error(err))
 		return fmt.Errorf("failed to connect to agent: %w", err)
 	}
 
+	ac.mu.Lock()
+	previous := ac.conn
 	ac.conn = conn
 	ac.Client = pb.NewAgentServiceClient(conn)
 	ac.status = AgentStatusOnline
 	ac.lastSeen = time.Now()
 	ac.consecutiveFails = 0
+	ac.mu.Unlock()
+
+	if previous != nil {
+		ac.logger.Info("Replacing existing agent connection")
+		previous.Close()
+	}
 
 	ac.logger.Info("Successfully connected to agent")
 
@@ -111,6 +151,65 @@ func (ac *AgentConnection) Connect(ctx context.Context) error {
 	return nil
 }
 
+// dial abre una conexión gRPC nueva con el agente. Por un túnel exige además
+// un Ping correcto: el otro extremo superó el mTLS con la huella fijada y
+// acepta el token del agente.
+func (ac *AgentConnection) dial(ctx context.Context, agent *models.Agent, tunnel *tunnelDialer) (*grpc.ClientConn, error) {
+	// mTLS con el certificado cliente del backend; el del agente se
+	// verifica contra la huella fijada al emitirlo
+	if agent.CertFingerprint == "" {
+		ac.logger.Warn("Agent certificate not pinned yet, pair the agent to enable verification")
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(credentials.NewTLS(ac.ca.ClientTLSConfig(agent.CertFingerprint))),
+		grpc.WithBlock(),
+		grpc.WithUnaryInterceptor(telemetry.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(telemetry.StreamClientInterceptor()),
+	}
+
+	target := agent.GetAddress()
+	if tunnel != nil {
+		opts = append(opts, grpc.WithContextDialer(tunnel.dial))
+		target = "passthrough:///" + agent.AgentID
+	}
+
+	// Sin token solo funcionará Pair hasta que el agente sea emparejado
+	if agent.AuthToken != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{token: agent.AuthToken}))
+	}
+
+	conn, err := grpc.DialContext(ctx, target, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if tunnel != nil {
+		if _, err := pb.NewAgentServiceClient(conn).Ping(ctx, &pb.Empty{}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("tunnel verification failed: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// Reconnect reemplaza los datos del agente (token, certificado fijado) y
+// vuelve a conectar
+func (ac *AgentConnection) Reconnect(ctx context.Context, agent *models.Agent) error {
+	ac.mu.Lock()
+	ac.Agent = agent
+	ac.mu.Unlock()
+
+	return ac.Connect(ctx)
+}
+
+// setTunnel entrega un túnel nuevo para la próxima conexión
+func (ac *AgentConnection) setTunnel(conn net.Conn) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.tunnel = &tunnelDialer{conn: conn}
+}
+
 // Disconnect cierra la conexión con el agente
 func (ac *AgentConnection) Disconnect() error {
 	ac.mu.Lock()
@@ -242,7 +341,16 @@ func (ac *AgentConnection) UpdateMetrics(ctx context.Context) error {
 		return fmt.Errorf("failed to get metrics: %w", err)
 	}
 
+	ac.applyMetrics(metrics)
+
+	return nil
+}
+
+// applyMetrics actualiza las métricas almacenadas con las recibidas del agente
+func (ac *AgentConnection) applyMetrics(metrics *pb.SystemMetrics) {
 	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
 	ac.metrics.CPUPercent = metrics.CpuPercent
 	ac.metrics.MemoryTotal = metrics.MemoryTotal
 	ac.metrics.MemoryUsed = metrics.MemoryUsed
@@ -251,9 +359,25 @@ func (ac *AgentConnection) UpdateMetrics(ctx context.Context) error {
 	ac.metrics.DiskUsed = metrics.DiskUsed
 	ac.metrics.DiskPercent = metrics.DiskPercent
 	ac.metrics.LastUpdated = time.Now()
-	ac.mu.Unlock()
+}
 
-	return nil
+// markSeen registra actividad del agente (p. ej. un evento recibido por stream)
+func (ac *AgentConnection) markSeen() {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ac.lastSeen = time.Now()
+	ac.consecutiveFails = 0
+	if ac.status != AgentStatusOnline && ac.conn != nil {
+		ac.status = AgentStatusOnline
+	}
+}
+
+// GetClient retorna el cliente gRPC del agente (nil si no está conectado)
+func (ac *AgentConnection) GetClient() pb.AgentServiceClient {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return ac.Client
 }
 
 // updateAgentInfo obtiene información inicial del agente
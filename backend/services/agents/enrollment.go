@@ -0,0 +1,256 @@
+package agents
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultEnrollmentTokenTTL validez por defecto de un token de enrolamiento
+	DefaultEnrollmentTokenTTL = 24 * time.Hour
+
+	// MaxEnrollmentTokenTTL validez máxima de un token de enrolamiento
+	MaxEnrollmentTokenTTL = 30 * 24 * time.Hour
+
+	// enrollRegisterAttempts intentos de conectar con un agente recién
+	// enrolado (puede tardar unos segundos en instalar su certificado)
+	enrollRegisterAttempts = 5
+	enrollRegisterDelay    = 3 * time.Second
+)
+
+var (
+	// ErrInvalidEnrollmentToken token inexistente, usado o expirado
+	ErrInvalidEnrollmentToken = errors.New("invalid or expired enrollment token")
+
+	// ErrAgentAlreadyExists ya hay un agente registrado con ese agent_id
+	ErrAgentAlreadyExists = errors.New("agent already registered")
+)
+
+// EnrollmentRequest datos que envía un agente al canjear un token
+type EnrollmentRequest struct {
+	Token          string `json:"token" binding:"required"`
+	AgentID        string `json:"agent_id"`
+	Hostname       string `json:"hostname" binding:"required"`
+	IPAddress      string `json:"ip_address"`
+	Port           int    `json:"port"`
+	OS             string `json:"os"`
+	Version        string `json:"version"`
+	CPUCores       int    `json:"cpu_cores"`
+	MemoryTotal    int64  `json:"memory_total"`
+	DiskTotal      int64  `json:"disk_total"`
+	ConnectionMode string `json:"connection_mode" binding:"omitempty,oneof=direct reverse"`
+	CSR            string `json:"csr" binding:"required"` // PEM; la clave privada no sale del agente
+}
+
+// EnrollmentResult identidad y credenciales que recibe el agente
+type EnrollmentResult struct {
+	ID            uuid.UUID `json:"id"`
+	AgentID       string    `json:"agent_id"`
+	AuthToken     string    `json:"auth_token"`
+	Certificate   string    `json:"certificate"`
+	CACertificate string    `json:"ca_certificate"`
+}
+
+// hashEnrollmentToken retorna el hash con el que se guarda un token
+func hashEnrollmentToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateEnrollmentToken crea un token de un solo uso. El token en claro
+// solo se retorna aquí; en la base de datos se guarda su hash.
+func (s *AgentService) CreateEnrollmentToken(ctx context.Context, createdBy uuid.UUID, description string, ttl time.Duration) (*models.EnrollmentToken, string, error) {
+	if ttl <= 0 {
+		ttl = DefaultEnrollmentTokenTTL
+	}
+	if ttl > MaxEnrollmentTokenTTL {
+		return nil, "", fmt.Errorf("token lifetime cannot exceed %s", MaxEnrollmentTokenTTL)
+	}
+
+	token, err := generateAgentToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate enrollment token: %w", err)
+	}
+
+	record := &models.EnrollmentToken{
+		TokenHash:   hashEnrollmentToken(token),
+		TokenPrefix: token[:8],
+		Description: description,
+		CreatedBy:   createdBy,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	if err := s.registry.db.WithContext(ctx).Create(record).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to save enrollment token: %w", err)
+	}
+
+	s.logger.Info("Enrollment token created",
+		zap.String("token_id", record.ID.String()),
+		zap.String("created_by", createdBy.String()),
+		zap.Time("expires_at", record.ExpiresAt),
+	)
+
+	return record, token, nil
+}
+
+// ListEnrollmentTokens lista los tokens de enrolamiento
+func (s *AgentService) ListEnrollmentTokens(ctx context.Context) ([]models.EnrollmentToken, error) {
+	var tokens []models.EnrollmentToken
+	if err := s.registry.db.WithContext(ctx).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to list enrollment tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteEnrollmentToken revoca un token de enrolamiento
+func (s *AgentService) DeleteEnrollmentToken(ctx context.Context, tokenID uuid.UUID) error {
+	result := s.registry.db.WithContext(ctx).Delete(&models.EnrollmentToken{}, "id = ?", tokenID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete enrollment token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("enrollment token not found")
+	}
+	return nil
+}
+
+// EnrollAgent canjea un token de enrolamiento: crea el agente, emite su
+// token gRPC y su certificado, y lo registra en cuanto acepte conexiones
+func (s *AgentService) EnrollAgent(ctx context.Context, req *EnrollmentRequest) (*EnrollmentResult, error) {
+	var record models.EnrollmentToken
+	err := s.registry.db.WithContext(ctx).Where("token_hash = ?", hashEnrollmentToken(req.Token)).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !record.IsUsable()) {
+		return nil, ErrInvalidEnrollmentToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up enrollment token: %w", err)
+	}
+
+	agentID := req.AgentID
+	if agentID == "" {
+		agentID = fmt.Sprintf("agent-%s", uuid.New().String()[:8])
+	}
+	port := req.Port
+	if port == 0 {
+		port = 50051
+	}
+
+	authToken, err := generateAgentToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate agent token: %w", err)
+	}
+
+	agent := &models.Agent{
+		ID:             uuid.New(),
+		AgentID:        agentID,
+		Hostname:       req.Hostname,
+		IPAddress:      req.IPAddress,
+		Port:           port,
+		Status:         models.AgentStatusOffline,
+		Version:        req.Version,
+		OS:             req.OS,
+		CPUCores:       req.CPUCores,
+		MemoryTotal:    req.MemoryTotal,
+		DiskTotal:      req.DiskTotal,
+		AuthToken:      authToken,
+		ConnectionMode: models.ConnectionModeDirect,
+	}
+	if req.ConnectionMode == string(models.ConnectionModeReverse) {
+		agent.ConnectionMode = models.ConnectionModeReverse
+	}
+
+	cert, certPEM, err := s.registry.ca.SignAgentCSR([]byte(req.CSR), agent)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := cert.NotAfter
+	agent.CertFingerprint = CertificateFingerprint(cert.Raw)
+	agent.CertSerial = cert.SerialNumber.Text(16)
+	agent.CertExpiresAt = &expiresAt
+
+	// Consumir el token y crear el agente de forma atómica: si el agente ya
+	// existe el token sigue disponible
+	err = s.registry.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.Agent{}).Where("agent_id = ?", agentID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAgentAlreadyExists
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.EnrollmentToken{}).
+			Where("id = ? AND used_at IS NULL", record.ID).
+			Updates(map[string]interface{}{"used_at": now, "agent_id": agent.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidEnrollmentToken
+		}
+
+		return tx.Create(agent).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrAgentAlreadyExists) || errors.Is(err, ErrInvalidEnrollmentToken) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+
+	s.logger.Info("Agent enrolled",
+		zap.String("agent_id", agent.ID.String()),
+		zap.String("remote_agent_id", agent.AgentID),
+		zap.String("hostname", agent.Hostname),
+		zap.String("enrollment_token", record.ID.String()),
+	)
+
+	// Los agentes en modo reverse se registran al abrir su túnel
+	if !agent.IsReverse() {
+		go s.registerEnrolledAgent(agent)
+	}
+
+	return &EnrollmentResult{
+		ID:            agent.ID,
+		AgentID:       agent.AgentID,
+		AuthToken:     authToken,
+		Certificate:   string(certPEM),
+		CACertificate: string(s.registry.ca.CertPEM()),
+	}, nil
+}
+
+// registerEnrolledAgent conecta con un agente recién enrolado. El agente
+// instala sus credenciales después de recibir la respuesta, por lo que los
+// primeros intentos pueden fallar.
+func (s *AgentService) registerEnrolledAgent(agent *models.Agent) {
+	for attempt := 1; attempt <= enrollRegisterAttempts; attempt++ {
+		time.Sleep(enrollRegisterDelay)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		_, err := s.registry.Register(ctx, agent)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		s.logger.Debug("Enrolled agent not reachable yet",
+			zap.String("agent_id", agent.ID.String()),
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+	}
+
+	s.logger.Warn("Could not connect to enrolled agent, it will be retried on next startup",
+		zap.String("agent_id", agent.ID.String()),
+	)
+}
@@ -0,0 +1,424 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aymc/backend/api/websocket"
+	"github.com/aymc/backend/database"
+	"github.com/aymc/backend/database/models"
+	pb "github.com/aymc/backend/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultEventStreamSyncInterval es cada cuánto se revisan los agentes sin stream activo
+	DefaultEventStreamSyncInterval = 10 * time.Second
+
+	// EventStreamHeartbeatInterval es el intervalo de heartbeats enviados al agente
+	EventStreamHeartbeatInterval = 30 * time.Second
+)
+
+// ServerEventHandler recibe los eventos de servidores (crashed,
+// restart_attempt, ...) que llegan por los streams de los agentes
+type ServerEventHandler func(agentID, serverID uuid.UUID, eventType string, timestamp time.Time)
+
+// EventStreamManager mantiene un EventStream por agente conectado y
+// reenvía los eventos recibidos al Hub de WebSocket
+type EventStreamManager struct {
+	registry *AgentRegistry
+	hub      *websocket.Hub
+	interval time.Duration
+	streams  map[uuid.UUID]context.CancelFunc
+	mu       sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	logger   *zap.Logger
+
+	onServerEvent ServerEventHandler
+}
+
+// NewEventStreamManager crea un nuevo gestor de streams de eventos
+func NewEventStreamManager(registry *AgentRegistry, hub *websocket.Hub, logger *zap.Logger) *EventStreamManager {
+	return &EventStreamManager{
+		registry: registry,
+		hub:      hub,
+		interval: DefaultEventStreamSyncInterval,
+		streams:  make(map[uuid.UUID]context.CancelFunc),
+		logger:   logger.With(zap.String("component", "event_streams")),
+	}
+}
+
+// SetServerEventHandler registra una función que recibe cada evento de
+// servidor. Debe llamarse antes de Start.
+func (m *EventStreamManager) SetServerEventHandler(handler ServerEventHandler) {
+	m.onServerEvent = handler
+}
+
+// Start inicia el gestor de streams
+func (m *EventStreamManager) Start() error {
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+
+	m.logger.Info("Starting agent event streams", zap.Duration("sync_interval", m.interval))
+
+	m.wg.Add(1)
+	go m.syncLoop()
+
+	return nil
+}
+
+// Stop cierra todos los streams activos
+func (m *EventStreamManager) Stop() {
+	m.logger.Info("Stopping agent event streams")
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+
+	m.wg.Wait()
+
+	m.logger.Info("Agent event streams stopped")
+}
+
+// ActiveStreams retorna el número de streams abiertos
+func (m *EventStreamManager) ActiveStreams() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.streams)
+}
+
+// syncLoop abre streams para los agentes saludables que no tengan uno
+func (m *EventStreamManager) syncLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.syncStreams()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.syncStreams()
+		}
+	}
+}
+
+// syncStreams revisa el registry y abre los streams que falten
+func (m *EventStreamManager) syncStreams() {
+	for _, conn := range m.registry.ListAgents() {
+		if !conn.IsHealthy() || conn.GetClient() == nil {
+			continue
+		}
+
+		agentID := conn.Agent.ID
+
+		m.mu.Lock()
+		if _, exists := m.streams[agentID]; exists {
+			m.mu.Unlock()
+			continue
+		}
+		ctx, cancel := context.WithCancel(m.ctx)
+		m.streams[agentID] = cancel
+		m.mu.Unlock()
+
+		m.wg.Add(1)
+		go m.runStream(ctx, conn)
+	}
+}
+
+// runStream consume el EventStream de un agente hasta que se cierra.
+// Al terminar se elimina del mapa para que syncStreams lo reabra.
+func (m *EventStreamManager) runStream(ctx context.Context, conn *AgentConnection) {
+	defer m.wg.Done()
+
+	agentID := conn.Agent.ID
+	logger := m.logger.With(zap.String("agent_id", agentID.String()))
+
+	defer func() {
+		m.mu.Lock()
+		if cancel, exists := m.streams[agentID]; exists {
+			cancel()
+			delete(m.streams, agentID)
+		}
+		m.mu.Unlock()
+	}()
+
+	client := conn.GetClient()
+	if client == nil {
+		return
+	}
+
+	stream, err := client.EventStream(ctx)
+	if err != nil {
+		logger.Warn("Failed to open event stream", zap.Error(err))
+		return
+	}
+
+	// Petición inicial: todos los tipos de evento
+	if err := stream.Send(&pb.EventStreamRequest{Timestamp: time.Now().Unix()}); err != nil {
+		logger.Warn("Failed to send initial event stream request", zap.Error(err))
+		return
+	}
+
+	logger.Info("Agent event stream opened")
+
+	// Heartbeats periódicos para detectar conexiones muertas
+	go func() {
+		ticker := time.NewTicker(EventStreamHeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				req := &pb.EventStreamRequest{Timestamp: time.Now().Unix(), Ping: true}
+				if err := stream.Send(req); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				logger.Info("Agent event stream closed")
+			} else {
+				logger.Warn("Agent event stream interrupted", zap.Error(err))
+			}
+			return
+		}
+
+		conn.markSeen()
+		m.handleEvent(conn, event)
+	}
+}
+
+// handleEvent despacha un evento del agente según su tipo
+func (m *EventStreamManager) handleEvent(conn *AgentConnection, event *pb.AgentEvent) {
+	switch payload := event.Payload.(type) {
+	case *pb.AgentEvent_Metrics:
+		m.handleMetrics(conn, payload.Metrics)
+	case *pb.AgentEvent_ServerEvent:
+		m.handleServerEvent(conn, payload.ServerEvent)
+	case *pb.AgentEvent_LogAlert:
+		m.handleLogAlert(conn, payload.LogAlert)
+	case *pb.AgentEvent_Pong:
+		// markSeen ya registró la actividad
+	}
+}
+
+// handleMetrics actualiza las métricas del agente y las publica
+func (m *EventStreamManager) handleMetrics(conn *AgentConnection, metrics *pb.SystemMetrics) {
+	conn.applyMetrics(metrics)
+
+	m.hub.BroadcastAgentMetrics(conn.Agent.ID, websocket.AgentMetrics{
+		AgentID:       conn.Agent.ID,
+		Timestamp:     time.Unix(metrics.Timestamp, 0),
+		CPUPercent:    metrics.CpuPercent,
+		MemoryUsed:    metrics.MemoryUsed,
+		MemoryTotal:   metrics.MemoryTotal,
+		MemoryPercent: metrics.MemoryPercent,
+		DiskUsed:      metrics.DiskUsed,
+		DiskTotal:     metrics.DiskTotal,
+		DiskPercent:   metrics.DiskPercent,
+		NetworkSent:   metrics.NetworkSent,
+		NetworkRecv:   metrics.NetworkRecv,
+	})
+}
+
+// handleServerEvent sincroniza el estado del servidor en la base de datos
+// y notifica el cambio a los clientes suscritos
+func (m *EventStreamManager) handleServerEvent(conn *AgentConnection, event *pb.ServerEvent) {
+	server, ok := m.hostedServer(conn, event.ServerId, "server event")
+	if !ok {
+		return
+	}
+	serverID := server.ID
+
+	newStatus := mapAgentServerStatus(event.Status)
+	timestamp := time.Unix(event.Timestamp, 0)
+	db := database.GetDB()
+
+	oldStatus := server.Status
+	if newStatus != "" && newStatus != oldStatus {
+		if err := db.Model(server).Update("status", newStatus).Error; err != nil {
+			m.logger.Error("Failed to update server status from agent event",
+				zap.String("server_id", serverID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	m.logger.Info("Server event received",
+		zap.String("agent_id", conn.Agent.ID.String()),
+		zap.String("server_id", serverID.String()),
+		zap.String("type", event.Type),
+		zap.String("status", event.Status),
+		zap.String("reason", event.Reason),
+	)
+
+	if m.onServerEvent != nil {
+		m.onServerEvent(conn.Agent.ID, serverID, event.Type, timestamp)
+	}
+
+	m.hub.BroadcastServerStatus(serverID, websocket.ServerStatusChange{
+		ServerID:   serverID,
+		ServerName: server.Name,
+		OldStatus:  string(oldStatus),
+		NewStatus:  string(newStatus),
+		Timestamp:  timestamp,
+		Reason:     describeServerEvent(event),
+	})
+
+	// Crashes y reinicios automáticos también se notifican como alertas
+	severity := ""
+	switch event.Type {
+	case "crashed", "restart_failed":
+		severity = "error"
+	case "restart_limit":
+		severity = "critical"
+	case "restart_attempt":
+		severity = "warning"
+	default:
+		return
+	}
+
+	m.hub.BroadcastAlert(websocket.Alert{
+		ID:        uuid.New(),
+		Severity:  severity,
+		Title:     fmt.Sprintf("Servidor %s: %s", server.Name, event.Type),
+		Message:   describeServerEvent(event),
+		Source:    "server",
+		SourceID:  serverID,
+		Timestamp: timestamp,
+		Data: map[string]interface{}{
+			"agent_id":     conn.Agent.ID.String(),
+			"event_type":   event.Type,
+			"exit_code":    event.ExitCode,
+			"attempt":      event.Attempt,
+			"max_attempts": event.MaxAttempts,
+			"backoff_ms":   event.BackoffMs,
+		},
+	})
+}
+
+// handleLogAlert publica una alerta detectada en los logs de un servidor
+func (m *EventStreamManager) handleLogAlert(conn *AgentConnection, alert *pb.LogAlert) {
+	server, ok := m.hostedServer(conn, alert.ServerId, "log alert")
+	if !ok {
+		return
+	}
+	serverID := server.ID
+
+	title := alert.ErrorType
+	if title == "" {
+		title = "Error en logs del servidor"
+	}
+
+	m.hub.BroadcastAlert(websocket.Alert{
+		ID:        uuid.New(),
+		Severity:  mapLogSeverity(alert.Severity),
+		Title:     title,
+		Message:   alert.Message,
+		Source:    "server",
+		SourceID:  serverID,
+		Timestamp: time.Unix(alert.Timestamp, 0),
+		Data: map[string]interface{}{
+			"agent_id":   conn.Agent.ID.String(),
+			"level":      alert.Level,
+			"error_type": alert.ErrorType,
+			"suggestion": alert.Suggestion,
+			"plugin":     alert.Plugin,
+		},
+	})
+}
+
+// hostedServer carga el servidor de un evento y comprueba que lo aloja el
+// agente que lo envía. Un agente no puede cambiar el estado ni generar
+// alertas de servidores ajenos; esos eventos se descartan.
+func (m *EventStreamManager) hostedServer(conn *AgentConnection, rawServerID, kind string) (*models.Server, bool) {
+	serverID, err := uuid.Parse(rawServerID)
+	if err != nil {
+		m.logger.Warn("Agent "+kind+" with invalid server ID",
+			zap.String("agent_id", conn.Agent.ID.String()),
+			zap.String("server_id", rawServerID),
+		)
+		return nil, false
+	}
+
+	var server models.Server
+	if err := database.GetDB().Select("id", "name", "status", "agent_id").First(&server, "id = ?", serverID).Error; err != nil {
+		m.logger.Warn("Agent "+kind+" for unknown server",
+			zap.String("agent_id", conn.Agent.ID.String()),
+			zap.String("server_id", serverID.String()),
+			zap.Error(err),
+		)
+		return nil, false
+	}
+
+	if server.AgentID != conn.Agent.ID {
+		m.logger.Warn("Dropping agent "+kind+" for a server hosted by another agent",
+			zap.String("agent_id", conn.Agent.ID.String()),
+			zap.String("server_id", serverID.String()),
+			zap.String("server_agent_id", server.AgentID.String()),
+		)
+		return nil, false
+	}
+
+	return &server, true
+}
+
+// mapAgentServerStatus traduce el estado del agente al estado del modelo
+func mapAgentServerStatus(status string) models.ServerStatus {
+	switch status {
+	case "running":
+		return models.ServerStatusRunning
+	case "stopped":
+		return models.ServerStatusStopped
+	case "starting":
+		return models.ServerStatusStarting
+	case "stopping":
+		return models.ServerStatusStopping
+	case "crashed":
+		return models.ServerStatusError
+	default:
+		return ""
+	}
+}
+
+// mapLogSeverity traduce la severidad numérica del agente (1-5)
+func mapLogSeverity(severity int32) string {
+	switch {
+	case severity >= 5:
+		return "critical"
+	case severity == 4:
+		return "error"
+	case severity == 3:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// describeServerEvent genera una descripción legible de un evento
+func describeServerEvent(event *pb.ServerEvent) string {
+	switch event.Type {
+	case "restart_attempt":
+		return fmt.Sprintf("Reinicio automático %d/%d en %s: %s",
+			event.Attempt, event.MaxAttempts,
+			time.Duration(event.BackoffMs)*time.Millisecond, event.Reason)
+	default:
+		return event.Reason
+	}
+}
@@ -0,0 +1,97 @@
+package agents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aymc/backend/api/websocket"
+	"github.com/aymc/backend/database"
+	"github.com/aymc/backend/database/models"
+	pb "github.com/aymc/backend/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// useEventsTestDB sustituye la base de datos global por SQLite en memoria
+// con un servidor alojado en hostAgent
+func useEventsTestDB(t *testing.T, hostAgent uuid.UUID) uuid.UUID {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec(`CREATE TABLE servers (id TEXT PRIMARY KEY, agent_id TEXT, name TEXT, status TEXT, updated_at DATETIME)`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	serverID := uuid.New()
+	db.Exec(`INSERT INTO servers (id, agent_id, name, status) VALUES (?, ?, ?, ?)`, serverID, hostAgent, "survival", models.ServerStatusRunning)
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+
+	return serverID
+}
+
+func serverStatus(t *testing.T, serverID uuid.UUID) models.ServerStatus {
+	t.Helper()
+
+	var server models.Server
+	if err := database.DB.Select("status").First(&server, "id = ?", serverID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return server.Status
+}
+
+func TestEventsFromForeignAgentAreDropped(t *testing.T) {
+	host, intruder := uuid.New(), uuid.New()
+	serverID := useEventsTestDB(t, host)
+
+	// Sin hub: publicar un evento ajeno haría fallar el test
+	manager := NewEventStreamManager(nil, nil, zap.NewNop())
+	handled := 0
+	manager.SetServerEventHandler(func(agentID, serverID uuid.UUID, eventType string, timestamp time.Time) {
+		handled++
+	})
+
+	crash := &pb.AgentEvent{Payload: &pb.AgentEvent_ServerEvent{ServerEvent: &pb.ServerEvent{
+		ServerId:  serverID.String(),
+		Type:      "crashed",
+		Status:    "crashed",
+		Timestamp: time.Now().Unix(),
+	}}}
+	logAlert := &pb.AgentEvent{Payload: &pb.AgentEvent_LogAlert{LogAlert: &pb.LogAlert{
+		ServerId: serverID.String(),
+		Message:  "java.lang.OutOfMemoryError",
+		Severity: 5,
+	}}}
+
+	// Un agente que no aloja el servidor no cambia nada
+	foreign := &AgentConnection{Agent: &models.Agent{ID: intruder}}
+	manager.handleEvent(foreign, crash)
+	manager.handleEvent(foreign, logAlert)
+
+	if status := serverStatus(t, serverID); status != models.ServerStatusRunning {
+		t.Errorf("status = %s after a foreign event, want running", status)
+	}
+	if handled != 0 {
+		t.Errorf("server event handler called %d times for a foreign agent", handled)
+	}
+
+	// El agente que lo aloja sí
+	manager.hub = websocket.NewHub(zap.NewNop())
+	owner := &AgentConnection{Agent: &models.Agent{ID: host}}
+	manager.handleEvent(owner, crash)
+	manager.handleEvent(owner, logAlert)
+
+	if status := serverStatus(t, serverID); status != models.ServerStatusError {
+		t.Errorf("status = %s after the host agent's crash event, want error", status)
+	}
+	if handled != 1 {
+		t.Errorf("server event handler called %d times, want 1", handled)
+	}
+}
@@ -0,0 +1,154 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/aymc/backend/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// fileOperationTimeout tiempo máximo para operaciones simples de archivos
+	fileOperationTimeout = 30 * time.Second
+
+	// fileArchiveTimeout tiempo máximo para copiar, comprimir o extraer; un
+	// mundo grande puede tardar varios minutos
+	fileArchiveTimeout = 30 * time.Minute
+)
+
+// Las RPC de archivos retornan errores gRPC con código (NotFound,
+// PermissionDenied, AlreadyExists, InvalidArgument...) envueltos con %w, así
+// que status.Code(err) funciona sobre los errores de estos métodos.
+
+// ListFiles lista un directorio del sandbox de un servidor
+func (s *AgentService) ListFiles(ctx context.Context, agentID uuid.UUID, req *pb.DirectoryRequest) (*pb.FileList, error) {
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, fileOperationTimeout)
+	defer cancel()
+
+	resp, err := client.ListFiles(timeoutCtx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return resp, nil
+}
+
+// ReadFile lee un archivo del sandbox de un servidor
+func (s *AgentService) ReadFile(ctx context.Context, agentID uuid.UUID, req *pb.FileRequest) (*pb.FileContent, error) {
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, fileOperationTimeout)
+	defer cancel()
+
+	resp, err := client.ReadFile(timeoutCtx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return resp, nil
+}
+
+// WriteFile escribe un archivo en el sandbox de un servidor. WriteFile
+// mantiene el contrato antiguo (Success=false en lugar de error gRPC); el
+// rechazo se retorna como FailedPrecondition con el mensaje del agente.
+func (s *AgentService) WriteFile(ctx context.Context, agentID uuid.UUID, req *pb.WriteFileRequest) error {
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, fileOperationTimeout)
+	defer cancel()
+
+	resp, err := client.WriteFile(timeoutCtx, req)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("agent rejected write: %w", status.Error(codes.FailedPrecondition, resp.Message))
+	}
+	return nil
+}
+
+// DeleteFile borra un archivo o directorio
+func (s *AgentService) DeleteFile(ctx context.Context, agentID uuid.UUID, req *pb.DeleteFileRequest) (*pb.FileResponse, error) {
+	return s.fileOperation(ctx, agentID, fileOperationTimeout, "delete file", func(ctx context.Context, client pb.AgentServiceClient) (*pb.FileResponse, error) {
+		return client.DeleteFile(ctx, req)
+	})
+}
+
+// MoveFile mueve o renombra un archivo o directorio
+func (s *AgentService) MoveFile(ctx context.Context, agentID uuid.UUID, req *pb.MoveFileRequest) (*pb.FileResponse, error) {
+	return s.fileOperation(ctx, agentID, fileOperationTimeout, "move file", func(ctx context.Context, client pb.AgentServiceClient) (*pb.FileResponse, error) {
+		return client.MoveFile(ctx, req)
+	})
+}
+
+// CopyFile copia un archivo o directorio
+func (s *AgentService) CopyFile(ctx context.Context, agentID uuid.UUID, req *pb.MoveFileRequest) (*pb.FileResponse, error) {
+	return s.fileOperation(ctx, agentID, fileArchiveTimeout, "copy file", func(ctx context.Context, client pb.AgentServiceClient) (*pb.FileResponse, error) {
+		return client.CopyFile(ctx, req)
+	})
+}
+
+// CreateDirectory crea un directorio
+func (s *AgentService) CreateDirectory(ctx context.Context, agentID uuid.UUID, req *pb.CreateDirectoryRequest) (*pb.FileResponse, error) {
+	return s.fileOperation(ctx, agentID, fileOperationTimeout, "create directory", func(ctx context.Context, client pb.AgentServiceClient) (*pb.FileResponse, error) {
+		return client.CreateDirectory(ctx, req)
+	})
+}
+
+// ChangePermissions cambia los permisos unix de un archivo o directorio
+func (s *AgentService) ChangePermissions(ctx context.Context, agentID uuid.UUID, req *pb.ChangePermissionsRequest) (*pb.FileResponse, error) {
+	return s.fileOperation(ctx, agentID, fileOperationTimeout, "change permissions", func(ctx context.Context, client pb.AgentServiceClient) (*pb.FileResponse, error) {
+		return client.ChangePermissions(ctx, req)
+	})
+}
+
+// CompressFiles comprime una selección en zip o tar.gz
+func (s *AgentService) CompressFiles(ctx context.Context, agentID uuid.UUID, req *pb.CompressFilesRequest) (*pb.FileResponse, error) {
+	return s.fileOperation(ctx, agentID, fileArchiveTimeout, "compress files", func(ctx context.Context, client pb.AgentServiceClient) (*pb.FileResponse, error) {
+		return client.CompressFiles(ctx, req)
+	})
+}
+
+// ExtractArchive extrae un archivo comprimido en el sandbox
+func (s *AgentService) ExtractArchive(ctx context.Context, agentID uuid.UUID, req *pb.ExtractArchiveRequest) (*pb.FileResponse, error) {
+	return s.fileOperation(ctx, agentID, fileArchiveTimeout, "extract archive", func(ctx context.Context, client pb.AgentServiceClient) (*pb.FileResponse, error) {
+		return client.ExtractArchive(ctx, req)
+	})
+}
+
+// fileOperation ejecuta una RPC del gestor de archivos con su timeout
+func (s *AgentService) fileOperation(ctx context.Context, agentID uuid.UUID, timeout time.Duration, op string,
+	call func(context.Context, pb.AgentServiceClient) (*pb.FileResponse, error)) (*pb.FileResponse, error) {
+	s.logger.Info("File operation on agent",
+		zap.String("agent_id", agentID.String()),
+		zap.String("operation", op),
+	)
+
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := call(timeoutCtx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", op, err)
+	}
+	return resp, nil
+}
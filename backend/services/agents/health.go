@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/aymc/backend/database/models"
+	"github.com/aymc/backend/pkg/telemetry"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -79,6 +80,9 @@ func (hm *HealthMonitor) monitorLoop() {
 	ticker := time.NewTicker(hm.interval)
 	defer ticker.Stop()
 
+	certTicker := time.NewTicker(CertCheckInterval)
+	defer certTicker.Stop()
+
 	// Realizar check inicial inmediatamente
 	hm.checkAllAgents()
 
@@ -89,6 +93,8 @@ func (hm *HealthMonitor) monitorLoop() {
 			return
 		case <-ticker.C:
 			hm.checkAllAgents()
+		case <-certTicker.C:
+			hm.registry.RotateExpiringCertificates(hm.ctx)
 		}
 	}
 }
@@ -135,6 +141,7 @@ func (hm *HealthMonitor) checkAgent(conn *AgentConnection) {
 	// Realizar ping
 	err := conn.Ping(ctx)
 	if err != nil {
+		telemetry.AgentHealthChecks.WithLabelValues("failure").Inc()
 		hm.logger.Warn("Agent ping failed",
 			zap.String("agent_id", agentID),
 			zap.Int("consecutive_fails", conn.GetConsecutiveFails()),
@@ -149,6 +156,8 @@ func (hm *HealthMonitor) checkAgent(conn *AgentConnection) {
 		return
 	}
 
+	telemetry.AgentHealthChecks.WithLabelValues("success").Inc()
+
 	// Ping exitoso, actualizar métricas
 	if err := conn.UpdateMetrics(ctx); err != nil {
 		hm.logger.Warn("Failed to update agent metrics",
@@ -254,6 +263,35 @@ func (hm *HealthMonitor) GetStats() map[string]interface{} {
 	}
 }
 
+// AgentHealth estado de salud de un agente según el health monitor
+type AgentHealth struct {
+	AgentID          uuid.UUID
+	Hostname         string
+	Status           AgentStatus
+	Healthy          bool
+	ConsecutiveFails int
+	LastSeen         time.Time
+	Metrics          *AgentMetrics
+}
+
+// AgentHealth retorna el estado de salud de cada agente registrado
+func (hm *HealthMonitor) AgentHealth() []AgentHealth {
+	conns := hm.registry.ListAgents()
+	health := make([]AgentHealth, 0, len(conns))
+	for _, conn := range conns {
+		health = append(health, AgentHealth{
+			AgentID:          conn.Agent.ID,
+			Hostname:         conn.Agent.Hostname,
+			Status:           conn.GetStatus(),
+			Healthy:          conn.IsHealthy(),
+			ConsecutiveFails: conn.GetConsecutiveFails(),
+			LastSeen:         conn.GetLastSeen(),
+			Metrics:          conn.GetMetrics(),
+		})
+	}
+	return health
+}
+
 // parseUUID es una función helper para parsear UUIDs
 func parseUUID(id string) (uuid.UUID, error) {
 	return uuid.Parse(id)
@@ -0,0 +1,229 @@
+package agents
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aymc/backend/api/websocket"
+	"github.com/aymc/backend/database"
+	"github.com/aymc/backend/database/models"
+	pb "github.com/aymc/backend/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// LogRelayMinBackoff es la espera inicial antes de reconectar un stream de logs
+	LogRelayMinBackoff = 1 * time.Second
+
+	// LogRelayMaxBackoff es la espera máxima entre reconexiones
+	LogRelayMaxBackoff = 30 * time.Second
+
+	// LogRelayTailLines son las líneas de historial pedidas al abrir un relay
+	LogRelayTailLines = 200
+)
+
+// LogRelay conecta los streams de logs de los agentes con los canales
+// server:<id>:logs del Hub. Abre un único stream por servidor cuando el
+// primer cliente se suscribe y lo cierra cuando se va el último.
+type LogRelay struct {
+	agentService *AgentService
+	hub          *websocket.Hub
+	relays       map[uuid.UUID]context.CancelFunc
+	mu           sync.Mutex
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	logger       *zap.Logger
+}
+
+// NewLogRelay crea un nuevo relay de logs
+func NewLogRelay(agentService *AgentService, hub *websocket.Hub, logger *zap.Logger) *LogRelay {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &LogRelay{
+		agentService: agentService,
+		hub:          hub,
+		relays:       make(map[uuid.UUID]context.CancelFunc),
+		ctx:          ctx,
+		cancel:       cancel,
+		logger:       logger.With(zap.String("component", "log_relay")),
+	}
+}
+
+// Start registra el relay como observador de canales del Hub
+func (r *LogRelay) Start() {
+	r.hub.AddChannelObserver(r)
+	r.logger.Info("Log relay started")
+}
+
+// Stop cierra todos los streams de logs activos
+func (r *LogRelay) Stop() {
+	r.logger.Info("Stopping log relay")
+
+	r.cancel()
+
+	r.mu.Lock()
+	for serverID, cancel := range r.relays {
+		cancel()
+		delete(r.relays, serverID)
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+
+	r.logger.Info("Log relay stopped")
+}
+
+// ActiveRelays retorna el número de servidores con stream activo
+func (r *LogRelay) ActiveRelays() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.relays)
+}
+
+// ChannelActivated abre el stream cuando un canal de logs gana su primer
+// suscriptor. Las notificaciones del Hub pueden llegar desordenadas, así que
+// tanto la apertura como el cierre se deciden según los suscriptores
+// actuales, consultados bajo r.mu.
+func (r *LogRelay) ChannelActivated(channel string) {
+	serverID, channelType, ok := websocket.ParseServerChannel(channel)
+	if !ok || channelType != websocket.ChannelTypeLogs {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ctx.Err() != nil {
+		return
+	}
+	if _, exists := r.relays[serverID]; exists {
+		return
+	}
+	if r.hub.SubscriberCount(channel) == 0 {
+		return // el último suscriptor ya se fue
+	}
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	r.relays[serverID] = cancel
+
+	r.wg.Add(1)
+	go r.run(ctx, serverID)
+}
+
+// ChannelDeactivated cierra el stream cuando el canal se queda sin suscriptores
+func (r *LogRelay) ChannelDeactivated(channel string) {
+	serverID, channelType, ok := websocket.ParseServerChannel(channel)
+	if !ok || channelType != websocket.ChannelTypeLogs {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.hub.SubscriberCount(channel) > 0 {
+		return // alguien volvió a suscribirse antes de esta notificación
+	}
+	if cancel, exists := r.relays[serverID]; exists {
+		cancel()
+		delete(r.relays, serverID)
+	}
+}
+
+// run mantiene el stream de un servidor, reconectando con backoff
+// exponencial si el agente se cae o el stream se interrumpe
+func (r *LogRelay) run(ctx context.Context, serverID uuid.UUID) {
+	defer r.wg.Done()
+
+	logger := r.logger.With(zap.String("server_id", serverID.String()))
+	logger.Info("Log relay opened")
+	defer logger.Info("Log relay closed")
+
+	backoff := LogRelayMinBackoff
+
+	// Última secuencia reenviada: al reconectar se pide solo lo que falta.
+	// El límite de líneas acota la repetición si el agente se reinició y
+	// su numeración empezó de nuevo.
+	var lastSeq uint64
+
+	for ctx.Err() == nil {
+		received := false
+
+		opts := LogStreamOptions{
+			TailLines:     LogRelayTailLines,
+			AfterSequence: lastSeq,
+		}
+
+		agentID, err := r.lookupAgent(serverID)
+		if err == nil {
+			err = r.agentService.StreamLogs(ctx, serverID, agentID, opts, func(id uuid.UUID, entry *pb.LogEntry) {
+				received = true
+				if entry.Sequence > 0 {
+					lastSeq = entry.Sequence
+				}
+				r.hub.BroadcastServerLogs(id, convertLogEntry(id, entry))
+			})
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Si el stream llegó a entregar logs, reiniciar el backoff
+		if received {
+			backoff = LogRelayMinBackoff
+		}
+
+		if err != nil {
+			logger.Debug("Log stream unavailable, retrying",
+				zap.Duration("backoff", backoff),
+				zap.Error(err),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > LogRelayMaxBackoff {
+			backoff = LogRelayMaxBackoff
+		}
+	}
+}
+
+// lookupAgent obtiene el agente que ejecuta un servidor
+func (r *LogRelay) lookupAgent(serverID uuid.UUID) (uuid.UUID, error) {
+	var server models.Server
+	if err := database.GetDB().Select("id", "agent_id").First(&server, "id = ?", serverID).Error; err != nil {
+		return uuid.Nil, err
+	}
+	return server.AgentID, nil
+}
+
+// convertLogEntry convierte una entrada de log del agente al formato WebSocket
+func convertLogEntry(serverID uuid.UUID, entry *pb.LogEntry) websocket.LogEntry {
+	timestamp := time.Now()
+	if entry.Timestamp > 0 {
+		timestamp = time.Unix(entry.Timestamp, 0)
+	}
+
+	return websocket.LogEntry{
+		ServerID:   serverID,
+		Timestamp:  timestamp,
+		Level:      entry.Level,
+		Source:     entry.Source,
+		Message:    entry.Message,
+		Sequence:   entry.Sequence,
+		Thread:     entry.Thread,
+		Stream:     entry.Stream,
+		Plugin:     entry.Plugin,
+		ErrorType:  entry.ErrorType,
+		Severity:   entry.Severity,
+		Suggestion: entry.Suggestion,
+		StackTrace: entry.StackTrace,
+	}
+}
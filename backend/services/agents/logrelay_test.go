@@ -0,0 +1,23 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/aymc/backend/api/websocket"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestLogRelayIgnoresStaleActivation(t *testing.T) {
+	hub := websocket.NewHub(zap.NewNop())
+	relay := NewLogRelay(nil, hub, zap.NewNop())
+	defer relay.Stop()
+
+	// Activación que llega después de que el último suscriptor se fuera:
+	// el canal ya no tiene suscriptores y no se abre ningún stream
+	relay.ChannelActivated(websocket.BuildServerLogsChannel(uuid.New()))
+
+	if active := relay.ActiveRelays(); active != 0 {
+		t.Errorf("ActiveRelays() = %d after activating a channel without subscribers, want 0", active)
+	}
+}
@@ -0,0 +1,133 @@
+package agents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	pb "github.com/aymc/backend/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// agentTokenBytes bytes aleatorios del token de un agente (64 caracteres hex)
+const agentTokenBytes = 32
+
+// tokenCredentials envía el token del agente en cada llamada gRPC
+type tokenCredentials struct {
+	token string
+}
+
+// GetRequestMetadata implementa credentials.PerRPCCredentials
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+// RequireTransportSecurity implementa credentials.PerRPCCredentials.
+// El token nunca se envía por una conexión sin TLS.
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+// generateAgentToken genera un token aleatorio para un agente
+func generateAgentToken() (string, error) {
+	raw := make([]byte, agentTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// PairAgent provisiona un token nuevo en el agente y le emite un
+// certificado mTLS. Para el primer emparejamiento se necesita el código que
+// el agente muestra en su log; si el agente ya tiene token se envía el
+// actual y se rota.
+//
+// Se usa una conexión propia para que el emparejamiento funcione también
+// con el certificado revocado. Si el agente ya recibió un certificado se
+// exige su huella; sin ella un intermediario recibiría el token vigente al
+// rotarlo. Solo los agentes que nunca tuvieron certificado se emparejan sin
+// huella (confianza en el primer uso).
+func (s *AgentService) PairAgent(ctx context.Context, agentID uuid.UUID, pairingCode string) error {
+	var agent models.Agent
+	if err := s.registry.db.First(&agent, "id = ?", agentID).Error; err != nil {
+		return fmt.Errorf("agent not found: %w", err)
+	}
+	if agent.IsReverse() {
+		return fmt.Errorf("agents in reverse mode cannot be dialed, enroll them with an enrollment token")
+	}
+
+	token, err := generateAgentToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate agent token: %w", err)
+	}
+
+	pairCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(
+		pairCtx,
+		agent.GetAddress(),
+		grpc.WithTransportCredentials(credentials.NewTLS(s.registry.ca.ClientTLSConfig(agent.CertFingerprint))),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to agent: %w", err)
+	}
+	defer conn.Close()
+	client := pb.NewAgentServiceClient(conn)
+
+	var callOpts []grpc.CallOption
+	if agent.AuthToken != "" {
+		callOpts = append(callOpts, grpc.PerRPCCredentials(tokenCredentials{token: agent.AuthToken}))
+	}
+
+	resp, err := client.Pair(pairCtx, &pb.PairRequest{
+		PairingCode: pairingCode,
+		Token:       token,
+	}, callOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to pair agent: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("agent rejected pairing: %s", resp.Message)
+	}
+
+	// El agente ya solo acepta el token nuevo
+	if err := s.registry.db.Model(&agent).Update("auth_token", token).Error; err != nil {
+		s.logger.Error("Agent paired but token could not be saved",
+			zap.String("agent_id", agentID.String()),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to save agent token: %w", err)
+	}
+	agent.AuthToken = token
+
+	if err := s.registry.issueCertificate(pairCtx, client, &agent, grpc.PerRPCCredentials(tokenCredentials{token: token})); err != nil {
+		return fmt.Errorf("agent paired but certificate could not be issued: %w", err)
+	}
+
+	s.logger.Info("Agent paired",
+		zap.String("agent_id", agentID.String()),
+		zap.String("remote_agent_id", resp.AgentId),
+		zap.String("cert_fingerprint", agent.CertFingerprint),
+	)
+
+	// Conectar con el token y el certificado fijado
+	if existing, err := s.registry.GetAgent(agentID); err == nil {
+		if err := existing.Reconnect(ctx, &agent); err != nil {
+			return fmt.Errorf("agent paired but reconnection failed: %w", err)
+		}
+		return nil
+	}
+	if _, err := s.registry.Register(ctx, &agent); err != nil {
+		return fmt.Errorf("agent paired but registration failed: %w", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package agents
 import (
 	"context"
 	"fmt"
+	"net"
 	"sync"
 
 	"github.com/aymc/backend/database"
@@ -17,20 +18,28 @@ type AgentRegistry struct {
 	agents map[uuid.UUID]*AgentConnection
 	mu     sync.RWMutex
 	db     *gorm.DB
+	ca     *CertificateAuthority
 	logger *zap.Logger
 }
 
 // NewAgentRegistry crea un nuevo registro de agentes
-func NewAgentRegistry(logger *zap.Logger) *AgentRegistry {
+func NewAgentRegistry(ca *CertificateAuthority, logger *zap.Logger) *AgentRegistry {
 	return &AgentRegistry{
 		agents: make(map[uuid.UUID]*AgentConnection),
 		db:     database.GetDB(),
+		ca:     ca,
 		logger: logger.With(zap.String("component", "agent_registry")),
 	}
 }
 
 // Register registra y conecta un agente
 func (r *AgentRegistry) Register(ctx context.Context, agent *models.Agent) (*AgentConnection, error) {
+	return r.register(ctx, agent, nil)
+}
+
+// register registra un agente; tunnel es la conexión abierta por un agente
+// en modo reverse (nil en modo directo)
+func (r *AgentRegistry) register(ctx context.Context, agent *models.Agent, tunnel net.Conn) (*AgentConnection, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -50,7 +59,10 @@ func (r *AgentRegistry) Register(ctx context.Context, agent *models.Agent) (*Age
 	)
 
 	// Crear nueva conexión
-	conn := NewAgentConnection(agent, r.logger)
+	conn := NewAgentConnection(agent, r.ca, r.logger)
+	if tunnel != nil {
+		conn.setTunnel(tunnel)
+	}
 
 	// Intentar conectar
 	if err := conn.Connect(ctx); err != nil {
@@ -202,6 +214,14 @@ func (r *AgentRegistry) LoadAgentsFromDatabase(ctx context.Context) error {
 	for i := range agents {
 		agent := &agents[i]
 
+		// Los agentes en modo reverse se registran al abrir su túnel
+		if agent.IsReverse() {
+			r.logger.Info("Waiting for reverse agent to open its tunnel",
+				zap.String("agent_id", agent.ID.String()),
+			)
+			continue
+		}
+
 		// Solo intentar conectar si estaba marcado como online o si es la primera vez
 		if agent.Status == models.AgentStatusOnline || agent.LastSeen == nil {
 			r.logger.Info("Attempting to connect to agent",
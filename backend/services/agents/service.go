@@ -3,6 +3,7 @@ package agents
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/aymc/backend/database/models"
@@ -11,6 +12,10 @@ import (
 	"go.uber.org/zap"
 )
 
+// BackupOperationTimeout tiempo máximo para crear o restaurar un backup.
+// Mundos grandes pueden tardar varios minutos en comprimirse.
+const BackupOperationTimeout = 1 * time.Hour
+
 // AgentService proporciona operaciones para interactuar con agentes remotos
 type AgentService struct {
 	registry *AgentRegistry
@@ -356,11 +361,41 @@ func (s *AgentService) GetAgentMetrics(ctx context.Context, agentID uuid.UUID) (
 return resp, nil
 }
 
+// GetServerMetrics obtiene el uso de recursos del proceso de un servidor.
+// Si el servidor no está en ejecución el agente retorna FailedPrecondition.
+func (s *AgentService) GetServerMetrics(ctx context.Context, agentID uuid.UUID, serverID string) (*pb.ServerMetrics, error) {
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := client.GetServerMetrics(ctx, &pb.ServerRequest{ServerId: serverID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server metrics: %w", err)
+	}
+
+	return resp, nil
+}
+
 // StreamLogsCallback es una función callback para manejar logs en streaming
 type StreamLogsCallback func(serverID uuid.UUID, entry *pb.LogEntry)
 
-// StreamLogs inicia el streaming de logs de un servidor desde un agente
-func (s *AgentService) StreamLogs(ctx context.Context, serverID, agentID uuid.UUID, callback StreamLogsCallback) error {
+// LogStreamOptions define qué historial reenvía el agente antes de
+// seguir la salida en vivo
+type LogStreamOptions struct {
+	TailLines     int32     // Últimas N líneas del historial
+	Since         time.Time // Solo líneas posteriores a este instante
+	AfterSequence uint64    // Reanudar tras la última secuencia recibida
+	NoHistory     bool      // Solo líneas nuevas
+}
+
+// StreamLogs hace streaming de los logs de un servidor desde un agente.
+// Bloquea hasta que el stream termina o se cancela el contexto; retorna
+// nil si el agente cerró el stream normalmente.
+func (s *AgentService) StreamLogs(ctx context.Context, serverID, agentID uuid.UUID, opts LogStreamOptions, callback StreamLogsCallback) error {
 	s.logger.Info("Starting log stream",
 		zap.String("server_id", serverID.String()),
 		zap.String("agent_id", agentID.String()),
@@ -377,14 +412,23 @@ func (s *AgentService) StreamLogs(ctx context.Context, serverID, agentID uuid.UU
 		return fmt.Errorf("agent is not healthy")
 	}
 
-	// Crear contexto con timeout
-	streamCtx, cancel := context.WithTimeout(ctx, 24*time.Hour) // Stream de larga duración
-	defer cancel()
+	client := conn.GetClient()
+	if client == nil {
+		return fmt.Errorf("agent not connected")
+	}
 
 	// Llamar al método StreamLogs del agente
-	stream, err := conn.Client.StreamLogs(streamCtx, &pb.ServerRequest{
-		ServerId: serverID.String(),
-	})
+	req := &pb.StreamLogsRequest{
+		ServerId:      serverID.String(),
+		TailLines:     opts.TailLines,
+		AfterSequence: opts.AfterSequence,
+		NoHistory:     opts.NoHistory,
+	}
+	if !opts.Since.IsZero() {
+		req.Since = opts.Since.Unix()
+	}
+
+	stream, err := client.StreamLogs(ctx, req)
 	if err != nil {
 		s.logger.Error("Failed to start log stream",
 			zap.String("server_id", serverID.String()),
@@ -400,23 +444,28 @@ func (s *AgentService) StreamLogs(ctx context.Context, serverID, agentID uuid.UU
 	)
 
 	// Leer logs del stream
-	go func() {
-		for {
-			logEntry, err := stream.Recv()
-			if err != nil {
-				s.logger.Warn("Log stream closed",
+	for {
+		logEntry, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				s.logger.Info("Log stream closed by agent",
 					zap.String("server_id", serverID.String()),
-					zap.Error(err),
 				)
-				return
+				return nil
 			}
-
-			// Llamar al callback con el log entry
-			callback(serverID, logEntry)
+			if ctx.Err() != nil {
+				return nil
+			}
+			s.logger.Warn("Log stream interrupted",
+				zap.String("server_id", serverID.String()),
+				zap.Error(err),
+			)
+			return fmt.Errorf("log stream interrupted: %w", err)
 		}
-	}()
 
-	return nil
+		// Llamar al callback con el log entry
+		callback(serverID, logEntry)
+	}
 }
 
 // GetRegistry retorna el registry de agentes (para uso interno)
@@ -571,3 +620,76 @@ func (s *AgentService) UpdatePlugin(ctx context.Context, agentID uuid.UUID, serv
 
 	return nil
 }
+
+// CreateBackup pide al agente crear el archivo de backup de un servidor.
+// Un backup rechazado por el agente no es un error de transporte: se
+// retorna la respuesta con Success=false y el mensaje del agente.
+func (s *AgentService) CreateBackup(ctx context.Context, agentID uuid.UUID, req *pb.CreateBackupRequest) (*pb.CreateBackupResponse, error) {
+	s.logger.Info("Creating backup on agent",
+		zap.String("agent_id", agentID.String()),
+		zap.String("server_id", req.ServerId),
+		zap.String("destination", req.Destination),
+	)
+
+	// Obtener conexión al agente
+	agent, err := s.registry.GetAgent(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	// Verificar salud del agente
+	if !agent.IsHealthy() {
+		return nil, fmt.Errorf("agent is not healthy")
+	}
+
+	client := agent.GetClient()
+	if client == nil {
+		return nil, fmt.Errorf("agent not connected")
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, BackupOperationTimeout)
+	defer cancel()
+
+	resp, err := client.CreateBackup(timeoutCtx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	return resp, nil
+}
+
+// RestoreBackup pide al agente restaurar un backup en un servidor.
+// Igual que CreateBackup, un rechazo del agente se retorna en la respuesta.
+func (s *AgentService) RestoreBackup(ctx context.Context, agentID uuid.UUID, req *pb.RestoreBackupRequest) (*pb.RestoreBackupResponse, error) {
+	s.logger.Info("Restoring backup on agent",
+		zap.String("agent_id", agentID.String()),
+		zap.String("server_id", req.ServerId),
+		zap.String("backup_path", req.BackupPath),
+	)
+
+	// Obtener conexión al agente
+	agent, err := s.registry.GetAgent(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	// Verificar salud del agente
+	if !agent.IsHealthy() {
+		return nil, fmt.Errorf("agent is not healthy")
+	}
+
+	client := agent.GetClient()
+	if client == nil {
+		return nil, fmt.Errorf("agent not connected")
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, BackupOperationTimeout)
+	defer cancel()
+
+	resp, err := client.RestoreBackup(timeoutCtx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	return resp, nil
+}
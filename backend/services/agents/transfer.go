@@ -0,0 +1,231 @@
+package agents
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	pb "github.com/aymc/backend/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fileUploadChunkSize tamaño de cada fragmento enviado en UploadFile
+const fileUploadChunkSize = 1024 * 1024
+
+// Las transferencias de archivos no tienen timeout propio: duran lo que el
+// contexto del llamador (normalmente la petición HTTP) siga vivo.
+
+// UploadFile envía el contenido de reader al agente. first lleva el destino
+// (server_id, path, create_dirs), el offset desde el que se reanuda y,
+// opcionalmente, el checksum del archivo completo; sus datos se ignoran.
+func (s *AgentService) UploadFile(ctx context.Context, agentID uuid.UUID, first *pb.FileChunk, reader io.Reader) (*pb.FileTransferResponse, error) {
+	s.logger.Info("Uploading file to agent",
+		zap.String("agent_id", agentID.String()),
+		zap.String("server_id", first.ServerId),
+		zap.String("path", first.Path),
+		zap.Int64("offset", first.Offset),
+	)
+
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.UploadFile(streamCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	buffer := make([]byte, fileUploadChunkSize)
+	offset := first.Offset
+	sent := false
+	for {
+		n, readErr := io.ReadFull(reader, buffer)
+		if n > 0 || !sent {
+			chunk := &pb.FileChunk{
+				Data:   buffer[:n],
+				Offset: offset,
+			}
+			if !sent {
+				chunk.ServerId = first.ServerId
+				chunk.Path = first.Path
+				chunk.CreateDirs = first.CreateDirs
+				chunk.Checksum = first.Checksum
+			}
+			if err := stream.Send(chunk); err != nil {
+				// El error real llega en CloseAndRecv
+				_, recvErr := stream.CloseAndRecv()
+				if recvErr == nil {
+					recvErr = err
+				}
+				return nil, fmt.Errorf("failed to upload file: %w", recvErr)
+			}
+			offset += int64(n)
+			sent = true
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			// Cancelar el stream deja el parcial en el agente para reanudar
+			return nil, fmt.Errorf("failed to read upload: %w", readErr)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetUploadOffset retorna los bytes ya recibidos de una subida interrumpida
+func (s *AgentService) GetUploadOffset(ctx context.Context, agentID uuid.UUID, req *pb.FileRequest) (int64, error) {
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return 0, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, fileOperationTimeout)
+	defer cancel()
+
+	resp, err := client.GetUploadOffset(timeoutCtx, req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get upload offset: %w", err)
+	}
+	return resp.Offset, nil
+}
+
+// DownloadFile abre un archivo (o un rango) del agente. El primer fragmento
+// se lee antes de retornar para detectar errores y conocer el tamaño. El
+// llamador debe cerrar la descarga.
+func (s *AgentService) DownloadFile(ctx context.Context, agentID uuid.UUID, req *pb.DownloadFileRequest) (*FileDownload, error) {
+	s.logger.Info("Downloading file from agent",
+		zap.String("agent_id", agentID.String()),
+		zap.String("server_id", req.ServerId),
+		zap.String("path", req.Path),
+		zap.Int64("offset", req.Offset),
+		zap.Int64("length", req.Length),
+	)
+
+	client, err := s.healthyClient(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+
+	stream, err := client.DownloadFile(streamCtx, req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		cancel()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	end := first.TotalSize
+	if req.Length > 0 && first.Offset+req.Length < end {
+		end = first.Offset + req.Length
+	}
+
+	download := &FileDownload{
+		Offset:       first.Offset,
+		Length:       end - first.Offset,
+		TotalSize:    first.TotalSize,
+		ModifiedTime: first.ModifiedTime,
+		stream:       stream,
+		cancel:       cancel,
+		hasher:       sha256.New(),
+		next:         first.Offset,
+	}
+	download.accept(first.Data)
+
+	return download, nil
+}
+
+// FileDownload adapta el stream de DownloadFile a io.Reader. Al terminar
+// comprueba que llegó el rango completo y que coincide con el checksum
+// anunciado por el agente.
+type FileDownload struct {
+	Offset       int64 // primer byte del rango
+	Length       int64 // bytes del rango
+	TotalSize    int64 // tamaño del archivo completo
+	ModifiedTime int64
+
+	stream  pb.AgentService_DownloadFileClient
+	cancel  context.CancelFunc
+	hasher  hash.Hash
+	pending []byte
+	next    int64
+	done    bool
+}
+
+func (d *FileDownload) accept(data []byte) {
+	d.hasher.Write(data)
+	d.pending = data
+	d.next += int64(len(data))
+}
+
+func (d *FileDownload) Read(p []byte) (int, error) {
+	for len(d.pending) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+
+		chunk, err := d.stream.Recv()
+		if err == io.EOF {
+			return 0, fmt.Errorf("download ended without checksum: %w", io.ErrUnexpectedEOF)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to download file: %w", err)
+		}
+		if chunk.Offset != d.next {
+			return 0, fmt.Errorf("file chunk out of order: offset %d, expected %d", chunk.Offset, d.next)
+		}
+
+		if len(chunk.Data) == 0 && chunk.Checksum != "" {
+			if err := d.verify(chunk.Checksum); err != nil {
+				return 0, err
+			}
+			d.done = true
+			continue
+		}
+		d.accept(chunk.Data)
+	}
+
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
+
+// verify compara lo recibido con el rango pedido y el checksum del agente
+func (d *FileDownload) verify(checksum string) error {
+	if received := d.next - d.Offset; received != d.Length {
+		return fmt.Errorf("download incomplete: received %d of %d bytes", received, d.Length)
+	}
+	if sum := hex.EncodeToString(d.hasher.Sum(nil)); !strings.EqualFold(sum, checksum) {
+		return fmt.Errorf("download checksum mismatch: expected %s, got %s", checksum, sum)
+	}
+	return nil
+}
+
+func (d *FileDownload) Close() error {
+	d.cancel()
+	return nil
+}
@@ -0,0 +1,249 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// TunnelHelloPrefix primera línea que envía un agente al abrir un túnel:
+	// "AYMC-TUNNEL/1 <agent_id>\n". El backend responde "OK\n" o "ERR <motivo>\n"
+	// y a partir de ahí actúa como cliente gRPC (mTLS) sobre la conexión.
+	TunnelHelloPrefix = "AYMC-TUNNEL/1"
+
+	// tunnelHandshakeTimeout tiempo máximo para recibir el saludo
+	tunnelHandshakeTimeout = 10 * time.Second
+
+	// tunnelMaxHelloLength longitud máxima de la línea de saludo
+	tunnelMaxHelloLength = 256
+)
+
+// errNoTunnel se retorna al conectar con un agente en modo reverse que no
+// tiene un túnel abierto
+var errNoTunnel = errors.New("agent uses reverse mode and has no open tunnel")
+
+// errTunnelClosed se retorna al redial de gRPC cuando el túnel se cerró; el
+// agente debe abrir uno nuevo
+var errTunnelClosed = errors.New("agent tunnel closed, waiting for the agent to reconnect")
+
+// tunnelDialer entrega a gRPC la conexión abierta por el agente. Solo se
+// puede usar una vez.
+type tunnelDialer struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// dial implementa el dialer de grpc.WithContextDialer
+func (d *tunnelDialer) dial(ctx context.Context, _ string) (net.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return nil, errTunnelClosed
+	}
+	conn := d.conn
+	d.conn = nil
+	return conn, nil
+}
+
+// TunnelServer acepta los túneles que abren los agentes en modo reverse
+type TunnelServer struct {
+	registry *AgentRegistry
+	addr     string
+	listener net.Listener
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	logger   *zap.Logger
+}
+
+// NewTunnelServer crea el servidor de túneles
+func NewTunnelServer(registry *AgentRegistry, addr string, logger *zap.Logger) *TunnelServer {
+	return &TunnelServer{
+		registry: registry,
+		addr:     addr,
+		logger:   logger.With(zap.String("component", "tunnel_server")),
+	}
+}
+
+// Start comienza a aceptar túneles
+func (ts *TunnelServer) Start() error {
+	listener, err := net.Listen("tcp", ts.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for agent tunnels: %w", err)
+	}
+
+	ts.listener = listener
+	ts.ctx, ts.cancel = context.WithCancel(context.Background())
+
+	ts.logger.Info("Accepting agent tunnels", zap.String("addr", listener.Addr().String()))
+
+	ts.wg.Add(1)
+	go ts.acceptLoop()
+
+	return nil
+}
+
+// Stop deja de aceptar túneles. Los túneles abiertos se cierran al cerrar
+// el registro de agentes.
+func (ts *TunnelServer) Stop() {
+	if ts.cancel != nil {
+		ts.cancel()
+	}
+	if ts.listener != nil {
+		ts.listener.Close()
+	}
+	ts.wg.Wait()
+
+	ts.logger.Info("Tunnel server stopped")
+}
+
+// acceptLoop acepta conexiones hasta que se detenga el servidor
+func (ts *TunnelServer) acceptLoop() {
+	defer ts.wg.Done()
+
+	for {
+		conn, err := ts.listener.Accept()
+		if err != nil {
+			if ts.ctx.Err() != nil {
+				return
+			}
+			ts.logger.Warn("Failed to accept tunnel", zap.Error(err))
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		go ts.handleConn(conn)
+	}
+}
+
+// handleConn lee el saludo del agente y entrega la conexión al registro
+func (ts *TunnelServer) handleConn(conn net.Conn) {
+	remote := conn.RemoteAddr().String()
+
+	conn.SetDeadline(time.Now().Add(tunnelHandshakeTimeout))
+	agentID, err := readTunnelHello(conn)
+	if err != nil {
+		ts.logger.Debug("Invalid tunnel handshake", zap.String("remote", remote), zap.Error(err))
+		conn.Close()
+		return
+	}
+
+	var agent models.Agent
+	err = ts.registry.db.Where("agent_id = ?", agentID).First(&agent).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ts.reject(conn, agentID, remote, "unknown agent")
+		return
+	case err != nil:
+		ts.logger.Error("Failed to look up tunnel agent", zap.Error(err))
+		ts.reject(conn, agentID, remote, "internal error")
+		return
+	case agent.CertRevokedAt != nil:
+		ts.reject(conn, agentID, remote, "agent certificate revoked")
+		return
+	case !agent.IsReverse():
+		// El modo se fija al enrolar; un saludo no puede cambiarlo
+		ts.reject(conn, agentID, remote, "agent is not configured for reverse mode")
+		return
+	case agent.CertFingerprint == "" || agent.AuthToken == "":
+		// Sin huella fijada cualquiera podría hacerse pasar por el agente y
+		// recibir su token
+		ts.reject(conn, agentID, remote, "agent certificate not issued, enroll or pair the agent first")
+		return
+	}
+
+	if _, err := conn.Write([]byte("OK\n")); err != nil {
+		conn.Close()
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetKeepAlive(true)
+		tcpConn.SetKeepAlivePeriod(30 * time.Second)
+	}
+
+	ts.logger.Info("Agent tunnel opened",
+		zap.String("agent_id", agent.ID.String()),
+		zap.String("remote", remote),
+	)
+
+	ctx, cancel := context.WithTimeout(ts.ctx, 15*time.Second)
+	defer cancel()
+
+	if err := ts.registry.AttachTunnel(ctx, &agent, conn); err != nil {
+		ts.logger.Warn("Failed to connect through agent tunnel",
+			zap.String("agent_id", agent.ID.String()),
+			zap.Error(err),
+		)
+		conn.Close()
+	}
+}
+
+// reject responde con un error y cierra el túnel
+func (ts *TunnelServer) reject(conn net.Conn, agentID, remote, reason string) {
+	ts.logger.Warn("Tunnel rejected",
+		zap.String("remote_agent_id", agentID),
+		zap.String("remote", remote),
+		zap.String("reason", reason),
+	)
+	conn.Write([]byte("ERR " + reason + "\n"))
+	conn.Close()
+}
+
+// readTunnelHello lee la línea de saludo byte a byte para no consumir nada
+// del handshake TLS que sigue
+func readTunnelHello(conn net.Conn) (string, error) {
+	line := make([]byte, 0, 64)
+	buf := make([]byte, 1)
+
+	for len(line) < tunnelMaxHelloLength {
+		if _, err := conn.Read(buf); err != nil {
+			return "", err
+		}
+		if buf[0] == '\n' {
+			fields := strings.Fields(string(line))
+			if len(fields) != 2 || fields[0] != TunnelHelloPrefix {
+				return "", fmt.Errorf("unexpected tunnel hello")
+			}
+			return fields[1], nil
+		}
+		line = append(line, buf[0])
+	}
+
+	return "", fmt.Errorf("tunnel hello too long")
+}
+
+// AttachTunnel conecta con un agente a través del túnel que acaba de abrir.
+// Para el registro es una conexión más: la actual solo se reemplaza si el
+// túnel supera el mTLS con la huella fijada y un Ping.
+func (r *AgentRegistry) AttachTunnel(ctx context.Context, agent *models.Agent, tunnel net.Conn) error {
+	if !agent.IsReverse() {
+		return fmt.Errorf("agent %s is not configured for reverse mode", agent.ID)
+	}
+
+	r.mu.RLock()
+	existing, exists := r.agents[agent.ID]
+	r.mu.RUnlock()
+
+	if !exists {
+		_, err := r.register(ctx, agent, tunnel)
+		return err
+	}
+
+	existing.setTunnel(tunnel)
+	if err := existing.Reconnect(ctx, agent); err != nil {
+		return err
+	}
+
+	return r.UpdateAgentStatus(agent.ID, models.AgentStatusOnline)
+}
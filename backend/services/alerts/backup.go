@@ -0,0 +1,121 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aymc/backend/api/websocket"
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// HandleBackupResult evalúa las reglas backup_failed al terminar un backup.
+// Un backup fallido dispara las reglas del servidor y uno completado
+// resuelve sus alertas. Los flags notify_on_failure y notify_on_complete de
+// la configuración de backups del servidor deciden si se notifica: sin
+// reglas, el fallo se publica igualmente como alerta por WebSocket.
+func (s *Service) HandleBackupResult(backup *models.Backup) {
+	var server models.Server
+	if err := s.db.Select("id", "user_id", "name").First(&server, "id = ?", backup.ServerID).Error; err != nil {
+		s.logger.Warn("Backup result for unknown server",
+			zap.String("backup_id", backup.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	notifyOnComplete, notifyOnFailure := s.backupNotifyFlags(server.ID)
+
+	rules, err := s.backupRules(&server)
+	if err != nil {
+		s.logger.Error("Failed to load backup alert rules", zap.Error(err))
+		return
+	}
+
+	now := time.Now().UTC()
+	failed := backup.Status == models.BackupStatusFailed
+
+	if failed && !notifyOnFailure {
+		return
+	}
+
+	for i := range rules {
+		o := observation{
+			targetID:   server.ID,
+			targetName: server.Name,
+			breached:   failed,
+			message:    fmt.Sprintf("Backup %s fallido: %s", backup.Filename, backup.Error),
+		}
+		if failed {
+			o.value = 1
+		}
+		s.apply(&rules[i], []observation{o}, now, false)
+	}
+
+	switch {
+	case failed && len(rules) == 0:
+		s.hub.BroadcastAlert(websocket.Alert{
+			ID:        uuid.New(),
+			Severity:  string(models.AlertSeverityError),
+			Title:     fmt.Sprintf("Backup fallido: %s", server.Name),
+			Message:   backup.Error,
+			Source:    models.AlertTargetServer,
+			SourceID:  server.ID,
+			Timestamp: now,
+			Data:      backupAlertData(backup),
+		})
+	case !failed && notifyOnComplete:
+		s.hub.BroadcastAlert(websocket.Alert{
+			ID:        uuid.New(),
+			Severity:  string(models.AlertSeverityInfo),
+			Title:     fmt.Sprintf("Backup completado: %s", server.Name),
+			Message:   fmt.Sprintf("%s (%.1f MB)", backup.Filename, backup.FileSizeMB()),
+			Source:    models.AlertTargetServer,
+			SourceID:  server.ID,
+			Timestamp: now,
+			Data:      backupAlertData(backup),
+		})
+	}
+}
+
+// backupRules retorna las reglas backup_failed activas que aplican a un
+// servidor
+func (s *Service) backupRules(server *models.Server) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := s.db.Preload("Channels").
+		Where("enabled = ? AND type = ?", true, models.AlertRuleBackupFailed).
+		Where("target_id = ? OR (target_id IS NULL AND user_id = ?)", server.ID, server.UserID).
+		Find(&rules).Error
+	return rules, err
+}
+
+// backupNotifyFlags lee los flags de notificación de la configuración de
+// backups de un servidor. Sin configuración ambos están activos, como en
+// la configuración por defecto.
+func (s *Service) backupNotifyFlags(serverID uuid.UUID) (onComplete, onFailure bool) {
+	var config models.BackupConfig
+	err := s.db.Select("notify_on_complete", "notify_on_failure").
+		Where("server_id = ?", serverID).First(&config).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("Failed to load backup config", zap.String("server_id", serverID.String()), zap.Error(err))
+		}
+		return true, true
+	}
+	return config.NotifyOnComplete, config.NotifyOnFailure
+}
+
+// backupAlertData datos de un backup incluidos en las alertas por WebSocket
+func backupAlertData(backup *models.Backup) map[string]interface{} {
+	return map[string]interface{}{
+		"backup_id":   backup.ID.String(),
+		"backup_type": backup.BackupType,
+		"filename":    backup.Filename,
+		"size_bytes":  backup.SizeBytes,
+		"duration_ms": backup.DurationMs,
+		"error":       backup.Error,
+	}
+}
@@ -0,0 +1,122 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenDestination la URL de un canal apunta a una dirección interna
+// (loopback, enlace local o red privada) y el dueño no es administrador
+var ErrForbiddenDestination = errors.New("destination address not allowed")
+
+// sharedAddressSpace rango CGNAT (RFC 6598), interno aunque no sea privado
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+type internalDestinationsKey struct{}
+
+// withInternalDestinations permite que las entregas hechas con ctx conecten
+// con direcciones internas. Solo para canales de administradores.
+func withInternalDestinations(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalDestinationsKey{}, true)
+}
+
+func internalDestinationsAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(internalDestinationsKey{}).(bool)
+	return allowed
+}
+
+// newWebhookClient crea el cliente HTTP de los webhooks. La dirección se
+// comprueba al conectar, después de resolver el nombre, así que un DNS que
+// cambie de respuesta (rebinding) no permite llegar a la red interna. No se
+// siguen redirecciones ni se usa el proxy del entorno.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+
+	return &http.Client{
+		Timeout: deliveryTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				if internalDestinationsAllowed(ctx) {
+					return dialer.DialContext(ctx, network, address)
+				}
+				guarded := *dialer
+				guarded.Control = rejectInternalAddress
+				return guarded.DialContext(ctx, network, address)
+			},
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// rejectInternalAddress se ejecuta antes de cada conexión con la IP ya
+// resuelta
+func rejectInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isInternalIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenDestination, host)
+	}
+	return nil
+}
+
+// isInternalIP indica si ip pertenece a loopback, enlace local, redes
+// privadas o rangos no enrutables
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// validateWebhookURL comprueba la URL de un canal webhook o Discord. Exige
+// https salvo que allowHTTP lo permita y, sin allowInternal, rechaza los
+// hosts que ya se sabe que son internos (IP literal o localhost). Los
+// nombres que resuelven a direcciones internas se rechazan al conectar.
+func validateWebhookURL(raw string, allowHTTP, allowInternal bool) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+
+	switch parsed.Scheme {
+	case "https":
+	case "http":
+		if !allowHTTP {
+			return fmt.Errorf("url must use https")
+		}
+	default:
+		return fmt.Errorf("url must use https")
+	}
+
+	if allowInternal {
+		return nil
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenDestination, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && isInternalIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenDestination, host)
+	}
+	return nil
+}
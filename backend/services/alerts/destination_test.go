@@ -0,0 +1,175 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	cases := []struct {
+		url           string
+		allowHTTP     bool
+		allowInternal bool
+		wantErr       bool
+	}{
+		{url: "https://discord.com/api/webhooks/1/abc"},
+		{url: "http://hooks.example.com/aymc", wantErr: true},
+		{url: "http://hooks.example.com/aymc", allowHTTP: true},
+		{url: "ftp://hooks.example.com/aymc", allowHTTP: true, wantErr: true},
+		{url: "/relative", wantErr: true},
+		{url: "https://localhost:8080/hook", wantErr: true},
+		{url: "https://127.0.0.1/hook", wantErr: true},
+		{url: "https://169.254.169.254/latest/meta-data", wantErr: true},
+		{url: "https://10.0.0.5/hook", wantErr: true},
+		{url: "https://[::1]/hook", wantErr: true},
+		{url: "https://[fe80::1]/hook", wantErr: true},
+		{url: "https://100.64.1.1/hook", wantErr: true},
+		{url: "https://10.0.0.5/hook", allowInternal: true},
+		{url: "https://8.8.8.8/hook"},
+	}
+
+	for _, tc := range cases {
+		err := validateWebhookURL(tc.url, tc.allowHTTP, tc.allowInternal)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("validateWebhookURL(%q, http=%v, internal=%v) = %v, wantErr %v",
+				tc.url, tc.allowHTTP, tc.allowInternal, err, tc.wantErr)
+		}
+	}
+}
+
+func TestIsInternalIP(t *testing.T) {
+	internal := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fd00::1", "fe80::1", "::ffff:127.0.0.1"}
+	for _, addr := range internal {
+		if !isInternalIP(net.ParseIP(addr)) {
+			t.Errorf("isInternalIP(%s) = false, want true", addr)
+		}
+	}
+
+	public := []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"}
+	for _, addr := range public {
+		if isInternalIP(net.ParseIP(addr)) {
+			t.Errorf("isInternalIP(%s) = true, want false", addr)
+		}
+	}
+}
+
+func TestWebhookClientBlocksInternalDestinations(t *testing.T) {
+	delivered := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered++
+	}))
+	defer server.Close()
+
+	client := newWebhookClient()
+
+	// La comprobación se hace al conectar, con la IP ya resuelta
+	err := postJSON(context.Background(), client, server.URL, []byte(`{}`), nil)
+	if !errors.Is(err, ErrForbiddenDestination) {
+		t.Fatalf("postJSON to loopback = %v, want ErrForbiddenDestination", err)
+	}
+	if delivered != 0 {
+		t.Fatal("the request reached the internal server")
+	}
+
+	// Los canales de administradores pueden usar la red interna
+	if err := postJSON(withInternalDestinations(context.Background()), client, server.URL, []byte(`{}`), nil); err != nil {
+		t.Fatalf("postJSON with internal destinations allowed: %v", err)
+	}
+	if delivered != 1 {
+		t.Errorf("delivered = %d, want 1", delivered)
+	}
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect was followed")
+	}))
+	defer target.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	ctx := withInternalDestinations(context.Background())
+	if err := postJSON(ctx, newWebhookClient(), server.URL, []byte(`{}`), nil); err == nil {
+		t.Error("a redirect response should fail the delivery")
+	}
+}
+
+// newChannelTestService crea un servicio sobre SQLite en memoria con un
+// usuario normal y un administrador. Solo se crean las columnas que usan
+// los canales.
+func newChannelTestService(t *testing.T, config Config) (*Service, uuid.UUID, uuid.UUID) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE users (id TEXT PRIMARY KEY, role TEXT)`,
+		`CREATE TABLE notification_channels (id TEXT PRIMARY KEY, user_id TEXT, name TEXT, type TEXT, url TEXT,
+			secret TEXT, recipients TEXT, enabled BOOLEAN, created_at DATETIME, updated_at DATETIME)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	user, admin := uuid.New(), uuid.New()
+	db.Exec(`INSERT INTO users (id, role) VALUES (?, ?), (?, ?)`, user, models.RoleUser, admin, models.RoleAdmin)
+
+	return NewService(db, nil, nil, config, zap.NewNop()), user, admin
+}
+
+func TestInternalWebhooksRequireAdminOwner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	service, user, admin := newChannelTestService(t, Config{AllowHTTPWebhooks: true})
+	ctx := context.Background()
+	req := &CreateChannelRequest{Name: "interno", Type: models.NotificationChannelWebhook, URL: server.URL}
+
+	if _, err := service.CreateChannel(ctx, user, req); !errors.Is(err, ErrInvalidChannel) {
+		t.Errorf("CreateChannel() with a loopback URL for a user = %v, want ErrInvalidChannel", err)
+	}
+
+	channel, err := service.CreateChannel(ctx, admin, req)
+	if err != nil {
+		t.Fatalf("CreateChannel() with a loopback URL for an admin: %v", err)
+	}
+	if err := service.TestChannel(ctx, channel.ID, admin, true); err != nil {
+		t.Errorf("TestChannel() for the admin channel: %v", err)
+	}
+
+	// Un canal guardado antes de la restricción tampoco entrega a la red interna
+	legacy := &models.NotificationChannel{UserID: user, Name: "antiguo", Type: models.NotificationChannelWebhook, URL: server.URL, Enabled: true}
+	if err := service.db.Create(legacy).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := service.TestChannel(ctx, legacy.ID, user, false); !errors.Is(err, ErrDeliveryFailed) {
+		t.Errorf("TestChannel() for a user channel to loopback = %v, want ErrDeliveryFailed", err)
+	}
+}
+
+func TestWebhooksRequireHTTPSByDefault(t *testing.T) {
+	service, _, admin := newChannelTestService(t, Config{})
+
+	_, err := service.CreateChannel(context.Background(), admin, &CreateChannelRequest{
+		Name: "plano", Type: models.NotificationChannelDiscord, URL: "http://discord.example.com/api/webhooks/1",
+	})
+	if !errors.Is(err, ErrInvalidChannel) {
+		t.Errorf("CreateChannel() with http URL = %v, want ErrInvalidChannel", err)
+	}
+}
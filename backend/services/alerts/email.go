@@ -0,0 +1,161 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+)
+
+// SMTPConfig servidor SMTP de los canales de email
+type SMTPConfig struct {
+	Host     string // Vacío = canales de email deshabilitados
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// ErrSMTPNotConfigured indica que no hay servidor SMTP para los canales de
+// email
+var ErrSMTPNotConfigured = errors.New("SMTP is not configured")
+
+// EmailNotifier envía la notificación por SMTP a los destinatarios del
+// canal. Usa STARTTLS si el servidor lo ofrece y solo se autentica si hay
+// usuario configurado.
+type EmailNotifier struct {
+	config SMTPConfig
+}
+
+// Send implementa Notifier
+func (n *EmailNotifier) Send(ctx context.Context, channel *models.NotificationChannel, notification *Notification) error {
+	if n.config.Host == "" {
+		return ErrSMTPNotConfigured
+	}
+
+	recipients, err := parseRecipients(channel.Recipients)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("[AYMC] [%s] %s", strings.ToUpper(string(notification.Severity)), notification.Title)
+	message := buildEmail(n.config.From, recipients, subject, emailBody(notification), notification.Timestamp)
+	return n.sendMail(ctx, recipients, message)
+}
+
+// sendMail entrega un mensaje respetando el deadline de ctx
+func (n *EmailNotifier) sendMail(ctx context.Context, recipients []string, message []byte) error {
+	port := n.config.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(port))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error conectando a %s: %w", addr, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return fmt.Errorf("error en STARTTLS: %w", err)
+		}
+	}
+
+	if n.config.Username != "" {
+		auth := smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("error de autenticación SMTP: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("destinatario %s rechazado: %w", recipient, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// parseRecipients valida una lista de direcciones separadas por comas
+func parseRecipients(list string) ([]string, error) {
+	var recipients []string
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		address, err := mail.ParseAddress(part)
+		if err != nil {
+			return nil, fmt.Errorf("dirección inválida %q: %w", part, err)
+		}
+		recipients = append(recipients, address.Address)
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("sin destinatarios")
+	}
+	return recipients, nil
+}
+
+// emailBody cuerpo en texto plano de una notificación
+func emailBody(notification *Notification) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\r\n\r\n", notification.Message)
+	fmt.Fprintf(&body, "Regla: %s (%s)\r\n", notification.RuleName, notification.RuleType)
+	fmt.Fprintf(&body, "Objetivo: %s %s (%s)\r\n", notification.TargetType, notification.TargetName, notification.TargetID)
+	fmt.Fprintf(&body, "Estado: %s\r\n", notification.Status)
+	fmt.Fprintf(&body, "Severidad: %s\r\n", notification.Severity)
+	fmt.Fprintf(&body, "Disparada: %s\r\n", notification.FiredAt.Format(time.RFC1123Z))
+	return body.String()
+}
+
+// buildEmail compone un mensaje RFC 5322 en UTF-8
+func buildEmail(from string, to []string, subject, body string, date time.Time) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+	return msg.Bytes()
+}
@@ -0,0 +1,443 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/aymc/backend/services/agents"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// periodicRuleTypes tipos de regla que se evalúan en cada intervalo. Las
+// reglas backup_failed se evalúan al terminar cada backup.
+var periodicRuleTypes = []models.AlertRuleType{
+	models.AlertRuleCPU,
+	models.AlertRuleMemory,
+	models.AlertRuleTPS,
+	models.AlertRuleDisk,
+	models.AlertRuleAgentOffline,
+	models.AlertRuleCrashLoop,
+}
+
+// observation valor de la condición de una regla para un servidor o agente
+type observation struct {
+	targetID   uuid.UUID
+	targetName string
+	value      float64
+	breached   bool
+	message    string // Detalle del evento que disparó la alerta (backups)
+}
+
+// evaluate evalúa todas las reglas periódicas activas
+func (s *Service) evaluate(ctx context.Context, now time.Time) {
+	var rules []models.AlertRule
+	err := s.db.WithContext(ctx).Preload("Channels").
+		Where("enabled = ? AND type IN ?", true, periodicRuleTypes).
+		Find(&rules).Error
+	if err != nil {
+		s.logger.Error("Failed to load alert rules", zap.Error(err))
+		return
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		observations, err := s.observe(ctx, rule, now)
+		if err != nil {
+			s.logger.Warn("Failed to evaluate alert rule",
+				zap.String("rule_id", rule.ID.String()),
+				zap.String("type", string(rule.Type)),
+				zap.Error(err),
+			)
+			continue
+		}
+		s.apply(rule, observations, now, true)
+	}
+}
+
+// observe calcula la condición de una regla para cada servidor o agente de
+// su alcance. Los que no tienen datos en la ventana no la incumplen.
+func (s *Service) observe(ctx context.Context, rule *models.AlertRule, now time.Time) ([]observation, error) {
+	since := now.Add(-rule.Window())
+
+	if rule.TargetType == models.AlertTargetAgent {
+		agentsInScope := s.agentTargets(rule)
+		if rule.Type == models.AlertRuleAgentOffline {
+			return offlineObservations(rule, agentsInScope, now), nil
+		}
+
+		ids := make([]uuid.UUID, len(agentsInScope))
+		for i, agent := range agentsInScope {
+			ids[i] = agent.AgentID
+		}
+		averages, err := s.averages(ctx, "agent_metrics", "agent_id", agentMetricColumn(rule.Type), ids, since)
+		if err != nil {
+			return nil, err
+		}
+
+		observations := make([]observation, 0, len(agentsInScope))
+		for _, agent := range agentsInScope {
+			value, ok := averages[agent.AgentID]
+			observations = append(observations, observation{
+				targetID:   agent.AgentID,
+				targetName: agent.Hostname,
+				value:      value,
+				breached:   ok && breaches(rule, value),
+			})
+		}
+		return observations, nil
+	}
+
+	servers, err := s.serverTargets(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[uuid.UUID]float64)
+	switch rule.Type {
+	case models.AlertRuleCrashLoop:
+		for _, server := range servers {
+			values[server.ID] = float64(s.crashCount(server.ID, since))
+		}
+	case models.AlertRuleDisk:
+		// El disco es el del volumen del agente que aloja el servidor
+		agentIDs := make([]uuid.UUID, len(servers))
+		for i, server := range servers {
+			agentIDs[i] = server.AgentID
+		}
+		byAgent, err := s.averages(ctx, "agent_metrics", "agent_id", "disk_percent", agentIDs, since)
+		if err != nil {
+			return nil, err
+		}
+		for _, server := range servers {
+			if value, ok := byAgent[server.AgentID]; ok {
+				values[server.ID] = value
+			}
+		}
+	default:
+		ids := make([]uuid.UUID, len(servers))
+		for i, server := range servers {
+			ids[i] = server.ID
+		}
+		values, err = s.averages(ctx, "server_metrics", "server_id", serverMetricColumn(rule.Type), ids, since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	observations := make([]observation, 0, len(servers))
+	for _, server := range servers {
+		value, ok := values[server.ID]
+		if ok && rule.Type == models.AlertRuleMemory {
+			value = memoryPercent(value, server.MemoryMax)
+		}
+		observations = append(observations, observation{
+			targetID:   server.ID,
+			targetName: server.Name,
+			value:      value,
+			breached:   ok && breaches(rule, value),
+		})
+	}
+	return observations, nil
+}
+
+// serverTargets retorna los servidores a los que aplica una regla
+func (s *Service) serverTargets(ctx context.Context, rule *models.AlertRule) ([]models.Server, error) {
+	query := s.db.WithContext(ctx).Select("id", "agent_id", "name", "memory_max")
+	if rule.TargetID != nil {
+		query = query.Where("id = ?", *rule.TargetID)
+	} else {
+		query = query.Where("user_id = ?", rule.UserID)
+	}
+
+	var servers []models.Server
+	if err := query.Find(&servers).Error; err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// agentTargets retorna el estado de los agentes a los que aplica una regla
+func (s *Service) agentTargets(rule *models.AlertRule) []agents.AgentHealth {
+	all := s.health.AgentHealth()
+	if rule.TargetID == nil {
+		return all
+	}
+	for _, agent := range all {
+		if agent.AgentID == *rule.TargetID {
+			return []agents.AgentHealth{agent}
+		}
+	}
+	return nil
+}
+
+// averages calcula la media de una columna de métricas desde since para
+// cada ID. Los IDs sin muestras no aparecen en el resultado.
+func (s *Service) averages(ctx context.Context, table, idColumn, column string, ids []uuid.UUID, since time.Time) (map[uuid.UUID]float64, error) {
+	result := make(map[uuid.UUID]float64)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		SourceID uuid.UUID
+		Value    float64
+	}
+	err := s.db.WithContext(ctx).Table(table).
+		Select(idColumn+" AS source_id, AVG("+column+") AS value").
+		Where(idColumn+" IN ? AND timestamp >= ? AND "+column+" IS NOT NULL", ids, since).
+		Group(idColumn).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.SourceID] = row.Value
+	}
+	return result, nil
+}
+
+// offlineObservations marca los agentes que no están online desde hace más
+// que la ventana de la regla. El valor es el tiempo sin respuesta en segundos.
+func offlineObservations(rule *models.AlertRule, agentsInScope []agents.AgentHealth, now time.Time) []observation {
+	observations := make([]observation, 0, len(agentsInScope))
+	for _, agent := range agentsInScope {
+		o := observation{targetID: agent.AgentID, targetName: agent.Hostname}
+		if agent.Status != agents.AgentStatusOnline {
+			if !agent.LastSeen.IsZero() {
+				o.value = now.Sub(agent.LastSeen).Seconds()
+			}
+			o.breached = agent.LastSeen.IsZero() || now.Sub(agent.LastSeen) >= rule.Window()
+		}
+		observations = append(observations, o)
+	}
+	return observations
+}
+
+// serverMetricColumn columna de server_metrics que evalúa un tipo de regla
+func serverMetricColumn(ruleType models.AlertRuleType) string {
+	switch ruleType {
+	case models.AlertRuleMemory:
+		return "memory_used"
+	case models.AlertRuleTPS:
+		return "tps"
+	default:
+		return "cpu_percent"
+	}
+}
+
+// agentMetricColumn columna de agent_metrics que evalúa un tipo de regla
+func agentMetricColumn(ruleType models.AlertRuleType) string {
+	switch ruleType {
+	case models.AlertRuleMemory:
+		return "memory_percent"
+	case models.AlertRuleDisk:
+		return "disk_percent"
+	default:
+		return "cpu_percent"
+	}
+}
+
+// memoryPercent convierte la memoria usada por un servidor en porcentaje de
+// su memoria máxima configurada (MB)
+func memoryPercent(usedBytes float64, memoryMaxMB int) float64 {
+	if memoryMaxMB <= 0 {
+		return 0
+	}
+	return usedBytes / float64(memoryMaxMB*1024*1024) * 100
+}
+
+// breaches indica si un valor incumple el umbral de una regla
+func breaches(rule *models.AlertRule, value float64) bool {
+	switch rule.Type {
+	case models.AlertRuleTPS:
+		return value < rule.Threshold
+	case models.AlertRuleCrashLoop:
+		return value >= rule.Threshold
+	default:
+		return value > rule.Threshold
+	}
+}
+
+// apply actualiza las alertas de una regla según sus observaciones:
+// dispara las nuevas, recuerda las que siguen activas tras el cooldown y
+// resuelve las que ya no se cumplen. Con resolveMissing también se
+// resuelven las alertas de objetivos que ya no están en el alcance.
+func (s *Service) apply(rule *models.AlertRule, observations []observation, now time.Time, resolveMissing bool) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	var firing []models.Alert
+	if err := s.db.Where("rule_id = ? AND status = ?", rule.ID, models.AlertStatusFiring).Find(&firing).Error; err != nil {
+		s.logger.Error("Failed to load firing alerts", zap.String("rule_id", rule.ID.String()), zap.Error(err))
+		return
+	}
+	byTarget := make(map[uuid.UUID]*models.Alert, len(firing))
+	for i := range firing {
+		byTarget[firing[i].TargetID] = &firing[i]
+	}
+
+	for _, o := range observations {
+		alert := byTarget[o.targetID]
+		delete(byTarget, o.targetID)
+
+		switch {
+		case o.breached:
+			s.fire(rule, alert, o, now)
+		case alert != nil:
+			s.resolve(rule, alert, now)
+		}
+	}
+
+	if resolveMissing {
+		for _, alert := range byTarget {
+			s.resolve(rule, alert, now)
+		}
+	}
+}
+
+// fire crea la alerta de un objetivo que incumple la regla o, si ya estaba
+// activa, la vuelve a notificar cuando pasa el cooldown y nadie la ha
+// reconocido
+func (s *Service) fire(rule *models.AlertRule, alert *models.Alert, o observation, now time.Time) {
+	title, message := describe(rule, o)
+
+	if alert == nil {
+		alert = &models.Alert{
+			RuleID:     rule.ID,
+			UserID:     rule.UserID,
+			TargetType: rule.TargetType,
+			TargetID:   o.targetID,
+			TargetName: o.targetName,
+			Severity:   rule.Severity,
+			Status:     models.AlertStatusFiring,
+			Title:      title,
+			Message:    message,
+			Value:      o.value,
+			Threshold:  rule.Threshold,
+			FiredAt:    now,
+		}
+
+		// El cooldown también cuenta desde la alerta anterior del mismo
+		// objetivo, para no repetir notificaciones si la condición oscila
+		alert.LastNotifiedAt = s.lastNotified(rule.ID, o.targetID)
+		notify := cooldownElapsed(alert.LastNotifiedAt, rule.Cooldown(), now)
+		if notify {
+			alert.LastNotifiedAt = &now
+		}
+
+		if err := s.db.Create(alert).Error; err != nil {
+			s.logger.Error("Failed to create alert", zap.String("rule_id", rule.ID.String()), zap.Error(err))
+			return
+		}
+
+		s.logger.Info("Alert fired",
+			zap.String("rule_id", rule.ID.String()),
+			zap.String("target_id", o.targetID.String()),
+			zap.Float64("value", o.value),
+			zap.Bool("notified", notify),
+		)
+		if notify {
+			s.notify(rule, alert)
+		}
+		return
+	}
+
+	alert.Value = o.value
+	alert.Message = message
+	if alert.IsAcknowledged() || !cooldownElapsed(alert.LastNotifiedAt, rule.Cooldown(), now) {
+		s.db.Model(alert).Updates(map[string]interface{}{"value": alert.Value, "message": alert.Message})
+		return
+	}
+
+	alert.LastNotifiedAt = &now
+	if err := s.db.Save(alert).Error; err != nil {
+		s.logger.Error("Failed to update alert", zap.String("alert_id", alert.ID.String()), zap.Error(err))
+		return
+	}
+	s.notify(rule, alert)
+}
+
+// resolve marca una alerta como resuelta. Solo se notifica la resolución
+// si se notificó la alerta.
+func (s *Service) resolve(rule *models.AlertRule, alert *models.Alert, now time.Time) {
+	alert.Status = models.AlertStatusResolved
+	alert.ResolvedAt = &now
+	if err := s.db.Save(alert).Error; err != nil {
+		s.logger.Error("Failed to resolve alert", zap.String("alert_id", alert.ID.String()), zap.Error(err))
+		return
+	}
+
+	s.logger.Info("Alert resolved",
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("target_id", alert.TargetID.String()),
+	)
+
+	if alert.LastNotifiedAt != nil && !alert.LastNotifiedAt.Before(alert.FiredAt) {
+		s.notify(rule, alert)
+	}
+}
+
+// lastNotified retorna la última notificación de una regla para un objetivo
+func (s *Service) lastNotified(ruleID, targetID uuid.UUID) *time.Time {
+	var last models.Alert
+	err := s.db.Select("last_notified_at").
+		Where("rule_id = ? AND target_id = ? AND last_notified_at IS NOT NULL", ruleID, targetID).
+		Order("last_notified_at DESC").
+		Limit(1).Find(&last).Error
+	if err != nil {
+		return nil
+	}
+	return last.LastNotifiedAt
+}
+
+// cooldownElapsed indica si se puede volver a notificar
+func cooldownElapsed(lastNotified *time.Time, cooldown time.Duration, now time.Time) bool {
+	return lastNotified == nil || now.Sub(*lastNotified) >= cooldown
+}
+
+// describe genera el título y el mensaje de una alerta
+func describe(rule *models.AlertRule, o observation) (string, string) {
+	title := fmt.Sprintf("%s: %s", rule.Name, o.targetName)
+	window := formatDuration(rule.Window())
+
+	var message string
+	switch rule.Type {
+	case models.AlertRuleCPU:
+		message = fmt.Sprintf("CPU media de %.1f%% en los últimos %s (umbral %.0f%%)", o.value, window, rule.Threshold)
+	case models.AlertRuleMemory:
+		message = fmt.Sprintf("Memoria media al %.1f%% en los últimos %s (umbral %.0f%%)", o.value, window, rule.Threshold)
+	case models.AlertRuleTPS:
+		message = fmt.Sprintf("TPS medio de %.1f en los últimos %s (umbral %.1f)", o.value, window, rule.Threshold)
+	case models.AlertRuleDisk:
+		message = fmt.Sprintf("Disco al %.1f%% en los últimos %s (umbral %.0f%%)", o.value, window, rule.Threshold)
+	case models.AlertRuleAgentOffline:
+		if o.value > 0 {
+			message = fmt.Sprintf("El agente %s no responde desde hace %s", o.targetName,
+				formatDuration(time.Duration(o.value)*time.Second))
+		} else {
+			message = fmt.Sprintf("El agente %s no responde", o.targetName)
+		}
+	case models.AlertRuleCrashLoop:
+		message = fmt.Sprintf("%.0f crashes en los últimos %s (umbral %.0f)", o.value, window, rule.Threshold)
+	case models.AlertRuleBackupFailed:
+		message = o.message
+	}
+	return title, message
+}
+
+// formatDuration formatea una duración sin ceros finales ("5m", "1h30m")
+func formatDuration(d time.Duration) string {
+	text := d.Round(time.Second).String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
@@ -0,0 +1,140 @@
+package alerts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/aymc/backend/services/agents"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestBreaches(t *testing.T) {
+	tests := []struct {
+		ruleType  models.AlertRuleType
+		threshold float64
+		value     float64
+		want      bool
+	}{
+		{models.AlertRuleCPU, 90, 95, true},
+		{models.AlertRuleCPU, 90, 90, false},
+		{models.AlertRuleMemory, 80, 50, false},
+		{models.AlertRuleTPS, 15, 12.5, true},
+		{models.AlertRuleTPS, 15, 19.9, false},
+		{models.AlertRuleCrashLoop, 3, 3, true},
+		{models.AlertRuleCrashLoop, 3, 2, false},
+	}
+
+	for _, tt := range tests {
+		rule := &models.AlertRule{Type: tt.ruleType, Threshold: tt.threshold}
+		if got := breaches(rule, tt.value); got != tt.want {
+			t.Errorf("breaches(%s, threshold %v, value %v) = %v, want %v", tt.ruleType, tt.threshold, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestOfflineObservations(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	rule := &models.AlertRule{Type: models.AlertRuleAgentOffline, WindowSeconds: 120}
+
+	online := agents.AgentHealth{AgentID: uuid.New(), Status: agents.AgentStatusOnline, LastSeen: now}
+	recent := agents.AgentHealth{AgentID: uuid.New(), Status: agents.AgentStatusOffline, LastSeen: now.Add(-time.Minute)}
+	gone := agents.AgentHealth{AgentID: uuid.New(), Status: agents.AgentStatusOffline, LastSeen: now.Add(-5 * time.Minute)}
+	never := agents.AgentHealth{AgentID: uuid.New(), Status: agents.AgentStatusOffline}
+
+	observations := offlineObservations(rule, []agents.AgentHealth{online, recent, gone, never}, now)
+
+	want := map[uuid.UUID]bool{online.AgentID: false, recent.AgentID: false, gone.AgentID: true, never.AgentID: true}
+	for _, o := range observations {
+		if o.breached != want[o.targetID] {
+			t.Errorf("agent %s: breached = %v, want %v", o.targetID, o.breached, want[o.targetID])
+		}
+		if o.targetID == gone.AgentID && o.value != 300 {
+			t.Errorf("offline seconds = %v, want 300", o.value)
+		}
+	}
+}
+
+func TestCrashCountUsesWindow(t *testing.T) {
+	s := NewService(nil, nil, nil, Config{}, zap.NewNop())
+	serverID := uuid.New()
+	now := time.Now()
+
+	for _, ago := range []time.Duration{25 * time.Hour, 20 * time.Minute, 5 * time.Minute, time.Minute} {
+		s.HandleServerEvent(uuid.New(), serverID, "crashed", now.Add(-ago))
+	}
+	s.HandleServerEvent(uuid.New(), serverID, "stopped", now)
+
+	if got := s.crashCount(serverID, now.Add(-10*time.Minute)); got != 2 {
+		t.Errorf("crashes in 10m = %d, want 2", got)
+	}
+	if got := s.crashCount(serverID, now.Add(-48*time.Hour)); got != 3 {
+		t.Errorf("crashes kept = %d, want 3 (older than 24h are pruned)", got)
+	}
+	if got := s.crashCount(uuid.New(), now.Add(-time.Hour)); got != 0 {
+		t.Errorf("unknown server crashes = %d, want 0", got)
+	}
+}
+
+func TestCooldownElapsed(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	recent := now.Add(-10 * time.Minute)
+	old := now.Add(-time.Hour)
+
+	if !cooldownElapsed(nil, 30*time.Minute, now) {
+		t.Error("never notified: cooldown should be elapsed")
+	}
+	if cooldownElapsed(&recent, 30*time.Minute, now) {
+		t.Error("notified 10m ago: cooldown of 30m should not be elapsed")
+	}
+	if !cooldownElapsed(&old, 30*time.Minute, now) {
+		t.Error("notified 1h ago: cooldown of 30m should be elapsed")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		30 * time.Second:                   "30s",
+		10 * time.Minute:                   "10m",
+		2 * time.Hour:                      "2h",
+		90 * time.Minute:                   "1h30m",
+		2*time.Hour + 30*time.Second:       "2h0m30s",
+		time.Minute + 500*time.Millisecond: "1m1s",
+	} {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := []models.AlertRule{
+		{Type: models.AlertRuleCPU, TargetType: models.AlertTargetAgent, Threshold: 90, WindowSeconds: 300},
+		{Type: models.AlertRuleTPS, TargetType: models.AlertTargetServer, Threshold: 15, WindowSeconds: 60},
+		{Type: models.AlertRuleAgentOffline, TargetType: models.AlertTargetAgent},
+		{Type: models.AlertRuleBackupFailed, TargetType: models.AlertTargetServer},
+	}
+	for _, rule := range valid {
+		if err := validateRule(&rule); err != nil {
+			t.Errorf("validateRule(%s/%s): %v", rule.Type, rule.TargetType, err)
+		}
+	}
+
+	invalid := []models.AlertRule{
+		{Type: models.AlertRuleTPS, TargetType: models.AlertTargetAgent, Threshold: 15, WindowSeconds: 300},
+		{Type: models.AlertRuleAgentOffline, TargetType: models.AlertTargetServer},
+		{Type: models.AlertRuleMemory, TargetType: models.AlertTargetServer, Threshold: 150, WindowSeconds: 300},
+		{Type: models.AlertRuleTPS, TargetType: models.AlertTargetServer, Threshold: 25, WindowSeconds: 300},
+		{Type: models.AlertRuleCrashLoop, TargetType: models.AlertTargetServer, Threshold: 0, WindowSeconds: 600},
+		{Type: models.AlertRuleCPU, TargetType: models.AlertTargetServer, Threshold: 90, WindowSeconds: 10},
+		{Type: models.AlertRuleCPU, TargetType: models.AlertTargetServer, Threshold: 90, WindowSeconds: 2 * 86400},
+	}
+	for _, rule := range invalid {
+		if err := validateRule(&rule); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("validateRule(%s/%s, threshold %v, window %d): expected ErrInvalidRule, got %v",
+				rule.Type, rule.TargetType, rule.Threshold, rule.WindowSeconds, err)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aymc/backend/api/websocket"
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Notification contenido de una notificación de alerta. Es también el
+// cuerpo JSON que reciben los webhooks genéricos.
+type Notification struct {
+	AlertID    uuid.UUID            `json:"alert_id"`
+	RuleID     uuid.UUID            `json:"rule_id"`
+	RuleName   string               `json:"rule_name"`
+	RuleType   models.AlertRuleType `json:"rule_type"`
+	Status     models.AlertStatus   `json:"status"` // firing, resolved
+	Severity   models.AlertSeverity `json:"severity"`
+	Title      string               `json:"title"`
+	Message    string               `json:"message"`
+	TargetType string               `json:"target_type"`
+	TargetID   uuid.UUID            `json:"target_id"`
+	TargetName string               `json:"target_name"`
+	Value      float64              `json:"value"`
+	Threshold  float64              `json:"threshold"`
+	FiredAt    time.Time            `json:"fired_at"`
+	Timestamp  time.Time            `json:"timestamp"`
+}
+
+// Notifier entrega notificaciones a un tipo de canal
+type Notifier interface {
+	Send(ctx context.Context, channel *models.NotificationChannel, notification *Notification) error
+}
+
+// newNotification construye la notificación del estado actual de una alerta
+func newNotification(rule *models.AlertRule, alert *models.Alert) *Notification {
+	notification := &Notification{
+		AlertID:    alert.ID,
+		RuleID:     rule.ID,
+		RuleName:   rule.Name,
+		RuleType:   rule.Type,
+		Status:     alert.Status,
+		Severity:   alert.Severity,
+		Title:      alert.Title,
+		Message:    alert.Message,
+		TargetType: alert.TargetType,
+		TargetID:   alert.TargetID,
+		TargetName: alert.TargetName,
+		Value:      alert.Value,
+		Threshold:  alert.Threshold,
+		FiredAt:    alert.FiredAt,
+		Timestamp:  time.Now().UTC(),
+	}
+	if alert.Status == models.AlertStatusResolved {
+		notification.Severity = models.AlertSeverityInfo
+		notification.Title = "Resuelta: " + alert.Title
+	}
+	return notification
+}
+
+// notify publica una alerta por WebSocket y la entrega en segundo plano a
+// los canales activos de la regla
+func (s *Service) notify(rule *models.AlertRule, alert *models.Alert) {
+	notification := newNotification(rule, alert)
+
+	s.hub.BroadcastAlert(websocket.Alert{
+		ID:        alert.ID,
+		Severity:  string(notification.Severity),
+		Title:     notification.Title,
+		Message:   notification.Message,
+		Source:    alert.TargetType,
+		SourceID:  alert.TargetID,
+		Timestamp: notification.Timestamp,
+		Data: map[string]interface{}{
+			"rule_id":      rule.ID.String(),
+			"rule_name":    rule.Name,
+			"rule_type":    rule.Type,
+			"status":       alert.Status,
+			"value":        alert.Value,
+			"threshold":    alert.Threshold,
+			"acknowledged": alert.IsAcknowledged(),
+		},
+	})
+
+	for i := range rule.Channels {
+		channel := rule.Channels[i]
+		if !channel.Enabled {
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+			defer cancel()
+
+			if err := s.deliver(ctx, &channel, notification); err != nil {
+				s.logger.Warn("Failed to deliver alert notification",
+					zap.String("alert_id", alert.ID.String()),
+					zap.String("channel_id", channel.ID.String()),
+					zap.String("channel_type", string(channel.Type)),
+					zap.Error(err),
+				)
+			}
+		}()
+	}
+}
+
+// deliver entrega una notificación con el notificador del tipo de canal.
+// Las URLs se vuelven a validar porque el rol del dueño o la configuración
+// pueden haber cambiado desde que se guardó el canal.
+func (s *Service) deliver(ctx context.Context, channel *models.NotificationChannel, notification *Notification) error {
+	notifier, ok := s.notifiers[channel.Type]
+	if !ok {
+		return fmt.Errorf("tipo de canal no soportado: %s", channel.Type)
+	}
+
+	if channel.Type == models.NotificationChannelWebhook || channel.Type == models.NotificationChannelDiscord {
+		isAdmin, err := s.ownerIsAdmin(ctx, channel.UserID)
+		if err != nil {
+			return err
+		}
+		if err := validateWebhookURL(channel.URL, s.config.AllowHTTPWebhooks, isAdmin); err != nil {
+			return err
+		}
+		if isAdmin {
+			ctx = withInternalDestinations(ctx)
+		}
+	}
+
+	return notifier.Send(ctx, channel, notification)
+}
+
+// ownerIsAdmin indica si el dueño de un canal es administrador
+func (s *Service) ownerIsAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var owner models.User
+	if err := s.db.WithContext(ctx).Select("role").First(&owner, "id = ?", userID).Error; err != nil {
+		return false, fmt.Errorf("error cargando el dueño del canal: %w", err)
+	}
+	return owner.IsAdmin(), nil
+}
+
+// WebhookNotifier envía la notificación como JSON por POST. Si el canal
+// tiene secreto, la cabecera X-AYMC-Signature lleva el HMAC-SHA256 del
+// cuerpo ("sha256=<hex>").
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+// Send implementa Notifier
+func (n *WebhookNotifier) Send(ctx context.Context, channel *models.NotificationChannel, notification *Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{
+		"X-AYMC-Event": "alert." + string(notification.Status),
+	}
+	if channel.Secret != "" {
+		headers["X-AYMC-Signature"] = "sha256=" + signPayload(channel.Secret, body)
+	}
+
+	return postJSON(ctx, n.client, channel.URL, body, headers)
+}
+
+// signPayload calcula el HMAC-SHA256 de un cuerpo en hexadecimal
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DiscordNotifier envía la notificación como embed a un webhook de Discord
+type DiscordNotifier struct {
+	client *http.Client
+}
+
+// discordEmbed embed de un mensaje de webhook de Discord
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Color       int            `json:"color"`
+	Timestamp   string         `json:"timestamp"`
+	Fields      []discordField `json:"fields,omitempty"`
+}
+
+// discordField campo de un embed de Discord
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// Send implementa Notifier
+func (n *DiscordNotifier) Send(ctx context.Context, channel *models.NotificationChannel, notification *Notification) error {
+	payload := map[string]interface{}{
+		"username": "AYMC",
+		"embeds": []discordEmbed{{
+			Title:       truncate(notification.Title, 256),
+			Description: truncate(notification.Message, 4096),
+			Color:       severityColor(notification.Severity),
+			Timestamp:   notification.Timestamp.Format(time.RFC3339),
+			Fields: []discordField{
+				{Name: "Regla", Value: truncate(notification.RuleName, 1024), Inline: true},
+				{Name: "Severidad", Value: string(notification.Severity), Inline: true},
+				{Name: "Estado", Value: string(notification.Status), Inline: true},
+			},
+		}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.client, channel.URL, body, nil)
+}
+
+// severityColor color del embed de Discord según la severidad
+func severityColor(severity models.AlertSeverity) int {
+	switch severity {
+	case models.AlertSeverityCritical:
+		return 0x992D22
+	case models.AlertSeverityError:
+		return 0xE74C3C
+	case models.AlertSeverityWarning:
+		return 0xF1C40F
+	default:
+		return 0x2ECC71
+	}
+}
+
+// postJSON envía un cuerpo JSON por POST y falla si la respuesta no es 2xx
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AYMC-Alerts/1.0")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("respuesta %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// truncate recorta un texto a max caracteres
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
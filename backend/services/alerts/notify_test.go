@@ -0,0 +1,263 @@
+package alerts
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+)
+
+func testNotification() *Notification {
+	firedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	return &Notification{
+		AlertID:    uuid.New(),
+		RuleID:     uuid.New(),
+		RuleName:   "CPU alta",
+		RuleType:   models.AlertRuleCPU,
+		Status:     models.AlertStatusFiring,
+		Severity:   models.AlertSeverityCritical,
+		Title:      "CPU alta: survival",
+		Message:    "CPU media de 97.0% en los últimos 5m (umbral 90%)",
+		TargetType: models.AlertTargetServer,
+		TargetID:   uuid.New(),
+		TargetName: "survival",
+		Value:      97,
+		Threshold:  90,
+		FiredAt:    firedAt,
+		Timestamp:  firedAt,
+	}
+}
+
+func TestWebhookNotifierSignsPayload(t *testing.T) {
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	notifier := &WebhookNotifier{client: server.Client()}
+	channel := &models.NotificationChannel{Type: models.NotificationChannelWebhook, URL: server.URL, Secret: "s3cret"}
+	notification := testNotification()
+
+	if err := notifier.Send(context.Background(), channel, notification); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if got := header.Get("X-AYMC-Event"); got != "alert.firing" {
+		t.Errorf("X-AYMC-Event = %q, want alert.firing", got)
+	}
+	if got, want := header.Get("X-AYMC-Signature"), "sha256="+signPayload("s3cret", body); got != want {
+		t.Errorf("X-AYMC-Signature = %q, want %q", got, want)
+	}
+
+	var received Notification
+	if err := json.Unmarshal(body, &received); err != nil {
+		t.Fatalf("invalid JSON body: %v", err)
+	}
+	if received.AlertID != notification.AlertID || received.Value != 97 {
+		t.Errorf("unexpected body: %+v", received)
+	}
+}
+
+func TestWebhookNotifierFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	notifier := &WebhookNotifier{client: server.Client()}
+	channel := &models.NotificationChannel{Type: models.NotificationChannelWebhook, URL: server.URL}
+
+	err := notifier.Send(context.Background(), channel, testNotification())
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected 403 error, got %v", err)
+	}
+}
+
+func TestDiscordNotifierSendsEmbed(t *testing.T) {
+	var payload struct {
+		Username string         `json:"username"`
+		Embeds   []discordEmbed `json:"embeds"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid JSON body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := &DiscordNotifier{client: server.Client()}
+	channel := &models.NotificationChannel{Type: models.NotificationChannelDiscord, URL: server.URL}
+
+	if err := notifier.Send(context.Background(), channel, testNotification()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if payload.Username != "AYMC" || len(payload.Embeds) != 1 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	embed := payload.Embeds[0]
+	if embed.Title != "CPU alta: survival" || embed.Color != severityColor(models.AlertSeverityCritical) {
+		t.Errorf("unexpected embed: %+v", embed)
+	}
+	if embed.Timestamp != "2024-01-15T10:00:00Z" {
+		t.Errorf("timestamp = %q", embed.Timestamp)
+	}
+}
+
+// fakeSMTP acepta una única sesión SMTP sin TLS ni autenticación y publica
+// el sobre y el mensaje recibidos
+type fakeSMTP struct {
+	listener   net.Listener
+	from       string
+	recipients []string
+	data       string
+	done       chan struct{}
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	f := &fakeSMTP{listener: listener, done: make(chan struct{})}
+	go f.serve()
+	t.Cleanup(func() { listener.Close() })
+	return f
+}
+
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) serve() {
+	defer close(f.done)
+
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP fake")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250-localhost\r\n250 8BITMIME")
+		case "MAIL":
+			f.from = smtpPath(line)
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			f.recipients = append(f.recipients, smtpPath(line))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			f.data = string(data)
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+// smtpPath extrae la dirección de "MAIL FROM:<x>" o "RCPT TO:<x>"
+func smtpPath(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func TestEmailNotifierDeliversToLocalSMTP(t *testing.T) {
+	smtpServer := newFakeSMTP(t)
+
+	notifier := &EmailNotifier{config: SMTPConfig{Host: "127.0.0.1", Port: smtpServer.port(), From: "aymc@example.com"}}
+	channel := &models.NotificationChannel{
+		Type:       models.NotificationChannelEmail,
+		Recipients: "ops@example.com, Admin <admin@example.com>",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := notifier.Send(ctx, channel, testNotification()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-smtpServer.done
+
+	if smtpServer.from != "aymc@example.com" {
+		t.Errorf("MAIL FROM = %q", smtpServer.from)
+	}
+	if strings.Join(smtpServer.recipients, ",") != "ops@example.com,admin@example.com" {
+		t.Errorf("RCPT TO = %v", smtpServer.recipients)
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(smtpServer.data)))
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("invalid message header: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("invalid subject: %v", err)
+	}
+	if subject != "[AYMC] [CRITICAL] CPU alta: survival" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if !strings.Contains(smtpServer.data, "umbral 90%") {
+		t.Errorf("body does not contain the alert message:\n%s", smtpServer.data)
+	}
+}
+
+func TestEmailNotifierRequiresSMTP(t *testing.T) {
+	notifier := &EmailNotifier{}
+	channel := &models.NotificationChannel{Type: models.NotificationChannelEmail, Recipients: "ops@example.com"}
+
+	if err := notifier.Send(context.Background(), channel, testNotification()); err != ErrSMTPNotConfigured {
+		t.Fatalf("expected ErrSMTPNotConfigured, got %v", err)
+	}
+}
+
+func TestParseRecipients(t *testing.T) {
+	recipients, err := parseRecipients(" a@example.com,, B <b@example.com> ")
+	if err != nil {
+		t.Fatalf("parseRecipients: %v", err)
+	}
+	if strings.Join(recipients, ",") != "a@example.com,b@example.com" {
+		t.Errorf("got %v", recipients)
+	}
+
+	for _, invalid := range []string{"", " , ", "not-an-address"} {
+		if _, err := parseRecipients(invalid); err == nil {
+			t.Errorf("parseRecipients(%q): expected error", invalid)
+		}
+	}
+}
@@ -0,0 +1,562 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRuleNotFound     = errors.New("alert rule not found")
+	ErrChannelNotFound  = errors.New("notification channel not found")
+	ErrAlertNotFound    = errors.New("alert not found")
+	ErrServerNotFound   = errors.New("server not found")
+	ErrAgentNotFound    = errors.New("agent not found")
+	ErrInvalidRule      = errors.New("invalid alert rule")
+	ErrInvalidChannel   = errors.New("invalid notification channel")
+	ErrAlertResolved    = errors.New("alert is already resolved")
+	ErrDeliveryFailed   = errors.New("notification delivery failed")
+	errChannelOwnership = fmt.Errorf("%w: channels must belong to the rule owner", ErrInvalidRule)
+)
+
+const (
+	defaultWindowSeconds   = 300
+	defaultCooldownSeconds = 1800
+)
+
+// CreateRuleRequest represents the request to create an alert rule
+type CreateRuleRequest struct {
+	Name            string               `json:"name" validate:"required,min=3,max=100"`
+	Type            models.AlertRuleType `json:"type" validate:"required,oneof=cpu memory tps disk agent_offline crash_loop backup_failed"`
+	TargetType      string               `json:"target_type" validate:"required,oneof=server agent"`
+	TargetID        *uuid.UUID           `json:"target_id,omitempty"` // Omit for all servers of the user, or all agents
+	Threshold       float64              `json:"threshold" validate:"gte=0"`
+	WindowSeconds   *int                 `json:"window_seconds,omitempty" validate:"omitempty,min=0,max=86400"`
+	CooldownSeconds *int                 `json:"cooldown_seconds,omitempty" validate:"omitempty,min=60,max=604800"`
+	Severity        models.AlertSeverity `json:"severity,omitempty" validate:"omitempty,oneof=info warning error critical"`
+	Enabled         *bool                `json:"enabled,omitempty"`
+	ChannelIDs      []uuid.UUID          `json:"channel_ids,omitempty"`
+}
+
+// UpdateRuleRequest represents the request to update an alert rule. The
+// type and target cannot change: create a new rule instead.
+type UpdateRuleRequest struct {
+	Name            *string              `json:"name,omitempty" validate:"omitempty,min=3,max=100"`
+	Threshold       *float64             `json:"threshold,omitempty" validate:"omitempty,gte=0"`
+	WindowSeconds   *int                 `json:"window_seconds,omitempty" validate:"omitempty,min=0,max=86400"`
+	CooldownSeconds *int                 `json:"cooldown_seconds,omitempty" validate:"omitempty,min=60,max=604800"`
+	Severity        models.AlertSeverity `json:"severity,omitempty" validate:"omitempty,oneof=info warning error critical"`
+	Enabled         *bool                `json:"enabled,omitempty"`
+	ChannelIDs      *[]uuid.UUID         `json:"channel_ids,omitempty"`
+}
+
+// CreateChannelRequest represents the request to create a notification channel
+type CreateChannelRequest struct {
+	Name       string                         `json:"name" validate:"required,min=3,max=100"`
+	Type       models.NotificationChannelType `json:"type" validate:"required,oneof=webhook discord email"`
+	URL        string                         `json:"url,omitempty"`        // webhook, discord
+	Secret     string                         `json:"secret,omitempty"`     // webhook
+	Recipients string                         `json:"recipients,omitempty"` // email, comma-separated
+	Enabled    *bool                          `json:"enabled,omitempty"`
+}
+
+// UpdateChannelRequest represents the request to update a notification channel
+type UpdateChannelRequest struct {
+	Name       *string `json:"name,omitempty" validate:"omitempty,min=3,max=100"`
+	URL        *string `json:"url,omitempty"`
+	Secret     *string `json:"secret,omitempty"`
+	Recipients *string `json:"recipients,omitempty"`
+	Enabled    *bool   `json:"enabled,omitempty"`
+}
+
+// AlertFilter filters the alert list
+type AlertFilter struct {
+	Status   models.AlertStatus
+	TargetID *uuid.UUID
+	RuleID   *uuid.UUID
+	Limit    int
+	Offset   int
+}
+
+// AlertListResponse represents a page of fired alerts
+type AlertListResponse struct {
+	Alerts []models.Alert `json:"alerts"`
+	Total  int64          `json:"total"`
+}
+
+// owned restringe una consulta a los registros del usuario salvo para
+// administradores
+func owned(db *gorm.DB, userID uuid.UUID, isAdmin bool) *gorm.DB {
+	if isAdmin {
+		return db
+	}
+	return db.Where("user_id = ?", userID)
+}
+
+// ListRules lista las reglas de alertas del usuario
+func (s *Service) ListRules(ctx context.Context, userID uuid.UUID, isAdmin bool) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := owned(s.db.WithContext(ctx), userID, isAdmin).
+		Preload("Channels").Order("created_at").Find(&rules).Error
+	return rules, err
+}
+
+// GetRule obtiene una regla de alertas
+func (s *Service) GetRule(ctx context.Context, ruleID, userID uuid.UUID, isAdmin bool) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	err := owned(s.db.WithContext(ctx), userID, isAdmin).
+		Preload("Channels").First(&rule, "id = ?", ruleID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// CreateRule crea una regla de alertas
+func (s *Service) CreateRule(ctx context.Context, userID uuid.UUID, isAdmin bool, req *CreateRuleRequest) (*models.AlertRule, error) {
+	rule := &models.AlertRule{
+		UserID:          userID,
+		Name:            req.Name,
+		Type:            req.Type,
+		TargetType:      req.TargetType,
+		TargetID:        req.TargetID,
+		Threshold:       req.Threshold,
+		WindowSeconds:   defaultWindowSeconds,
+		CooldownSeconds: defaultCooldownSeconds,
+		Severity:        req.Severity,
+		Enabled:         true,
+	}
+	if rule.Type == models.AlertRuleAgentOffline {
+		rule.WindowSeconds = 0
+	}
+	if req.WindowSeconds != nil {
+		rule.WindowSeconds = *req.WindowSeconds
+	}
+	if req.CooldownSeconds != nil {
+		rule.CooldownSeconds = *req.CooldownSeconds
+	}
+	if rule.Severity == "" {
+		rule.Severity = models.AlertSeverityWarning
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+	if err := s.checkTarget(ctx, rule, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	channels, err := s.ruleChannels(ctx, userID, req.ChannelIDs)
+	if err != nil {
+		return nil, err
+	}
+	rule.Channels = channels
+
+	if err := s.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// UpdateRule actualiza una regla de alertas
+func (s *Service) UpdateRule(ctx context.Context, ruleID, userID uuid.UUID, isAdmin bool, req *UpdateRuleRequest) (*models.AlertRule, error) {
+	rule, err := s.GetRule(ctx, ruleID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+	if req.WindowSeconds != nil {
+		rule.WindowSeconds = *req.WindowSeconds
+	}
+	if req.CooldownSeconds != nil {
+		rule.CooldownSeconds = *req.CooldownSeconds
+	}
+	if req.Severity != "" {
+		rule.Severity = req.Severity
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	var channels []models.NotificationChannel
+	if req.ChannelIDs != nil {
+		if channels, err = s.ruleChannels(ctx, rule.UserID, *req.ChannelIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Channels").Save(rule).Error; err != nil {
+			return err
+		}
+		if req.ChannelIDs != nil {
+			if err := tx.Model(rule).Association("Channels").Replace(channels); err != nil {
+				return err
+			}
+			rule.Channels = channels
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule elimina una regla y sus alertas
+func (s *Service) DeleteRule(ctx context.Context, ruleID, userID uuid.UUID, isAdmin bool) error {
+	rule, err := s.GetRule(ctx, ruleID, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", rule.ID).Delete(&models.Alert{}).Error; err != nil {
+			return err
+		}
+		return tx.Select("Channels").Delete(rule).Error
+	})
+}
+
+// validateRule comprueba que el tipo, el objetivo y el umbral de una regla
+// son coherentes
+func validateRule(rule *models.AlertRule) error {
+	switch rule.Type {
+	case models.AlertRuleTPS, models.AlertRuleCrashLoop, models.AlertRuleBackupFailed:
+		if rule.TargetType != models.AlertTargetServer {
+			return fmt.Errorf("%w: %s rules only apply to servers", ErrInvalidRule, rule.Type)
+		}
+	case models.AlertRuleAgentOffline:
+		if rule.TargetType != models.AlertTargetAgent {
+			return fmt.Errorf("%w: %s rules only apply to agents", ErrInvalidRule, rule.Type)
+		}
+	}
+
+	switch rule.Type {
+	case models.AlertRuleCPU:
+		if rule.Threshold <= 0 {
+			return fmt.Errorf("%w: threshold must be a CPU percentage above 0", ErrInvalidRule)
+		}
+	case models.AlertRuleMemory, models.AlertRuleDisk:
+		if rule.Threshold <= 0 || rule.Threshold > 100 {
+			return fmt.Errorf("%w: threshold must be a percentage between 0 and 100", ErrInvalidRule)
+		}
+	case models.AlertRuleTPS:
+		if rule.Threshold <= 0 || rule.Threshold > 20 {
+			return fmt.Errorf("%w: threshold must be a TPS value between 0 and 20", ErrInvalidRule)
+		}
+	case models.AlertRuleCrashLoop:
+		if rule.Threshold < 1 {
+			return fmt.Errorf("%w: threshold must be a number of crashes of at least 1", ErrInvalidRule)
+		}
+	}
+
+	// Las métricas se muestrean cada 30s por defecto: ventanas más cortas
+	// no tendrían muestras
+	switch rule.Type {
+	case models.AlertRuleAgentOffline, models.AlertRuleBackupFailed:
+	default:
+		if rule.WindowSeconds < 60 {
+			return fmt.Errorf("%w: window must be at least 60 seconds", ErrInvalidRule)
+		}
+	}
+	if rule.Window() > maxWindow {
+		return fmt.Errorf("%w: window must be at most %s", ErrInvalidRule, maxWindow)
+	}
+
+	return nil
+}
+
+// checkTarget comprueba que el objetivo de una regla existe y que el
+// usuario tiene acceso a él
+func (s *Service) checkTarget(ctx context.Context, rule *models.AlertRule, userID uuid.UUID, isAdmin bool) error {
+	if rule.TargetID == nil {
+		return nil
+	}
+
+	db := s.db.WithContext(ctx)
+	if rule.TargetType == models.AlertTargetAgent {
+		var count int64
+		if err := db.Model(&models.Agent{}).Where("id = ?", *rule.TargetID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrAgentNotFound
+		}
+		return nil
+	}
+
+	var count int64
+	if err := owned(db.Model(&models.Server{}), userID, isAdmin).Where("id = ?", *rule.TargetID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrServerNotFound
+	}
+	return nil
+}
+
+// ruleChannels carga los canales de una regla, que deben ser del dueño
+// de la regla
+func (s *Service) ruleChannels(ctx context.Context, ownerID uuid.UUID, ids []uuid.UUID) ([]models.NotificationChannel, error) {
+	channels := []models.NotificationChannel{}
+	if len(ids) == 0 {
+		return channels, nil
+	}
+
+	err := s.db.WithContext(ctx).Where("id IN ? AND user_id = ?", ids, ownerID).Find(&channels).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(channels) != len(uniqueIDs(ids)) {
+		return nil, errChannelOwnership
+	}
+	return channels, nil
+}
+
+// uniqueIDs elimina los IDs repetidos
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// ListChannels lista los canales de notificación del usuario
+func (s *Service) ListChannels(ctx context.Context, userID uuid.UUID, isAdmin bool) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	err := owned(s.db.WithContext(ctx), userID, isAdmin).Order("created_at").Find(&channels).Error
+	return channels, err
+}
+
+// GetChannel obtiene un canal de notificación
+func (s *Service) GetChannel(ctx context.Context, channelID, userID uuid.UUID, isAdmin bool) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	err := owned(s.db.WithContext(ctx), userID, isAdmin).First(&channel, "id = ?", channelID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChannelNotFound
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// CreateChannel crea un canal de notificación
+func (s *Service) CreateChannel(ctx context.Context, userID uuid.UUID, req *CreateChannelRequest) (*models.NotificationChannel, error) {
+	channel := &models.NotificationChannel{
+		UserID:     userID,
+		Name:       req.Name,
+		Type:       req.Type,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		Recipients: req.Recipients,
+		Enabled:    true,
+	}
+	if req.Enabled != nil {
+		channel.Enabled = *req.Enabled
+	}
+
+	ownerIsAdmin, err := s.ownerIsAdmin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateChannel(channel, ownerIsAdmin); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(channel).Error; err != nil {
+		return nil, fmt.Errorf("failed to create notification channel: %w", err)
+	}
+	return channel, nil
+}
+
+// UpdateChannel actualiza un canal de notificación
+func (s *Service) UpdateChannel(ctx context.Context, channelID, userID uuid.UUID, isAdmin bool, req *UpdateChannelRequest) (*models.NotificationChannel, error) {
+	channel, err := s.GetChannel(ctx, channelID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		channel.Name = *req.Name
+	}
+	if req.URL != nil {
+		channel.URL = *req.URL
+	}
+	if req.Secret != nil {
+		channel.Secret = *req.Secret
+	}
+	if req.Recipients != nil {
+		channel.Recipients = *req.Recipients
+	}
+	if req.Enabled != nil {
+		channel.Enabled = *req.Enabled
+	}
+
+	// Lo que se permite depende del dueño, no de quien edita
+	ownerIsAdmin, err := s.ownerIsAdmin(ctx, channel.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateChannel(channel, ownerIsAdmin); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Save(channel).Error; err != nil {
+		return nil, fmt.Errorf("failed to update notification channel: %w", err)
+	}
+	return channel, nil
+}
+
+// DeleteChannel elimina un canal de notificación y lo quita de las reglas
+func (s *Service) DeleteChannel(ctx context.Context, channelID, userID uuid.UUID, isAdmin bool) error {
+	channel, err := s.GetChannel(ctx, channelID, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM alert_rule_channels WHERE notification_channel_id = ?", channel.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(channel).Error
+	})
+}
+
+// TestChannel envía una notificación de prueba a un canal y espera el
+// resultado de la entrega
+func (s *Service) TestChannel(ctx context.Context, channelID, userID uuid.UUID, isAdmin bool) error {
+	channel, err := s.GetChannel(ctx, channelID, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	notification := &Notification{
+		AlertID:    uuid.New(),
+		RuleName:   "Prueba",
+		Status:     models.AlertStatusFiring,
+		Severity:   models.AlertSeverityInfo,
+		Title:      "Notificación de prueba de AYMC",
+		Message:    fmt.Sprintf("El canal %q está configurado correctamente.", channel.Name),
+		TargetType: "system",
+		FiredAt:    now,
+		Timestamp:  now,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	if err := s.deliver(ctx, channel, notification); err != nil {
+		return fmt.Errorf("%w: %v", ErrDeliveryFailed, err)
+	}
+	return nil
+}
+
+// validateChannel comprueba los datos de entrega según el tipo de canal.
+// Solo los canales de administradores pueden apuntar a la red interna.
+func (s *Service) validateChannel(channel *models.NotificationChannel, ownerIsAdmin bool) error {
+	switch channel.Type {
+	case models.NotificationChannelWebhook, models.NotificationChannelDiscord:
+		if err := validateWebhookURL(channel.URL, s.config.AllowHTTPWebhooks, ownerIsAdmin); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+		}
+	case models.NotificationChannelEmail:
+		if s.config.SMTP.Host == "" {
+			return fmt.Errorf("%w: %v", ErrInvalidChannel, ErrSMTPNotConfigured)
+		}
+		recipients, err := parseRecipients(channel.Recipients)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+		}
+		channel.Recipients = strings.Join(recipients, ",")
+	}
+	return nil
+}
+
+// ListAlerts lista las alertas disparadas, las más recientes primero
+func (s *Service) ListAlerts(ctx context.Context, userID uuid.UUID, isAdmin bool, filter AlertFilter) (*AlertListResponse, error) {
+	query := owned(s.db.WithContext(ctx).Model(&models.Alert{}), userID, isAdmin)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.RuleID != nil {
+		query = query.Where("rule_id = ?", *filter.RuleID)
+	}
+
+	response := &AlertListResponse{Alerts: []models.Alert{}}
+	if err := query.Count(&response.Total).Error; err != nil {
+		return nil, err
+	}
+
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+	err := query.Order("fired_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&response.Alerts).Error
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AcknowledgeAlert reconoce una alerta activa: deja de recordarse hasta que
+// se resuelva
+func (s *Service) AcknowledgeAlert(ctx context.Context, alertID, userID uuid.UUID, isAdmin bool) (*models.Alert, error) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	var alert models.Alert
+	err := owned(s.db.WithContext(ctx), userID, isAdmin).First(&alert, "id = ?", alertID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlertNotFound
+		}
+		return nil, err
+	}
+
+	if !alert.IsFiring() {
+		return nil, ErrAlertResolved
+	}
+	if alert.IsAcknowledged() {
+		return &alert, nil
+	}
+
+	now := time.Now().UTC()
+	alert.AcknowledgedAt = &now
+	alert.AcknowledgedBy = &userID
+	if err := s.db.WithContext(ctx).Save(&alert).Error; err != nil {
+		return nil, fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+	return &alert, nil
+}
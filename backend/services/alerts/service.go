@@ -0,0 +1,157 @@
+package alerts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aymc/backend/api/websocket"
+	"github.com/aymc/backend/database/models"
+	"github.com/aymc/backend/services/agents"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxWindow ventana máxima de una regla; también limita cuánto se
+	// conserva el historial de crashes en memoria
+	maxWindow = 24 * time.Hour
+
+	// deliveryTimeout tiempo máximo para entregar una notificación a un canal
+	deliveryTimeout = 15 * time.Second
+)
+
+// Config define la evaluación de reglas, el servidor SMTP de los canales
+// de email y si los webhooks pueden usar http sin TLS
+type Config struct {
+	EvaluationInterval time.Duration
+	SMTP               SMTPConfig
+	AllowHTTPWebhooks  bool
+}
+
+// Service evalúa las reglas de alertas, mantiene el estado de las alertas
+// disparadas (cooldown, reconocimiento, resolución) y entrega las
+// notificaciones por WebSocket y por los canales de cada regla
+type Service struct {
+	db        *gorm.DB
+	hub       *websocket.Hub
+	health    *agents.HealthMonitor
+	config    Config
+	notifiers map[models.NotificationChannelType]Notifier
+	logger    *zap.Logger
+
+	// Serializa los cambios de estado de las alertas entre la evaluación
+	// periódica y los eventos de backups
+	stateMu sync.Mutex
+
+	// Instantes de los crashes recientes de cada servidor
+	crashMu sync.Mutex
+	crashes map[uuid.UUID][]time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService crea el servicio de alertas
+func NewService(db *gorm.DB, hub *websocket.Hub, health *agents.HealthMonitor, config Config, logger *zap.Logger) *Service {
+	if config.EvaluationInterval <= 0 {
+		config.EvaluationInterval = 30 * time.Second
+	}
+
+	client := newWebhookClient()
+	return &Service{
+		db:     db,
+		hub:    hub,
+		health: health,
+		config: config,
+		notifiers: map[models.NotificationChannelType]Notifier{
+			models.NotificationChannelWebhook: &WebhookNotifier{client: client},
+			models.NotificationChannelDiscord: &DiscordNotifier{client: client},
+			models.NotificationChannelEmail:   &EmailNotifier{config: config.SMTP},
+		},
+		logger:  logger.With(zap.String("component", "alerts")),
+		crashes: make(map[uuid.UUID][]time.Time),
+	}
+}
+
+// SetNotifier registra (o reemplaza) el notificador de un tipo de canal
+func (s *Service) SetNotifier(channelType models.NotificationChannelType, notifier Notifier) {
+	s.notifiers[channelType] = notifier
+}
+
+// Start inicia la evaluación periódica de las reglas
+func (s *Service) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.logger.Info("Starting alert rules evaluation",
+		zap.Duration("interval", s.config.EvaluationInterval),
+		zap.Bool("smtp", s.config.SMTP.Host != ""),
+	)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.config.EvaluationInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.evaluate(ctx, now.UTC())
+			}
+		}
+	}()
+}
+
+// Stop detiene la evaluación y espera a que terminen las entregas en curso
+func (s *Service) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("Alert rules evaluation stopped")
+}
+
+// HandleServerEvent registra los crashes que reportan los agentes para las
+// reglas crash_loop
+func (s *Service) HandleServerEvent(agentID, serverID uuid.UUID, eventType string, timestamp time.Time) {
+	if eventType != "crashed" {
+		return
+	}
+
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	s.crashMu.Lock()
+	defer s.crashMu.Unlock()
+
+	cutoff := time.Now().Add(-maxWindow)
+	crashes := s.crashes[serverID][:0]
+	for _, at := range s.crashes[serverID] {
+		if at.After(cutoff) {
+			crashes = append(crashes, at)
+		}
+	}
+	s.crashes[serverID] = append(crashes, timestamp)
+}
+
+// crashCount retorna los crashes de un servidor desde since
+func (s *Service) crashCount(serverID uuid.UUID, since time.Time) int {
+	s.crashMu.Lock()
+	defer s.crashMu.Unlock()
+
+	count := 0
+	for _, at := range s.crashes[serverID] {
+		if !at.Before(since) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,183 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	// s3DefaultPartSize tamaño de cada parte en subidas multipart
+	s3DefaultPartSize = 16 * 1024 * 1024
+
+	// s3MinPartSize mínimo que exige S3 para todas las partes menos la última
+	s3MinPartSize = 5 * 1024 * 1024
+)
+
+// S3Config configuración de un almacenamiento compatible con S3
+// (AWS S3, MinIO, Backblaze B2, etc.)
+type S3Config struct {
+	Endpoint  string // ej: https://s3.amazonaws.com o http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Prefix    string // Prefijo opcional para todas las claves
+	PathStyle bool   // Bucket en la ruta en lugar del host (MinIO)
+	PartSize  int64  // Tamaño de parte en subidas multipart
+}
+
+// S3Storage guarda los backups en un bucket S3 usando el cliente de MinIO
+type S3Storage struct {
+	config S3Config
+	client *minio.Client
+}
+
+// NewS3Storage crea un almacenamiento S3
+func NewS3Storage(config S3Config) (*S3Storage, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint de S3 requerido")
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("bucket de S3 requerido")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("credenciales de S3 requeridas")
+	}
+
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("endpoint de S3 inválido: %s", config.Endpoint)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("endpoint de S3 inválido: %s", config.Endpoint)
+	}
+	if strings.Trim(endpoint.Path, "/") != "" {
+		return nil, fmt.Errorf("el endpoint de S3 no puede incluir una ruta: %s", config.Endpoint)
+	}
+
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.PartSize <= 0 {
+		config.PartSize = s3DefaultPartSize
+	}
+	if config.PartSize < s3MinPartSize {
+		config.PartSize = s3MinPartSize
+	}
+
+	lookup := minio.BucketLookupDNS
+	if config.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure:       endpoint.Scheme == "https",
+		Region:       config.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creando cliente S3: %w", err)
+	}
+
+	return &S3Storage{
+		config: config,
+		client: client,
+	}, nil
+}
+
+// Type retorna el tipo de almacenamiento
+func (s *S3Storage) Type() string {
+	return StorageTypeS3
+}
+
+// Put sube el backup. Archivos de tamaño desconocido o mayores que una
+// parte se suben con multipart para no cargarlos completos en memoria.
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutObject(ctx, s.config.Bucket, objectKey, r, size, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+		PartSize:    uint64(s.config.PartSize),
+	})
+	if err != nil {
+		return s3Error(err)
+	}
+
+	return nil
+}
+
+// Get descarga el backup
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := s.client.GetObject(ctx, s.config.Bucket, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+
+	// GetObject es perezoso: Stat hace la petición y detecta objetos inexistentes
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, s3Error(err)
+	}
+
+	return object, nil
+}
+
+// Delete elimina el backup del bucket
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+
+	err = s.client.RemoveObject(ctx, s.config.Bucket, objectKey, minio.RemoveObjectOptions{})
+	if err != nil {
+		if err := s3Error(err); err != ErrObjectNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// objectKey agrega el prefijo configurado a una clave
+func (s *S3Storage) objectKey(key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	if prefix := strings.Trim(s.config.Prefix, "/"); prefix != "" {
+		cleaned = prefix + "/" + cleaned
+	}
+
+	return cleaned, nil
+}
+
+// s3Error traduce los errores de objeto inexistente a ErrObjectNotFound e
+// incluye el código de S3 en el resto
+func s3Error(err error) error {
+	response := minio.ToErrorResponse(err)
+	switch {
+	case response.Code == "NoSuchKey":
+		return ErrObjectNotFound
+	case response.Code != "":
+		return fmt.Errorf("S3 %s: %w", response.Code, err)
+	default:
+		return fmt.Errorf("error de S3: %w", err)
+	}
+}
@@ -0,0 +1,327 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testS3AccessKey = "minioadmin"
+	testS3SecretKey = "minioadmin-secret"
+	testS3Bucket    = "aymc-backups"
+)
+
+// fakeS3 imita el subconjunto de la API de MinIO/S3 que usa S3Storage.
+// Solo acepta la clave de acceso de prueba; la firma la calcula minio-go.
+type fakeS3 struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+	nextID  int
+}
+
+func newFakeS3(t *testing.T) *fakeS3 {
+	return &fakeS3{
+		t:       t,
+		objects: make(map[string][]byte),
+		uploads: make(map[string]map[int][]byte),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Authorization"), "Credential="+testS3AccessKey+"/") {
+		writeFakeS3Error(w, http.StatusForbidden, "InvalidAccessKeyId", "clave de acceso desconocida")
+		return
+	}
+
+	bucketPrefix := "/" + testS3Bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, bucketPrefix) {
+		writeFakeS3Error(w, http.StatusNotFound, "NoSuchBucket", "bucket inexistente")
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, bucketPrefix)
+	query := r.URL.Query()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		uploadID := fmt.Sprintf("upload-%d", f.nextID)
+		f.uploads[uploadID] = make(map[int][]byte)
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>",
+			testS3Bucket, key, uploadID)
+
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeFakeS3Error(w, http.StatusNotFound, "NoSuchUpload", "upload inexistente")
+			return
+		}
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		data := readFakeS3Body(f.t, r)
+		parts[partNumber] = data
+		w.Header().Set("ETag", fmt.Sprintf("\"etag-%d\"", partNumber))
+
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		uploadID := query.Get("uploadId")
+		parts, ok := f.uploads[uploadID]
+		if !ok {
+			writeFakeS3Error(w, http.StatusNotFound, "NoSuchUpload", "upload inexistente")
+			return
+		}
+
+		var complete struct {
+			Parts []struct {
+				PartNumber int    `xml:"PartNumber"`
+				ETag       string `xml:"ETag"`
+			} `xml:"Part"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&complete); err != nil || len(complete.Parts) != len(parts) {
+			writeFakeS3Error(w, http.StatusBadRequest, "InvalidPart", "partes incompletas")
+			return
+		}
+
+		numbers := make([]int, 0, len(parts))
+		for n := range parts {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+
+		var object bytes.Buffer
+		for _, n := range numbers {
+			object.Write(parts[n])
+		}
+		f.objects[key] = object.Bytes()
+		delete(f.uploads, uploadID)
+		fmt.Fprintf(w, "<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><ETag>\"etag-%s\"</ETag></CompleteMultipartUploadResult>",
+			testS3Bucket, key, uploadID)
+
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPut:
+		f.objects[key] = readFakeS3Body(f.t, r)
+
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			writeFakeS3Error(w, http.StatusNotFound, "NoSuchKey", "objeto inexistente")
+			return
+		}
+		w.Header().Set("ETag", "\"etag\"")
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeFakeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+// readFakeS3Body lee el cuerpo de una subida. Sin TLS minio-go firma cada
+// fragmento (aws-chunked): "<tamaño hex>;chunk-signature=...\r\n<datos>\r\n"
+func readFakeS3Body(t *testing.T, r *http.Request) []byte {
+	if !strings.HasPrefix(r.Header.Get("x-amz-content-sha256"), "STREAMING-") {
+		data, _ := io.ReadAll(r.Body)
+		return data
+	}
+
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			t.Errorf("Fragmento aws-chunked inválido: %v", err)
+			return data.Bytes()
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			t.Errorf("Tamaño de fragmento inválido %q", header)
+			return data.Bytes()
+		}
+		if size == 0 {
+			return data.Bytes()
+		}
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			t.Errorf("Fragmento incompleto: %v", err)
+			return data.Bytes()
+		}
+		reader.Discard(2) // \r\n
+	}
+}
+
+func (f *fakeS3) uploadCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.uploads)
+}
+
+func writeFakeS3Error(w http.ResponseWriter, status int, code, message string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
+
+func newTestS3Storage(t *testing.T, endpoint string) *S3Storage {
+	storage, err := NewS3Storage(S3Config{
+		Endpoint:  endpoint,
+		Bucket:    testS3Bucket,
+		AccessKey: testS3AccessKey,
+		SecretKey: testS3SecretKey,
+		Prefix:    "aymc",
+		PathStyle: true,
+		PartSize:  s3MinPartSize,
+	})
+	if err != nil {
+		t.Fatalf("Error creando almacenamiento S3: %v", err)
+	}
+	return storage
+}
+
+func TestS3StorageRoundTrip(t *testing.T) {
+	fake := newFakeS3(t)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	storage := newTestS3Storage(t, server.URL)
+	ctx := context.Background()
+	content := []byte("contenido del backup")
+
+	if err := storage.Put(ctx, "server-1/backup 1.tar.gz", bytes.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Error en Put: %v", err)
+	}
+	if _, exists := fake.objects["aymc/server-1/backup 1.tar.gz"]; !exists {
+		t.Fatalf("El objeto debería guardarse con el prefijo configurado, objetos: %v", fake.objects)
+	}
+
+	reader, err := storage.Get(ctx, "server-1/backup 1.tar.gz")
+	if err != nil {
+		t.Fatalf("Error en Get: %v", err)
+	}
+	got, _ := io.ReadAll(reader)
+	reader.Close()
+	if !bytes.Equal(got, content) {
+		t.Errorf("Contenido descargado distinto: %q", got)
+	}
+
+	if err := storage.Delete(ctx, "server-1/backup 1.tar.gz"); err != nil {
+		t.Fatalf("Error en Delete: %v", err)
+	}
+	if _, err := storage.Get(ctx, "server-1/backup 1.tar.gz"); err != ErrObjectNotFound {
+		t.Errorf("Se esperaba ErrObjectNotFound tras eliminar, obtenido %v", err)
+	}
+}
+
+func TestS3StorageMultipartUpload(t *testing.T) {
+	fake := newFakeS3(t)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	storage := newTestS3Storage(t, server.URL)
+	ctx := context.Background()
+
+	// Dos partes completas y una parcial, tamaño desconocido
+	content := make([]byte, 2*s3MinPartSize+1234)
+	rand.Read(content)
+
+	if err := storage.Put(ctx, "server-1/big.tar.gz", bytes.NewReader(content), -1); err != nil {
+		t.Fatalf("Error en Put multipart: %v", err)
+	}
+
+	if !bytes.Equal(fake.objects["aymc/server-1/big.tar.gz"], content) {
+		t.Error("El objeto ensamblado no coincide con el original")
+	}
+	if fake.uploadCount() != 0 {
+		t.Errorf("No deberían quedar subidas pendientes, quedan %d", fake.uploadCount())
+	}
+}
+
+func TestS3StorageRejectsBadCredentials(t *testing.T) {
+	server := httptest.NewServer(newFakeS3(t))
+	defer server.Close()
+
+	storage, _ := NewS3Storage(S3Config{
+		Endpoint:  server.URL,
+		Bucket:    testS3Bucket,
+		AccessKey: "desconocida",
+		SecretKey: testS3SecretKey,
+		PathStyle: true,
+	})
+
+	err := storage.Put(context.Background(), "server-1/a.tar.gz", strings.NewReader("x"), 1)
+	if err == nil || !strings.Contains(err.Error(), "InvalidAccessKeyId") {
+		t.Errorf("Se esperaba InvalidAccessKeyId, obtenido %v", err)
+	}
+}
+
+func TestS3StorageRejectsTraversal(t *testing.T) {
+	storage := newTestS3Storage(t, "http://localhost:9000")
+
+	if err := storage.Delete(context.Background(), "../otro-bucket/archivo"); err == nil {
+		t.Error("Las claves con .. deberían rechazarse")
+	}
+}
+
+// TestS3StorageMinIO ejecuta el mismo ciclo contra un MinIO real cuando
+// se configura AYMC_TEST_S3_ENDPOINT (ej: docker run minio/minio server /data)
+func TestS3StorageMinIO(t *testing.T) {
+	endpoint := os.Getenv("AYMC_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("AYMC_TEST_S3_ENDPOINT no configurado")
+	}
+
+	storage, err := NewS3Storage(S3Config{
+		Endpoint:  endpoint,
+		Bucket:    os.Getenv("AYMC_TEST_S3_BUCKET"),
+		AccessKey: os.Getenv("AYMC_TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("AYMC_TEST_S3_SECRET_KEY"),
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("Error creando almacenamiento S3: %v", err)
+	}
+
+	ctx := context.Background()
+	key := fmt.Sprintf("aymc-test/%d.bin", time.Now().UnixNano())
+	content := []byte("backup de prueba")
+
+	if err := storage.Put(ctx, key, bytes.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Error en Put: %v", err)
+	}
+	defer storage.Delete(ctx, key)
+
+	reader, err := storage.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Error en Get: %v", err)
+	}
+	defer reader.Close()
+
+	got, _ := io.ReadAll(reader)
+	if !bytes.Equal(got, content) {
+		t.Errorf("Contenido descargado distinto: %q", got)
+	}
+}
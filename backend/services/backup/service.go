@@ -2,11 +2,17 @@ package backup
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"path"
 	"path/filepath"
 	"time"
 
 	"github.com/aymc/backend/database/models"
+	"github.com/aymc/backend/pkg/telemetry"
+	pb "github.com/aymc/backend/proto"
 	"github.com/aymc/backend/services/agents"
 
 	"github.com/google/uuid"
@@ -19,20 +25,55 @@ type Service struct {
 	db           *gorm.DB
 	agentService *agents.AgentService
 	logger       *zap.Logger
-	backupDir    string // Directorio base para almacenar backups
+	backupDir    string             // Directorio base para almacenar backups
+	storages     map[string]Storage // Almacenamientos disponibles por tipo
+	onResult     ResultHandler
 }
 
-// NewService crea una nueva instancia del servicio de backups
+// ResultHandler recibe cada backup al terminar, completado o fallido
+type ResultHandler func(backup *models.Backup)
+
+// NewService crea una nueva instancia del servicio de backups. El
+// almacenamiento local en backupDir siempre está disponible.
 func NewService(db *gorm.DB, agentService *agents.AgentService, logger *zap.Logger, backupDir string) *Service {
 	return &Service{
 		db:           db,
 		agentService: agentService,
 		logger:       logger,
 		backupDir:    backupDir,
+		storages: map[string]Storage{
+			StorageTypeLocal: NewLocalStorage(backupDir),
+		},
+	}
+}
+
+// SetStorage registra (o reemplaza) un almacenamiento de backups
+func (s *Service) SetStorage(storage Storage) {
+	s.storages[storage.Type()] = storage
+}
+
+// SetResultHandler registra una función que recibe el resultado de cada
+// backup (alertas, notificaciones)
+func (s *Service) SetResultHandler(handler ResultHandler) {
+	s.onResult = handler
+}
+
+// storageFor retorna el almacenamiento de un tipo. Un tipo vacío equivale
+// al almacenamiento local.
+func (s *Service) storageFor(storageType string) (Storage, error) {
+	if storageType == "" {
+		storageType = StorageTypeLocal
+	}
+
+	storage, ok := s.storages[storageType]
+	if !ok {
+		return nil, fmt.Errorf("almacenamiento de backups %q no configurado", storageType)
 	}
+	return storage, nil
 }
 
-// CreateBackup crea un nuevo backup de un servidor
+// CreateBackup crea un nuevo backup de un servidor. El registro se crea
+// en estado "in_progress" y el agente genera el archivo en segundo plano.
 func (s *Service) CreateBackup(ctx context.Context, req *models.CreateBackupRequest, userID uuid.UUID) (*models.Backup, error) {
 	s.logger.Info("Creating backup",
 		zap.String("server_id", req.ServerID.String()),
@@ -46,18 +87,34 @@ func (s *Service) CreateBackup(ctx context.Context, req *models.CreateBackupRequ
 		return nil, fmt.Errorf("servidor no encontrado: %w", err)
 	}
 
-	// Crear registro de backup en DB
+	backup, err := s.createBackupRecord(req, &server, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// El backup puede durar más que la petición HTTP que lo originó
+	go s.executeBackup(context.WithoutCancel(ctx), backup, &server)
+
+	return backup, nil
+}
+
+// createBackupRecord registra un backup nuevo en estado "in_progress".
+// Path es la ruta temporal relativa al directorio de backups del agente
+// hasta que el archivo se transfiere al almacenamiento.
+func (s *Service) createBackupRecord(req *models.CreateBackupRequest, server *models.Server, userID uuid.UUID) (*models.Backup, error) {
 	backup := &models.Backup{
 		ID:          uuid.New(),
 		ServerID:    req.ServerID,
 		Filename:    req.Filename,
-		Path:        filepath.Join(s.backupDir, server.ID.String(), req.Filename),
+		Path:        path.Join(server.ID.String(), req.Filename),
 		BackupType:  req.BackupType,
 		Status:      models.BackupStatusPending,
 		Compression: req.Compression,
-		CreatedBy:   &userID,
 		CreatedAt:   time.Now(),
 	}
+	if userID != uuid.Nil {
+		backup.CreatedBy = &userID
+	}
 
 	if err := s.db.Create(backup).Error; err != nil {
 		return nil, fmt.Errorf("error creando registro de backup: %w", err)
@@ -67,54 +124,237 @@ func (s *Service) CreateBackup(ctx context.Context, req *models.CreateBackupRequ
 	backup.Status = models.BackupStatusInProgress
 	s.db.Save(backup)
 
-	// Llamar al agente para crear el backup físico
-	go s.executeBackup(ctx, backup, &server)
-
 	return backup, nil
 }
 
-// executeBackup ejecuta el backup en segundo plano
-func (s *Service) executeBackup(ctx context.Context, backup *models.Backup, server *models.Server) {
+// executeBackup pide al agente crear el archivo de backup, lo transfiere
+// al almacenamiento configurado y registra el resultado (tamaño, checksum
+// y duración, o el error)
+func (s *Service) executeBackup(ctx context.Context, backup *models.Backup, server *models.Server) error {
 	s.logger.Info("Executing backup",
 		zap.String("backup_id", backup.ID.String()),
 		zap.String("server_id", server.ID.String()),
 	)
 
-	// Llamar al agente para crear el backup
-	// TODO: Implementar llamada gRPC cuando se agreguen los métodos
-	s.logger.Warn("Backup creation not yet implemented in agent - marking as completed")
+	config, err := s.GetBackupConfig(ctx, server.ID)
+	if err != nil {
+		return s.failBackup(backup, fmt.Sprintf("error obteniendo configuración de backups: %v", err))
+	}
+
+	req := buildCreateBackupRequest(backup, server, config)
+	backup.Compression = req.Compression
+
+	var storage Storage
+	if !req.Incremental {
+		storage, err = s.storageFor(config.StorageType)
+		if err != nil {
+			return s.failBackup(backup, err.Error())
+		}
+	}
+
+	startTime := time.Now()
+	resp, err := s.agentService.CreateBackup(ctx, server.AgentID, req)
+	if err != nil {
+		return s.failBackup(backup, err.Error())
+	}
+	if !resp.Success {
+		return s.failBackup(backup, resp.Message)
+	}
+
+	if req.Incremental {
+		// El snapshot queda en el agente; Path guarda su ID
+		backup.Path = resp.SnapshotId
+		backup.StorageType = StorageTypeSnapshot
+		return s.completeBackup(backup, config, resp, startTime)
+	}
 
-	// Simular creación de backup por ahora
-	time.Sleep(2 * time.Second)
+	// Mover el archivo del agente al almacenamiento. El archivo temporal
+	// del agente se elimina aunque la transferencia falle.
+	filename := filepath.Base(resp.BackupPath)
+	key := path.Join(storagePrefix(config, server.ID), filename)
+	transferErr := s.transferToStorage(ctx, server.AgentID, resp.BackupPath, storage, key, resp.Checksum)
+	if err := s.agentService.DeleteBackup(ctx, server.AgentID, resp.BackupPath); err != nil {
+		s.logger.Warn("Error deleting staged backup on agent",
+			zap.String("backup_id", backup.ID.String()),
+			zap.Error(err),
+		)
+	}
+	if transferErr != nil {
+		return s.failBackup(backup, transferErr.Error())
+	}
 
-	// Marcar como completado
-	now := time.Now()
-	backup.Status = models.BackupStatusCompleted
-	backup.CompletedAt = &now
-	backup.SizeBytes = 1024 * 1024 * 100 // 100MB placeholder
+	backup.Path = key
+	backup.Filename = filename
+	backup.StorageType = storage.Type()
+
+	return s.completeBackup(backup, config, resp, startTime)
+}
+
+// completeBackup marca el backup como completado con los datos reales del
+// agente y aplica la política de retención
+func (s *Service) completeBackup(backup *models.Backup, config *models.BackupConfig, resp *pb.CreateBackupResponse, startTime time.Time) error {
+	backup.MarkCompleted()
+	backup.SizeBytes = resp.SizeBytes
+	backup.Checksum = resp.Checksum
+	backup.DurationMs = resp.DurationMs
+	if backup.DurationMs == 0 {
+		backup.DurationMs = time.Since(startTime).Milliseconds()
+	}
 
 	if err := s.db.Save(backup).Error; err != nil {
 		s.logger.Error("Error updating backup status", zap.Error(err))
-		return
+		return fmt.Errorf("error actualizando backup: %w", err)
 	}
 
-	// Actualizar last_backup_at en config si existe
-	var config models.BackupConfig
-	if err := s.db.First(&config, "server_id = ?", server.ID).Error; err == nil {
-		config.LastBackupAt = &now
-		s.db.Save(&config)
-	}
+	backupType := string(backup.BackupType)
+	telemetry.BackupJobs.WithLabelValues(backupType, string(models.BackupStatusCompleted)).Inc()
+	telemetry.BackupDuration.WithLabelValues(backupType).Observe(float64(backup.DurationMs) / 1000)
+	telemetry.BackupSize.WithLabelValues(backupType).Observe(float64(backup.SizeBytes))
+
+	// Actualizar last_backup_at en la configuración
+	config.LastBackupAt = backup.CompletedAt
+	s.db.Save(config)
 
 	// Limpiar backups antiguos según retention policy
-	go s.cleanupOldBackups(server.ID)
+	go s.cleanupOldBackups(backup.ServerID)
+
+	if s.onResult != nil {
+		s.onResult(backup)
+	}
 
 	s.logger.Info("Backup completed successfully",
 		zap.String("backup_id", backup.ID.String()),
+		zap.String("storage", backup.StorageType),
 		zap.Int64("size_bytes", backup.SizeBytes),
+		zap.String("checksum", backup.Checksum),
+		zap.Int64("duration_ms", backup.DurationMs),
+	)
+
+	return nil
+}
+
+// transferToStorage copia un archivo del directorio de backups del agente
+// al almacenamiento y verifica que el SHA256 coincide con el del agente
+func (s *Service) transferToStorage(ctx context.Context, agentID uuid.UUID, agentPath string, storage Storage, key, checksum string) error {
+	reader, size, err := s.agentService.DownloadBackup(ctx, agentID, agentPath)
+	if err != nil {
+		return fmt.Errorf("error descargando backup del agente: %w", err)
+	}
+	defer reader.Close()
+
+	hasher := sha256.New()
+	if err := storage.Put(ctx, key, io.TeeReader(reader, hasher), size); err != nil {
+		return fmt.Errorf("error guardando backup en %s: %w", storage.Type(), err)
+	}
+
+	if sum := hex.EncodeToString(hasher.Sum(nil)); checksum != "" && sum != checksum {
+		storage.Delete(ctx, key)
+		return fmt.Errorf("checksum no coincide: %s, esperado %s", sum, checksum)
+	}
+
+	return nil
+}
+
+// storagePrefix retorna el prefijo bajo el que se guardan los backups de
+// un servidor en el almacenamiento
+func storagePrefix(config *models.BackupConfig, serverID uuid.UUID) string {
+	if config.StoragePath != "" {
+		return config.StoragePath
+	}
+	return serverID.String()
+}
+
+// failBackup marca un backup como fallido y retorna el motivo como error
+func (s *Service) failBackup(backup *models.Backup, reason string) error {
+	s.logger.Error("Backup failed",
+		zap.String("backup_id", backup.ID.String()),
+		zap.String("reason", reason),
 	)
+
+	backup.MarkFailed(reason)
+	if err := s.db.Save(backup).Error; err != nil {
+		s.logger.Error("Error updating backup status", zap.Error(err))
+	}
+
+	telemetry.BackupJobs.WithLabelValues(string(backup.BackupType), string(models.BackupStatusFailed)).Inc()
+
+	if s.onResult != nil {
+		s.onResult(backup)
+	}
+
+	return fmt.Errorf("backup fallido: %s", reason)
 }
 
-// RestoreBackup restaura un backup en un servidor
+// Rutas del directorio del servidor por categoría de contenido
+var (
+	worldPaths  = []string{"world", "world_nether", "world_the_end"}
+	pluginPaths = []string{"plugins"}
+	configPaths = []string{"server.properties", "bukkit.yml", "spigot.yml", "paper.yml", "pufferfish.yml", "purpur.yml", "config"}
+	logPaths    = []string{"logs"}
+)
+
+// buildCreateBackupRequest traduce el backup y la configuración del
+// servidor a la petición del agente. En un backup completo el agente
+// incluye todo el directorio, así que las categorías desactivadas en la
+// configuración se envían como exclusiones.
+func buildCreateBackupRequest(backup *models.Backup, server *models.Server, config *models.BackupConfig) *pb.CreateBackupRequest {
+	req := &pb.CreateBackupRequest{
+		ServerId:     server.ID.String(),
+		BackupType:   string(backup.BackupType),
+		Destination:  backup.Path,
+		Compression:  agentCompression(backup.Compression, config.CompressBackups),
+		ExcludePaths: append([]string{}, config.ExcludePaths...),
+		Incremental:  config.StorageType == StorageTypeSnapshot,
+		Online:       config.OnlineBackup,
+	}
+
+	switch backup.BackupType {
+	case models.BackupTypeWorld:
+		req.IncludeWorld = true
+	case models.BackupTypePlugins:
+		req.IncludePlugins = true
+	case models.BackupTypeConfig:
+		req.IncludeConfig = true
+	default:
+		req.BackupType = string(models.BackupTypeFull)
+		req.IncludeWorld = config.IncludeWorld
+		req.IncludePlugins = config.IncludePlugins
+		req.IncludeConfig = config.IncludeConfig
+		req.IncludeLogs = config.IncludeLogs
+
+		if !config.IncludeWorld {
+			req.ExcludePaths = append(req.ExcludePaths, worldPaths...)
+		}
+		if !config.IncludePlugins {
+			req.ExcludePaths = append(req.ExcludePaths, pluginPaths...)
+		}
+		if !config.IncludeConfig {
+			req.ExcludePaths = append(req.ExcludePaths, configPaths...)
+		}
+		if !config.IncludeLogs {
+			req.ExcludePaths = append(req.ExcludePaths, logPaths...)
+		}
+	}
+
+	return req
+}
+
+// agentCompression normaliza la compresión a una soportada por el agente
+// (gzip o none). Sin compresión explícita se usa la de la configuración.
+func agentCompression(compression string, compressBackups bool) string {
+	switch compression {
+	case "none":
+		return "none"
+	case "":
+		if !compressBackups {
+			return "none"
+		}
+	}
+	return "gzip"
+}
+
+// RestoreBackup restaura un backup en un servidor. Bloquea hasta que el
+// agente termina y retorna su mensaje si la restauración falla.
 func (s *Service) RestoreBackup(ctx context.Context, req *models.RestoreBackupRequest) error {
 	s.logger.Info("Restoring backup",
 		zap.String("backup_id", req.BackupID.String()),
@@ -143,37 +383,96 @@ func (s *Service) RestoreBackup(ctx context.Context, req *models.RestoreBackupRe
 		return fmt.Errorf("servidor no encontrado: %w", err)
 	}
 
-	// Crear backup antes de restaurar si se solicita
+	// Crear backup de seguridad antes de restaurar si se solicita. Se espera
+	// a que termine para no copiar archivos a medio restaurar.
 	if req.BackupBeforeRestore {
 		s.logger.Info("Creating safety backup before restore")
-		backupReq := &models.CreateBackupRequest{
+		safetyReq := &models.CreateBackupRequest{
 			ServerID:    req.ServerID,
 			Filename:    fmt.Sprintf("pre-restore-%d.tar.gz", time.Now().Unix()),
 			BackupType:  models.BackupTypeFull,
 			Compression: "gzip",
 		}
-		if _, err := s.CreateBackup(ctx, backupReq, uuid.Nil); err != nil {
-			s.logger.Warn("Failed to create safety backup", zap.Error(err))
+		safety, err := s.createBackupRecord(safetyReq, &server, uuid.Nil)
+		if err != nil {
+			return fmt.Errorf("error creando backup de seguridad: %w", err)
+		}
+		if err := s.executeBackup(ctx, safety, &server); err != nil {
+			return fmt.Errorf("error creando backup de seguridad: %w", err)
 		}
 	}
 
-	// Detener servidor si se solicita
-	if req.StopServer {
-		s.logger.Info("Stopping server before restore")
-		// TODO: Implementar detención del servidor
+	// Los backups guardados en un almacenamiento se copian primero al
+	// directorio de backups del agente. Los registros antiguos (sin tipo
+	// de almacenamiento) apuntan directamente a un archivo del agente.
+	backupPath, snapshotID := backup.Path, ""
+	if backup.StorageType == StorageTypeSnapshot {
+		backupPath, snapshotID = "", backup.Path
+	} else if backup.StorageType != "" {
+		staged, err := s.stageOnAgent(ctx, &backup, server.AgentID)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := s.agentService.DeleteBackup(context.WithoutCancel(ctx), server.AgentID, staged); err != nil {
+				s.logger.Warn("Error deleting staged restore on agent", zap.Error(err))
+			}
+		}()
+		backupPath = staged
+	}
+
+	resp, err := s.agentService.RestoreBackup(ctx, server.AgentID, &pb.RestoreBackupRequest{
+		ServerId:       server.ID.String(),
+		BackupPath:     backupPath,
+		SnapshotId:     snapshotID,
+		StopServer:     req.StopServer,
+		RestoreWorld:   req.RestoreWorld,
+		RestorePlugins: req.RestorePlugins,
+		RestoreConfig:  req.RestoreConfig,
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("error restaurando backup: %s", resp.Message)
 	}
 
-	// Llamar al agente para restaurar el backup
-	// TODO: Implementar llamada gRPC cuando se agreguen los métodos
-	s.logger.Warn("Backup restore not yet implemented in agent")
-
-	s.logger.Info("Backup restore initiated",
+	s.logger.Info("Backup restored successfully",
 		zap.String("backup_id", req.BackupID.String()),
+		zap.Int64("duration_ms", resp.DurationMs),
 	)
 
 	return nil
 }
 
+// stageOnAgent sube un backup del almacenamiento al directorio de backups
+// del agente y retorna la ruta donde quedó
+func (s *Service) stageOnAgent(ctx context.Context, backup *models.Backup, agentID uuid.UUID) (string, error) {
+	storage, err := s.storageFor(backup.StorageType)
+	if err != nil {
+		return "", err
+	}
+
+	reader, err := storage.Get(ctx, backup.Path)
+	if err != nil {
+		return "", fmt.Errorf("error leyendo backup de %s: %w", storage.Type(), err)
+	}
+	defer reader.Close()
+
+	staging := path.Join("restore", backup.ServerID.String(), backup.Filename)
+	resp, err := s.agentService.UploadBackup(ctx, agentID, staging, reader)
+	if err != nil {
+		return "", fmt.Errorf("error enviando backup al agente: %w", err)
+	}
+
+	if backup.Checksum != "" && resp.Checksum != backup.Checksum {
+		s.agentService.DeleteBackup(ctx, agentID, resp.Path)
+		return "", fmt.Errorf("checksum no coincide: %s, esperado %s", resp.Checksum, backup.Checksum)
+	}
+
+	return resp.Path, nil
+}
+
 // ListBackups lista los backups de un servidor
 func (s *Service) ListBackups(ctx context.Context, serverID uuid.UUID, limit, offset int) (*models.BackupListResponse, error) {
 	var backups []models.Backup
@@ -238,7 +537,9 @@ func (s *Service) DeleteBackup(ctx context.Context, backupID uuid.UUID) error {
 	}
 
 	// Eliminar archivo físico
-	// TODO: Implementar eliminación del archivo en el agente
+	if err := s.deleteBackupFile(ctx, &backup); err != nil {
+		return err
+	}
 
 	// Eliminar registro de DB
 	if err := s.db.Delete(&backup).Error; err != nil {
@@ -249,6 +550,70 @@ func (s *Service) DeleteBackup(ctx context.Context, backupID uuid.UUID) error {
 	return nil
 }
 
+// deleteBackupFile elimina el archivo de un backup de su almacenamiento.
+// Para registros antiguos se intenta eliminarlo en el agente.
+func (s *Service) deleteBackupFile(ctx context.Context, backup *models.Backup) error {
+	if backup.StorageType == "" {
+		if backup.Status != models.BackupStatusCompleted {
+			return nil
+		}
+
+		var server models.Server
+		if err := s.db.First(&server, "id = ?", backup.ServerID).Error; err != nil {
+			return nil
+		}
+		if err := s.agentService.DeleteBackup(ctx, server.AgentID, backup.Path); err != nil {
+			s.logger.Warn("Error deleting backup file on agent",
+				zap.String("backup_id", backup.ID.String()),
+				zap.Error(err),
+			)
+		}
+		return nil
+	}
+
+	if backup.StorageType == StorageTypeSnapshot {
+		return s.deleteSnapshot(ctx, backup)
+	}
+
+	storage, err := s.storageFor(backup.StorageType)
+	if err != nil {
+		return err
+	}
+	if err := storage.Delete(ctx, backup.Path); err != nil {
+		return fmt.Errorf("error eliminando archivo de backup: %w", err)
+	}
+
+	return nil
+}
+
+// deleteSnapshot elimina un snapshot incremental del agente. El agente
+// elimina también los fragmentos que ya no usa ningún otro snapshot.
+func (s *Service) deleteSnapshot(ctx context.Context, backup *models.Backup) error {
+	var server models.Server
+	if err := s.db.First(&server, "id = ?", backup.ServerID).Error; err != nil {
+		return fmt.Errorf("servidor no encontrado: %w", err)
+	}
+
+	resp, err := s.agentService.PruneSnapshots(ctx, server.AgentID, &pb.PruneSnapshotsRequest{
+		ServerId:    server.ID.String(),
+		SnapshotIds: []string{backup.Path},
+	})
+	if err != nil {
+		return fmt.Errorf("error eliminando snapshot: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("error eliminando snapshot: %s", resp.Message)
+	}
+
+	s.logger.Info("Snapshot deleted",
+		zap.String("backup_id", backup.ID.String()),
+		zap.Int32("chunks_removed", resp.ChunksRemoved),
+		zap.Int64("bytes_freed", resp.BytesFreed),
+	)
+
+	return nil
+}
+
 // GetBackupConfig obtiene la configuración de backups de un servidor
 func (s *Service) GetBackupConfig(ctx context.Context, serverID uuid.UUID) (*models.BackupConfig, error) {
 	var config models.BackupConfig
@@ -265,6 +630,7 @@ func (s *Service) GetBackupConfig(ctx context.Context, serverID uuid.UUID) (*mod
 				MaxBackups:       10,
 				RetentionDays:    30,
 				CompressBackups:  true,
+				OnlineBackup:     true,
 				IncludeWorld:     true,
 				IncludePlugins:   true,
 				IncludeConfig:    true,
@@ -272,7 +638,7 @@ func (s *Service) GetBackupConfig(ctx context.Context, serverID uuid.UUID) (*mod
 				NotifyOnComplete: true,
 				NotifyOnFailure:  true,
 				StorageType:      "local",
-				StoragePath:      filepath.Join(s.backupDir, serverID.String()),
+				StoragePath:      serverID.String(),
 			}
 			if err := s.db.Create(&config).Error; err != nil {
 				return nil, fmt.Errorf("error creando configuración por defecto: %w", err)
@@ -315,6 +681,9 @@ func (s *Service) UpdateBackupConfig(ctx context.Context, serverID uuid.UUID, re
 	if req.CompressBackups != nil {
 		config.CompressBackups = *req.CompressBackups
 	}
+	if req.OnlineBackup != nil {
+		config.OnlineBackup = *req.OnlineBackup
+	}
 	if req.IncludeWorld != nil {
 		config.IncludeWorld = *req.IncludeWorld
 	}
@@ -436,7 +805,7 @@ func (s *Service) cleanupOldBackups(serverID uuid.UUID) {
 				zap.String("backup_id", backup.ID.String()),
 				zap.Time("created_at", backup.CreatedAt),
 			)
-			s.db.Delete(&backup)
+			s.deleteOldBackup(&backup)
 		}
 	}
 
@@ -454,7 +823,7 @@ func (s *Service) cleanupOldBackups(serverID uuid.UUID) {
 			zap.String("backup_id", backup.ID.String()),
 			zap.Time("created_at", backup.CreatedAt),
 		)
-		s.db.Delete(&backup)
+		s.deleteOldBackup(&backup)
 	}
 
 	s.logger.Info("Cleanup completed",
@@ -463,3 +832,16 @@ func (s *Service) cleanupOldBackups(serverID uuid.UUID) {
 		zap.Int("deleted_by_retention", len(oldBackups)),
 	)
 }
+
+// deleteOldBackup elimina un backup antiguo. Si el archivo no se puede
+// eliminar se conserva el registro para reintentar en la próxima limpieza.
+func (s *Service) deleteOldBackup(backup *models.Backup) {
+	if err := s.deleteBackupFile(context.Background(), backup); err != nil {
+		s.logger.Error("Error deleting old backup file",
+			zap.String("backup_id", backup.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	s.db.Delete(backup)
+}
@@ -0,0 +1,265 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// sftpDialTimeout tiempo máximo para establecer la conexión SSH
+const sftpDialTimeout = 15 * time.Second
+
+// SFTPConfig configuración de un almacenamiento SFTP
+type SFTPConfig struct {
+	Host           string
+	Port           int
+	User           string
+	Password       string
+	PrivateKeyFile string
+	HostKey        string // Clave pública del servidor (formato authorized_keys)
+	Dir            string // Directorio remoto base
+}
+
+// SFTPStorage guarda los backups en un servidor SFTP. Cada operación usa
+// su propia conexión SSH, por lo que no hay estado compartido.
+type SFTPStorage struct {
+	config    SFTPConfig
+	sshConfig *ssh.ClientConfig
+}
+
+// NewSFTPStorage crea un almacenamiento SFTP. La clave del servidor es
+// obligatoria para evitar ataques de intermediario.
+func NewSFTPStorage(config SFTPConfig) (*SFTPStorage, error) {
+	if config.Host == "" || config.User == "" {
+		return nil, fmt.Errorf("host y usuario SFTP requeridos")
+	}
+	if config.Port == 0 {
+		config.Port = 22
+	}
+	if config.Dir == "" {
+		config.Dir = "."
+	}
+
+	if config.HostKey == "" {
+		return nil, fmt.Errorf("clave del servidor SFTP requerida")
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("clave del servidor SFTP inválida: %w", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if config.PrivateKeyFile != "" {
+		keyData, err := os.ReadFile(config.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo clave privada: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(keyData)
+		if err != nil {
+			return nil, fmt.Errorf("clave privada inválida: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if config.Password != "" {
+		auth = append(auth, ssh.Password(config.Password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("se requiere contraseña o clave privada SFTP")
+	}
+
+	return &SFTPStorage{
+		config: config,
+		sshConfig: &ssh.ClientConfig{
+			User:            config.User,
+			Auth:            auth,
+			HostKeyCallback: ssh.FixedHostKey(hostKey),
+			Timeout:         sftpDialTimeout,
+		},
+	}, nil
+}
+
+// Type retorna el tipo de almacenamiento
+func (s *SFTPStorage) Type() string {
+	return StorageTypeSFTP
+}
+
+// Put sube el backup a un archivo temporal y lo renombra al terminar
+func (s *SFTPStorage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	target, err := s.remotePath(key)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+
+	if err := client.MkdirAll(path.Dir(target)); err != nil {
+		return fmt.Errorf("error creando directorio remoto: %w", err)
+	}
+
+	tmpPath := target + ".tmp"
+	file, err := client.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("error creando archivo remoto: %w", err)
+	}
+
+	written, err := file.ReadFrom(r)
+	if err == nil && size >= 0 && written != size {
+		err = fmt.Errorf("tamaño inesperado: %d bytes, esperados %d", written, size)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		client.Remove(tmpPath)
+		return fmt.Errorf("error subiendo backup: %w", err)
+	}
+
+	// posix-rename@openssh.com reemplaza el destino de forma atómica; los
+	// servidores sin la extensión solo permiten renombrar a un nombre libre
+	if err := client.PosixRename(tmpPath, target); err == nil {
+		return nil
+	}
+	if err := client.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		client.Remove(tmpPath)
+		return fmt.Errorf("error reemplazando backup: %w", err)
+	}
+	if err := client.Rename(tmpPath, target); err != nil {
+		client.Remove(tmpPath)
+		return fmt.Errorf("error guardando backup: %w", err)
+	}
+
+	return nil
+}
+
+// Get abre el backup remoto. La conexión se cierra al cerrar el lector.
+func (s *SFTPStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	target, err := s.remotePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := client.Open(target)
+	if err != nil {
+		client.Close()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("error abriendo backup remoto: %w", err)
+	}
+
+	return &sftpFileReader{
+		File:   file,
+		client: client,
+		stop:   context.AfterFunc(ctx, func() { client.Close() }),
+	}, nil
+}
+
+// Delete elimina el backup remoto
+func (s *SFTPStorage) Delete(ctx context.Context, key string) error {
+	target, err := s.remotePath(key)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+
+	if err := client.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error eliminando backup remoto: %w", err)
+	}
+
+	return nil
+}
+
+// remotePath resuelve una clave dentro del directorio remoto
+func (s *SFTPStorage) remotePath(key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(s.config.Dir, cleaned), nil
+}
+
+// connect abre una conexión SSH e inicia el subsistema SFTP
+func (s *SFTPStorage) connect(ctx context.Context) (*sftpConn, error) {
+	address := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+
+	dialer := net.Dialer{Timeout: sftpDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando con %s: %w", address, err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, s.sshConfig)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error en handshake SSH: %w", err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, fmt.Errorf("error iniciando SFTP: %w", err)
+	}
+
+	return &sftpConn{Client: client, ssh: sshClient}, nil
+}
+
+// sftpConn cliente SFTP junto a la conexión SSH que lo transporta
+type sftpConn struct {
+	*sftp.Client
+	ssh       *ssh.Client
+	closeOnce sync.Once
+}
+
+// Close cierra el cliente SFTP y la conexión SSH. Puede llamarse varias
+// veces y desde otra goroutine (cancelación del contexto).
+func (c *sftpConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.Client.Close()
+		c.ssh.Close()
+	})
+	return nil
+}
+
+// sftpFileReader lee un archivo remoto y cierra la conexión al terminar
+type sftpFileReader struct {
+	*sftp.File
+	client *sftpConn
+	stop   func() bool
+}
+
+func (r *sftpFileReader) Close() error {
+	r.stop()
+	err := r.File.Close()
+	r.client.Close()
+	return err
+}
@@ -0,0 +1,292 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	testSFTPUser     = "backups"
+	testSFTPPassword = "backups-secret"
+)
+
+// fakeSFTPServer servidor SSH en memoria que expone el subsistema SFTP sobre
+// un directorio temporal
+type fakeSFTPServer struct {
+	root     string
+	hostKey  ssh.PublicKey
+	listener net.Listener
+	config   *ssh.ServerConfig
+	options  []sftp.ServerOption
+	wg       sync.WaitGroup
+}
+
+func newFakeSFTPServer(t *testing.T, options ...sftp.ServerOption) *fakeSFTPServer {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generando clave del servidor: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatalf("Error creando firmante: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == testSFTPUser && string(password) == testSFTPPassword {
+				return nil, nil
+			}
+			return nil, errors.New("credenciales inválidas")
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error escuchando: %v", err)
+	}
+
+	server := &fakeSFTPServer{
+		root:     t.TempDir(),
+		hostKey:  signer.PublicKey(),
+		listener: listener,
+		config:   config,
+		options:  options,
+	}
+	server.options = append(server.options, sftp.WithServerWorkingDirectory(server.root))
+
+	server.wg.Add(1)
+	go server.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		server.wg.Wait()
+	})
+
+	return server
+}
+
+func (f *fakeSFTPServer) serve() {
+	defer f.wg.Done()
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.wg.Add(1)
+		go func() {
+			defer f.wg.Done()
+			f.handleConn(conn)
+		}()
+	}
+}
+
+func (f *fakeSFTPServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	_, chans, reqs, err := ssh.NewServerConn(conn, f.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "solo sesiones")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for req := range requests {
+				isSFTP := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				req.Reply(isSFTP, nil)
+				if !isSFTP {
+					continue
+				}
+				server, err := sftp.NewServer(channel, f.options...)
+				if err != nil {
+					channel.Close()
+					return
+				}
+				server.Serve()
+				server.Close()
+			}
+		}()
+	}
+}
+
+func (f *fakeSFTPServer) storageConfig(t *testing.T) SFTPConfig {
+	t.Helper()
+
+	host, port, _ := net.SplitHostPort(f.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return SFTPConfig{
+		Host:     host,
+		Port:     portNumber,
+		User:     testSFTPUser,
+		Password: testSFTPPassword,
+		HostKey:  string(ssh.MarshalAuthorizedKey(f.hostKey)),
+		Dir:      "backups",
+	}
+}
+
+func newTestSFTPStorage(t *testing.T, config SFTPConfig) *SFTPStorage {
+	t.Helper()
+
+	storage, err := NewSFTPStorage(config)
+	if err != nil {
+		t.Fatalf("Error creando almacenamiento SFTP: %v", err)
+	}
+	return storage
+}
+
+func TestSFTPStorageRoundTrip(t *testing.T) {
+	server := newFakeSFTPServer(t)
+	storage := newTestSFTPStorage(t, server.storageConfig(t))
+	ctx := context.Background()
+	key := "server-1/backup 1.tar.gz"
+
+	// Más grande que un paquete SFTP para forzar varias escrituras
+	content := make([]byte, 300*1024)
+	rand.Read(content)
+
+	if err := storage.Put(ctx, key, bytes.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Error en Put: %v", err)
+	}
+	stored, err := os.ReadFile(filepath.Join(server.root, "backups", "server-1", "backup 1.tar.gz"))
+	if err != nil {
+		t.Fatalf("El backup debería guardarse bajo el directorio configurado: %v", err)
+	}
+	if !bytes.Equal(stored, content) {
+		t.Error("El archivo remoto no coincide con el original")
+	}
+
+	reader, err := storage.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Error en Get: %v", err)
+	}
+	got, _ := io.ReadAll(reader)
+	reader.Close()
+	if !bytes.Equal(got, content) {
+		t.Error("El contenido descargado no coincide con el original")
+	}
+
+	// Sobrescribir una clave existente reemplaza el archivo
+	replacement := []byte("backup nuevo")
+	if err := storage.Put(ctx, key, bytes.NewReader(replacement), -1); err != nil {
+		t.Fatalf("Error sobrescribiendo: %v", err)
+	}
+	reader, err = storage.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Error en Get tras sobrescribir: %v", err)
+	}
+	got, _ = io.ReadAll(reader)
+	reader.Close()
+	if !bytes.Equal(got, replacement) {
+		t.Errorf("Contenido tras sobrescribir: %q", got)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(server.root, "backups", "server-1"))
+	if len(entries) != 1 {
+		t.Errorf("Solo debería quedar el backup, hay %d archivos", len(entries))
+	}
+
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Fatalf("Error en Delete: %v", err)
+	}
+	if _, err := storage.Get(ctx, key); err != ErrObjectNotFound {
+		t.Errorf("Se esperaba ErrObjectNotFound tras eliminar, obtenido %v", err)
+	}
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Errorf("Eliminar un backup inexistente no debería fallar: %v", err)
+	}
+}
+
+func TestSFTPStorageSizeMismatch(t *testing.T) {
+	server := newFakeSFTPServer(t)
+	storage := newTestSFTPStorage(t, server.storageConfig(t))
+
+	err := storage.Put(context.Background(), "server-1/short.tar.gz", strings.NewReader("corto"), 100)
+	if err == nil {
+		t.Fatal("Se esperaba error por tamaño inesperado")
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(server.root, "backups", "server-1"))
+	if len(entries) != 0 {
+		t.Errorf("No deberían quedar archivos tras el fallo, hay %d", len(entries))
+	}
+}
+
+func TestSFTPStorageReportsServerErrors(t *testing.T) {
+	server := newFakeSFTPServer(t, sftp.ReadOnly())
+	storage := newTestSFTPStorage(t, server.storageConfig(t))
+	ctx := context.Background()
+
+	existing := filepath.Join(server.root, "backups", "server-1", "old.tar.gz")
+	os.MkdirAll(filepath.Dir(existing), 0755)
+	os.WriteFile(existing, []byte("backup"), 0644)
+
+	if err := storage.Put(ctx, "server-1/new.tar.gz", strings.NewReader("datos"), -1); err == nil {
+		t.Error("Put debería fallar en un servidor de solo lectura")
+	}
+	if err := storage.Delete(ctx, "server-1/old.tar.gz"); err == nil {
+		t.Error("Delete debería devolver el error del servidor, no ignorarlo")
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("El backup existente no debería tocarse: %v", err)
+	}
+
+	reader, err := storage.Get(ctx, "server-1/old.tar.gz")
+	if err != nil {
+		t.Fatalf("Get debería funcionar en solo lectura: %v", err)
+	}
+	reader.Close()
+}
+
+func TestSFTPStorageRejectsUnknownHostKey(t *testing.T) {
+	server := newFakeSFTPServer(t)
+	config := server.storageConfig(t)
+
+	otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	sshKey, _ := ssh.NewPublicKey(otherKey)
+	config.HostKey = string(ssh.MarshalAuthorizedKey(sshKey))
+
+	storage := newTestSFTPStorage(t, config)
+	err := storage.Put(context.Background(), "server-1/backup.tar.gz", strings.NewReader("datos"), -1)
+	if err == nil || !strings.Contains(err.Error(), "handshake") {
+		t.Errorf("Se esperaba error de handshake por clave distinta, obtenido %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(server.root, "backups")); !os.IsNotExist(statErr) {
+		t.Error("No debería escribirse nada con una clave de servidor distinta")
+	}
+}
+
+func TestSFTPStorageRejectsBadCredentials(t *testing.T) {
+	server := newFakeSFTPServer(t)
+	config := server.storageConfig(t)
+	config.Password = "incorrecta"
+
+	storage := newTestSFTPStorage(t, config)
+	if _, err := storage.Get(context.Background(), "server-1/backup.tar.gz"); err == nil || err == ErrObjectNotFound {
+		t.Errorf("Se esperaba error de autenticación, obtenido %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Tipos de almacenamiento soportados
+const (
+	StorageTypeLocal = "local"
+	StorageTypeS3    = "s3"
+	StorageTypeSFTP  = "sftp"
+
+	// StorageTypeSnapshot backups incrementales en el almacén deduplicado
+	// del agente. No es un Storage: el archivo nunca sale del agente.
+	StorageTypeSnapshot = "snapshot"
+)
+
+// ErrObjectNotFound se retorna cuando un backup no existe en el almacenamiento
+var ErrObjectNotFound = errors.New("backup no encontrado en el almacenamiento")
+
+// Storage es un destino donde se guardan los archivos de backup.
+// Las claves usan "/" como separador sin importar la implementación.
+type Storage interface {
+	// Type retorna el tipo de almacenamiento (local, s3, sftp)
+	Type() string
+
+	// Put guarda el contenido de r bajo key. size puede ser -1 si se desconoce.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+
+	// Get abre el backup guardado bajo key para lectura secuencial
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete elimina el backup. No falla si ya no existe.
+	Delete(ctx context.Context, key string) error
+}
+
+// cleanKey normaliza una clave de almacenamiento y rechaza las que
+// intentan salir del directorio base
+func cleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	cleaned := strings.TrimPrefix(path.Clean("/"+key), "/")
+
+	if cleaned == "" {
+		return "", fmt.Errorf("clave de backup vacía")
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("clave de backup inválida: %s", key)
+		}
+	}
+
+	return cleaned, nil
+}
+
+// LocalStorage guarda los backups en un directorio del backend
+type LocalStorage struct {
+	baseDir string
+}
+
+// NewLocalStorage crea un almacenamiento local en baseDir
+func NewLocalStorage(baseDir string) *LocalStorage {
+	return &LocalStorage{baseDir: baseDir}
+}
+
+// Type retorna el tipo de almacenamiento
+func (s *LocalStorage) Type() string {
+	return StorageTypeLocal
+}
+
+// Put escribe el backup en un archivo temporal y lo renombra al terminar
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("error creando directorio: %w", err)
+	}
+
+	tmpPath := target + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("error creando archivo: %w", err)
+	}
+
+	written, err := io.Copy(file, contextReader{ctx: ctx, r: r})
+	if err == nil && size >= 0 && written != size {
+		err = fmt.Errorf("tamaño inesperado: %d bytes, esperados %d", written, size)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error escribiendo backup: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, target); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error guardando backup: %w", err)
+	}
+
+	return nil
+}
+
+// Get abre el archivo del backup
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("error abriendo backup: %w", err)
+	}
+
+	return file, nil
+}
+
+// Delete elimina el archivo del backup
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error eliminando backup: %w", err)
+	}
+
+	return nil
+}
+
+// path resuelve una clave dentro del directorio base
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.baseDir, filepath.FromSlash(cleaned)), nil
+}
+
+// contextReader corta una copia larga cuando se cancela el contexto
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,368 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrBackupNotFound se retorna cuando el backup no existe
+	ErrBackupNotFound = errors.New("backup no encontrado")
+
+	// ErrServerNotFound se retorna al importar a un servidor que no existe
+	ErrServerNotFound = errors.New("servidor no encontrado")
+
+	// ErrBackupNotDownloadable se retorna para backups sin archivo descargable
+	// (en curso, fallidos o snapshots incrementales)
+	ErrBackupNotDownloadable = errors.New("backup no descargable")
+
+	// ErrInvalidBackupArchive se retorna cuando un archivo importado no tiene
+	// la estructura de un backup de servidor
+	ErrInvalidBackupArchive = errors.New("archivo de backup inválido")
+)
+
+// BackupDownload archivo de un backup abierto para descarga. El llamador
+// debe cerrarlo.
+type BackupDownload struct {
+	io.ReadCloser
+	Filename    string
+	Size        int64 // -1 si se desconoce
+	Checksum    string
+	Compression string
+}
+
+// OpenBackup abre el archivo de un backup completado, desde su
+// almacenamiento o, en registros antiguos, desde el agente. Los backups de
+// servidores de otro usuario se tratan como inexistentes salvo para admins.
+func (s *Service) OpenBackup(ctx context.Context, backupID, userID uuid.UUID, isAdmin bool) (*BackupDownload, error) {
+	var backup models.Backup
+	if err := s.db.WithContext(ctx).First(&backup, "id = ?", backupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBackupNotFound
+		}
+		return nil, fmt.Errorf("error obteniendo backup: %w", err)
+	}
+
+	server, err := s.ownedServer(ctx, backup.ServerID, userID, isAdmin)
+	if err != nil {
+		if errors.Is(err, ErrServerNotFound) {
+			return nil, ErrBackupNotFound
+		}
+		return nil, err
+	}
+
+	if backup.Status != models.BackupStatusCompleted {
+		return nil, fmt.Errorf("%w: no está completado (estado: %s)", ErrBackupNotDownloadable, backup.Status)
+	}
+
+	download := &BackupDownload{
+		Filename:    backup.Filename,
+		Size:        backup.SizeBytes,
+		Checksum:    backup.Checksum,
+		Compression: backup.Compression,
+	}
+	if download.Size <= 0 {
+		download.Size = -1
+	}
+
+	switch backup.StorageType {
+	case StorageTypeSnapshot:
+		return nil, fmt.Errorf("%w: los snapshots incrementales solo existen en el agente", ErrBackupNotDownloadable)
+	case "":
+		reader, size, err := s.agentService.DownloadBackup(ctx, server.AgentID, backup.Path)
+		if err != nil {
+			return nil, fmt.Errorf("error descargando backup del agente: %w", err)
+		}
+		download.ReadCloser = reader
+		if size > 0 {
+			download.Size = size
+		}
+	default:
+		storage, err := s.storageFor(backup.StorageType)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := storage.Get(ctx, backup.Path)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo backup de %s: %w", storage.Type(), err)
+		}
+		download.ReadCloser = reader
+	}
+
+	s.logger.Info("Backup download started",
+		zap.String("backup_id", backup.ID.String()),
+		zap.String("storage", backup.StorageType),
+	)
+
+	return download, nil
+}
+
+// ImportBackup guarda un archivo tar o tar.gz subido por el usuario como
+// backup de tipo "imported" del servidor. El archivo se inspecciona mientras
+// se copia al almacenamiento configurado; si no tiene la estructura de un
+// directorio de servidor se descarta y no se registra nada. Los snapshots
+// no admiten importación, así que en ese caso se usa el almacenamiento local.
+// Solo el dueño del servidor o un admin pueden importar.
+func (s *Service) ImportBackup(ctx context.Context, serverID uuid.UUID, filename string, r io.Reader, userID uuid.UUID, isAdmin bool) (*models.Backup, error) {
+	if _, err := s.ownedServer(ctx, serverID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	name, compression, err := importedFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.GetBackupConfig(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	storageType := config.StorageType
+	if storageType == StorageTypeSnapshot {
+		storageType = StorageTypeLocal
+	}
+	storage, err := s.storageFor(storageType)
+	if err != nil {
+		return nil, err
+	}
+
+	name = fmt.Sprintf("imported-%s-%s", time.Now().Format("20060102-150405"), name)
+	key := path.Join(storagePrefix(config, serverID), name)
+
+	s.logger.Info("Importing backup",
+		zap.String("server_id", serverID.String()),
+		zap.String("storage", storage.Type()),
+		zap.String("key", key),
+	)
+
+	startTime := time.Now()
+	size, checksum, err := putInspected(ctx, storage, key, r, compression == "gzip")
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &models.Backup{
+		ID:          uuid.New(),
+		ServerID:    serverID,
+		Filename:    name,
+		Path:        key,
+		SizeBytes:   size,
+		BackupType:  models.BackupTypeImported,
+		Compression: compression,
+		StorageType: storage.Type(),
+		Checksum:    checksum,
+		DurationMs:  time.Since(startTime).Milliseconds(),
+		CreatedAt:   time.Now(),
+	}
+	if userID != uuid.Nil {
+		backup.CreatedBy = &userID
+	}
+	backup.MarkCompleted()
+
+	if err := s.db.Create(backup).Error; err != nil {
+		storage.Delete(context.WithoutCancel(ctx), key)
+		return nil, fmt.Errorf("error creando registro de backup: %w", err)
+	}
+
+	s.logger.Info("Backup imported successfully",
+		zap.String("backup_id", backup.ID.String()),
+		zap.Int64("size_bytes", backup.SizeBytes),
+		zap.String("checksum", backup.Checksum),
+	)
+
+	return backup, nil
+}
+
+// ownedServer obtiene un servidor si pertenece al usuario. Los admins
+// acceden a cualquiera; para el resto los ajenos no existen.
+func (s *Service) ownedServer(ctx context.Context, serverID, userID uuid.UUID, isAdmin bool) (*models.Server, error) {
+	query := s.db.WithContext(ctx)
+	if !isAdmin {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var server models.Server
+	if err := query.First(&server, "id = ?", serverID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrServerNotFound
+		}
+		return nil, fmt.Errorf("error obteniendo servidor: %w", err)
+	}
+	return &server, nil
+}
+
+// putInspected guarda r en el almacenamiento mientras inspecciona una copia
+// del stream. Si el archivo no es válido, el inspector cierra el pipe con el
+// error (lo que corta también la subida) y lo guardado se elimina. Retorna
+// el tamaño y el SHA256 de lo guardado.
+func putInspected(ctx context.Context, storage Storage, key string, r io.Reader, compressed bool) (int64, string, error) {
+	pr, pw := io.Pipe()
+	inspected := make(chan error, 1)
+	go func() {
+		err := inspectBackupArchive(pr, compressed)
+		if err != nil {
+			pr.CloseWithError(err)
+		} else {
+			io.Copy(io.Discard, pr)
+		}
+		inspected <- err
+	}()
+
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	putErr := storage.Put(ctx, key, io.TeeReader(r, io.MultiWriter(hasher, counter, pw)), -1)
+	pw.CloseWithError(putErr)
+	inspectErr := <-inspected
+
+	switch {
+	case errors.Is(inspectErr, ErrInvalidBackupArchive):
+		storage.Delete(context.WithoutCancel(ctx), key)
+		return 0, "", inspectErr
+	case putErr != nil:
+		return 0, "", fmt.Errorf("error guardando backup en %s: %w", storage.Type(), putErr)
+	case inspectErr != nil:
+		storage.Delete(context.WithoutCancel(ctx), key)
+		return 0, "", fmt.Errorf("error inspeccionando backup: %w", inspectErr)
+	}
+
+	return counter.n, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// importedFilename limpia el nombre de un archivo importado y deduce la
+// compresión. El agente detecta gzip por el sufijo ".gz" (en minúsculas),
+// así que la extensión se normaliza a ".tar.gz" o ".tar".
+func importedFilename(filename string) (string, string, error) {
+	name := filepath.Base(strings.ReplaceAll(filename, "\\", "/"))
+	lower := strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"):
+		return name[:len(name)-len(".tar.gz")] + ".tar.gz", "gzip", nil
+	case strings.HasSuffix(lower, ".tgz"):
+		return name[:len(name)-len(".tgz")] + ".tar.gz", "gzip", nil
+	case strings.HasSuffix(lower, ".tar"):
+		return name[:len(name)-len(".tar")] + ".tar", "none", nil
+	}
+	return "", "", fmt.Errorf("%w: formato no soportado (%s), usa .tar.gz, .tgz o .tar", ErrInvalidBackupArchive, name)
+}
+
+// serverTopLevel nombres que identifican el directorio de un servidor en
+// la raíz de un archivo
+var serverTopLevel = func() map[string]bool {
+	names := make(map[string]bool)
+	for _, group := range [][]string{worldPaths, pluginPaths, configPaths} {
+		for _, name := range group {
+			names[name] = true
+		}
+	}
+	return names
+}()
+
+// inspectBackupArchive comprueba que un tar (o tar.gz) se puede restaurar:
+// solo archivos y directorios con rutas relativas, al menos un archivo, y
+// el contenido de un servidor en la raíz (server.properties, plugins, un
+// mundo...). Un mundo con nombre propio se reconoce por su level.dat.
+func inspectBackupArchive(r io.Reader, compressed bool) error {
+	if compressed {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return archiveError(err, "no es un archivo gzip")
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	files := 0
+	topLevel := make(map[string]bool)
+	recognized := false
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return archiveError(err, "error leyendo tar")
+		}
+
+		name := strings.TrimPrefix(header.Name, "./")
+		if path.IsAbs(name) {
+			return fmt.Errorf("%w: ruta absoluta %q", ErrInvalidBackupArchive, header.Name)
+		}
+		parts := strings.Split(strings.Trim(name, "/"), "/")
+		for _, part := range parts {
+			if part == ".." {
+				return fmt.Errorf("%w: la entrada %q sale del directorio", ErrInvalidBackupArchive, header.Name)
+			}
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+		case tar.TypeReg:
+			files++
+		case tar.TypeSymlink, tar.TypeLink:
+			return fmt.Errorf("%w: contiene enlaces (%s), que no se pueden restaurar", ErrInvalidBackupArchive, header.Name)
+		default:
+			return fmt.Errorf("%w: tipo de entrada no soportado en %s", ErrInvalidBackupArchive, header.Name)
+		}
+
+		if parts[0] == "" || parts[0] == "." {
+			continue
+		}
+		topLevel[parts[0]] = true
+		if serverTopLevel[parts[0]] || (len(parts) == 2 && parts[1] == "level.dat") {
+			recognized = true
+		}
+	}
+
+	if files == 0 {
+		return fmt.Errorf("%w: el archivo no contiene archivos", ErrInvalidBackupArchive)
+	}
+	if !recognized {
+		if len(topLevel) == 1 {
+			for dir := range topLevel {
+				return fmt.Errorf("%w: el contenido está dentro de la carpeta %q; los archivos del servidor deben estar en la raíz", ErrInvalidBackupArchive, dir)
+			}
+		}
+		return fmt.Errorf("%w: no contiene archivos de un servidor (server.properties, world, plugins...)", ErrInvalidBackupArchive)
+	}
+
+	return nil
+}
+
+// archiveError marca como archivo inválido los errores de formato. Los
+// demás (p. ej. la subida cortada) se retornan sin cambios.
+func archiveError(err error, msg string) error {
+	if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) ||
+		errors.Is(err, tar.ErrHeader) || errors.Is(err, io.ErrUnexpectedEOF) || err == io.EOF {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidBackupArchive, msg, err)
+	}
+	return err
+}
+
+// countingWriter cuenta los bytes escritos
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
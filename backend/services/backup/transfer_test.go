@@ -0,0 +1,201 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// testArchive construye un tar.gz con las entradas indicadas. Los nombres
+// terminados en "/" son directorios y "->" crea un enlace simbólico.
+func testArchive(t *testing.T, names ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		header := &tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg, Size: 4}
+		switch {
+		case strings.HasSuffix(name, "/"):
+			header.Typeflag, header.Size, header.Mode = tar.TypeDir, 0, 0755
+		case strings.Contains(name, "->"):
+			parts := strings.SplitN(name, "->", 2)
+			header.Name, header.Linkname = parts[0], parts[1]
+			header.Typeflag, header.Size = tar.TypeSymlink, 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Size > 0 {
+			tw.Write([]byte("data"))
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestInspectBackupArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		wantErr string
+	}{
+		{"server root", []string{"./", "server.properties", "world/", "world/level.dat"}, ""},
+		{"plugins only", []string{"plugins/", "plugins/Essentials.jar"}, ""},
+		{"custom world name", []string{"survival/", "survival/level.dat"}, ""},
+		{"wrapped in folder", []string{"myserver/", "myserver/server.properties"}, "carpeta \"myserver\""},
+		{"unrelated files", []string{"notes.txt", "photos/cat.png"}, "no contiene archivos de un servidor"},
+		{"only directories", []string{"world/"}, "no contiene archivos"},
+		{"path traversal", []string{"../etc/passwd"}, "sale del directorio"},
+		{"absolute path", []string{"/etc/passwd"}, "ruta absoluta"},
+		{"symlink", []string{"server.properties", "world->/etc"}, "enlaces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := inspectBackupArchive(bytes.NewReader(testArchive(t, tt.entries...)), true)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("inspectBackupArchive() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidBackupArchive) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("inspectBackupArchive() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInspectBackupArchiveRejectsCorruptData(t *testing.T) {
+	err := inspectBackupArchive(strings.NewReader("PK\x03\x04 not a tarball"), true)
+	if !errors.Is(err, ErrInvalidBackupArchive) {
+		t.Fatalf("gzip inválido: error = %v", err)
+	}
+
+	data := testArchive(t, "server.properties", "world/level.dat")
+	err = inspectBackupArchive(bytes.NewReader(data[:len(data)/2]), true)
+	if !errors.Is(err, ErrInvalidBackupArchive) {
+		t.Fatalf("archivo truncado: error = %v", err)
+	}
+}
+
+func TestImportedFilename(t *testing.T) {
+	tests := []struct {
+		in, name, compression string
+	}{
+		{"world.tar.gz", "world.tar.gz", "gzip"},
+		{"C:\\Users\\me\\Backup.TGZ", "Backup.tar.gz", "gzip"},
+		{"../../server.tar", "server.tar", "none"},
+		{"World.TAR.GZ", "World.tar.gz", "gzip"},
+	}
+	for _, tt := range tests {
+		name, compression, err := importedFilename(tt.in)
+		if err != nil || name != tt.name || compression != tt.compression {
+			t.Errorf("importedFilename(%q) = %q, %q, %v; want %q, %q", tt.in, name, compression, err, tt.name, tt.compression)
+		}
+	}
+
+	if _, _, err := importedFilename("world.zip"); !errors.Is(err, ErrInvalidBackupArchive) {
+		t.Errorf("importedFilename(world.zip) error = %v", err)
+	}
+}
+
+func TestPutInspected(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewLocalStorage(dir)
+
+	data := testArchive(t, "server.properties", "world/", "world/level.dat")
+	size, checksum, err := putInspected(context.Background(), storage, "srv/ok.tar.gz", bytes.NewReader(data), true)
+	if err != nil {
+		t.Fatalf("putInspected() error = %v", err)
+	}
+	sum := sha256.Sum256(data)
+	if size != int64(len(data)) || checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("putInspected() = %d, %s; want %d, %x", size, checksum, len(data), sum)
+	}
+	if stored, _ := os.ReadFile(filepath.Join(dir, "srv", "ok.tar.gz")); !bytes.Equal(stored, data) {
+		t.Error("el archivo guardado no coincide con el subido")
+	}
+
+	// Un archivo inválido no debe quedar en el almacenamiento
+	bad := testArchive(t, "myserver/", "myserver/server.properties")
+	_, _, err = putInspected(context.Background(), storage, "srv/bad.tar.gz", bytes.NewReader(bad), true)
+	if !errors.Is(err, ErrInvalidBackupArchive) {
+		t.Fatalf("putInspected() error = %v, want ErrInvalidBackupArchive", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "srv", "bad.tar.gz")); !os.IsNotExist(err) {
+		t.Error("el archivo inválido quedó en el almacenamiento")
+	}
+}
+
+// newAccessTestService crea un servicio sobre SQLite en memoria con un
+// servidor de owner y un backup fallido de ese servidor. Solo se crean las
+// columnas que usan las comprobaciones de acceso.
+func newAccessTestService(t *testing.T, owner uuid.UUID) (*Service, uuid.UUID, uuid.UUID) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE servers (id TEXT PRIMARY KEY, user_id TEXT, agent_id TEXT, name TEXT)`,
+		`CREATE TABLE backups (id TEXT PRIMARY KEY, server_id TEXT, status TEXT, storage_type TEXT, path TEXT, filename TEXT)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	serverID, backupID := uuid.New(), uuid.New()
+	db.Exec(`INSERT INTO servers (id, user_id, agent_id, name) VALUES (?, ?, ?, ?)`, serverID, owner, uuid.New(), "survival")
+	db.Exec(`INSERT INTO backups (id, server_id, status) VALUES (?, ?, ?)`, backupID, serverID, models.BackupStatusFailed)
+
+	return NewService(db, nil, zap.NewNop(), t.TempDir()), serverID, backupID
+}
+
+func TestBackupTransferRequiresServerOwner(t *testing.T) {
+	owner, stranger := uuid.New(), uuid.New()
+	service, serverID, backupID := newAccessTestService(t, owner)
+	ctx := context.Background()
+
+	// Un backup ajeno no existe para el usuario
+	if _, err := service.OpenBackup(ctx, backupID, stranger, false); !errors.Is(err, ErrBackupNotFound) {
+		t.Errorf("OpenBackup() de otro usuario error = %v, want ErrBackupNotFound", err)
+	}
+	// El dueño y los admins pasan la comprobación (el backup fallido no es descargable)
+	for _, access := range []struct {
+		user    uuid.UUID
+		isAdmin bool
+	}{{owner, false}, {stranger, true}} {
+		if _, err := service.OpenBackup(ctx, backupID, access.user, access.isAdmin); !errors.Is(err, ErrBackupNotDownloadable) {
+			t.Errorf("OpenBackup(admin=%v) error = %v, want ErrBackupNotDownloadable", access.isAdmin, err)
+		}
+	}
+
+	archive := testArchive(t, "server.properties")
+	if _, err := service.ImportBackup(ctx, serverID, "world.tar.gz", bytes.NewReader(archive), stranger, false); !errors.Is(err, ErrServerNotFound) {
+		t.Errorf("ImportBackup() en servidor ajeno error = %v, want ErrServerNotFound", err)
+	}
+	// Con acceso se llega a validar el archivo
+	if _, err := service.ImportBackup(ctx, serverID, "world.zip", bytes.NewReader(archive), owner, false); !errors.Is(err, ErrInvalidBackupArchive) {
+		t.Errorf("ImportBackup() del dueño error = %v, want ErrInvalidBackupArchive", err)
+	}
+}
@@ -0,0 +1,201 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/aymc/backend/api/websocket"
+	"github.com/aymc/backend/database/models"
+	"github.com/aymc/backend/pkg/telemetry"
+	"github.com/aymc/backend/services/agents"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// collectTimeout tiempo máximo de las consultas a la base de datos durante
+// un scrape
+const collectTimeout = 5 * time.Second
+
+var (
+	serverLabels = []string{"server_id", "server_name", "agent_id"}
+	agentLabels  = []string{"agent_id", "hostname"}
+)
+
+// desc crea la descripción de una métrica del exportador
+func desc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(telemetry.Namespace, "", name), help, labels, nil)
+}
+
+// Collector exporta a Prometheus el estado de servidores, agentes y
+// WebSocket. Los valores se leen en cada scrape: las métricas de servidores
+// son la última muestra del histórico, las de agentes las del health
+// monitor.
+type Collector struct {
+	history *Service
+	health  *agents.HealthMonitor
+	hub     *websocket.Hub
+
+	servers             *prometheus.Desc
+	serverCPU           *prometheus.Desc
+	serverMemory        *prometheus.Desc
+	serverDisk          *prometheus.Desc
+	serverThreads       *prometheus.Desc
+	serverPlayers       *prometheus.Desc
+	serverTPS           *prometheus.Desc
+	serverMSPT          *prometheus.Desc
+	serverUptime        *prometheus.Desc
+	agents              *prometheus.Desc
+	agentUp             *prometheus.Desc
+	agentFailures       *prometheus.Desc
+	agentLastSeen       *prometheus.Desc
+	agentCPU            *prometheus.Desc
+	agentMemoryUsed     *prometheus.Desc
+	agentMemoryTotal    *prometheus.Desc
+	agentDiskUsed       *prometheus.Desc
+	agentDiskTotal      *prometheus.Desc
+	websocketClients    *prometheus.Desc
+	websocketChannels   *prometheus.Desc
+	websocketSubscribed *prometheus.Desc
+}
+
+// NewCollector crea el collector del backend
+func NewCollector(history *Service, health *agents.HealthMonitor, hub *websocket.Hub) *Collector {
+	return &Collector{
+		history: history,
+		health:  health,
+		hub:     hub,
+
+		servers:             desc("servers", "Servers by status.", "status"),
+		serverCPU:           desc("server_cpu_percent", "Server process CPU usage, 100 = one core.", serverLabels...),
+		serverMemory:        desc("server_memory_used_bytes", "Server process resident memory.", serverLabels...),
+		serverDisk:          desc("server_disk_used_bytes", "Size of the server directory.", serverLabels...),
+		serverThreads:       desc("server_threads", "Threads of the server process and its children.", serverLabels...),
+		serverPlayers:       desc("server_players_online", "Players connected to the server.", serverLabels...),
+		serverTPS:           desc("server_tps", "Ticks per second, last minute average (Paper and Purpur).", serverLabels...),
+		serverMSPT:          desc("server_mspt", "Milliseconds per tick, last minute average (Paper and Purpur).", serverLabels...),
+		serverUptime:        desc("server_uptime_seconds", "Time since the server was started.", serverLabels...),
+		agents:              desc("agents", "Registered agents by connection status.", "status"),
+		agentUp:             desc("agent_up", "Whether the agent passes health checks.", agentLabels...),
+		agentFailures:       desc("agent_consecutive_failures", "Consecutive failed health checks.", agentLabels...),
+		agentLastSeen:       desc("agent_last_seen_timestamp_seconds", "Last time the agent answered.", agentLabels...),
+		agentCPU:            desc("agent_cpu_percent", "Host CPU usage reported by the agent.", agentLabels...),
+		agentMemoryUsed:     desc("agent_memory_used_bytes", "Host memory in use.", agentLabels...),
+		agentMemoryTotal:    desc("agent_memory_total_bytes", "Host memory.", agentLabels...),
+		agentDiskUsed:       desc("agent_disk_used_bytes", "Disk in use on the agent work directory volume.", agentLabels...),
+		agentDiskTotal:      desc("agent_disk_total_bytes", "Size of the agent work directory volume.", agentLabels...),
+		websocketClients:    desc("websocket_clients", "Connected WebSocket clients."),
+		websocketChannels:   desc("websocket_channels", "WebSocket channels with at least one subscriber."),
+		websocketSubscribed: desc("websocket_subscriptions", "WebSocket client subscriptions by channel type.", "channel_type"),
+	}
+}
+
+// Describe implementa prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.servers, c.serverCPU, c.serverMemory, c.serverDisk, c.serverThreads, c.serverPlayers, c.serverTPS, c.serverMSPT, c.serverUptime,
+		c.agents, c.agentUp, c.agentFailures, c.agentLastSeen, c.agentCPU, c.agentMemoryUsed, c.agentMemoryTotal, c.agentDiskUsed, c.agentDiskTotal,
+		c.websocketClients, c.websocketChannels, c.websocketSubscribed,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implementa prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.collectServers(ch)
+	c.collectAgents(ch)
+	c.collectWebSocket(ch)
+}
+
+// collectServers exporta el número de servidores por estado y la última
+// muestra de cada servidor en ejecución
+func (c *Collector) collectServers(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	var counts []struct {
+		Status string
+		Count  int64
+	}
+	err := c.history.db.WithContext(ctx).Model(&models.Server{}).
+		Select("status, COUNT(*) AS count").Group("status").Scan(&counts).Error
+	if err != nil {
+		c.history.logger.Warn("Failed to count servers for metrics export", zap.Error(err))
+	}
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.servers, prometheus.GaugeValue, float64(count.Count), count.Status)
+	}
+
+	c.history.latestMu.RLock()
+	snapshots := c.history.latest
+	c.history.latestMu.RUnlock()
+
+	for _, snapshot := range snapshots {
+		labels := []string{snapshot.server.ID.String(), snapshot.server.Name, snapshot.server.AgentID.String()}
+		sample := snapshot.sample
+
+		gauge := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+		}
+		gauge(c.serverCPU, sample.CPUPercent)
+		gauge(c.serverMemory, float64(sample.MemoryUsed))
+		gauge(c.serverDisk, float64(sample.DiskUsed))
+		gauge(c.serverThreads, float64(sample.Threads))
+		gauge(c.serverUptime, float64(sample.UptimeSeconds))
+		if sample.PlayersOnline != nil {
+			gauge(c.serverPlayers, float64(*sample.PlayersOnline))
+		}
+		if sample.TPS != nil {
+			gauge(c.serverTPS, *sample.TPS)
+		}
+		if sample.MSPT != nil {
+			gauge(c.serverMSPT, *sample.MSPT)
+		}
+	}
+}
+
+// collectAgents exporta la salud y las métricas de sistema de los agentes
+func (c *Collector) collectAgents(ch chan<- prometheus.Metric) {
+	byStatus := make(map[agents.AgentStatus]int)
+	for _, agent := range c.health.AgentHealth() {
+		byStatus[agent.Status]++
+		labels := []string{agent.AgentID.String(), agent.Hostname}
+
+		gauge := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+		}
+		up := 0.0
+		if agent.Healthy {
+			up = 1
+		}
+		gauge(c.agentUp, up)
+		gauge(c.agentFailures, float64(agent.ConsecutiveFails))
+		if !agent.LastSeen.IsZero() {
+			gauge(c.agentLastSeen, float64(agent.LastSeen.Unix()))
+		}
+
+		// Sin métricas hasta el primer health check o evento del agente
+		if m := agent.Metrics; !m.LastUpdated.IsZero() {
+			gauge(c.agentCPU, m.CPUPercent)
+			gauge(c.agentMemoryUsed, float64(m.MemoryUsed))
+			gauge(c.agentMemoryTotal, float64(m.MemoryTotal))
+			gauge(c.agentDiskUsed, float64(m.DiskUsed))
+			gauge(c.agentDiskTotal, float64(m.DiskTotal))
+		}
+	}
+
+	for _, status := range []agents.AgentStatus{
+		agents.AgentStatusOnline, agents.AgentStatusOffline, agents.AgentStatusConnecting, agents.AgentStatusError,
+	} {
+		ch <- prometheus.MustNewConstMetric(c.agents, prometheus.GaugeValue, float64(byStatus[status]), string(status))
+	}
+}
+
+// collectWebSocket exporta los clientes y suscripciones del hub
+func (c *Collector) collectWebSocket(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.websocketClients, prometheus.GaugeValue, float64(c.hub.GetClientCount()))
+	ch <- prometheus.MustNewConstMetric(c.websocketChannels, prometheus.GaugeValue, float64(c.hub.GetSubscriptionCount()))
+	for channelType, count := range c.hub.GetSubscriptionsByType() {
+		ch <- prometheus.MustNewConstMetric(c.websocketSubscribed, prometheus.GaugeValue, float64(count), channelType)
+	}
+}
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	pb "github.com/aymc/backend/proto"
+	"go.uber.org/zap"
+)
+
+// agentMetricsMaxAge antigüedad máxima de las métricas en memoria de un
+// agente para guardarlas como muestra
+const agentMetricsMaxAge = 2 * time.Minute
+
+// serverSnapshot última muestra de un servidor junto a sus datos
+type serverSnapshot struct {
+	server models.Server
+	sample models.ServerMetric
+}
+
+// collect guarda una muestra de cada agente conectado y de cada servidor en
+// ejecución
+func (s *Service) collect(ctx context.Context, now time.Time) {
+	if err := s.collectAgents(now); err != nil {
+		s.logger.Error("Failed to store agent metrics", zap.Error(err))
+	}
+	if err := s.collectServers(ctx, now); err != nil {
+		s.logger.Error("Failed to store server metrics", zap.Error(err))
+	}
+}
+
+// collectAgents guarda las métricas de sistema que los agentes publican por
+// su stream de eventos o el health check
+func (s *Service) collectAgents(now time.Time) error {
+	var samples []models.AgentMetric
+	for _, conn := range s.agentService.GetRegistry().GetOnlineAgents() {
+		metrics := conn.GetMetrics()
+		if metrics.LastUpdated.IsZero() || now.Sub(metrics.LastUpdated) > agentMetricsMaxAge {
+			continue
+		}
+		samples = append(samples, models.AgentMetric{
+			AgentID:       conn.Agent.ID,
+			Timestamp:     now,
+			CPUPercent:    metrics.CPUPercent,
+			MemoryUsed:    int64(metrics.MemoryUsed),
+			MemoryPercent: metrics.MemoryPercent,
+			DiskUsed:      int64(metrics.DiskUsed),
+			DiskPercent:   metrics.DiskPercent,
+		})
+	}
+
+	if len(samples) == 0 {
+		return nil
+	}
+	return s.db.Create(&samples).Error
+}
+
+// collectServers consulta en paralelo las métricas de los servidores en
+// ejecución. Los que no responden (agente caído, proceso recién detenido)
+// se omiten en esta muestra.
+func (s *Service) collectServers(ctx context.Context, now time.Time) error {
+	var servers []models.Server
+	if err := s.db.Where("status = ?", models.ServerStatusRunning).Find(&servers).Error; err != nil {
+		return err
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		samples   []models.ServerMetric
+		snapshots []serverSnapshot
+	)
+	for i := range servers {
+		wg.Add(1)
+		go func(server *models.Server) {
+			defer wg.Done()
+
+			metrics, err := s.agentService.GetServerMetrics(ctx, server.AgentID, server.ID.String())
+			if err != nil {
+				s.logger.Debug("Skipping server metrics sample",
+					zap.String("server_id", server.ID.String()),
+					zap.Error(err),
+				)
+				return
+			}
+
+			sample := serverSample(server, metrics, now)
+			mu.Lock()
+			samples = append(samples, sample)
+			snapshots = append(snapshots, serverSnapshot{server: *server, sample: sample})
+			mu.Unlock()
+		}(&servers[i])
+	}
+	wg.Wait()
+
+	// Los servidores que no respondieron dejan de exportarse
+	s.latestMu.Lock()
+	s.latest = snapshots
+	s.latestMu.Unlock()
+
+	if len(samples) == 0 {
+		return nil
+	}
+	return s.db.Create(&samples).Error
+}
+
+// serverSample construye la muestra de un servidor. Las estadísticas de
+// juego solo se incluyen si el agente las tiene.
+func serverSample(server *models.Server, metrics *pb.ServerMetrics, now time.Time) models.ServerMetric {
+	sample := models.ServerMetric{
+		ServerID:   server.ID,
+		Timestamp:  now,
+		CPUPercent: metrics.CpuPercent,
+		MemoryUsed: int64(metrics.MemoryRss),
+		DiskUsed:   int64(metrics.DiskUsed),
+		Threads:    int(metrics.Threads),
+	}
+	if server.LastStarted != nil {
+		sample.UptimeSeconds = int64(now.Sub(*server.LastStarted).Seconds())
+	}
+
+	if metrics.StatsTimestamp > 0 {
+		players := int(metrics.PlayersOnline)
+		sample.PlayersOnline = &players
+		// El agente envía 0 si el software no tiene los comandos tps/mspt
+		if metrics.Tps > 0 {
+			tps := metrics.Tps
+			sample.TPS = &tps
+		}
+		if metrics.Mspt > 0 {
+			mspt := metrics.Mspt
+			sample.MSPT = &mspt
+		}
+	}
+
+	return sample
+}
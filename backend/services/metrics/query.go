@@ -0,0 +1,250 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// targetPoints puntos por serie que busca la resolución automática
+	targetPoints = 500
+
+	// maxPoints puntos máximos por serie de una consulta
+	maxPoints = 2000
+)
+
+var (
+	ErrServerNotFound    = errors.New("server not found")
+	ErrAgentNotFound     = errors.New("agent not found")
+	ErrInvalidMetric     = errors.New("invalid metric")
+	ErrInvalidResolution = errors.New("invalid resolution")
+	ErrInvalidRange      = errors.New("invalid time range")
+	ErrTooManyPoints     = errors.New("too many points for the requested resolution")
+)
+
+// HistoryQuery rango y métricas de una consulta de histórico
+type HistoryQuery struct {
+	Metrics    []string // Vacío = todas las del origen
+	From       time.Time
+	To         time.Time
+	Resolution Resolution // raw, 1m, 5m, 1h o auto
+}
+
+// HistoryPoint valor agregado de una métrica en un bucket. Con resolución
+// raw cada punto es una muestra y avg, min y max coinciden.
+type HistoryPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Avg       float64   `json:"avg"`
+	Min       float64   `json:"min"`
+	Max       float64   `json:"max"`
+	Count     int64     `json:"count"`
+}
+
+// HistorySeries puntos de una métrica
+type HistorySeries struct {
+	Metric string         `json:"metric"`
+	Points []HistoryPoint `json:"points"`
+}
+
+// HistoryResponse histórico de métricas de un servidor o agente
+type HistoryResponse struct {
+	SourceType string          `json:"source_type"`
+	SourceID   uuid.UUID       `json:"source_id"`
+	Resolution Resolution      `json:"resolution"`
+	From       time.Time       `json:"from"`
+	To         time.Time       `json:"to"`
+	Series     []HistorySeries `json:"series"`
+}
+
+// ServerHistory retorna el histórico de métricas de un servidor accesible
+// por el usuario
+func (s *Service) ServerHistory(ctx context.Context, serverID, userID uuid.UUID, isAdmin bool, query HistoryQuery) (*HistoryResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var server models.Server
+	q := db.Select("id")
+	if !isAdmin {
+		q = q.Where("user_id = ?", userID)
+	}
+	if err := q.First(&server, "id = ?", serverID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrServerNotFound
+		}
+		return nil, fmt.Errorf("failed to query server: %w", err)
+	}
+
+	return s.history(db, models.MetricSourceServer, serverID, ServerMetricNames, query)
+}
+
+// AgentHistory retorna el histórico de métricas de sistema de un agente
+func (s *Service) AgentHistory(ctx context.Context, agentID uuid.UUID, query HistoryQuery) (*HistoryResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var agent models.Agent
+	if err := db.Select("id").First(&agent, "id = ?", agentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAgentNotFound
+		}
+		return nil, fmt.Errorf("failed to query agent: %w", err)
+	}
+
+	return s.history(db, models.MetricSourceAgent, agentID, AgentMetricNames, query)
+}
+
+// history valida la consulta, elige la resolución y carga las series
+func (s *Service) history(db *gorm.DB, sourceType string, sourceID uuid.UUID, available []string, query HistoryQuery) (*HistoryResponse, error) {
+	metrics, err := selectMetrics(query.Metrics, available)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	to := query.To
+	if to.IsZero() {
+		to = now
+	}
+	from := query.From
+	if from.IsZero() {
+		from = to.Add(-time.Hour)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+
+	resolution := query.Resolution
+	switch resolution {
+	case "", "auto":
+		resolution = s.chooseResolution(from.UTC(), to.UTC(), now)
+	case ResolutionRaw, Resolution1m, Resolution5m, Resolution1h:
+		if to.Sub(from)/s.step(resolution) > maxPoints {
+			return nil, ErrTooManyPoints
+		}
+	default:
+		return nil, ErrInvalidResolution
+	}
+
+	var rollups []models.MetricRollup
+	if resolution == ResolutionRaw {
+		rollups, err = loadRawSeries(db, sourceType, sourceID, from, to)
+	} else {
+		err = db.Where("source_type = ? AND source_id = ? AND resolution = ? AND metric IN ? AND bucket_start >= ? AND bucket_start < ?",
+			sourceType, sourceID, resolution, metrics, from.Truncate(s.step(resolution)), to).
+			Order("bucket_start").
+			Find(&rollups).Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics history: %w", err)
+	}
+
+	return &HistoryResponse{
+		SourceType: sourceType,
+		SourceID:   sourceID,
+		Resolution: resolution,
+		From:       from.UTC(),
+		To:         to.UTC(),
+		Series:     buildSeries(metrics, rollups),
+	}, nil
+}
+
+// step duración de un punto en una resolución
+func (s *Service) step(resolution Resolution) time.Duration {
+	if resolution == ResolutionRaw {
+		return s.config.SampleInterval
+	}
+	for _, level := range rollupLevels {
+		if level.resolution == resolution {
+			return level.step
+		}
+	}
+	return time.Hour
+}
+
+// chooseResolution elige la resolución más fina que cubre el rango con
+// targetPoints puntos como mucho y cuyos datos siguen retenidos desde from
+func (s *Service) chooseResolution(from, to, now time.Time) Resolution {
+	for _, resolution := range []Resolution{ResolutionRaw, Resolution1m, Resolution5m} {
+		if to.Sub(from)/s.step(resolution) > targetPoints {
+			continue
+		}
+		if from.Before(now.Add(-s.config.retention(resolution))) {
+			continue
+		}
+		return resolution
+	}
+	return Resolution1h
+}
+
+// selectMetrics valida las métricas pedidas. Sin métricas se retornan todas.
+func selectMetrics(requested, available []string) ([]string, error) {
+	if len(requested) == 0 {
+		return available, nil
+	}
+	selected := make([]string, 0, len(requested))
+	seen := make(map[string]bool, len(requested))
+	for _, metric := range requested {
+		found := false
+		for _, name := range available {
+			if metric == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMetric, metric)
+		}
+		if !seen[metric] {
+			seen[metric] = true
+			selected = append(selected, metric)
+		}
+	}
+	return selected, nil
+}
+
+// loadRawSeries carga las muestras de un origen en [from, to) como rollups
+// de un valor
+func loadRawSeries(db *gorm.DB, sourceType string, sourceID uuid.UUID, from, to time.Time) ([]models.MetricRollup, error) {
+	if sourceType == models.MetricSourceServer {
+		var samples []models.ServerMetric
+		err := db.Where("server_id = ? AND timestamp >= ? AND timestamp < ?", sourceID, from, to).
+			Order("timestamp").Find(&samples).Error
+		return serverRollups(samples), err
+	}
+
+	var samples []models.AgentMetric
+	err := db.Where("agent_id = ? AND timestamp >= ? AND timestamp < ?", sourceID, from, to).
+		Order("timestamp").Find(&samples).Error
+	return agentRollups(samples), err
+}
+
+// buildSeries agrupa los rollups (ordenados por instante) en una serie por
+// métrica, en el orden de metrics
+func buildSeries(metrics []string, rollups []models.MetricRollup) []HistorySeries {
+	index := make(map[string]int, len(metrics))
+	series := make([]HistorySeries, len(metrics))
+	for i, metric := range metrics {
+		index[metric] = i
+		series[i] = HistorySeries{Metric: metric, Points: []HistoryPoint{}}
+	}
+
+	for _, r := range rollups {
+		i, ok := index[r.Metric]
+		if !ok || r.Count == 0 {
+			continue
+		}
+		series[i].Points = append(series[i].Points, HistoryPoint{
+			Timestamp: r.BucketStart.UTC(),
+			Avg:       r.Sum / float64(r.Count),
+			Min:       r.Min,
+			Max:       r.Max,
+			Count:     r.Count,
+		})
+	}
+
+	return series
+}
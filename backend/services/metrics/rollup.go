@@ -0,0 +1,339 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Resolution granularidad de una serie de métricas
+type Resolution string
+
+const (
+	ResolutionRaw Resolution = "raw"
+	Resolution1m  Resolution = "1m"
+	Resolution5m  Resolution = "5m"
+	Resolution1h  Resolution = "1h"
+)
+
+// rollupDelay margen antes de cerrar un bucket de 1m, para que terminen de
+// guardarse las muestras tomadas al final del minuto
+const rollupDelay = time.Minute
+
+// rollupLevel resolución de rollup y la resolución de la que se calcula
+type rollupLevel struct {
+	resolution Resolution
+	step       time.Duration
+	source     Resolution
+}
+
+// rollupLevels en orden: cada nivel se calcula a partir del anterior
+var rollupLevels = []rollupLevel{
+	{Resolution1m, time.Minute, ResolutionRaw},
+	{Resolution5m, 5 * time.Minute, Resolution1m},
+	{Resolution1h, time.Hour, Resolution5m},
+}
+
+// Métricas guardadas de cada tipo de origen
+var (
+	ServerMetricNames = []string{"cpu_percent", "memory_used", "disk_used", "threads", "players_online", "tps", "mspt"}
+	AgentMetricNames  = []string{"cpu_percent", "memory_used", "memory_percent", "disk_used", "disk_percent"}
+)
+
+// point valor de una métrica en un instante
+type point struct {
+	metric string
+	value  float64
+}
+
+// serverPoints retorna los valores de una muestra de servidor. Las
+// estadísticas de juego ausentes no generan punto.
+func serverPoints(m *models.ServerMetric) []point {
+	points := []point{
+		{"cpu_percent", m.CPUPercent},
+		{"memory_used", float64(m.MemoryUsed)},
+		{"disk_used", float64(m.DiskUsed)},
+		{"threads", float64(m.Threads)},
+	}
+	if m.PlayersOnline != nil {
+		points = append(points, point{"players_online", float64(*m.PlayersOnline)})
+	}
+	if m.TPS != nil {
+		points = append(points, point{"tps", *m.TPS})
+	}
+	if m.MSPT != nil {
+		points = append(points, point{"mspt", *m.MSPT})
+	}
+	return points
+}
+
+// agentPoints retorna los valores de una muestra de agente
+func agentPoints(m *models.AgentMetric) []point {
+	return []point{
+		{"cpu_percent", m.CPUPercent},
+		{"memory_used", float64(m.MemoryUsed)},
+		{"memory_percent", m.MemoryPercent},
+		{"disk_used", float64(m.DiskUsed)},
+		{"disk_percent", m.DiskPercent},
+	}
+}
+
+// sampleRollup representa una muestra como un rollup de un solo valor, para
+// agregar muestras y rollups con el mismo código
+func sampleRollup(sourceType string, sourceID uuid.UUID, timestamp time.Time, p point) models.MetricRollup {
+	return models.MetricRollup{
+		SourceType:  sourceType,
+		SourceID:    sourceID,
+		Resolution:  string(ResolutionRaw),
+		Metric:      p.metric,
+		BucketStart: timestamp,
+		Count:       1,
+		Sum:         p.value,
+		Min:         p.value,
+		Max:         p.value,
+	}
+}
+
+// serverRollups convierte muestras de servidores en rollups de un valor
+func serverRollups(samples []models.ServerMetric) []models.MetricRollup {
+	var rollups []models.MetricRollup
+	for i := range samples {
+		for _, p := range serverPoints(&samples[i]) {
+			rollups = append(rollups, sampleRollup(models.MetricSourceServer, samples[i].ServerID, samples[i].Timestamp, p))
+		}
+	}
+	return rollups
+}
+
+// agentRollups convierte muestras de agentes en rollups de un valor
+func agentRollups(samples []models.AgentMetric) []models.MetricRollup {
+	var rollups []models.MetricRollup
+	for i := range samples {
+		for _, p := range agentPoints(&samples[i]) {
+			rollups = append(rollups, sampleRollup(models.MetricSourceAgent, samples[i].AgentID, samples[i].Timestamp, p))
+		}
+	}
+	return rollups
+}
+
+// rollupKey identifica el bucket de una serie
+type rollupKey struct {
+	sourceType string
+	sourceID   string
+	metric     string
+	bucket     time.Time
+}
+
+// mergeRollups agrega rollups (o muestras convertidas) en buckets de step.
+// El resultado se ordena por origen, métrica e instante.
+func mergeRollups(rollups []models.MetricRollup, resolution Resolution, step time.Duration) []models.MetricRollup {
+	buckets := make(map[rollupKey]*models.MetricRollup)
+	for _, r := range rollups {
+		key := rollupKey{
+			sourceType: r.SourceType,
+			sourceID:   r.SourceID.String(),
+			metric:     r.Metric,
+			bucket:     r.BucketStart.UTC().Truncate(step),
+		}
+		bucket, ok := buckets[key]
+		if !ok {
+			buckets[key] = &models.MetricRollup{
+				SourceType:  r.SourceType,
+				SourceID:    r.SourceID,
+				Resolution:  string(resolution),
+				Metric:      r.Metric,
+				BucketStart: key.bucket,
+				Count:       r.Count,
+				Sum:         r.Sum,
+				Min:         r.Min,
+				Max:         r.Max,
+			}
+			continue
+		}
+		bucket.Count += r.Count
+		bucket.Sum += r.Sum
+		if r.Min < bucket.Min {
+			bucket.Min = r.Min
+		}
+		if r.Max > bucket.Max {
+			bucket.Max = r.Max
+		}
+	}
+
+	merged := make([]models.MetricRollup, 0, len(buckets))
+	for _, bucket := range buckets {
+		merged = append(merged, *bucket)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		a, b := merged[i], merged[j]
+		if a.SourceType != b.SourceType {
+			return a.SourceType < b.SourceType
+		}
+		if a.SourceID != b.SourceID {
+			return a.SourceID.String() < b.SourceID.String()
+		}
+		if a.Metric != b.Metric {
+			return a.Metric < b.Metric
+		}
+		return a.BucketStart.Before(b.BucketStart)
+	})
+	return merged
+}
+
+// downsample calcula los buckets cerrados de cada resolución. Un nivel solo
+// avanza hasta donde el nivel del que se calcula está completo.
+func (s *Service) downsample(ctx context.Context, now time.Time) error {
+	db := s.db.WithContext(ctx)
+	complete := now.Add(-rollupDelay)
+
+	for _, level := range rollupLevels {
+		end := complete.Truncate(level.step)
+		complete = end
+
+		start, err := s.watermark(db, level)
+		if err != nil {
+			return err
+		}
+		if start.IsZero() {
+			continue // Aún no hay datos de origen
+		}
+
+		// Ventanas acotadas para no cargar demasiadas filas al ponerse al día
+		window := 60 * level.step
+		for start.Before(end) {
+			windowEnd := start.Add(window)
+			if windowEnd.After(end) {
+				windowEnd = end
+			}
+
+			source, err := loadRollupSource(db, level.source, start, windowEnd)
+			if err != nil {
+				return err
+			}
+			if err := saveRollups(db, mergeRollups(source, level.resolution, level.step)); err != nil {
+				return err
+			}
+
+			start = windowEnd
+			s.watermarks[level.resolution] = start
+		}
+	}
+
+	return nil
+}
+
+// watermark retorna el inicio del siguiente bucket a calcular. Tras un
+// reinicio se continúa desde el último bucket guardado; si no hay ninguno,
+// desde el dato de origen más antiguo.
+func (s *Service) watermark(db *gorm.DB, level rollupLevel) (time.Time, error) {
+	if start, ok := s.watermarks[level.resolution]; ok {
+		return start, nil
+	}
+
+	var last *time.Time
+	if err := db.Model(&models.MetricRollup{}).
+		Where("resolution = ?", level.resolution).
+		Select("MAX(bucket_start)").Scan(&last).Error; err != nil {
+		return time.Time{}, fmt.Errorf("error reading %s rollups: %w", level.resolution, err)
+	}
+	if last != nil {
+		start := last.UTC().Add(level.step)
+		s.watermarks[level.resolution] = start
+		return start, nil
+	}
+
+	first, err := oldestSource(db, level.source)
+	if err != nil || first == nil {
+		return time.Time{}, err
+	}
+	start := first.UTC().Truncate(level.step)
+	s.watermarks[level.resolution] = start
+	return start, nil
+}
+
+// oldestSource retorna el instante del dato más antiguo de una resolución,
+// o nil si no hay datos
+func oldestSource(db *gorm.DB, resolution Resolution) (*time.Time, error) {
+	if resolution != ResolutionRaw {
+		var first *time.Time
+		err := db.Model(&models.MetricRollup{}).
+			Where("resolution = ?", resolution).
+			Select("MIN(bucket_start)").Scan(&first).Error
+		return first, err
+	}
+
+	var serverFirst, agentFirst *time.Time
+	if err := db.Model(&models.ServerMetric{}).Select("MIN(timestamp)").Scan(&serverFirst).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.AgentMetric{}).Select("MIN(timestamp)").Scan(&agentFirst).Error; err != nil {
+		return nil, err
+	}
+	if serverFirst == nil || (agentFirst != nil && agentFirst.Before(*serverFirst)) {
+		return agentFirst, nil
+	}
+	return serverFirst, nil
+}
+
+// loadRollupSource carga los datos de una resolución en [from, to) como
+// rollups
+func loadRollupSource(db *gorm.DB, resolution Resolution, from, to time.Time) ([]models.MetricRollup, error) {
+	if resolution != ResolutionRaw {
+		var rollups []models.MetricRollup
+		err := db.Where("resolution = ? AND bucket_start >= ? AND bucket_start < ?", resolution, from, to).
+			Find(&rollups).Error
+		return rollups, err
+	}
+
+	var serverSamples []models.ServerMetric
+	if err := db.Where("timestamp >= ? AND timestamp < ?", from, to).Find(&serverSamples).Error; err != nil {
+		return nil, err
+	}
+	var agentSamples []models.AgentMetric
+	if err := db.Where("timestamp >= ? AND timestamp < ?", from, to).Find(&agentSamples).Error; err != nil {
+		return nil, err
+	}
+	return append(serverRollups(serverSamples), agentRollups(agentSamples)...), nil
+}
+
+// saveRollups guarda los rollups reemplazando los buckets ya existentes
+func saveRollups(db *gorm.DB, rollups []models.MetricRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "source_type"}, {Name: "source_id"}, {Name: "resolution"}, {Name: "metric"}, {Name: "bucket_start"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"count", "sum", "min", "max"}),
+	}).CreateInBatches(&rollups, 500).Error
+}
+
+// prune borra las muestras y rollups que superan su retención
+func (s *Service) prune(ctx context.Context, now time.Time) error {
+	db := s.db.WithContext(ctx)
+
+	rawCutoff := now.Add(-s.config.RawRetention)
+	if err := db.Where("timestamp < ?", rawCutoff).Delete(&models.ServerMetric{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("timestamp < ?", rawCutoff).Delete(&models.AgentMetric{}).Error; err != nil {
+		return err
+	}
+
+	for _, level := range rollupLevels {
+		cutoff := now.Add(-s.config.retention(level.resolution))
+		if err := db.Where("resolution = ? AND bucket_start < ?", level.resolution, cutoff).
+			Delete(&models.MetricRollup{}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
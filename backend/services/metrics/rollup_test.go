@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+)
+
+func TestServerRollupsSkipsMissingGameStats(t *testing.T) {
+	players := 3
+	tps := 19.5
+	serverID := uuid.New()
+	at := time.Date(2024, 1, 15, 10, 0, 10, 0, time.UTC)
+
+	rollups := serverRollups([]models.ServerMetric{
+		{ServerID: serverID, Timestamp: at, CPUPercent: 50, MemoryUsed: 1024, PlayersOnline: &players, TPS: &tps},
+		{ServerID: serverID, Timestamp: at.Add(30 * time.Second), CPUPercent: 70},
+	})
+
+	counts := make(map[string]int)
+	for _, r := range rollups {
+		if r.SourceType != models.MetricSourceServer || r.SourceID != serverID {
+			t.Fatalf("rollup with wrong source: %+v", r)
+		}
+		counts[r.Metric]++
+	}
+	for metric, want := range map[string]int{"cpu_percent": 2, "memory_used": 2, "players_online": 1, "tps": 1, "mspt": 0} {
+		if counts[metric] != want {
+			t.Errorf("%s: got %d points, want %d", metric, counts[metric], want)
+		}
+	}
+}
+
+func TestMergeRollups(t *testing.T) {
+	agentID := uuid.New()
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	var samples []models.AgentMetric
+	for i, cpu := range []float64{10, 30, 20, 60} {
+		samples = append(samples, models.AgentMetric{
+			AgentID:    agentID,
+			Timestamp:  base.Add(time.Duration(i) * 30 * time.Second),
+			CPUPercent: cpu,
+		})
+	}
+
+	var cpu []models.MetricRollup
+	for _, r := range mergeRollups(agentRollups(samples), Resolution1m, time.Minute) {
+		if r.Metric == "cpu_percent" {
+			cpu = append(cpu, r)
+		}
+	}
+
+	want := []models.MetricRollup{
+		{BucketStart: base, Count: 2, Sum: 40, Min: 10, Max: 30},
+		{BucketStart: base.Add(time.Minute), Count: 2, Sum: 80, Min: 20, Max: 60},
+	}
+	if len(cpu) != len(want) {
+		t.Fatalf("got %d buckets, want %d", len(cpu), len(want))
+	}
+	for i, w := range want {
+		got := cpu[i]
+		if !got.BucketStart.Equal(w.BucketStart) || got.Count != w.Count || got.Sum != w.Sum || got.Min != w.Min || got.Max != w.Max {
+			t.Errorf("bucket %d: got %+v, want %+v", i, got, w)
+		}
+		if got.Resolution != string(Resolution1m) || got.SourceID != agentID {
+			t.Errorf("bucket %d: wrong resolution or source: %+v", i, got)
+		}
+	}
+
+	// Agregar los rollups de 1m en 5m conserva count, sum, min y max
+	coarse := mergeRollups(cpu, Resolution5m, 5*time.Minute)
+	if len(coarse) != 1 {
+		t.Fatalf("got %d 5m buckets, want 1", len(coarse))
+	}
+	if got := coarse[0]; got.Count != 4 || got.Sum != 120 || got.Min != 10 || got.Max != 60 || !got.BucketStart.Equal(base) {
+		t.Errorf("5m bucket: got %+v", got)
+	}
+}
+
+func TestChooseResolution(t *testing.T) {
+	s := NewService(nil, nil, Config{}, nil)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		from time.Duration // Antes de now
+		to   time.Duration
+		want Resolution
+	}{
+		{"last hour", time.Hour, 0, ResolutionRaw},
+		{"last day", 24 * time.Hour, 0, Resolution5m},
+		{"six hours", 6 * time.Hour, 0, Resolution1m},
+		{"last week", 7 * 24 * time.Hour, 0, Resolution1h},
+		{"hour beyond raw retention", 49 * time.Hour, 48 * time.Hour, Resolution1m},
+		{"hour beyond 1m retention", 10 * 24 * time.Hour, 10*24*time.Hour - time.Hour, Resolution5m},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.chooseResolution(now.Add(-tt.from), now.Add(-tt.to), now)
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectMetrics(t *testing.T) {
+	got, err := selectMetrics(nil, AgentMetricNames)
+	if err != nil || len(got) != len(AgentMetricNames) {
+		t.Fatalf("default metrics: got %v, %v", got, err)
+	}
+
+	got, err = selectMetrics([]string{"tps", "cpu_percent", "tps"}, ServerMetricNames)
+	if err != nil || len(got) != 2 || got[0] != "tps" || got[1] != "cpu_percent" {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	if _, err := selectMetrics([]string{"tps"}, AgentMetricNames); !errors.Is(err, ErrInvalidMetric) {
+		t.Errorf("agent tps: got %v, want ErrInvalidMetric", err)
+	}
+}
+
+func TestBuildSeries(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	series := buildSeries([]string{"tps", "mspt"}, []models.MetricRollup{
+		{Metric: "tps", BucketStart: base, Count: 4, Sum: 78, Min: 18, Max: 20},
+		{Metric: "cpu_percent", BucketStart: base, Count: 1, Sum: 5, Min: 5, Max: 5},
+	})
+
+	if len(series) != 2 || series[0].Metric != "tps" || series[1].Metric != "mspt" {
+		t.Fatalf("got %+v", series)
+	}
+	if len(series[0].Points) != 1 || series[0].Points[0].Avg != 19.5 {
+		t.Errorf("tps points: got %+v", series[0].Points)
+	}
+	// Las series sin datos se retornan vacías, no nil, para que el JSON sea []
+	if series[1].Points == nil || len(series[1].Points) != 0 {
+		t.Errorf("mspt points: got %#v", series[1].Points)
+	}
+}
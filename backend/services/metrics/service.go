@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aymc/backend/services/agents"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// maintenanceInterval frecuencia con la que se calculan los rollups
+	maintenanceInterval = time.Minute
+
+	// pruneInterval frecuencia con la que se borran los datos caducados
+	pruneInterval = 10 * time.Minute
+)
+
+// Config define el muestreo y la retención de cada resolución
+type Config struct {
+	SampleInterval    time.Duration
+	RawRetention      time.Duration
+	Rollup1mRetention time.Duration
+	Rollup5mRetention time.Duration
+	Rollup1hRetention time.Duration
+}
+
+// withDefaults completa los valores no configurados
+func (c Config) withDefaults() Config {
+	if c.SampleInterval <= 0 {
+		c.SampleInterval = 30 * time.Second
+	}
+	if c.RawRetention <= 0 {
+		c.RawRetention = 24 * time.Hour
+	}
+	if c.Rollup1mRetention <= 0 {
+		c.Rollup1mRetention = 7 * 24 * time.Hour
+	}
+	if c.Rollup5mRetention <= 0 {
+		c.Rollup5mRetention = 30 * 24 * time.Hour
+	}
+	if c.Rollup1hRetention <= 0 {
+		c.Rollup1hRetention = 365 * 24 * time.Hour
+	}
+	return c
+}
+
+// retention retorna cuánto se conservan los datos de una resolución
+func (c Config) retention(resolution Resolution) time.Duration {
+	switch resolution {
+	case Resolution1m:
+		return c.Rollup1mRetention
+	case Resolution5m:
+		return c.Rollup5mRetention
+	case Resolution1h:
+		return c.Rollup1hRetention
+	}
+	return c.RawRetention
+}
+
+// Service guarda el histórico de métricas de servidores y agentes: toma
+// muestras periódicas, las agrega en rollups de 1m, 5m y 1h, borra los
+// datos que superan la retención y responde consultas por rango
+type Service struct {
+	db           *gorm.DB
+	agentService *agents.AgentService
+	config       Config
+	logger       *zap.Logger
+
+	// Siguiente bucket a calcular de cada resolución de rollup
+	watermarks map[Resolution]time.Time
+	lastPrune  time.Time
+
+	// Última muestra de cada servidor en ejecución, para el exportador
+	latestMu sync.RWMutex
+	latest   []serverSnapshot
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService crea el servicio de histórico de métricas
+func NewService(db *gorm.DB, agentService *agents.AgentService, config Config, logger *zap.Logger) *Service {
+	return &Service{
+		db:           db,
+		agentService: agentService,
+		config:       config.withDefaults(),
+		logger:       logger,
+		watermarks:   make(map[Resolution]time.Time),
+	}
+}
+
+// Start inicia la toma de muestras y el mantenimiento de rollups
+func (s *Service) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.logger.Info("Starting metrics history",
+		zap.Duration("sample_interval", s.config.SampleInterval),
+		zap.Duration("raw_retention", s.config.RawRetention),
+	)
+
+	s.wg.Add(2)
+	go s.run(ctx, s.config.SampleInterval, s.collect)
+	go s.run(ctx, maintenanceInterval, s.maintain)
+}
+
+// Stop detiene el servicio y espera a que terminen las tareas en curso
+func (s *Service) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("Metrics history stopped")
+}
+
+// run ejecuta task cada interval hasta que se cancele ctx
+func (s *Service) run(ctx context.Context, interval time.Duration, task func(ctx context.Context, now time.Time)) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			task(ctx, now.UTC())
+		}
+	}
+}
+
+// maintain calcula los rollups pendientes y, cada pruneInterval, aplica la
+// retención
+func (s *Service) maintain(ctx context.Context, now time.Time) {
+	if err := s.downsample(ctx, now); err != nil {
+		s.logger.Error("Failed to compute metric rollups", zap.Error(err))
+	}
+
+	if now.Sub(s.lastPrune) < pruneInterval {
+		return
+	}
+	if err := s.prune(ctx, now); err != nil {
+		s.logger.Error("Failed to prune metrics history", zap.Error(err))
+		return
+	}
+	s.lastPrune = now
+}
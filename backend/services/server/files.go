@@ -0,0 +1,431 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aymc/backend/database"
+	"github.com/aymc/backend/database/models"
+	pb "github.com/aymc/backend/proto"
+	"github.com/aymc/backend/services/agents"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFileNotFound        = errors.New("file not found")
+	ErrFileAccessDenied    = errors.New("path outside the server directory")
+	ErrFileAlreadyExists   = errors.New("file already exists")
+	ErrInvalidFileRequest  = errors.New("invalid file request")
+	ErrChecksumMismatch    = errors.New("checksum mismatch")
+	ErrRangeNotSatisfiable = errors.New("requested range not satisfiable")
+)
+
+// FileError wraps an agent file error with the agent's message
+type FileError struct {
+	Kind    error
+	Message string
+}
+
+func (e *FileError) Error() string { return e.Message }
+func (e *FileError) Unwrap() error { return e.Kind }
+
+// FileEntry represents a file in a server directory listing
+type FileEntry struct {
+	Name         string `json:"name"`
+	Path         string `json:"path"`
+	Size         int64  `json:"size"`
+	IsDir        bool   `json:"is_dir"`
+	ModifiedTime int64  `json:"modified_time"`
+	Permissions  string `json:"permissions"` // octal, e.g. "0644"
+}
+
+// FileContentResponse represents the content of a text file
+type FileContentResponse struct {
+	Path         string `json:"path"`
+	Content      string `json:"content"`
+	Size         int64  `json:"size"`
+	ModifiedTime int64  `json:"modified_time"`
+}
+
+// FileOperationResponse represents the result of a file manager operation
+type FileOperationResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// WriteFileRequest represents a request to write a text file
+type WriteFileRequest struct {
+	Path       string `json:"path" binding:"required"`
+	Content    string `json:"content"`
+	CreateDirs bool   `json:"create_dirs"`
+}
+
+// MoveFileRequest represents a move/rename or copy request
+type MoveFileRequest struct {
+	Source      string `json:"source" binding:"required"`
+	Destination string `json:"destination" binding:"required"`
+	Overwrite   bool   `json:"overwrite"`
+}
+
+// CreateDirectoryRequest represents a mkdir request
+type CreateDirectoryRequest struct {
+	Path    string `json:"path" binding:"required"`
+	Parents bool   `json:"parents"`
+}
+
+// ChangePermissionsRequest represents a chmod request
+type ChangePermissionsRequest struct {
+	Path      string `json:"path" binding:"required"`
+	Mode      string `json:"mode" binding:"required"` // octal, e.g. "755"
+	Recursive bool   `json:"recursive"`
+}
+
+// CompressFilesRequest represents a request to archive a selection
+type CompressFilesRequest struct {
+	Paths       []string `json:"paths" binding:"required,min=1"`
+	Destination string   `json:"destination" binding:"required"`
+	Format      string   `json:"format" binding:"omitempty,oneof=zip tar.gz"`
+}
+
+// ExtractArchiveRequest represents a request to extract an archive in place
+type ExtractArchiveRequest struct {
+	Path        string `json:"path" binding:"required"`
+	Destination string `json:"destination"`
+	Overwrite   bool   `json:"overwrite"`
+}
+
+// UploadFileRequest describes a streamed upload. Offset resumes an
+// interrupted upload; Checksum is the optional SHA256 of the whole file.
+type UploadFileRequest struct {
+	Path       string
+	Offset     int64
+	CreateDirs bool
+	Checksum   string
+}
+
+// FileTransferResponse represents a completed upload
+type FileTransferResponse struct {
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"size_bytes"`
+	Checksum  string `json:"checksum"`
+	Message   string `json:"message"`
+}
+
+// UploadOffsetResponse represents the resume point of an interrupted upload
+type UploadOffsetResponse struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+}
+
+// fileServer loads a server the user can access and checks its agent is online
+func (s *ServerService) fileServer(serverID, userID uuid.UUID, isAdmin bool) (*models.Server, error) {
+	db := database.GetDB()
+
+	var server models.Server
+	query := db.Preload("Agent")
+
+	if !isAdmin {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	if err := query.First(&server, "id = ?", serverID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrServerNotFound
+		}
+		return nil, fmt.Errorf("failed to query server: %w", err)
+	}
+
+	if !server.Agent.IsOnline() {
+		return nil, ErrAgentOffline
+	}
+
+	return &server, nil
+}
+
+// fileError maps the gRPC code returned by the agent to a FileError
+func fileError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	var kind error
+	switch st.Code() {
+	case codes.NotFound:
+		kind = ErrFileNotFound
+	case codes.PermissionDenied:
+		kind = ErrFileAccessDenied
+	case codes.AlreadyExists:
+		kind = ErrFileAlreadyExists
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		kind = ErrInvalidFileRequest
+	case codes.DataLoss:
+		kind = ErrChecksumMismatch
+	case codes.OutOfRange:
+		kind = ErrRangeNotSatisfiable
+	default:
+		return err
+	}
+	return &FileError{Kind: kind, Message: st.Message()}
+}
+
+// ListFiles lists a directory inside the server directory
+func (s *ServerService) ListFiles(serverID, userID uuid.UUID, isAdmin bool, path string, recursive bool) ([]FileEntry, error) {
+	server, err := s.fileServer(serverID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.agentService.ListFiles(context.Background(), server.AgentID, &pb.DirectoryRequest{
+		ServerId:  serverID.String(),
+		Path:      path,
+		Recursive: recursive,
+	})
+	if err != nil {
+		return nil, fileError(err)
+	}
+
+	entries := make([]FileEntry, 0, len(list.Files))
+	for _, f := range list.Files {
+		entries = append(entries, FileEntry{
+			Name:         f.Name,
+			Path:         f.Path,
+			Size:         f.Size,
+			IsDir:        f.IsDir,
+			ModifiedTime: f.ModifiedTime,
+			Permissions:  fmt.Sprintf("%04o", f.Permissions),
+		})
+	}
+	return entries, nil
+}
+
+// ReadFile reads a file inside the server directory
+func (s *ServerService) ReadFile(serverID, userID uuid.UUID, isAdmin bool, path string) (*FileContentResponse, error) {
+	server, err := s.fileServer(serverID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.agentService.ReadFile(context.Background(), server.AgentID, &pb.FileRequest{
+		ServerId: serverID.String(),
+		Path:     path,
+	})
+	if err != nil {
+		return nil, fileError(err)
+	}
+
+	return &FileContentResponse{
+		Path:         path,
+		Content:      string(content.Content),
+		Size:         content.Size,
+		ModifiedTime: content.ModifiedTime,
+	}, nil
+}
+
+// WriteFile writes a file inside the server directory
+func (s *ServerService) WriteFile(serverID, userID uuid.UUID, isAdmin bool, req *WriteFileRequest) error {
+	server, err := s.fileServer(serverID, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+
+	err = s.agentService.WriteFile(context.Background(), server.AgentID, &pb.WriteFileRequest{
+		ServerId:   serverID.String(),
+		Path:       req.Path,
+		Content:    []byte(req.Content),
+		CreateDirs: req.CreateDirs,
+	})
+	if err != nil {
+		return fileError(err)
+	}
+
+	s.logger.Info("Server file written",
+		zap.String("server_id", serverID.String()),
+		zap.String("path", req.Path),
+	)
+	return nil
+}
+
+// DeleteFile deletes a file or directory inside the server directory
+func (s *ServerService) DeleteFile(serverID, userID uuid.UUID, isAdmin bool, path string, recursive bool) (*FileOperationResponse, error) {
+	return s.fileOperation(serverID, userID, isAdmin, func(ctx context.Context, server *models.Server) (*pb.FileResponse, error) {
+		return s.agentService.DeleteFile(ctx, server.AgentID, &pb.DeleteFileRequest{
+			ServerId:  serverID.String(),
+			Path:      path,
+			Recursive: recursive,
+		})
+	})
+}
+
+// MoveFile moves or renames a file or directory inside the server directory
+func (s *ServerService) MoveFile(serverID, userID uuid.UUID, isAdmin bool, req *MoveFileRequest) (*FileOperationResponse, error) {
+	return s.fileOperation(serverID, userID, isAdmin, func(ctx context.Context, server *models.Server) (*pb.FileResponse, error) {
+		return s.agentService.MoveFile(ctx, server.AgentID, &pb.MoveFileRequest{
+			ServerId:    serverID.String(),
+			Source:      req.Source,
+			Destination: req.Destination,
+			Overwrite:   req.Overwrite,
+		})
+	})
+}
+
+// CopyFile copies a file or directory inside the server directory
+func (s *ServerService) CopyFile(serverID, userID uuid.UUID, isAdmin bool, req *MoveFileRequest) (*FileOperationResponse, error) {
+	return s.fileOperation(serverID, userID, isAdmin, func(ctx context.Context, server *models.Server) (*pb.FileResponse, error) {
+		return s.agentService.CopyFile(ctx, server.AgentID, &pb.MoveFileRequest{
+			ServerId:    serverID.String(),
+			Source:      req.Source,
+			Destination: req.Destination,
+			Overwrite:   req.Overwrite,
+		})
+	})
+}
+
+// CreateDirectory creates a directory inside the server directory
+func (s *ServerService) CreateDirectory(serverID, userID uuid.UUID, isAdmin bool, req *CreateDirectoryRequest) (*FileOperationResponse, error) {
+	return s.fileOperation(serverID, userID, isAdmin, func(ctx context.Context, server *models.Server) (*pb.FileResponse, error) {
+		return s.agentService.CreateDirectory(ctx, server.AgentID, &pb.CreateDirectoryRequest{
+			ServerId: serverID.String(),
+			Path:     req.Path,
+			Parents:  req.Parents,
+		})
+	})
+}
+
+// ChangePermissions changes unix permissions inside the server directory.
+// mode is the octal permission string already parsed by the caller.
+func (s *ServerService) ChangePermissions(serverID, userID uuid.UUID, isAdmin bool, req *ChangePermissionsRequest, mode uint32) (*FileOperationResponse, error) {
+	return s.fileOperation(serverID, userID, isAdmin, func(ctx context.Context, server *models.Server) (*pb.FileResponse, error) {
+		return s.agentService.ChangePermissions(ctx, server.AgentID, &pb.ChangePermissionsRequest{
+			ServerId:  serverID.String(),
+			Path:      req.Path,
+			Mode:      mode,
+			Recursive: req.Recursive,
+		})
+	})
+}
+
+// CompressFiles archives a selection of files inside the server directory
+func (s *ServerService) CompressFiles(serverID, userID uuid.UUID, isAdmin bool, req *CompressFilesRequest) (*FileOperationResponse, error) {
+	return s.fileOperation(serverID, userID, isAdmin, func(ctx context.Context, server *models.Server) (*pb.FileResponse, error) {
+		return s.agentService.CompressFiles(ctx, server.AgentID, &pb.CompressFilesRequest{
+			ServerId:    serverID.String(),
+			Paths:       req.Paths,
+			Destination: req.Destination,
+			Format:      req.Format,
+		})
+	})
+}
+
+// ExtractArchive extracts an archive inside the server directory
+func (s *ServerService) ExtractArchive(serverID, userID uuid.UUID, isAdmin bool, req *ExtractArchiveRequest) (*FileOperationResponse, error) {
+	return s.fileOperation(serverID, userID, isAdmin, func(ctx context.Context, server *models.Server) (*pb.FileResponse, error) {
+		return s.agentService.ExtractArchive(ctx, server.AgentID, &pb.ExtractArchiveRequest{
+			ServerId:    serverID.String(),
+			Path:        req.Path,
+			Destination: req.Destination,
+			Overwrite:   req.Overwrite,
+		})
+	})
+}
+
+// fileOperation checks access to the server and runs a file manager RPC
+func (s *ServerService) fileOperation(serverID, userID uuid.UUID, isAdmin bool,
+	call func(context.Context, *models.Server) (*pb.FileResponse, error)) (*FileOperationResponse, error) {
+	server, err := s.fileServer(serverID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := call(context.Background(), server)
+	if err != nil {
+		return nil, fileError(err)
+	}
+
+	s.logger.Info("Server file operation completed",
+		zap.String("server_id", serverID.String()),
+		zap.String("message", resp.Message),
+	)
+
+	return &FileOperationResponse{
+		Success: resp.Success,
+		Message: resp.Message,
+	}, nil
+}
+
+// UploadFile streams reader to a file inside the server directory
+func (s *ServerService) UploadFile(ctx context.Context, serverID, userID uuid.UUID, isAdmin bool, req *UploadFileRequest, reader io.Reader) (*FileTransferResponse, error) {
+	server, err := s.fileServer(serverID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.agentService.UploadFile(ctx, server.AgentID, &pb.FileChunk{
+		ServerId:   serverID.String(),
+		Path:       req.Path,
+		Offset:     req.Offset,
+		CreateDirs: req.CreateDirs,
+		Checksum:   req.Checksum,
+	}, reader)
+	if err != nil {
+		return nil, fileError(err)
+	}
+
+	s.logger.Info("Server file uploaded",
+		zap.String("server_id", serverID.String()),
+		zap.String("path", resp.Path),
+		zap.Int64("size_bytes", resp.SizeBytes),
+	)
+
+	return &FileTransferResponse{
+		Path:      resp.Path,
+		SizeBytes: resp.SizeBytes,
+		Checksum:  resp.Checksum,
+		Message:   resp.Message,
+	}, nil
+}
+
+// GetUploadOffset returns how many bytes of an interrupted upload the agent kept
+func (s *ServerService) GetUploadOffset(serverID, userID uuid.UUID, isAdmin bool, path string) (*UploadOffsetResponse, error) {
+	server, err := s.fileServer(serverID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := s.agentService.GetUploadOffset(context.Background(), server.AgentID, &pb.FileRequest{
+		ServerId: serverID.String(),
+		Path:     path,
+	})
+	if err != nil {
+		return nil, fileError(err)
+	}
+
+	return &UploadOffsetResponse{Path: path, Offset: offset}, nil
+}
+
+// DownloadFile opens a file (or a byte range of it) inside the server
+// directory. A negative offset selects the last -offset bytes and length 0
+// reads to the end. The caller must close the download.
+func (s *ServerService) DownloadFile(ctx context.Context, serverID, userID uuid.UUID, isAdmin bool, path string, offset, length int64) (*agents.FileDownload, error) {
+	server, err := s.fileServer(serverID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	download, err := s.agentService.DownloadFile(ctx, server.AgentID, &pb.DownloadFileRequest{
+		ServerId: serverID.String(),
+		Path:     path,
+		Offset:   offset,
+		Length:   length,
+	})
+	if err != nil {
+		return nil, fileError(err)
+	}
+	return download, nil
+}
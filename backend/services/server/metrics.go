@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ServerMetricsResponse represents the resource usage of a running server
+// process and its children, as sampled by the agent
+type ServerMetricsResponse struct {
+	ServerID     uuid.UUID `json:"server_id"`
+	Timestamp    time.Time `json:"timestamp"`
+	PID          int32     `json:"pid"`
+	CPUPercent   float64   `json:"cpu_percent"` // 100 = one full core
+	MemoryRSS    uint64    `json:"memory_rss"`
+	Threads      int32     `json:"threads"`
+	OpenFiles    int32     `json:"open_files"`
+	ProcessCount int32     `json:"process_count"`
+	DiskUsed     uint64    `json:"disk_used"` // Size of the server directory
+
+	// Game stats collected periodically by the agent through Server List
+	// Ping, Query and, on Paper, the tps/mspt console commands. Nil until
+	// the first successful collection.
+	Game *GameStatsResponse `json:"game,omitempty"`
+}
+
+// GameStatsResponse represents players and tick performance of a server
+type GameStatsResponse struct {
+	CollectedAt   time.Time `json:"collected_at"`
+	Version       string    `json:"version,omitempty"`
+	PlayersOnline int32     `json:"players_online"`
+	MaxPlayers    int32     `json:"max_players"`
+	Players       []string  `json:"players"`
+	LatencyMs     int64     `json:"latency_ms"`
+	TPS           float64   `json:"tps,omitempty"`  // Last minute average, Paper only
+	MSPT          float64   `json:"mspt,omitempty"` // Last minute average, Paper only
+}
+
+// GetMetrics retrieves the live resource usage of a server process
+func (s *ServerService) GetMetrics(ctx context.Context, serverID, userID uuid.UUID, isAdmin bool) (*ServerMetricsResponse, error) {
+	server, err := s.fileServer(serverID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.agentService.GetServerMetrics(ctx, server.AgentID, serverID.String())
+	if err != nil {
+		// The agent answers NotFound for servers it never started and
+		// FailedPrecondition for stopped ones
+		switch status.Code(err) {
+		case codes.NotFound, codes.FailedPrecondition:
+			return nil, ErrServerNotRunning
+		}
+		return nil, err
+	}
+
+	response := &ServerMetricsResponse{
+		ServerID:     serverID,
+		Timestamp:    time.Unix(metrics.Timestamp, 0),
+		PID:          metrics.Pid,
+		CPUPercent:   metrics.CpuPercent,
+		MemoryRSS:    metrics.MemoryRss,
+		Threads:      metrics.Threads,
+		OpenFiles:    metrics.OpenFiles,
+		ProcessCount: metrics.ProcessCount,
+		DiskUsed:     metrics.DiskUsed,
+	}
+
+	if metrics.StatsTimestamp > 0 {
+		players := metrics.Players
+		if players == nil {
+			players = []string{}
+		}
+		response.Game = &GameStatsResponse{
+			CollectedAt:   time.Unix(metrics.StatsTimestamp, 0),
+			Version:       metrics.GameVersion,
+			PlayersOnline: metrics.PlayersOnline,
+			MaxPlayers:    metrics.MaxPlayers,
+			Players:       players,
+			LatencyMs:     metrics.LatencyMs,
+			TPS:           metrics.Tps,
+			MSPT:          metrics.Mspt,
+		}
+	}
+
+	return response, nil
+}
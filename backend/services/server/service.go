@@ -20,6 +20,7 @@ var (
 	ErrInvalidServerState  = errors.New("invalid server state for this operation")
 	ErrAgentNotFound       = errors.New("agent not found")
 	ErrAgentOffline        = errors.New("agent is offline")
+	ErrServerNotRunning    = errors.New("server is not running")
 )
 
 // CreateServerRequest represents the request to create a new server
@@ -359,8 +360,27 @@ func (s *ServerService) Delete(serverID, userID uuid.UUID, isAdmin bool) error {
 		return ErrInvalidServerState
 	}
 
-	// Delete server
-	if err := db.Delete(&server).Error; err != nil {
+	// Delete server along with its metrics history, alerts and scheduled tasks
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("server_id = ?", server.ID).Delete(&models.ServerMetric{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("source_type = ? AND source_id = ?", models.MetricSourceServer, server.ID).
+			Delete(&models.MetricRollup{}).Error; err != nil {
+			return err
+		}
+		if err := deleteServerAlerts(tx, server.ID); err != nil {
+			return err
+		}
+		if err := tx.Where("server_id = ?", server.ID).Delete(&models.ScheduledTaskRun{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("server_id = ?", server.ID).Delete(&models.ScheduledTask{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&server).Error
+	})
+	if err != nil {
 		s.logger.Error("Failed to delete server", zap.Error(err))
 		return fmt.Errorf("failed to delete server: %w", err)
 	}
@@ -373,6 +393,23 @@ func (s *ServerService) Delete(serverID, userID uuid.UUID, isAdmin bool) error {
 	return nil
 }
 
+// deleteServerAlerts removes the alerts of a server and the alert rules that
+// target it specifically. Rules without a target are kept.
+func deleteServerAlerts(tx *gorm.DB, serverID uuid.UUID) error {
+	rules := tx.Model(&models.AlertRule{}).Select("id").
+		Where("target_type = ? AND target_id = ?", models.AlertTargetServer, serverID)
+
+	if err := tx.Where("(target_type = ? AND target_id = ?) OR rule_id IN (?)", models.AlertTargetServer, serverID, rules).
+		Delete(&models.Alert{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM alert_rule_channels WHERE alert_rule_id IN (?)", rules).Error; err != nil {
+		return err
+	}
+	return tx.Where("target_type = ? AND target_id = ?", models.AlertTargetServer, serverID).
+		Delete(&models.AlertRule{}).Error
+}
+
 // toServerResponse converts a model to response format
 func (s *ServerService) toServerResponse(server *models.Server, agent *models.Agent, user *models.User) *ServerResponse {
 	resp := &ServerResponse{
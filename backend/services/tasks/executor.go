@@ -0,0 +1,419 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aymc/backend/api/websocket"
+	"github.com/aymc/backend/database/models"
+	"github.com/aymc/backend/services/server"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxRunsPerTask ejecuciones que se conservan en el historial de cada tarea
+	maxRunsPerTask = 50
+
+	// backupTimeout tiempo máximo de espera de una acción backup
+	backupTimeout = 2 * time.Hour
+
+	// backupPollInterval cada cuánto se consulta el estado del backup
+	backupPollInterval = 5 * time.Second
+
+	// maxOutputLength longitud máxima de la salida guardada de cada paso
+	maxOutputLength = 4096
+
+	defaultRestartWarning = "El servidor se reiniciará en {time}"
+	defaultStopWarning    = "El servidor se detendrá en {time}"
+)
+
+// execute registra una ejecución nueva de una tarea y la lanza en segundo
+// plano. Falla con ErrTaskRunning si la tarea ya se está ejecutando.
+func (s *Service) execute(task *models.ScheduledTask, trigger string, triggeredBy *uuid.UUID) (*models.ScheduledTaskRun, error) {
+	s.mu.Lock()
+	if s.running[task.ID] {
+		s.mu.Unlock()
+		return nil, ErrTaskRunning
+	}
+	s.running[task.ID] = true
+	s.mu.Unlock()
+
+	run := &models.ScheduledTaskRun{
+		TaskID:      task.ID,
+		ServerID:    task.ServerID,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Status:      models.TaskRunRunning,
+		Steps:       []models.TaskStepResult{},
+		StartedAt:   time.Now(),
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		s.finishRunning(task.ID)
+		return nil, fmt.Errorf("error creating task run: %w", err)
+	}
+
+	s.logger.Info("Running scheduled task",
+		zap.String("task_id", task.ID.String()),
+		zap.String("run_id", run.ID.String()),
+		zap.String("trigger", trigger),
+	)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.finishRunning(task.ID)
+		s.run(s.ctx, task, *run)
+	}()
+
+	return run, nil
+}
+
+// finishRunning libera la tarea para la siguiente ejecución
+func (s *Service) finishRunning(taskID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.running, taskID)
+	s.mu.Unlock()
+}
+
+// run ejecuta las acciones de una tarea en orden y registra el resultado.
+// Una acción fallida detiene la ejecución salvo que tenga continue_on_error.
+func (s *Service) run(ctx context.Context, task *models.ScheduledTask, run models.ScheduledTaskRun) {
+	var srv models.Server
+	if err := s.db.First(&srv, "id = ?", task.ServerID).Error; err != nil {
+		s.finish(task, &run, nil, fmt.Errorf("servidor no encontrado: %w", err))
+		return
+	}
+
+	if task.SkipIfStopped && !srv.IsRunning() {
+		run.Status = models.TaskRunSkipped
+		s.finish(task, &run, &srv, nil)
+		return
+	}
+
+	var runErr error
+	for i, action := range task.Actions {
+		step := models.TaskStepResult{
+			Index:     i,
+			Type:      action.Type,
+			Status:    models.TaskRunSuccess,
+			StartedAt: time.Now(),
+		}
+
+		output, err := s.runAction(ctx, task, action)
+		step.Output = truncate(output, maxOutputLength)
+		step.DurationMs = time.Since(step.StartedAt).Milliseconds()
+		if err != nil {
+			step.Status = models.TaskRunFailed
+			step.Error = err.Error()
+			if runErr == nil {
+				index := i
+				run.FailedStep = &index
+				runErr = fmt.Errorf("acción %d (%s): %w", i+1, action.Type, err)
+			}
+		}
+
+		run.Steps = append(run.Steps, step)
+		s.db.Model(&run).Update("steps", run.Steps)
+
+		if err != nil && (!action.ContinueOnError || ctx.Err() != nil) {
+			break
+		}
+	}
+
+	s.finish(task, &run, &srv, runErr)
+}
+
+// runAction ejecuta una acción y retorna su salida
+func (s *Service) runAction(ctx context.Context, task *models.ScheduledTask, action models.TaskAction) (string, error) {
+	switch action.Type {
+	case models.TaskActionCommand:
+		return s.sendCommand(ctx, task.ServerID, action.Command)
+
+	case models.TaskActionWait:
+		return "", sleep(ctx, time.Duration(action.Seconds)*time.Second)
+
+	case models.TaskActionRestart, models.TaskActionStop:
+		output, err := s.countdown(ctx, task.ServerID, action)
+		if err != nil {
+			return output, err
+		}
+		if action.Type == models.TaskActionRestart {
+			if _, err := s.serverService.Restart(task.ServerID, task.UserID, true); err != nil {
+				return output, err
+			}
+			return output + "servidor reiniciado", nil
+		}
+		_, err = s.serverService.Stop(task.ServerID, task.UserID, true)
+		switch {
+		case errors.Is(err, server.ErrInvalidServerState):
+			return output + "el servidor no estaba en ejecución", nil
+		case err != nil:
+			return output, err
+		}
+		return output + "servidor detenido", nil
+
+	case models.TaskActionStart:
+		_, err := s.serverService.Start(task.ServerID, task.UserID, true)
+		switch {
+		case errors.Is(err, server.ErrInvalidServerState):
+			return "el servidor ya estaba en ejecución", nil
+		case err != nil:
+			return "", err
+		}
+		return "servidor iniciado", nil
+
+	case models.TaskActionBackup:
+		return s.backup(ctx, task.ServerID)
+
+	default:
+		return "", fmt.Errorf("acción no soportada: %s", action.Type)
+	}
+}
+
+// sendCommand envía un comando a la consola de un servidor en ejecución
+func (s *Service) sendCommand(ctx context.Context, serverID uuid.UUID, command string) (string, error) {
+	var srv models.Server
+	if err := s.db.Select("id", "agent_id", "status").First(&srv, "id = ?", serverID).Error; err != nil {
+		return "", fmt.Errorf("servidor no encontrado: %w", err)
+	}
+	if !srv.IsRunning() {
+		return "", fmt.Errorf("el servidor no está en ejecución (%s)", srv.Status)
+	}
+	return s.agentService.SendCommand(ctx, srv.ID.String(), srv.AgentID, command)
+}
+
+// countdown avisa a los jugadores antes de un reinicio o una parada y
+// espera hasta el momento de la acción. Los avisos solo se envían si el
+// servidor está en ejecución y un aviso fallido no detiene la cuenta atrás.
+func (s *Service) countdown(ctx context.Context, serverID uuid.UUID, action models.TaskAction) (string, error) {
+	message := action.Message
+	if message == "" {
+		message = defaultRestartWarning
+		if action.Type == models.TaskActionStop {
+			message = defaultStopWarning
+		}
+	}
+
+	var output strings.Builder
+	for _, step := range warningPlan(action.Warnings) {
+		text := strings.ReplaceAll(message, "{time}", formatCountdown(step.remaining))
+		if _, err := s.sendCommand(ctx, serverID, "say "+text); err != nil {
+			fmt.Fprintf(&output, "aviso %q no enviado: %v\n", text, err)
+		} else {
+			fmt.Fprintf(&output, "aviso: %s\n", text)
+		}
+		if err := sleep(ctx, step.wait); err != nil {
+			return output.String(), err
+		}
+	}
+	return output.String(), nil
+}
+
+// warningStep un aviso de la cuenta atrás: el tiempo restante que anuncia y
+// la espera hasta el siguiente aviso (o hasta la acción)
+type warningStep struct {
+	remaining int
+	wait      time.Duration
+}
+
+// warningPlan ordena los avisos de mayor a menor y calcula las esperas
+func warningPlan(warnings []int) []warningStep {
+	sorted := make([]int, 0, len(warnings))
+	seen := make(map[int]bool, len(warnings))
+	for _, w := range warnings {
+		if w > 0 && !seen[w] {
+			seen[w] = true
+			sorted = append(sorted, w)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	plan := make([]warningStep, len(sorted))
+	for i, w := range sorted {
+		next := 0
+		if i+1 < len(sorted) {
+			next = sorted[i+1]
+		}
+		plan[i] = warningStep{remaining: w, wait: time.Duration(w-next) * time.Second}
+	}
+	return plan
+}
+
+// formatCountdown formatea el tiempo restante de un aviso
+func formatCountdown(seconds int) string {
+	switch {
+	case seconds >= 3600 && seconds%3600 == 0:
+		return plural(seconds/3600, "hora", "horas")
+	case seconds >= 60 && seconds%60 == 0:
+		return plural(seconds/60, "minuto", "minutos")
+	default:
+		return plural(seconds, "segundo", "segundos")
+	}
+}
+
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, pluralForm)
+}
+
+// backup crea un backup con la configuración del servidor y espera a que
+// termine
+func (s *Service) backup(ctx context.Context, serverID uuid.UUID) (string, error) {
+	config, err := s.backupService.GetBackupConfig(ctx, serverID)
+	if err != nil {
+		return "", fmt.Errorf("error obteniendo configuración de backups: %w", err)
+	}
+
+	timestamp := time.Now().Format("2006-01-02-15-04-05")
+	req := &models.CreateBackupRequest{
+		ServerID:    serverID,
+		Filename:    fmt.Sprintf("task-backup-%s.tar.gz", timestamp),
+		BackupType:  config.BackupType,
+		Compression: "gzip",
+	}
+	if !config.CompressBackups {
+		req.Filename = fmt.Sprintf("task-backup-%s.tar", timestamp)
+		req.Compression = "none"
+	}
+
+	created, err := s.backupService.CreateBackup(ctx, req, uuid.Nil)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backupTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(backupPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Sprintf("backup %s", created.ID), fmt.Errorf("el backup no terminó a tiempo: %w", ctx.Err())
+		case <-ticker.C:
+		}
+
+		current, err := s.backupService.GetBackup(ctx, created.ID)
+		if err != nil {
+			return "", err
+		}
+		switch current.Status {
+		case models.BackupStatusCompleted:
+			return fmt.Sprintf("backup %s (%.1f MB)", current.Filename, current.FileSizeMB()), nil
+		case models.BackupStatusFailed:
+			return fmt.Sprintf("backup %s", current.Filename), fmt.Errorf("backup fallido: %s", current.Error)
+		}
+	}
+}
+
+// finish cierra una ejecución, actualiza el estado de la tarea, recorta el
+// historial y notifica los fallos
+func (s *Service) finish(task *models.ScheduledTask, run *models.ScheduledTaskRun, srv *models.Server, runErr error) {
+	now := time.Now()
+	run.FinishedAt = &now
+	run.DurationMs = now.Sub(run.StartedAt).Milliseconds()
+	if runErr != nil {
+		run.Status = models.TaskRunFailed
+		run.Error = runErr.Error()
+	} else if run.Status == models.TaskRunRunning {
+		run.Status = models.TaskRunSuccess
+	}
+
+	if err := s.db.Save(run).Error; err != nil {
+		s.logger.Error("Error saving task run", zap.String("run_id", run.ID.String()), zap.Error(err))
+	}
+	if err := s.db.Model(&models.ScheduledTask{}).Where("id = ?", task.ID).Updates(map[string]interface{}{
+		"last_run_at":     run.StartedAt,
+		"last_run_status": run.Status,
+	}).Error; err != nil {
+		s.logger.Error("Error updating task status", zap.String("task_id", task.ID.String()), zap.Error(err))
+	}
+
+	s.pruneRuns(task.ID)
+
+	if run.Status != models.TaskRunFailed {
+		s.logger.Info("Scheduled task finished",
+			zap.String("task_id", task.ID.String()),
+			zap.String("run_id", run.ID.String()),
+			zap.String("status", string(run.Status)),
+			zap.Int64("duration_ms", run.DurationMs),
+		)
+		return
+	}
+
+	s.logger.Warn("Scheduled task failed",
+		zap.String("task_id", task.ID.String()),
+		zap.String("run_id", run.ID.String()),
+		zap.Error(runErr),
+	)
+
+	serverName := task.ServerID.String()
+	if srv != nil {
+		serverName = srv.Name
+	}
+	data := map[string]interface{}{
+		"task_id":   task.ID.String(),
+		"task_name": task.Name,
+		"run_id":    run.ID.String(),
+		"trigger":   run.Trigger,
+	}
+	if run.FailedStep != nil {
+		data["failed_step"] = *run.FailedStep
+	}
+	s.hub.BroadcastAlert(websocket.Alert{
+		ID:        uuid.New(),
+		Severity:  string(models.AlertSeverityError),
+		Title:     fmt.Sprintf("Tarea programada fallida: %s (%s)", task.Name, serverName),
+		Message:   run.Error,
+		Source:    models.AlertTargetServer,
+		SourceID:  task.ServerID,
+		Timestamp: now,
+		Data:      data,
+	})
+}
+
+// pruneRuns conserva solo las últimas maxRunsPerTask ejecuciones de una tarea
+func (s *Service) pruneRuns(taskID uuid.UUID) {
+	keep := s.db.Model(&models.ScheduledTaskRun{}).Select("id").
+		Where("task_id = ?", taskID).
+		Order("started_at DESC").
+		Limit(maxRunsPerTask)
+
+	if err := s.db.Where("task_id = ? AND id NOT IN (?)", taskID, keep).
+		Delete(&models.ScheduledTaskRun{}).Error; err != nil {
+		s.logger.Warn("Error pruning task runs", zap.String("task_id", taskID.String()), zap.Error(err))
+	}
+}
+
+// sleep espera d o hasta que se cancele ctx
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// truncate recorta un texto a max bytes sin partir caracteres UTF-8
+func truncate(text string, max int) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= max {
+		return text
+	}
+	return strings.ToValidUTF8(text[:max], "") + "…"
+}
@@ -0,0 +1,202 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aymc/backend/api/websocket"
+	"github.com/aymc/backend/database/models"
+	"github.com/aymc/backend/services/agents"
+	"github.com/aymc/backend/services/backup"
+	"github.com/aymc/backend/services/server"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// scheduleParser acepta expresiones cron de 5 campos, con segundos
+// opcionales como primer campo, y descriptores como @daily o @every 1h
+var scheduleParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// Service programa y ejecuta las tareas de los servidores: secuencias de
+// acciones (comandos, esperas, reinicios, backups...) lanzadas por cron o
+// manualmente, con un historial de ejecuciones por tarea
+type Service struct {
+	db            *gorm.DB
+	serverService *server.ServerService
+	agentService  *agents.AgentService
+	backupService *backup.Service
+	hub           *websocket.Hub
+	logger        *zap.Logger
+	cron          *cron.Cron
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]cron.EntryID // task_id -> entry_id
+	running map[uuid.UUID]bool         // Tareas con una ejecución en curso
+
+	// Las ejecuciones en curso se cancelan al detener el servicio
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService crea el servicio de tareas programadas
+func NewService(db *gorm.DB, serverService *server.ServerService, agentService *agents.AgentService, backupService *backup.Service, hub *websocket.Hub, logger *zap.Logger) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		db:            db,
+		serverService: serverService,
+		agentService:  agentService,
+		backupService: backupService,
+		hub:           hub,
+		logger:        logger.With(zap.String("component", "tasks")),
+		cron:          cron.New(cron.WithParser(scheduleParser)),
+		entries:       make(map[uuid.UUID]cron.EntryID),
+		running:       make(map[uuid.UUID]bool),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Start programa todas las tareas activas e inicia el cron
+func (s *Service) Start() error {
+	s.logger.Info("Starting scheduled tasks")
+
+	// Las ejecuciones que quedaron a medias en un reinicio del backend no
+	// van a terminar
+	now := time.Now()
+	if err := s.db.Model(&models.ScheduledTaskRun{}).
+		Where("status = ?", models.TaskRunRunning).
+		Updates(map[string]interface{}{
+			"status":      models.TaskRunFailed,
+			"error":       "interrupted by backend shutdown",
+			"finished_at": now,
+		}).Error; err != nil {
+		return fmt.Errorf("error closing interrupted task runs: %w", err)
+	}
+
+	var tasks []models.ScheduledTask
+	if err := s.db.Where("enabled = ?", true).Find(&tasks).Error; err != nil {
+		return fmt.Errorf("error loading scheduled tasks: %w", err)
+	}
+
+	for i := range tasks {
+		if err := s.schedule(&tasks[i]); err != nil {
+			s.logger.Error("Error scheduling task",
+				zap.String("task_id", tasks[i].ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	s.cron.Start()
+
+	s.logger.Info("Scheduled tasks started", zap.Int("scheduled_tasks", s.JobCount()))
+	return nil
+}
+
+// Stop detiene el cron, cancela las ejecuciones en curso y espera a que
+// terminen
+func (s *Service) Stop() {
+	s.logger.Info("Stopping scheduled tasks")
+	ctx := s.cron.Stop()
+	s.cancel()
+	<-ctx.Done()
+	s.wg.Wait()
+	s.logger.Info("Scheduled tasks stopped")
+}
+
+// schedule programa (o reprograma) una tarea y actualiza next_run_at
+func (s *Service) schedule(task *models.ScheduledTask) error {
+	s.unschedule(task.ID)
+
+	schedule, err := scheduleParser.Parse(task.Schedule)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+
+	taskID := task.ID
+	s.mu.Lock()
+	s.entries[taskID] = s.cron.Schedule(schedule, cron.FuncJob(func() {
+		s.runScheduled(taskID)
+	}))
+	s.mu.Unlock()
+
+	s.updateNextRun(taskID, schedule)
+	return nil
+}
+
+// unschedule quita una tarea del cron
+func (s *Service) unschedule(taskID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entryID, exists := s.entries[taskID]; exists {
+		s.cron.Remove(entryID)
+		delete(s.entries, taskID)
+	}
+}
+
+// updateNextRun guarda la próxima ejecución de una tarea
+func (s *Service) updateNextRun(taskID uuid.UUID, schedule cron.Schedule) {
+	next := schedule.Next(time.Now())
+	if err := s.db.Model(&models.ScheduledTask{}).
+		Where("id = ?", taskID).
+		Update("next_run_at", next).Error; err != nil {
+		s.logger.Error("Error updating next_run_at",
+			zap.String("task_id", taskID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// runScheduled ejecuta una tarea lanzada por el cron. Se relee la tarea
+// para usar sus acciones actuales; si ya no existe o está desactivada se
+// quita del cron.
+func (s *Service) runScheduled(taskID uuid.UUID) {
+	var task models.ScheduledTask
+	if err := s.db.First(&task, "id = ?", taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.unschedule(taskID)
+			return
+		}
+		s.logger.Error("Error loading scheduled task", zap.String("task_id", taskID.String()), zap.Error(err))
+		return
+	}
+	if !task.Enabled {
+		s.unschedule(taskID)
+		return
+	}
+
+	if schedule, err := scheduleParser.Parse(task.Schedule); err == nil {
+		s.updateNextRun(taskID, schedule)
+	}
+
+	if _, err := s.execute(&task, models.TaskTriggerSchedule, nil); err != nil {
+		s.logger.Warn("Scheduled task not run",
+			zap.String("task_id", taskID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// IsTaskScheduled verifica si una tarea está programada
+func (s *Service) IsTaskScheduled(taskID uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.entries[taskID]
+	return exists
+}
+
+// JobCount retorna el número de tareas programadas
+func (s *Service) JobCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
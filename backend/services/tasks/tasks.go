@@ -0,0 +1,302 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aymc/backend/database/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTaskNotFound    = errors.New("scheduled task not found")
+	ErrServerNotFound  = errors.New("server not found")
+	ErrInvalidTask     = errors.New("invalid scheduled task")
+	ErrInvalidSchedule = errors.New("invalid cron schedule")
+	ErrTaskRunning     = errors.New("scheduled task is already running")
+)
+
+const (
+	maxActions         = 50
+	maxWaitSeconds     = 6 * 3600
+	maxWarningSeconds  = 3600
+	maxCommandLength   = 1000
+	defaultRunsPerPage = 20
+)
+
+// CreateTaskRequest represents the request to create a scheduled task
+type CreateTaskRequest struct {
+	Name          string              `json:"name" validate:"required,min=3,max=100"`
+	Schedule      string              `json:"schedule" validate:"required,max=100"`
+	Actions       []models.TaskAction `json:"actions" validate:"required,min=1,dive"`
+	Enabled       *bool               `json:"enabled,omitempty"`
+	SkipIfStopped bool                `json:"skip_if_stopped"`
+}
+
+// UpdateTaskRequest represents the request to update a scheduled task
+type UpdateTaskRequest struct {
+	Name          *string             `json:"name,omitempty" validate:"omitempty,min=3,max=100"`
+	Schedule      *string             `json:"schedule,omitempty" validate:"omitempty,max=100"`
+	Actions       []models.TaskAction `json:"actions,omitempty" validate:"omitempty,min=1,dive"`
+	Enabled       *bool               `json:"enabled,omitempty"`
+	SkipIfStopped *bool               `json:"skip_if_stopped,omitempty"`
+}
+
+// TaskRunListResponse represents a page of task runs
+type TaskRunListResponse struct {
+	Runs  []models.ScheduledTaskRun `json:"runs"`
+	Total int64                     `json:"total"`
+}
+
+// ListTasks lista las tareas programadas de un servidor
+func (s *Service) ListTasks(ctx context.Context, serverID, userID uuid.UUID, isAdmin bool) ([]models.ScheduledTask, error) {
+	if _, err := s.getServer(ctx, serverID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	tasks := []models.ScheduledTask{}
+	err := s.db.WithContext(ctx).Where("server_id = ?", serverID).Order("created_at").Find(&tasks).Error
+	return tasks, err
+}
+
+// GetTask obtiene una tarea programada
+func (s *Service) GetTask(ctx context.Context, taskID, userID uuid.UUID, isAdmin bool) (*models.ScheduledTask, error) {
+	query := s.db.WithContext(ctx)
+	if !isAdmin {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var task models.ScheduledTask
+	if err := query.First(&task, "id = ?", taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CreateTask crea una tarea programada y la añade al cron si está activa
+func (s *Service) CreateTask(ctx context.Context, serverID, userID uuid.UUID, isAdmin bool, req *CreateTaskRequest) (*models.ScheduledTask, error) {
+	srv, err := s.getServer(ctx, serverID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &models.ScheduledTask{
+		ServerID:      srv.ID,
+		UserID:        srv.UserID,
+		Name:          req.Name,
+		Schedule:      strings.TrimSpace(req.Schedule),
+		Actions:       normalizeActions(req.Actions),
+		Enabled:       true,
+		SkipIfStopped: req.SkipIfStopped,
+	}
+	if req.Enabled != nil {
+		task.Enabled = *req.Enabled
+	}
+
+	if err := validateTask(task); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(task).Error; err != nil {
+		return nil, fmt.Errorf("failed to create scheduled task: %w", err)
+	}
+
+	if task.Enabled {
+		if err := s.schedule(task); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetTask(ctx, task.ID, userID, isAdmin)
+}
+
+// UpdateTask actualiza una tarea programada y la reprograma
+func (s *Service) UpdateTask(ctx context.Context, taskID, userID uuid.UUID, isAdmin bool, req *UpdateTaskRequest) (*models.ScheduledTask, error) {
+	task, err := s.GetTask(ctx, taskID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		task.Name = *req.Name
+	}
+	if req.Schedule != nil {
+		task.Schedule = strings.TrimSpace(*req.Schedule)
+	}
+	if req.Actions != nil {
+		task.Actions = normalizeActions(req.Actions)
+	}
+	if req.Enabled != nil {
+		task.Enabled = *req.Enabled
+	}
+	if req.SkipIfStopped != nil {
+		task.SkipIfStopped = *req.SkipIfStopped
+	}
+
+	if err := validateTask(task); err != nil {
+		return nil, err
+	}
+
+	if !task.Enabled {
+		task.NextRunAt = nil
+	}
+	if err := s.db.WithContext(ctx).Save(task).Error; err != nil {
+		return nil, fmt.Errorf("failed to update scheduled task: %w", err)
+	}
+
+	if task.Enabled {
+		if err := s.schedule(task); err != nil {
+			return nil, err
+		}
+	} else {
+		s.unschedule(task.ID)
+	}
+
+	return s.GetTask(ctx, task.ID, userID, isAdmin)
+}
+
+// DeleteTask elimina una tarea programada y su historial. Una ejecución en
+// curso termina normalmente.
+func (s *Service) DeleteTask(ctx context.Context, taskID, userID uuid.UUID, isAdmin bool) error {
+	task, err := s.GetTask(ctx, taskID, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+
+	s.unschedule(task.ID)
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", task.ID).Delete(&models.ScheduledTaskRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(task).Error
+	})
+}
+
+// RunTask lanza una tarea inmediatamente, esté o no activa
+func (s *Service) RunTask(ctx context.Context, taskID, userID uuid.UUID, isAdmin bool) (*models.ScheduledTaskRun, error) {
+	task, err := s.GetTask(ctx, taskID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	return s.execute(task, models.TaskTriggerManual, &userID)
+}
+
+// ListRuns lista el historial de ejecuciones de una tarea, las más
+// recientes primero
+func (s *Service) ListRuns(ctx context.Context, taskID, userID uuid.UUID, isAdmin bool, limit, offset int) (*TaskRunListResponse, error) {
+	if _, err := s.GetTask(ctx, taskID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > maxRunsPerTask {
+		limit = defaultRunsPerPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.ScheduledTaskRun{}).Where("task_id = ?", taskID)
+
+	response := &TaskRunListResponse{Runs: []models.ScheduledTaskRun{}}
+	if err := query.Count(&response.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Order("started_at DESC").Limit(limit).Offset(offset).Find(&response.Runs).Error; err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// getServer obtiene un servidor comprobando el acceso del usuario
+func (s *Service) getServer(ctx context.Context, serverID, userID uuid.UUID, isAdmin bool) (*models.Server, error) {
+	query := s.db.WithContext(ctx).Select("id", "user_id", "name")
+	if !isAdmin {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var srv models.Server
+	if err := query.First(&srv, "id = ?", serverID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrServerNotFound
+		}
+		return nil, err
+	}
+	return &srv, nil
+}
+
+// normalizeActions limpia los campos de texto de las acciones
+func normalizeActions(actions []models.TaskAction) []models.TaskAction {
+	normalized := make([]models.TaskAction, len(actions))
+	for i, action := range actions {
+		action.Command = strings.TrimPrefix(strings.TrimSpace(action.Command), "/")
+		action.Message = strings.TrimSpace(action.Message)
+		normalized[i] = action
+	}
+	return normalized
+}
+
+// validateTask comprueba la expresión cron y los campos de cada acción
+func validateTask(task *models.ScheduledTask) error {
+	if _, err := scheduleParser.Parse(task.Schedule); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+
+	if len(task.Actions) == 0 || len(task.Actions) > maxActions {
+		return fmt.Errorf("%w: a task needs between 1 and %d actions", ErrInvalidTask, maxActions)
+	}
+
+	for i, action := range task.Actions {
+		if err := validateAction(action); err != nil {
+			return fmt.Errorf("%w: action %d (%s): %v", ErrInvalidTask, i+1, action.Type, err)
+		}
+	}
+	return nil
+}
+
+// validateAction comprueba los campos que usa cada tipo de acción
+func validateAction(action models.TaskAction) error {
+	switch action.Type {
+	case models.TaskActionCommand:
+		if action.Command == "" {
+			return errors.New("command is required")
+		}
+		if len(action.Command) > maxCommandLength || strings.ContainsAny(action.Command, "\r\n") {
+			return fmt.Errorf("command must be a single line of at most %d characters", maxCommandLength)
+		}
+	case models.TaskActionWait:
+		if action.Seconds < 1 || action.Seconds > maxWaitSeconds {
+			return fmt.Errorf("seconds must be between 1 and %d", maxWaitSeconds)
+		}
+	case models.TaskActionRestart, models.TaskActionStop:
+		for _, w := range action.Warnings {
+			if w < 1 || w > maxWarningSeconds {
+				return fmt.Errorf("warnings must be between 1 and %d seconds", maxWarningSeconds)
+			}
+		}
+		if strings.ContainsAny(action.Message, "\r\n") {
+			return errors.New("message must be a single line")
+		}
+	case models.TaskActionBackup, models.TaskActionStart:
+	default:
+		return errors.New("unknown action type")
+	}
+
+	if action.Type != models.TaskActionWait && action.Seconds != 0 {
+		return errors.New("seconds only applies to wait actions")
+	}
+	if action.Type != models.TaskActionRestart && action.Type != models.TaskActionStop &&
+		(len(action.Warnings) > 0 || action.Message != "") {
+		return errors.New("warnings and message only apply to restart and stop actions")
+	}
+	if action.Type != models.TaskActionCommand && action.Command != "" {
+		return errors.New("command only applies to command actions")
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aymc/backend/database/models"
+)
+
+func TestWarningPlan(t *testing.T) {
+	plan := warningPlan([]int{10, 300, 60, 60, 0})
+
+	want := []warningStep{
+		{remaining: 300, wait: 240 * time.Second},
+		{remaining: 60, wait: 50 * time.Second},
+		{remaining: 10, wait: 10 * time.Second},
+	}
+	if len(plan) != len(want) {
+		t.Fatalf("got %d steps, want %d: %+v", len(plan), len(want), plan)
+	}
+	for i := range want {
+		if plan[i] != want[i] {
+			t.Errorf("step %d = %+v, want %+v", i, plan[i], want[i])
+		}
+	}
+
+	if len(warningPlan(nil)) != 0 {
+		t.Error("no warnings should produce an empty plan")
+	}
+}
+
+func TestFormatCountdown(t *testing.T) {
+	for seconds, want := range map[int]string{
+		1:    "1 segundo",
+		30:   "30 segundos",
+		60:   "1 minuto",
+		90:   "90 segundos",
+		300:  "5 minutos",
+		3600: "1 hora",
+	} {
+		if got := formatCountdown(seconds); got != want {
+			t.Errorf("formatCountdown(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
+
+func TestValidateTask(t *testing.T) {
+	restart := []models.TaskAction{
+		{Type: models.TaskActionRestart, Warnings: []int{300, 60}, Message: "Reinicio en {time}"},
+	}
+
+	for _, schedule := range []string{"0 4 * * *", "30 0 4 * * *", "@daily", "@every 6h"} {
+		task := &models.ScheduledTask{Schedule: schedule, Actions: restart}
+		if err := validateTask(task); err != nil {
+			t.Errorf("schedule %q: %v", schedule, err)
+		}
+	}
+
+	for _, schedule := range []string{"", "every day", "61 * * * *"} {
+		task := &models.ScheduledTask{Schedule: schedule, Actions: restart}
+		if err := validateTask(task); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("schedule %q: expected ErrInvalidSchedule, got %v", schedule, err)
+		}
+	}
+
+	valid := []models.TaskAction{
+		{Type: models.TaskActionCommand, Command: "say Backup en curso"},
+		{Type: models.TaskActionWait, Seconds: 30},
+		{Type: models.TaskActionBackup},
+		{Type: models.TaskActionStop, Warnings: []int{60}},
+		{Type: models.TaskActionStart},
+	}
+	if err := validateTask(&models.ScheduledTask{Schedule: "0 4 * * *", Actions: valid}); err != nil {
+		t.Errorf("valid actions: %v", err)
+	}
+
+	invalid := []models.TaskAction{
+		{Type: models.TaskActionCommand},
+		{Type: models.TaskActionCommand, Command: "say a\nstop"},
+		{Type: models.TaskActionWait},
+		{Type: models.TaskActionWait, Seconds: maxWaitSeconds + 1},
+		{Type: models.TaskActionRestart, Warnings: []int{0}},
+		{Type: models.TaskActionRestart, Warnings: []int{maxWarningSeconds + 1}},
+		{Type: models.TaskActionBackup, Seconds: 10},
+		{Type: models.TaskActionStart, Warnings: []int{60}},
+		{Type: models.TaskActionWait, Seconds: 10, Command: "list"},
+		{Type: "reboot"},
+	}
+	for _, action := range invalid {
+		task := &models.ScheduledTask{Schedule: "0 4 * * *", Actions: []models.TaskAction{action}}
+		if err := validateTask(task); !errors.Is(err, ErrInvalidTask) {
+			t.Errorf("action %+v: expected ErrInvalidTask, got %v", action, err)
+		}
+	}
+
+	if err := validateTask(&models.ScheduledTask{Schedule: "0 4 * * *"}); !errors.Is(err, ErrInvalidTask) {
+		t.Errorf("no actions: expected ErrInvalidTask, got %v", err)
+	}
+}
+
+func TestNormalizeActions(t *testing.T) {
+	actions := normalizeActions([]models.TaskAction{
+		{Type: models.TaskActionCommand, Command: "  /save-all flush "},
+		{Type: models.TaskActionRestart, Message: " Reinicio en {time} "},
+	})
+
+	if actions[0].Command != "save-all flush" {
+		t.Errorf("command = %q", actions[0].Command)
+	}
+	if actions[1].Message != "Reinicio en {time}" {
+		t.Errorf("message = %q", actions[1].Message)
+	}
+}
+
+func TestSleepIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("sleep did not return on cancellation")
+	}
+}